@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -85,6 +86,25 @@ func runDoctor(options *doctorOptions) error {
 	results = append(results, checkDeployDir("Nginx 证书目录", cfg.SSL.NginxPath))
 	results = append(results, checkDeployDir("Apache 证书目录", cfg.SSL.ApachePath))
 	results = append(results, checkRustFSTarget(cfg.SSL.RustFS))
+	results = append(results, checkOpenVPNASTarget(cfg.SSL.OpenVPNAS))
+	results = append(results, checkCaddyTarget(cfg.SSL.Caddy)...)
+	results = append(results, checkHAProxyTarget(cfg.SSL.HAProxy)...)
+	results = append(results, checkMailTarget(cfg.SSL.Mail)...)
+	results = append(results, checkDockerTarget(cfg.SSL.Docker)...)
+	results = append(results, checkNginxProxyManagerTarget(cfg.SSL.NginxProxyManager))
+	results = append(results, checkTraefikTarget(cfg.SSL.Traefik))
+	results = append(results, checkKubernetesTarget(cfg.SSL.Kubernetes))
+	results = append(results, checkJavaKeystoreTarget(cfg.SSL.JavaKeystore))
+	results = append(results, checkLocalTargets(cfg.SSL.LocalTargets)...)
+	results = append(results, checkSSHTargets(cfg.SSL.SSHTargets)...)
+	results = append(results, checkFeiNiuDevices(cfg.SSL.FeiNiuDevices)...)
+	results = append(results, checkSynologyTargets(cfg.SSL.Synology)...)
+	results = append(results, checkProxmoxTargets(cfg.SSL.Proxmox)...)
+	results = append(results, checkFirewallTargets(cfg.SSL.Firewalls)...)
+	results = append(results, checkProxyServers(cfg.SSL.ProxyServers)...)
+	results = append(results, checkDatabases(cfg.SSL.Databases)...)
+	results = append(results, checkS3Servers(cfg.SSL.S3Servers)...)
+	results = append(results, checkMediaServers(cfg.SSL.MediaServers)...)
 	results = append(results, checkCommand("Nginx 命令", "nginx", "-t"))
 	results = append(results, checkApacheCommand())
 	results = append(results, checkProviderConfigs(cfg)...)
@@ -112,6 +132,235 @@ func checkRustFSTarget(rustFS *config.RustFSConfig) doctorResult {
 	return checkDeployDir("RustFS 证书目录", rustFS.Path)
 }
 
+// checkOpenVPNASTarget 只报告已配置的 OpenVPN-AS 部署模式，远程 sacli 由连接测试在 SSH 登录后检查。
+func checkOpenVPNASTarget(openVPNAS *config.OpenVPNASConfig) doctorResult {
+	if openVPNAS != nil && config.IsSSHConfigured(&openVPNAS.SSHConfig) {
+		return okDoctor("OpenVPN-AS", "已配置 SSH 远程部署")
+	}
+	return okDoctor("OpenVPN-AS", "本机部署")
+}
+
+// checkCaddyTarget 检查 Caddy 证书目录是否可写，配置后再确认 caddy 命令可用，不主动请求管理 API。
+func checkCaddyTarget(caddy *config.CaddyConfig) []doctorResult {
+	if caddy == nil || caddy.Path == "" {
+		return []doctorResult{checkDeployDir("Caddy 证书目录", "")}
+	}
+	return []doctorResult{checkDeployDir("Caddy 证书目录", caddy.Path), checkCommand("Caddy 命令", "caddy", "version")}
+}
+
+// checkHAProxyTarget 检查 HAProxy 合并证书目录是否可写，配置后再确认 haproxy 命令可用，不主动连接 Runtime API。
+func checkHAProxyTarget(haproxy *config.HAProxyConfig) []doctorResult {
+	if haproxy == nil || haproxy.Path == "" {
+		return []doctorResult{checkDeployDir("HAProxy 证书目录", "")}
+	}
+	return []doctorResult{checkDeployDir("HAProxy 证书目录", haproxy.Path), checkCommand("HAProxy 命令", "haproxy", "-v")}
+}
+
+// checkMailTarget 检查邮件服务证书目录是否可写，并只对配置中启用的服务执行 postfix check 或 doveconf -n，不主动重新加载。
+func checkMailTarget(mail *config.MailConfig) []doctorResult {
+	if mail == nil || mail.Path == "" {
+		return []doctorResult{checkDeployDir("邮件服务证书目录", "")}
+	}
+	results := []doctorResult{checkDeployDir("邮件服务证书目录", mail.Path)}
+	for _, service := range mail.Services {
+		switch service {
+		case config.MailServicePostfix:
+			results = append(results, checkCommand("Postfix 命令", "postfix", "check"))
+		case config.MailServiceDovecot:
+			results = append(results, checkCommand("Dovecot 命令", "doveconf", "-n"))
+		}
+	}
+	return results
+}
+
+// checkDockerTarget 检查 Docker socket 是否存在以及证书目录是否可写，不主动请求 Docker Engine API。
+func checkDockerTarget(docker *config.DockerConfig) []doctorResult {
+	if docker == nil {
+		return []doctorResult{okDoctor("Docker socket", "未配置，跳过")}
+	}
+	socket := okDoctor("Docker socket", docker.Socket)
+	if info, err := os.Stat(docker.Socket); err != nil {
+		socket = failDoctor("Docker socket", fmt.Sprintf("socket 不可访问: %v", err))
+	} else if info.Mode()&os.ModeSocket == 0 {
+		socket = failDoctor("Docker socket", fmt.Sprintf("%s 不是 Unix socket", docker.Socket))
+	}
+	return []doctorResult{socket, checkDeployDir("Docker 证书目录", docker.Path)}
+}
+
+// checkNginxProxyManagerTarget 显示已配置的 Nginx Proxy Manager 地址和账号，不主动登录管理端。
+func checkNginxProxyManagerTarget(npm *config.NginxProxyManagerConfig) doctorResult {
+	if npm == nil || npm.URL == "" {
+		return okDoctor("Nginx Proxy Manager", "未配置，跳过")
+	}
+	return okDoctor("Nginx Proxy Manager", fmt.Sprintf("%s@%s", npm.Email, npm.URL))
+}
+
+// checkTraefikTarget 检查 Traefik 证书和动态配置目录是否可写。
+func checkTraefikTarget(traefik *config.TraefikConfig) doctorResult {
+	if traefik == nil {
+		return checkDeployDir("Traefik 证书目录", "")
+	}
+	return checkDeployDir("Traefik 证书目录", traefik.Path)
+}
+
+// checkKubernetesTarget 检查 Kubernetes 凭据来源是否可读，不主动请求 API Server。
+func checkKubernetesTarget(kubernetes *config.KubernetesConfig) doctorResult {
+	if kubernetes == nil {
+		return okDoctor("Kubernetes 凭据", "未配置，跳过")
+	}
+	if kubernetes.Kubeconfig == "" {
+		return okDoctor("Kubernetes 凭据", "使用 Pod 内 ServiceAccount 凭据")
+	}
+	if _, err := os.Stat(kubernetes.Kubeconfig); err != nil {
+		return failDoctor("Kubernetes 凭据", fmt.Sprintf("kubeconfig 不可读: %v", err))
+	}
+	return okDoctor("Kubernetes 凭据", kubernetes.Kubeconfig)
+}
+
+// checkJavaKeystoreTarget 检查 Java 密钥库目录是否可写，不执行重启命令。
+func checkJavaKeystoreTarget(keystore *config.JavaKeystoreConfig) doctorResult {
+	if keystore == nil {
+		return checkDeployDir("Java 密钥库目录", "")
+	}
+	return checkDeployDir("Java 密钥库目录", keystore.Path)
+}
+
+// checkLocalTargets 检查每个本地部署实例的目录，不执行实例配置的命令。
+func checkLocalTargets(targets []*config.LocalTargetConfig) []doctorResult {
+	results := make([]doctorResult, 0, len(targets))
+	for _, target := range targets {
+		results = append(results, checkDeployDir("本地部署实例 "+target.Name, target.Path))
+	}
+	return results
+}
+
+// checkSSHTargets 检查每台 SSH 远程主机的私钥文件是否可读，不主动建立 SSH 连接。
+func checkSSHTargets(targets []*config.SSHTargetConfig) []doctorResult {
+	results := make([]doctorResult, 0, len(targets))
+	for _, target := range targets {
+		name := "SSH 远程主机 " + target.Name
+		if target.PrivateKeyPath != "" {
+			if _, err := os.Stat(target.PrivateKeyPath); err != nil {
+				results = append(results, failDoctor(name, fmt.Sprintf("私钥文件不可读: %v", err)))
+				continue
+			}
+		}
+		results = append(results, okDoctor(name, fmt.Sprintf("%s@%s:%d %s", target.Username, target.Host, target.Port, target.Path)))
+	}
+	return results
+}
+
+// checkFeiNiuDevices 检查每台飞牛设备的私钥文件是否可读，不主动建立 SSH 连接。
+func checkFeiNiuDevices(devices []*config.FeiNiuDeviceConfig) []doctorResult {
+	results := make([]doctorResult, 0, len(devices))
+	for _, device := range devices {
+		name := "飞牛设备 " + device.Name
+		if device.PrivateKeyPath != "" {
+			if _, err := os.Stat(device.PrivateKeyPath); err != nil {
+				results = append(results, failDoctor(name, fmt.Sprintf("私钥文件不可读: %v", err)))
+				continue
+			}
+		}
+		results = append(results, okDoctor(name, fmt.Sprintf("%s@%s:%d", device.Username, device.Host, device.Port)))
+	}
+	return results
+}
+
+// checkSynologyTargets 列出已配置的群晖 NAS，不主动登录 DSM。
+func checkSynologyTargets(targets []*config.SynologyConfig) []doctorResult {
+	results := make([]doctorResult, 0, len(targets))
+	for _, nas := range targets {
+		results = append(results, okDoctor("群晖 NAS "+nas.Name, fmt.Sprintf("%s@%s", nas.Username, nas.URL)))
+	}
+	return results
+}
+
+// checkProxmoxTargets 列出已配置的 Proxmox VE 集群，不主动请求节点 API。
+func checkProxmoxTargets(targets []*config.ProxmoxConfig) []doctorResult {
+	results := make([]doctorResult, 0, len(targets))
+	for _, cluster := range targets {
+		results = append(results, okDoctor("Proxmox VE 集群 "+cluster.Name, fmt.Sprintf("%s %s", cluster.TokenID, cluster.URL)))
+	}
+	return results
+}
+
+// checkFirewallTargets 列出已配置的防火墙，不主动请求 REST API。
+func checkFirewallTargets(targets []*config.FirewallConfig) []doctorResult {
+	results := make([]doctorResult, 0, len(targets))
+	for _, firewall := range targets {
+		results = append(results, okDoctor("防火墙 "+firewall.Name, fmt.Sprintf("%s %s", firewall.Kind, firewall.URL)))
+	}
+	return results
+}
+
+// checkProxyServers 检查每个代理服务的配置文件是否可读，不执行配置校验或重启。
+func checkProxyServers(servers []*config.ProxyServerConfig) []doctorResult {
+	results := make([]doctorResult, 0, len(servers))
+	for _, server := range servers {
+		name := "代理服务 " + server.Name
+		if _, err := os.Stat(server.Config); err != nil {
+			results = append(results, failDoctor(name, fmt.Sprintf("配置文件不可读: %v", err)))
+			continue
+		}
+		results = append(results, okDoctor(name, fmt.Sprintf("%s %s (%s)", server.Kind, server.Config, server.Service)))
+	}
+	return results
+}
+
+// checkDatabases 检查每个数据库实例的证书目录和客户端命令是否存在，不连接数据库也不重新加载证书。
+func checkDatabases(databases []*config.DatabaseConfig) []doctorResult {
+	results := make([]doctorResult, 0, len(databases))
+	for _, database := range databases {
+		name := "数据库 " + database.Name
+		if _, err := os.Stat(filepath.Dir(database.CertFile)); err != nil {
+			results = append(results, failDoctor(name, fmt.Sprintf("证书目录不可用: %v", err)))
+			continue
+		}
+		if database.Binary != "" {
+			if _, err := exec.LookPath(database.Binary); err != nil {
+				results = append(results, failDoctor(name, fmt.Sprintf("未找到客户端命令 %s", database.Binary)))
+				continue
+			}
+		}
+		results = append(results, okDoctor(name, fmt.Sprintf("%s %s (%s)", database.Kind, net.JoinHostPort(database.Host, strconv.Itoa(database.TLSPort)), database.CertFile)))
+	}
+	return results
+}
+
+// checkS3Servers 检查本机对象存储证书目录可写，SSH 实例只列出远程地址，不建立连接也不重启服务。
+func checkS3Servers(servers []*config.S3ServerConfig) []doctorResult {
+	results := make([]doctorResult, 0, len(servers))
+	for _, server := range servers {
+		name := "对象存储服务 " + server.Name
+		if !config.IsSSHConfigured(&server.SSHConfig) {
+			results = append(results, checkDeployDir(name, server.Path))
+			continue
+		}
+		if server.PrivateKeyPath != "" {
+			if _, err := os.Stat(server.PrivateKeyPath); err != nil {
+				results = append(results, failDoctor(name, fmt.Sprintf("私钥文件不可读: %v", err)))
+				continue
+			}
+		}
+		results = append(results, okDoctor(name, fmt.Sprintf("%s@%s:%d %s", server.Username, server.Host, server.Port, server.Path)))
+	}
+	return results
+}
+
+// checkMediaServers 检查每个媒体服务器的 PFX 所在目录是否存在，不调用服务器 API 也不重启服务。
+func checkMediaServers(servers []*config.MediaServerConfig) []doctorResult {
+	results := make([]doctorResult, 0, len(servers))
+	for _, server := range servers {
+		name := "媒体服务器 " + server.Name
+		if _, err := os.Stat(filepath.Dir(server.PFXFile)); err != nil {
+			results = append(results, failDoctor(name, fmt.Sprintf("PFX 所在目录不可用: %v", err)))
+			continue
+		}
+		results = append(results, okDoctor(name, fmt.Sprintf("%s %s (%s)", server.Kind, server.URL, server.PFXFile)))
+	}
+	return results
+}
+
 // okDoctor 创建成功诊断结果。
 func okDoctor(name, message string) doctorResult {
 	return doctorResult{Name: name, OK: true, Status: "PASS", Message: message}
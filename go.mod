@@ -11,6 +11,7 @@ require (
 	github.com/huaweicloud/huaweicloud-sdk-go-obs v3.26.6+incompatible
 	github.com/huaweicloud/huaweicloud-sdk-go-v3 v0.1.211
 	github.com/jdcloud-api/jdcloud-sdk-go v1.67.0
+	github.com/pavlo-v-chernykh/keystore-go/v4 v4.5.0
 	github.com/qiniu/go-sdk/v7 v7.27.0
 	github.com/spf13/cobra v1.10.2
 	github.com/spf13/viper v1.21.0
@@ -23,9 +24,11 @@ require (
 	github.com/tencentyun/cos-go-sdk-v5 v0.7.75
 	github.com/volcengine/ve-tos-golang-sdk/v2 v2.9.8
 	github.com/volcengine/volcengine-go-sdk v1.2.47
+	go.yaml.in/yaml/v3 v3.0.5
 	golang.org/x/crypto v0.55.0
 	golang.org/x/net v0.58.0
 	google.golang.org/protobuf v1.36.12
+	software.sslmate.com/src/go-pkcs12 v0.7.3
 )
 
 require (
@@ -65,7 +68,6 @@ require (
 	github.com/tjfoc/gmsm v1.4.1 // indirect
 	github.com/volcengine/volc-sdk-golang v1.0.254 // indirect
 	go.mongodb.org/mongo-driver v1.17.9 // indirect
-	go.yaml.in/yaml/v3 v3.0.5 // indirect
 	golang.org/x/sync v0.22.0 // indirect
 	golang.org/x/sys v0.47.0 // indirect
 	golang.org/x/text v0.41.0 // indirect
@@ -47,6 +47,18 @@ func (be *DeploymentExecutor) executeNonResourceDeployment(ctx context.Context,
 		case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_CERT:
 			// 部署证书到本地 apache
 			return be.handleApacheCertificateDeploy(ctx, domain, downloadURL)
+		case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_CADDY_CERT:
+			// 部署证书到本地 caddy
+			return be.handleCaddyCertificateDeploy(ctx, domain, downloadURL)
+		case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_HAPROXY_CERT:
+			// 部署证书到本地 haproxy
+			return be.handleHAProxyCertificateDeploy(ctx, domain, downloadURL)
+		case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_TRAEFIK_CERT:
+			// 部署证书到 traefik 文件 provider 目录
+			return be.handleTraefikCertificateDeploy(ctx, domain, downloadURL)
+		case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_JAVA_KEYSTORE_CERT:
+			// 部署证书到 java 密钥库目录
+			return be.handleJavaKeystoreCertificateDeploy(ctx, domain, downloadURL)
 		case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_OPENVPN_AS_CERT:
 			// 部署证书到 OpenVPN-AS
 			return be.handleOpenVPNASCertificateDeploy(ctx, domain, downloadURL)
@@ -68,6 +80,9 @@ func (be *DeploymentExecutor) executeNonResourceDeployment(ctx context.Context,
 		case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_CERT:
 			// 部署证书到雷池 WAF
 			return be.handleSafeLineCertificateDeploy(ctx, domain, downloadURL)
+		case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MAIL_CERT:
+			// 部署证书到本地 Postfix 和 Dovecot
+			return be.handleMailCertificateDeploy(ctx, domain, downloadURL)
 		default:
 			logger.Warn("不支持的部署类型", "deploymentType", deploymentType)
 			return fmt.Errorf("不支持的部署类型: %s", deploymentType.String())
@@ -124,6 +139,86 @@ func (be *DeploymentExecutor) handleApacheCertificateDeploy(ctx context.Context,
 	return nil
 }
 
+// handleCaddyCertificateDeploy 处理证书部署到本地 caddy
+func (be *DeploymentExecutor) handleCaddyCertificateDeploy(ctx context.Context, domain, downloadURL string) error {
+	if domain == "" {
+		return fmt.Errorf("域名不能为空")
+	}
+
+	deployer := be.newCertDeployer()
+	if err := deployer.DeployCertificateToCaddy(ctx, domain, downloadURL); err != nil {
+		logger.Error("Caddy证书部署失败", "error", err, "domain", domain)
+		return err
+	}
+
+	logger.Info("Caddy 证书部署成功", "domain", domain)
+	return nil
+}
+
+// handleHAProxyCertificateDeploy 处理证书部署到本地 haproxy
+func (be *DeploymentExecutor) handleHAProxyCertificateDeploy(ctx context.Context, domain, downloadURL string) error {
+	if domain == "" {
+		return fmt.Errorf("域名不能为空")
+	}
+
+	deployer := be.newCertDeployer()
+	if err := deployer.DeployCertificateToHAProxy(ctx, domain, downloadURL); err != nil {
+		logger.Error("HAProxy证书部署失败", "error", err, "domain", domain)
+		return err
+	}
+
+	logger.Info("HAProxy 证书部署成功", "domain", domain)
+	return nil
+}
+
+// handleMailCertificateDeploy 处理证书部署到本地 Postfix 和 Dovecot
+func (be *DeploymentExecutor) handleMailCertificateDeploy(ctx context.Context, domain, downloadURL string) error {
+	if domain == "" {
+		return fmt.Errorf("域名不能为空")
+	}
+
+	deployer := be.newCertDeployer()
+	if err := deployer.DeployCertificateToMail(ctx, domain, downloadURL); err != nil {
+		logger.Error("邮件服务证书部署失败", "error", err, "domain", domain)
+		return err
+	}
+
+	logger.Info("邮件服务证书部署成功", "domain", domain)
+	return nil
+}
+
+// handleTraefikCertificateDeploy 处理证书部署到 traefik 文件 provider 目录
+func (be *DeploymentExecutor) handleTraefikCertificateDeploy(ctx context.Context, domain, downloadURL string) error {
+	if domain == "" {
+		return fmt.Errorf("域名不能为空")
+	}
+
+	deployer := be.newCertDeployer()
+	if err := deployer.DeployCertificateToTraefik(ctx, domain, downloadURL); err != nil {
+		logger.Error("Traefik证书部署失败", "error", err, "domain", domain)
+		return err
+	}
+
+	logger.Info("Traefik 证书部署成功", "domain", domain)
+	return nil
+}
+
+// handleJavaKeystoreCertificateDeploy 处理证书转换为 java 密钥库并发布
+func (be *DeploymentExecutor) handleJavaKeystoreCertificateDeploy(ctx context.Context, domain, downloadURL string) error {
+	if domain == "" {
+		return fmt.Errorf("域名不能为空")
+	}
+
+	deployer := be.newCertDeployer()
+	if err := deployer.DeployCertificateToJavaKeystore(ctx, domain, downloadURL); err != nil {
+		logger.Error("Java密钥库证书部署失败", "error", err, "domain", domain)
+		return err
+	}
+
+	logger.Info("Java 密钥库证书部署成功", "domain", domain)
+	return nil
+}
+
 // handleOpenVPNASCertificateDeploy 处理证书部署到 OpenVPN-AS
 func (be *DeploymentExecutor) handleOpenVPNASCertificateDeploy(ctx context.Context, domain, downloadURL string) error {
 	if domain == "" {
@@ -131,6 +131,54 @@ func (be *DeploymentExecutor) executeDeploymentResource(ctx context.Context, req
 	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_WEBSITE_CERT {
 		return be.executeBTPanelWebsiteResource(ctx, request)
 	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_KUBERNETES_SECRET_CERT {
+		return be.executeKubernetesSecretResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_LOCAL_TARGET_CERT {
+		return be.executeLocalTargetResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SSH_CERT {
+		return be.executeSSHTargetResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SYNOLOGY_CERT {
+		return be.executeSynologyResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXMOX_CERT {
+		return be.executeProxmoxNodeResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FIREWALL_CERT {
+		return be.executeFirewallResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_SERVER_CERT {
+		return be.executeNginxServerResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_VHOST_CERT {
+		return be.executeApacheVhostResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DOCKER_CONTAINER_CERT {
+		return be.executeDockerContainerResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NPM_PROXY_HOST_CERT {
+		return be.executeNPMProxyHostResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXY_SERVER_CERT {
+		return be.executeProxyServerResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DATABASE_TLS_CERT {
+		return be.executeDatabaseResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_S3_SERVER_CERT {
+		return be.executeS3ServerResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MEDIA_SERVER_PFX {
+		return be.executeMediaServerResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_SITE_CERT {
+		return be.executeSafeLineSiteResource(ctx, request)
+	}
+	if request.DeploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_DEVICE_CERT {
+		return be.executeFeiNiuDeviceResource(ctx, request)
+	}
 
 	factory := be.deploymentResourceProviderFactory
 	var resourceProvider providers.DeploymentResourceProvider
@@ -189,6 +237,207 @@ func (be *DeploymentExecutor) executeBTPanelWebsiteResource(ctx context.Context,
 	return providers.DeploymentResult{Message: "宝塔网站证书部署成功"}, nil
 }
 
+// executeKubernetesSecretResource 在客户端本地重新解析 Secret 引用并写入 TLS 证书数据。
+func (be *DeploymentExecutor) executeKubernetesSecretResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("kubernetes Secret 部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToKubernetesSecret(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(
+			localDeploymentFailureMessage,
+			deploys.IsKubernetesErrorRetryable(err),
+			"",
+			err,
+		)
+	}
+	return providers.DeploymentResult{Message: "Kubernetes Secret 证书部署成功"}, nil
+}
+
+// executeLocalTargetResource 在客户端本地重新解析实例引用，发布证书并执行实例配置的命令。
+func (be *DeploymentExecutor) executeLocalTargetResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("本地部署实例平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToLocalTarget(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", err)
+	}
+	return providers.DeploymentResult{Message: "本地部署实例证书部署成功"}, nil
+}
+
+// executeSSHTargetResource 在客户端本地重新解析远程主机引用，通过 SSH 发布证书并执行校验和重载命令。
+func (be *DeploymentExecutor) executeSSHTargetResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("SSH 远程主机部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToSSHTarget(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(
+			localDeploymentFailureMessage,
+			deploys.IsSSHTargetErrorRetryable(err),
+			"",
+			err,
+		)
+	}
+	return providers.DeploymentResult{Message: "SSH 远程主机证书部署成功"}, nil
+}
+
+// executeSynologyResource 在客户端本地重新解析 DSM 证书引用，导入证书并导出回读校验。
+func (be *DeploymentExecutor) executeSynologyResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("群晖 DSM 部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToSynology(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(
+			localDeploymentFailureMessage,
+			deploys.IsSynologyErrorRetryable(err),
+			"",
+			err,
+		)
+	}
+	return providers.DeploymentResult{Message: "群晖 DSM 证书部署成功"}, nil
+}
+
+// executeProxmoxNodeResource 在客户端本地重新解析节点引用，上传自定义证书并回读节点指纹校验。
+func (be *DeploymentExecutor) executeProxmoxNodeResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("Proxmox VE 部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToProxmoxNode(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(
+			localDeploymentFailureMessage,
+			deploys.IsProxmoxErrorRetryable(err),
+			"",
+			err,
+		)
+	}
+	return providers.DeploymentResult{Message: "Proxmox VE 节点证书部署成功"}, nil
+}
+
+// executeFirewallResource 在客户端本地重新解析防火墙引用，导入证书、切换引用并删除旧证书。
+func (be *DeploymentExecutor) executeFirewallResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("防火墙部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToFirewall(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(
+			localDeploymentFailureMessage,
+			deploys.IsFirewallErrorRetryable(err),
+			"",
+			err,
+		)
+	}
+	return providers.DeploymentResult{Message: "防火墙证书部署成功"}, nil
+}
+
+// executeNginxServerResource 在客户端本地重新定位 server 块，发布证书并原位改写证书指令，校验失败时回滚。
+func (be *DeploymentExecutor) executeNginxServerResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("Nginx server 块部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToNginxServer(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", err)
+	}
+	return providers.DeploymentResult{Message: "Nginx server 块证书部署成功"}, nil
+}
+
+// executeApacheVhostResource 在客户端本地重新定位虚拟主机，发布证书并原子改写证书指令，校验失败时回滚。
+func (be *DeploymentExecutor) executeApacheVhostResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("Apache 虚拟主机部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToApacheVhost(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", err)
+	}
+	return providers.DeploymentResult{Message: "Apache 虚拟主机证书部署成功"}, nil
+}
+
+// executeDockerContainerResource 在客户端本地重新定位容器，发布证书后发送信号或重启并确认容器状态，失败时回滚。
+func (be *DeploymentExecutor) executeDockerContainerResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("Docker 容器部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToDockerContainer(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", err)
+	}
+	return providers.DeploymentResult{Message: "Docker 容器证书部署成功"}, nil
+}
+
+// executeNPMProxyHostResource 在客户端本地重新解析代理主机引用，上传自定义证书后把代理主机指向该证书。
+func (be *DeploymentExecutor) executeNPMProxyHostResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("Nginx Proxy Manager 部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToNPMProxyHost(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, deploys.IsNPMErrorRetryable(err), "", err)
+	}
+	return providers.DeploymentResult{Message: "Nginx Proxy Manager 代理主机证书部署成功"}, nil
+}
+
+// executeProxyServerResource 在客户端本地重新定位代理服务实例，替换配置引用的证书文件，校验并重启服务，失败时回滚。
+func (be *DeploymentExecutor) executeProxyServerResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("代理服务部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToProxyServer(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", err)
+	}
+	return providers.DeploymentResult{Message: "代理服务证书部署成功"}, nil
+}
+
+// executeDatabaseResource 在客户端本地重新定位数据库实例，写入证书文件并在线重新加载 TLS，握手校验失败时回滚。
+func (be *DeploymentExecutor) executeDatabaseResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("数据库部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToDatabase(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", err)
+	}
+	return providers.DeploymentResult{Message: "数据库 TLS 证书部署成功"}, nil
+}
+
+// executeS3ServerResource 在客户端本地重新定位对象存储服务实例，发布证书目录并重启服务，失败时恢复旧目录。
+func (be *DeploymentExecutor) executeS3ServerResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("对象存储服务部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToS3Server(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, deploys.IsS3ServerErrorRetryable(err), "", err)
+	}
+	return providers.DeploymentResult{Message: "对象存储服务证书部署成功"}, nil
+}
+
+// executeMediaServerResource 在客户端本地重新定位媒体服务器实例，写入 PFX 并通过 HTTP API 加载新证书，握手校验失败时回滚。
+func (be *DeploymentExecutor) executeMediaServerResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("媒体服务器部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToMediaServer(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", err)
+	}
+	return providers.DeploymentResult{Message: "媒体服务器证书部署成功"}, nil
+}
+
+// executeSafeLineSiteResource 在客户端本地重新定位雷池站点，新增或更新证书后绑定到该站点并回读确认。
+func (be *DeploymentExecutor) executeSafeLineSiteResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("雷池站点部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToSafeLineSite(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", err)
+	}
+	return providers.DeploymentResult{Message: "雷池站点证书部署成功"}, nil
+}
+
+// executeFeiNiuDeviceResource 在客户端本地重新定位飞牛设备，通过 SSH 发布证书并更新数据库和网关配置。
+func (be *DeploymentExecutor) executeFeiNiuDeviceResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
+	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", fmt.Errorf("飞牛设备部署平台不匹配"))
+	}
+	if err := deploys.DeployCertificateToFeiNiuDevice(deploys.WithRuntime(ctx, be.runtime), request.TargetRef, request.Domain, request.CertificatePEM, request.PrivateKeyPEM); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError(localDeploymentFailureMessage, false, "", err)
+	}
+	return providers.DeploymentResult{Message: "飞牛设备证书部署成功"}, nil
+}
+
 // executeOnePanelWebsiteResource 在客户端本地重新解析网站引用并精确替换所选网站证书。
 func (be *DeploymentExecutor) executeOnePanelWebsiteResource(ctx context.Context, request DeploymentExecutionRequest) (providers.DeploymentResult, error) {
 	if request.Provider != deployPB.Provider_PROVIDER_ANSSL_CLI {
@@ -235,7 +235,7 @@ func discoverLocalDeploymentResources(ctx context.Context, deploymentType deploy
 			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
 		}
 		resources, err := deploys.DiscoverOnePanelWebsiteResources(ctx)
-		if err != nil {
+		if err != nil && len(resources) == 0 {
 			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
 		}
 		result := make([]providers.DeploymentResource, 0, len(resources))
@@ -246,6 +246,10 @@ func discoverLocalDeploymentResources(ctx context.Context, deploymentType deploy
 			}
 			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Domain: resource.Domain, Domains: append([]string(nil), resource.Domains...), Protocol: resource.Protocol, Status: resource.Status, Availability: availability})
 		}
+		// 部分 1Panel 实例不可达时仍上报其他实例的网站。
+		if err != nil {
+			return providers.ResourceCatalogResult{Resources: result, Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_PARTIAL, Error: err}
+		}
 		return completedResourceCatalog(result)
 
 	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_WEBSITE_CERT:
@@ -253,7 +257,7 @@ func discoverLocalDeploymentResources(ctx context.Context, deploymentType deploy
 			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
 		}
 		resources, err := deploys.DiscoverBTPanelWebsiteResources(ctx)
-		if err != nil {
+		if err != nil && len(resources) == 0 {
 			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
 		}
 		result := make([]providers.DeploymentResource, 0, len(resources))
@@ -264,6 +268,266 @@ func discoverLocalDeploymentResources(ctx context.Context, deploymentType deploy
 			}
 			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Domain: resource.Domain, Domains: append([]string(nil), resource.Domains...), Protocol: resource.Protocol, Status: resource.Status, Availability: availability})
 		}
+		// 部分宝塔面板实例不可达时仍上报其他实例的网站。
+		if err != nil {
+			return providers.ResourceCatalogResult{Resources: result, Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_PARTIAL, Error: err}
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_KUBERNETES_SECRET_CERT:
+		if !deploys.IsKubernetesConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverKubernetesSecretResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			availability := deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY
+			if resource.Status == deploys.KubernetesSecretStatusUnsupported {
+				availability = deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_UNSUPPORTED
+			}
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Group: resource.Namespace, Domain: resource.Domain, Domains: append([]string(nil), resource.Domains...), Status: resource.Status, Availability: availability})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_LOCAL_TARGET_CERT:
+		if !deploys.IsLocalTargetConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverLocalTargetResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Group: resource.Path, Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SSH_CERT:
+		if !deploys.IsSSHTargetConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverSSHTargetResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Group: resource.Host, Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SYNOLOGY_CERT:
+		if !deploys.IsSynologyConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverSynologyCertificateResources(ctx)
+		if err != nil && len(resources) == 0 {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Group: resource.NAS, Domain: resource.Domain, Domains: append([]string(nil), resource.Domains...), Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		// 部分 NAS 不可达时仍上报其他 NAS 的证书。
+		if err != nil {
+			return providers.ResourceCatalogResult{Resources: result, Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_PARTIAL, Error: err}
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FIREWALL_CERT:
+		if !deploys.IsFirewallConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverFirewallResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Group: resource.Kind, Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_SERVER_CERT:
+		if !deploys.IsNginxServerConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverNginxServerResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Domain: resource.Domain, Domains: append([]string(nil), resource.Domains...), Group: resource.File, Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_VHOST_CERT:
+		if !deploys.IsApacheVhostConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverApacheVhostResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Domain: resource.Domain, Domains: append([]string(nil), resource.Domains...), Group: resource.File, Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DOCKER_CONTAINER_CERT:
+		if !deploys.IsDockerConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverDockerContainerResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Domain: resource.Domain, Domains: []string{resource.Domain}, Group: resource.Image, Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NPM_PROXY_HOST_CERT:
+		if !deploys.IsNPMConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverNPMProxyHostResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			availability := deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY
+			if resource.Status != "Enabled" {
+				availability = deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_STOPPED
+			}
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Domain: resource.Domain, Domains: append([]string(nil), resource.Domains...), Status: resource.Status, Availability: availability})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXY_SERVER_CERT:
+		if !deploys.IsProxyServerConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverProxyServerResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Group: resource.Kind, Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DATABASE_TLS_CERT:
+		if !deploys.IsDatabaseConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverDatabaseResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Group: resource.Kind, Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_S3_SERVER_CERT:
+		if !deploys.IsS3ServerConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverS3ServerResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Group: resource.Profile, Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MEDIA_SERVER_PFX:
+		if !deploys.IsMediaServerConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverMediaServerResources(ctx)
+		if err != nil {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Group: resource.Kind, Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_SITE_CERT:
+		if !deploys.IsSafeLineConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverSafeLineSiteResources(ctx)
+		if err != nil && len(resources) == 0 {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			availability := deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY
+			if resource.Status != "Enabled" {
+				availability = deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_STOPPED
+			}
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Domain: resource.Domain, Domains: append([]string(nil), resource.Domains...), Group: strings.Join(resource.Ports, ","), Protocol: resource.Protocol, Status: resource.Status, Availability: availability})
+		}
+		// 部分雷池实例不可达时仍上报其他实例的站点。
+		if err != nil {
+			return providers.ResourceCatalogResult{Resources: result, Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_PARTIAL, Error: err}
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_DEVICE_CERT:
+		if !deploys.IsFeiNiuDevicesConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverFeiNiuDeviceResources(ctx)
+		if err != nil && len(resources) == 0 {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Group: resource.Hostname, Status: resource.Status, Availability: deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY})
+		}
+		// 部分飞牛设备不可达时仍上报其他设备。
+		if err != nil {
+			return providers.ResourceCatalogResult{Resources: result, Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_PARTIAL, Error: err}
+		}
+		return completedResourceCatalog(result)
+
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXMOX_CERT:
+		if !deploys.IsProxmoxConfiguredWithContext(ctx) {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED}
+		}
+		resources, err := deploys.DiscoverProxmoxNodeResources(ctx)
+		if err != nil && len(resources) == 0 {
+			return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE, Error: err}
+		}
+		result := make([]providers.DeploymentResource, 0, len(resources))
+		for _, resource := range resources {
+			availability := deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY
+			if resource.Status != "online" {
+				availability = deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_STOPPED
+			}
+			result = append(result, providers.DeploymentResource{TargetRef: resource.TargetRef, Label: resource.Label, Group: resource.Cluster, Status: resource.Status, Availability: availability})
+		}
+		// 部分集群不可达时仍上报其他集群的节点。
+		if err != nil {
+			return providers.ResourceCatalogResult{Resources: result, Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_PARTIAL, Error: err}
+		}
 		return completedResourceCatalog(result)
 
 	default:
@@ -299,6 +563,27 @@ func deploymentHandlerSpecs() []deploymentHandlerSpec {
 		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_WEBSITE_CERT, required, anyDomain),
 		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_WEBSITE_CERT, required, anyDomain),
 		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_CERT, none, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_CADDY_CERT, none, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_HAPROXY_CERT, none, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_TRAEFIK_CERT, none, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_KUBERNETES_SECRET_CERT, required, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_JAVA_KEYSTORE_CERT, none, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_LOCAL_TARGET_CERT, required, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SSH_CERT, required, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SYNOLOGY_CERT, required, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXMOX_CERT, required, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FIREWALL_CERT, required, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_SERVER_CERT, required, anyDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_VHOST_CERT, required, anyDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DOCKER_CONTAINER_CERT, required, anyDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NPM_PROXY_HOST_CERT, required, anyDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXY_SERVER_CERT, required, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DATABASE_TLS_CERT, required, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_S3_SERVER_CERT, required, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MEDIA_SERVER_PFX, required, noDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_SITE_CERT, required, anyDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_DEVICE_CERT, required, anyDomain),
+		newDeploymentHandlerSpec(deployPB.Provider_PROVIDER_ANSSL_CLI, deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MAIL_CERT, none, noDomain),
 	}
 	for _, definition := range providerDefinitions {
 		if definition.UploadOnly {
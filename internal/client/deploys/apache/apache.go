@@ -6,12 +6,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"time"
 
 	"github.com/https-cert/deploy/internal/client/deploys/shared"
 	"github.com/https-cert/deploy/pkg/logger"
 )
 
+// apacheCommands 是按优先级查找的常见 Apache 控制命令，测试可以替换为模拟脚本。
+var apacheCommands = []string{"apachectl", "apache2ctl", "httpd"}
+
 // Deploy 部署证书到 Apache 目录。
 func Deploy(sourceDir, apachePath, folderName, safeDomain string) error {
 	return DeployWithContext(context.Background(), sourceDir, apachePath, folderName, safeDomain)
@@ -29,7 +31,8 @@ func DeployAndReloadWithContext(ctx context.Context, sourceDir, apachePath, fold
 
 // deployWithContext 执行 Apache 目录发布，并可选执行发布后校验与 reload。
 func deployWithContext(ctx context.Context, sourceDir, apachePath, folderName, safeDomain string, reload bool) error {
-	if err := shared.ValidateCertificateFiles(sourceDir, safeDomain); err != nil {
+	// 归档可以在 secondary/ 中携带第二组证书，两组证书随目录一起原子发布。
+	if err := shared.ValidateCertificateFileSet(sourceDir, safeDomain); err != nil {
 		return err
 	}
 
@@ -78,6 +81,12 @@ func GenerateApacheSSLConfig(apachePath, folderName, safeDomain string) error {
 	// 证书文件路径（使用用户配置的实际路径）
 	certPath := filepath.Join(certDir, "cert.pem")
 	keyPath := filepath.Join(certDir, "privateKey.key")
+	// 目录中存在第二组证书时同时列出，Apache 按客户端支持的算法选择 RSA 或 ECDSA 证书。
+	secondaryConfig := ""
+	if shared.HasSecondaryCertificate(certDir) {
+		secondaryDir := filepath.Join(certDir, shared.SecondaryCertificateDir)
+		secondaryConfig = fmt.Sprintf("SSLCertificateFile %s\nSSLCertificateKeyFile %s\n", filepath.Join(secondaryDir, "cert.pem"), filepath.Join(secondaryDir, "privateKey.key"))
+	}
 
 	// 生成配置内容
 	configContent := fmt.Sprintf(`# Apache SSL 证书配置 - %s
@@ -92,7 +101,7 @@ func GenerateApacheSSLConfig(apachePath, folderName, safeDomain string) error {
 SSLEngine on
 SSLCertificateFile %s
 SSLCertificateKeyFile %s
-
+%s
 # SSL 协议配置（推荐配置）
 SSLProtocol all -SSLv3 -TLSv1 -TLSv1.1
 
@@ -102,7 +111,7 @@ SSLHonorCipherOrder off
 
 # SSL 会话配置
 SSLSessionTickets off
-`, safeDomain, configFile, certPath, keyPath)
+`, safeDomain, configFile, certPath, keyPath, secondaryConfig)
 
 	// 写入配置文件
 	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
@@ -116,8 +125,6 @@ SSLSessionTickets off
 
 // IsApacheAvailable 检查apache是否可用
 func IsApacheAvailable() bool {
-	// 检查常见的 Apache 命令名
-	apacheCommands := []string{"apachectl", "apache2ctl", "httpd"}
 	for _, cmd := range apacheCommands {
 		if _, err := exec.LookPath(cmd); err == nil {
 			return true
@@ -128,7 +135,6 @@ func IsApacheAvailable() bool {
 
 // GetApacheCommand 获取可用的 Apache 控制命令
 func GetApacheCommand() string {
-	apacheCommands := []string{"apachectl", "apache2ctl", "httpd"}
 	for _, cmd := range apacheCommands {
 		if _, err := exec.LookPath(cmd); err == nil {
 			return cmd
@@ -144,7 +150,7 @@ func TestApacheConfig() error {
 
 // TestApacheConfigWithContext 使用调用方上下文测试 Apache 配置。
 func TestApacheConfigWithContext(parent context.Context) error {
-	ctx, cancel := context.WithTimeout(parent, 10*time.Second)
+	ctx, cancel := context.WithTimeout(parent, apacheCommandTimeout)
 	defer cancel()
 
 	apacheCmd := GetApacheCommand()
@@ -168,7 +174,7 @@ func ReloadApache() error {
 
 // ReloadApacheWithContext 使用调用方上下文重新加载 Apache。
 func ReloadApacheWithContext(parent context.Context) error {
-	ctx, cancel := context.WithTimeout(parent, 10*time.Second)
+	ctx, cancel := context.WithTimeout(parent, apacheCommandTimeout)
 	defer cancel()
 
 	apacheCmd := GetApacheCommand()
@@ -0,0 +1,108 @@
+package apache
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+)
+
+// TestDeployPublishesSecondaryCertificatePair 验证 secondary/ 中的 ECDSA 证书随主证书一起发布，片段同时列出两组证书，同算法的第二组证书被拒绝。
+func TestDeployPublishesSecondaryCertificatePair(t *testing.T) {
+	root := t.TempDir()
+	sourceDir := filepath.Join(root, "source")
+	secondaryDir := filepath.Join(sourceDir, shared.SecondaryCertificateDir)
+	rsaCertificate, rsaKey := generateTestCertificatePair(t, "example.com")
+	ecdsaCertificate, ecdsaKey := generateTestECDSACertificatePair(t, "example.com")
+	writeSource := func(secondaryCertificate, secondaryKey string) {
+		writeTestFile(t, filepath.Join(sourceDir, "cert.pem"), rsaCertificate)
+		writeTestFile(t, filepath.Join(sourceDir, "privateKey.key"), rsaKey)
+		writeTestFile(t, filepath.Join(secondaryDir, "cert.pem"), secondaryCertificate)
+		if secondaryKey != "" {
+			writeTestFile(t, filepath.Join(secondaryDir, "privateKey.key"), secondaryKey)
+		}
+	}
+	writeSource(ecdsaCertificate, ecdsaKey)
+	apachePath := filepath.Join(root, "ssl")
+
+	if err := Deploy(sourceDir, apachePath, "example.com", "example.com"); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	targetDir := filepath.Join(apachePath, "example.com")
+	if readTestFile(t, filepath.Join(targetDir, shared.SecondaryCertificateDir, "privateKey.key")) != ecdsaKey {
+		t.Fatal("第二组证书未发布")
+	}
+	snippet := readTestFile(t, filepath.Join(targetDir, "example.com.ssl.conf"))
+	expected := "SSLCertificateFile " + filepath.Join(targetDir, "cert.pem") + "\n" +
+		"SSLCertificateKeyFile " + filepath.Join(targetDir, "privateKey.key") + "\n" +
+		"SSLCertificateFile " + filepath.Join(targetDir, shared.SecondaryCertificateDir, "cert.pem") + "\n" +
+		"SSLCertificateKeyFile " + filepath.Join(targetDir, shared.SecondaryCertificateDir, "privateKey.key") + "\n"
+	if !strings.Contains(snippet, expected) {
+		t.Fatalf("片段应列出两组证书:\n%s", snippet)
+	}
+
+	// 第二组证书与主证书算法相同或缺少私钥时整体拒绝，已发布的目录和片段保持不变。
+	writeSource(rsaCertificate, rsaKey)
+	if err := Deploy(sourceDir, apachePath, "example.com", "example.com"); err == nil || !strings.Contains(err.Error(), "相同的公钥算法") {
+		t.Fatalf("同算法的第二组证书应被拒绝: %v", err)
+	}
+	os.RemoveAll(sourceDir)
+	writeSource(ecdsaCertificate, "")
+	if err := Deploy(sourceDir, apachePath, "example.com", "example.com"); err == nil || !strings.Contains(err.Error(), "第二组证书不可用") {
+		t.Fatalf("缺少私钥的第二组证书应被拒绝: %v", err)
+	}
+	if readTestFile(t, filepath.Join(targetDir, shared.SecondaryCertificateDir, "cert.pem")) != ecdsaCertificate || readTestFile(t, filepath.Join(targetDir, "example.com.ssl.conf")) != snippet {
+		t.Fatal("校验失败后不应覆盖已发布的证书和片段")
+	}
+
+	// 之后的归档不再携带第二组证书时，片段只保留主证书。
+	os.RemoveAll(sourceDir)
+	writeTestFile(t, filepath.Join(sourceDir, "cert.pem"), rsaCertificate)
+	writeTestFile(t, filepath.Join(sourceDir, "privateKey.key"), rsaKey)
+	if err := Deploy(sourceDir, apachePath, "example.com", "example.com"); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	if snippet := readTestFile(t, filepath.Join(targetDir, "example.com.ssl.conf")); strings.Contains(snippet, shared.SecondaryCertificateDir) {
+		t.Fatalf("没有第二组证书时片段不应引用 secondary/:\n%s", snippet)
+	}
+}
+
+// generateTestECDSACertificatePair 生成 Apache 测试使用的 ECDSA 自签证书和匹配私钥。
+func generateTestECDSACertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -0,0 +1,138 @@
+package apache
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+)
+
+// apacheVhostLocationPattern 匹配 apachectl -S 输出行末尾的 (文件:行号)。
+var apacheVhostLocationPattern = regexp.MustCompile(`\(([^()]+):(\d+)\)\s*$`)
+
+// apacheVirtualHost 描述配置文件中的一个 VirtualHost 块。
+type apacheVirtualHost struct {
+	Addresses    []string // Addresses 是 <VirtualHost> 的地址参数。
+	Start        int      // Start 是 <VirtualHost> 所在行的下标。
+	End          int      // End 是 </VirtualHost> 所在行的下标。
+	ServerName   string   // ServerName 是 ServerName 指令的值。
+	ServerAlias  []string // ServerAlias 是全部 ServerAlias 指令的值。
+	Certificates []int    // Certificates 是 SSLCertificateFile 所在行的下标。
+	Keys         []int    // Keys 是 SSLCertificateKeyFile 所在行的下标。
+	Chains       []int    // Chains 是 SSLCertificateChainFile 所在行的下标。
+}
+
+// parseApacheVhostFiles 从 apachectl -S 输出中提取声明了 443 端口虚拟主机的配置文件，按首次出现的顺序返回。
+func parseApacheVhostFiles(output string) []string {
+	files := make([]string, 0)
+	seen := make(map[string]struct{})
+	address := ""
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// 顶格的地址行开启新的地址分组，缩进的 namevhost 行沿用该地址。
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			address = fields[0]
+		}
+		if !isApacheHTTPSAddress(address) && !(len(fields) > 2 && fields[0] == "port" && fields[1] == "443") {
+			continue
+		}
+		match := apacheVhostLocationPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if _, exists := seen[match[1]]; exists {
+			continue
+		}
+		seen[match[1]] = struct{}{}
+		files = append(files, match[1])
+	}
+	return files
+}
+
+// isApacheHTTPSAddress 判断虚拟主机地址是否监听 443 端口。
+func isApacheHTTPSAddress(address string) bool {
+	return strings.HasSuffix(address, ":443")
+}
+
+// parseApacheVirtualHosts 按行解析配置文件中的 VirtualHost 块及其证书相关指令。
+func parseApacheVirtualHosts(lines []string) []apacheVirtualHost {
+	hosts := make([]apacheVirtualHost, 0)
+	var current *apacheVirtualHost
+	for index, line := range lines {
+		fields := splitApacheArguments(strings.TrimSpace(line))
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if current == nil {
+			if name == "<virtualhost" {
+				addresses := make([]string, 0, len(fields)-1)
+				for _, field := range fields[1:] {
+					if field = strings.TrimSuffix(field, ">"); field != "" {
+						addresses = append(addresses, field)
+					}
+				}
+				hosts = append(hosts, apacheVirtualHost{Addresses: addresses, Start: index, End: -1})
+				current = &hosts[len(hosts)-1]
+			}
+			continue
+		}
+		switch name {
+		case "</virtualhost>":
+			current.End = index
+			current = nil
+		case "servername":
+			if len(fields) > 1 {
+				current.ServerName = fields[1]
+			}
+		case "serveralias":
+			current.ServerAlias = append(current.ServerAlias, fields[1:]...)
+		case "sslcertificatefile":
+			current.Certificates = append(current.Certificates, index)
+		case "sslcertificatekeyfile":
+			current.Keys = append(current.Keys, index)
+		case "sslcertificatechainfile":
+			current.Chains = append(current.Chains, index)
+		}
+	}
+	// 未闭合的块无法安全改写，直接丢弃。
+	if current != nil {
+		hosts = hosts[:len(hosts)-1]
+	}
+	return hosts
+}
+
+// splitApacheArguments 按空白切分指令参数，保留双引号内的空白。
+func splitApacheArguments(line string) []string {
+	fields := make([]string, 0)
+	var current strings.Builder
+	quoted, started := false, false
+	for index := 0; index < len(line); index++ {
+		character := line[index]
+		switch {
+		case character == '\\' && quoted && index+1 < len(line):
+			index++
+			current.WriteByte(line[index])
+		case character == '"':
+			quoted = !quoted
+			started = true
+		case (character == ' ' || character == '\t') && !quoted:
+			if started {
+				fields = append(fields, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteByte(character)
+			started = true
+		}
+	}
+	if started {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
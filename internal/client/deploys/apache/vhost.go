@@ -0,0 +1,487 @@
+package apache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	apacheVhostTargetPrefix = "apache-vhost-"
+	apacheBackupDir         = ".anssl-backup"
+	apacheCommandTimeout    = 10 * time.Second
+	// ApacheVhostStatusReady 表示虚拟主机监听 443 端口，可以直接替换证书。
+	ApacheVhostStatusReady = "Ready"
+)
+
+// apacheConfigLock 串行化配置文件改写，避免两个域名同时部署时互相覆盖备份和校验结果。
+var apacheConfigLock sync.Mutex
+
+// ApacheVhostResource 是可以安全上报到 anSSL 后端的 Apache 虚拟主机资源。
+type ApacheVhostResource struct {
+	TargetRef string   // TargetRef 是客户端根据配置文件、虚拟主机地址和域名生成的不透明稳定引用。
+	Label     string   // Label 是 ServerName，未设置时为配置文件名。
+	Domain    string   // Domain 是首个可用于证书匹配的域名。
+	Domains   []string // Domains 是 ServerName 和 ServerAlias 中的全部域名。
+	File      string   // File 是虚拟主机所在的配置文件。
+	Status    string   // Status 是虚拟主机状态。
+}
+
+// apacheVhostRecord 在 deploy 内部关联脱敏资源和虚拟主机位置。
+type apacheVhostRecord struct {
+	Resource  ApacheVhostResource // Resource 是可以上报的脱敏资源。
+	Host      apacheVirtualHost   // Host 是虚拟主机在配置文件中的位置。
+	Ambiguous bool                // Ambiguous 表示同一文件中有多个虚拟主机生成相同引用。
+}
+
+// IsApacheVhostConfiguredWithContext 从 context 快照判断是否配置了 Apache 证书目录。
+func IsApacheVhostConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && configuration.SSL.ApachePath != ""
+}
+
+// DiscoverApacheVhostResources 通过 apachectl -S 列出全部监听 443 端口的虚拟主机。
+func DiscoverApacheVhostResources(ctx context.Context) ([]ApacheVhostResource, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	files, err := dumpApacheVhostFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]ApacheVhostResource, 0)
+	for _, file := range files {
+		records, err := loadApacheVhostRecords(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			resources = append(resources, record.Resource)
+		}
+	}
+	return resources, nil
+}
+
+// TestApacheVhostConnection 确认虚拟主机仍可定位、配置目录和证书目录可写，且当前配置可以通过 apachectl -t。
+func TestApacheVhostConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	record, err := findApacheVhost(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	file, err := filepath.EvalSymlinks(record.Resource.File)
+	if err != nil {
+		return fmt.Errorf("解析 Apache 配置文件路径失败: %w", err)
+	}
+	// 改写通过同目录临时文件重命名完成，因此需要配置文件所在目录可写。
+	for _, dir := range []string{filepath.Dir(file), shared.ConfigurationFromContext(ctx).SSL.ApachePath} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建目录失败: %w", err)
+		}
+		probe, err := os.CreateTemp(dir, ".anssl-probe-*")
+		if err != nil {
+			return fmt.Errorf("apache 目录不可写: %w", err)
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+	}
+	return TestApacheConfigWithContext(ctx)
+}
+
+// DeployCertificateToApacheVhost 发布证书到 apachePath/<域名>/，原子改写所选虚拟主机的证书指令，校验并 graceful reload，失败时恢复配置和旧证书。
+func DeployCertificateToApacheVhost(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	_, safeDomain, err := shared.NormalizeDeploymentDomain(domain)
+	if err != nil {
+		return err
+	}
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || configuration.SSL.ApachePath == "" {
+		return errors.New("未配置 Apache SSL 目录 (ssl.apachePath)")
+	}
+	apachePath := configuration.SSL.ApachePath
+
+	apacheConfigLock.Lock()
+	defer apacheConfigLock.Unlock()
+	record, err := findApacheVhost(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+
+	stagingDir, err := os.MkdirTemp("", "anssl-apache-*")
+	if err != nil {
+		return fmt.Errorf("创建 Apache 临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	if err := os.WriteFile(filepath.Join(stagingDir, "cert.pem"), []byte(certificatePEM), 0o644); err != nil {
+		return fmt.Errorf("写入证书文件失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(stagingDir, "privateKey.key"), []byte(privateKeyPEM), 0o600); err != nil {
+		return fmt.Errorf("写入私钥文件失败: %w", err)
+	}
+	if err := os.MkdirAll(apachePath, 0755); err != nil {
+		return fmt.Errorf("创建SSL目录失败: %w", err)
+	}
+	targetDir, err := shared.SafeJoinUnderBase(apachePath, safeDomain)
+	if err != nil {
+		return err
+	}
+
+	return shared.PublishDirectoryWithValidationContext(ctx, stagingDir, targetDir, func() error {
+		// 同时生成 Include 片段，已手动引用片段的其他虚拟主机继续生效。
+		if err := GenerateApacheSSLConfig(apachePath, safeDomain, safeDomain); err != nil {
+			return fmt.Errorf("生成Apache SSL配置失败: %w", err)
+		}
+		return rewriteApacheVhost(ctx, record, apachePath, filepath.Join(targetDir, "cert.pem"), filepath.Join(targetDir, "privateKey.key"))
+	})
+}
+
+// rewriteApacheVhost 备份并原子改写虚拟主机的证书指令，apachectl -t 或 graceful 失败时恢复原文件。
+func rewriteApacheVhost(ctx context.Context, record *apacheVhostRecord, apachePath, certPath, keyPath string) error {
+	// sites-enabled 中的配置通常是符号链接，必须改写链接指向的文件而不是替换链接本身。
+	file, err := filepath.EvalSymlinks(record.Resource.File)
+	if err != nil {
+		return fmt.Errorf("解析 Apache 配置文件路径失败: %w", err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("读取 Apache 配置文件失败: %w", err)
+	}
+	original, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("读取 Apache 配置文件失败: %w", err)
+	}
+	// 定位后文件可能被其他进程修改，按当前内容重新定位虚拟主机。
+	current, err := selectApacheVhostRecord(parseApacheVhostRecords(record.Resource.File, string(original)), record.Resource.TargetRef)
+	if err != nil {
+		return err
+	}
+	updated, err := replaceApacheCertificateDirectives(string(original), current.Host, certPath, keyPath)
+	if err != nil {
+		return err
+	}
+	if updated == string(original) {
+		logger.Info("Apache 虚拟主机已指向证书目录，仅重新加载", "file", file, "vhost", record.Resource.Label)
+	} else {
+		if err := backupApacheConfigFile(apachePath, file, original); err != nil {
+			return fmt.Errorf("备份 Apache 配置文件失败: %w", err)
+		}
+		if err := replaceApacheConfigFile(file, []byte(updated), info); err != nil {
+			return fmt.Errorf("写入 Apache 配置文件失败: %w", err)
+		}
+	}
+	restore := func(cause error) error {
+		if updated == string(original) {
+			return cause
+		}
+		if err := replaceApacheConfigFile(file, original, info); err != nil {
+			return errors.Join(cause, fmt.Errorf("恢复 Apache 配置文件失败: %w", err))
+		}
+		return cause
+	}
+	if err := TestApacheConfigWithContext(ctx); err != nil {
+		return restore(fmt.Errorf("apache配置测试失败: %w", err))
+	}
+	if err := ReloadApacheWithContext(ctx); err != nil {
+		return restore(fmt.Errorf("apache重新加载失败: %w", err))
+	}
+	logger.Info("Apache 虚拟主机证书已更新", "file", file, "vhost", record.Resource.Label)
+	return nil
+}
+
+// replaceApacheCertificateDirectives 把虚拟主机的证书指令改为新路径，块内没有证书指令时在块首插入。
+// 只有一组证书指令时同时删除 SSLCertificateChainFile；有多组证书指令（例如 RSA 与 ECDSA 双证书）时只改写已指向托管文件的一组，
+// 其余组和链文件原样保留，没有任何一组指向托管文件时拒绝改写。
+func replaceApacheCertificateDirectives(content string, host apacheVirtualHost, certPath, keyPath string) (string, error) {
+	lines := strings.Split(content, "\n")
+	certDirective := "SSLCertificateFile " + quoteApacheValue(certPath)
+	keyDirective := "SSLCertificateKeyFile " + quoteApacheValue(keyPath)
+	replacements := make(map[int][]string)
+	if len(host.Certificates) > 1 || len(host.Keys) > 1 {
+		index := managedApacheCertificatePair(lines, host, certPath, keyPath)
+		if index < 0 {
+			return "", fmt.Errorf("虚拟主机包含 %d 组 SSLCertificateFile 指令且没有一组指向 %s，请先将需要托管的一组改为该路径", len(host.Certificates), certPath)
+		}
+		certLine, keyLine := host.Certificates[index], host.Keys[index]
+		replacements[certLine] = []string{apacheLineIndent(lines[certLine]) + certDirective}
+		replacements[keyLine] = []string{apacheLineIndent(lines[keyLine]) + keyDirective}
+		return applyApacheLineReplacements(lines, replacements), nil
+	}
+
+	// cert.pem 已包含完整证书链，Apache 2.4.8 起不再需要单独的链文件。
+	for _, index := range host.Chains {
+		replacements[index] = nil
+	}
+	// 只有其中一种指令时，把缺少的指令放在已有指令之后；都没有时放在 <VirtualHost> 之后。
+	switch {
+	case len(host.Certificates) == 0 && len(host.Keys) == 0:
+		indent := apacheVhostIndent(lines, host)
+		replacements[host.Start] = []string{lines[host.Start], indent + certDirective, indent + keyDirective}
+	case len(host.Certificates) == 0:
+		replacements[host.Keys[0]] = []string{apacheLineIndent(lines[host.Keys[0]]) + keyDirective, apacheLineIndent(lines[host.Keys[0]]) + certDirective}
+	case len(host.Keys) == 0:
+		replacements[host.Certificates[0]] = []string{apacheLineIndent(lines[host.Certificates[0]]) + certDirective, apacheLineIndent(lines[host.Certificates[0]]) + keyDirective}
+	default:
+		replacements[host.Certificates[0]] = []string{apacheLineIndent(lines[host.Certificates[0]]) + certDirective}
+		replacements[host.Keys[0]] = []string{apacheLineIndent(lines[host.Keys[0]]) + keyDirective}
+	}
+	return applyApacheLineReplacements(lines, replacements), nil
+}
+
+// managedApacheCertificatePair 按出现顺序配对证书和私钥指令，返回证书或私钥已指向托管文件的那一组，找不到或配对不完整时返回 -1。
+func managedApacheCertificatePair(lines []string, host apacheVirtualHost, certPath, keyPath string) int {
+	if len(host.Certificates) != len(host.Keys) {
+		return -1
+	}
+	for index := range host.Certificates {
+		if apacheDirectiveValue(lines[host.Certificates[index]]) == certPath || apacheDirectiveValue(lines[host.Keys[index]]) == keyPath {
+			return index
+		}
+	}
+	return -1
+}
+
+// apacheDirectiveValue 返回单参数指令去掉引号后的值。
+func apacheDirectiveValue(line string) string {
+	fields := splitApacheArguments(strings.TrimSpace(line))
+	if len(fields) != 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// applyApacheLineReplacements 按行下标替换或删除配置行，保持原文件的换行风格。
+func applyApacheLineReplacements(lines []string, replacements map[int][]string) string {
+	updated := make([]string, 0, len(lines)+2)
+	for index, line := range lines {
+		replacement, exists := replacements[index]
+		if !exists {
+			updated = append(updated, line)
+			continue
+		}
+		// Windows 换行的配置文件保持 \r\n 风格。
+		carriage := strings.HasSuffix(line, "\r")
+		for _, text := range replacement {
+			if carriage && !strings.HasSuffix(text, "\r") {
+				text += "\r"
+			}
+			updated = append(updated, text)
+		}
+	}
+	return strings.Join(updated, "\n")
+}
+
+// apacheLineIndent 返回行首缩进。
+func apacheLineIndent(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// apacheVhostIndent 返回虚拟主机首条指令的缩进，块为空时在 <VirtualHost> 缩进基础上增加四个空格。
+func apacheVhostIndent(lines []string, host apacheVirtualHost) string {
+	for _, line := range lines[host.Start+1 : host.End] {
+		if strings.TrimSpace(line) != "" {
+			return apacheLineIndent(line)
+		}
+	}
+	return apacheLineIndent(lines[host.Start]) + "    "
+}
+
+// quoteApacheValue 在路径包含空白或引号时使用双引号。
+func quoteApacheValue(value string) string {
+	if !strings.ContainsAny(value, " \t\"\\") {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// findApacheVhost 重新执行 apachectl -S 定位 targetRef 对应的虚拟主机。
+func findApacheVhost(ctx context.Context, targetRef string) (*apacheVhostRecord, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("apache 虚拟主机 targetRef 不能为空")
+	}
+	if !IsApacheVhostConfiguredWithContext(ctx) {
+		return nil, errors.New("未配置 Apache SSL 目录 (ssl.apachePath)")
+	}
+	files, err := dumpApacheVhostFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		records, err := loadApacheVhostRecords(file)
+		if err != nil {
+			return nil, err
+		}
+		if record, err := selectApacheVhostRecord(records, targetRef); err == nil {
+			return record, nil
+		} else if !errors.Is(err, errApacheVhostNotFound) {
+			return nil, err
+		}
+	}
+	return nil, errApacheVhostNotFound
+}
+
+// errApacheVhostNotFound 表示虚拟主机已删除或 ServerName、地址已修改。
+var errApacheVhostNotFound = errors.New("apache 虚拟主机不存在或 ServerName、监听地址已修改，请重新配置部署目标")
+
+// selectApacheVhostRecord 在单个配置文件的记录中查找 targetRef。
+func selectApacheVhostRecord(records []apacheVhostRecord, targetRef string) (*apacheVhostRecord, error) {
+	for index := range records {
+		if records[index].Resource.TargetRef != targetRef {
+			continue
+		}
+		if records[index].Ambiguous {
+			return nil, errors.New("同一配置文件中有多个虚拟主机使用相同的 ServerName 和监听地址，无法确定部署目标")
+		}
+		record := records[index]
+		return &record, nil
+	}
+	return nil, errApacheVhostNotFound
+}
+
+// loadApacheVhostRecords 读取并解析单个配置文件。
+func loadApacheVhostRecords(file string) ([]apacheVhostRecord, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取 Apache 配置文件失败: %w", err)
+	}
+	return parseApacheVhostRecords(file, string(content)), nil
+}
+
+// parseApacheVhostRecords 把配置文件中监听 443 端口的虚拟主机转换为记录。
+func parseApacheVhostRecords(file, content string) []apacheVhostRecord {
+	hosts := parseApacheVirtualHosts(strings.Split(content, "\n"))
+	records := make([]apacheVhostRecord, 0, len(hosts))
+	refs := make(map[string]int, len(hosts))
+	for _, host := range hosts {
+		addresses := apacheHTTPSAddresses(host.Addresses)
+		if len(addresses) == 0 {
+			continue
+		}
+		names := append([]string{host.ServerName}, host.ServerAlias...)
+		domains := apacheVhostDomains(names)
+		targetRef := buildApacheVhostTargetRef(file, names, addresses)
+		if index, exists := refs[targetRef]; exists {
+			records[index].Ambiguous = true
+			continue
+		}
+		label := filepath.Base(file)
+		if host.ServerName != "" {
+			label = host.ServerName
+		}
+		resource := ApacheVhostResource{TargetRef: targetRef, Label: label, Domains: domains, File: file, Status: ApacheVhostStatusReady}
+		if len(domains) > 0 {
+			resource.Domain = domains[0]
+		}
+		refs[targetRef] = len(records)
+		records = append(records, apacheVhostRecord{Resource: resource, Host: host})
+	}
+	return records
+}
+
+// apacheHTTPSAddresses 返回监听 443 端口的地址，已排序。
+func apacheHTTPSAddresses(addresses []string) []string {
+	matched := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if isApacheHTTPSAddress(address) {
+			matched = append(matched, address)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+// apacheVhostDomains 从 ServerName 和 ServerAlias 中提取可用于证书匹配的域名，忽略端口、协议前缀和非通配符的模式。
+func apacheVhostDomains(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	domains := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if index := strings.Index(name, "://"); index >= 0 {
+			name = name[index+3:]
+		}
+		if index := strings.LastIndexByte(name, ':'); index >= 0 {
+			name = name[:index]
+		}
+		name = strings.TrimSuffix(name, ".")
+		if name == "" || strings.Contains(name, "?") || strings.Contains(name, "$") || strings.Contains(strings.TrimPrefix(name, "*."), "*") {
+			continue
+		}
+		if _, exists := seen[name]; exists {
+			continue
+		}
+		seen[name] = struct{}{}
+		domains = append(domains, name)
+	}
+	return domains
+}
+
+// buildApacheVhostTargetRef 根据配置文件、ServerName、ServerAlias 和 443 端口地址生成稳定的不透明引用。
+func buildApacheVhostTargetRef(file string, serverNames, addresses []string) string {
+	names := append([]string(nil), serverNames...)
+	sort.Strings(names)
+	identity := strings.Join([]string{
+		"ansslCli",
+		"DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_VHOST_CERT",
+		filepath.Clean(file),
+		strings.Join(names, ","),
+		strings.Join(addresses, ","),
+	}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return apacheVhostTargetPrefix + hex.EncodeToString(digest[:12])
+}
+
+// dumpApacheVhostFiles 执行 apachectl -S 并返回声明了 443 端口虚拟主机的配置文件。
+func dumpApacheVhostFiles(parent context.Context) ([]string, error) {
+	apacheCmd := GetApacheCommand()
+	if apacheCmd == "" {
+		return nil, errors.New("未找到Apache控制命令")
+	}
+	ctx, cancel := context.WithTimeout(parent, apacheCommandTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, apacheCmd, "-S").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("执行 %s -S 失败: %w\n%s", apacheCmd, err, strings.TrimSpace(string(output)))
+	}
+	return parseApacheVhostFiles(string(output)), nil
+}
+
+// replaceApacheConfigFile 在同目录写入临时文件后重命名覆盖并沿用原文件权限和属主，Apache 任何时候读取都只会看到完整的新旧版本之一。
+func replaceApacheConfigFile(file string, content []byte, info os.FileInfo) error {
+	uid, gid, ok := shared.FileOwnership(info)
+	if !ok {
+		uid, gid = -1, -1
+	}
+	return shared.ReplaceFile(file, content, info.Mode().Perm(), uid, gid)
+}
+
+// backupApacheConfigFile 把改写前的配置保存到 apachePath/.anssl-backup，避免备份文件被 IncludeOptional 通配符加载。
+func backupApacheConfigFile(apachePath, file string, content []byte) error {
+	backupDir := filepath.Join(apachePath, apacheBackupDir)
+	if err := os.MkdirAll(backupDir, 0o700); err != nil {
+		return err
+	}
+	name := strings.ReplaceAll(strings.TrimPrefix(filepath.ToSlash(filepath.Clean(file)), "/"), "/", "_")
+	return os.WriteFile(filepath.Join(backupDir, name), content, 0o600)
+}
@@ -0,0 +1,232 @@
+package apache
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+const testApacheSiteConfig = `# 站点配置
+<VirtualHost *:80>
+    ServerName example.com
+    Redirect permanent / https://example.com/
+</VirtualHost>
+
+<IfModule mod_ssl.c>
+<VirtualHost *:443 [::]:443>
+    ServerName example.com
+    ServerAlias www.example.com *.cdn.example.com
+    SSLEngine on
+    SSLCertificateFile "/old/cert.pem"
+    SSLCertificateKeyFile /old/key.pem
+    SSLCertificateChainFile /old/chain.pem
+    SSLCertificateFile /old/ecc.pem
+    SSLCertificateKeyFile /old/ecc.key
+    <Directory /var/www>
+        Require all granted
+    </Directory>
+</VirtualHost>
+</IfModule>
+`
+
+// TestDeployCertificateToApacheVhostRewritesDirectivesAtomically 验证从 apachectl -S 发现 443 虚拟主机、改写符号链接指向的配置、保留备份并执行校验和 graceful；
+// 双证书虚拟主机只改写指向托管文件的一组，没有托管组时拒绝改写。
+func TestDeployCertificateToApacheVhostRewritesDirectivesAtomically(t *testing.T) {
+	root := t.TempDir()
+	available := filepath.Join(root, "sites-available", "example.conf")
+	enabled := filepath.Join(root, "sites-enabled", "example.conf")
+	writeTestFile(t, available, testApacheSiteConfig)
+	if err := os.MkdirAll(filepath.Dir(enabled), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.Symlink(available, enabled); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	stateDir := installFakeApachectl(t, "VirtualHost configuration:\n"+
+		"*:80                   example.com ("+enabled+":2)\n"+
+		"*:443                  is a NameVirtualHost\n"+
+		"         default server example.com ("+enabled+":8)\n"+
+		"         port 443 namevhost example.com ("+enabled+":8)\n"+
+		"                 alias www.example.com\n"+
+		"ServerRoot: \"/etc/apache2\"\n")
+	apachePath := filepath.Join(root, "ssl")
+	ctx := apacheTestContext(apachePath)
+
+	resources, err := DiscoverApacheVhostResources(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverApacheVhostResources: %v", err)
+	}
+	if len(resources) != 1 || resources[0].Label != "example.com" || strings.Join(resources[0].Domains, ",") != "example.com,www.example.com,*.cdn.example.com" || !strings.HasPrefix(resources[0].TargetRef, apacheVhostTargetPrefix) {
+		t.Fatalf("发现的虚拟主机不匹配: %+v", resources)
+	}
+	if err := TestApacheVhostConnection(ctx, resources[0].TargetRef); err != nil {
+		t.Fatalf("TestApacheVhostConnection: %v", err)
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "example.com")
+	err = DeployCertificateToApacheVhost(ctx, resources[0].TargetRef, "example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "2 组 SSLCertificateFile") {
+		t.Fatalf("没有托管组的双证书虚拟主机应拒绝改写: %v", err)
+	}
+	if readTestFile(t, available) != testApacheSiteConfig {
+		t.Fatal("拒绝改写时不应修改配置")
+	}
+
+	// 运维把 RSA 一组指向托管证书后，只改写这一组，ECDSA 一组和链文件原样保留。
+	certPath := filepath.Join(apachePath, "example.com", "cert.pem")
+	keyPath := filepath.Join(apachePath, "example.com", "privateKey.key")
+	managedConfig := strings.Replace(testApacheSiteConfig, `"/old/cert.pem"`, certPath, 1)
+	writeTestFile(t, available, managedConfig)
+	if err := DeployCertificateToApacheVhost(ctx, resources[0].TargetRef, "example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToApacheVhost: %v", err)
+	}
+	if info, err := os.Lstat(enabled); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatal("sites-enabled 中的符号链接不应被替换")
+	}
+	updated := readTestFile(t, available)
+	if !strings.Contains(updated, "    SSLEngine on\n    SSLCertificateFile "+certPath+"\n    SSLCertificateKeyFile "+keyPath+"\n    SSLCertificateChainFile /old/chain.pem\n    SSLCertificateFile /old/ecc.pem\n    SSLCertificateKeyFile /old/ecc.key\n    <Directory /var/www>") {
+		t.Fatalf("证书指令未按预期替换:\n%s", updated)
+	}
+	if readTestFile(t, certPath) != certificatePEM {
+		t.Fatal("证书文件未发布")
+	}
+	backup := filepath.Join(apachePath, apacheBackupDir, strings.ReplaceAll(strings.TrimPrefix(filepath.ToSlash(available), "/"), "/", "_"))
+	if readTestFile(t, backup) != managedConfig {
+		t.Fatal("应保留改写前的配置备份")
+	}
+	if readTestFile(t, filepath.Join(stateDir, "reloads")) != "graceful\n" {
+		t.Fatal("应执行一次 apachectl graceful")
+	}
+
+	// 改写后 targetRef 保持不变，再次部署时配置已指向证书目录，只重新加载。
+	if err := DeployCertificateToApacheVhost(ctx, resources[0].TargetRef, "example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("重复部署失败: %v", err)
+	}
+	if readTestFile(t, available) != updated {
+		t.Fatal("重复部署不应再次改写配置")
+	}
+}
+
+// TestDeployCertificateToApacheVhostRollsBackWhenConfigTestFails 验证单行 -S 输出、缺少证书指令时在块首插入，apachectl -t 失败时恢复配置和证书目录。
+func TestDeployCertificateToApacheVhostRollsBackWhenConfigTestFails(t *testing.T) {
+	root := t.TempDir()
+	site := filepath.Join(root, "conf.d", "api.conf")
+	original := "<VirtualHost 10.0.0.1:443>\r\n\tServerName api.example.com:443\r\n</VirtualHost>\r\n"
+	writeTestFile(t, site, original)
+	stateDir := installFakeApachectl(t, "10.0.0.1:443           api.example.com ("+site+":1)\n")
+	apachePath := filepath.Join(root, "ssl")
+	ctx := apacheTestContext(apachePath)
+
+	hosts := parseApacheVirtualHosts(strings.Split(original, "\n"))
+	if len(hosts) != 1 {
+		t.Fatalf("parseApacheVirtualHosts: %+v", hosts)
+	}
+	inserted, err := replaceApacheCertificateDirectives(original, hosts[0], "/ssl/a b/cert.pem", "/ssl/key.pem")
+	if err != nil || inserted != "<VirtualHost 10.0.0.1:443>\r\n\tSSLCertificateFile \"/ssl/a b/cert.pem\"\r\n\tSSLCertificateKeyFile /ssl/key.pem\r\n\tServerName api.example.com:443\r\n</VirtualHost>\r\n" {
+		t.Fatalf("缺少证书指令时应在块首插入:\n%q", inserted)
+	}
+
+	resources, err := DiscoverApacheVhostResources(ctx)
+	if err != nil || len(resources) != 1 || resources[0].Domain != "api.example.com" {
+		t.Fatalf("DiscoverApacheVhostResources: %+v, %v", resources, err)
+	}
+	writeTestFile(t, filepath.Join(stateDir, "fail"), "")
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "api.example.com")
+	err = DeployCertificateToApacheVhost(ctx, resources[0].TargetRef, "api.example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "apache配置测试失败") {
+		t.Fatalf("apachectl -t 失败时应返回错误: %v", err)
+	}
+	if readTestFile(t, site) != original {
+		t.Fatal("校验失败后应恢复原配置")
+	}
+	if _, err := os.Stat(filepath.Join(apachePath, "api.example.com")); !os.IsNotExist(err) {
+		t.Fatalf("校验失败后不应保留新证书目录: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, "reloads")); !os.IsNotExist(err) {
+		t.Fatal("校验失败后不应 reload")
+	}
+}
+
+// installFakeApachectl 安装模拟 apachectl：-S 输出指定虚拟主机列表，-t 在 fail 文件存在时失败，graceful 记录调用。
+func installFakeApachectl(t *testing.T, vhosts string) string {
+	t.Helper()
+	stateDir := t.TempDir()
+	writeTestFile(t, filepath.Join(stateDir, "vhosts"), vhosts)
+	script := "#!/bin/sh\ncase \"$1\" in\n" +
+		"-S)\n\tcat '" + stateDir + "/vhosts'\n\t;;\n" +
+		"-t)\n\tif [ -f '" + stateDir + "/fail' ]; then echo 'AH00526: Syntax error'; exit 1; fi\n\t;;\n" +
+		"graceful)\n\techo \"$1\" >> '" + stateDir + "/reloads'\n\t;;\n" +
+		"esac\n"
+	command := filepath.Join(stateDir, "apachectl")
+	if err := os.WriteFile(command, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake apachectl: %v", err)
+	}
+	original := apacheCommands
+	apacheCommands = []string{command}
+	t.Cleanup(func() { apacheCommands = original })
+	return stateDir
+}
+
+// apacheTestContext 返回只包含 Apache 证书目录配置的操作 context。
+func apacheTestContext(apachePath string) context.Context {
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{ApachePath: apachePath}}})
+}
+
+// writeTestFile 创建父目录并写入测试文件。
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// readTestFile 读取测试文件内容。
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(content)
+}
+
+// generateTestCertificatePair 生成 Apache 测试使用的自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -1,17 +1,27 @@
 package btpanel
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
 	"encoding/pem"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
 )
 
 // TestBTPanelCertificateFingerprintUsesLeaf 验证宝塔证书库回读校验使用叶证书指纹。
@@ -42,6 +52,125 @@ func TestBTPanelCertificateFingerprintUsesLeaf(t *testing.T) {
 	}
 }
 
+// TestDiscoverBTPanelWebsiteResourcesAcrossInstances 验证多实例汇总发现、不可达实例返回部分结果、单实例 targetRef 保持不变，以及 targetRef 直接定位所属实例。
+func TestDiscoverBTPanelWebsiteResourcesAcrossInstances(t *testing.T) {
+	legacy := &fakeBTPanel{apiKey: "legacy-key", websites: []*fakeBTPanelWebsite{{ID: 1, Name: "a.example.com", AddTime: "2024-01-01 00:00:00"}}}
+	named := &fakeBTPanel{apiKey: "named-key", websites: []*fakeBTPanelWebsite{{ID: 1, Name: "b.example.com", AddTime: "2024-01-01 00:00:00"}}}
+	legacyServer := httptest.NewServer(legacy)
+	t.Cleanup(legacyServer.Close)
+	namedServer := httptest.NewServer(named)
+	t.Cleanup(namedServer.Close)
+	downServer := httptest.NewServer(http.NotFoundHandler())
+	downServer.Close()
+	ctx := shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{
+		BTPanel: &config.BTPanelConfig{URL: legacyServer.URL, APIKey: "legacy-key"},
+		BTPanels: []*config.BTPanelConfig{
+			{Name: "bt-02", URL: namedServer.URL, APIKey: "named-key"},
+			{Name: "down", URL: downServer.URL, APIKey: "down-key"},
+		},
+	}}})
+
+	resources, err := DiscoverBTPanelWebsiteResources(ctx)
+	if err == nil || !strings.Contains(err.Error(), "宝塔面板实例 down") || !IsBTPanelErrorRetryable(err) || len(resources) != 2 {
+		t.Fatalf("不可达实例应返回其他实例的资源和可重试错误: %+v err=%v", resources, err)
+	}
+	if resources[0].Domain != "a.example.com" || resources[1].Domain != "b.example.com" {
+		t.Fatalf("应按配置顺序汇总各实例的网站: %+v", resources)
+	}
+	legacyIdentity := strings.Join([]string{"ansslCli", "EXECUTE_BUSINES_ANSSL_CLI_BT_PANEL_WEBSITE_CERT", normalizeBTPanelOrigin(legacyServer.URL), "1", "2024-01-01 00:00:00"}, "\x00")
+	legacyDigest := sha256.Sum256([]byte(legacyIdentity))
+	if resources[0].TargetRef != btPanelWebsiteTargetPrefix+hex.EncodeToString(legacyDigest[:12]) {
+		t.Fatalf("单实例配置的 targetRef 应与升级前完全一致: %s", resources[0].TargetRef)
+	}
+	if !strings.HasPrefix(resources[1].TargetRef, shared.InstanceTargetRefPrefix(btPanelWebsiteTargetPrefix, "bt-02")) {
+		t.Fatalf("具名实例的 targetRef 应包含实例标记: %s", resources[1].TargetRef)
+	}
+	if err := TestBTPanelWebsiteConnection(ctx, shared.InstanceTargetRefPrefix(btPanelWebsiteTargetPrefix, "gone")+"0123"); err == nil || !strings.Contains(err.Error(), "已重命名") {
+		t.Fatalf("实例已删除的 targetRef 应提示重新配置: %v", err)
+	}
+
+	sourceDir := t.TempDir()
+	writeTestCertificatePair(t, sourceDir, "b.example.com")
+	certificatePEM, _ := os.ReadFile(filepath.Join(sourceDir, "cert.pem"))
+	privateKeyPEM, _ := os.ReadFile(filepath.Join(sourceDir, "privateKey.key"))
+	if err := DeployCertificateToBTPanelWebsite(ctx, resources[1].TargetRef, string(certificatePEM), string(privateKeyPEM)); err != nil {
+		t.Fatalf("DeployCertificateToBTPanelWebsite: %v", err)
+	}
+	named.mu.Lock()
+	defer named.mu.Unlock()
+	legacy.mu.Lock()
+	defer legacy.mu.Unlock()
+	if named.websites[0].Certificate != string(certificatePEM) || legacy.websites[0].Certificate != "" {
+		t.Fatalf("证书应只部署到具名实例: named=%+v legacy=%+v", named.websites[0], legacy.websites[0])
+	}
+}
+
+// fakeBTPanelWebsite 是模拟宝塔面板中的一个网站。
+type fakeBTPanelWebsite struct {
+	ID          uint64
+	Name        string
+	AddTime     string
+	Certificate string
+}
+
+// fakeBTPanel 在内存中模拟宝塔网站列表、域名和 SSL 接口。
+type fakeBTPanel struct {
+	mu       sync.Mutex
+	apiKey   string
+	websites []*fakeBTPanelWebsite
+}
+
+// ServeHTTP 校验实例 API 密钥签名，并按 action 返回宝塔接口数据。
+func (f *fakeBTPanel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("request_token") != btPanelMD5(r.PostForm.Get("request_time")+btPanelMD5(f.apiKey)) {
+		json.NewEncoder(w).Encode(map[string]any{"status": false, "msg": "密钥校验失败"})
+		return
+	}
+	var data any
+	switch r.URL.Path + "." + r.PostForm.Get("action") {
+	case btPanelDataPath + ".getData":
+		sites := make([]map[string]any, 0, len(f.websites))
+		for _, website := range f.websites {
+			sites = append(sites, map[string]any{"id": website.ID, "name": website.Name, "addtime": website.AddTime, "status": "1", "ssl": -1})
+		}
+		data = map[string]any{"data": sites}
+	case btPanelSitePath + ".GetSiteDomains":
+		data = map[string]any{"domains": []map[string]any{}}
+	case btPanelSitePath + ".SetSSL":
+		website := f.find(r.PostForm.Get("siteName"))
+		if website == nil {
+			data = map[string]any{"status": false, "msg": "网站不存在"}
+			break
+		}
+		website.Certificate = r.PostForm.Get("csr")
+		data = map[string]any{"status": true}
+	case btPanelSitePath + ".GetSSL":
+		website := f.find(r.PostForm.Get("siteName"))
+		if website == nil || website.Certificate == "" {
+			data = map[string]any{"status": false}
+			break
+		}
+		fingerprint, _ := btPanelCertificateFingerprint(website.Certificate)
+		data = map[string]any{"status": true, "cert_data": map[string]any{"sha256": hex.EncodeToString(fingerprint[:])}}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(data)
+}
+
+// find 按网站名称查找模拟网站。
+func (f *fakeBTPanel) find(name string) *fakeBTPanelWebsite {
+	for _, website := range f.websites {
+		if website.Name == name {
+			return website
+		}
+	}
+	return nil
+}
+
 // writeTestCertificatePair 写入宝塔测试使用的自签证书和匹配私钥。
 func writeTestCertificatePair(t *testing.T, dir, domain string) {
 	t.Helper()
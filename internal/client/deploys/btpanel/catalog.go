@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
@@ -13,28 +14,40 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
 	"golang.org/x/net/idna"
 )
 
-// DiscoverBTPanelWebsiteResources 动态读取全部宝塔网站的脱敏目录。
+// DiscoverBTPanelWebsiteResources 动态读取全部宝塔面板实例的网站脱敏目录；部分实例不可达时同时返回已发现资源和错误。
 func DiscoverBTPanelWebsiteResources(ctx context.Context) ([]BTPanelWebsiteResource, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	discoveryContext, cancel := context.WithTimeout(ctx, btPanelDiscoveryTimeout)
-	defer cancel()
-
-	records, err := loadBTPanelWebsiteRecords(discoveryContext)
+	panels, err := getBTPanelConfigs(ctx)
 	if err != nil {
 		return nil, err
 	}
-	resources := make([]BTPanelWebsiteResource, 0, len(records))
-	for _, record := range records {
-		resource := record.Resource
-		resource.Domains = append([]string(nil), record.Resource.Domains...)
-		resources = append(resources, resource)
+
+	resources := make([]BTPanelWebsiteResource, 0)
+	var failures []error
+	for _, panel := range panels {
+		discoveryContext, cancel := context.WithTimeout(ctx, btPanelDiscoveryTimeout)
+		records, err := loadBTPanelWebsiteRecords(discoveryContext, panel)
+		cancel()
+		if err != nil {
+			logger.Warn("读取宝塔网站目录失败", "instance", panel.Name, "error", err)
+			failures = append(failures, btPanelInstanceError(panel, err))
+			continue
+		}
+		for _, record := range records {
+			resource := record.Resource
+			resource.Domains = append([]string(nil), record.Resource.Domains...)
+			resources = append(resources, resource)
+		}
 	}
-	return resources, nil
+	return resources, errors.Join(failures...)
 }
 
 // TestBTPanelWebsiteConnection 只读确认 targetRef 对应网站仍存在并能读取 SSL 配置。
@@ -49,29 +62,25 @@ func TestBTPanelWebsiteConnection(ctx context.Context, targetRef string) error {
 	discoveryContext, cancel := context.WithTimeout(ctx, btPanelDiscoveryTimeout)
 	defer cancel()
 
-	apiURL, apiKey, insecureSkipVerify, err := getBTPanelConfig(ctx)
-	if err != nil {
-		return err
-	}
-	record, err := findBTPanelWebsiteByTargetRef(discoveryContext, targetRef)
+	panel, record, err := findBTPanelWebsiteByTargetRef(discoveryContext, targetRef)
 	if err != nil {
 		return err
 	}
 	if record.Resource.Status == btPanelStatusStopped {
 		return fmt.Errorf("宝塔网站未运行")
 	}
-	if _, err := getBTPanelWebsiteSSL(discoveryContext, apiURL, apiKey, insecureSkipVerify, record.SiteName); err != nil {
-		return fmt.Errorf("读取宝塔网站 SSL 配置失败: %w", err)
+	if _, err := getBTPanelWebsiteSSL(discoveryContext, panel, record.SiteName); err != nil {
+		return btPanelInstanceError(panel, fmt.Errorf("读取宝塔网站 SSL 配置失败: %w", err))
 	}
 	return nil
 }
 
 // listBTPanelWebsiteSummaries 分页读取全部宝塔网站。
-func listBTPanelWebsiteSummaries(ctx context.Context, apiURL, apiKey string, insecureSkipVerify bool) ([]btPanelWebsiteSummary, error) {
+func listBTPanelWebsiteSummaries(ctx context.Context, panel *config.BTPanelConfig) ([]btPanelWebsiteSummary, error) {
 	websites := make([]btPanelWebsiteSummary, 0)
 	for page := 1; page <= btPanelWebsiteMaxPages; page++ {
 		var pageData btPanelWebsitePage
-		if err := requestBTPanelAPI(ctx, apiURL, apiKey, insecureSkipVerify, btPanelDataPath, url.Values{
+		if err := requestBTPanelAPI(ctx, panel, btPanelDataPath, url.Values{
 			"action": {"getData"},
 			"table":  {"sites"},
 			"type":   {"-1"},
@@ -91,13 +100,9 @@ func listBTPanelWebsiteSummaries(ctx context.Context, apiURL, apiKey string, ins
 	return nil, fmt.Errorf("宝塔网站分页超过安全上限")
 }
 
-// loadBTPanelWebsiteRecords 读取全部宝塔网站及其域名，并用有限并发控制面板压力。
-func loadBTPanelWebsiteRecords(ctx context.Context) ([]btPanelWebsiteRecord, error) {
-	apiURL, apiKey, insecureSkipVerify, err := getBTPanelConfig(ctx)
-	if err != nil {
-		return nil, err
-	}
-	websites, err := listBTPanelWebsiteSummaries(ctx, apiURL, apiKey, insecureSkipVerify)
+// loadBTPanelWebsiteRecords 读取一个宝塔面板实例的全部网站及其域名，并用有限并发控制面板压力。
+func loadBTPanelWebsiteRecords(ctx context.Context, panel *config.BTPanelConfig) ([]btPanelWebsiteRecord, error) {
+	websites, err := listBTPanelWebsiteSummaries(ctx, panel)
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +135,7 @@ func loadBTPanelWebsiteRecords(ctx context.Context) ([]btPanelWebsiteRecord, err
 		go func() {
 			defer waitGroup.Done()
 			for job := range jobs {
-				domains, loadErr := loadBTPanelWebsiteDomains(workerContext, apiURL, apiKey, insecureSkipVerify, job.Website)
+				domains, loadErr := loadBTPanelWebsiteDomains(workerContext, panel, job.Website)
 				if loadErr != nil {
 					select {
 					case errorChannel <- loadErr:
@@ -151,7 +156,7 @@ func loadBTPanelWebsiteRecords(ctx context.Context) ([]btPanelWebsiteRecord, err
 					ID:       job.Website.ID,
 					SiteName: strings.TrimSpace(job.Website.Name),
 					Resource: BTPanelWebsiteResource{
-						TargetRef: buildBTPanelWebsiteTargetRef(apiURL, job.Website),
+						TargetRef: buildBTPanelWebsiteTargetRef(panel, job.Website),
 						Label:     label,
 						Domain:    primaryDomain,
 						Domains:   domains,
@@ -189,9 +194,9 @@ sendJobs:
 }
 
 // loadBTPanelWebsiteDomains 读取一个宝塔网站的域名并规范化、去重和排序。
-func loadBTPanelWebsiteDomains(ctx context.Context, apiURL, apiKey string, insecureSkipVerify bool, website btPanelWebsiteSummary) ([]string, error) {
+func loadBTPanelWebsiteDomains(ctx context.Context, panel *config.BTPanelConfig, website btPanelWebsiteSummary) ([]string, error) {
 	var response btPanelWebsiteDomains
-	if err := requestBTPanelAPI(ctx, apiURL, apiKey, insecureSkipVerify, btPanelSitePath, url.Values{
+	if err := requestBTPanelAPI(ctx, panel, btPanelSitePath, url.Values{
 		"action": {"GetSiteDomains"},
 		"id":     {strconv.FormatUint(website.ID, 10)},
 	}, &response); err != nil {
@@ -234,9 +239,9 @@ func validateBTPanelReadEnvelope(status *bool, message, operation string) error
 }
 
 // getBTPanelWebsiteSSL 读取指定宝塔网站的当前 SSL 状态和证书元数据。
-func getBTPanelWebsiteSSL(ctx context.Context, apiURL, apiKey string, insecureSkipVerify bool, siteName string) (*btPanelWebsiteSSL, error) {
+func getBTPanelWebsiteSSL(ctx context.Context, panel *config.BTPanelConfig, siteName string) (*btPanelWebsiteSSL, error) {
 	var response btPanelWebsiteSSL
-	if err := requestBTPanelAPI(ctx, apiURL, apiKey, insecureSkipVerify, btPanelSitePath, url.Values{
+	if err := requestBTPanelAPI(ctx, panel, btPanelSitePath, url.Values{
 		"action":   {"GetSSL"},
 		"siteName": {siteName},
 	}, &response); err != nil {
@@ -246,15 +251,29 @@ func getBTPanelWebsiteSSL(ctx context.Context, apiURL, apiKey string, insecureSk
 }
 
 // findBTPanelWebsiteByTargetRef 重新发现宝塔网站并要求 targetRef 唯一匹配。
-func findBTPanelWebsiteByTargetRef(ctx context.Context, targetRef string) (*btPanelWebsiteRecord, error) {
+func findBTPanelWebsiteByTargetRef(ctx context.Context, targetRef string) (*config.BTPanelConfig, *btPanelWebsiteRecord, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	panels, err := getBTPanelConfigs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var panel *config.BTPanelConfig
+	for _, candidate := range panels {
+		if shared.MatchInstanceTargetRef(targetRef, shared.InstanceTargetRefPrefix(btPanelWebsiteTargetPrefix, candidate.Name)) {
+			panel = candidate
+			break
+		}
+	}
+	if panel == nil {
+		return nil, nil, fmt.Errorf("宝塔网站所属实例不存在或已重命名，请重新配置部署目标")
+	}
 	discoveryContext, cancel := context.WithTimeout(ctx, btPanelDiscoveryTimeout)
 	defer cancel()
-	records, err := loadBTPanelWebsiteRecords(discoveryContext)
+	records, err := loadBTPanelWebsiteRecords(discoveryContext, panel)
 	if err != nil {
-		return nil, err
+		return nil, nil, btPanelInstanceError(panel, err)
 	}
 	var matched *btPanelWebsiteRecord
 	for index := range records {
@@ -262,28 +281,31 @@ func findBTPanelWebsiteByTargetRef(ctx context.Context, targetRef string) (*btPa
 			continue
 		}
 		if matched != nil {
-			return nil, fmt.Errorf("宝塔网站 targetRef 不唯一，请重新配置部署目标")
+			return nil, nil, fmt.Errorf("宝塔网站 targetRef 不唯一，请重新配置部署目标")
 		}
 		record := records[index]
 		matched = &record
 	}
 	if matched == nil {
-		return nil, fmt.Errorf("宝塔网站不存在或已重新创建，请重新配置部署目标")
+		return nil, nil, fmt.Errorf("宝塔网站不存在或已重新创建，请重新配置部署目标")
 	}
-	return matched, nil
+	return panel, matched, nil
 }
 
-// buildBTPanelWebsiteTargetRef 根据实例、网站身份和创建时间生成稳定的不透明引用。
-func buildBTPanelWebsiteTargetRef(apiURL string, website btPanelWebsiteSummary) string {
-	identity := strings.Join([]string{
+// buildBTPanelWebsiteTargetRef 根据实例、网站身份和创建时间生成稳定的不透明引用；具名实例的名称同时进入前缀和摘要。
+func buildBTPanelWebsiteTargetRef(panel *config.BTPanelConfig, website btPanelWebsiteSummary) string {
+	parts := []string{
 		"ansslCli",
 		"EXECUTE_BUSINES_ANSSL_CLI_BT_PANEL_WEBSITE_CERT",
-		normalizeBTPanelOrigin(apiURL),
+		normalizeBTPanelOrigin(panel.URL),
 		strconv.FormatUint(website.ID, 10),
 		strings.TrimSpace(website.AddTime),
-	}, "\x00")
-	digest := sha256.Sum256([]byte(identity))
-	return btPanelWebsiteTargetPrefix + hex.EncodeToString(digest[:12])
+	}
+	if panel.Name != "" {
+		parts = append(parts, panel.Name)
+	}
+	digest := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return shared.InstanceTargetRefPrefix(btPanelWebsiteTargetPrefix, panel.Name) + hex.EncodeToString(digest[:12])
 }
 
 // normalizeBTPanelOrigin 规范化仅用于本地哈希的面板来源，不返回或记录该值。
@@ -14,6 +14,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/https-cert/deploy/internal/config"
 )
 
 // TestBTPanelCertificateConnection 通过兼容入口测试宝塔证书库权限。
@@ -21,29 +23,33 @@ func TestBTPanelCertificateConnection() error {
 	return TestBTPanelCertificateConnectionWithContext(context.Background())
 }
 
-// TestBTPanelCertificateConnectionWithContext 使用调用方 context 测试宝塔证书库权限。
+// TestBTPanelCertificateConnectionWithContext 使用调用方 context 逐个测试全部宝塔面板实例的证书库权限。
 func TestBTPanelCertificateConnectionWithContext(ctx context.Context) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	apiURL, apiKey, insecureSkipVerify, err := getBTPanelConfig(ctx)
+	panels, err := getBTPanelConfigs(ctx)
 	if err != nil {
 		return err
 	}
-	operationContext, cancel := context.WithTimeout(ctx, btPanelDiscoveryTimeout)
-	defer cancel()
-	if _, err := listBTPanelCertificates(operationContext, apiURL, apiKey, insecureSkipVerify); err != nil {
-		return fmt.Errorf("读取宝塔证书库失败: %w", err)
+	var failures []error
+	for _, panel := range panels {
+		operationContext, cancel := context.WithTimeout(ctx, btPanelDiscoveryTimeout)
+		_, err := listBTPanelCertificates(operationContext, panel)
+		cancel()
+		if err != nil {
+			failures = append(failures, btPanelInstanceError(panel, fmt.Errorf("读取宝塔证书库失败: %w", err)))
+		}
 	}
-	return nil
+	return errors.Join(failures...)
 }
 
-// DeployCertificateToBTPanelCertificateStore 将证书保存到宝塔证书库并回读元数据校验。
+// DeployCertificateToBTPanelCertificateStore 将证书保存到全部宝塔面板实例的证书库并回读元数据校验；单个实例失败不影响其他实例。
 func DeployCertificateToBTPanelCertificateStore(ctx context.Context, certificatePEM, privateKeyPEM string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	apiURL, apiKey, insecureSkipVerify, err := getBTPanelConfig(ctx)
+	panels, err := getBTPanelConfigs(ctx)
 	if err != nil {
 		return err
 	}
@@ -51,9 +57,19 @@ func DeployCertificateToBTPanelCertificateStore(ctx context.Context, certificate
 	if err != nil {
 		return err
 	}
+	var failures []error
+	for _, panel := range panels {
+		if err := saveBTPanelCertificate(ctx, panel, leaf, certificatePEM, privateKeyPEM); err != nil {
+			failures = append(failures, btPanelInstanceError(panel, err))
+		}
+	}
+	return errors.Join(failures...)
+}
 
+// saveBTPanelCertificate 将证书保存到单个宝塔面板实例的证书库，并按叶证书指纹回读确认。
+func saveBTPanelCertificate(ctx context.Context, panel *config.BTPanelConfig, leaf *x509.Certificate, certificatePEM, privateKeyPEM string) error {
 	var response btPanelCertificateSaveResponse
-	if err := requestBTPanelAPI(ctx, apiURL, apiKey, insecureSkipVerify, btPanelCertificateSavePath, url.Values{
+	if err := requestBTPanelAPI(ctx, panel, btPanelCertificateSavePath, url.Values{
 		"key": {privateKeyPEM},
 		"csr": {certificatePEM},
 	}, &response); err != nil {
@@ -69,7 +85,7 @@ func DeployCertificateToBTPanelCertificateStore(ctx context.Context, certificate
 	if strings.TrimSpace(response.SSLHash) == "" {
 		return fmt.Errorf("宝塔保存证书响应缺少证书摘要")
 	}
-	details, err := getBTPanelCertificateDetails(ctx, apiURL, apiKey, insecureSkipVerify, response.SSLHash)
+	details, err := getBTPanelCertificateDetails(ctx, panel, response.SSLHash)
 	if err != nil {
 		return fmt.Errorf("回读宝塔证书库失败: %w", err)
 	}
@@ -85,9 +101,9 @@ func DeployCertificateToBTPanelCertificateStore(ctx context.Context, certificate
 }
 
 // listBTPanelCertificates 通过宝塔只读接口读取证书库的脱敏摘要。
-func listBTPanelCertificates(ctx context.Context, apiURL, apiKey string, insecureSkipVerify bool) ([]btPanelCertificateSummary, error) {
+func listBTPanelCertificates(ctx context.Context, panel *config.BTPanelConfig) ([]btPanelCertificateSummary, error) {
 	var raw json.RawMessage
-	if err := requestBTPanelAPI(ctx, apiURL, apiKey, insecureSkipVerify, btPanelSSLPath, url.Values{
+	if err := requestBTPanelAPI(ctx, panel, btPanelSSLPath, url.Values{
 		"action": {"get_cert_list"},
 	}, &raw); err != nil {
 		return nil, fmt.Errorf("读取宝塔证书列表失败: %w", err)
@@ -131,10 +147,10 @@ func validateBTPanelCertificatePair(certificatePEM, privateKeyPEM string) (*x509
 }
 
 // getBTPanelCertificateDetails 读取宝塔证书库中指定摘要的证书详情。
-func getBTPanelCertificateDetails(ctx context.Context, apiURL, apiKey string, insecureSkipVerify bool, sslHash string) (*btPanelCertificateDetails, error) {
+func getBTPanelCertificateDetails(ctx context.Context, panel *config.BTPanelConfig, sslHash string) (*btPanelCertificateDetails, error) {
 	var details btPanelCertificateDetails
 	var raw json.RawMessage
-	if err := requestBTPanelAPI(ctx, apiURL, apiKey, insecureSkipVerify, btPanelSSLPath, url.Values{
+	if err := requestBTPanelAPI(ctx, panel, btPanelSSLPath, url.Values{
 		"action":   {"get_cert_info"},
 		"ssl_hash": {strings.TrimSpace(sslHash)},
 	}, &raw); err != nil {
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
 )
 
 // btPanelRequestError 保存仅供 deploy 本地判断重试属性的 API 错误。
@@ -49,8 +50,7 @@ func IsBTPanelConfigured() bool {
 // IsBTPanelConfiguredWithContext 从 context 快照判断宝塔面板是否已配置。
 func IsBTPanelConfiguredWithContext(ctx context.Context) bool {
 	configuration := shared.ConfigurationFromContext(ctx)
-	return configuration != nil && configuration.SSL != nil && configuration.SSL.BTPanel != nil &&
-		strings.TrimSpace(configuration.SSL.BTPanel.URL) != "" && strings.TrimSpace(configuration.SSL.BTPanel.APIKey) != ""
+	return configuration != nil && len(config.BTPanelInstances(configuration.SSL)) > 0
 }
 
 // IsBTPanelErrorRetryable 判断宝塔面板操作是否适合由后端稍后重试。
@@ -70,45 +70,70 @@ func IsBTPanelErrorRetryable(err error) bool {
 	return errors.As(err, &networkError) && (networkError.Timeout() || networkError.Temporary())
 }
 
-// getBTPanelConfig 读取并校验当前操作快照中的宝塔面板配置。
-func getBTPanelConfig(ctx context.Context) (string, string, bool, error) {
+// getBTPanelConfigs 读取并校验当前操作快照中的全部宝塔面板实例，兼容的单实例配置排在最前。
+func getBTPanelConfigs(ctx context.Context) ([]*config.BTPanelConfig, error) {
 	configuration := shared.ConfigurationFromContext(ctx)
-	if configuration == nil || configuration.SSL == nil || configuration.SSL.BTPanel == nil {
-		return "", "", false, fmt.Errorf("未配置宝塔面板 (ssl.btPanel)")
+	if configuration == nil {
+		return nil, fmt.Errorf("未配置宝塔面板 (ssl.btPanel 或 ssl.btPanels)")
 	}
-	btPanel := configuration.SSL.BTPanel
-	apiURL := strings.TrimRight(strings.TrimSpace(btPanel.URL), "/")
-	apiKey := strings.TrimSpace(btPanel.APIKey)
-	if apiURL == "" || apiKey == "" {
-		return "", "", false, fmt.Errorf("宝塔面板地址或 API 密钥未配置")
+	panels := config.BTPanelInstances(configuration.SSL)
+	if len(panels) == 0 {
+		return nil, fmt.Errorf("未配置宝塔面板 (ssl.btPanel 或 ssl.btPanels)")
+	}
+	for _, panel := range panels {
+		if err := checkBTPanelConfig(panel); err != nil {
+			return nil, btPanelInstanceError(panel, err)
+		}
+	}
+	return panels, nil
+}
+
+// checkBTPanelConfig 校验单个宝塔面板实例的地址和 API 密钥。
+func checkBTPanelConfig(panel *config.BTPanelConfig) error {
+	apiURL := btPanelAPIURL(panel)
+	if apiURL == "" || strings.TrimSpace(panel.APIKey) == "" {
+		return fmt.Errorf("宝塔面板地址或 API 密钥未配置")
 	}
 	parsedURL, err := url.Parse(apiURL)
 	if err != nil || parsedURL.Hostname() == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-		return "", "", false, fmt.Errorf("宝塔面板地址必须是合法的 HTTP 或 HTTPS 地址")
+		return fmt.Errorf("宝塔面板地址必须是合法的 HTTP 或 HTTPS 地址")
 	}
 	if parsedURL.User != nil || parsedURL.RawQuery != "" || parsedURL.Fragment != "" {
-		return "", "", false, fmt.Errorf("宝塔面板地址不能包含用户凭据、查询参数或片段")
+		return fmt.Errorf("宝塔面板地址不能包含用户凭据、查询参数或片段")
+	}
+	return nil
+}
+
+// btPanelAPIURL 返回不带结尾斜杠的宝塔面板地址。
+func btPanelAPIURL(panel *config.BTPanelConfig) string {
+	return strings.TrimRight(strings.TrimSpace(panel.URL), "/")
+}
+
+// btPanelInstanceError 为具名实例的错误加上实例名称，兼容的单实例保持原错误。
+func btPanelInstanceError(panel *config.BTPanelConfig, err error) error {
+	if err == nil || panel.Name == "" {
+		return err
 	}
-	return apiURL, apiKey, btPanel.InsecureSkipVerify, nil
+	return fmt.Errorf("宝塔面板实例 %s: %w", panel.Name, err)
 }
 
 // requestBTPanelAPI 使用双重 MD5 鉴权调用宝塔 API，并限制重定向和响应体大小。
-func requestBTPanelAPI(ctx context.Context, apiURL, apiKey string, insecureSkipVerify bool, endpoint string, values url.Values, responseData any) error {
+func requestBTPanelAPI(ctx context.Context, panel *config.BTPanelConfig, endpoint string, values url.Values, responseData any) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	requestTime := strconv.FormatInt(time.Now().Unix(), 10)
 	values = cloneBTPanelValues(values)
 	values.Set("request_time", requestTime)
-	values.Set("request_token", btPanelMD5(requestTime+btPanelMD5(apiKey)))
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+endpoint, strings.NewReader(values.Encode()))
+	values.Set("request_token", btPanelMD5(requestTime+btPanelMD5(strings.TrimSpace(panel.APIKey))))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, btPanelAPIURL(panel)+endpoint, strings.NewReader(values.Encode()))
 	if err != nil {
 		return fmt.Errorf("创建宝塔面板请求失败: %w", err)
 	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	client := newBTPanelHTTPClient(insecureSkipVerify)
+	client := newBTPanelHTTPClient(panel.InsecureSkipVerify)
 	resp, err := client.Do(req)
 	if err != nil {
 		return &btPanelRequestError{Retryable: true, Cause: fmt.Errorf("请求宝塔面板 API 失败: %w", err)}
@@ -18,11 +18,7 @@ func DeployCertificateToBTPanelWebsite(ctx context.Context, targetRef, certifica
 		ctx = context.Background()
 	}
 
-	apiURL, apiKey, insecureSkipVerify, err := getBTPanelConfig(ctx)
-	if err != nil {
-		return err
-	}
-	record, err := findBTPanelWebsiteByTargetRef(ctx, targetRef)
+	panel, record, err := findBTPanelWebsiteByTargetRef(ctx, targetRef)
 	if err != nil {
 		return err
 	}
@@ -35,7 +31,7 @@ func DeployCertificateToBTPanelWebsite(ctx context.Context, targetRef, certifica
 	}
 
 	var response btPanelActionResponse
-	if err := requestBTPanelAPI(ctx, apiURL, apiKey, insecureSkipVerify, btPanelSitePath, url.Values{
+	if err := requestBTPanelAPI(ctx, panel, btPanelSitePath, url.Values{
 		"action":   {"SetSSL"},
 		"siteName": {record.SiteName},
 		"key":      {privateKeyPEM},
@@ -51,7 +47,7 @@ func DeployCertificateToBTPanelWebsite(ctx context.Context, targetRef, certifica
 		return &btPanelRequestError{Retryable: false, Cause: fmt.Errorf("更新宝塔网站证书失败: %s", message)}
 	}
 
-	updated, err := getBTPanelWebsiteSSL(ctx, apiURL, apiKey, insecureSkipVerify, record.SiteName)
+	updated, err := getBTPanelWebsiteSSL(ctx, panel, record.SiteName)
 	if err != nil {
 		return fmt.Errorf("回读宝塔网站 SSL 配置失败: %w", err)
 	}
@@ -0,0 +1,302 @@
+package caddy
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	caddyRequestTimeout      = 30 * time.Second
+	caddyValidateTimeout     = 30 * time.Second
+	caddyMaxResponseBodySize = 8 * 1024 * 1024
+	caddyConfigPath          = "/config/"
+	caddyLoadPath            = "/load"
+	caddyCertificateTag      = "anssl"
+)
+
+// caddyCommand 允许测试替换 caddy 可执行文件，生产环境始终从 PATH 查找。
+var caddyCommand = "caddy"
+
+// caddyAdminClient 保存本机 Caddy 管理 API 的连接信息。
+type caddyAdminClient struct {
+	baseURL    string       // baseURL 是规范化后的管理 API 地址。
+	httpClient *http.Client // httpClient 限制超时、TLS 版本和重定向行为。
+}
+
+// caddyAdminError 是 Caddy 管理 API 的错误响应。
+type caddyAdminError struct {
+	Error string `json:"error"` // Error 是 Caddy 返回的错误说明。
+}
+
+// DeployWithContext 发布证书目录，并将 caddy validate 和管理 API 加载纳入同一发布事务。
+func DeployWithContext(ctx context.Context, sourceDir, domain, safeDomain string, caddyConfig *config.CaddyConfig) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if caddyConfig == nil || caddyConfig.Path == "" {
+		return errors.New("未配置 Caddy 证书目录 (ssl.caddy.path)")
+	}
+	if err := shared.ValidateCertificateFiles(sourceDir, domain); err != nil {
+		return err
+	}
+	client, err := newCaddyAdminClient(caddyConfig.AdminURL)
+	if err != nil {
+		return err
+	}
+	// 先读取运行中配置，管理 API 不可用时不触碰已发布证书。
+	currentConfig, err := client.getConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("读取 Caddy 当前配置失败: %w", err)
+	}
+
+	if err := os.MkdirAll(caddyConfig.Path, 0755); err != nil {
+		return fmt.Errorf("创建 Caddy 证书目录失败: %w", err)
+	}
+	targetDir, err := shared.SafeJoinUnderBase(caddyConfig.Path, safeDomain)
+	if err != nil {
+		return err
+	}
+	certificateFile := filepath.Join(targetDir, "cert.pem")
+	privateKeyFile := filepath.Join(targetDir, "privateKey.key")
+	nextConfig, err := upsertCaddyLoadFiles(currentConfig, certificateFile, privateKeyFile, caddyCertificateTag+"-"+safeDomain)
+	if err != nil {
+		return err
+	}
+
+	return shared.PublishDirectoryWithValidationContext(ctx, sourceDir, targetDir, func() error {
+		if err := validateCaddyConfig(ctx, caddyConfig.ConfigFile, nextConfig); err != nil {
+			return fmt.Errorf("caddy 配置校验失败: %w", err)
+		}
+		if err := client.load(ctx, nextConfig); err != nil {
+			return fmt.Errorf("caddy 加载证书失败: %w", err)
+		}
+		logger.Info("Caddy 已通过管理 API 加载证书", "path", targetDir)
+		return nil
+	})
+}
+
+// TestCaddyConnectionWithContext 只读请求 Caddy 管理 API，确认地址可达且配置可读取。
+func TestCaddyConnectionWithContext(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || configuration.SSL.Caddy == nil {
+		return errors.New("未配置 Caddy (ssl.caddy)")
+	}
+	client, err := newCaddyAdminClient(configuration.SSL.Caddy.AdminURL)
+	if err != nil {
+		return err
+	}
+	if _, err := client.getConfig(ctx); err != nil {
+		return fmt.Errorf("caddy 连接测试失败: %w", err)
+	}
+	return nil
+}
+
+// IsCaddyAvailable 检查 caddy 命令是否可用。
+func IsCaddyAvailable() bool {
+	_, err := exec.LookPath(caddyCommand)
+	return err == nil
+}
+
+// validateCaddyConfig 使用 caddy validate 校验配置文件，未指定文件时校验即将加载的 JSON 配置。
+func validateCaddyConfig(parent context.Context, configFile string, nextConfig []byte) error {
+	if !IsCaddyAvailable() {
+		logger.Info("caddy 未安装或不在 PATH 中，跳过 caddy validate，由管理 API 加载时校验")
+		return nil
+	}
+	if configFile == "" {
+		tempFile, err := os.CreateTemp("", "anssl-caddy-*.json")
+		if err != nil {
+			return fmt.Errorf("创建 Caddy 临时配置失败: %w", err)
+		}
+		defer os.Remove(tempFile.Name())
+		if _, err := tempFile.Write(nextConfig); err != nil {
+			_ = tempFile.Close()
+			return fmt.Errorf("写入 Caddy 临时配置失败: %w", err)
+		}
+		if err := tempFile.Close(); err != nil {
+			return fmt.Errorf("关闭 Caddy 临时配置失败: %w", err)
+		}
+		configFile = tempFile.Name()
+	}
+
+	ctx, cancel := context.WithTimeout(parent, caddyValidateTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, caddyCommand, "validate", "--config", configFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// upsertCaddyLoadFiles 确保 tls.certificates.load_files 引用新证书文件，已存在的同路径条目保持不变。
+func upsertCaddyLoadFiles(currentConfig []byte, certificateFile, privateKeyFile, tag string) ([]byte, error) {
+	root := map[string]any{}
+	trimmed := bytes.TrimSpace(currentConfig)
+	if len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.UseNumber()
+		if err := decoder.Decode(&root); err != nil {
+			return nil, fmt.Errorf("解析 Caddy 当前配置失败: %w", err)
+		}
+	}
+
+	apps, err := caddyObject(root, "apps")
+	if err != nil {
+		return nil, err
+	}
+	tlsApp, err := caddyObject(apps, "tls")
+	if err != nil {
+		return nil, err
+	}
+	certificates, err := caddyObject(tlsApp, "certificates")
+	if err != nil {
+		return nil, err
+	}
+	var loadFiles []any
+	if raw, exists := certificates["load_files"]; exists && raw != nil {
+		items, ok := raw.([]any)
+		if !ok {
+			return nil, errors.New("caddy 配置 apps.tls.certificates.load_files 格式无效")
+		}
+		loadFiles = items
+	}
+
+	found := false
+	for index, item := range loadFiles {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		certificate, _ := entry["certificate"].(string)
+		if filepath.Clean(certificate) != certificateFile {
+			continue
+		}
+		if found {
+			return nil, errors.New("caddy 配置中存在多个引用相同证书文件的 load_files 条目")
+		}
+		found = true
+		key, _ := entry["key"].(string)
+		if filepath.Clean(key) != privateKeyFile {
+			entry["key"] = privateKeyFile
+			loadFiles[index] = entry
+		}
+	}
+	if !found {
+		loadFiles = append(loadFiles, map[string]any{
+			"certificate": certificateFile,
+			"key":         privateKeyFile,
+			"tags":        []any{tag},
+		})
+	}
+	certificates["load_files"] = loadFiles
+
+	payload, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("序列化 Caddy 配置失败: %w", err)
+	}
+	return payload, nil
+}
+
+// caddyObject 返回指定键的 JSON 对象，不存在时创建空对象。
+func caddyObject(parent map[string]any, key string) (map[string]any, error) {
+	raw, exists := parent[key]
+	if !exists || raw == nil {
+		object := map[string]any{}
+		parent[key] = object
+		return object, nil
+	}
+	object, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("caddy 配置 %s 不是 JSON 对象", key)
+	}
+	return object, nil
+}
+
+// newCaddyAdminClient 创建只访问配置地址的 Caddy 管理 API 客户端。
+func newCaddyAdminClient(adminURL string) (*caddyAdminClient, error) {
+	baseURL := strings.TrimRight(strings.TrimSpace(adminURL), "/")
+	if baseURL == "" {
+		return nil, errors.New("caddy 管理 API 地址未配置 (ssl.caddy.adminUrl)")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	return &caddyAdminClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout:   caddyRequestTimeout,
+			Transport: transport,
+			CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// getConfig 读取 Caddy 当前生效的 JSON 配置。
+func (client *caddyAdminClient) getConfig(ctx context.Context) ([]byte, error) {
+	return client.request(ctx, http.MethodGet, caddyConfigPath, nil)
+}
+
+// load 强制 Caddy 重新加载完整配置，使其重新读取证书文件。
+func (client *caddyAdminClient) load(ctx context.Context, payload []byte) error {
+	_, err := client.request(ctx, http.MethodPost, caddyLoadPath, payload)
+	return err
+}
+
+// request 调用 Caddy 管理 API，并限制响应体大小。
+func (client *caddyAdminClient) request(ctx context.Context, method, endpoint string, payload []byte) ([]byte, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, client.baseURL+endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("创建 Caddy 请求失败: %w", err)
+	}
+	request.Header.Set("Accept", "application/json")
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+		// 配置内容不变时 Caddy 默认跳过加载，必须强制重新读取证书文件。
+		request.Header.Set("Cache-Control", "must-revalidate")
+	}
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("请求 Caddy 管理 API 失败: %w", err)
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, caddyMaxResponseBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取 Caddy 响应失败: %w", err)
+	}
+	if len(responseBody) > caddyMaxResponseBodySize {
+		return nil, errors.New("caddy 响应体超过最大限制")
+	}
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		var adminError caddyAdminError
+		if json.Unmarshal(responseBody, &adminError) == nil && strings.TrimSpace(adminError.Error) != "" {
+			return nil, fmt.Errorf("caddy 管理 API 返回 HTTP %d: %s", response.StatusCode, strings.TrimSpace(adminError.Error))
+		}
+		return nil, fmt.Errorf("caddy 管理 API 返回 HTTP %d", response.StatusCode)
+	}
+	return responseBody, nil
+}
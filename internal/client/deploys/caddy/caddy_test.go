@@ -0,0 +1,152 @@
+package caddy
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestDeployWithContextLoadsCertificateThroughAdminAPI 验证发布后通过管理 API 强制加载新证书。
+func TestDeployWithContextLoadsCertificateThroughAdminAPI(t *testing.T) {
+	useMissingCaddyCommand(t)
+	var loaded map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch {
+		case request.Method == http.MethodGet && request.URL.Path == caddyConfigPath:
+			_, _ = writer.Write([]byte(`{"apps":{"http":{"servers":{"srv0":{"listen":[":443"]}}}}}`))
+		case request.Method == http.MethodPost && request.URL.Path == caddyLoadPath:
+			if request.Header.Get("Cache-Control") != "must-revalidate" {
+				t.Errorf("load 请求缺少强制重新加载头")
+			}
+			body, _ := io.ReadAll(request.Body)
+			if err := json.Unmarshal(body, &loaded); err != nil {
+				t.Errorf("解析 load 请求失败: %v", err)
+			}
+		default:
+			http.NotFound(writer, request)
+		}
+	}))
+	defer server.Close()
+
+	sourceDir := t.TempDir()
+	basePath := filepath.Join(t.TempDir(), "caddy")
+	writeTestCertificatePair(t, sourceDir, "example.com")
+	if err := DeployWithContext(context.Background(), sourceDir, "example.com", "example.com", &config.CaddyConfig{Path: basePath, AdminURL: server.URL}); err != nil {
+		t.Fatalf("DeployWithContext: %v", err)
+	}
+
+	certificateFile := filepath.Join(basePath, "example.com", "cert.pem")
+	if _, err := os.Stat(certificateFile); err != nil {
+		t.Fatalf("证书文件未发布: %v", err)
+	}
+	encoded, _ := json.Marshal(loaded)
+	if !strings.Contains(string(encoded), certificateFile) || !strings.Contains(string(encoded), "srv0") {
+		t.Fatalf("load 配置未保留原配置或未引用新证书: %s", encoded)
+	}
+}
+
+// TestDeployWithContextRollsBackWhenLoadFails 验证管理 API 拒绝配置时恢复旧证书目录。
+func TestDeployWithContextRollsBackWhenLoadFails(t *testing.T) {
+	useMissingCaddyCommand(t)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == http.MethodGet {
+			_, _ = writer.Write([]byte("null"))
+			return
+		}
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(`{"error":"loading new config: invalid certificate"}`))
+	}))
+	defer server.Close()
+
+	basePath := t.TempDir()
+	targetDir := filepath.Join(basePath, "example.com")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "cert.pem"), []byte("old"), 0o644); err != nil {
+		t.Fatalf("write old cert: %v", err)
+	}
+
+	sourceDir := t.TempDir()
+	writeTestCertificatePair(t, sourceDir, "example.com")
+	err := DeployWithContext(context.Background(), sourceDir, "example.com", "example.com", &config.CaddyConfig{Path: basePath, AdminURL: server.URL})
+	if err == nil || !strings.Contains(err.Error(), "invalid certificate") {
+		t.Fatalf("加载失败错误不匹配: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(targetDir, "cert.pem"))
+	if err != nil || string(content) != "old" {
+		t.Fatalf("旧证书未恢复: content=%q err=%v", content, err)
+	}
+}
+
+// TestUpsertCaddyLoadFilesKeepsExistingEntry 验证已引用相同证书路径的条目不会重复追加。
+func TestUpsertCaddyLoadFilesKeepsExistingEntry(t *testing.T) {
+	current := []byte(`{"apps":{"tls":{"certificates":{"load_files":[{"certificate":"/etc/caddy/certs/example.com/cert.pem","key":"/etc/caddy/certs/example.com/privateKey.key","tags":["cert0"]}]}}}}`)
+	updated, err := upsertCaddyLoadFiles(current, "/etc/caddy/certs/example.com/cert.pem", "/etc/caddy/certs/example.com/privateKey.key", "anssl-example.com")
+	if err != nil {
+		t.Fatalf("upsertCaddyLoadFiles: %v", err)
+	}
+	if strings.Count(string(updated), "cert.pem") != 1 || !strings.Contains(string(updated), "cert0") {
+		t.Fatalf("已有 load_files 条目被改写: %s", updated)
+	}
+	if _, err := upsertCaddyLoadFiles([]byte(`{"apps":[]}`), "/a/cert.pem", "/a/privateKey.key", "anssl-a"); err == nil {
+		t.Fatal("无效 apps 配置应被拒绝")
+	}
+}
+
+// useMissingCaddyCommand 让测试跳过本机真实 caddy validate。
+func useMissingCaddyCommand(t *testing.T) {
+	t.Helper()
+	original := caddyCommand
+	caddyCommand = filepath.Join(t.TempDir(), "missing-caddy")
+	t.Cleanup(func() {
+		caddyCommand = original
+	})
+}
+
+// writeTestCertificatePair 写入测试用自签证书和匹配私钥。
+func writeTestCertificatePair(t *testing.T, dir, domain string) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0o644); err != nil {
+		t.Fatalf("write cert.pem: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	if err := os.WriteFile(filepath.Join(dir, "privateKey.key"), keyPEM, 0o600); err != nil {
+		t.Fatalf("write privateKey.key: %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	databaseTargetPrefix    = "database-tls-"
+	databaseCommandTimeout  = 30 * time.Second
+	databaseDialTimeout     = 5 * time.Second
+	databaseRollbackTimeout = 30 * time.Second
+	// DatabaseStatusReady 表示实例已配置，部署时写入证书文件并在线重新加载 TLS。
+	DatabaseStatusReady = "Ready"
+)
+
+var (
+	// databaseVerifyTimeout 是重新加载后等待服务端提供新证书的总时长，测试可以缩短。
+	databaseVerifyTimeout = 30 * time.Second
+	// databaseVerifyInterval 是两次握手校验之间的等待时间。
+	databaseVerifyInterval = time.Second
+)
+
+// DatabaseResource 是可以安全上报到 anSSL 后端的数据库实例资源。
+type DatabaseResource struct {
+	TargetRef string // TargetRef 是客户端根据实例名称生成的不透明稳定引用。
+	Label     string // Label 是配置中的实例名称。
+	Kind      string // Kind 是 postgresql、mysql 或 redis。
+	Address   string // Address 是握手校验连接的主机和端口。
+	Status    string // Status 是实例状态。
+}
+
+// IsDatabaseConfiguredWithContext 从 context 快照判断是否配置了数据库实例。
+func IsDatabaseConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && len(configuration.SSL.Databases) > 0
+}
+
+// DiscoverDatabaseResources 把配置中的每个具名数据库实例列为一个部署资源。
+func DiscoverDatabaseResources(ctx context.Context) ([]DatabaseResource, error) {
+	databases, err := getDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]DatabaseResource, 0, len(databases))
+	for _, database := range databases {
+		resources = append(resources, DatabaseResource{
+			TargetRef: buildDatabaseTargetRef(database.Name),
+			Label:     database.Name,
+			Kind:      database.Kind,
+			Address:   net.JoinHostPort(database.Host, strconv.Itoa(database.TLSPort)),
+			Status:    DatabaseStatusReady,
+		})
+	}
+	return resources, nil
+}
+
+// TestDatabaseConnection 检查文件属主可以解析、证书目录可写、管理连接可用且服务端已启用 TLS，不重新加载证书。
+func TestDatabaseConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	database, err := findDatabase(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	if _, _, err := shared.ResolveOwnership(database.Owner, database.Group); err != nil {
+		return err
+	}
+	for _, directory := range []string{filepath.Dir(database.CertFile), filepath.Dir(database.KeyFile)} {
+		probe, err := os.CreateTemp(directory, ".anssl-probe-*")
+		if err != nil {
+			return fmt.Errorf("数据库证书目录不可写: %w", err)
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+	}
+	if err := pingDatabase(ctx, database); err != nil {
+		return fmt.Errorf("%s 管理连接失败: %w", database.Kind, err)
+	}
+	if _, err := fetchDatabaseLeafCertificate(ctx, database, ""); err != nil {
+		return fmt.Errorf("%s TLS 握手失败: %w", database.Kind, err)
+	}
+	return nil
+}
+
+// DeployCertificateToDatabase 按属主和权限替换证书与私钥文件，在线重新加载 TLS 并通过握手确认新证书，失败时恢复旧文件并再次重新加载。
+func DeployCertificateToDatabase(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	database, err := findDatabase(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	fingerprint, err := providers.LeafCertificateSHA256(certificatePEM)
+	if err != nil {
+		return err
+	}
+	uid, gid, err := shared.ResolveOwnership(database.Owner, database.Group)
+	if err != nil {
+		return err
+	}
+	backups := make([]shared.FileBackup, 0, 2)
+	for _, path := range []string{database.CertFile, database.KeyFile} {
+		backup, err := shared.BackupFile(path)
+		if err != nil {
+			return err
+		}
+		backups = append(backups, backup)
+	}
+
+	reloaded := false
+	err = func() error {
+		if err := shared.ReplaceFile(database.CertFile, []byte(certificatePEM), 0o644, uid, gid); err != nil {
+			return fmt.Errorf("写入证书文件失败: %w", err)
+		}
+		// PostgreSQL 拒绝加载其他用户可读的私钥，三种数据库统一使用 0600。
+		if err := shared.ReplaceFile(database.KeyFile, []byte(privateKeyPEM), 0o600, uid, gid); err != nil {
+			return fmt.Errorf("写入私钥文件失败: %w", err)
+		}
+		reloaded = true
+		if err := reloadDatabaseTLS(ctx, database); err != nil {
+			return fmt.Errorf("重新加载 TLS 证书失败: %w", err)
+		}
+		if err := verifyDatabaseCertificate(ctx, database, domain, fingerprint); err != nil {
+			return fmt.Errorf("TLS 握手校验失败: %w", err)
+		}
+		return nil
+	}()
+	if err != nil {
+		if restoreErr := shared.RestoreFiles(backups); restoreErr != nil {
+			logger.Warn("恢复数据库旧证书文件失败", "name", database.Name, "error", restoreErr)
+		} else if reloaded {
+			rollbackContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), databaseRollbackTimeout)
+			defer cancel()
+			if reloadErr := reloadDatabaseTLS(rollbackContext, database); reloadErr != nil {
+				logger.Warn("恢复旧证书后重新加载数据库 TLS 失败", "name", database.Name, "error", reloadErr)
+			}
+		}
+		return fmt.Errorf("数据库 %s 证书部署失败: %w", database.Name, err)
+	}
+	logger.Info("数据库 TLS 证书已更新", "name", database.Name, "kind", database.Kind, "certFile", database.CertFile)
+	return nil
+}
+
+// verifyDatabaseCertificate 在超时内轮询 TLS 端口，直到握手返回的叶证书与新证书指纹一致。
+func verifyDatabaseCertificate(ctx context.Context, database *config.DatabaseConfig, domain, fingerprint string) error {
+	serverName := strings.TrimPrefix(shared.NormalizeCertificateDomain(domain), "*.")
+	// PostgreSQL 的 pg_reload_conf() 只发送 SIGHUP，新连接可能稍后才使用新证书。
+	return shared.WaitForServedCertificate(ctx, fingerprint, time.Now().Add(databaseVerifyTimeout), databaseVerifyInterval, func(ctx context.Context) ([]byte, error) {
+		return fetchDatabaseLeafCertificate(ctx, database, serverName)
+	})
+}
+
+// getDatabases 读取当前操作快照中的数据库实例配置。
+func getDatabases(ctx context.Context) ([]*config.DatabaseConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || len(configuration.SSL.Databases) == 0 {
+		return nil, errors.New("未配置数据库实例 (ssl.databases)")
+	}
+	return configuration.SSL.Databases, nil
+}
+
+// findDatabase 根据 targetRef 重新定位配置中的数据库实例，实例改名或删除后引用失效。
+func findDatabase(ctx context.Context, targetRef string) (*config.DatabaseConfig, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("数据库实例 targetRef 不能为空")
+	}
+	databases, err := getDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, database := range databases {
+		if buildDatabaseTargetRef(database.Name) == targetRef {
+			return database, nil
+		}
+	}
+	return nil, errors.New("数据库实例不存在或已改名，请重新配置部署目标")
+}
+
+// buildDatabaseTargetRef 根据实例名称生成稳定的不透明引用。
+func buildDatabaseTargetRef(name string) string {
+	identity := strings.Join([]string{"ansslCli", "DEPLOYMENT_TYPE_ANSSL_CLI_DATABASE_TLS_CERT", name}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return databaseTargetPrefix + hex.EncodeToString(digest[:12])
+}
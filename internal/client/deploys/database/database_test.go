@@ -0,0 +1,248 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestDeployCertificateToDatabaseReloadsPostgreSQLAndMySQL 验证按属主和 0600 写入私钥、执行在线重新加载语句，并通过协议内 TLS 协商确认新证书。
+func TestDeployCertificateToDatabaseReloadsPostgreSQLAndMySQL(t *testing.T) {
+	for _, kind := range []string{config.DatabaseKindPostgreSQL, config.DatabaseKindMySQL} {
+		t.Run(kind, func(t *testing.T) {
+			root := t.TempDir()
+			database := &config.DatabaseConfig{
+				Name: "db", Kind: kind, Host: "127.0.0.1", Username: "admin", Password: "secret",
+				CertFile: filepath.Join(root, "server.crt"), KeyFile: filepath.Join(root, "server.key"),
+				Owner: strconv.Itoa(os.Getuid()), Binary: installFakeDatabaseClient(t, root),
+			}
+			load := func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+				certificate, err := tls.LoadX509KeyPair(database.CertFile, database.KeyFile)
+				return &certificate, err
+			}
+			negotiate := acceptPostgreSQLTLS
+			if kind == config.DatabaseKindMySQL {
+				negotiate = acceptMySQLTLS
+			}
+			database.Port = startFakeDatabaseServer(t, negotiate, load)
+			database.TLSPort = database.Port
+			oldCertificate, oldKey := generateTestCertificatePair(t, "db.example.com")
+			os.WriteFile(database.CertFile, []byte(oldCertificate), 0o644)
+			os.WriteFile(database.KeyFile, []byte(oldKey), 0o640)
+			ctx := databaseTestContext(database)
+
+			resources, err := DiscoverDatabaseResources(ctx)
+			if err != nil || len(resources) != 1 || resources[0].Kind != kind || !strings.HasPrefix(resources[0].TargetRef, databaseTargetPrefix) {
+				t.Fatalf("发现的数据库资源不匹配: %+v err=%v", resources, err)
+			}
+			if err := TestDatabaseConnection(ctx, resources[0].TargetRef); err != nil {
+				t.Fatalf("TestDatabaseConnection: %v", err)
+			}
+			certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "db.example.com")
+			if err := DeployCertificateToDatabase(ctx, resources[0].TargetRef, "db.example.com", certificatePEM, privateKeyPEM); err != nil {
+				t.Fatalf("DeployCertificateToDatabase: %v", err)
+			}
+			if content, _ := os.ReadFile(database.CertFile); string(content) != certificatePEM {
+				t.Fatal("证书文件未更新")
+			}
+			if info, err := os.Stat(database.KeyFile); err != nil || info.Mode().Perm() != 0o600 {
+				t.Fatalf("私钥权限应为 0600: info=%v err=%v", info, err)
+			}
+			statement := "SELECT pg_reload_conf()"
+			if kind == config.DatabaseKindMySQL {
+				statement = "ALTER INSTANCE RELOAD TLS"
+			}
+			calls, _ := os.ReadFile(filepath.Join(root, "calls"))
+			if lines := strings.Split(strings.TrimSpace(string(calls)), "\n"); len(lines) != 2 || !strings.HasSuffix(lines[0], "SELECT 1 secret") || !strings.HasSuffix(lines[1], statement+" secret") {
+				t.Fatalf("客户端命令调用不匹配:\n%s", calls)
+			}
+		})
+	}
+}
+
+// TestDeployCertificateToDatabaseRollsBackRedisWhenCertificateNotServed 验证 Redis 重新加载后仍提供旧证书时恢复旧文件并再次执行 CONFIG SET。
+func TestDeployCertificateToDatabaseRollsBackRedisWhenCertificateNotServed(t *testing.T) {
+	root := t.TempDir()
+	originalTimeout := databaseVerifyTimeout
+	databaseVerifyTimeout = 0
+	t.Cleanup(func() { databaseVerifyTimeout = originalTimeout })
+	oldCertificate, oldKey := generateTestCertificatePair(t, "cache.example.com")
+	stale, err := tls.X509KeyPair([]byte(oldCertificate), []byte(oldKey))
+	if err != nil {
+		t.Fatalf("load stale certificate: %v", err)
+	}
+	redis := &fakeRedis{}
+	database := &config.DatabaseConfig{
+		Name: "cache", Kind: config.DatabaseKindRedis, Host: "127.0.0.1", Username: "ops", Password: "secret",
+		CertFile: filepath.Join(root, "redis.crt"), KeyFile: filepath.Join(root, "redis.key"),
+		Port:    startFakeDatabaseServer(t, redis.serve, nil),
+		TLSPort: startFakeDatabaseServer(t, nil, func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return &stale, nil }),
+	}
+	os.WriteFile(database.CertFile, []byte(oldCertificate), 0o644)
+	os.WriteFile(database.KeyFile, []byte(oldKey), 0o640)
+	ctx := databaseTestContext(database)
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "cache.example.com")
+	err = DeployCertificateToDatabase(ctx, buildDatabaseTargetRef("cache"), "cache.example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "指纹不一致") {
+		t.Fatalf("握手证书不一致时应返回错误: %v", err)
+	}
+	if content, _ := os.ReadFile(database.CertFile); string(content) != oldCertificate {
+		t.Fatal("旧证书未恢复")
+	}
+	if info, err := os.Stat(database.KeyFile); err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("旧私钥权限未恢复: info=%v err=%v", info, err)
+	}
+	setCommand := "CONFIG SET tls-cert-file " + database.CertFile
+	if commands := redis.log(); commands != "AUTH ops secret;"+setCommand+";AUTH ops secret;"+setCommand {
+		t.Fatalf("恢复旧证书后应再次重新加载: %s", commands)
+	}
+}
+
+// fakeRedis 模拟只接受 AUTH 和 CONFIG SET 的 Redis 明文管理端口。
+type fakeRedis struct {
+	mu       sync.Mutex
+	commands []string
+}
+
+// serve 逐条解析 RESP 命令并记录，全部返回 +OK。
+func (f *fakeRedis) serve(conn net.Conn) bool {
+	reader := bufio.NewReader(conn)
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return false
+		}
+		count, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		args := make([]string, 0, count)
+		for range count {
+			reader.ReadString('\n')
+			value, _ := reader.ReadString('\n')
+			args = append(args, strings.TrimSpace(value))
+		}
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+		f.mu.Unlock()
+		io.WriteString(conn, "+OK\r\n")
+	}
+}
+
+// log 返回以分号连接的命令记录。
+func (f *fakeRedis) log() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return strings.Join(f.commands, ";")
+}
+
+// acceptPostgreSQLTLS 读取 SSLRequest 并回复 S。
+func acceptPostgreSQLTLS(conn net.Conn) bool {
+	request := make([]byte, 8)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return false
+	}
+	conn.Write([]byte{'S'})
+	return true
+}
+
+// acceptMySQLTLS 发送声明支持 TLS 的初始握手包并读取 SSLRequest。
+func acceptMySQLTLS(conn net.Conn) bool {
+	payload := append([]byte{10}, "8.0.36\x00"...)
+	payload = append(payload, 1, 0, 0, 0)
+	payload = append(payload, "12345678\x00"...)
+	payload = append(payload, 0x00, 0x88)
+	conn.Write(append([]byte{byte(len(payload)), 0, 0, 0}, payload...))
+	request := make([]byte, 36)
+	_, err := io.ReadFull(conn, request)
+	return err == nil && request[3] == 1 && request[5]&0x08 != 0
+}
+
+// startFakeDatabaseServer 在随机端口上逐个处理连接：先执行协议协商，getCertificate 非空时再切换为 TLS。
+func startFakeDatabaseServer(t *testing.T, negotiate func(net.Conn) bool, getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
+				if negotiate != nil && !negotiate(conn) {
+					return
+				}
+				if getCertificate != nil {
+					tls.Server(conn, &tls.Config{GetCertificate: getCertificate}).Handshake()
+				}
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// installFakeDatabaseClient 安装模拟 psql 和 mysql 的脚本，记录最后一个参数和密码环境变量。
+func installFakeDatabaseClient(t *testing.T, stateDir string) string {
+	t.Helper()
+	path := filepath.Join(stateDir, "client")
+	script := "#!/bin/sh\n" +
+		"for arg; do last=\"$arg\"; done\n" +
+		"echo \"$last ${PGPASSWORD:-$MYSQL_PWD}\" >> '" + stateDir + "/calls'\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake client: %v", err)
+	}
+	return path
+}
+
+// databaseTestContext 返回只包含数据库配置的操作 context。
+func databaseTestContext(databases ...*config.DatabaseConfig) context.Context {
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{Databases: databases}}})
+}
+
+// generateTestCertificatePair 生成测试用自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -0,0 +1,230 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+const (
+	// postgresSSLRequestCode 是 PostgreSQL SSLRequest 消息中的固定协议号 80877103。
+	postgresSSLRequestCode = 80877103
+	// mysqlClientProtocol41 等是 MySQL 握手能力标志位。
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientSSL              = 0x00000800
+	mysqlClientSecureConnection = 0x00008000
+	// mysqlCharsetUTF8MB4 是 SSLRequest 中声明的 utf8mb4_general_ci 字符集编号。
+	mysqlCharsetUTF8MB4 = 45
+	mysqlMaxPacketSize  = 1 << 24
+)
+
+// pingDatabase 通过管理连接执行只读命令，确认账号和连接参数可用。
+func pingDatabase(ctx context.Context, database *config.DatabaseConfig) error {
+	if database.Kind == config.DatabaseKindRedis {
+		return requestRedis(ctx, database, "PING")
+	}
+	return runDatabaseClient(ctx, database, "SELECT 1")
+}
+
+// reloadDatabaseTLS 使用各数据库的在线方式重新加载证书，不重启服务也不断开现有连接。
+func reloadDatabaseTLS(ctx context.Context, database *config.DatabaseConfig) error {
+	switch database.Kind {
+	case config.DatabaseKindPostgreSQL:
+		return runDatabaseClient(ctx, database, "SELECT pg_reload_conf()")
+	case config.DatabaseKindMySQL:
+		return runDatabaseClient(ctx, database, "ALTER INSTANCE RELOAD TLS")
+	default:
+		// tls-cert-file 即使取值不变也会让 Redis 从磁盘重新读取证书、私钥和 CA。
+		return requestRedis(ctx, database, "CONFIG", "SET", "tls-cert-file", database.CertFile)
+	}
+}
+
+// runDatabaseClient 调用 psql 或 mysql 执行单条语句，密码通过环境变量传递，不出现在进程参数中。
+func runDatabaseClient(parent context.Context, database *config.DatabaseConfig, statement string) error {
+	ctx, cancel := context.WithTimeout(parent, databaseCommandTimeout)
+	defer cancel()
+	port := strconv.Itoa(database.Port)
+	var args []string
+	var passwordVariable string
+	switch database.Kind {
+	case config.DatabaseKindPostgreSQL:
+		// -w 禁止交互式密码提示，-X 不读取 ~/.psqlrc。
+		args = []string{"-h", database.Host, "-p", port, "-U", database.Username, "-d", "postgres", "-w", "-X", "-v", "ON_ERROR_STOP=1", "-c", statement}
+		passwordVariable = "PGPASSWORD"
+	case config.DatabaseKindMySQL:
+		args = []string{"--protocol=TCP", "-h", database.Host, "-P", port, "-u", database.Username, "--batch", "-e", statement}
+		passwordVariable = "MYSQL_PWD"
+	default:
+		return fmt.Errorf("%s 不使用客户端命令", database.Kind)
+	}
+	command := exec.CommandContext(ctx, database.Binary, args...)
+	command.Env = os.Environ()
+	if database.Password != "" {
+		command.Env = append(command.Env, passwordVariable+"="+database.Password)
+	}
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// requestRedis 通过 RESP 协议执行一条 Redis 命令，管理端口与 tls-port 相同时使用 TLS 连接。
+func requestRedis(parent context.Context, database *config.DatabaseConfig, args ...string) error {
+	ctx, cancel := context.WithTimeout(parent, databaseCommandTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{Timeout: databaseDialTimeout}).DialContext(ctx, "tcp", net.JoinHostPort(database.Host, strconv.Itoa(database.Port)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if database.TLSPort == database.Port {
+		// 部署时服务端证书可能正在更换，管理连接只加密不校验证书。
+		tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return err
+		}
+		conn = tlsConn
+	}
+	reader := bufio.NewReader(conn)
+	if database.Password != "" {
+		auth := []string{"AUTH", database.Password}
+		if database.Username != "" {
+			auth = []string{"AUTH", database.Username, database.Password}
+		}
+		if err := redisRoundTrip(conn, reader, auth...); err != nil {
+			return fmt.Errorf("Redis 认证失败: %w", err)
+		}
+	}
+	if err := redisRoundTrip(conn, reader, args...); err != nil {
+		return fmt.Errorf("Redis %s: %w", strings.Join(args[:min(len(args), 2)], " "), err)
+	}
+	return nil
+}
+
+// redisRoundTrip 发送一条 RESP 数组命令并读取单行状态回复，错误回复转换为 error。
+func redisRoundTrip(w io.Writer, r *bufio.Reader, args ...string) error {
+	var request bytes.Buffer
+	fmt.Fprintf(&request, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&request, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := w.Write(request.Bytes()); err != nil {
+		return err
+	}
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	switch {
+	case strings.HasPrefix(line, "+"):
+		return nil
+	case strings.HasPrefix(line, "-"):
+		return errors.New(line[1:])
+	default:
+		return fmt.Errorf("Redis 返回了意外的响应: %q", line)
+	}
+}
+
+// fetchDatabaseLeafCertificate 连接 TLS 端口完成协议协商和握手，返回服务端叶证书。
+func fetchDatabaseLeafCertificate(parent context.Context, database *config.DatabaseConfig, serverName string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(parent, databaseDialTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(database.Host, strconv.Itoa(database.TLSPort)))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	switch database.Kind {
+	case config.DatabaseKindPostgreSQL:
+		err = requestPostgreSQLTLS(conn)
+	case config.DatabaseKindMySQL:
+		err = requestMySQLTLS(conn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return shared.HandshakeServedLeaf(ctx, conn, serverName)
+}
+
+// requestPostgreSQLTLS 发送 SSLRequest 消息，服务端回复 S 后连接切换为 TLS。
+func requestPostgreSQLTLS(conn net.Conn) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgresSSLRequestCode)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+	reply := make([]byte, 1)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != 'S' {
+		return errors.New("PostgreSQL 服务端未启用 TLS")
+	}
+	return nil
+}
+
+// requestMySQLTLS 读取服务端初始握手包，确认支持 TLS 后发送 SSLRequest 包。
+func requestMySQLTLS(conn net.Conn) error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if length == 0 || length > 64*1024 {
+		return fmt.Errorf("MySQL 初始握手包长度异常: %d", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return err
+	}
+	if payload[0] == 0xff {
+		message := ""
+		if len(payload) > 3 {
+			message = string(payload[3:])
+		}
+		return fmt.Errorf("MySQL 拒绝连接: %s", message)
+	}
+	if payload[0] != 10 {
+		return fmt.Errorf("不支持的 MySQL 握手协议版本: %d", payload[0])
+	}
+	// 协议版本之后依次是以 NUL 结尾的版本号、4 字节连接 ID、8 字节盐和 1 字节填充，随后是低 16 位能力标志。
+	versionEnd := bytes.IndexByte(payload[1:], 0)
+	offset := 1 + versionEnd + 1 + 4 + 8 + 1
+	if versionEnd < 0 || len(payload) < offset+2 {
+		return errors.New("MySQL 初始握手包格式异常")
+	}
+	if binary.LittleEndian.Uint16(payload[offset:])&mysqlClientSSL == 0 {
+		return errors.New("MySQL 服务端未启用 TLS")
+	}
+
+	request := make([]byte, 4+32)
+	request[0] = 32
+	request[3] = header[3] + 1
+	binary.LittleEndian.PutUint32(request[4:], mysqlClientProtocol41|mysqlClientSSL|mysqlClientSecureConnection)
+	binary.LittleEndian.PutUint32(request[8:], mysqlMaxPacketSize)
+	request[12] = mysqlCharsetUTF8MB4
+	_, err := conn.Write(request)
+	return err
+}
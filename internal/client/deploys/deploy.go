@@ -184,8 +184,8 @@ func (cd *CertDeployer) DeployCertificate(ctx context.Context, domain, url strin
 	nginxPath := sslConfig.NginxPath
 	apachePath := sslConfig.ApachePath
 	rustFS := sslConfig.RustFS
-	onePanelEnabled := sslConfig.OnePanel != nil && sslConfig.OnePanel.URL != ""
-	safeLineEnabled := sslConfig.SafeLine != nil && sslConfig.SafeLine.URL != "" && sslConfig.SafeLine.APIToken != ""
+	onePanelEnabled := len(config.OnePanelInstances(sslConfig)) > 0
+	safeLineEnabled := len(config.SafeLineInstances(sslConfig)) > 0
 	rustFSEnabled := rustFS != nil && rustFS.Path != ""
 
 	if nginxPath == "" && apachePath == "" && !rustFSEnabled && !onePanelEnabled && !safeLineEnabled {
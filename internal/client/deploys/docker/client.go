@@ -0,0 +1,148 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/config"
+)
+
+const (
+	dockerRequestTimeout      = 30 * time.Second
+	dockerMaxResponseBodySize = 4 * 1024 * 1024
+	// dockerAPIHost 只用于拼接请求 URL，实际连接始终走配置的 Unix socket。
+	dockerAPIHost = "http://docker"
+)
+
+// dockerContainerSummary 描述 /containers/json 列表中的单个容器。
+type dockerContainerSummary struct {
+	ID     string            `json:"Id"`     // ID 是容器完整 ID。
+	Names  []string          `json:"Names"`  // Names 是带前导斜杠的容器名称。
+	Image  string            `json:"Image"`  // Image 是容器使用的镜像名称。
+	Labels map[string]string `json:"Labels"` // Labels 是容器标签。
+	State  string            `json:"State"`  // State 是 running、exited 等容器状态。
+}
+
+// dockerContainerInspect 描述 /containers/{id}/json 中部署后校验需要的状态字段。
+type dockerContainerInspect struct {
+	ID    string `json:"Id"` // ID 是容器完整 ID。
+	State struct {
+		Status     string `json:"Status"`     // Status 是 running、restarting、exited 等容器状态。
+		Running    bool   `json:"Running"`    // Running 表示主进程仍在运行。
+		Restarting bool   `json:"Restarting"` // Restarting 表示容器正在按重启策略重启。
+		StartedAt  string `json:"StartedAt"`  // StartedAt 是主进程最近一次启动时间。
+		Health     *struct {
+			Status string `json:"Status"` // Status 是 starting、healthy 或 unhealthy。
+		} `json:"Health"` // Health 仅在镜像或容器定义了健康检查时存在。
+	} `json:"State"`
+}
+
+// dockerErrorResponse 描述 Docker Engine API 的错误响应。
+type dockerErrorResponse struct {
+	Message string `json:"message"` // Message 是错误原因。
+}
+
+// listDockerContainers 列出带有配置标签的运行中容器。
+func listDockerContainers(ctx context.Context, dockerConfig *config.DockerConfig) ([]dockerContainerSummary, error) {
+	filters, err := json.Marshal(map[string][]string{"label": {dockerConfig.Label}, "status": {"running"}})
+	if err != nil {
+		return nil, fmt.Errorf("编码 Docker 容器过滤条件失败: %w", err)
+	}
+	var containers []dockerContainerSummary
+	if err := requestDockerAPI(ctx, dockerConfig, http.MethodGet, "/containers/json", url.Values{"filters": {string(filters)}}, dockerRequestTimeout, &containers); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+// inspectDockerContainer 读取单个容器的当前状态。
+func inspectDockerContainer(ctx context.Context, dockerConfig *config.DockerConfig, id string) (*dockerContainerInspect, error) {
+	var inspect dockerContainerInspect
+	if err := requestDockerAPI(ctx, dockerConfig, http.MethodGet, "/containers/"+url.PathEscape(id)+"/json", nil, dockerRequestTimeout, &inspect); err != nil {
+		return nil, err
+	}
+	return &inspect, nil
+}
+
+// signalDockerContainer 向容器主进程发送配置的信号。
+func signalDockerContainer(ctx context.Context, dockerConfig *config.DockerConfig, id string) error {
+	return requestDockerAPI(ctx, dockerConfig, http.MethodPost, "/containers/"+url.PathEscape(id)+"/kill", url.Values{"signal": {dockerConfig.Signal}}, dockerRequestTimeout, nil)
+}
+
+// restartDockerContainer 重启容器，Docker 会先等待 timeout 秒让主进程退出。
+func restartDockerContainer(ctx context.Context, dockerConfig *config.DockerConfig, id string) error {
+	timeout := dockerRequestTimeout + time.Duration(dockerConfig.Timeout)*time.Second
+	return requestDockerAPI(ctx, dockerConfig, http.MethodPost, "/containers/"+url.PathEscape(id)+"/restart", url.Values{"t": {strconv.Itoa(dockerConfig.Timeout)}}, timeout, nil)
+}
+
+// requestDockerAPI 在 timeout 内通过 Unix socket 调用 Docker Engine API，非 2xx 响应转换为错误。
+func requestDockerAPI(ctx context.Context, dockerConfig *config.DockerConfig, method, endpoint string, query url.Values, timeout time.Duration, responseData any) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	requestURL := dockerAPIHost + endpoint
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("创建 Docker 请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := newDockerHTTPClient(dockerConfig.Socket).Do(req)
+	if err != nil {
+		return fmt.Errorf("请求 Docker Engine API 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, dockerMaxResponseBodySize+1))
+	if err != nil {
+		return fmt.Errorf("读取 Docker 响应失败: %w", err)
+	}
+	if len(body) > dockerMaxResponseBodySize {
+		return errors.New("Docker 响应体超过最大限制")
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		var errorResponse dockerErrorResponse
+		if json.Unmarshal(body, &errorResponse) == nil && strings.TrimSpace(errorResponse.Message) != "" {
+			return fmt.Errorf("Docker Engine API 返回 HTTP %d: %s", resp.StatusCode, strings.TrimSpace(errorResponse.Message))
+		}
+		return fmt.Errorf("Docker Engine API 返回 HTTP %d", resp.StatusCode)
+	}
+	if responseData == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, responseData); err != nil {
+		return fmt.Errorf("解析 Docker 响应失败: %w", err)
+	}
+	return nil
+}
+
+// newDockerHTTPClient 返回只连接指定 Unix socket 的 HTTP 客户端，请求超时由调用方 context 控制。
+func newDockerHTTPClient(socket string) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return &http.Client{
+		Transport: &http.Transport{
+			// 每次请求都会新建客户端，关闭连接复用避免遗留空闲连接。
+			DisableKeepAlives: true,
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,341 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	dockerTargetPrefix = "docker-container-"
+	// dockerRollbackTimeout 限制回滚时重新通知容器的时长；回滚 context 脱离调用方取消，保证部署被取消后旧证书仍能生效，
+	// 因此必须足够短，并在校验等待中预留出来，使整个部署仍在单次操作超时内结束。
+	dockerRollbackTimeout = 10 * time.Second
+	// DockerStatusRunning 表示容器正在运行，部署时可以发送信号或重启。
+	DockerStatusRunning = "running"
+)
+
+var (
+	// dockerVerifyInterval 是部署后两次读取容器状态的间隔，测试可以缩短。
+	dockerVerifyInterval = time.Second
+	// dockerVerifyTimeout 是部署后等待全部容器恢复运行的最长时间，实际等待还受操作截止时间限制，测试可以缩短。
+	dockerVerifyTimeout = 40 * time.Second
+)
+
+// DockerContainerResource 是可以安全上报到 anSSL 后端的 Docker 容器资源。
+type DockerContainerResource struct {
+	TargetRef string // TargetRef 是客户端根据容器名称生成的不透明稳定引用。
+	Label     string // Label 是容器名称。
+	Domain    string // Domain 是容器标签声明的证书域名。
+	Image     string // Image 是容器使用的镜像名称。
+	Status    string // Status 是容器状态。
+}
+
+// dockerContainer 在 deploy 内部关联容器 ID 和脱敏资源。
+type dockerContainer struct {
+	ID       string                  // ID 是调用 Docker Engine API 使用的容器 ID。
+	Resource DockerContainerResource // Resource 是可以上报的脱敏资源。
+}
+
+// IsDockerConfiguredWithContext 从 context 快照判断是否配置了 Docker 容器部署。
+func IsDockerConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && configuration.SSL.Docker != nil
+}
+
+// DiscoverDockerContainerResources 列出带有配置标签的全部运行中容器。
+func DiscoverDockerContainerResources(ctx context.Context) ([]DockerContainerResource, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	dockerConfig, err := getDockerConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := loadDockerContainers(ctx, dockerConfig)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]DockerContainerResource, 0, len(containers))
+	for _, container := range containers {
+		resources = append(resources, container.Resource)
+	}
+	return resources, nil
+}
+
+// TestDockerContainerConnection 确认 targetRef 对应容器仍在运行，且 bind mount 目录可写，不发送信号或重启。
+func TestDockerContainerConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	dockerConfig, err := getDockerConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if _, _, err := findDockerContainer(ctx, dockerConfig, targetRef); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dockerConfig.Path, 0755); err != nil {
+		return fmt.Errorf("创建 Docker 证书目录失败: %w", err)
+	}
+	probe, err := os.CreateTemp(dockerConfig.Path, ".anssl-probe-*")
+	if err != nil {
+		return fmt.Errorf("Docker 证书目录不可写: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// DeployCertificateToDockerContainer 把证书发布到 path/<标签域名>/，再对同一域名的全部运行中容器发送信号或重启并确认状态。
+func DeployCertificateToDockerContainer(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	dockerConfig, err := getDockerConfig(ctx)
+	if err != nil {
+		return err
+	}
+	target, containers, err := findDockerContainer(ctx, dockerConfig, targetRef)
+	if err != nil {
+		return err
+	}
+	// 容器从 bind mount 中按标签域名读取文件，证书必须覆盖该域名。
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, target.Resource.Domain, time.Now()); err != nil {
+		return err
+	}
+	_, safeDomain, err := shared.NormalizeDeploymentDomain(target.Resource.Domain)
+	if err != nil {
+		return err
+	}
+
+	stagingDir, err := os.MkdirTemp("", "anssl-docker-*")
+	if err != nil {
+		return fmt.Errorf("创建 Docker 临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	if err := os.WriteFile(filepath.Join(stagingDir, "cert.pem"), []byte(certificatePEM), 0o644); err != nil {
+		return fmt.Errorf("写入证书文件失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(stagingDir, "privateKey.key"), []byte(privateKeyPEM), 0o600); err != nil {
+		return fmt.Errorf("写入私钥文件失败: %w", err)
+	}
+	if err := os.MkdirAll(dockerConfig.Path, 0755); err != nil {
+		return fmt.Errorf("创建 Docker 证书目录失败: %w", err)
+	}
+	targetDir, err := shared.SafeJoinUnderBase(dockerConfig.Path, safeDomain)
+	if err != nil {
+		return err
+	}
+
+	startedAt, err := readDockerStartedAt(ctx, dockerConfig, containers)
+	if err != nil {
+		return err
+	}
+	applied := false
+	err = shared.PublishDirectoryWithValidationContext(ctx, stagingDir, targetDir, func() error {
+		applied = true
+		if err := applyDockerAction(ctx, dockerConfig, containers); err != nil {
+			return err
+		}
+		return verifyDockerContainers(ctx, dockerConfig, containers, startedAt)
+	})
+	if err != nil {
+		if applied {
+			// 发布事务已恢复旧证书文件，再执行一次操作让容器重新加载旧证书。
+			rollbackContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), dockerRollbackTimeout)
+			defer cancel()
+			if rollbackErr := applyDockerAction(rollbackContext, dockerConfig, containers); rollbackErr != nil {
+				logger.Warn("恢复旧证书后重新通知 Docker 容器失败", "error", rollbackErr)
+			}
+		}
+		return err
+	}
+	names := make([]string, 0, len(containers))
+	for _, container := range containers {
+		names = append(names, container.Resource.Label)
+	}
+	logger.Info("Docker 容器证书已更新", "domain", target.Resource.Domain, "path", targetDir, "action", dockerConfig.Action, "containers", strings.Join(names, ","))
+	return nil
+}
+
+// getDockerConfig 读取当前操作快照中的 Docker 配置。
+func getDockerConfig(ctx context.Context) (*config.DockerConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || configuration.SSL.Docker == nil {
+		return nil, errors.New("未配置 Docker 容器部署 (ssl.docker)")
+	}
+	return configuration.SSL.Docker, nil
+}
+
+// loadDockerContainers 列出带标签的运行中容器并按名称排序，标签值不是合法域名的容器会被跳过。
+func loadDockerContainers(ctx context.Context, dockerConfig *config.DockerConfig) ([]dockerContainer, error) {
+	summaries, err := listDockerContainers(ctx, dockerConfig)
+	if err != nil {
+		return nil, err
+	}
+	containers := make([]dockerContainer, 0, len(summaries))
+	for _, summary := range summaries {
+		name := dockerContainerName(summary)
+		if name == "" || summary.ID == "" {
+			continue
+		}
+		domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(summary.Labels[dockerConfig.Label])), ".")
+		if _, _, err := shared.NormalizeDeploymentDomain(domain); err != nil {
+			logger.Warn("Docker 容器标签不是合法域名，已跳过", "container", name, "label", dockerConfig.Label, "error", err)
+			continue
+		}
+		containers = append(containers, dockerContainer{
+			ID: summary.ID,
+			Resource: DockerContainerResource{
+				TargetRef: buildDockerTargetRef(name),
+				Label:     name,
+				Domain:    domain,
+				Image:     summary.Image,
+				Status:    summary.State,
+			},
+		})
+	}
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Resource.Label < containers[j].Resource.Label })
+	return containers, nil
+}
+
+// findDockerContainer 根据 targetRef 重新定位容器，并返回标签域名相同的全部运行中容器；容器停止或改名后引用失效。
+func findDockerContainer(ctx context.Context, dockerConfig *config.DockerConfig, targetRef string) (*dockerContainer, []dockerContainer, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, nil, errors.New("Docker 容器 targetRef 不能为空")
+	}
+	containers, err := loadDockerContainers(ctx, dockerConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	var target *dockerContainer
+	for index := range containers {
+		if containers[index].Resource.TargetRef == targetRef {
+			target = &containers[index]
+			break
+		}
+	}
+	if target == nil {
+		return nil, nil, errors.New("Docker 容器不存在、未运行或已移除标签，请重新配置部署目标")
+	}
+	// 同一域名的容器共享 path/<域名>/ 中的文件，必须一起重新加载。
+	matched := make([]dockerContainer, 0, 1)
+	for _, container := range containers {
+		if container.Resource.Domain == target.Resource.Domain {
+			matched = append(matched, container)
+		}
+	}
+	return target, matched, nil
+}
+
+// readDockerStartedAt 记录容器当前的启动时间，用于判断信号是否导致进程退出或重启是否生效。
+func readDockerStartedAt(ctx context.Context, dockerConfig *config.DockerConfig, containers []dockerContainer) (map[string]string, error) {
+	startedAt := make(map[string]string, len(containers))
+	for _, container := range containers {
+		inspect, err := inspectDockerContainer(ctx, dockerConfig, container.ID)
+		if err != nil {
+			return nil, fmt.Errorf("读取 Docker 容器 %s 状态失败: %w", container.Resource.Label, err)
+		}
+		startedAt[container.ID] = inspect.State.StartedAt
+	}
+	return startedAt, nil
+}
+
+// applyDockerAction 按配置向每个容器发送信号或重启容器。
+func applyDockerAction(ctx context.Context, dockerConfig *config.DockerConfig, containers []dockerContainer) error {
+	for _, container := range containers {
+		if dockerConfig.Action == config.DockerActionRestart {
+			if err := restartDockerContainer(ctx, dockerConfig, container.ID); err != nil {
+				return fmt.Errorf("重启 Docker 容器 %s 失败: %w", container.Resource.Label, err)
+			}
+			continue
+		}
+		if err := signalDockerContainer(ctx, dockerConfig, container.ID); err != nil {
+			return fmt.Errorf("向 Docker 容器 %s 发送 %s 失败: %w", container.Resource.Label, dockerConfig.Signal, err)
+		}
+	}
+	return nil
+}
+
+// verifyDockerContainers 在 dockerVerifyTimeout 内等待每个容器恢复运行且健康检查未失败，并为回滚预留操作剩余时间。
+func verifyDockerContainers(ctx context.Context, dockerConfig *config.DockerConfig, containers []dockerContainer, startedAt map[string]string) error {
+	verifyContext, cancel := context.WithTimeout(ctx, providers.OperationWaitTimeout(ctx, dockerVerifyTimeout, dockerRollbackTimeout))
+	defer cancel()
+	for _, container := range containers {
+		if err := waitDockerContainer(verifyContext, dockerConfig, container, startedAt[container.ID]); err != nil {
+			return fmt.Errorf("Docker 容器 %s 状态校验失败: %w", container.Resource.Label, err)
+		}
+	}
+	return nil
+}
+
+// waitDockerContainer 轮询单个容器状态，直到满足当前操作的预期或超时。
+func waitDockerContainer(ctx context.Context, dockerConfig *config.DockerConfig, container dockerContainer, previousStartedAt string) error {
+	var lastErr error
+	for {
+		// 先等待一个间隔再读取，给收到信号的进程留出退出或重新加载的时间。
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return lastErr
+			}
+			return ctx.Err()
+		case <-time.After(dockerVerifyInterval):
+		}
+		inspect, err := inspectDockerContainer(ctx, dockerConfig, container.ID)
+		if err != nil {
+			// 超时打断的读取不覆盖此前观察到的容器状态原因。
+			if ctx.Err() == nil || lastErr == nil {
+				lastErr = err
+			}
+			continue
+		}
+		restarted := inspect.State.StartedAt != previousStartedAt
+		if dockerConfig.Action != config.DockerActionRestart && restarted {
+			return fmt.Errorf("容器主进程收到 %s 后已退出并被重新启动", dockerConfig.Signal)
+		}
+		switch {
+		case inspect.State.Restarting:
+			lastErr = errors.New("容器正在重启")
+		case !inspect.State.Running:
+			lastErr = fmt.Errorf("容器未运行，当前状态 %s", inspect.State.Status)
+		case dockerConfig.Action == config.DockerActionRestart && !restarted:
+			lastErr = errors.New("容器尚未重新启动")
+		case inspect.State.Health != nil && inspect.State.Health.Status == "unhealthy":
+			lastErr = errors.New("容器健康检查失败")
+		case inspect.State.Health != nil && inspect.State.Health.Status == "starting":
+			lastErr = errors.New("容器健康检查尚未通过")
+		default:
+			return nil
+		}
+	}
+}
+
+// dockerContainerName 返回去掉前导斜杠的首个容器名称。
+func dockerContainerName(summary dockerContainerSummary) string {
+	if len(summary.Names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(summary.Names[0]), "/")
+}
+
+// buildDockerTargetRef 根据容器名称生成稳定的不透明引用，容器重建后名称不变即可继续使用。
+func buildDockerTargetRef(name string) string {
+	identity := strings.Join([]string{"ansslCli", "DEPLOYMENT_TYPE_ANSSL_CLI_DOCKER_CONTAINER_CERT", name}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return dockerTargetPrefix + hex.EncodeToString(digest[:12])
+}
@@ -0,0 +1,222 @@
+package docker
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// fakeDockerContainer 是模拟 Docker Engine 中的一个容器。
+type fakeDockerContainer struct {
+	ID        string
+	Name      string
+	Labels    map[string]string
+	Running   bool
+	StartedAt int
+}
+
+// fakeDocker 模拟容器列表、状态读取、发送信号和重启接口。
+type fakeDocker struct {
+	mu          sync.Mutex
+	containers  []*fakeDockerContainer
+	actions     []string
+	failRestart bool
+}
+
+// ServeHTTP 按路径分发模拟 Docker Engine API 请求。
+func (f *fakeDocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Method == http.MethodGet && r.URL.Path == "/containers/json" {
+		var filters map[string][]string
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters); err != nil || len(filters["label"]) != 1 {
+			http.Error(w, `{"message":"invalid filters"}`, http.StatusBadRequest)
+			return
+		}
+		summaries := make([]map[string]any, 0, len(f.containers))
+		for _, container := range f.containers {
+			if _, exists := container.Labels[filters["label"][0]]; !exists || !container.Running {
+				continue
+			}
+			summaries = append(summaries, map[string]any{"Id": container.ID, "Names": []string{"/" + container.Name}, "Image": "nginx:alpine", "Labels": container.Labels, "State": "running"})
+		}
+		json.NewEncoder(w).Encode(summaries)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/containers/"), "/")
+	container := f.find(parts[0])
+	if len(parts) != 2 || container == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "No such container"})
+		return
+	}
+	switch r.Method + " " + parts[1] {
+	case "GET json":
+		status := "exited"
+		if container.Running {
+			status = "running"
+		}
+		json.NewEncoder(w).Encode(map[string]any{"Id": container.ID, "State": map[string]any{"Status": status, "Running": container.Running, "StartedAt": strconv.Itoa(container.StartedAt)}})
+	case "POST kill":
+		f.actions = append(f.actions, container.Name+" "+r.URL.Query().Get("signal"))
+		w.WriteHeader(http.StatusNoContent)
+	case "POST restart":
+		f.actions = append(f.actions, container.Name+" restart t="+r.URL.Query().Get("t"))
+		container.StartedAt++
+		container.Running = !f.failRestart
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// find 根据容器 ID 查找模拟容器。
+func (f *fakeDocker) find(id string) *fakeDockerContainer {
+	for _, container := range f.containers {
+		if container.ID == id {
+			return container
+		}
+	}
+	return nil
+}
+
+// TestDeployCertificateToDockerContainerSignalsMatchingContainers 验证按标签发现容器，并向同一域名的全部容器发送信号。
+func TestDeployCertificateToDockerContainerSignalsMatchingContainers(t *testing.T) {
+	docker := &fakeDocker{containers: []*fakeDockerContainer{
+		{ID: "c1", Name: "web-2", Labels: map[string]string{"anssl.domain": "example.com"}, Running: true},
+		{ID: "c2", Name: "web-1", Labels: map[string]string{"anssl.domain": "Example.com."}, Running: true},
+		{ID: "c3", Name: "api", Labels: map[string]string{"anssl.domain": "api.example.com"}, Running: true},
+		{ID: "c4", Name: "broken", Labels: map[string]string{"anssl.domain": "not a domain"}, Running: true},
+		{ID: "c5", Name: "stopped", Labels: map[string]string{"anssl.domain": "example.com"}},
+		{ID: "c6", Name: "db"},
+	}}
+	basePath := filepath.Join(t.TempDir(), "certs")
+	ctx := dockerTestContext(t, docker, &config.DockerConfig{Path: basePath, Label: "anssl.domain", Action: config.DockerActionSignal, Signal: "SIGHUP", Timeout: 10})
+
+	resources, err := DiscoverDockerContainerResources(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverDockerContainerResources: %v", err)
+	}
+	if len(resources) != 3 || resources[0].Label != "api" || resources[1].Label != "web-1" || resources[1].Domain != "example.com" || !strings.HasPrefix(resources[1].TargetRef, dockerTargetPrefix) {
+		t.Fatalf("发现的容器资源不匹配: %+v", resources)
+	}
+	if err := TestDockerContainerConnection(ctx, resources[1].TargetRef); err != nil {
+		t.Fatalf("TestDockerContainerConnection: %v", err)
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "example.com")
+	if err := DeployCertificateToDockerContainer(ctx, resources[1].TargetRef, "example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToDockerContainer: %v", err)
+	}
+	if strings.Join(docker.actions, ";") != "web-1 SIGHUP;web-2 SIGHUP" {
+		t.Fatalf("容器操作不匹配: %v", docker.actions)
+	}
+	content, err := os.ReadFile(filepath.Join(basePath, "example.com", "cert.pem"))
+	if err != nil || string(content) != certificatePEM {
+		t.Fatalf("证书未写入 bind mount 目录: err=%v", err)
+	}
+	if info, err := os.Stat(filepath.Join(basePath, "example.com", "privateKey.key")); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("私钥权限不匹配: info=%v err=%v", info, err)
+	}
+
+	if err := DeployCertificateToDockerContainer(ctx, resources[0].TargetRef, "example.com", certificatePEM, privateKeyPEM); err == nil {
+		t.Fatal("证书未覆盖容器标签域名时应拒绝部署")
+	}
+}
+
+// TestDeployCertificateToDockerContainerRollsBackWhenRestartFails 验证重启后容器未运行时恢复旧证书并再次重启。
+func TestDeployCertificateToDockerContainerRollsBackWhenRestartFails(t *testing.T) {
+	docker := &fakeDocker{failRestart: true, containers: []*fakeDockerContainer{
+		{ID: "c1", Name: "app", Labels: map[string]string{"com.example.tls": "example.com"}, Running: true},
+	}}
+	basePath := filepath.Join(t.TempDir(), "certs")
+	targetDir := filepath.Join(basePath, "example.com")
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		t.Fatalf("mkdir target: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "cert.pem"), []byte("old"), 0o644); err != nil {
+		t.Fatalf("write old certificate: %v", err)
+	}
+	ctx := dockerTestContext(t, docker, &config.DockerConfig{Path: basePath, Label: "com.example.tls", Action: config.DockerActionRestart, Timeout: 5})
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "example.com")
+	err := DeployCertificateToDockerContainer(ctx, buildDockerTargetRef("app"), "example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "容器未运行") {
+		t.Fatalf("重启后容器未运行应返回错误: %v", err)
+	}
+	if strings.Join(docker.actions, ";") != "app restart t=5;app restart t=5" {
+		t.Fatalf("恢复旧证书后应再次重启容器: %v", docker.actions)
+	}
+	content, err := os.ReadFile(filepath.Join(targetDir, "cert.pem"))
+	if err != nil || string(content) != "old" {
+		t.Fatalf("旧证书未恢复: content=%q err=%v", content, err)
+	}
+}
+
+// dockerTestContext 在临时 Unix socket 上启动模拟 Docker Engine，并返回只包含 Docker 配置的操作 context。
+func dockerTestContext(t *testing.T, handler http.Handler, dockerConfig *config.DockerConfig) context.Context {
+	t.Helper()
+	socketDir, err := os.MkdirTemp("", "docker")
+	if err != nil {
+		t.Fatalf("create socket dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(socketDir) })
+	dockerConfig.Socket = filepath.Join(socketDir, "docker.sock")
+	listener, err := net.Listen("unix", dockerConfig.Socket)
+	if err != nil {
+		t.Fatalf("listen unix socket: %v", err)
+	}
+	server := httptest.NewUnstartedServer(handler)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	originalInterval, originalTimeout := dockerVerifyInterval, dockerVerifyTimeout
+	dockerVerifyInterval, dockerVerifyTimeout = 10*time.Millisecond, 200*time.Millisecond
+	t.Cleanup(func() { dockerVerifyInterval, dockerVerifyTimeout = originalInterval, originalTimeout })
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{Docker: dockerConfig}}})
+}
+
+// generateTestCertificatePair 生成测试用自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -0,0 +1,236 @@
+package feiniu
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/remote"
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	feiNiuDeviceTargetPrefix = "feiniu-device-"
+	// FeiNiuDeviceStatusNoCertificate 表示设备网关配置中没有可读取的证书。
+	FeiNiuDeviceStatusNoCertificate = "NoCertificate"
+)
+
+// FeiNiuDeviceResource 是可以安全上报到 anSSL 后端的飞牛 OS 设备资源。
+type FeiNiuDeviceResource struct {
+	TargetRef string    // TargetRef 是客户端根据设备名称和主机地址生成的不透明稳定引用。
+	Label     string    // Label 是配置中的设备名称。
+	Host      string    // Host 是 host:port 形式的 SSH 地址。
+	Hostname  string    // Hostname 是设备上 hostname 命令返回的主机名。
+	NotAfter  time.Time // NotAfter 是网关当前使用证书中最早的到期时间，没有证书时为零值。
+	Status    string    // Status 是设备证书状态。
+}
+
+// IsFeiNiuDevicesConfiguredWithContext 从 context 快照判断是否配置了具名飞牛设备。
+func IsFeiNiuDevicesConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && len(configuration.SSL.FeiNiuDevices) > 0
+}
+
+// DiscoverFeiNiuDeviceResources 通过 SSH 读取每台飞牛设备的主机名和当前证书到期时间；部分设备不可达时仍返回其余设备。
+func DiscoverFeiNiuDeviceResources(ctx context.Context) ([]FeiNiuDeviceResource, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	devices, err := getFeiNiuDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]FeiNiuDeviceResource, 0, len(devices))
+	var failures []error
+	for _, device := range devices {
+		resource, err := inspectFeiNiuDevice(ctx, device)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			logger.Warn("读取飞牛设备信息失败", "name", device.Name, "error", err)
+			failures = append(failures, fmt.Errorf("飞牛设备 %s: %w", device.Name, err))
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	return resources, errors.Join(failures...)
+}
+
+// TestFeiNiuDeviceConnection 验证指定飞牛设备的 SSH 登录、sudo 权限和部署环境。
+func TestFeiNiuDeviceConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	device, err := findFeiNiuDevice(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	if err := TestRemoteFeiNiuConnectionWithContext(ctx, &device.SSHConfig); err != nil {
+		return fmt.Errorf("飞牛设备 %s: %w", device.Name, err)
+	}
+	return nil
+}
+
+// DeployCertificateToFeiNiuDevice 将证书写入本地临时目录后复用飞牛 SSH 部署流程发布到指定设备。
+func DeployCertificateToFeiNiuDevice(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	device, err := findFeiNiuDevice(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	stagingDir, err := os.MkdirTemp("", "anssl-feiniu-device-*")
+	if err != nil {
+		return fmt.Errorf("创建飞牛设备证书临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	if err := os.WriteFile(filepath.Join(stagingDir, "cert.pem"), []byte(certificatePEM), 0o600); err != nil {
+		return fmt.Errorf("写入飞牛设备证书临时文件失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(stagingDir, "privateKey.key"), []byte(privateKeyPEM), 0o600); err != nil {
+		return fmt.Errorf("写入飞牛设备私钥临时文件失败: %w", err)
+	}
+	if err := DeployRemoteWithContext(ctx, stagingDir, domain, &device.SSHConfig, knownHostsFile(ctx)); err != nil {
+		return fmt.Errorf("部署到飞牛设备 %s 失败: %w", device.Name, err)
+	}
+	return nil
+}
+
+// inspectFeiNiuDevice 登录设备读取主机名，并从网关配置引用的证书文件中取最早到期时间。
+func inspectFeiNiuDevice(ctx context.Context, device *config.FeiNiuDeviceConfig) (FeiNiuDeviceResource, error) {
+	executor, err := remote.NewExecutorContext(ctx, &device.SSHConfig, "飞牛设备 "+device.Name, "anssl-feiniu", knownHostsFile(ctx))
+	if err != nil {
+		return FeiNiuDeviceResource{}, err
+	}
+	defer executor.Close()
+
+	hostnameOutput, err := executor.RunContext(ctx, "hostname", nil, false)
+	if err != nil {
+		return FeiNiuDeviceResource{}, fmt.Errorf("读取主机名失败: %w", err)
+	}
+	gatewayConfig, err := executor.RunContext(ctx, "cat "+remote.QuotePOSIXShellArg(feiniuNginxConfigFile), nil, true)
+	if err != nil {
+		return FeiNiuDeviceResource{}, fmt.Errorf("读取网关证书配置失败: %w", err)
+	}
+	notAfter, err := earliestFeiNiuGatewayExpiry(gatewayConfig, func(certificateFile string) ([]byte, error) {
+		return executor.RunContext(ctx, "cat "+remote.QuotePOSIXShellArg(certificateFile), nil, true)
+	})
+	if err != nil {
+		return FeiNiuDeviceResource{}, err
+	}
+	status := FeiNiuDeviceStatusNoCertificate
+	if !notAfter.IsZero() {
+		status = "Expires " + notAfter.UTC().Format(time.RFC3339)
+	}
+	return FeiNiuDeviceResource{
+		TargetRef: buildFeiNiuDeviceTargetRef(device),
+		Label:     device.Name,
+		Host:      net.JoinHostPort(device.Host, strconv.Itoa(device.Port)),
+		Hostname:  strings.TrimSpace(string(hostnameOutput)),
+		NotAfter:  notAfter,
+		Status:    status,
+	}, nil
+}
+
+// earliestFeiNiuGatewayExpiry 解析飞牛网关证书配置并返回所引用证书中最早的到期时间；单个证书文件不可读时跳过。
+func earliestFeiNiuGatewayExpiry(gatewayConfig []byte, readCertificate func(string) ([]byte, error)) (time.Time, error) {
+	var entries []struct {
+		Host string `json:"host"`
+		Cert string `json:"cert"`
+	}
+	if err := json.Unmarshal(gatewayConfig, &entries); err != nil {
+		return time.Time{}, fmt.Errorf("解析网关证书配置失败: %w", err)
+	}
+	var earliest time.Time
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		if !path.IsAbs(entry.Cert) || path.Clean(entry.Cert) != entry.Cert {
+			continue
+		}
+		if _, exists := seen[entry.Cert]; exists {
+			continue
+		}
+		seen[entry.Cert] = struct{}{}
+		content, err := readCertificate(entry.Cert)
+		if err != nil {
+			logger.WarnLocal("读取飞牛网关证书失败", "host", entry.Host, "path", entry.Cert, "error", err)
+			continue
+		}
+		block, _ := pem.Decode(content)
+		if block == nil || block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		if earliest.IsZero() || certificate.NotAfter.Before(earliest) {
+			earliest = certificate.NotAfter
+		}
+	}
+	return earliest, nil
+}
+
+// getFeiNiuDevices 读取当前操作快照中的飞牛设备配置。
+func getFeiNiuDevices(ctx context.Context) ([]*config.FeiNiuDeviceConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || len(configuration.SSL.FeiNiuDevices) == 0 {
+		return nil, errors.New("未配置飞牛设备 (ssl.feiNiuDevices)")
+	}
+	return configuration.SSL.FeiNiuDevices, nil
+}
+
+// findFeiNiuDevice 根据 targetRef 重新定位配置中的设备，设备改名或更换主机后引用失效。
+func findFeiNiuDevice(ctx context.Context, targetRef string) (*config.FeiNiuDeviceConfig, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("飞牛设备 targetRef 不能为空")
+	}
+	devices, err := getFeiNiuDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, device := range devices {
+		if buildFeiNiuDeviceTargetRef(device) == targetRef {
+			return device, nil
+		}
+	}
+	return nil, errors.New("飞牛设备不存在或配置已变更，请重新配置部署目标")
+}
+
+// buildFeiNiuDeviceTargetRef 根据设备名称和主机地址生成稳定的不透明引用。
+func buildFeiNiuDeviceTargetRef(device *config.FeiNiuDeviceConfig) string {
+	identity := strings.Join([]string{
+		"ansslCli",
+		"DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_DEVICE_CERT",
+		device.Name,
+		strings.ToLower(device.Host),
+		strconv.Itoa(device.Port),
+	}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return feiNiuDeviceTargetPrefix + hex.EncodeToString(digest[:12])
+}
+
+// knownHostsFile 返回运行时配置的 known_hosts 路径，未设置时由 remote 使用默认值。
+func knownHostsFile(ctx context.Context) string {
+	if runtime := shared.RuntimeFromContext(ctx); runtime != nil {
+		return runtime.KnownHostsFile
+	}
+	return ""
+}
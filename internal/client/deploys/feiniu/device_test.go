@@ -0,0 +1,98 @@
+package feiniu
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestEarliestFeiNiuGatewayExpiry 验证从网关配置引用的证书中取最早到期时间，并跳过不可读或非规范路径。
+func TestEarliestFeiNiuGatewayExpiry(t *testing.T) {
+	early := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	late := time.Now().Add(72 * time.Hour).Truncate(time.Second)
+	files := map[string][]byte{
+		"/usr/trim/var/trim_connect/ssls/a.example.com/1/a.example.com.crt": testCertificatePEM(t, late),
+		"/usr/trim/var/trim_connect/ssls/b.example.com/1/b.example.com.crt": testCertificatePEM(t, early),
+	}
+	gatewayConfig := []byte(`[
+  {"host": "a.example.com", "cert": "/usr/trim/var/trim_connect/ssls/a.example.com/1/a.example.com.crt"},
+  {"host": "b.example.com", "cert": "/usr/trim/var/trim_connect/ssls/b.example.com/1/b.example.com.crt"},
+  {"host": "c.example.com", "cert": "/usr/trim/var/trim_connect/ssls/missing.crt"},
+  {"host": "d.example.com", "cert": "relative/d.crt"}
+]`)
+	var read []string
+	notAfter, err := earliestFeiNiuGatewayExpiry(gatewayConfig, func(certificateFile string) ([]byte, error) {
+		read = append(read, certificateFile)
+		if content, ok := files[certificateFile]; ok {
+			return content, nil
+		}
+		return nil, errors.New("not found")
+	})
+	if err != nil || !notAfter.Equal(early) {
+		t.Fatalf("最早到期时间不匹配: got=%v want=%v err=%v", notAfter, early, err)
+	}
+	if len(read) != 3 {
+		t.Fatalf("应只读取绝对规范路径: %v", read)
+	}
+	if notAfter, err := earliestFeiNiuGatewayExpiry([]byte("[]"), nil); err != nil || !notAfter.IsZero() {
+		t.Fatalf("空网关配置应返回零值: %v %v", notAfter, err)
+	}
+	if _, err := earliestFeiNiuGatewayExpiry([]byte("{"), nil); err == nil {
+		t.Fatal("网关配置不是 JSON 数组时应返回错误")
+	}
+}
+
+// TestFindFeiNiuDeviceUsesStableRefs 验证设备引用随名称和主机稳定，且在设备被改动后失效。
+func TestFindFeiNiuDeviceUsesStableRefs(t *testing.T) {
+	home := &config.FeiNiuDeviceConfig{Name: "home", SSHConfig: config.SSHConfig{Host: "192.168.1.20", Port: 22, Username: "admin"}}
+	office := &config.FeiNiuDeviceConfig{Name: "office", SSHConfig: config.SSHConfig{Host: "192.168.1.21", Port: 22, Username: "admin"}}
+	ctx := shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{FeiNiuDevices: []*config.FeiNiuDeviceConfig{home, office}}}})
+
+	officeRef := buildFeiNiuDeviceTargetRef(office)
+	if !strings.HasPrefix(officeRef, feiNiuDeviceTargetPrefix) || officeRef == buildFeiNiuDeviceTargetRef(home) {
+		t.Fatalf("设备引用不唯一: %s", officeRef)
+	}
+	device, err := findFeiNiuDevice(ctx, officeRef)
+	if err != nil || device != office {
+		t.Fatalf("未定位到所选设备: %v %v", device, err)
+	}
+	office.Host = "192.168.1.22"
+	if _, err := findFeiNiuDevice(ctx, officeRef); err == nil {
+		t.Fatal("设备主机变更后旧引用应失效")
+	}
+	if IsFeiNiuDevicesConfiguredWithContext(context.Background()) {
+		t.Fatal("未注入配置时不应视为已配置飞牛设备")
+	}
+}
+
+// testCertificatePEM 生成指定到期时间的自签证书。
+func testCertificatePEM(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "feiniu.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+}
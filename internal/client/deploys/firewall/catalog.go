@@ -0,0 +1,142 @@
+package firewall
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+const (
+	firewallTargetPrefix = "firewall-"
+	// FirewallStatusReady 表示防火墙已配置，部署时才调用 REST API。
+	FirewallStatusReady = "Ready"
+)
+
+// FirewallResource 是可以安全上报到 anSSL 后端的防火墙证书管理器资源。
+type FirewallResource struct {
+	TargetRef string // TargetRef 是客户端根据防火墙名称和管理端地址生成的不透明稳定引用。
+	Label     string // Label 是配置中的防火墙名称。
+	Kind      string // Kind 是防火墙类型 opnsense 或 pfsense。
+	Status    string // Status 是防火墙状态。
+}
+
+// IsFirewallConfiguredWithContext 从 context 快照判断是否配置了防火墙。
+func IsFirewallConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && len(configuration.SSL.Firewalls) > 0
+}
+
+// DiscoverFirewallResources 把配置中的每台防火墙列为一个部署资源，发现阶段不调用 REST API。
+func DiscoverFirewallResources(ctx context.Context) ([]FirewallResource, error) {
+	firewalls, err := getFirewallConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]FirewallResource, 0, len(firewalls))
+	for _, firewall := range firewalls {
+		resources = append(resources, FirewallResource{
+			TargetRef: buildFirewallTargetRef(firewall),
+			Label:     firewall.Name,
+			Kind:      firewall.Kind,
+			Status:    FirewallStatusReady,
+		})
+	}
+	return resources, nil
+}
+
+// TestFirewallConnection 只读确认 API 凭据可以列出证书，并按配置检查 Web 管理界面和 HAProxy 接口。
+func TestFirewallConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	firewall, err := findFirewall(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	client, err := newFirewallClient(firewall)
+	if err != nil {
+		return err
+	}
+	if _, err := client.listCertificates(ctx); err != nil {
+		return fmt.Errorf("读取防火墙 %s 证书列表失败: %w", firewall.Name, err)
+	}
+	if firewall.WebGUI {
+		if _, err := client.webGUICertificate(ctx); err != nil {
+			return fmt.Errorf("读取防火墙 %s Web 管理界面设置失败: %w", firewall.Name, err)
+		}
+	}
+	if firewall.HAProxy {
+		if _, err := client.haproxyFrontends(ctx); err != nil {
+			return fmt.Errorf("读取防火墙 %s HAProxy 前端失败: %w", firewall.Name, err)
+		}
+	}
+	return nil
+}
+
+// getFirewallConfigs 从 context 快照读取防火墙配置。
+func getFirewallConfigs(ctx context.Context) ([]*config.FirewallConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || len(configuration.SSL.Firewalls) == 0 {
+		return nil, errors.New("未配置防火墙 (ssl.firewalls)")
+	}
+	return configuration.SSL.Firewalls, nil
+}
+
+// findFirewall 根据 targetRef 重新定位防火墙配置。
+func findFirewall(ctx context.Context, targetRef string) (*config.FirewallConfig, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("防火墙 targetRef 不能为空")
+	}
+	firewalls, err := getFirewallConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, firewall := range firewalls {
+		if buildFirewallTargetRef(firewall) == targetRef {
+			return firewall, nil
+		}
+	}
+	return nil, errors.New("防火墙不存在或名称、地址已修改，请重新配置部署目标")
+}
+
+// buildFirewallTargetRef 根据防火墙名称和管理端地址生成稳定的不透明引用。
+func buildFirewallTargetRef(firewall *config.FirewallConfig) string {
+	identity := strings.Join([]string{
+		"ansslCli",
+		"DEPLOYMENT_TYPE_ANSSL_CLI_FIREWALL_CERT",
+		normalizeFirewallOrigin(firewall.URL),
+		firewall.Name,
+	}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return firewallTargetPrefix + hex.EncodeToString(digest[:12])
+}
+
+// normalizeFirewallOrigin 规范化仅用于本地哈希的管理端来源，不返回或记录该值。
+func normalizeFirewallOrigin(apiURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(apiURL))
+	if err != nil {
+		return strings.ToLower(strings.TrimRight(strings.TrimSpace(apiURL), "/"))
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	hostname := strings.ToLower(parsed.Hostname())
+	port := parsed.Port()
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+	host := hostname
+	if port != "" {
+		host = net.JoinHostPort(hostname, port)
+	} else if strings.Contains(hostname, ":") {
+		host = "[" + hostname + "]"
+	}
+	return scheme + "://" + host + strings.TrimRight(parsed.Path, "/")
+}
@@ -0,0 +1,194 @@
+package firewall
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/config"
+)
+
+const (
+	firewallRequestTimeout      = 30 * time.Second
+	firewallMaxResponseBodySize = 4 * 1024 * 1024
+	firewallReadbackAttempts    = 10
+	firewallReadbackInterval    = 2 * time.Second
+)
+
+// firewallRequestError 保存仅供 deploy 本地判断重试属性的防火墙 API 错误。
+type firewallRequestError struct {
+	Retryable bool  // Retryable 表示网络或服务端错误可以稍后重试。
+	Cause     error // Cause 不得写入 WebSocket 响应；在线日志必须先经过统一脱敏。
+}
+
+// Error 返回防火墙本地诊断信息。
+func (e *firewallRequestError) Error() string {
+	if e == nil || e.Cause == nil {
+		return "防火墙请求失败"
+	}
+	return e.Cause.Error()
+}
+
+// Unwrap 返回原始错误，供 errors.Is 和 errors.As 使用。
+func (e *firewallRequestError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Cause
+}
+
+// IsFirewallErrorRetryable 判断防火墙操作是否适合由后端稍后重试。
+func IsFirewallErrorRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var requestError *firewallRequestError
+	if errors.As(err, &requestError) {
+		return requestError.Retryable
+	}
+	var networkError net.Error
+	return errors.As(err, &networkError) && networkError.Timeout()
+}
+
+// firewallCertificate 描述证书管理器中的一条证书记录。
+type firewallCertificate struct {
+	ID          string // ID 是 API 定位证书使用的 UUID 或数组下标。
+	RefID       string // RefID 是 Web 管理界面和 HAProxy 引用证书使用的 refid。
+	Description string // Description 是证书描述。
+}
+
+// firewallFrontend 描述 HAProxy 插件中的一个前端及其引用的证书。
+type firewallFrontend struct {
+	ID              string   // ID 是 API 定位前端使用的 UUID 或数组下标。
+	Name            string   // Name 是前端名称。
+	DefaultRef      string   // DefaultRef 是前端的默认证书 refid。
+	CertificateRefs []string // CertificateRefs 是前端额外引用的证书 refid。
+}
+
+// firewallClient 屏蔽 OPNsense 和 pfSense REST API 的差异。
+type firewallClient interface {
+	// listCertificates 读取证书管理器中的全部证书。
+	listCertificates(ctx context.Context) ([]firewallCertificate, error)
+	// importCertificate 导入证书和私钥并返回新记录。
+	importCertificate(ctx context.Context, description, certificatePEM, privateKeyPEM string) (firewallCertificate, error)
+	// certificatePEM 读取证书记录保存的 PEM 证书。
+	certificatePEM(ctx context.Context, certificate firewallCertificate) (string, error)
+	// deleteCertificate 删除证书记录。
+	deleteCertificate(ctx context.Context, certificate firewallCertificate) error
+	// webGUICertificate 返回 Web 管理界面当前使用的证书 refid。
+	webGUICertificate(ctx context.Context) (string, error)
+	// setWebGUICertificate 把 Web 管理界面切换到指定证书。
+	setWebGUICertificate(ctx context.Context, refID string) error
+	// haproxyFrontends 读取 HAProxy 插件的全部前端。
+	haproxyFrontends(ctx context.Context) ([]firewallFrontend, error)
+	// setHAProxyFrontendCertificates 按 frontend 中的 refid 替换前端引用的证书。
+	setHAProxyFrontendCertificates(ctx context.Context, frontend firewallFrontend) error
+	// applyHAProxy 让 HAProxy 插件加载已保存的配置。
+	applyHAProxy(ctx context.Context) error
+}
+
+// newFirewallClient 按防火墙类型创建 REST API 客户端。
+func newFirewallClient(firewall *config.FirewallConfig) (firewallClient, error) {
+	switch firewall.Kind {
+	case config.FirewallKindOPNsense:
+		return &opnsenseClient{firewall: firewall}, nil
+	case config.FirewallKindPfSense:
+		return &pfsenseClient{firewall: firewall}, nil
+	default:
+		return nil, fmt.Errorf("不支持的防火墙类型: %s", firewall.Kind)
+	}
+}
+
+// requestFirewallAPI 以 JSON 调用防火墙 REST API，authorize 负责写入各自的鉴权头。
+func requestFirewallAPI(ctx context.Context, firewall *config.FirewallConfig, authorize func(*http.Request), method, endpoint string, query url.Values, payload any, responseData any) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("编码防火墙请求失败: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+	requestURL := strings.TrimRight(strings.TrimSpace(firewall.URL), "/") + endpoint
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return fmt.Errorf("创建防火墙请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	authorize(req)
+
+	resp, err := newFirewallHTTPClient(firewall.InsecureSkipVerify).Do(req)
+	if err != nil {
+		return &firewallRequestError{Retryable: true, Cause: fmt.Errorf("请求防火墙 API 失败: %w", err)}
+	}
+	defer resp.Body.Close()
+	responseBody, err := io.ReadAll(io.LimitReader(resp.Body, firewallMaxResponseBodySize+1))
+	if err != nil {
+		return &firewallRequestError{Retryable: true, Cause: fmt.Errorf("读取防火墙响应失败: %w", err)}
+	}
+	if len(responseBody) > firewallMaxResponseBodySize {
+		return &firewallRequestError{Retryable: false, Cause: errors.New("防火墙响应体超过最大限制")}
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return &firewallRequestError{Retryable: retryable, Cause: fmt.Errorf("防火墙 API 返回 HTTP %d%s", resp.StatusCode, firewallErrorDetail(responseBody))}
+	}
+	if responseData == nil {
+		return nil
+	}
+	if err := json.Unmarshal(responseBody, responseData); err != nil {
+		return &firewallRequestError{Retryable: false, Cause: fmt.Errorf("解析防火墙响应失败: %w", err)}
+	}
+	return nil
+}
+
+// firewallErrorDetail 提取 pfSense message 或 OPNsense errorMessage 字段作为本地诊断信息。
+func firewallErrorDetail(responseBody []byte) string {
+	var detail struct {
+		Message      string `json:"message"`      // Message 是 pfSense REST API 的错误说明。
+		ErrorMessage string `json:"errorMessage"` // ErrorMessage 是 OPNsense 的错误说明。
+	}
+	if json.Unmarshal(responseBody, &detail) != nil {
+		return ""
+	}
+	for _, message := range []string{detail.Message, detail.ErrorMessage} {
+		if message = strings.TrimSpace(message); message != "" {
+			return ": " + message
+		}
+	}
+	return ""
+}
+
+// newFirewallHTTPClient 为每台防火墙配置独立 TLS 策略，避免影响其他 HTTP 客户端。
+func newFirewallHTTPClient(insecureSkipVerify bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: insecureSkipVerify} //nolint:gosec // 仅在用户显式配置后允许自签名管理端。
+	return &http.Client{
+		Timeout:   firewallRequestTimeout,
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
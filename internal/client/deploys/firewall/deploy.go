@@ -0,0 +1,287 @@
+package firewall
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const firewallDescriptionPrefix = "anssl "
+
+// firewallLocks 按防火墙名称串行化部署，避免 pfSense 证书下标在并发删除时错位。
+var firewallLocks sync.Map
+
+// firewallDeployPlan 保存预检阶段读取的防火墙状态。
+type firewallDeployPlan struct {
+	current    *firewallCertificate  // current 是已导入的相同叶证书，为空时需要导入。
+	superseded []firewallCertificate // superseded 是同一域名的旧证书。
+	frontends  []firewallFrontend    // frontends 是启用 HAProxy 时读取的前端。
+}
+
+// DeployCertificateToFirewall 导入证书到防火墙证书管理器，按配置切换 Web 管理界面和 HAProxy 前端，再删除同一域名的旧证书。
+func DeployCertificateToFirewall(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	firewall, err := findFirewall(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	canonicalDomain, _, err := shared.NormalizeDeploymentDomain(domain)
+	if err != nil {
+		return err
+	}
+	description, err := firewallCertificateDescription(canonicalDomain, certificatePEM)
+	if err != nil {
+		return err
+	}
+	client, err := newFirewallClient(firewall)
+	if err != nil {
+		return err
+	}
+	lock, _ := firewallLocks.LoadOrStore(firewall.Name, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	plan, err := preflightFirewall(ctx, client, firewall.HAProxy, canonicalDomain, certificatePEM)
+	if err != nil {
+		return fmt.Errorf("预检防火墙 %s 失败: %w", firewall.Name, err)
+	}
+	if plan.current == nil {
+		imported, err := client.importCertificate(ctx, description, certificatePEM, privateKeyPEM)
+		if err != nil {
+			return fmt.Errorf("导入防火墙 %s 证书失败: %w", firewall.Name, err)
+		}
+		exported, err := client.certificatePEM(ctx, imported)
+		if err != nil {
+			return fmt.Errorf("回读防火墙 %s 证书失败: %w", firewall.Name, err)
+		}
+		if err := providers.VerifyLeafCertificateSHA256(certificatePEM, exported); err != nil {
+			return fmt.Errorf("校验防火墙 %s 回读证书失败: %w", firewall.Name, err)
+		}
+		plan.current = &imported
+	}
+
+	supersededRefs := make(map[string]struct{}, len(plan.superseded))
+	for _, certificate := range plan.superseded {
+		supersededRefs[certificate.RefID] = struct{}{}
+	}
+	if firewall.WebGUI {
+		if err := switchFirewallWebGUI(ctx, client, plan.current.RefID); err != nil {
+			return fmt.Errorf("切换防火墙 %s Web 管理界面证书失败: %w", firewall.Name, err)
+		}
+	}
+	switched := 0
+	if firewall.HAProxy {
+		switched, err = switchFirewallFrontends(ctx, client, plan.frontends, supersededRefs, plan.current.RefID)
+		if err != nil {
+			return fmt.Errorf("切换防火墙 %s HAProxy 前端证书失败: %w", firewall.Name, err)
+		}
+		// 没有前端引用该域名的证书时只提示运维手动选择，证书仍然导入并照常清理旧证书，避免每次续期都失败。
+		if switched == 0 && !frontendsReference(plan.frontends, plan.current.RefID) {
+			logger.Warn("没有 HAProxy 前端引用该域名的证书，请在需要的前端中选择已导入的证书", "name", firewall.Name, "domain", canonicalDomain, "description", description)
+		}
+	}
+
+	deleteSupersededCertificates(ctx, client, firewall.Name, plan.superseded)
+	logger.Info("防火墙证书已更新", "name", firewall.Name, "kind", firewall.Kind, "domain", canonicalDomain, "webGui", firewall.WebGUI, "frontends", switched)
+	return nil
+}
+
+// preflightFirewall 只读获取证书列表和 HAProxy 前端，确认写入前所有依赖接口都可用。
+func preflightFirewall(ctx context.Context, client firewallClient, haproxy bool, domain, certificatePEM string) (firewallDeployPlan, error) {
+	var plan firewallDeployPlan
+	certificates, err := client.listCertificates(ctx)
+	if err != nil {
+		return plan, err
+	}
+	prefix := firewallDescriptionPrefix + domain + " "
+	for _, certificate := range certificates {
+		if !strings.HasPrefix(certificate.Description, prefix) {
+			continue
+		}
+		// 上次部署可能在导入后中断，相同叶证书直接复用，避免重复导入。
+		if plan.current == nil {
+			existing, err := client.certificatePEM(ctx, certificate)
+			if err != nil {
+				return plan, err
+			}
+			if providers.VerifyLeafCertificateSHA256(certificatePEM, existing) == nil {
+				current := certificate
+				plan.current = &current
+				continue
+			}
+		}
+		plan.superseded = append(plan.superseded, certificate)
+	}
+	if haproxy {
+		if plan.frontends, err = client.haproxyFrontends(ctx); err != nil {
+			return plan, err
+		}
+	}
+	return plan, nil
+}
+
+// switchFirewallWebGUI 把 Web 管理界面切换到新证书并回读确认。
+func switchFirewallWebGUI(ctx context.Context, client firewallClient, refID string) error {
+	current, err := client.webGUICertificate(ctx)
+	if err != nil {
+		return err
+	}
+	if current == refID {
+		return nil
+	}
+	if err := client.setWebGUICertificate(ctx, refID); err != nil {
+		return err
+	}
+	return verifyFirewallWebGUI(ctx, client, refID)
+}
+
+// verifyFirewallWebGUI 轮询回读 Web 管理界面证书；pfSense 保存设置后会重启 Web 服务，期间的请求失败不视为部署失败。
+func verifyFirewallWebGUI(ctx context.Context, client firewallClient, refID string) error {
+	var lastErr error
+	for attempt := 0; attempt < firewallReadbackAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(firewallReadbackInterval):
+			}
+		}
+		current, err := client.webGUICertificate(ctx)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if current != refID {
+			return errors.New("回读的 Web 管理界面证书与新证书不一致")
+		}
+		return nil
+	}
+	return fmt.Errorf("回读 Web 管理界面证书失败: %w", lastErr)
+}
+
+// switchFirewallFrontends 把引用旧证书的前端切换到新证书，应用配置后回读确认，返回切换的前端数量。
+func switchFirewallFrontends(ctx context.Context, client firewallClient, frontends []firewallFrontend, supersededRefs map[string]struct{}, refID string) (int, error) {
+	changed := make(map[string]firewallFrontend)
+	for _, frontend := range frontends {
+		updated, ok := replaceFrontendCertificates(frontend, supersededRefs, refID)
+		if !ok {
+			continue
+		}
+		if err := client.setHAProxyFrontendCertificates(ctx, updated); err != nil {
+			return 0, fmt.Errorf("前端 %s: %w", frontend.Name, err)
+		}
+		changed[updated.ID] = updated
+	}
+	switched := len(changed)
+	if switched == 0 {
+		return 0, nil
+	}
+	if err := client.applyHAProxy(ctx); err != nil {
+		return 0, err
+	}
+	readback, err := client.haproxyFrontends(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, frontend := range readback {
+		expected, ok := changed[frontend.ID]
+		if !ok {
+			continue
+		}
+		if frontend.DefaultRef != expected.DefaultRef || !slices.Equal(sortedRefs(frontend.CertificateRefs), sortedRefs(expected.CertificateRefs)) {
+			return 0, fmt.Errorf("回读的前端 %s 证书与新证书不一致", frontend.Name)
+		}
+		delete(changed, frontend.ID)
+	}
+	if len(changed) > 0 {
+		return 0, errors.New("回读时未找到已切换的 HAProxy 前端")
+	}
+	return switched, nil
+}
+
+// frontendsReference 判断是否有前端把指定证书作为默认证书或 SNI 证书引用。
+func frontendsReference(frontends []firewallFrontend, refID string) bool {
+	for _, frontend := range frontends {
+		if frontend.DefaultRef == refID || slices.Contains(frontend.CertificateRefs, refID) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceFrontendCertificates 把前端中的旧证书 refid 替换为新 refid 并去重，未引用旧证书时返回 false。
+func replaceFrontendCertificates(frontend firewallFrontend, supersededRefs map[string]struct{}, refID string) (firewallFrontend, bool) {
+	replaced := false
+	if _, ok := supersededRefs[frontend.DefaultRef]; ok {
+		frontend.DefaultRef = refID
+		replaced = true
+	}
+	refs := make([]string, 0, len(frontend.CertificateRefs))
+	for _, ref := range frontend.CertificateRefs {
+		if _, ok := supersededRefs[ref]; ok {
+			ref = refID
+			replaced = true
+		}
+		if !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	frontend.CertificateRefs = refs
+	return frontend, replaced
+}
+
+// deleteSupersededCertificates 删除同一域名的旧证书；仍被其他服务引用时防火墙会拒绝删除，此时只记录警告，不影响已生效的新证书。
+func deleteSupersededCertificates(ctx context.Context, client firewallClient, name string, superseded []firewallCertificate) {
+	// pfSense 使用数组下标定位证书，从大到小删除才能保证剩余下标不变。
+	sort.SliceStable(superseded, func(i, j int) bool {
+		left, leftErr := strconv.Atoi(superseded[i].ID)
+		right, rightErr := strconv.Atoi(superseded[j].ID)
+		if leftErr == nil && rightErr == nil {
+			return left > right
+		}
+		return superseded[i].ID > superseded[j].ID
+	})
+	for _, certificate := range superseded {
+		if err := client.deleteCertificate(ctx, certificate); err != nil {
+			logger.Warn("删除防火墙旧证书失败", "name", name, "description", certificate.Description, "error", err)
+		}
+	}
+}
+
+// firewallCertificateDescription 生成 "anssl <域名> <到期日>" 形式的证书描述，用于识别同一域名的旧证书。
+func firewallCertificateDescription(domain, certificatePEM string) (string, error) {
+	block, _ := pem.Decode([]byte(certificatePEM))
+	if block == nil {
+		return "", errors.New("未找到 PEM 证书块")
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("解析证书失败: %w", err)
+	}
+	return firewallDescriptionPrefix + domain + " " + leaf.NotAfter.UTC().Format("2006-01-02"), nil
+}
+
+// sortedRefs 返回排序后的 refid 副本。
+func sortedRefs(refs []string) []string {
+	sorted := append([]string(nil), refs...)
+	sort.Strings(sorted)
+	return sorted
+}
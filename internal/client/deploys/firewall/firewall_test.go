@@ -0,0 +1,350 @@
+package firewall
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+// fakeFirewallCertificate 是模拟证书管理器中保存的一张证书。
+type fakeFirewallCertificate struct {
+	UUID        string
+	RefID       string
+	Description string
+	Certificate string
+}
+
+// fakePfSense 模拟 pfSense REST API v2 的证书、Web 管理界面和 HAProxy 接口。
+type fakePfSense struct {
+	mu           sync.Mutex
+	certificates []*fakeFirewallCertificate
+	webGUIRef    string
+	restarting   bool // restarting 模拟保存 Web 管理界面设置后 Web 服务重启，下一次读取设置失败。
+	offloadCert  string
+	deleted      []string
+	applied      int
+	nextRef      int
+}
+
+// ServeHTTP 按路径分发模拟 pfSense 请求，证书 id 使用数组下标。
+func (f *fakePfSense) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get(pfsenseAPIKeyHeader) != "key-1" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]any{"message": "Authentication failed"})
+		return
+	}
+	var body map[string]any
+	json.NewDecoder(r.Body).Decode(&body)
+	id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+	switch r.Method + " " + r.URL.Path {
+	case "GET " + pfsenseCertificatesPath:
+		data := make([]map[string]any, 0, len(f.certificates))
+		for index, certificate := range f.certificates {
+			data = append(data, map[string]any{"id": index, "refid": certificate.RefID, "descr": certificate.Description, "crt": certificate.Certificate})
+		}
+		writePfSenseResponse(w, data)
+	case "POST " + pfsenseCertificatePath:
+		f.nextRef++
+		certificate := &fakeFirewallCertificate{RefID: "ref-new-" + strconv.Itoa(f.nextRef), Description: body["descr"].(string), Certificate: body["crt"].(string)}
+		f.certificates = append(f.certificates, certificate)
+		writePfSenseResponse(w, map[string]any{"id": len(f.certificates) - 1, "refid": certificate.RefID, "descr": certificate.Description})
+	case "GET " + pfsenseCertificatePath:
+		certificate := f.certificates[id]
+		writePfSenseResponse(w, map[string]any{"id": id, "refid": certificate.RefID, "descr": certificate.Description, "crt": base64.StdEncoding.EncodeToString([]byte(certificate.Certificate))})
+	case "DELETE " + pfsenseCertificatePath:
+		f.deleted = append(f.deleted, f.certificates[id].RefID)
+		f.certificates = append(f.certificates[:id], f.certificates[id+1:]...)
+		writePfSenseResponse(w, nil)
+	case "GET " + pfsenseWebGUISettingsPath:
+		if f.restarting {
+			f.restarting = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writePfSenseResponse(w, map[string]any{"sslcertref": f.webGUIRef})
+	case "PATCH " + pfsenseWebGUISettingsPath:
+		f.webGUIRef = body["sslcertref"].(string)
+		f.restarting = true
+		writePfSenseResponse(w, nil)
+	case "GET " + pfsenseHAProxyFrontends:
+		writePfSenseResponse(w, []map[string]any{
+			{"id": 0, "name": "web", "ssloffloadcert": f.offloadCert, "ha_certificates": []any{}},
+			{"id": 1, "name": "other", "ssloffloadcert": "ref-other", "ha_certificates": []any{}},
+		})
+	case "PATCH " + pfsenseHAProxyFrontend:
+		if body["id"].(float64) != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.offloadCert = body["ssloffloadcert"].(string)
+		writePfSenseResponse(w, nil)
+	case "POST " + pfsenseHAProxyApplyPath:
+		f.applied++
+		writePfSenseResponse(w, nil)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// TestDeployCertificateToPfSenseSwitchesReferencesAndDeletesSuperseded 验证 pfSense 导入回读、切换 Web 管理界面并等待 Web 服务重启后回读、切换 HAProxy 前端，并从大到小删除旧证书。
+func TestDeployCertificateToPfSenseSwitchesReferencesAndDeletesSuperseded(t *testing.T) {
+	oldCertificate, _ := generateTestCertificatePair(t, "fw.example.com")
+	olderCertificate, _ := generateTestCertificatePair(t, "fw.example.com")
+	pfsense := &fakePfSense{
+		certificates: []*fakeFirewallCertificate{
+			{RefID: "ref-older", Description: "anssl fw.example.com 2020-01-01", Certificate: olderCertificate},
+			{RefID: "ref-other", Description: "anssl fw.example.com.cn 2020-01-01", Certificate: olderCertificate},
+			{RefID: "ref-old", Description: "anssl fw.example.com 2020-02-01", Certificate: oldCertificate},
+		},
+		webGUIRef:   "ref-old",
+		offloadCert: "ref-older",
+	}
+	server := httptest.NewServer(pfsense)
+	defer server.Close()
+	ctx := firewallTestContext(&config.FirewallConfig{Name: "edge", Kind: config.FirewallKindPfSense, URL: server.URL, APIKey: "key-1", WebGUI: true, HAProxy: true})
+
+	resources, err := DiscoverFirewallResources(ctx)
+	if err != nil || len(resources) != 1 || resources[0].Label != "edge" || !strings.HasPrefix(resources[0].TargetRef, firewallTargetPrefix) {
+		t.Fatalf("发现的防火墙不匹配: %+v, %v", resources, err)
+	}
+	if err := TestFirewallConnection(ctx, resources[0].TargetRef); err != nil {
+		t.Fatalf("TestFirewallConnection: %v", err)
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "fw.example.com")
+	if err := DeployCertificateToFirewall(ctx, resources[0].TargetRef, "fw.example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToFirewall: %v", err)
+	}
+	if pfsense.webGUIRef != "ref-new-1" || pfsense.offloadCert != "ref-new-1" || pfsense.applied != 1 {
+		t.Fatalf("引用未切换到新证书: webGui=%s offload=%s applied=%d", pfsense.webGUIRef, pfsense.offloadCert, pfsense.applied)
+	}
+	if strings.Join(pfsense.deleted, ",") != "ref-old,ref-older" {
+		t.Fatalf("旧证书应从大下标到小下标删除: %v", pfsense.deleted)
+	}
+	if len(pfsense.certificates) != 2 || pfsense.certificates[0].RefID != "ref-other" || !strings.HasPrefix(pfsense.certificates[1].Description, "anssl fw.example.com ") {
+		t.Fatalf("证书管理器剩余证书不匹配: %+v", pfsense.certificates)
+	}
+
+	// 重复部署相同证书时复用已有记录，不再导入或切换。
+	if err := DeployCertificateToFirewall(ctx, resources[0].TargetRef, "fw.example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("重复部署失败: %v", err)
+	}
+	if pfsense.nextRef != 1 || pfsense.applied != 1 {
+		t.Fatalf("相同证书不应重复导入: nextRef=%d applied=%d", pfsense.nextRef, pfsense.applied)
+	}
+}
+
+// fakeOPNsense 模拟 OPNsense trust/cert 和 HAProxy 插件接口。
+type fakeOPNsense struct {
+	mu           sync.Mutex
+	certificates []*fakeFirewallCertificate
+	frontendRefs string
+	defaultRef   string
+	deleted      []string
+	reconfigured int
+}
+
+// ServeHTTP 按路径分发模拟 OPNsense 请求。
+func (f *fakeOPNsense) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if key, secret, ok := r.BasicAuth(); !ok || key != "key-1" || secret != "secret-1" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var body map[string]map[string]string
+	json.NewDecoder(r.Body).Decode(&body)
+	switch {
+	case r.URL.Path == opnsenseCertificateSearchPath:
+		rows := make([]map[string]string, 0, len(f.certificates))
+		for _, certificate := range f.certificates {
+			rows = append(rows, map[string]string{"uuid": certificate.UUID, "refid": certificate.RefID, "descr": certificate.Description})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"rows": rows})
+	case r.URL.Path == opnsenseCertificateAddPath:
+		if body["cert"]["action"] != "import" || body["cert"]["prv_payload"] == "" {
+			json.NewEncoder(w).Encode(map[string]any{"result": "failed"})
+			return
+		}
+		certificate := &fakeFirewallCertificate{UUID: "uuid-new", RefID: "ref-new", Description: body["cert"]["descr"], Certificate: body["cert"]["crt_payload"]}
+		f.certificates = append(f.certificates, certificate)
+		json.NewEncoder(w).Encode(map[string]any{"result": "saved", "uuid": certificate.UUID})
+	case strings.HasPrefix(r.URL.Path, opnsenseCertificateGetPath):
+		certificate := f.find(strings.TrimPrefix(r.URL.Path, opnsenseCertificateGetPath))
+		json.NewEncoder(w).Encode(map[string]any{"cert": map[string]string{"refid": certificate.RefID, "descr": certificate.Description, "crt": base64.StdEncoding.EncodeToString([]byte(certificate.Certificate))}})
+	case strings.HasPrefix(r.URL.Path, opnsenseCertificateDeletePath):
+		f.deleted = append(f.deleted, strings.TrimPrefix(r.URL.Path, opnsenseCertificateDeletePath))
+		json.NewEncoder(w).Encode(map[string]any{"result": "deleted"})
+	case r.URL.Path == opnsenseFrontendSearchPath:
+		json.NewEncoder(w).Encode(map[string]any{"rows": []map[string]string{{"uuid": "fe-1", "name": "https"}}})
+	case r.URL.Path == opnsenseFrontendGetPath+"fe-1":
+		options := map[string]map[string]any{}
+		for _, ref := range []string{"ref-old", "ref-keep", "ref-new"} {
+			options[ref] = map[string]any{"value": ref, "selected": 0}
+		}
+		for _, ref := range strings.Split(f.frontendRefs, ",") {
+			options[ref]["selected"] = 1
+		}
+		json.NewEncoder(w).Encode(map[string]any{"frontend": map[string]any{
+			"ssl_certificates":        options,
+			"ssl_default_certificate": map[string]map[string]any{f.defaultRef: {"selected": 1}},
+		}})
+	case r.URL.Path == opnsenseFrontendSetPath+"fe-1":
+		f.frontendRefs, f.defaultRef = body["frontend"]["ssl_certificates"], body["frontend"]["ssl_default_certificate"]
+		json.NewEncoder(w).Encode(map[string]any{"result": "saved"})
+	case r.URL.Path == opnsenseHAProxyReconfigure:
+		f.reconfigured++
+		json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// find 根据 UUID 查找模拟证书。
+func (f *fakeOPNsense) find(uuid string) *fakeFirewallCertificate {
+	for _, certificate := range f.certificates {
+		if certificate.UUID == uuid {
+			return certificate
+		}
+	}
+	return &fakeFirewallCertificate{}
+}
+
+// TestDeployCertificateToOPNsenseSwitchesHAProxyFrontends 验证 OPNsense 导入后替换 HAProxy 前端中的旧证书并保留其他证书，且 5xx 错误可重试。
+func TestDeployCertificateToOPNsenseSwitchesHAProxyFrontends(t *testing.T) {
+	oldCertificate, _ := generateTestCertificatePair(t, "*.example.com")
+	opnsense := &fakeOPNsense{
+		certificates: []*fakeFirewallCertificate{{UUID: "uuid-old", RefID: "ref-old", Description: "anssl *.example.com 2020-01-01", Certificate: oldCertificate}},
+		frontendRefs: "ref-keep,ref-old",
+		defaultRef:   "ref-old",
+	}
+	server := httptest.NewServer(opnsense)
+	defer server.Close()
+	ctx := firewallTestContext(&config.FirewallConfig{Name: "opn", Kind: config.FirewallKindOPNsense, URL: server.URL, APIKey: "key-1", APISecret: "secret-1", HAProxy: true})
+	resources, err := DiscoverFirewallResources(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverFirewallResources: %v", err)
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "*.example.com")
+	if err := DeployCertificateToFirewall(ctx, resources[0].TargetRef, "*.example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToFirewall: %v", err)
+	}
+	if opnsense.frontendRefs != "ref-keep,ref-new" || opnsense.defaultRef != "ref-new" || opnsense.reconfigured != 1 {
+		t.Fatalf("HAProxy 前端未切换: refs=%s default=%s reconfigured=%d", opnsense.frontendRefs, opnsense.defaultRef, opnsense.reconfigured)
+	}
+	if strings.Join(opnsense.deleted, ",") != "uuid-old" {
+		t.Fatalf("旧证书应被删除: %v", opnsense.deleted)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]any{"errorMessage": "backend down"})
+	}))
+	defer failing.Close()
+	ctx = firewallTestContext(&config.FirewallConfig{Name: "opn", Kind: config.FirewallKindOPNsense, URL: failing.URL, APIKey: "key-1", APISecret: "secret-1"})
+	resources, _ = DiscoverFirewallResources(ctx)
+	err = TestFirewallConnection(ctx, resources[0].TargetRef)
+	if err == nil || !IsFirewallErrorRetryable(err) || !strings.Contains(err.Error(), "backend down") {
+		t.Fatalf("5xx 应返回带诊断信息的可重试错误: %v", err)
+	}
+}
+
+// TestDeployCertificateToOPNsenseWarnsWhenNoFrontendIsBound 验证没有前端引用该域名证书时只记录警告，证书照常导入且旧证书照常清理。
+func TestDeployCertificateToOPNsenseWarnsWhenNoFrontendIsBound(t *testing.T) {
+	oldCertificate, _ := generateTestCertificatePair(t, "app.example.com")
+	opnsense := &fakeOPNsense{
+		certificates: []*fakeFirewallCertificate{{UUID: "uuid-old", RefID: "ref-old", Description: "anssl app.example.com 2020-01-01", Certificate: oldCertificate}},
+		frontendRefs: "ref-keep",
+		defaultRef:   "ref-keep",
+	}
+	server := httptest.NewServer(opnsense)
+	defer server.Close()
+	ctx := firewallTestContext(&config.FirewallConfig{Name: "opn", Kind: config.FirewallKindOPNsense, URL: server.URL, APIKey: "key-1", APISecret: "secret-1", HAProxy: true})
+	resources, err := DiscoverFirewallResources(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverFirewallResources: %v", err)
+	}
+	logs := captureLogs(t)
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "app.example.com")
+	if err := DeployCertificateToFirewall(ctx, resources[0].TargetRef, "app.example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("没有前端引用证书时不应返回错误: %v", err)
+	}
+	if !strings.Contains(logs.String(), "[WARN] 没有 HAProxy 前端引用该域名的证书") {
+		t.Fatalf("应记录没有前端引用证书的警告:\n%s", logs.String())
+	}
+	if len(opnsense.certificates) != 2 || opnsense.frontendRefs != "ref-keep" || opnsense.reconfigured != 0 {
+		t.Fatalf("应只导入证书而不改动前端: certificates=%d refs=%s reconfigured=%d", len(opnsense.certificates), opnsense.frontendRefs, opnsense.reconfigured)
+	}
+	if strings.Join(opnsense.deleted, ",") != "uuid-old" {
+		t.Fatalf("旧证书应照常删除: %v", opnsense.deleted)
+	}
+}
+
+// captureLogs 把全局日志重定向到缓冲区，测试结束后恢复。
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buffer bytes.Buffer
+	original := logger.Logger
+	logger.Logger = log.New(&buffer, "", 0)
+	t.Cleanup(func() { logger.Logger = original })
+	return &buffer
+}
+
+// firewallTestContext 返回只包含防火墙配置的操作 context。
+func firewallTestContext(firewalls ...*config.FirewallConfig) context.Context {
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{Firewalls: firewalls}}})
+}
+
+// writePfSenseResponse 写入 pfSense REST API v2 成功响应包络。
+func writePfSenseResponse(w http.ResponseWriter, data any) {
+	json.NewEncoder(w).Encode(map[string]any{"code": 200, "status": "ok", "data": data})
+}
+
+// generateTestCertificatePair 生成防火墙测试使用的自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -0,0 +1,253 @@
+package firewall
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/config"
+)
+
+const (
+	opnsenseCertificateSearchPath = "/api/trust/cert/search"
+	opnsenseCertificateAddPath    = "/api/trust/cert/add"
+	opnsenseCertificateGetPath    = "/api/trust/cert/get/"
+	opnsenseCertificateDeletePath = "/api/trust/cert/del/"
+	opnsenseFrontendSearchPath    = "/api/haproxy/settings/search_frontends"
+	opnsenseFrontendGetPath       = "/api/haproxy/settings/get_frontend/"
+	opnsenseFrontendSetPath       = "/api/haproxy/settings/set_frontend/"
+	opnsenseHAProxyReconfigure    = "/api/haproxy/service/reconfigure"
+)
+
+// opnsenseClient 通过 OPNsense 内置 REST API 管理 System > Trust > Certificates。
+type opnsenseClient struct {
+	firewall *config.FirewallConfig // firewall 是防火墙配置。
+}
+
+// opnsenseCertificateRow 描述 trust/cert/search 返回的证书行。
+type opnsenseCertificateRow struct {
+	UUID        string `json:"uuid"`  // UUID 是 API 定位证书使用的 ID。
+	RefID       string `json:"refid"` // RefID 是其他模块引用证书使用的 refid。
+	Description string `json:"descr"` // Description 是证书描述。
+}
+
+// opnsenseSearchResponse 描述 OPNsense search 接口的分页响应，deploy 一次读取全部行。
+type opnsenseSearchResponse[T any] struct {
+	Rows []T `json:"rows"` // Rows 是当前页的记录。
+}
+
+// opnsenseMutationResponse 描述 OPNsense add、set 和 del 接口的结果。
+type opnsenseMutationResponse struct {
+	Result      string          `json:"result"`      // Result 是 saved、deleted 或 failed。
+	UUID        string          `json:"uuid"`        // UUID 是新建记录的 ID。
+	Validations json.RawMessage `json:"validations"` // Validations 是字段校验失败原因，仅用于本地诊断。
+}
+
+// opnsenseFrontendRow 描述 search_frontends 返回的前端行。
+type opnsenseFrontendRow struct {
+	UUID string `json:"uuid"` // UUID 是前端 ID。
+	Name string `json:"name"` // Name 是前端名称。
+}
+
+// authorize 使用 API key 和 secret 进行 HTTP Basic 鉴权。
+func (c *opnsenseClient) authorize(req *http.Request) {
+	req.SetBasicAuth(c.firewall.APIKey, c.firewall.APISecret)
+}
+
+// call 调用 OPNsense API。
+func (c *opnsenseClient) call(ctx context.Context, method, endpoint string, payload, responseData any) error {
+	return requestFirewallAPI(ctx, c.firewall, c.authorize, method, endpoint, nil, payload, responseData)
+}
+
+// mutate 调用 OPNsense 写接口并检查 result 字段。
+func (c *opnsenseClient) mutate(ctx context.Context, endpoint string, payload any, expected string) (opnsenseMutationResponse, error) {
+	var response opnsenseMutationResponse
+	if payload == nil {
+		payload = map[string]any{}
+	}
+	if err := c.call(ctx, http.MethodPost, endpoint, payload, &response); err != nil {
+		return response, err
+	}
+	if response.Result != expected {
+		detail := strings.TrimSpace(string(response.Validations))
+		if detail == "" || detail == "null" {
+			detail = response.Result
+		}
+		return response, &firewallRequestError{Retryable: false, Cause: fmt.Errorf("OPNsense 返回 %s", detail)}
+	}
+	return response, nil
+}
+
+// listCertificates 读取证书管理器中的全部证书。
+func (c *opnsenseClient) listCertificates(ctx context.Context) ([]firewallCertificate, error) {
+	var response opnsenseSearchResponse[opnsenseCertificateRow]
+	if err := requestFirewallAPI(ctx, c.firewall, c.authorize, http.MethodGet, opnsenseCertificateSearchPath, url.Values{"rowCount": {"-1"}}, nil, &response); err != nil {
+		return nil, err
+	}
+	certificates := make([]firewallCertificate, 0, len(response.Rows))
+	for _, row := range response.Rows {
+		certificates = append(certificates, firewallCertificate{ID: row.UUID, RefID: row.RefID, Description: row.Description})
+	}
+	return certificates, nil
+}
+
+// importCertificate 以 import 动作导入证书和私钥，再读取新记录的 refid。
+func (c *opnsenseClient) importCertificate(ctx context.Context, description, certificatePEM, privateKeyPEM string) (firewallCertificate, error) {
+	response, err := c.mutate(ctx, opnsenseCertificateAddPath, map[string]any{"cert": map[string]string{
+		"action":      "import",
+		"descr":       description,
+		"crt_payload": certificatePEM,
+		"prv_payload": privateKeyPEM,
+	}}, "saved")
+	if err != nil {
+		return firewallCertificate{}, err
+	}
+	if strings.TrimSpace(response.UUID) == "" {
+		return firewallCertificate{}, errors.New("OPNsense 导入证书响应缺少 uuid")
+	}
+	certificate, _, err := c.getCertificate(ctx, response.UUID)
+	return certificate, err
+}
+
+// certificatePEM 读取证书记录保存的 PEM 证书。
+func (c *opnsenseClient) certificatePEM(ctx context.Context, certificate firewallCertificate) (string, error) {
+	_, certificatePEM, err := c.getCertificate(ctx, certificate.ID)
+	return certificatePEM, err
+}
+
+// getCertificate 读取单条证书记录；crt 字段在配置中以 base64 保存。
+func (c *opnsenseClient) getCertificate(ctx context.Context, id string) (firewallCertificate, string, error) {
+	var response struct {
+		Cert struct {
+			RefID       string `json:"refid"`       // RefID 是证书 refid。
+			Description string `json:"descr"`       // Description 是证书描述。
+			Certificate string `json:"crt"`         // Certificate 是 base64 编码的 PEM 证书。
+			Payload     string `json:"crt_payload"` // Payload 是部分版本直接返回的 PEM 证书。
+		} `json:"cert"`
+	}
+	if err := c.call(ctx, http.MethodGet, opnsenseCertificateGetPath+url.PathEscape(id), nil, &response); err != nil {
+		return firewallCertificate{}, "", err
+	}
+	if strings.TrimSpace(response.Cert.RefID) == "" {
+		return firewallCertificate{}, "", errors.New("OPNsense 证书记录缺少 refid")
+	}
+	certificate := firewallCertificate{ID: id, RefID: response.Cert.RefID, Description: response.Cert.Description}
+	certificatePEM := response.Cert.Payload
+	if strings.TrimSpace(certificatePEM) == "" {
+		certificatePEM = decodeFirewallPEM(response.Cert.Certificate)
+	}
+	return certificate, certificatePEM, nil
+}
+
+// deleteCertificate 删除证书记录。
+func (c *opnsenseClient) deleteCertificate(ctx context.Context, certificate firewallCertificate) error {
+	_, err := c.mutate(ctx, opnsenseCertificateDeletePath+url.PathEscape(certificate.ID), nil, "deleted")
+	return err
+}
+
+// webGUICertificate 在 OPNsense 上不可用，配置校验会拒绝 webGui。
+func (c *opnsenseClient) webGUICertificate(context.Context) (string, error) {
+	return "", errors.New("OPNsense 不支持通过 API 切换 Web 管理界面证书")
+}
+
+// setWebGUICertificate 在 OPNsense 上不可用，配置校验会拒绝 webGui。
+func (c *opnsenseClient) setWebGUICertificate(context.Context, string) error {
+	return errors.New("OPNsense 不支持通过 API 切换 Web 管理界面证书")
+}
+
+// haproxyFrontends 读取 HAProxy 插件全部前端及其证书选项。
+func (c *opnsenseClient) haproxyFrontends(ctx context.Context) ([]firewallFrontend, error) {
+	var response opnsenseSearchResponse[opnsenseFrontendRow]
+	if err := requestFirewallAPI(ctx, c.firewall, c.authorize, http.MethodGet, opnsenseFrontendSearchPath, url.Values{"rowCount": {"-1"}}, nil, &response); err != nil {
+		return nil, err
+	}
+	frontends := make([]firewallFrontend, 0, len(response.Rows))
+	for _, row := range response.Rows {
+		var detail struct {
+			Frontend struct {
+				Certificates       json.RawMessage `json:"ssl_certificates"`        // Certificates 是多选证书选项。
+				DefaultCertificate json.RawMessage `json:"ssl_default_certificate"` // DefaultCertificate 是单选默认证书选项。
+			} `json:"frontend"`
+		}
+		if err := c.call(ctx, http.MethodGet, opnsenseFrontendGetPath+url.PathEscape(row.UUID), nil, &detail); err != nil {
+			return nil, err
+		}
+		defaultRefs := selectedOPNsenseOptions(detail.Frontend.DefaultCertificate)
+		frontend := firewallFrontend{ID: row.UUID, Name: row.Name, CertificateRefs: selectedOPNsenseOptions(detail.Frontend.Certificates)}
+		if len(defaultRefs) > 0 {
+			frontend.DefaultRef = defaultRefs[0]
+		}
+		frontends = append(frontends, frontend)
+	}
+	return frontends, nil
+}
+
+// setHAProxyFrontendCertificates 保存前端的证书多选和默认证书。
+func (c *opnsenseClient) setHAProxyFrontendCertificates(ctx context.Context, frontend firewallFrontend) error {
+	_, err := c.mutate(ctx, opnsenseFrontendSetPath+url.PathEscape(frontend.ID), map[string]any{"frontend": map[string]string{
+		"ssl_certificates":        strings.Join(frontend.CertificateRefs, ","),
+		"ssl_default_certificate": frontend.DefaultRef,
+	}}, "saved")
+	return err
+}
+
+// applyHAProxy 生成 HAProxy 配置并重新加载服务。
+func (c *opnsenseClient) applyHAProxy(ctx context.Context) error {
+	var response struct {
+		Status string `json:"status"` // Status 是 reconfigure 结果。
+	}
+	if err := c.call(ctx, http.MethodPost, opnsenseHAProxyReconfigure, map[string]any{}, &response); err != nil {
+		return err
+	}
+	if !strings.EqualFold(strings.TrimSpace(response.Status), "ok") {
+		return fmt.Errorf("OPNsense HAProxy 重新加载失败: %s", response.Status)
+	}
+	return nil
+}
+
+// selectedOPNsenseOptions 从 OPNsense 选项字段中提取已选中的值，兼容选项映射和逗号分隔字符串两种格式。
+func selectedOPNsenseOptions(raw json.RawMessage) []string {
+	var options map[string]struct {
+		Selected json.Number `json:"selected"` // Selected 为 1 表示已选中。
+	}
+	if err := json.Unmarshal(raw, &options); err == nil {
+		selected := make([]string, 0)
+		for value, option := range options {
+			if value != "" && option.Selected.String() == "1" {
+				selected = append(selected, value)
+			}
+		}
+		sort.Strings(selected)
+		return selected
+	}
+	var joined string
+	if err := json.Unmarshal(raw, &joined); err != nil {
+		return nil
+	}
+	selected := make([]string, 0)
+	for _, value := range strings.Split(joined, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			selected = append(selected, value)
+		}
+	}
+	return selected
+}
+
+// decodeFirewallPEM 返回 PEM 文本；配置中以 base64 保存的证书会先解码。
+func decodeFirewallPEM(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "-----BEGIN") {
+		return value
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return value
+	}
+	return string(decoded)
+}
@@ -0,0 +1,171 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/config"
+)
+
+const (
+	pfsenseCertificatesPath    = "/api/v2/system/certificates"
+	pfsenseCertificatePath     = "/api/v2/system/certificate"
+	pfsenseWebGUISettingsPath  = "/api/v2/system/webgui/settings"
+	pfsenseHAProxyFrontends    = "/api/v2/services/haproxy/frontends"
+	pfsenseHAProxyFrontend     = "/api/v2/services/haproxy/frontend"
+	pfsenseHAProxyApplyPath    = "/api/v2/services/haproxy/apply"
+	pfsenseAPIKeyHeader        = "X-API-Key"
+	pfsenseCertificateTypeLeaf = "server"
+)
+
+// pfsenseClient 通过 pfSense-pkg-RESTAPI v2 管理 System > Certificates。
+type pfsenseClient struct {
+	firewall *config.FirewallConfig // firewall 是防火墙配置。
+}
+
+// pfsenseResponse 描述 pfSense REST API v2 的统一响应包络。
+type pfsenseResponse[T any] struct {
+	Data T `json:"data"` // Data 是各接口自己的响应数据。
+}
+
+// pfsenseCertificate 描述 pfSense 证书对象，id 是配置数组下标，删除后会变化。
+type pfsenseCertificate struct {
+	ID          int    `json:"id"`    // ID 是证书在配置数组中的下标。
+	RefID       string `json:"refid"` // RefID 是其他模块引用证书使用的 refid。
+	Description string `json:"descr"` // Description 是证书描述。
+	Certificate string `json:"crt"`   // Certificate 是 PEM 证书。
+}
+
+// pfsenseFrontend 描述 HAProxy 包前端中与证书相关的字段。
+type pfsenseFrontend struct {
+	ID           int    `json:"id"`             // ID 是前端在配置数组中的下标。
+	Name         string `json:"name"`           // Name 是前端名称。
+	OffloadCert  string `json:"ssloffloadcert"` // OffloadCert 是 SSL 卸载默认证书 refid。
+	Certificates []struct {
+		Certificate string `json:"ssl_certificate"` // Certificate 是额外证书 refid。
+	} `json:"ha_certificates"`
+}
+
+// authorize 使用 REST API 包生成的密钥鉴权。
+func (c *pfsenseClient) authorize(req *http.Request) {
+	req.Header.Set(pfsenseAPIKeyHeader, c.firewall.APIKey)
+}
+
+// call 调用 pfSense REST API v2。
+func (c *pfsenseClient) call(ctx context.Context, method, endpoint string, query url.Values, payload, responseData any) error {
+	return requestFirewallAPI(ctx, c.firewall, c.authorize, method, endpoint, query, payload, responseData)
+}
+
+// listCertificates 读取证书管理器中的全部证书。
+func (c *pfsenseClient) listCertificates(ctx context.Context) ([]firewallCertificate, error) {
+	var response pfsenseResponse[[]pfsenseCertificate]
+	if err := c.call(ctx, http.MethodGet, pfsenseCertificatesPath, url.Values{"limit": {"0"}}, nil, &response); err != nil {
+		return nil, err
+	}
+	certificates := make([]firewallCertificate, 0, len(response.Data))
+	for _, certificate := range response.Data {
+		certificates = append(certificates, certificate.record())
+	}
+	return certificates, nil
+}
+
+// importCertificate 导入证书和私钥并返回新记录。
+func (c *pfsenseClient) importCertificate(ctx context.Context, description, certificatePEM, privateKeyPEM string) (firewallCertificate, error) {
+	var response pfsenseResponse[pfsenseCertificate]
+	if err := c.call(ctx, http.MethodPost, pfsenseCertificatePath, nil, map[string]string{
+		"descr": description,
+		"crt":   certificatePEM,
+		"prv":   privateKeyPEM,
+		"type":  pfsenseCertificateTypeLeaf,
+	}, &response); err != nil {
+		return firewallCertificate{}, err
+	}
+	if strings.TrimSpace(response.Data.RefID) == "" {
+		return firewallCertificate{}, errors.New("pfSense 导入证书响应缺少 refid")
+	}
+	return response.Data.record(), nil
+}
+
+// certificatePEM 读取证书记录保存的 PEM 证书。
+func (c *pfsenseClient) certificatePEM(ctx context.Context, certificate firewallCertificate) (string, error) {
+	var response pfsenseResponse[pfsenseCertificate]
+	if err := c.call(ctx, http.MethodGet, pfsenseCertificatePath, url.Values{"id": {certificate.ID}}, nil, &response); err != nil {
+		return "", err
+	}
+	// id 会在删除其他证书后变化，必须确认读到的仍是同一条记录。
+	if response.Data.RefID != certificate.RefID {
+		return "", errors.New("pfSense 证书 id 已变化，请重试")
+	}
+	return decodeFirewallPEM(response.Data.Certificate), nil
+}
+
+// deleteCertificate 删除证书记录，pfSense 会拒绝删除仍被引用的证书。
+func (c *pfsenseClient) deleteCertificate(ctx context.Context, certificate firewallCertificate) error {
+	return c.call(ctx, http.MethodDelete, pfsenseCertificatePath, url.Values{"id": {certificate.ID}}, nil, nil)
+}
+
+// webGUICertificate 返回 Web 管理界面当前使用的证书 refid。
+func (c *pfsenseClient) webGUICertificate(ctx context.Context) (string, error) {
+	var response pfsenseResponse[struct {
+		CertificateRef string `json:"sslcertref"` // CertificateRef 是 Web 管理界面证书 refid。
+	}]
+	if err := c.call(ctx, http.MethodGet, pfsenseWebGUISettingsPath, nil, nil, &response); err != nil {
+		return "", err
+	}
+	return response.Data.CertificateRef, nil
+}
+
+// setWebGUICertificate 把 Web 管理界面切换到指定证书，pfSense 会在响应返回后重启 Web 服务。
+func (c *pfsenseClient) setWebGUICertificate(ctx context.Context, refID string) error {
+	return c.call(ctx, http.MethodPatch, pfsenseWebGUISettingsPath, nil, map[string]string{"sslcertref": refID}, nil)
+}
+
+// haproxyFrontends 读取 HAProxy 包的全部前端。
+func (c *pfsenseClient) haproxyFrontends(ctx context.Context) ([]firewallFrontend, error) {
+	var response pfsenseResponse[[]pfsenseFrontend]
+	if err := c.call(ctx, http.MethodGet, pfsenseHAProxyFrontends, url.Values{"limit": {"0"}}, nil, &response); err != nil {
+		return nil, err
+	}
+	frontends := make([]firewallFrontend, 0, len(response.Data))
+	for _, item := range response.Data {
+		frontend := firewallFrontend{ID: strconv.Itoa(item.ID), Name: item.Name, DefaultRef: item.OffloadCert}
+		for _, certificate := range item.Certificates {
+			if certificate.Certificate != "" {
+				frontend.CertificateRefs = append(frontend.CertificateRefs, certificate.Certificate)
+			}
+		}
+		frontends = append(frontends, frontend)
+	}
+	return frontends, nil
+}
+
+// setHAProxyFrontendCertificates 保存前端的 SSL 卸载证书和额外证书列表。
+func (c *pfsenseClient) setHAProxyFrontendCertificates(ctx context.Context, frontend firewallFrontend) error {
+	id, err := strconv.Atoi(frontend.ID)
+	if err != nil {
+		return errors.New("pfSense HAProxy 前端 id 无效")
+	}
+	certificates := make([]map[string]string, 0, len(frontend.CertificateRefs))
+	for _, refID := range frontend.CertificateRefs {
+		certificates = append(certificates, map[string]string{"ssl_certificate": refID})
+	}
+	return c.call(ctx, http.MethodPatch, pfsenseHAProxyFrontend, nil, map[string]any{
+		"id":              id,
+		"ssloffloadcert":  frontend.DefaultRef,
+		"ha_certificates": certificates,
+	}, nil)
+}
+
+// applyHAProxy 让 HAProxy 包加载已保存的配置。
+func (c *pfsenseClient) applyHAProxy(ctx context.Context) error {
+	return c.call(ctx, http.MethodPost, pfsenseHAProxyApplyPath, nil, map[string]any{}, nil)
+}
+
+// record 转换为与防火墙类型无关的证书记录。
+func (c pfsenseCertificate) record() firewallCertificate {
+	return firewallCertificate{ID: strconv.Itoa(c.ID), RefID: c.RefID, Description: c.Description}
+}
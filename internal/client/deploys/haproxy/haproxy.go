@@ -0,0 +1,257 @@
+package haproxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	haproxyCommandTimeout      = 10 * time.Second
+	haproxySocketTimeout       = 10 * time.Second
+	haproxyMaxResponseBodySize = 1024 * 1024
+)
+
+// errRuntimeCertificateUnknown 表示 HAProxy 尚未加载该证书文件，Runtime API 只能替换配置中已引用的证书。
+var errRuntimeCertificateUnknown = errors.New("haproxy 尚未加载该证书")
+
+var (
+	// haproxyCommand 允许测试替换 haproxy 可执行文件，生产环境始终从 PATH 查找。
+	haproxyCommand = "haproxy"
+	// reloadHAProxy 允许测试替换 systemd reload，生产环境始终调用 systemctl。
+	reloadHAProxy = ReloadHAProxyWithContext
+)
+
+// DeployWithContext 发布合并 PEM 证书，并将 Runtime API 热替换或配置校验与 reload 纳入同一发布事务。
+func DeployWithContext(ctx context.Context, sourceDir, domain, safeDomain string, haproxyConfig *config.HAProxyConfig) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if haproxyConfig == nil || haproxyConfig.Path == "" {
+		return errors.New("未配置 HAProxy 证书目录 (ssl.haproxy.path)")
+	}
+	if err := shared.ValidateCertificateFiles(sourceDir, domain); err != nil {
+		return err
+	}
+	bundle, err := buildCombinedPEM(sourceDir)
+	if err != nil {
+		return err
+	}
+
+	// 发布目录只包含合并 PEM，避免 HAProxy 以目录方式加载时读到单独的私钥文件。
+	stagingDir, err := os.MkdirTemp("", "anssl-haproxy-*")
+	if err != nil {
+		return fmt.Errorf("创建 HAProxy 临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	bundleName := safeDomain + ".pem"
+	if err := os.WriteFile(filepath.Join(stagingDir, bundleName), bundle, 0o600); err != nil {
+		return fmt.Errorf("写入 HAProxy 合并证书失败: %w", err)
+	}
+
+	if err := os.MkdirAll(haproxyConfig.Path, 0755); err != nil {
+		return fmt.Errorf("创建 HAProxy 证书目录失败: %w", err)
+	}
+	targetDir, err := shared.SafeJoinUnderBase(haproxyConfig.Path, safeDomain)
+	if err != nil {
+		return err
+	}
+	bundlePath := filepath.Join(targetDir, bundleName)
+
+	return shared.PublishDirectoryWithValidationContext(ctx, stagingDir, targetDir, func() error {
+		logger.Info("HAProxy 合并证书已更新", "path", bundlePath)
+		if haproxyConfig.RuntimeSocket != "" {
+			err := updateCertificateThroughRuntimeAPI(ctx, haproxyConfig.RuntimeSocket, bundlePath, bundle)
+			if err == nil {
+				logger.Info("HAProxy 已通过 Runtime API 替换证书", "certificate", bundlePath)
+				return nil
+			}
+			if !errors.Is(err, errRuntimeCertificateUnknown) {
+				return fmt.Errorf("haproxy Runtime API 替换证书失败: %w", err)
+			}
+			// 首次部署时 HAProxy 还没有加载该文件，只能校验配置后 reload 让其从磁盘读取。
+			if !IsHAProxyAvailable() {
+				return fmt.Errorf("haproxy Runtime API 替换证书失败且未找到 haproxy 命令，无法 reload: %w", err)
+			}
+			logger.Info("HAProxy 尚未加载该证书，改为校验配置并 reload", "certificate", bundlePath)
+			return testAndReloadHAProxy(ctx, haproxyConfig.ConfigFile)
+		}
+		if !IsHAProxyAvailable() {
+			logger.Info("haproxy 未安装或不在 PATH 中，跳过配置校验和 reload")
+			return nil
+		}
+		return testAndReloadHAProxy(ctx, haproxyConfig.ConfigFile)
+	})
+}
+
+// testAndReloadHAProxy 使用 haproxy -c 校验配置，通过后平滑重新加载。
+func testAndReloadHAProxy(ctx context.Context, configFile string) error {
+	if err := TestHAProxyConfigWithContext(ctx, configFile); err != nil {
+		return fmt.Errorf("haproxy 配置测试失败: %w", err)
+	}
+	if err := reloadHAProxy(ctx); err != nil {
+		return fmt.Errorf("haproxy 重新加载失败: %w", err)
+	}
+	return nil
+}
+
+// TestHAProxyConnectionWithContext 只读检查 Runtime API 可达，未配置套接字时检查本机配置文件语法。
+func TestHAProxyConnectionWithContext(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || configuration.SSL.HAProxy == nil {
+		return errors.New("未配置 HAProxy (ssl.haproxy)")
+	}
+	haproxyConfig := configuration.SSL.HAProxy
+	if haproxyConfig.RuntimeSocket != "" {
+		if _, err := runtimeCommand(ctx, haproxyConfig.RuntimeSocket, "show ssl cert\n"); err != nil {
+			return fmt.Errorf("haproxy Runtime API 连接测试失败: %w", err)
+		}
+		return nil
+	}
+	if !IsHAProxyAvailable() {
+		return errors.New("未找到 haproxy 命令")
+	}
+	if err := TestHAProxyConfigWithContext(ctx, haproxyConfig.ConfigFile); err != nil {
+		return fmt.Errorf("haproxy 配置测试失败: %w", err)
+	}
+	return nil
+}
+
+// IsHAProxyAvailable 检查 haproxy 命令是否可用。
+func IsHAProxyAvailable() bool {
+	_, err := exec.LookPath(haproxyCommand)
+	return err == nil
+}
+
+// TestHAProxyConfigWithContext 使用 haproxy -c 校验配置文件。
+func TestHAProxyConfigWithContext(parent context.Context, configFile string) error {
+	ctx, cancel := context.WithTimeout(parent, haproxyCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, haproxyCommand, "-c", "-f", configFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// ReloadHAProxyWithContext 使用 systemd 平滑重新加载 HAProxy。
+func ReloadHAProxyWithContext(parent context.Context) error {
+	ctx, cancel := context.WithTimeout(parent, haproxyCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "systemctl", "reload", "haproxy")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, string(output))
+	}
+
+	logger.Info("haproxy重新加载成功")
+	return nil
+}
+
+// buildCombinedPEM 按 HAProxy 要求拼接完整证书链和私钥，并去掉会截断 Runtime API 载荷的空行。
+func buildCombinedPEM(sourceDir string) ([]byte, error) {
+	certificatePEM, err := os.ReadFile(filepath.Join(sourceDir, "cert.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("读取证书文件失败: %w", err)
+	}
+	privateKeyPEM, err := os.ReadFile(filepath.Join(sourceDir, "privateKey.key"))
+	if err != nil {
+		return nil, fmt.Errorf("读取私钥文件失败: %w", err)
+	}
+
+	var bundle bytes.Buffer
+	for _, content := range [][]byte{certificatePEM, privateKeyPEM} {
+		for _, line := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			bundle.WriteString(strings.TrimRight(line, " \t"))
+			bundle.WriteByte('\n')
+		}
+	}
+	return bundle.Bytes(), nil
+}
+
+// updateCertificateThroughRuntimeAPI 通过 set ssl cert 和 commit ssl cert 替换内存中的证书，失败时放弃事务。
+func updateCertificateThroughRuntimeAPI(ctx context.Context, socketPath, certificatePath string, bundle []byte) error {
+	// 载荷以空行结束，bundle 已保证内部不含空行且以换行结尾。
+	output, err := runtimeCommand(ctx, socketPath, "set ssl cert "+certificatePath+" <<\n"+string(bundle)+"\n")
+	if err != nil {
+		return err
+	}
+	if isRuntimeCertificateUnknown(output) {
+		return fmt.Errorf("%w: %s", errRuntimeCertificateUnknown, strings.TrimSpace(output))
+	}
+	if !strings.Contains(output, "Transaction created") && !strings.Contains(output, "Transaction updated") {
+		abortRuntimeTransaction(ctx, socketPath, certificatePath)
+		return fmt.Errorf("set ssl cert 未创建事务: %s", strings.TrimSpace(output))
+	}
+
+	output, err = runtimeCommand(ctx, socketPath, "commit ssl cert "+certificatePath+"\n")
+	if err != nil {
+		abortRuntimeTransaction(ctx, socketPath, certificatePath)
+		return err
+	}
+	if !strings.Contains(output, "Success!") {
+		abortRuntimeTransaction(ctx, socketPath, certificatePath)
+		return fmt.Errorf("commit ssl cert 未成功: %s", strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// isRuntimeCertificateUnknown 判断 set ssl cert 是否因证书未被配置引用而被拒绝，不同版本的 HAProxy 使用不同措辞。
+func isRuntimeCertificateUnknown(output string) bool {
+	output = strings.ToLower(output)
+	return strings.Contains(output, "not referenced by the configuration") || strings.Contains(output, "unknown certificate")
+}
+
+// abortRuntimeTransaction 尽量放弃未提交的证书事务，避免残留事务阻塞下一次部署。
+func abortRuntimeTransaction(ctx context.Context, socketPath, certificatePath string) {
+	if _, err := runtimeCommand(ctx, socketPath, "abort ssl cert "+certificatePath+"\n"); err != nil {
+		logger.Warn("放弃 HAProxy 证书事务失败", "certificate", certificatePath, "error", err)
+	}
+}
+
+// runtimeCommand 在独立连接中发送一条 Runtime API 命令，并读取 HAProxy 关闭连接前的全部响应。
+func runtimeCommand(parent context.Context, socketPath, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(parent, haproxySocketTimeout)
+	defer cancel()
+
+	connection, err := (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+	if err != nil {
+		return "", fmt.Errorf("连接 HAProxy Runtime API 失败: %w", err)
+	}
+	defer connection.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = connection.SetDeadline(deadline)
+	}
+	if _, err := io.WriteString(connection, command); err != nil {
+		return "", fmt.Errorf("发送 HAProxy Runtime API 命令失败: %w", err)
+	}
+	response, err := io.ReadAll(io.LimitReader(connection, haproxyMaxResponseBodySize+1))
+	if err != nil {
+		return "", fmt.Errorf("读取 HAProxy Runtime API 响应失败: %w", err)
+	}
+	if len(response) > haproxyMaxResponseBodySize {
+		return "", errors.New("haproxy Runtime API 响应超过最大限制")
+	}
+	return string(response), nil
+}
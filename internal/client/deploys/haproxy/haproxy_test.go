@@ -0,0 +1,243 @@
+package haproxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestDeployWithContextSwapsCertificateThroughRuntimeAPI 验证配置 stats socket 时通过事务命令热替换合并证书。
+func TestDeployWithContextSwapsCertificateThroughRuntimeAPI(t *testing.T) {
+	socketPath, commands := startFakeRuntimeAPI(t, func(command string) string {
+		switch {
+		case strings.HasPrefix(command, "set ssl cert "):
+			return "Transaction created for certificate example.pem!\n"
+		case strings.HasPrefix(command, "commit ssl cert "):
+			return "Committing example.pem.\nSuccess!\n"
+		}
+		return "Unknown command\n"
+	})
+	sourceDir := t.TempDir()
+	basePath := filepath.Join(t.TempDir(), "haproxy")
+	writeTestCertificatePair(t, sourceDir, "example.com")
+
+	err := DeployWithContext(context.Background(), sourceDir, "example.com", "example.com", &config.HAProxyConfig{Path: basePath, RuntimeSocket: socketPath})
+	if err != nil {
+		t.Fatalf("DeployWithContext: %v", err)
+	}
+
+	bundlePath := filepath.Join(basePath, "example.com", "example.com.pem")
+	bundle, err := os.ReadFile(bundlePath)
+	if err != nil {
+		t.Fatalf("合并证书未发布: %v", err)
+	}
+	if !strings.Contains(string(bundle), "BEGIN CERTIFICATE") || !strings.Contains(string(bundle), "PRIVATE KEY") || strings.Contains(string(bundle), "\n\n") {
+		t.Fatalf("合并证书内容无效: %s", bundle)
+	}
+	got := commands()
+	if len(got) != 2 || !strings.HasPrefix(got[0], "set ssl cert "+bundlePath+" <<\n") || got[1] != "commit ssl cert "+bundlePath {
+		t.Fatalf("Runtime API 命令不匹配: %q", got)
+	}
+	if !strings.HasSuffix(got[0], strings.TrimSuffix(string(bundle), "\n")) {
+		t.Fatalf("set ssl cert 载荷未包含合并证书")
+	}
+}
+
+// TestDeployWithContextRollsBackWhenCommitFails 验证提交失败时放弃事务并恢复旧证书。
+func TestDeployWithContextRollsBackWhenCommitFails(t *testing.T) {
+	socketPath, commands := startFakeRuntimeAPI(t, func(command string) string {
+		if strings.HasPrefix(command, "set ssl cert ") {
+			return "Transaction updated for certificate example.pem!\n"
+		}
+		if strings.HasPrefix(command, "commit ssl cert ") {
+			return "Can't commit: unable to load certificate\n"
+		}
+		return "Transaction aborted.\n"
+	})
+	basePath := t.TempDir()
+	targetDir := filepath.Join(basePath, "example.com")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "example.com.pem"), []byte("old"), 0o600); err != nil {
+		t.Fatalf("write old bundle: %v", err)
+	}
+	sourceDir := t.TempDir()
+	writeTestCertificatePair(t, sourceDir, "example.com")
+
+	err := DeployWithContext(context.Background(), sourceDir, "example.com", "example.com", &config.HAProxyConfig{Path: basePath, RuntimeSocket: socketPath})
+	if err == nil || !strings.Contains(err.Error(), "unable to load certificate") {
+		t.Fatalf("提交失败错误不匹配: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(targetDir, "example.com.pem"))
+	if err != nil || string(content) != "old" {
+		t.Fatalf("旧证书未恢复: content=%q err=%v", content, err)
+	}
+	got := commands()
+	if len(got) != 3 || !strings.HasPrefix(got[2], "abort ssl cert ") {
+		t.Fatalf("提交失败后未放弃事务: %q", got)
+	}
+}
+
+// TestDeployWithContextFallsBackToConfigCheckAndReload 验证未配置套接字时先执行 haproxy -c 再 reload。
+func TestDeployWithContextFallsBackToConfigCheckAndReload(t *testing.T) {
+	scriptDir := t.TempDir()
+	argsFile := filepath.Join(scriptDir, "args")
+	script := filepath.Join(scriptDir, "haproxy")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"), 0o755); err != nil {
+		t.Fatalf("write fake haproxy: %v", err)
+	}
+	originalCommand, originalReload := haproxyCommand, reloadHAProxy
+	reloaded := false
+	haproxyCommand = script
+	reloadHAProxy = func(context.Context) error { reloaded = true; return nil }
+	t.Cleanup(func() {
+		haproxyCommand, reloadHAProxy = originalCommand, originalReload
+	})
+
+	sourceDir := t.TempDir()
+	writeTestCertificatePair(t, sourceDir, "example.com")
+	err := DeployWithContext(context.Background(), sourceDir, "example.com", "example.com", &config.HAProxyConfig{Path: t.TempDir(), ConfigFile: "/etc/haproxy/haproxy.cfg"})
+	if err != nil {
+		t.Fatalf("DeployWithContext: %v", err)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if strings.TrimSpace(string(args)) != "-c -f /etc/haproxy/haproxy.cfg" || !reloaded {
+		t.Fatalf("未执行配置校验和 reload: args=%q reloaded=%v", args, reloaded)
+	}
+}
+
+// TestDeployWithContextReloadsWhenRuntimeAPIDoesNotKnowCertificate 验证首次部署时 Runtime API 不认识证书文件，改为执行 haproxy -c 后 reload。
+func TestDeployWithContextReloadsWhenRuntimeAPIDoesNotKnowCertificate(t *testing.T) {
+	socketPath, commands := startFakeRuntimeAPI(t, func(command string) string {
+		if strings.HasPrefix(command, "set ssl cert ") {
+			return "Can't replace a certificate which is not referenced by the configuration!\nCan't update " + strings.Fields(command)[3] + "!\n"
+		}
+		return "Unknown command\n"
+	})
+	scriptDir := t.TempDir()
+	argsFile := filepath.Join(scriptDir, "args")
+	script := filepath.Join(scriptDir, "haproxy")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"), 0o755); err != nil {
+		t.Fatalf("write fake haproxy: %v", err)
+	}
+	originalCommand, originalReload := haproxyCommand, reloadHAProxy
+	reloaded := false
+	haproxyCommand = script
+	reloadHAProxy = func(context.Context) error { reloaded = true; return nil }
+	t.Cleanup(func() {
+		haproxyCommand, reloadHAProxy = originalCommand, originalReload
+	})
+
+	sourceDir := t.TempDir()
+	writeTestCertificatePair(t, sourceDir, "example.com")
+	err := DeployWithContext(context.Background(), sourceDir, "example.com", "example.com", &config.HAProxyConfig{Path: t.TempDir(), ConfigFile: "/etc/haproxy/haproxy.cfg", RuntimeSocket: socketPath})
+	if err != nil {
+		t.Fatalf("DeployWithContext: %v", err)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if strings.TrimSpace(string(args)) != "-c -f /etc/haproxy/haproxy.cfg" || !reloaded {
+		t.Fatalf("未执行配置校验和 reload: args=%q reloaded=%v", args, reloaded)
+	}
+	if got := commands(); len(got) != 1 {
+		t.Fatalf("证书未加载时不应提交或放弃事务: %q", got)
+	}
+}
+
+// startFakeRuntimeAPI 启动按连接处理单条命令的 Runtime API 替身，并返回去掉载荷结束空行的命令记录。
+func startFakeRuntimeAPI(t *testing.T, respond func(command string) string) (string, func() []string) {
+	t.Helper()
+	// unix socket 路径长度有限，不使用可能较长的 t.TempDir。
+	socketDir, err := os.MkdirTemp("", "haproxy")
+	if err != nil {
+		t.Fatalf("create socket dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+	socketPath := filepath.Join(socketDir, "admin.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	var mu sync.Mutex
+	var commands []string
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = connection.SetDeadline(time.Now().Add(5 * time.Second))
+			reader := bufio.NewReader(connection)
+			line, _ := reader.ReadString('\n')
+			command := strings.TrimSuffix(line, "\n")
+			if strings.HasSuffix(command, "<<") {
+				for {
+					payloadLine, err := reader.ReadString('\n')
+					if err != nil || payloadLine == "\n" {
+						break
+					}
+					command += "\n" + strings.TrimSuffix(payloadLine, "\n")
+				}
+				command += "\n"
+			}
+			mu.Lock()
+			commands = append(commands, strings.TrimSuffix(command, "\n"))
+			mu.Unlock()
+			_, _ = connection.Write([]byte(respond(command)))
+			_ = connection.Close()
+		}
+	}()
+	return socketPath, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), commands...)
+	}
+}
+
+// writeTestCertificatePair 写入测试用自签证书和匹配私钥。
+func writeTestCertificatePair(t *testing.T, dir, domain string) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0o644); err != nil {
+		t.Fatalf("write cert.pem: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	if err := os.WriteFile(filepath.Join(dir, "privateKey.key"), keyPEM, 0o600); err != nil {
+		t.Fatalf("write privateKey.key: %v", err)
+	}
+}
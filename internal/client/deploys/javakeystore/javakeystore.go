@@ -0,0 +1,173 @@
+package javakeystore
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+	keystore "github.com/pavlo-v-chernykh/keystore-go/v4"
+)
+
+const (
+	// PKCS12FileName 是发布目录中的 PKCS#12 密钥库文件名。
+	PKCS12FileName = "keystore.p12"
+	// JKSFileName 是发布目录中的旧版 JKS 密钥库文件名。
+	JKSFileName = "keystore.jks"
+
+	// javaKeystoreCommandTimeout 是重启命令的最长执行时间，必须小于单次部署操作超时，失败后才有时间恢复旧密钥库并上报结果。
+	javaKeystoreCommandTimeout = 45 * time.Second
+	// javaKeystoreRestoreReserve 是从操作剩余时间中为恢复旧密钥库预留的时间。
+	javaKeystoreRestoreReserve = 5 * time.Second
+)
+
+// runRestartCommand 允许测试替换重启命令执行方式，生产环境始终通过 /bin/sh -c 执行。
+var runRestartCommand = RunRestartCommandWithContext
+
+// DeployWithContext 把证书和私钥转换为带密码的 PKCS#12（可选 JKS）密钥库，并原子发布到 path/<域名>/。
+func DeployWithContext(ctx context.Context, sourceDir, domain, safeDomain string, keystoreConfig *config.JavaKeystoreConfig) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if keystoreConfig == nil || keystoreConfig.Path == "" {
+		return errors.New("未配置 Java 密钥库目录 (ssl.javaKeystore.path)")
+	}
+	if err := shared.ValidateCertificateFiles(sourceDir, domain); err != nil {
+		return err
+	}
+	password, err := ResolvePassword(keystoreConfig)
+	if err != nil {
+		return err
+	}
+	chain, privateKey, err := shared.LoadCertificateChainAndKey(sourceDir)
+	if err != nil {
+		return err
+	}
+
+	// 发布目录只包含密钥库，避免明文私钥随密钥库一起暴露给 JVM 服务账号。
+	stagingDir, err := os.MkdirTemp("", "anssl-java-keystore-*")
+	if err != nil {
+		return fmt.Errorf("创建 Java 密钥库临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	pkcs12Data, err := shared.EncodePKCS12(privateKey, chain, keystoreConfig.Alias, password)
+	if err != nil {
+		return fmt.Errorf("生成 PKCS#12 密钥库失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(stagingDir, PKCS12FileName), pkcs12Data, 0o600); err != nil {
+		return fmt.Errorf("写入 PKCS#12 密钥库失败: %w", err)
+	}
+	if keystoreConfig.JKS {
+		jksData, err := encodeJKS(privateKey, chain, keystoreConfig.Alias, password)
+		if err != nil {
+			return fmt.Errorf("生成 JKS 密钥库失败: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(stagingDir, JKSFileName), jksData, 0o600); err != nil {
+			return fmt.Errorf("写入 JKS 密钥库失败: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(keystoreConfig.Path, 0755); err != nil {
+		return fmt.Errorf("创建 Java 密钥库目录失败: %w", err)
+	}
+	targetDir, err := shared.SafeJoinUnderBase(keystoreConfig.Path, safeDomain)
+	if err != nil {
+		return err
+	}
+
+	if keystoreConfig.RestartCommand == "" {
+		if err := shared.PublishDirectoryWithRollbackContext(ctx, stagingDir, targetDir); err != nil {
+			return err
+		}
+		logger.Info("Java 密钥库已更新，未配置重启命令", "path", targetDir)
+		return nil
+	}
+	// 重启命令作为发布校验步骤，服务无法加载新密钥库时恢复旧目录。
+	return shared.PublishDirectoryWithValidationContext(ctx, stagingDir, targetDir, func() error {
+		logger.Info("Java 密钥库已更新", "path", targetDir)
+		if err := runRestartCommand(ctx, keystoreConfig.RestartCommand, targetDir); err != nil {
+			return fmt.Errorf("java 服务重启命令执行失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// TestJavaKeystoreConnectionWithContext 检查密钥库目录已配置且密码来源可以读取，不执行重启命令。
+func TestJavaKeystoreConnectionWithContext(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || configuration.SSL.JavaKeystore == nil {
+		return errors.New("未配置 Java 密钥库 (ssl.javaKeystore)")
+	}
+	_, err := ResolvePassword(configuration.SSL.JavaKeystore)
+	return err
+}
+
+// ResolvePassword 按配置的唯一来源读取密钥库密码，密码文件只去掉结尾换行。
+func ResolvePassword(keystoreConfig *config.JavaKeystoreConfig) (string, error) {
+	var password string
+	switch {
+	case keystoreConfig.Password != "":
+		password = keystoreConfig.Password
+	case keystoreConfig.PasswordFile != "":
+		content, err := os.ReadFile(keystoreConfig.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("读取 Java 密钥库密码文件失败: %w", err)
+		}
+		password = strings.TrimRight(string(content), "\r\n")
+	case keystoreConfig.PasswordEnv != "":
+		password = os.Getenv(keystoreConfig.PasswordEnv)
+		if password == "" {
+			return "", fmt.Errorf("环境变量 %s 未设置 Java 密钥库密码", keystoreConfig.PasswordEnv)
+		}
+	}
+	if password == "" {
+		return "", errors.New("java 密钥库密码不能为空")
+	}
+	return password, nil
+}
+
+// RunRestartCommandWithContext 通过 /bin/sh -c 执行重启命令，并通过 ANSSL_KEYSTORE_DIR 传入发布目录；执行时间不超过操作剩余时间。
+func RunRestartCommandWithContext(ctx context.Context, command, keystoreDir string) error {
+	timeout := providers.OperationWaitTimeout(ctx, javaKeystoreCommandTimeout, javaKeystoreRestoreReserve)
+	if err := shared.RunShellCommandWithContext(ctx, command, timeout, []string{"ANSSL_KEYSTORE_DIR=" + keystoreDir}); err != nil {
+		return err
+	}
+	logger.Info("Java 服务重启命令执行成功")
+	return nil
+}
+
+// encodeJKS 生成 JKS 密钥库，Java 会把 JKS 别名统一转换为小写。
+func encodeJKS(privateKey any, chain []*x509.Certificate, alias, password string) ([]byte, error) {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	certificates := make([]keystore.Certificate, 0, len(chain))
+	for _, certificate := range chain {
+		certificates = append(certificates, keystore.Certificate{Type: "X509", Content: certificate.Raw})
+	}
+
+	store := keystore.New()
+	entry := keystore.PrivateKeyEntry{CreationTime: time.Now(), PrivateKey: keyDER, CertificateChain: certificates}
+	if err := store.SetPrivateKeyEntry(strings.ToLower(alias), entry, []byte(password)); err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err := store.Store(&buffer, []byte(password)); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
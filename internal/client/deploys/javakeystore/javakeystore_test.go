@@ -0,0 +1,141 @@
+package javakeystore
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/config"
+	keystore "github.com/pavlo-v-chernykh/keystore-go/v4"
+)
+
+// TestDeployWithContextPublishesKeystoresAndRunsRestart 验证生成 PKCS#12 和 JKS 并在发布后执行重启命令。
+func TestDeployWithContextPublishesKeystoresAndRunsRestart(t *testing.T) {
+	sourceDir := t.TempDir()
+	basePath := filepath.Join(t.TempDir(), "keystores")
+	writeTestCertificatePair(t, sourceDir, "example.com")
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("changeit\n"), 0o600); err != nil {
+		t.Fatalf("write password file: %v", err)
+	}
+	var restarted []string
+	originalRestart := runRestartCommand
+	runRestartCommand = func(_ context.Context, command, keystoreDir string) error {
+		restarted = append(restarted, command, keystoreDir)
+		return nil
+	}
+	t.Cleanup(func() { runRestartCommand = originalRestart })
+
+	keystoreConfig := &config.JavaKeystoreConfig{Path: basePath, Alias: "Tomcat", PasswordFile: passwordFile, JKS: true, RestartCommand: "systemctl restart tomcat"}
+	if err := DeployWithContext(context.Background(), sourceDir, "example.com", "example.com", keystoreConfig); err != nil {
+		t.Fatalf("DeployWithContext: %v", err)
+	}
+
+	targetDir := filepath.Join(basePath, "example.com")
+	if len(restarted) != 2 || restarted[0] != "systemctl restart tomcat" || restarted[1] != targetDir {
+		t.Fatalf("重启命令调用不匹配: %q", restarted)
+	}
+	info, err := os.Stat(filepath.Join(targetDir, PKCS12FileName))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("PKCS#12 密钥库未以 0600 发布: info=%v err=%v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "privateKey.key")); !os.IsNotExist(err) {
+		t.Fatalf("发布目录不应包含明文私钥: %v", err)
+	}
+
+	jksFile, err := os.Open(filepath.Join(targetDir, JKSFileName))
+	if err != nil {
+		t.Fatalf("JKS 密钥库未发布: %v", err)
+	}
+	defer jksFile.Close()
+	store := keystore.New()
+	if err := store.Load(jksFile, []byte("changeit")); err != nil {
+		t.Fatalf("加载 JKS 失败: %v", err)
+	}
+	entry, err := store.GetPrivateKeyEntry("tomcat", []byte("changeit"))
+	if err != nil {
+		t.Fatalf("JKS 别名条目不可读: %v", err)
+	}
+	if len(entry.CertificateChain) != 1 {
+		t.Fatalf("JKS 证书链长度不匹配: %d", len(entry.CertificateChain))
+	}
+}
+
+// TestDeployWithContextRollsBackWhenRestartFails 验证重启命令失败时恢复旧密钥库目录。
+func TestDeployWithContextRollsBackWhenRestartFails(t *testing.T) {
+	sourceDir := t.TempDir()
+	basePath := filepath.Join(t.TempDir(), "keystores")
+	targetDir := filepath.Join(basePath, "example.com")
+	writeTestCertificatePair(t, sourceDir, "example.com")
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		t.Fatalf("mkdir target: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, PKCS12FileName), []byte("old"), 0o600); err != nil {
+		t.Fatalf("write old keystore: %v", err)
+	}
+	originalRestart := runRestartCommand
+	runRestartCommand = func(context.Context, string, string) error { return errors.New("restart failed") }
+	t.Cleanup(func() { runRestartCommand = originalRestart })
+
+	keystoreConfig := &config.JavaKeystoreConfig{Path: basePath, Alias: "anssl", Password: "changeit", RestartCommand: "false"}
+	if err := DeployWithContext(context.Background(), sourceDir, "example.com", "example.com", keystoreConfig); err == nil {
+		t.Fatal("重启失败时应返回错误")
+	}
+	content, err := os.ReadFile(filepath.Join(targetDir, PKCS12FileName))
+	if err != nil || string(content) != "old" {
+		t.Fatalf("旧密钥库未恢复: content=%q err=%v", content, err)
+	}
+}
+
+// TestResolvePasswordFromEnvironment 验证从环境变量读取密码并拒绝空值。
+func TestResolvePasswordFromEnvironment(t *testing.T) {
+	t.Setenv("ANSSL_TEST_KEYSTORE_PASSWORD", "secret")
+	password, err := ResolvePassword(&config.JavaKeystoreConfig{PasswordEnv: "ANSSL_TEST_KEYSTORE_PASSWORD"})
+	if err != nil || password != "secret" {
+		t.Fatalf("ResolvePassword: password=%q err=%v", password, err)
+	}
+	if _, err := ResolvePassword(&config.JavaKeystoreConfig{PasswordEnv: "ANSSL_TEST_KEYSTORE_MISSING"}); err == nil {
+		t.Fatal("未设置的环境变量应返回错误")
+	}
+}
+
+// writeTestCertificatePair 写入测试用自签证书和匹配私钥。
+func writeTestCertificatePair(t *testing.T, dir, domain string) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0o644); err != nil {
+		t.Fatalf("write cert.pem: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	if err := os.WriteFile(filepath.Join(dir, "privateKey.key"), keyPEM, 0o600); err != nil {
+		t.Fatalf("write privateKey.key: %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// DiscoverKubernetesSecretResources 动态读取配置命名空间中的 TLS Secret 和声明的目标 Secret。
+func DiscoverKubernetesSecretResources(ctx context.Context) ([]KubernetesSecretResource, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	discoveryContext, cancel := context.WithTimeout(ctx, kubernetesDiscoveryTimeout)
+	defer cancel()
+
+	kubernetesConfig, err := getKubernetesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newKubernetesClient(kubernetesConfig)
+	if err != nil {
+		return nil, err
+	}
+	records, err := loadKubernetesSecretRecords(discoveryContext, client, kubernetesConfig)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]KubernetesSecretResource, 0, len(records))
+	for _, record := range records {
+		resource := record.Resource
+		resource.Domains = append([]string(nil), record.Resource.Domains...)
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+// TestKubernetesSecretConnection 只读确认 targetRef 对应 Secret 可以读取，尚未创建的 Secret 只要求命名空间可访问。
+func TestKubernetesSecretConnection(ctx context.Context, targetRef string) error {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return fmt.Errorf("kubernetes Secret targetRef 不能为空")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	discoveryContext, cancel := context.WithTimeout(ctx, kubernetesDiscoveryTimeout)
+	defer cancel()
+
+	kubernetesConfig, err := getKubernetesConfig(ctx)
+	if err != nil {
+		return err
+	}
+	client, err := newKubernetesClient(kubernetesConfig)
+	if err != nil {
+		return err
+	}
+	record, err := findKubernetesSecretByTargetRef(discoveryContext, client, kubernetesConfig, targetRef)
+	if err != nil {
+		return err
+	}
+	if record.Resource.Status == KubernetesSecretStatusUnsupported {
+		return fmt.Errorf("kubernetes Secret %s 不是 %s 类型", record.Resource.Label, kubernetesSecretTypeTLS)
+	}
+	var secret kubernetesSecret
+	if err := client.request(discoveryContext, http.MethodGet, secretPath(record.Namespace, record.Name), nil, nil, &secret); err != nil {
+		if isKubernetesNotFound(err) && record.Resource.Status == KubernetesSecretStatusNotCreated {
+			return nil
+		}
+		return fmt.Errorf("读取 Kubernetes Secret 失败: %w", err)
+	}
+	return nil
+}
+
+// loadKubernetesSecretRecords 分页读取命名空间中的 TLS Secret，并补充配置声明但尚不存在的 Secret。
+func loadKubernetesSecretRecords(ctx context.Context, client *kubernetesClient, kubernetesConfig *config.KubernetesConfig) ([]kubernetesSecretRecord, error) {
+	namespaces := append([]string(nil), kubernetesConfig.Namespaces...)
+	if len(namespaces) == 0 && len(kubernetesConfig.Secrets) == 0 {
+		namespaces = []string{client.defaultNamespace}
+	}
+
+	records := make([]kubernetesSecretRecord, 0)
+	seen := make(map[string]struct{})
+	for _, namespace := range namespaces {
+		secrets, err := listKubernetesTLSSecrets(ctx, client, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, secret := range secrets {
+			key := namespace + "/" + secret.Metadata.Name
+			if _, exists := seen[key]; exists {
+				continue
+			}
+			seen[key] = struct{}{}
+			records = append(records, newKubernetesSecretRecord(client.server, namespace, secret.Metadata.Name, &secret))
+		}
+	}
+	for _, declared := range kubernetesConfig.Secrets {
+		if _, exists := seen[declared]; exists {
+			continue
+		}
+		seen[declared] = struct{}{}
+		namespace, name, _ := strings.Cut(declared, "/")
+		var secret kubernetesSecret
+		if err := client.request(ctx, http.MethodGet, secretPath(namespace, name), nil, nil, &secret); err != nil {
+			if !isKubernetesNotFound(err) {
+				return nil, fmt.Errorf("读取 Kubernetes Secret %s 失败: %w", declared, err)
+			}
+			records = append(records, newKubernetesSecretRecord(client.server, namespace, name, nil))
+			continue
+		}
+		records = append(records, newKubernetesSecretRecord(client.server, namespace, name, &secret))
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Resource.Label < records[j].Resource.Label
+	})
+	return records, nil
+}
+
+// listKubernetesTLSSecrets 分页读取一个命名空间中的 kubernetes.io/tls Secret。
+func listKubernetesTLSSecrets(ctx context.Context, client *kubernetesClient, namespace string) ([]kubernetesSecret, error) {
+	secrets := make([]kubernetesSecret, 0)
+	continueToken := ""
+	for page := 1; page <= kubernetesSecretMaxPages; page++ {
+		query := url.Values{
+			"fieldSelector": {"type=" + kubernetesSecretTypeTLS},
+			"limit":         {strconv.Itoa(kubernetesSecretPageSize)},
+		}
+		if continueToken != "" {
+			query.Set("continue", continueToken)
+		}
+		var list kubernetesSecretList
+		if err := client.request(ctx, http.MethodGet, secretPath(namespace, ""), query, nil, &list); err != nil {
+			return nil, fmt.Errorf("读取命名空间 %s 的 Secret 列表失败: %w", namespace, err)
+		}
+		for _, secret := range list.Items {
+			if secret.Type == kubernetesSecretTypeTLS && secret.Metadata.Name != "" {
+				secrets = append(secrets, secret)
+			}
+		}
+		continueToken = list.Metadata.Continue
+		if continueToken == "" {
+			return secrets, nil
+		}
+	}
+	return nil, fmt.Errorf("kubernetes Secret 分页超过安全上限")
+}
+
+// newKubernetesSecretRecord 根据 Secret 当前内容生成脱敏资源，secret 为空表示尚未创建。
+func newKubernetesSecretRecord(server, namespace, name string, secret *kubernetesSecret) kubernetesSecretRecord {
+	resource := KubernetesSecretResource{
+		TargetRef: buildKubernetesSecretTargetRef(server, namespace, name),
+		Label:     namespace + "/" + name,
+		Namespace: namespace,
+		Status:    KubernetesSecretStatusNotCreated,
+	}
+	if secret != nil {
+		resource.Status = KubernetesSecretStatusActive
+		if secret.Type != kubernetesSecretTypeTLS {
+			resource.Status = KubernetesSecretStatusUnsupported
+		} else if leaf := parseSecretLeafCertificate(secret.Data[kubernetesSecretCertKey]); leaf != nil {
+			resource.Domains = providers.NormalizeDomains(append([]string{leaf.Subject.CommonName}, leaf.DNSNames...)...)
+			if domain, err := providers.NormalizeDomain(leaf.Subject.CommonName); err == nil {
+				resource.Domain = domain
+			} else if len(resource.Domains) > 0 {
+				resource.Domain = resource.Domains[0]
+			}
+		}
+	}
+	return kubernetesSecretRecord{Namespace: namespace, Name: name, Resource: resource}
+}
+
+// parseSecretLeafCertificate 解析 Secret 中 base64 编码的叶证书，内容无效时返回 nil。
+func parseSecretLeafCertificate(encoded string) *x509.Certificate {
+	certificatePEM, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+	for len(certificatePEM) > 0 {
+		block, rest := pem.Decode(certificatePEM)
+		if block == nil {
+			return nil
+		}
+		if block.Type == "CERTIFICATE" {
+			leaf, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil
+			}
+			return leaf
+		}
+		certificatePEM = rest
+	}
+	return nil
+}
+
+// findKubernetesSecretByTargetRef 重新发现 Secret 并要求 targetRef 唯一匹配。
+func findKubernetesSecretByTargetRef(ctx context.Context, client *kubernetesClient, kubernetesConfig *config.KubernetesConfig, targetRef string) (*kubernetesSecretRecord, error) {
+	records, err := loadKubernetesSecretRecords(ctx, client, kubernetesConfig)
+	if err != nil {
+		return nil, err
+	}
+	var matched *kubernetesSecretRecord
+	for index := range records {
+		if records[index].Resource.TargetRef != targetRef {
+			continue
+		}
+		if matched != nil {
+			return nil, fmt.Errorf("kubernetes Secret targetRef 不唯一，请重新配置部署目标")
+		}
+		record := records[index]
+		matched = &record
+	}
+	if matched == nil {
+		return nil, fmt.Errorf("kubernetes Secret 不存在或已移出配置范围，请重新配置部署目标")
+	}
+	return matched, nil
+}
+
+// buildKubernetesSecretTargetRef 根据集群地址、命名空间和名称生成稳定的不透明引用。
+func buildKubernetesSecretTargetRef(server, namespace, name string) string {
+	identity := strings.Join([]string{
+		"ansslCli",
+		"DEPLOYMENT_TYPE_ANSSL_CLI_KUBERNETES_SECRET_CERT",
+		strings.ToLower(strings.TrimRight(strings.TrimSpace(server), "/")),
+		namespace,
+		name,
+	}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return kubernetesSecretTargetPrefix + hex.EncodeToString(digest[:12])
+}
@@ -0,0 +1,346 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+	"go.yaml.in/yaml/v3"
+)
+
+// kubernetesRequestError 保存仅供 deploy 本地判断重试属性的 API 错误。
+type kubernetesRequestError struct {
+	StatusCode int   // StatusCode 是 API Server 返回的 HTTP 状态码，网络错误时为 0。
+	Retryable  bool  // Retryable 表示网络、冲突或服务端错误可以稍后重试。
+	Cause      error // Cause 不得写入 WebSocket 响应；在线日志必须先经过统一脱敏。
+}
+
+// Error 返回 Kubernetes API 本地诊断信息。
+func (e *kubernetesRequestError) Error() string {
+	if e == nil || e.Cause == nil {
+		return "Kubernetes API 请求失败"
+	}
+	return e.Cause.Error()
+}
+
+// Unwrap 返回原始错误，供 errors.Is 和 errors.As 使用。
+func (e *kubernetesRequestError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Cause
+}
+
+// kubernetesClient 保存一次操作使用的 API Server 连接和凭据。
+type kubernetesClient struct {
+	server           string       // server 是规范化后的 API Server 地址。
+	token            string       // token 是可选的 Bearer 令牌。
+	defaultNamespace string       // defaultNamespace 是凭据声明的默认命名空间。
+	httpClient       *http.Client // httpClient 限制超时、TLS 版本和重定向行为。
+}
+
+// IsKubernetesConfigured 判断兼容入口是否带有 Kubernetes 配置。
+func IsKubernetesConfigured() bool {
+	return IsKubernetesConfiguredWithContext(context.Background())
+}
+
+// IsKubernetesConfiguredWithContext 从 context 快照判断 Kubernetes 是否已配置。
+func IsKubernetesConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && configuration.SSL.Kubernetes != nil
+}
+
+// IsKubernetesErrorRetryable 判断 Kubernetes 操作是否适合由后端稍后重试。
+func IsKubernetesErrorRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var requestError *kubernetesRequestError
+	if errors.As(err, &requestError) {
+		return requestError.Retryable
+	}
+	var networkError net.Error
+	return errors.As(err, &networkError) && networkError.Timeout()
+}
+
+// getKubernetesConfig 读取当前操作快照中的 Kubernetes 配置。
+func getKubernetesConfig(ctx context.Context) (*config.KubernetesConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || configuration.SSL.Kubernetes == nil {
+		return nil, fmt.Errorf("未配置 Kubernetes (ssl.kubernetes)")
+	}
+	return configuration.SSL.Kubernetes, nil
+}
+
+// newKubernetesClient 根据 kubeconfig 或 Pod 内 ServiceAccount 创建 API 客户端。
+func newKubernetesClient(kubernetesConfig *config.KubernetesConfig) (*kubernetesClient, error) {
+	if kubernetesConfig.Kubeconfig != "" {
+		return newKubeconfigClient(kubernetesConfig.Kubeconfig, kubernetesConfig.Context)
+	}
+	return newInClusterClient()
+}
+
+// newInClusterClient 使用 Pod 内 ServiceAccount 令牌和 CA 创建 API 客户端。
+func newInClusterClient() (*kubernetesClient, error) {
+	host := strings.TrimSpace(os.Getenv("KUBERNETES_SERVICE_HOST"))
+	port := strings.TrimSpace(os.Getenv("KUBERNETES_SERVICE_PORT"))
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("未检测到 Pod 内 Kubernetes 环境，请配置 ssl.kubernetes.kubeconfig")
+	}
+	token, err := os.ReadFile(inClusterTokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取 ServiceAccount 令牌失败: %w", err)
+	}
+	caPEM, err := os.ReadFile(inClusterCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取 ServiceAccount CA 失败: %w", err)
+	}
+	tlsConfig, err := newKubernetesTLSConfig(caPEM, false, "")
+	if err != nil {
+		return nil, err
+	}
+	namespace := kubernetesDefaultNamespace
+	if content, err := os.ReadFile(inClusterNamespaceFile); err == nil && strings.TrimSpace(string(content)) != "" {
+		namespace = strings.TrimSpace(string(content))
+	}
+	return &kubernetesClient{
+		server:           "https://" + net.JoinHostPort(host, port),
+		token:            strings.TrimSpace(string(token)),
+		defaultNamespace: namespace,
+		httpClient:       newKubernetesHTTPClient(tlsConfig),
+	}, nil
+}
+
+// newKubeconfigClient 解析 kubeconfig 中指定上下文的集群和用户凭据。
+func newKubeconfigClient(kubeconfigPath, contextName string) (*kubernetesClient, error) {
+	content, err := os.ReadFile(kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取 kubeconfig 失败: %w", err)
+	}
+	var kubeconfig kubeconfigFile
+	if err := yaml.Unmarshal(content, &kubeconfig); err != nil {
+		return nil, fmt.Errorf("解析 kubeconfig 失败: %w", err)
+	}
+	if contextName == "" {
+		contextName = kubeconfig.CurrentContext
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("kubeconfig 未设置 current-context，请配置 ssl.kubernetes.context")
+	}
+
+	var selectedContext *kubeconfigContext
+	for index := range kubeconfig.Contexts {
+		if kubeconfig.Contexts[index].Name == contextName {
+			selectedContext = &kubeconfig.Contexts[index].Context
+			break
+		}
+	}
+	if selectedContext == nil {
+		return nil, fmt.Errorf("kubeconfig 中不存在上下文: %s", contextName)
+	}
+	var cluster *kubeconfigCluster
+	for index := range kubeconfig.Clusters {
+		if kubeconfig.Clusters[index].Name == selectedContext.Cluster {
+			cluster = &kubeconfig.Clusters[index].Cluster
+			break
+		}
+	}
+	if cluster == nil {
+		return nil, fmt.Errorf("kubeconfig 中不存在集群: %s", selectedContext.Cluster)
+	}
+	var user kubeconfigUser
+	for index := range kubeconfig.Users {
+		if kubeconfig.Users[index].Name == selectedContext.User {
+			user = kubeconfig.Users[index].User
+			break
+		}
+	}
+	if len(user.Exec) > 0 || len(user.AuthProvider) > 0 {
+		return nil, fmt.Errorf("kubeconfig 用户使用 exec 或 auth-provider 插件，deploy 仅支持令牌和客户端证书认证")
+	}
+
+	server := strings.TrimRight(strings.TrimSpace(cluster.Server), "/")
+	parsedServer, err := url.Parse(server)
+	if err != nil || parsedServer.Hostname() == "" || (parsedServer.Scheme != "https" && parsedServer.Scheme != "http") {
+		return nil, fmt.Errorf("kubeconfig 集群地址无效")
+	}
+	baseDir := filepath.Dir(kubeconfigPath)
+	caPEM, err := readKubeconfigData(cluster.CertificateAuthorityData, cluster.CertificateAuthority, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取 kubeconfig CA 失败: %w", err)
+	}
+	tlsConfig, err := newKubernetesTLSConfig(caPEM, cluster.InsecureSkipTLSVerify, cluster.TLSServerName)
+	if err != nil {
+		return nil, err
+	}
+	certificatePEM, err := readKubeconfigData(user.ClientCertificateData, user.ClientCertificate, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取 kubeconfig 客户端证书失败: %w", err)
+	}
+	keyPEM, err := readKubeconfigData(user.ClientKeyData, user.ClientKey, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取 kubeconfig 客户端私钥失败: %w", err)
+	}
+	if len(certificatePEM) > 0 || len(keyPEM) > 0 {
+		clientCertificate, err := tls.X509KeyPair(certificatePEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("kubeconfig 客户端证书无效: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCertificate}
+	}
+	token := strings.TrimSpace(user.Token)
+	if token == "" && user.TokenFile != "" {
+		content, err := os.ReadFile(resolveKubeconfigPath(user.TokenFile, baseDir))
+		if err != nil {
+			return nil, fmt.Errorf("读取 kubeconfig 令牌文件失败: %w", err)
+		}
+		token = strings.TrimSpace(string(content))
+	}
+
+	namespace := strings.TrimSpace(selectedContext.Namespace)
+	if namespace == "" {
+		namespace = kubernetesDefaultNamespace
+	}
+	return &kubernetesClient{
+		server:           server,
+		token:            token,
+		defaultNamespace: namespace,
+		httpClient:       newKubernetesHTTPClient(tlsConfig),
+	}, nil
+}
+
+// readKubeconfigData 优先使用内联 base64 数据，否则读取相对 kubeconfig 目录解析的文件。
+func readKubeconfigData(inlineData, path, baseDir string) ([]byte, error) {
+	if strings.TrimSpace(inlineData) != "" {
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(inlineData))
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+	return os.ReadFile(resolveKubeconfigPath(path, baseDir))
+}
+
+// resolveKubeconfigPath 按 kubectl 规则将相对路径解析到 kubeconfig 所在目录。
+func resolveKubeconfigPath(path, baseDir string) string {
+	path = strings.TrimSpace(path)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// newKubernetesTLSConfig 创建只信任集群 CA 的 TLS 配置，未提供 CA 时使用系统根证书。
+func newKubernetesTLSConfig(caPEM []byte, insecureSkipVerify bool, serverName string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: insecureSkipVerify, ServerName: strings.TrimSpace(serverName)} //nolint:gosec // 仅在 kubeconfig 显式声明后跳过校验。
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("kubernetes CA 证书无效")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// newKubernetesHTTPClient 为 API Server 配置独立 TLS 策略，避免影响其他 HTTP 客户端。
+func newKubernetesHTTPClient(tlsConfig *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{
+		Timeout:   kubernetesRequestTimeout,
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// request 调用 Kubernetes API，并限制重定向和响应体大小。
+func (client *kubernetesClient) request(ctx context.Context, method, endpoint string, query url.Values, payload any, responseData any) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("序列化 Kubernetes 请求失败: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+	requestURL := client.server + endpoint
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return fmt.Errorf("创建 Kubernetes 请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if client.token != "" {
+		req.Header.Set("Authorization", "Bearer "+client.token)
+	}
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return &kubernetesRequestError{Retryable: true, Cause: fmt.Errorf("请求 Kubernetes API 失败: %w", err)}
+	}
+	defer resp.Body.Close()
+	responseBody, err := io.ReadAll(io.LimitReader(resp.Body, kubernetesMaxResponseBodySize+1))
+	if err != nil {
+		return &kubernetesRequestError{Retryable: true, Cause: fmt.Errorf("读取 Kubernetes 响应失败: %w", err)}
+	}
+	if len(responseBody) > kubernetesMaxResponseBodySize {
+		return &kubernetesRequestError{Retryable: false, Cause: fmt.Errorf("kubernetes 响应体超过最大限制")}
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		retryable := resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		var status kubernetesStatus
+		if json.Unmarshal(responseBody, &status) == nil && strings.TrimSpace(status.Message) != "" {
+			return &kubernetesRequestError{StatusCode: resp.StatusCode, Retryable: retryable, Cause: fmt.Errorf("kubernetes API 返回 HTTP %d: %s", resp.StatusCode, strings.TrimSpace(status.Message))}
+		}
+		return &kubernetesRequestError{StatusCode: resp.StatusCode, Retryable: retryable, Cause: fmt.Errorf("kubernetes API 返回 HTTP %d", resp.StatusCode)}
+	}
+	if responseData == nil {
+		return nil
+	}
+	if err := json.Unmarshal(responseBody, responseData); err != nil {
+		return &kubernetesRequestError{Retryable: false, Cause: fmt.Errorf("解析 Kubernetes 响应失败: %w", err)}
+	}
+	return nil
+}
+
+// isKubernetesNotFound 判断错误是否为 API Server 返回的 404。
+func isKubernetesNotFound(err error) bool {
+	var requestError *kubernetesRequestError
+	return errors.As(err, &requestError) && requestError.StatusCode == http.StatusNotFound
+}
+
+// secretPath 返回指定命名空间 Secret 集合或单个 Secret 的 API 路径。
+func secretPath(namespace, name string) string {
+	path := "/api/v1/namespaces/" + url.PathEscape(namespace) + "/secrets"
+	if name != "" {
+		path += "/" + url.PathEscape(name)
+	}
+	return path
+}
@@ -0,0 +1,33 @@
+package kubernetes
+
+import "time"
+
+const (
+	kubernetesRequestTimeout      = 30 * time.Second
+	kubernetesDiscoveryTimeout    = 20 * time.Second
+	kubernetesMaxResponseBodySize = 8 * 1024 * 1024
+	kubernetesSecretPageSize      = 200
+	kubernetesSecretMaxPages      = 50
+	kubernetesSecretTargetPrefix  = "k8s-secret-"
+	kubernetesSecretTypeTLS       = "kubernetes.io/tls"
+	kubernetesSecretCertKey       = "tls.crt"
+	kubernetesSecretPrivateKey    = "tls.key"
+	kubernetesManagedByLabel      = "app.kubernetes.io/managed-by"
+	kubernetesManagedByValue      = "anssl-deploy"
+	kubernetesDefaultNamespace    = "default"
+	// KubernetesSecretStatusActive 表示 Secret 已存在且类型为 kubernetes.io/tls。
+	KubernetesSecretStatusActive = "Active"
+	// KubernetesSecretStatusNotCreated 表示配置声明的 Secret 尚不存在，首次部署时创建。
+	KubernetesSecretStatusNotCreated = "NotCreated"
+	// KubernetesSecretStatusUnsupported 表示同名 Secret 已存在但不是 kubernetes.io/tls 类型。
+	KubernetesSecretStatusUnsupported = "Unsupported"
+)
+
+var (
+	// inClusterTokenFile 允许测试替换 ServiceAccount 令牌路径，生产环境始终使用 Kubernetes 约定路径。
+	inClusterTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// inClusterCAFile 允许测试替换 ServiceAccount CA 路径。
+	inClusterCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	// inClusterNamespaceFile 允许测试替换 ServiceAccount 命名空间路径。
+	inClusterNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
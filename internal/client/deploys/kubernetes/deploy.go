@@ -0,0 +1,115 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+// DeployCertificateToKubernetesSecret 创建或更新 targetRef 对应的 TLS Secret，并回读叶证书指纹确认。
+func DeployCertificateToKubernetesSecret(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return fmt.Errorf("kubernetes Secret targetRef 不能为空")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+
+	kubernetesConfig, err := getKubernetesConfig(ctx)
+	if err != nil {
+		return err
+	}
+	client, err := newKubernetesClient(kubernetesConfig)
+	if err != nil {
+		return err
+	}
+	record, err := findKubernetesSecretByTargetRef(ctx, client, kubernetesConfig, targetRef)
+	if err != nil {
+		return err
+	}
+	if record.Resource.Status == KubernetesSecretStatusUnsupported {
+		return fmt.Errorf("kubernetes Secret %s 不是 %s 类型", record.Resource.Label, kubernetesSecretTypeTLS)
+	}
+
+	if err := upsertKubernetesTLSSecret(ctx, client, record.Namespace, record.Name, certificatePEM, privateKeyPEM); err != nil {
+		return err
+	}
+
+	var updated kubernetesSecret
+	if err := client.request(ctx, http.MethodGet, secretPath(record.Namespace, record.Name), nil, nil, &updated); err != nil {
+		return fmt.Errorf("回读 Kubernetes Secret 失败: %w", err)
+	}
+	actualPEM, err := base64.StdEncoding.DecodeString(updated.Data[kubernetesSecretCertKey])
+	if err != nil {
+		return fmt.Errorf("回读 Kubernetes Secret 证书编码无效: %w", err)
+	}
+	if err := providers.VerifyLeafCertificateSHA256(certificatePEM, string(actualPEM)); err != nil {
+		return err
+	}
+	logger.Info("Kubernetes TLS Secret 已更新", "secret", record.Resource.Label)
+	return nil
+}
+
+// upsertKubernetesTLSSecret 不存在时创建 TLS Secret，存在时保留其他字段并携带 resourceVersion 更新证书数据。
+func upsertKubernetesTLSSecret(ctx context.Context, client *kubernetesClient, namespace, name, certificatePEM, privateKeyPEM string) error {
+	encodedCertificate := base64.StdEncoding.EncodeToString([]byte(certificatePEM))
+	encodedPrivateKey := base64.StdEncoding.EncodeToString([]byte(privateKeyPEM))
+
+	var current map[string]any
+	err := client.request(ctx, http.MethodGet, secretPath(namespace, name), nil, nil, &current)
+	if isKubernetesNotFound(err) {
+		payload := map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+				"labels":    map[string]string{kubernetesManagedByLabel: kubernetesManagedByValue},
+			},
+			"type": kubernetesSecretTypeTLS,
+			"data": map[string]string{
+				kubernetesSecretCertKey:    encodedCertificate,
+				kubernetesSecretPrivateKey: encodedPrivateKey,
+			},
+		}
+		if err := client.request(ctx, http.MethodPost, secretPath(namespace, ""), nil, payload, nil); err != nil {
+			return fmt.Errorf("创建 Kubernetes Secret 失败: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取 Kubernetes Secret 失败: %w", err)
+	}
+
+	// 重新确认类型和不可变标记，避免发现与写入之间 Secret 被替换。
+	if secretType, _ := current["type"].(string); secretType != kubernetesSecretTypeTLS {
+		return fmt.Errorf("kubernetes Secret %s/%s 不是 %s 类型", namespace, name, kubernetesSecretTypeTLS)
+	}
+	if immutable, _ := current["immutable"].(bool); immutable {
+		return fmt.Errorf("kubernetes Secret %s/%s 已设置为不可变，无法更新证书", namespace, name)
+	}
+	data, _ := current["data"].(map[string]any)
+	if data == nil {
+		data = map[string]any{}
+	}
+	data[kubernetesSecretCertKey] = encodedCertificate
+	data[kubernetesSecretPrivateKey] = encodedPrivateKey
+	current["data"] = data
+	// stringData 会覆盖 data 中的同名键，更新时必须移除。
+	delete(current, "stringData")
+	if err := client.request(ctx, http.MethodPut, secretPath(namespace, name), nil, current, nil); err != nil {
+		return fmt.Errorf("更新 Kubernetes Secret 失败: %w", err)
+	}
+	return nil
+}
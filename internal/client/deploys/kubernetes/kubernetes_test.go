@@ -0,0 +1,234 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// fakeKubernetesAPI 是只实现 Secret 读写的内存 API Server。
+type fakeKubernetesAPI struct {
+	mu      sync.Mutex
+	secrets map[string]map[string]any // secrets 以 namespace/name 索引 Secret 对象。
+	methods []string                  // methods 记录请求方法和路径。
+}
+
+// ServeHTTP 处理 Secret 列表、读取、创建和更新请求。
+func (api *fakeKubernetesAPI) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.methods = append(api.methods, request.Method+" "+request.URL.Path)
+	if request.Header.Get("Authorization") != "Bearer test-token" {
+		writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	parts := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+	if len(parts) < 5 || parts[0] != "api" || parts[1] != "v1" || parts[2] != "namespaces" || parts[4] != "secrets" {
+		http.NotFound(writer, request)
+		return
+	}
+	namespace := parts[3]
+	switch {
+	case len(parts) == 5 && request.Method == http.MethodGet:
+		items := make([]map[string]any, 0)
+		for key, secret := range api.secrets {
+			if strings.HasPrefix(key, namespace+"/") && secret["type"] == kubernetesSecretTypeTLS {
+				items = append(items, secret)
+			}
+		}
+		_ = json.NewEncoder(writer).Encode(map[string]any{"metadata": map[string]any{}, "items": items})
+	case len(parts) == 5 && request.Method == http.MethodPost:
+		var secret map[string]any
+		_ = json.NewDecoder(request.Body).Decode(&secret)
+		name := secret["metadata"].(map[string]any)["name"].(string)
+		api.secrets[namespace+"/"+name] = secret
+		writer.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(writer).Encode(secret)
+	case len(parts) == 6 && request.Method == http.MethodGet:
+		secret, ok := api.secrets[namespace+"/"+parts[5]]
+		if !ok {
+			writer.WriteHeader(http.StatusNotFound)
+			_, _ = writer.Write([]byte(`{"kind":"Status","message":"secrets not found","reason":"NotFound"}`))
+			return
+		}
+		_ = json.NewEncoder(writer).Encode(secret)
+	case len(parts) == 6 && request.Method == http.MethodPut:
+		var secret map[string]any
+		_ = json.NewDecoder(request.Body).Decode(&secret)
+		api.secrets[namespace+"/"+parts[5]] = secret
+		_ = json.NewEncoder(writer).Encode(secret)
+	default:
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// TestKubernetesSecretDiscoveryAndUpsert 验证发现、创建、更新和只读测试完整流程。
+func TestKubernetesSecretDiscoveryAndUpsert(t *testing.T) {
+	oldCertificatePEM, _ := generateTestCertificatePair(t, "old.example.com")
+	api := &fakeKubernetesAPI{secrets: map[string]map[string]any{
+		"web/existing-tls": {
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]any{"name": "existing-tls", "namespace": "web", "resourceVersion": "7"},
+			"type":       kubernetesSecretTypeTLS,
+			"data": map[string]any{
+				kubernetesSecretCertKey: base64.StdEncoding.EncodeToString([]byte(oldCertificatePEM)),
+				"ca.crt":                "Y2E=",
+			},
+		},
+		"web/opaque": {"metadata": map[string]any{"name": "opaque", "namespace": "web"}, "type": "Opaque"},
+	}}
+	server := httptest.NewTLSServer(api)
+	defer server.Close()
+	ctx := shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{
+		Kubernetes: &config.KubernetesConfig{
+			Kubeconfig: writeTestKubeconfig(t, server),
+			Namespaces: []string{"web"},
+			Secrets:    []string{"apps/new-tls"},
+		},
+	}}})
+
+	resources, err := DiscoverKubernetesSecretResources(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverKubernetesSecretResources: %v", err)
+	}
+	if len(resources) != 2 || resources[0].Label != "apps/new-tls" || resources[0].Status != KubernetesSecretStatusNotCreated ||
+		resources[1].Label != "web/existing-tls" || resources[1].Domain != "old.example.com" {
+		t.Fatalf("发现的 Secret 资源不匹配: %+v", resources)
+	}
+	again, _ := DiscoverKubernetesSecretResources(ctx)
+	if again[0].TargetRef != resources[0].TargetRef || !strings.HasPrefix(resources[0].TargetRef, kubernetesSecretTargetPrefix) {
+		t.Fatalf("targetRef 不稳定: %s %s", resources[0].TargetRef, again[0].TargetRef)
+	}
+
+	api.methods = nil
+	for _, resource := range resources {
+		if err := TestKubernetesSecretConnection(ctx, resource.TargetRef); err != nil {
+			t.Fatalf("TestKubernetesSecretConnection(%s): %v", resource.Label, err)
+		}
+	}
+	for _, method := range api.methods {
+		if !strings.HasPrefix(method, http.MethodGet+" ") {
+			t.Fatalf("连接测试发出了写请求: %s", method)
+		}
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "www.example.com")
+	for _, resource := range resources {
+		if err := DeployCertificateToKubernetesSecret(ctx, resource.TargetRef, "www.example.com", certificatePEM, privateKeyPEM); err != nil {
+			t.Fatalf("DeployCertificateToKubernetesSecret(%s): %v", resource.Label, err)
+		}
+	}
+	created := api.secrets["apps/new-tls"]
+	if created["type"] != kubernetesSecretTypeTLS || created["data"].(map[string]any)[kubernetesSecretPrivateKey] != base64.StdEncoding.EncodeToString([]byte(privateKeyPEM)) {
+		t.Fatalf("新建 Secret 内容不匹配: %+v", created)
+	}
+	updated := api.secrets["web/existing-tls"]
+	if updated["data"].(map[string]any)["ca.crt"] != "Y2E=" || updated["metadata"].(map[string]any)["resourceVersion"] != "7" {
+		t.Fatalf("更新 Secret 未保留原有字段: %+v", updated)
+	}
+	if updated["data"].(map[string]any)[kubernetesSecretCertKey] != base64.StdEncoding.EncodeToString([]byte(certificatePEM)) {
+		t.Fatal("更新 Secret 证书未替换")
+	}
+}
+
+// TestKubernetesSecretDeployRejectsTamperedReadback 验证回读指纹不一致时返回错误。
+func TestKubernetesSecretDeployRejectsTamperedReadback(t *testing.T) {
+	otherCertificatePEM, _ := generateTestCertificatePair(t, "www.example.com")
+	api := &fakeKubernetesAPI{secrets: map[string]map[string]any{}}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == http.MethodGet && len(api.secrets) > 0 {
+			for _, secret := range api.secrets {
+				secret["data"].(map[string]any)[kubernetesSecretCertKey] = base64.StdEncoding.EncodeToString([]byte(otherCertificatePEM))
+			}
+		}
+		api.ServeHTTP(writer, request)
+	}))
+	defer server.Close()
+	ctx := shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{
+		Kubernetes: &config.KubernetesConfig{Kubeconfig: writeTestKubeconfig(t, server), Secrets: []string{"apps/new-tls"}},
+	}}})
+
+	resources, err := DiscoverKubernetesSecretResources(ctx)
+	if err != nil || len(resources) != 1 {
+		t.Fatalf("DiscoverKubernetesSecretResources: resources=%+v err=%v", resources, err)
+	}
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "www.example.com")
+	err = DeployCertificateToKubernetesSecret(ctx, resources[0].TargetRef, "www.example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "指纹") {
+		t.Fatalf("回读指纹不一致应返回错误: %v", err)
+	}
+}
+
+// writeTestKubeconfig 写入信任测试服务器证书并使用 Bearer 令牌的 kubeconfig。
+func writeTestKubeconfig(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	content := `apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+  - name: test
+    cluster:
+      server: ` + server.URL + `
+      certificate-authority-data: ` + base64.StdEncoding.EncodeToString(caPEM) + `
+contexts:
+  - name: test
+    context:
+      cluster: test
+      user: test
+      namespace: web
+users:
+  - name: test
+    user:
+      token: test-token
+`
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+	return path
+}
+
+// generateTestCertificatePair 生成测试用自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -0,0 +1,90 @@
+package kubernetes
+
+// KubernetesSecretResource 是可以安全上报到 anSSL 后端的脱敏 TLS Secret 资源。
+type KubernetesSecretResource struct {
+	TargetRef string   // TargetRef 是客户端生成的不透明稳定引用。
+	Label     string   // Label 是 namespace/name 形式的展示名称。
+	Namespace string   // Namespace 是 Secret 所在命名空间。
+	Domain    string   // Domain 是当前证书的主域名，尚未创建时为空。
+	Domains   []string // Domains 是当前证书覆盖的全部规范化域名。
+	Status    string   // Status 是 Active、NotCreated 或 Unsupported。
+}
+
+// kubernetesSecretRecord 在 deploy 内部关联脱敏资源和真实 Secret 身份。
+type kubernetesSecretRecord struct {
+	Namespace string                   // Namespace 是 Secret 所在命名空间。
+	Name      string                   // Name 是 Secret 名称。
+	Resource  KubernetesSecretResource // Resource 是可以上报的脱敏资源。
+}
+
+// kubernetesObjectMeta 描述 Secret 元数据中 deploy 需要读取的字段。
+type kubernetesObjectMeta struct {
+	Name      string `json:"name"`      // Name 是 Secret 名称。
+	Namespace string `json:"namespace"` // Namespace 是 Secret 所在命名空间。
+}
+
+// kubernetesSecret 描述 Secret 中 deploy 需要读取的字段。
+type kubernetesSecret struct {
+	Metadata kubernetesObjectMeta `json:"metadata"` // Metadata 是 Secret 元数据。
+	Type     string               `json:"type"`     // Type 是 Secret 类型。
+	Data     map[string]string    `json:"data"`     // Data 是 base64 编码的 Secret 数据。
+}
+
+// kubernetesSecretList 描述 Secret 列表接口的分页响应。
+type kubernetesSecretList struct {
+	Metadata struct {
+		Continue string `json:"continue"` // Continue 是下一页的分页令牌。
+	} `json:"metadata"` // Metadata 是列表元数据。
+	Items []kubernetesSecret `json:"items"` // Items 是当前页 Secret。
+}
+
+// kubernetesStatus 描述 Kubernetes API 的错误响应。
+type kubernetesStatus struct {
+	Message string `json:"message"` // Message 是 API Server 返回的错误说明。
+	Reason  string `json:"reason"`  // Reason 是机器可读的错误原因。
+}
+
+// kubeconfigFile 描述 deploy 支持的 kubeconfig 子集。
+type kubeconfigFile struct {
+	CurrentContext string `yaml:"current-context"` // CurrentContext 是默认上下文名称。
+	Clusters       []struct {
+		Name    string            `yaml:"name"`    // Name 是集群名称。
+		Cluster kubeconfigCluster `yaml:"cluster"` // Cluster 是集群连接参数。
+	} `yaml:"clusters"` // Clusters 是集群列表。
+	Contexts []struct {
+		Name    string            `yaml:"name"`    // Name 是上下文名称。
+		Context kubeconfigContext `yaml:"context"` // Context 是上下文绑定的集群、用户和命名空间。
+	} `yaml:"contexts"` // Contexts 是上下文列表。
+	Users []struct {
+		Name string         `yaml:"name"` // Name 是用户名称。
+		User kubeconfigUser `yaml:"user"` // User 是用户认证参数。
+	} `yaml:"users"` // Users 是用户列表。
+}
+
+// kubeconfigCluster 描述 kubeconfig 集群连接参数。
+type kubeconfigCluster struct {
+	Server                   string `yaml:"server"`                     // Server 是 API Server 地址。
+	CertificateAuthority     string `yaml:"certificate-authority"`      // CertificateAuthority 是 CA 文件路径。
+	CertificateAuthorityData string `yaml:"certificate-authority-data"` // CertificateAuthorityData 是 base64 编码的 CA。
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`   // InsecureSkipTLSVerify 跳过 API Server 证书校验。
+	TLSServerName            string `yaml:"tls-server-name"`            // TLSServerName 覆盖证书校验使用的服务器名称。
+}
+
+// kubeconfigContext 描述 kubeconfig 上下文。
+type kubeconfigContext struct {
+	Cluster   string `yaml:"cluster"`   // Cluster 是集群名称。
+	User      string `yaml:"user"`      // User 是用户名称。
+	Namespace string `yaml:"namespace"` // Namespace 是默认命名空间。
+}
+
+// kubeconfigUser 描述 deploy 支持的 kubeconfig 用户认证方式。
+type kubeconfigUser struct {
+	Token                 string         `yaml:"token"`                   // Token 是 Bearer 令牌。
+	TokenFile             string         `yaml:"tokenFile"`               // TokenFile 是 Bearer 令牌文件路径。
+	ClientCertificate     string         `yaml:"client-certificate"`      // ClientCertificate 是客户端证书路径。
+	ClientCertificateData string         `yaml:"client-certificate-data"` // ClientCertificateData 是 base64 编码的客户端证书。
+	ClientKey             string         `yaml:"client-key"`              // ClientKey 是客户端私钥路径。
+	ClientKeyData         string         `yaml:"client-key-data"`         // ClientKeyData 是 base64 编码的客户端私钥。
+	Exec                  map[string]any `yaml:"exec"`                    // Exec 是 deploy 不支持的外部凭据插件。
+	AuthProvider          map[string]any `yaml:"auth-provider"`           // AuthProvider 是 deploy 不支持的旧版认证插件。
+}
@@ -0,0 +1,218 @@
+package localtarget
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	localTargetPrefix = "local-target-"
+	// LocalTargetStatusReady 表示实例目录可用，部署时按配置发布文件并执行命令。
+	LocalTargetStatusReady = "Ready"
+)
+
+// LocalTargetResource 是可以安全上报到 anSSL 后端的本地部署实例资源。
+type LocalTargetResource struct {
+	TargetRef string // TargetRef 是客户端根据实例名称生成的不透明稳定引用。
+	Label     string // Label 是配置中的实例名称。
+	Path      string // Path 是实例证书根目录。
+	Status    string // Status 是实例状态。
+}
+
+// IsLocalTargetConfiguredWithContext 从 context 快照判断是否配置了本地部署实例。
+func IsLocalTargetConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && len(configuration.SSL.LocalTargets) > 0
+}
+
+// DiscoverLocalTargetResources 把配置中的每个具名实例列为一个部署资源。
+func DiscoverLocalTargetResources(ctx context.Context) ([]LocalTargetResource, error) {
+	targets, err := getLocalTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]LocalTargetResource, 0, len(targets))
+	for _, target := range targets {
+		resources = append(resources, LocalTargetResource{
+			TargetRef: buildLocalTargetRef(target.Name),
+			Label:     target.Name,
+			Path:      target.Path,
+			Status:    LocalTargetStatusReady,
+		})
+	}
+	return resources, nil
+}
+
+// TestLocalTargetConnection 检查实例目录可写且属主可以解析，不执行校验或重载命令。
+func TestLocalTargetConnection(ctx context.Context, targetRef string) error {
+	target, err := findLocalTarget(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	if _, _, err := shared.ResolveOwnership(target.Owner, target.Group); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(target.Path, 0755); err != nil {
+		return fmt.Errorf("创建本地部署目录失败: %w", err)
+	}
+	probe, err := os.CreateTemp(target.Path, ".anssl-probe-*")
+	if err != nil {
+		return fmt.Errorf("本地部署目录不可写: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// DeployCertificateToLocalTarget 把证书发布到实例目录 path/<域名>/，发布前后的命令都在同一回滚事务中执行。
+func DeployCertificateToLocalTarget(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	target, err := findLocalTarget(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	canonicalDomain, safeDomain, err := shared.NormalizeDeploymentDomain(domain)
+	if err != nil {
+		return err
+	}
+	certMode, err := config.ParseFileMode(target.CertMode)
+	if err != nil {
+		return err
+	}
+	keyMode, err := config.ParseFileMode(target.KeyMode)
+	if err != nil {
+		return err
+	}
+	uid, gid, err := shared.ResolveOwnership(target.Owner, target.Group)
+	if err != nil {
+		return err
+	}
+
+	stagingDir, err := os.MkdirTemp("", "anssl-local-target-*")
+	if err != nil {
+		return fmt.Errorf("创建本地部署临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	if err := writeFileWithMode(filepath.Join(stagingDir, target.CertFile), []byte(certificatePEM), certMode); err != nil {
+		return fmt.Errorf("写入证书文件失败: %w", err)
+	}
+	if err := writeFileWithMode(filepath.Join(stagingDir, target.KeyFile), []byte(privateKeyPEM), keyMode); err != nil {
+		return fmt.Errorf("写入私钥文件失败: %w", err)
+	}
+
+	// 发布前命令针对临时目录执行，失败时目标目录保持不变。
+	if err := runLocalCommands(ctx, target.PreCommands, commandEnvironment(canonicalDomain, stagingDir, target)); err != nil {
+		return fmt.Errorf("本地部署实例 %s 发布前命令执行失败: %w", target.Name, err)
+	}
+
+	if err := os.MkdirAll(target.Path, 0755); err != nil {
+		return fmt.Errorf("创建本地部署目录失败: %w", err)
+	}
+	targetDir, err := shared.SafeJoinUnderBase(target.Path, safeDomain)
+	if err != nil {
+		return err
+	}
+	err = shared.PublishDirectoryWithValidationContext(ctx, stagingDir, targetDir, func() error {
+		// 跨设备发布会复制文件，属主必须在目标目录中设置。
+		if uid >= 0 || gid >= 0 {
+			for _, name := range []string{"", target.CertFile, target.KeyFile} {
+				if err := os.Lchown(filepath.Join(targetDir, name), uid, gid); err != nil {
+					return fmt.Errorf("设置文件属主失败: %w", err)
+				}
+			}
+		}
+		if err := runLocalCommands(ctx, target.PostCommands, commandEnvironment(canonicalDomain, targetDir, target)); err != nil {
+			return fmt.Errorf("本地部署实例 %s 发布后命令执行失败: %w", target.Name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.Info("本地部署实例证书已更新", "name", target.Name, "path", targetDir)
+	return nil
+}
+
+// getLocalTargets 读取当前操作快照中的本地部署实例。
+func getLocalTargets(ctx context.Context) ([]*config.LocalTargetConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || len(configuration.SSL.LocalTargets) == 0 {
+		return nil, errors.New("未配置本地部署实例 (ssl.localTargets)")
+	}
+	return configuration.SSL.LocalTargets, nil
+}
+
+// findLocalTarget 根据 targetRef 重新定位配置中的实例，实例改名或删除后引用失效。
+func findLocalTarget(ctx context.Context, targetRef string) (*config.LocalTargetConfig, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("本地部署实例 targetRef 不能为空")
+	}
+	targets, err := getLocalTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		if buildLocalTargetRef(target.Name) == targetRef {
+			return target, nil
+		}
+	}
+	return nil, errors.New("本地部署实例不存在或已改名，请重新配置部署目标")
+}
+
+// buildLocalTargetRef 根据实例名称生成稳定的不透明引用。
+func buildLocalTargetRef(name string) string {
+	identity := strings.Join([]string{"ansslCli", "DEPLOYMENT_TYPE_ANSSL_CLI_LOCAL_TARGET_CERT", name}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return localTargetPrefix + hex.EncodeToString(digest[:12])
+}
+
+// writeFileWithMode 写入文件并显式设置权限，避免受 umask 影响。
+func writeFileWithMode(path string, content []byte, mode os.FileMode) error {
+	if err := os.WriteFile(path, content, mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
+// commandEnvironment 返回传给校验和重载命令的证书位置环境变量。
+func commandEnvironment(domain, certDir string, target *config.LocalTargetConfig) []string {
+	return []string{
+		"ANSSL_DOMAIN=" + domain,
+		"ANSSL_CERT_DIR=" + certDir,
+		"ANSSL_CERT_FILE=" + filepath.Join(certDir, target.CertFile),
+		"ANSSL_KEY_FILE=" + filepath.Join(certDir, target.KeyFile),
+	}
+}
+
+// runLocalCommands 按顺序执行命令，遇到第一个失败立即返回。
+func runLocalCommands(ctx context.Context, commands []config.LocalCommandConfig, env []string) error {
+	for _, command := range commands {
+		timeout := time.Duration(command.Timeout) * time.Second
+		if timeout <= 0 {
+			timeout = time.Minute
+		}
+		if err := shared.RunShellCommandWithContext(ctx, command.Command, timeout, env); err != nil {
+			return err
+		}
+		logger.Info("本地部署命令执行成功", "command", command.Command)
+	}
+	return nil
+}
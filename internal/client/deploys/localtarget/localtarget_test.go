@@ -0,0 +1,123 @@
+package localtarget
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestDeployCertificateToLocalTargetRunsCommands 验证按实例配置的文件名和权限发布，并在前后执行命令。
+func TestDeployCertificateToLocalTargetRunsCommands(t *testing.T) {
+	basePath := filepath.Join(t.TempDir(), "certs")
+	marker := filepath.Join(t.TempDir(), "reloaded")
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "example.com")
+	ctx := shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{
+		LocalTargets: []*config.LocalTargetConfig{
+			{Name: "other", Path: filepath.Join(t.TempDir(), "other")},
+			{
+				Name: "app", Path: basePath,
+				CertificateFilesConfig: config.CertificateFilesConfig{CertFile: "fullchain.pem", KeyFile: "key.pem", CertMode: "0640", KeyMode: "0600"},
+				PreCommands:            []config.LocalCommandConfig{{Command: `test -s "$ANSSL_CERT_FILE" && test -s "$ANSSL_KEY_FILE"`, Timeout: 5}},
+				PostCommands:           []config.LocalCommandConfig{{Command: `echo "$ANSSL_DOMAIN $ANSSL_CERT_DIR" > ` + marker, Timeout: 5}},
+			},
+		},
+	}}})
+
+	resources, err := DiscoverLocalTargetResources(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverLocalTargetResources: %v", err)
+	}
+	if len(resources) != 2 || resources[1].Label != "app" || !strings.HasPrefix(resources[1].TargetRef, localTargetPrefix) || resources[0].TargetRef == resources[1].TargetRef {
+		t.Fatalf("发现的实例资源不匹配: %+v", resources)
+	}
+	if err := TestLocalTargetConnection(ctx, resources[1].TargetRef); err != nil {
+		t.Fatalf("TestLocalTargetConnection: %v", err)
+	}
+	if err := DeployCertificateToLocalTarget(ctx, resources[1].TargetRef, "example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToLocalTarget: %v", err)
+	}
+
+	targetDir := filepath.Join(basePath, "example.com")
+	for name, mode := range map[string]os.FileMode{"fullchain.pem": 0o640, "key.pem": 0o600} {
+		info, err := os.Stat(filepath.Join(targetDir, name))
+		if err != nil || info.Mode().Perm() != mode {
+			t.Fatalf("%s 权限不匹配: info=%v err=%v", name, info, err)
+		}
+	}
+	content, err := os.ReadFile(marker)
+	if err != nil || strings.TrimSpace(string(content)) != "example.com "+targetDir {
+		t.Fatalf("发布后命令未在目标目录执行: content=%q err=%v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(resources[0].Path, "example.com")); !os.IsNotExist(err) {
+		t.Fatalf("其他实例目录不应被写入: %v", err)
+	}
+}
+
+// TestDeployCertificateToLocalTargetRollsBackWhenPostCommandFails 验证发布后命令失败时恢复旧证书文件。
+func TestDeployCertificateToLocalTargetRollsBackWhenPostCommandFails(t *testing.T) {
+	basePath := filepath.Join(t.TempDir(), "certs")
+	targetDir := filepath.Join(basePath, "example.com")
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		t.Fatalf("mkdir target: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "cert.pem"), []byte("old"), 0o644); err != nil {
+		t.Fatalf("write old certificate: %v", err)
+	}
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "example.com")
+	target := &config.LocalTargetConfig{
+		Name: "app", Path: basePath,
+		CertificateFilesConfig: config.CertificateFilesConfig{CertFile: "cert.pem", KeyFile: "privateKey.key", CertMode: "0644", KeyMode: "0600"},
+		PostCommands:           []config.LocalCommandConfig{{Command: "true", Timeout: 5}, {Command: "echo reload failed >&2; exit 3", Timeout: 5}},
+	}
+	ctx := shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{LocalTargets: []*config.LocalTargetConfig{target}}}})
+
+	err := DeployCertificateToLocalTarget(ctx, buildLocalTargetRef("app"), "example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "reload failed") {
+		t.Fatalf("发布后命令失败应返回命令输出: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(targetDir, "cert.pem"))
+	if err != nil || string(content) != "old" {
+		t.Fatalf("旧证书未恢复: content=%q err=%v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "privateKey.key")); !os.IsNotExist(err) {
+		t.Fatalf("回滚后不应残留新私钥: %v", err)
+	}
+}
+
+// generateTestCertificatePair 生成测试用自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
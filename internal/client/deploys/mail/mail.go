@@ -0,0 +1,255 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	mailCommandTimeout  = 30 * time.Second
+	mailDialTimeout     = 5 * time.Second
+	mailVerifyInterval  = time.Second
+	mailRollbackTimeout = 30 * time.Second
+	// mailChainFile 是 Postfix smtpd_tls_chain_files 引用的私钥加完整证书链文件。
+	mailChainFile = "chain.pem"
+)
+
+var (
+	// postfixCommand 允许测试替换 postfix 可执行文件，生产环境始终从 PATH 查找。
+	postfixCommand = "postfix"
+	// doveconfCommand 允许测试替换 doveconf 可执行文件。
+	doveconfCommand = "doveconf"
+	// doveadmCommand 允许测试替换 doveadm 可执行文件。
+	doveadmCommand = "doveadm"
+	// dialMail 允许测试把校验端口映射到本地监听地址。
+	dialMail = (&net.Dialer{Timeout: mailDialTimeout}).DialContext
+	// mailVerifyTimeout 是重新加载后等待各端口提供新证书的总时长，测试可以缩短。
+	mailVerifyTimeout = 30 * time.Second
+	// mailImplicitTLSPorts 是连接后直接进行 TLS 握手的端口，其余端口使用 SMTP STARTTLS。
+	mailImplicitTLSPorts = map[int]bool{465: true, 993: true, 995: true}
+)
+
+// DeployWithContext 发布邮件服务证书目录，在同一发布事务中校验配置、重新加载服务并确认各端口已提供新证书。
+func DeployWithContext(ctx context.Context, sourceDir, domain, safeDomain string, mailConfig *config.MailConfig) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if mailConfig == nil || mailConfig.Path == "" {
+		return errors.New("未配置邮件服务证书目录 (ssl.mail.path)")
+	}
+	if err := shared.ValidateCertificateFiles(sourceDir, domain); err != nil {
+		return err
+	}
+	certificatePEM, err := os.ReadFile(filepath.Join(sourceDir, "cert.pem"))
+	if err != nil {
+		return fmt.Errorf("读取证书文件失败: %w", err)
+	}
+	privateKeyPEM, err := os.ReadFile(filepath.Join(sourceDir, "privateKey.key"))
+	if err != nil {
+		return fmt.Errorf("读取私钥文件失败: %w", err)
+	}
+	fingerprint, err := providers.LeafCertificateSHA256(string(certificatePEM))
+	if err != nil {
+		return err
+	}
+
+	stagingDir, err := os.MkdirTemp("", "anssl-mail-*")
+	if err != nil {
+		return fmt.Errorf("创建邮件服务临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	files := []struct {
+		name    string
+		content []byte
+		mode    os.FileMode
+	}{
+		{"cert.pem", certificatePEM, 0o644},
+		{"privateKey.key", privateKeyPEM, 0o600},
+		// Postfix 要求同一文件中私钥在前、证书链在后。
+		{mailChainFile, append(append(bytes.TrimRight(privateKeyPEM, "\n"), '\n'), certificatePEM...), 0o600},
+	}
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(stagingDir, file.name), file.content, file.mode); err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", file.name, err)
+		}
+	}
+
+	if err := os.MkdirAll(mailConfig.Path, 0755); err != nil {
+		return fmt.Errorf("创建邮件服务证书目录失败: %w", err)
+	}
+	targetDir, err := shared.SafeJoinUnderBase(mailConfig.Path, safeDomain)
+	if err != nil {
+		return err
+	}
+
+	reloaded := false
+	err = shared.PublishDirectoryWithValidationContext(ctx, stagingDir, targetDir, func() error {
+		if err := checkMailServices(ctx, mailConfig.Services); err != nil {
+			return fmt.Errorf("邮件服务配置校验失败: %w", err)
+		}
+		reloaded = true
+		if err := reloadMailServices(ctx, mailConfig.Services); err != nil {
+			return fmt.Errorf("邮件服务重新加载失败: %w", err)
+		}
+		if err := verifyMailCertificates(ctx, mailConfig, domain, fingerprint); err != nil {
+			return fmt.Errorf("邮件服务证书握手校验失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if reloaded {
+			// 发布事务已恢复旧证书目录，再次重新加载让服务回到旧证书。
+			rollbackContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), mailRollbackTimeout)
+			defer cancel()
+			if reloadErr := reloadMailServices(rollbackContext, mailConfig.Services); reloadErr != nil {
+				logger.Warn("恢复旧证书后重新加载邮件服务失败", "error", reloadErr)
+			}
+		}
+		return err
+	}
+	logger.Info("邮件服务证书已更新", "path", targetDir, "services", strings.Join(mailConfig.Services, ","))
+	return nil
+}
+
+// TestMailConnectionWithContext 只读校验已启用邮件服务的配置，并确认证书目录可写。
+func TestMailConnectionWithContext(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || configuration.SSL.Mail == nil {
+		return errors.New("未配置邮件服务 (ssl.mail)")
+	}
+	mailConfig := configuration.SSL.Mail
+	if err := os.MkdirAll(mailConfig.Path, 0755); err != nil {
+		return fmt.Errorf("创建邮件服务证书目录失败: %w", err)
+	}
+	probe, err := os.CreateTemp(mailConfig.Path, ".anssl-probe-*")
+	if err != nil {
+		return fmt.Errorf("邮件服务证书目录不可写: %w", err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	if err := checkMailServices(ctx, mailConfig.Services); err != nil {
+		return fmt.Errorf("邮件服务配置校验失败: %w", err)
+	}
+	return nil
+}
+
+// checkMailServices 对已启用的服务执行 postfix check 和 doveconf -n。
+func checkMailServices(ctx context.Context, services []string) error {
+	for _, service := range services {
+		switch service {
+		case config.MailServicePostfix:
+			if err := runMailCommand(ctx, postfixCommand, "check"); err != nil {
+				return fmt.Errorf("postfix check: %w", err)
+			}
+		case config.MailServiceDovecot:
+			if err := runMailCommand(ctx, doveconfCommand, "-n"); err != nil {
+				return fmt.Errorf("doveconf -n: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// reloadMailServices 依次重新加载已启用的服务，单个服务失败时继续尝试其余服务。
+func reloadMailServices(ctx context.Context, services []string) error {
+	var failures []error
+	for _, service := range services {
+		switch service {
+		case config.MailServicePostfix:
+			if err := runMailCommand(ctx, postfixCommand, "reload"); err != nil {
+				failures = append(failures, fmt.Errorf("postfix reload: %w", err))
+			}
+		case config.MailServiceDovecot:
+			if err := runMailCommand(ctx, doveadmCommand, "reload"); err != nil {
+				failures = append(failures, fmt.Errorf("doveadm reload: %w", err))
+			}
+		}
+	}
+	return errors.Join(failures...)
+}
+
+// runMailCommand 在独立超时内执行邮件服务管理命令，失败时附带命令输出。
+func runMailCommand(parent context.Context, command string, args ...string) error {
+	ctx, cancel := context.WithTimeout(parent, mailCommandTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, command, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// verifyMailCertificates 在超时内轮询每个校验端口，直到握手返回的叶证书与新证书指纹一致。
+func verifyMailCertificates(ctx context.Context, mailConfig *config.MailConfig, domain, fingerprint string) error {
+	serverName := strings.TrimPrefix(shared.NormalizeCertificateDomain(domain), "*.")
+	deadline := time.Now().Add(mailVerifyTimeout)
+	for _, port := range mailConfig.VerifyPorts {
+		address := net.JoinHostPort(mailConfig.VerifyHost, strconv.Itoa(port))
+		// 服务重新加载是异步的，旧进程退出前仍可能返回旧证书。
+		err := shared.WaitForServedCertificate(ctx, fingerprint, deadline, mailVerifyInterval, func(ctx context.Context) ([]byte, error) {
+			return fetchMailLeafCertificate(ctx, address, port, serverName)
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", address, err)
+		}
+		logger.Info("邮件服务端口已提供新证书", "address", address)
+	}
+	return nil
+}
+
+// fetchMailLeafCertificate 连接邮件端口完成 TLS 握手，返回服务端叶证书。
+func fetchMailLeafCertificate(parent context.Context, address string, port int, serverName string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(parent, mailDialTimeout)
+	defer cancel()
+	conn, err := dialMail(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if mailImplicitTLSPorts[port] {
+		return shared.HandshakeServedLeaf(ctx, conn, serverName)
+	}
+
+	client, err := smtp.NewClient(conn, serverName)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	if err := client.Hello("localhost"); err != nil {
+		return nil, err
+	}
+	if ok, _ := client.Extension("STARTTLS"); !ok {
+		return nil, errors.New("服务端未提供 STARTTLS")
+	}
+	var leaf []byte
+	err = client.StartTLS(shared.ServedLeafTLSConfig(serverName, &leaf))
+	if leaf == nil {
+		if err == nil {
+			err = errors.New("服务端未返回证书")
+		}
+		return nil, err
+	}
+	client.Quit()
+	return leaf, nil
+}
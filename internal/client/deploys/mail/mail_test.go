@@ -0,0 +1,236 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestDeployWithContextVerifiesServedCertificate 验证发布 Postfix 链文件、执行配置校验和重新加载，并通过 STARTTLS 和 993 端口确认新证书。
+func TestDeployWithContextVerifiesServedCertificate(t *testing.T) {
+	root := t.TempDir()
+	mailPath := filepath.Join(root, "mail")
+	stateDir := installFakeMailCommands(t)
+	targetDir := filepath.Join(mailPath, "mail.example.com")
+	load := func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		certificate, err := tls.LoadX509KeyPair(filepath.Join(targetDir, "cert.pem"), filepath.Join(targetDir, "privateKey.key"))
+		return &certificate, err
+	}
+	smtpAddress := startFakeSMTPServer(t, load)
+	imapsAddress := startFakeTLSServer(t, load)
+	redirectMailPorts(t, map[string]string{"25": smtpAddress, "587": smtpAddress, "993": imapsAddress})
+
+	sourceDir := writeSourceDir(t, filepath.Join(root, "source"), "mail.example.com")
+	certificatePEM := readTestFile(t, filepath.Join(sourceDir, "cert.pem"))
+	privateKeyPEM := readTestFile(t, filepath.Join(sourceDir, "privateKey.key"))
+	mailConfig := &config.MailConfig{Path: mailPath, Services: []string{config.MailServicePostfix, config.MailServiceDovecot}, VerifyHost: "localhost", VerifyPorts: []int{25, 587, 993}}
+	if err := DeployWithContext(context.Background(), sourceDir, "mail.example.com", "mail.example.com", mailConfig); err != nil {
+		t.Fatalf("DeployWithContext: %v", err)
+	}
+	if readTestFile(t, filepath.Join(targetDir, mailChainFile)) != strings.TrimRight(privateKeyPEM, "\n")+"\n"+certificatePEM {
+		t.Fatal("Postfix 链文件应为私钥加完整证书链")
+	}
+	if calls := readTestFile(t, filepath.Join(stateDir, "calls")); calls != "postfix check\ndoveconf -n\npostfix reload\ndoveadm reload\n" {
+		t.Fatalf("命令调用顺序不匹配:\n%s", calls)
+	}
+}
+
+// TestDeployWithContextRollsBackAndReloadsWhenServedCertificateDiffers 验证配置校验失败时不重新加载，握手证书不一致时恢复旧目录并再次重新加载。
+func TestDeployWithContextRollsBackAndReloadsWhenServedCertificateDiffers(t *testing.T) {
+	root := t.TempDir()
+	mailPath := filepath.Join(root, "mail")
+	stateDir := installFakeMailCommands(t)
+	originalTimeout := mailVerifyTimeout
+	mailVerifyTimeout = 0
+	t.Cleanup(func() { mailVerifyTimeout = originalTimeout })
+	staleDir := writeSourceDir(t, filepath.Join(root, "stale"), "mail.example.com")
+	stale, err := tls.LoadX509KeyPair(filepath.Join(staleDir, "cert.pem"), filepath.Join(staleDir, "privateKey.key"))
+	if err != nil {
+		t.Fatalf("load stale certificate: %v", err)
+	}
+	smtpAddress := startFakeSMTPServer(t, func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return &stale, nil })
+	redirectMailPorts(t, map[string]string{"25": smtpAddress})
+	mailConfig := &config.MailConfig{Path: mailPath, Services: []string{config.MailServicePostfix}, VerifyHost: "localhost", VerifyPorts: []int{25}}
+
+	writeTestFile(t, filepath.Join(stateDir, "fail-check"), "")
+	sourceDir := writeSourceDir(t, filepath.Join(root, "source"), "mail.example.com")
+	if err := DeployWithContext(context.Background(), sourceDir, "mail.example.com", "mail.example.com", mailConfig); err == nil || !strings.Contains(err.Error(), "邮件服务配置校验失败") {
+		t.Fatalf("postfix check 失败时应返回错误: %v", err)
+	}
+	if calls := readTestFile(t, filepath.Join(stateDir, "calls")); calls != "postfix check\n" {
+		t.Fatalf("配置校验失败后不应重新加载:\n%s", calls)
+	}
+
+	os.Remove(filepath.Join(stateDir, "fail-check"))
+	os.Remove(filepath.Join(stateDir, "calls"))
+	if err := DeployWithContext(context.Background(), sourceDir, "mail.example.com", "mail.example.com", mailConfig); err == nil || !strings.Contains(err.Error(), "指纹不一致") {
+		t.Fatalf("握手证书不一致时应返回错误: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mailPath, "mail.example.com")); !os.IsNotExist(err) {
+		t.Fatalf("校验失败后不应保留新证书目录: %v", err)
+	}
+	if calls := readTestFile(t, filepath.Join(stateDir, "calls")); calls != "postfix check\npostfix reload\npostfix reload\n" {
+		t.Fatalf("恢复旧证书后应再次重新加载:\n%s", calls)
+	}
+}
+
+// installFakeMailCommands 安装模拟 postfix、doveconf 和 doveadm：记录每次调用，fail-check 文件存在时 postfix check 失败。
+func installFakeMailCommands(t *testing.T) string {
+	t.Helper()
+	stateDir := t.TempDir()
+	for _, name := range []string{"postfix", "doveconf", "doveadm"} {
+		script := "#!/bin/sh\n" +
+			"echo \"" + name + " $*\" >> '" + stateDir + "/calls'\n" +
+			"if [ \"$1\" = check ] && [ -f '" + stateDir + "/fail-check' ]; then echo 'postfix: fatal: bad config'; exit 1; fi\n"
+		if err := os.WriteFile(filepath.Join(stateDir, name), []byte(script), 0o755); err != nil {
+			t.Fatalf("write fake %s: %v", name, err)
+		}
+	}
+	originalPostfix, originalDoveconf, originalDoveadm := postfixCommand, doveconfCommand, doveadmCommand
+	postfixCommand = filepath.Join(stateDir, "postfix")
+	doveconfCommand = filepath.Join(stateDir, "doveconf")
+	doveadmCommand = filepath.Join(stateDir, "doveadm")
+	t.Cleanup(func() {
+		postfixCommand, doveconfCommand, doveadmCommand = originalPostfix, originalDoveconf, originalDoveadm
+	})
+	return stateDir
+}
+
+// redirectMailPorts 把 localhost 上的校验端口映射到测试监听地址。
+func redirectMailPorts(t *testing.T, addresses map[string]string) {
+	t.Helper()
+	original := dialMail
+	dialMail = func(ctx context.Context, network, address string) (net.Conn, error) {
+		_, port, _ := net.SplitHostPort(address)
+		return (&net.Dialer{}).DialContext(ctx, network, addresses[port])
+	}
+	t.Cleanup(func() { dialMail = original })
+}
+
+// startFakeSMTPServer 启动只支持 EHLO、STARTTLS 和 QUIT 的 SMTP 服务。
+func startFakeSMTPServer(t *testing.T, getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)) string {
+	t.Helper()
+	return startFakeServer(t, func(conn net.Conn) {
+		writer := bufio.NewWriter(conn)
+		reply := func(text string) { writer.WriteString(text + "\r\n"); writer.Flush() }
+		reply("220 mail.example.com ESMTP")
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch strings.ToUpper(strings.TrimSpace(line)) {
+			case "EHLO LOCALHOST":
+				reply("250-mail.example.com")
+				reply("250 STARTTLS")
+			case "STARTTLS":
+				reply("220 ready")
+				tlsConn := tls.Server(conn, &tls.Config{GetCertificate: getCertificate})
+				if tlsConn.Handshake() != nil {
+					return
+				}
+				conn = tlsConn
+				reader, writer = bufio.NewReader(tlsConn), bufio.NewWriter(tlsConn)
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 unsupported")
+			}
+		}
+	})
+}
+
+// startFakeTLSServer 启动连接后直接握手的 TLS 服务，模拟 IMAPS。
+func startFakeTLSServer(t *testing.T, getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)) string {
+	t.Helper()
+	return startFakeServer(t, func(conn net.Conn) {
+		tls.Server(conn, &tls.Config{GetCertificate: getCertificate}).Handshake()
+	})
+}
+
+// startFakeServer 在随机端口上逐个处理连接，测试结束时关闭监听。
+func startFakeServer(t *testing.T, handle func(net.Conn)) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
+				handle(conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// writeSourceDir 生成包含自签证书和私钥的待发布目录。
+func writeSourceDir(t *testing.T, dir, domain string) string {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	writeTestFile(t, filepath.Join(dir, "cert.pem"), string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})))
+	writeTestFile(t, filepath.Join(dir, "privateKey.key"), string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})))
+	return dir
+}
+
+// writeTestFile 创建父目录并写入测试文件。
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// readTestFile 读取测试文件内容。
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(content)
+}
@@ -0,0 +1,98 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	mediaServerRequestTimeout = 15 * time.Second
+	mediaServerDialTimeout    = 5 * time.Second
+)
+
+// pingMediaServer 调用需要认证的只读接口，确认 API 地址和密钥可用。
+func pingMediaServer(ctx context.Context, server *config.MediaServerConfig) error {
+	switch server.Kind {
+	case config.MediaServerKindJellyfin:
+		return requestMediaServer(ctx, server, http.MethodGet, "/System/Info", nil)
+	case config.MediaServerKindEmby:
+		return requestMediaServer(ctx, server, http.MethodGet, "/emby/System/Info", nil)
+	default:
+		return requestMediaServer(ctx, server, http.MethodGet, "/:/prefs", nil)
+	}
+}
+
+// applyMediaServerCertificate 让服务器重新读取 PFX：Jellyfin 和 Emby 只在启动时加载证书，需要重启；Plex 写回证书偏好设置后重新加载网络配置。
+func applyMediaServerCertificate(ctx context.Context, server *config.MediaServerConfig) error {
+	var err error
+	switch server.Kind {
+	case config.MediaServerKindJellyfin:
+		err = requestMediaServer(ctx, server, http.MethodPost, "/System/Restart", nil)
+	case config.MediaServerKindEmby:
+		err = requestMediaServer(ctx, server, http.MethodPost, "/emby/System/Restart", nil)
+	default:
+		err = requestMediaServer(ctx, server, http.MethodPut, "/:/prefs", url.Values{
+			"customCertificatePath": {server.PFXFile},
+			"customCertificateKey":  {server.Password},
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("%s 重新加载证书失败: %w", server.Kind, err)
+	}
+	logger.Info("已通知媒体服务器加载证书", "name", server.Name, "kind", server.Kind)
+	return nil
+}
+
+// requestMediaServer 携带 API 密钥请求媒体服务器，2xx 以外的状态码视为失败。
+func requestMediaServer(parent context.Context, server *config.MediaServerConfig, method, path string, query url.Values) error {
+	ctx, cancel := context.WithTimeout(parent, mediaServerRequestTimeout)
+	defer cancel()
+	endpoint := server.URL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	switch server.Kind {
+	case config.MediaServerKindJellyfin:
+		request.Header.Set("Authorization", `MediaBrowser Token="`+server.APIKey+`"`)
+	case config.MediaServerKindEmby:
+		request.Header.Set("X-Emby-Token", server.APIKey)
+	default:
+		request.Header.Set("X-Plex-Token", server.APIKey)
+	}
+	response, err := newMediaServerClient(server).Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("HTTP %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// newMediaServerClient 创建不跟随重定向的 API 客户端，避免 API 密钥被转发到其他地址。
+func newMediaServerClient(server *config.MediaServerConfig) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: server.InsecureSkipVerify, MinVersion: tls.VersionTLS12}
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,214 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	mediaServerTargetPrefix = "media-server-"
+	// mediaServerRollbackTimeout 限制回滚时重新加载旧 PFX 的时长；回滚 context 脱离调用方取消，保证部署被取消后旧证书仍能生效，
+	// 因此必须足够短，并在握手校验中预留出来，使整个部署仍在单次操作超时内结束。
+	mediaServerRollbackTimeout = 10 * time.Second
+	// MediaServerStatusReady 表示实例已配置，部署时写入 PFX 并通过 HTTP API 让服务器加载。
+	MediaServerStatusReady = "Ready"
+)
+
+var (
+	// mediaVerifyTimeout 是重启或重新加载后等待 HTTPS 端口提供新证书的最长时间，实际等待还受操作截止时间限制，测试可以缩短。
+	mediaVerifyTimeout = 40 * time.Second
+	// mediaVerifyInterval 是两次握手校验之间的等待时间。
+	mediaVerifyInterval = 2 * time.Second
+)
+
+// MediaServerResource 是可以安全上报到 anSSL 后端的媒体服务器实例资源。
+type MediaServerResource struct {
+	TargetRef string // TargetRef 是客户端根据实例名称生成的不透明稳定引用。
+	Label     string // Label 是配置中的实例名称。
+	Kind      string // Kind 是 jellyfin、emby 或 plex。
+	Address   string // Address 是握手校验连接的主机和 HTTPS 端口。
+	Status    string // Status 是实例状态。
+}
+
+// IsMediaServerConfiguredWithContext 从 context 快照判断是否配置了媒体服务器实例。
+func IsMediaServerConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && len(configuration.SSL.MediaServers) > 0
+}
+
+// DiscoverMediaServerResources 把配置中的每个具名媒体服务器列为一个部署资源，发现阶段不访问服务器。
+func DiscoverMediaServerResources(ctx context.Context) ([]MediaServerResource, error) {
+	servers, err := getMediaServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]MediaServerResource, 0, len(servers))
+	for _, server := range servers {
+		resources = append(resources, MediaServerResource{
+			TargetRef: buildMediaServerTargetRef(server.Name),
+			Label:     server.Name,
+			Kind:      server.Kind,
+			Address:   httpsAddress(server),
+			Status:    MediaServerStatusReady,
+		})
+	}
+	return resources, nil
+}
+
+// TestMediaServerConnection 检查 PFX 目录可写、属主可以解析，并用 API 密钥调用只读接口，不写入证书也不重启服务器。
+func TestMediaServerConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	server, err := findMediaServer(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	if _, _, err := shared.ResolveOwnership(server.Owner, server.Group); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(filepath.Dir(server.PFXFile), ".anssl-probe-*")
+	if err != nil {
+		return fmt.Errorf("PFX 所在目录不可写: %w", err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	if err := pingMediaServer(ctx, server); err != nil {
+		return fmt.Errorf("%s API 调用失败: %w", server.Kind, err)
+	}
+	return nil
+}
+
+// DeployCertificateToMediaServer 生成 PFX 并替换服务器引用的文件，通过 HTTP API 重启或重新加载网络配置后确认 HTTPS 端口提供新证书，失败时恢复旧文件并再次加载。
+func DeployCertificateToMediaServer(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	server, err := findMediaServer(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	chain, privateKey, err := shared.ParseCertificateChainAndKey([]byte(certificatePEM), []byte(privateKeyPEM))
+	if err != nil {
+		return err
+	}
+	pfx, err := shared.EncodePKCS12(privateKey, chain, "", server.Password)
+	if err != nil {
+		return fmt.Errorf("生成 PFX 失败: %w", err)
+	}
+	fingerprint, err := providers.LeafCertificateSHA256(certificatePEM)
+	if err != nil {
+		return err
+	}
+	uid, gid, err := shared.ResolveOwnership(server.Owner, server.Group)
+	if err != nil {
+		return err
+	}
+	backup, err := shared.BackupFile(server.PFXFile)
+	if err != nil {
+		return err
+	}
+
+	applied := false
+	err = func() error {
+		if err := shared.ReplaceFile(server.PFXFile, pfx, 0o600, uid, gid); err != nil {
+			return fmt.Errorf("写入 PFX 失败: %w", err)
+		}
+		applied = true
+		if err := applyMediaServerCertificate(ctx, server); err != nil {
+			return err
+		}
+		if err := verifyMediaServerCertificate(ctx, server, domain, fingerprint); err != nil {
+			return fmt.Errorf("HTTPS 握手校验失败: %w", err)
+		}
+		return nil
+	}()
+	if err != nil {
+		if restoreErr := shared.RestoreFiles([]shared.FileBackup{backup}); restoreErr != nil {
+			logger.Warn("恢复媒体服务器旧 PFX 失败", "name", server.Name, "error", restoreErr)
+		} else if applied {
+			rollbackContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), mediaServerRollbackTimeout)
+			defer cancel()
+			if applyErr := applyMediaServerCertificate(rollbackContext, server); applyErr != nil {
+				logger.Warn("恢复旧 PFX 后重新加载媒体服务器失败", "name", server.Name, "error", applyErr)
+			}
+		}
+		return fmt.Errorf("媒体服务器 %s 证书部署失败: %w", server.Name, err)
+	}
+	logger.Info("媒体服务器证书已更新", "name", server.Name, "kind", server.Kind, "pfxFile", server.PFXFile)
+	return nil
+}
+
+// verifyMediaServerCertificate 在超时内轮询 HTTPS 端口，直到握手返回的叶证书与新证书指纹一致；等待时间为回滚预留操作剩余时间。
+func verifyMediaServerCertificate(ctx context.Context, server *config.MediaServerConfig, domain, fingerprint string) error {
+	serverName := strings.TrimPrefix(shared.NormalizeCertificateDomain(domain), "*.")
+	deadline := time.Now().Add(providers.OperationWaitTimeout(ctx, mediaVerifyTimeout, mediaServerRollbackTimeout))
+	// Jellyfin 和 Emby 重启期间端口会短暂不可用。
+	return shared.WaitForServedCertificate(ctx, fingerprint, deadline, mediaVerifyInterval, func(ctx context.Context) ([]byte, error) {
+		dialContext, cancel := context.WithTimeout(ctx, mediaServerDialTimeout)
+		defer cancel()
+		return shared.DialServedLeaf(dialContext, httpsAddress(server), serverName)
+	})
+}
+
+// httpsAddress 返回 API 地址中的主机和 httpsPort 组成的握手校验地址。
+func httpsAddress(server *config.MediaServerConfig) string {
+	host := "127.0.0.1"
+	if parsedURL, err := url.Parse(server.URL); err == nil && parsedURL.Hostname() != "" {
+		host = parsedURL.Hostname()
+	}
+	return net.JoinHostPort(host, strconv.Itoa(server.HTTPSPort))
+}
+
+// getMediaServers 读取当前操作快照中的媒体服务器实例配置。
+func getMediaServers(ctx context.Context) ([]*config.MediaServerConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || len(configuration.SSL.MediaServers) == 0 {
+		return nil, errors.New("未配置媒体服务器实例 (ssl.mediaServers)")
+	}
+	return configuration.SSL.MediaServers, nil
+}
+
+// findMediaServer 根据 targetRef 重新定位配置中的媒体服务器实例，实例改名或删除后引用失效。
+func findMediaServer(ctx context.Context, targetRef string) (*config.MediaServerConfig, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("媒体服务器实例 targetRef 不能为空")
+	}
+	servers, err := getMediaServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, server := range servers {
+		if buildMediaServerTargetRef(server.Name) == targetRef {
+			return server, nil
+		}
+	}
+	return nil, errors.New("媒体服务器实例不存在或已改名，请重新配置部署目标")
+}
+
+// buildMediaServerTargetRef 根据实例名称生成稳定的不透明引用。
+func buildMediaServerTargetRef(name string) string {
+	identity := strings.Join([]string{"ansslCli", "DEPLOYMENT_TYPE_ANSSL_CLI_MEDIA_SERVER_PFX", name}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return mediaServerTargetPrefix + hex.EncodeToString(digest[:12])
+}
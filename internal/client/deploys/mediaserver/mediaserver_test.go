@@ -0,0 +1,203 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+// TestDeployCertificateToMediaServerRestartsJellyfin 验证生成带密码的 PFX、携带 API 密钥调用重启接口，并通过 HTTPS 握手确认新证书。
+func TestDeployCertificateToMediaServerRestartsJellyfin(t *testing.T) {
+	root := t.TempDir()
+	server := &config.MediaServerConfig{
+		Name: "home", Kind: config.MediaServerKindJellyfin, APIKey: "jellyfin-key",
+		PFXFile: filepath.Join(root, "jellyfin.pfx"), Password: "pfx-secret", Owner: strconv.Itoa(os.Getuid()),
+	}
+	api := &fakeMediaAPI{}
+	server.URL = startFakeMediaAPI(t, api)
+	server.HTTPSPort = startFakeHTTPSServer(t, func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		content, err := os.ReadFile(server.PFXFile)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, leaf, _, err := pkcs12.DecodeChain(content, server.Password)
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Certificate{Certificate: [][]byte{leaf.Raw}, PrivateKey: privateKey}, nil
+	})
+	ctx := mediaServerTestContext(server)
+
+	resources, err := DiscoverMediaServerResources(ctx)
+	if err != nil || len(resources) != 1 || resources[0].Kind != config.MediaServerKindJellyfin || !strings.HasPrefix(resources[0].TargetRef, mediaServerTargetPrefix) {
+		t.Fatalf("发现的媒体服务器资源不匹配: %+v err=%v", resources, err)
+	}
+	if err := TestMediaServerConnection(ctx, resources[0].TargetRef); err != nil {
+		t.Fatalf("TestMediaServerConnection: %v", err)
+	}
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "media.example.com")
+	if err := DeployCertificateToMediaServer(ctx, resources[0].TargetRef, "media.example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToMediaServer: %v", err)
+	}
+	if info, err := os.Stat(server.PFXFile); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("PFX 权限应为 0600: info=%v err=%v", info, err)
+	}
+	if requests := api.log(); requests != "GET /System/Info;POST /System/Restart" {
+		t.Fatalf("API 调用不匹配: %s", requests)
+	}
+	if authorization := api.header("Authorization"); authorization != `MediaBrowser Token="jellyfin-key"` {
+		t.Fatalf("API 密钥未通过 Authorization 传递: %q", authorization)
+	}
+}
+
+// TestDeployCertificateToMediaServerRestoresPlexPFXWhenCertificateNotServed 验证 Plex 重新加载后仍提供旧证书时恢复旧 PFX 并再次写回证书偏好设置。
+func TestDeployCertificateToMediaServerRestoresPlexPFXWhenCertificateNotServed(t *testing.T) {
+	root := t.TempDir()
+	originalTimeout := mediaVerifyTimeout
+	mediaVerifyTimeout = 0
+	t.Cleanup(func() { mediaVerifyTimeout = originalTimeout })
+	oldCertificate, oldKey := generateTestCertificatePair(t, "plex.example.com")
+	stale, err := tls.X509KeyPair([]byte(oldCertificate), []byte(oldKey))
+	if err != nil {
+		t.Fatalf("load stale certificate: %v", err)
+	}
+	server := &config.MediaServerConfig{
+		Name: "plex", Kind: config.MediaServerKindPlex, APIKey: "plex-token", PFXFile: filepath.Join(root, "plex.pfx"),
+	}
+	api := &fakeMediaAPI{}
+	server.URL = startFakeMediaAPI(t, api)
+	server.HTTPSPort = startFakeHTTPSServer(t, func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return &stale, nil })
+	os.WriteFile(server.PFXFile, []byte("old-pfx"), 0o640)
+	ctx := mediaServerTestContext(server)
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "plex.example.com")
+	err = DeployCertificateToMediaServer(ctx, buildMediaServerTargetRef("plex"), "plex.example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "指纹不一致") {
+		t.Fatalf("握手证书不一致时应返回错误: %v", err)
+	}
+	if content, _ := os.ReadFile(server.PFXFile); string(content) != "old-pfx" {
+		t.Fatal("旧 PFX 未恢复")
+	}
+	if info, err := os.Stat(server.PFXFile); err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("旧 PFX 权限未恢复: info=%v err=%v", info, err)
+	}
+	reload := "PUT /:/prefs?customCertificateKey=&customCertificatePath=" + strings.ReplaceAll(server.PFXFile, "/", "%2F")
+	if requests := api.log(); requests != reload+";"+reload {
+		t.Fatalf("恢复旧 PFX 后应再次重新加载: %s", requests)
+	}
+	if token := api.header("X-Plex-Token"); token != "plex-token" {
+		t.Fatalf("Plex 令牌未通过 X-Plex-Token 传递: %q", token)
+	}
+}
+
+// fakeMediaAPI 记录收到的请求方法、路径和最后一次请求的请求头，全部返回 204。
+type fakeMediaAPI struct {
+	mu       sync.Mutex
+	requests []string
+	headers  http.Header
+}
+
+// ServeHTTP 记录请求并返回 204。
+func (f *fakeMediaAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.RequestURI())
+	f.headers = r.Header.Clone()
+	f.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// log 返回以分号连接的请求记录。
+func (f *fakeMediaAPI) log() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return strings.Join(f.requests, ";")
+}
+
+// header 返回最后一次请求中的指定请求头。
+func (f *fakeMediaAPI) header(name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.headers.Get(name)
+}
+
+// startFakeMediaAPI 启动明文 HTTP API 服务并返回地址。
+func startFakeMediaAPI(t *testing.T, api *fakeMediaAPI) string {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// startFakeHTTPSServer 在随机端口上只完成 TLS 握手，模拟媒体服务器的 HTTPS 端口。
+func startFakeHTTPSServer(t *testing.T, getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
+				tls.Server(conn, &tls.Config{GetCertificate: getCertificate}).Handshake()
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// mediaServerTestContext 返回只包含媒体服务器配置的操作 context。
+func mediaServerTestContext(servers ...*config.MediaServerConfig) context.Context {
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{MediaServers: servers}}})
+}
+
+// generateTestCertificatePair 生成测试用自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
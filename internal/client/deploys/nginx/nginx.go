@@ -6,7 +6,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"time"
 
 	"github.com/https-cert/deploy/internal/client/deploys/shared"
 	"github.com/https-cert/deploy/pkg/logger"
@@ -29,7 +28,8 @@ func DeployAndReloadWithContext(ctx context.Context, sourceDir, nginxPath, folde
 
 // deployWithContext 执行 Nginx 目录发布，并可选执行发布后校验与 reload。
 func deployWithContext(ctx context.Context, sourceDir, nginxPath, folderName, safeDomain string, reload bool) error {
-	if err := shared.ValidateCertificateFiles(sourceDir, safeDomain); err != nil {
+	// 归档可以在 secondary/ 中携带第二组证书，两组证书随目录一起原子发布。
+	if err := shared.ValidateCertificateFileSet(sourceDir, safeDomain); err != nil {
 		return err
 	}
 
@@ -77,6 +77,12 @@ func GenerateNginxSSLConfig(nginxPath, folderName, safeDomain string) error {
 	// 证书文件路径
 	certPath := filepath.Join(certDir, "cert.pem")
 	keyPath := filepath.Join(certDir, "privateKey.key")
+	// 目录中存在第二组证书时同时列出，Nginx 按客户端支持的算法选择 RSA 或 ECDSA 证书。
+	secondaryConfig := ""
+	if shared.HasSecondaryCertificate(certDir) {
+		secondaryDir := filepath.Join(certDir, shared.SecondaryCertificateDir)
+		secondaryConfig = fmt.Sprintf("ssl_certificate %s;\nssl_certificate_key %s;\n", filepath.Join(secondaryDir, "cert.pem"), filepath.Join(secondaryDir, "privateKey.key"))
+	}
 
 	// 生成配置内容
 	configContent := fmt.Sprintf(`# SSL 证书配置 - %s
@@ -85,7 +91,7 @@ func GenerateNginxSSLConfig(nginxPath, folderName, safeDomain string) error {
 
 ssl_certificate %s;
 ssl_certificate_key %s;
-
+%s
 # SSL 协议和加密套件（推荐配置）
 ssl_protocols TLSv1.2 TLSv1.3;
 ssl_ciphers ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-AES256-GCM-SHA384:ECDHE-RSA-AES256-GCM-SHA384:ECDHE-ECDSA-CHACHA20-POLY1305:ECDHE-RSA-CHACHA20-POLY1305:DHE-RSA-AES128-GCM-SHA256:DHE-RSA-AES256-GCM-SHA384;
@@ -95,7 +101,7 @@ ssl_prefer_server_ciphers off;
 ssl_session_cache shared:SSL:10m;
 ssl_session_timeout 1d;
 ssl_session_tickets off;
-`, safeDomain, configFile, certPath, keyPath)
+`, safeDomain, configFile, certPath, keyPath, secondaryConfig)
 
 	// 写入配置文件
 	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
@@ -109,7 +115,7 @@ ssl_session_tickets off;
 
 // IsNginxAvailable 检查nginx是否可用
 func IsNginxAvailable() bool {
-	_, err := exec.LookPath("nginx")
+	_, err := exec.LookPath(nginxCommand)
 	return err == nil
 }
 
@@ -120,10 +126,10 @@ func TestNginxConfig() error {
 
 // TestNginxConfigWithContext 使用调用方上下文测试 Nginx 配置。
 func TestNginxConfigWithContext(parent context.Context) error {
-	ctx, cancel := context.WithTimeout(parent, 10*time.Second)
+	ctx, cancel := context.WithTimeout(parent, nginxCommandTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "nginx", "-t")
+	cmd := exec.CommandContext(ctx, nginxCommand, "-t")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%w\n%s", err, string(output))
@@ -139,10 +145,10 @@ func ReloadNginx() error {
 
 // ReloadNginxWithContext 使用调用方上下文重新加载 Nginx。
 func ReloadNginxWithContext(parent context.Context) error {
-	ctx, cancel := context.WithTimeout(parent, 10*time.Second)
+	ctx, cancel := context.WithTimeout(parent, nginxCommandTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "nginx", "-s", "reload")
+	cmd := exec.CommandContext(ctx, nginxCommand, "-s", "reload")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("%w\n%s", err, string(output))
@@ -0,0 +1,187 @@
+package nginx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// nginxDumpFilePrefix 是 nginx -T 在每个配置文件内容前输出的标记行前缀。
+const nginxDumpFilePrefix = "# configuration file "
+
+// nginxToken 是配置文件中的一个词法单元及其字节范围。
+type nginxToken struct {
+	Value string // Value 是去掉引号后的值。
+	Start int    // Start 是词法单元在文件中的起始偏移。
+	End   int    // End 是词法单元在文件中的结束偏移（不含）。
+	Quote bool   // Quote 表示值来自引号字符串，不能作为 ; { } 使用。
+}
+
+// nginxDirective 描述 server 块中的一条简单指令。
+type nginxDirective struct {
+	Args  []string // Args 是指令参数。
+	Start int      // Start 是指令名的起始偏移。
+	End   int      // End 是结尾分号之后的偏移。
+}
+
+// nginxServerBlock 描述配置文件中的一个顶层 server 块。
+type nginxServerBlock struct {
+	Open         int              // Open 是左花括号之后的偏移。
+	Close        int              // Close 是右花括号的偏移。
+	ServerNames  []string         // ServerNames 是 server_name 指令的全部参数。
+	Listens      [][]string       // Listens 是每条 listen 指令的参数。
+	Certificates []nginxDirective // Certificates 是 ssl_certificate 指令。
+	Keys         []nginxDirective // Keys 是 ssl_certificate_key 指令。
+}
+
+// splitNginxDump 把 nginx -T 的输出拆分为配置文件路径列表，按首次出现的顺序返回。
+func splitNginxDump(output string) []string {
+	files := make([]string, 0)
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, nginxDumpFilePrefix) || !strings.HasSuffix(line, ":") {
+			continue
+		}
+		file := strings.TrimSuffix(strings.TrimPrefix(line, nginxDumpFilePrefix), ":")
+		if _, exists := seen[file]; exists || file == "" {
+			continue
+		}
+		seen[file] = struct{}{}
+		files = append(files, file)
+	}
+	return files
+}
+
+// tokenizeNginxConfig 按 Nginx 语法切分配置内容，跳过注释并保留引号字符串。
+func tokenizeNginxConfig(content string) ([]nginxToken, error) {
+	tokens := make([]nginxToken, 0)
+	for index := 0; index < len(content); {
+		character := content[index]
+		switch {
+		case character == ' ' || character == '\t' || character == '\r' || character == '\n':
+			index++
+		case character == '#':
+			for index < len(content) && content[index] != '\n' {
+				index++
+			}
+		case character == ';' || character == '{' || character == '}':
+			tokens = append(tokens, nginxToken{Value: string(character), Start: index, End: index + 1})
+			index++
+		case character == '"' || character == '\'':
+			start := index
+			var value strings.Builder
+			index++
+			for index < len(content) && content[index] != character {
+				if content[index] == '\\' && index+1 < len(content) {
+					index++
+				}
+				value.WriteByte(content[index])
+				index++
+			}
+			if index >= len(content) {
+				return nil, fmt.Errorf("第 %d 字节处的引号未闭合", start)
+			}
+			index++
+			tokens = append(tokens, nginxToken{Value: value.String(), Start: start, End: index, Quote: true})
+		default:
+			start := index
+			for index < len(content) && !strings.ContainsRune(" \t\r\n;{}#\"'", rune(content[index])) {
+				if content[index] == '\\' && index+1 < len(content) {
+					index++
+				}
+				index++
+			}
+			// ${var} 形式的变量中的花括号属于同一个词法单元。
+			for index < len(content) && content[index] == '{' && index > start && content[index-1] == '$' {
+				for index < len(content) && content[index] != '}' {
+					index++
+				}
+				for index < len(content) && !strings.ContainsRune(" \t\r\n;{}#\"'", rune(content[index])) {
+					index++
+				}
+			}
+			tokens = append(tokens, nginxToken{Value: content[start:index], Start: start, End: index})
+		}
+	}
+	return tokens, nil
+}
+
+// parseNginxServerBlocks 解析配置文件中不嵌套在其他 server 块内的 server 块及其直接子指令。
+func parseNginxServerBlocks(content string) ([]nginxServerBlock, error) {
+	tokens, err := tokenizeNginxConfig(content)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]nginxServerBlock, 0)
+	var stack []string
+	serverDepth := -1
+	var current *nginxServerBlock
+	var statement []nginxToken
+	for _, token := range tokens {
+		if token.Quote || (token.Value != ";" && token.Value != "{" && token.Value != "}") {
+			statement = append(statement, token)
+			continue
+		}
+		switch token.Value {
+		case "{":
+			if len(statement) == 0 {
+				return nil, fmt.Errorf("第 %d 字节处的块缺少名称", token.Start)
+			}
+			name := statement[0].Value
+			if name == "server" && serverDepth < 0 && len(statement) == 1 {
+				serverDepth = len(stack)
+				blocks = append(blocks, nginxServerBlock{Open: token.End})
+				current = &blocks[len(blocks)-1]
+			}
+			stack = append(stack, name)
+		case "}":
+			if len(statement) > 0 {
+				return nil, fmt.Errorf("第 %d 字节处的指令缺少分号", statement[0].Start)
+			}
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("第 %d 字节处有多余的右花括号", token.Start)
+			}
+			stack = stack[:len(stack)-1]
+			if serverDepth == len(stack) {
+				current.Close = token.Start
+				current = nil
+				serverDepth = -1
+			}
+		case ";":
+			if len(statement) == 0 {
+				break
+			}
+			if current != nil && len(stack) == serverDepth+1 {
+				collectNginxServerDirective(current, statement, token.End)
+			}
+		}
+		statement = statement[:0]
+	}
+	if len(stack) > 0 || len(statement) > 0 {
+		return nil, errors.New("配置文件在块或指令结束前截断")
+	}
+	return blocks, nil
+}
+
+// collectNginxServerDirective 记录 server 块中与证书识别和替换有关的指令。
+func collectNginxServerDirective(block *nginxServerBlock, statement []nginxToken, end int) {
+	args := make([]string, 0, len(statement)-1)
+	for _, token := range statement[1:] {
+		args = append(args, token.Value)
+	}
+	directive := nginxDirective{Args: args, Start: statement[0].Start, End: end}
+	switch statement[0].Value {
+	case "server_name":
+		block.ServerNames = append(block.ServerNames, args...)
+	case "listen":
+		block.Listens = append(block.Listens, args)
+	case "ssl_certificate":
+		block.Certificates = append(block.Certificates, directive)
+	case "ssl_certificate_key":
+		block.Keys = append(block.Keys, directive)
+	}
+}
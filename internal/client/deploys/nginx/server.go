@@ -0,0 +1,479 @@
+package nginx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	nginxServerTargetPrefix = "nginx-server-"
+	nginxBackupDir          = ".anssl-backup"
+	nginxCommandTimeout     = 10 * time.Second
+	// NginxServerStatusReady 表示 server 块已声明 HTTPS 监听，可以直接替换证书。
+	NginxServerStatusReady = "Ready"
+)
+
+var (
+	// nginxCommand 允许测试替换 nginx 可执行文件，生产环境始终从 PATH 查找。
+	nginxCommand = "nginx"
+	// nginxConfigLock 串行化配置文件改写，避免两个域名同时部署时互相覆盖备份和校验结果。
+	nginxConfigLock sync.Mutex
+)
+
+// NginxServerResource 是可以安全上报到 anSSL 后端的 Nginx server 块资源。
+type NginxServerResource struct {
+	TargetRef string   // TargetRef 是客户端根据配置文件、server_name 和监听地址生成的不透明稳定引用。
+	Label     string   // Label 是首个 server_name，未设置时为配置文件名。
+	Domain    string   // Domain 是首个可用于证书匹配的域名。
+	Domains   []string // Domains 是 server_name 中的全部精确域名和通配符域名。
+	File      string   // File 是 server 块所在的配置文件。
+	Status    string   // Status 是 server 块状态。
+}
+
+// nginxServerRecord 在 deploy 内部关联脱敏资源和 server 块位置。
+type nginxServerRecord struct {
+	Resource  NginxServerResource // Resource 是可以上报的脱敏资源。
+	Block     nginxServerBlock    // Block 是 server 块在配置文件中的位置。
+	Ambiguous bool                // Ambiguous 表示同一文件中有多个 server 块生成相同引用。
+}
+
+// IsNginxServerConfiguredWithContext 从 context 快照判断是否配置了 Nginx 证书目录。
+func IsNginxServerConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && configuration.SSL.NginxPath != ""
+}
+
+// DiscoverNginxServerResources 通过 nginx -T 列出全部声明 HTTPS 监听的 server 块。
+func DiscoverNginxServerResources(ctx context.Context) ([]NginxServerResource, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	files, err := dumpNginxConfigFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]NginxServerResource, 0)
+	for _, file := range files {
+		records, err := loadNginxServerRecords(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			resources = append(resources, record.Resource)
+		}
+	}
+	return resources, nil
+}
+
+// TestNginxServerConnection 确认 server 块仍可定位、配置文件和证书目录可写，且当前配置可以通过 nginx -t。
+func TestNginxServerConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	record, err := findNginxServer(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(record.Resource.File, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("nginx 配置文件不可写: %w", err)
+	}
+	file.Close()
+	nginxPath := shared.ConfigurationFromContext(ctx).SSL.NginxPath
+	if err := os.MkdirAll(nginxPath, 0755); err != nil {
+		return fmt.Errorf("创建SSL目录失败: %w", err)
+	}
+	probe, err := os.CreateTemp(nginxPath, ".anssl-probe-*")
+	if err != nil {
+		return fmt.Errorf("nginx 证书目录不可写: %w", err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return TestNginxConfigWithContext(ctx)
+}
+
+// DeployCertificateToNginxServer 发布证书到 nginxPath/<域名>/，原位改写所选 server 块的证书指令，校验并 reload，失败时恢复配置和旧证书。
+func DeployCertificateToNginxServer(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	_, safeDomain, err := shared.NormalizeDeploymentDomain(domain)
+	if err != nil {
+		return err
+	}
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || configuration.SSL.NginxPath == "" {
+		return errors.New("未配置 Nginx SSL 目录 (ssl.nginxPath)")
+	}
+	nginxPath := configuration.SSL.NginxPath
+
+	nginxConfigLock.Lock()
+	defer nginxConfigLock.Unlock()
+	record, err := findNginxServer(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+
+	stagingDir, err := os.MkdirTemp("", "anssl-nginx-*")
+	if err != nil {
+		return fmt.Errorf("创建 Nginx 临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	if err := os.WriteFile(filepath.Join(stagingDir, "cert.pem"), []byte(certificatePEM), 0o644); err != nil {
+		return fmt.Errorf("写入证书文件失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(stagingDir, "privateKey.key"), []byte(privateKeyPEM), 0o600); err != nil {
+		return fmt.Errorf("写入私钥文件失败: %w", err)
+	}
+	if err := os.MkdirAll(nginxPath, 0755); err != nil {
+		return fmt.Errorf("创建SSL目录失败: %w", err)
+	}
+	targetDir, err := shared.SafeJoinUnderBase(nginxPath, safeDomain)
+	if err != nil {
+		return err
+	}
+
+	return shared.PublishDirectoryWithValidationContext(ctx, stagingDir, targetDir, func() error {
+		// 同时生成 include 片段，已手动引用片段的其他 server 块继续生效。
+		if err := GenerateNginxSSLConfig(nginxPath, safeDomain, safeDomain); err != nil {
+			return fmt.Errorf("生成Nginx SSL配置失败: %w", err)
+		}
+		return rewriteNginxServerBlock(ctx, record, nginxPath, filepath.Join(targetDir, "cert.pem"), filepath.Join(targetDir, "privateKey.key"))
+	})
+}
+
+// rewriteNginxServerBlock 备份并改写配置文件中的证书指令，nginx -t 或 reload 失败时恢复原文件。
+func rewriteNginxServerBlock(ctx context.Context, record *nginxServerRecord, nginxPath, certPath, keyPath string) error {
+	// sites-enabled 中的配置通常是符号链接，必须改写链接指向的文件而不是替换链接本身。
+	file, err := filepath.EvalSymlinks(record.Resource.File)
+	if err != nil {
+		return fmt.Errorf("解析 Nginx 配置文件路径失败: %w", err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("读取 Nginx 配置文件失败: %w", err)
+	}
+	original, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("读取 Nginx 配置文件失败: %w", err)
+	}
+	// 定位后文件可能被其他进程修改，按当前内容重新定位 server 块。
+	records, err := parseNginxServerRecords(record.Resource.File, string(original))
+	if err != nil {
+		return err
+	}
+	block, err := selectNginxServerRecord(records, record.Resource.TargetRef)
+	if err != nil {
+		return err
+	}
+	updated, err := replaceNginxCertificateDirectives(string(original), block.Block, certPath, keyPath)
+	if err != nil {
+		return err
+	}
+	if updated == string(original) {
+		logger.Info("Nginx server 块已指向证书目录，仅重新加载", "file", file, "server", record.Resource.Label)
+	} else {
+		if err := backupNginxConfigFile(nginxPath, file, original); err != nil {
+			return fmt.Errorf("备份 Nginx 配置文件失败: %w", err)
+		}
+		if err := replaceNginxConfigFile(file, []byte(updated), info); err != nil {
+			return fmt.Errorf("写入 Nginx 配置文件失败: %w", err)
+		}
+	}
+	restore := func(cause error) error {
+		if updated == string(original) {
+			return cause
+		}
+		if err := replaceNginxConfigFile(file, original, info); err != nil {
+			return errors.Join(cause, fmt.Errorf("恢复 Nginx 配置文件失败: %w", err))
+		}
+		return cause
+	}
+	if err := TestNginxConfigWithContext(ctx); err != nil {
+		return restore(fmt.Errorf("nginx配置测试失败: %w", err))
+	}
+	if err := ReloadNginxWithContext(ctx); err != nil {
+		return restore(fmt.Errorf("nginx重新加载失败: %w", err))
+	}
+	logger.Info("Nginx server 块证书已更新", "file", file, "server", record.Resource.Label)
+	return nil
+}
+
+// replaceNginxCertificateDirectives 把 server 块的证书指令改为新路径，块内没有证书指令时在块首插入。
+// 块内有多组证书指令（例如 RSA 与 ECDSA 双证书）时只改写已指向托管文件的一组，其余组原样保留；没有任何一组指向托管文件时拒绝改写。
+func replaceNginxCertificateDirectives(content string, block nginxServerBlock, certPath, keyPath string) (string, error) {
+	certDirective := "ssl_certificate " + quoteNginxValue(certPath) + ";"
+	keyDirective := "ssl_certificate_key " + quoteNginxValue(keyPath) + ";"
+	if len(block.Certificates) == 0 && len(block.Keys) == 0 {
+		indent := nginxBlockIndent(content, block)
+		return content[:block.Open] + "\n" + indent + certDirective + "\n" + indent + keyDirective + content[block.Open:], nil
+	}
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	edits := make([]edit, 0, 2)
+	switch {
+	case len(block.Certificates) <= 1 && len(block.Keys) <= 1:
+		if len(block.Certificates) == 1 {
+			edits = append(edits, edit{block.Certificates[0].Start, block.Certificates[0].End, certDirective})
+		}
+		if len(block.Keys) == 1 {
+			edits = append(edits, edit{block.Keys[0].Start, block.Keys[0].End, keyDirective})
+		}
+		// 只有其中一种指令时，把缺少的指令放在已有指令之后。
+		if len(block.Certificates) == 0 {
+			edits = append(edits, edit{block.Keys[0].End, block.Keys[0].End, " " + certDirective})
+		}
+		if len(block.Keys) == 0 {
+			edits = append(edits, edit{block.Certificates[0].End, block.Certificates[0].End, " " + keyDirective})
+		}
+	default:
+		index := managedNginxCertificatePair(block, certPath, keyPath)
+		if index < 0 {
+			return "", fmt.Errorf("server 块包含 %d 组 ssl_certificate 指令且没有一组指向 %s，请先将需要托管的一组改为该路径", len(block.Certificates), certPath)
+		}
+		edits = append(edits,
+			edit{block.Certificates[index].Start, block.Certificates[index].End, certDirective},
+			edit{block.Keys[index].Start, block.Keys[index].End, keyDirective},
+		)
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, change := range edits {
+		content = content[:change.start] + change.text + content[change.end:]
+	}
+	return content, nil
+}
+
+// managedNginxCertificatePair 按出现顺序配对证书和私钥指令，返回证书或私钥已指向托管文件的那一组，找不到或配对不完整时返回 -1。
+func managedNginxCertificatePair(block nginxServerBlock, certPath, keyPath string) int {
+	if len(block.Certificates) != len(block.Keys) {
+		return -1
+	}
+	for index := range block.Certificates {
+		if nginxDirectiveValue(block.Certificates[index]) == certPath || nginxDirectiveValue(block.Keys[index]) == keyPath {
+			return index
+		}
+	}
+	return -1
+}
+
+// nginxDirectiveValue 返回单参数指令的值。
+func nginxDirectiveValue(directive nginxDirective) string {
+	if len(directive.Args) != 1 {
+		return ""
+	}
+	return directive.Args[0]
+}
+
+// replaceNginxConfigFile 在同目录写入临时文件后重命名覆盖并沿用原文件权限和属主，写入中途失败不会留下半截配置。
+func replaceNginxConfigFile(file string, content []byte, info os.FileInfo) error {
+	uid, gid, ok := shared.FileOwnership(info)
+	if !ok {
+		uid, gid = -1, -1
+	}
+	return shared.ReplaceFile(file, content, info.Mode().Perm(), uid, gid)
+}
+
+// nginxBlockIndent 返回 server 块首条指令的缩进，块为空时使用四个空格。
+func nginxBlockIndent(content string, block nginxServerBlock) string {
+	body := content[block.Open:block.Close]
+	for _, line := range strings.Split(body, "\n")[1:] {
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != "" {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "    "
+}
+
+// quoteNginxValue 在路径包含空白或 Nginx 特殊字符时使用双引号。
+func quoteNginxValue(value string) string {
+	if !strings.ContainsAny(value, " \t\r\n;{}#\"'\\") {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// findNginxServer 重新执行 nginx -T 定位 targetRef 对应的 server 块。
+func findNginxServer(ctx context.Context, targetRef string) (*nginxServerRecord, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("nginx server 块 targetRef 不能为空")
+	}
+	if !IsNginxServerConfiguredWithContext(ctx) {
+		return nil, errors.New("未配置 Nginx SSL 目录 (ssl.nginxPath)")
+	}
+	files, err := dumpNginxConfigFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		records, err := loadNginxServerRecords(file)
+		if err != nil {
+			return nil, err
+		}
+		if record, err := selectNginxServerRecord(records, targetRef); err == nil {
+			return record, nil
+		} else if len(records) > 0 && !errors.Is(err, errNginxServerNotFound) {
+			return nil, err
+		}
+	}
+	return nil, errNginxServerNotFound
+}
+
+// errNginxServerNotFound 表示 server 块已删除或 server_name、监听地址已修改。
+var errNginxServerNotFound = errors.New("nginx server 块不存在或 server_name、监听地址已修改，请重新配置部署目标")
+
+// selectNginxServerRecord 在单个配置文件的记录中查找 targetRef。
+func selectNginxServerRecord(records []nginxServerRecord, targetRef string) (*nginxServerRecord, error) {
+	for index := range records {
+		if records[index].Resource.TargetRef != targetRef {
+			continue
+		}
+		if records[index].Ambiguous {
+			return nil, errors.New("同一配置文件中有多个 server 块使用相同的 server_name 和监听地址，无法确定部署目标")
+		}
+		record := records[index]
+		return &record, nil
+	}
+	return nil, errNginxServerNotFound
+}
+
+// loadNginxServerRecords 读取并解析单个配置文件。
+func loadNginxServerRecords(file string) ([]nginxServerRecord, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取 Nginx 配置文件失败: %w", err)
+	}
+	return parseNginxServerRecords(file, string(content))
+}
+
+// parseNginxServerRecords 把配置文件中声明 HTTPS 监听的 server 块转换为记录。
+func parseNginxServerRecords(file, content string) ([]nginxServerRecord, error) {
+	blocks, err := parseNginxServerBlocks(content)
+	if err != nil {
+		return nil, fmt.Errorf("解析 Nginx 配置文件 %s 失败: %w", file, err)
+	}
+	records := make([]nginxServerRecord, 0, len(blocks))
+	refs := make(map[string]int, len(blocks))
+	for _, block := range blocks {
+		listens := nginxSSLListenAddresses(block.Listens)
+		if len(listens) == 0 {
+			continue
+		}
+		domains := nginxServerDomains(block.ServerNames)
+		targetRef := buildNginxServerTargetRef(file, block.ServerNames, listens)
+		if index, exists := refs[targetRef]; exists {
+			records[index].Ambiguous = true
+			continue
+		}
+		label := filepath.Base(file)
+		if len(block.ServerNames) > 0 && block.ServerNames[0] != "" {
+			label = block.ServerNames[0]
+		}
+		resource := NginxServerResource{TargetRef: targetRef, Label: label, Domains: domains, File: file, Status: NginxServerStatusReady}
+		if len(domains) > 0 {
+			resource.Domain = domains[0]
+		}
+		refs[targetRef] = len(records)
+		records = append(records, nginxServerRecord{Resource: resource, Block: block})
+	}
+	return records, nil
+}
+
+// nginxSSLListenAddresses 返回带 ssl 或 quic 参数的监听地址，已排序。
+func nginxSSLListenAddresses(listens [][]string) []string {
+	addresses := make([]string, 0)
+	for _, listen := range listens {
+		if len(listen) == 0 {
+			continue
+		}
+		for _, parameter := range listen[1:] {
+			if parameter == "ssl" || parameter == "quic" {
+				addresses = append(addresses, listen[0]+" "+parameter)
+				break
+			}
+		}
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// nginxServerDomains 从 server_name 中提取可用于证书匹配的域名，忽略正则、占位符和变量。
+func nginxServerDomains(serverNames []string) []string {
+	seen := make(map[string]struct{}, len(serverNames))
+	domains := make([]string, 0, len(serverNames))
+	for _, name := range serverNames {
+		name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+		// .example.com 同时匹配 example.com 和 *.example.com，这里按主域名上报。
+		name = strings.TrimPrefix(name, ".")
+		if name == "" || name == "_" || strings.HasPrefix(name, "~") || strings.Contains(name, "$") || strings.HasSuffix(name, ".*") {
+			continue
+		}
+		if _, exists := seen[name]; exists {
+			continue
+		}
+		seen[name] = struct{}{}
+		domains = append(domains, name)
+	}
+	return domains
+}
+
+// buildNginxServerTargetRef 根据配置文件、server_name 和 HTTPS 监听地址生成稳定的不透明引用。
+func buildNginxServerTargetRef(file string, serverNames, listens []string) string {
+	names := append([]string(nil), serverNames...)
+	sort.Strings(names)
+	identity := strings.Join([]string{
+		"ansslCli",
+		"DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_SERVER_CERT",
+		filepath.Clean(file),
+		strings.Join(names, ","),
+		strings.Join(listens, ","),
+	}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return nginxServerTargetPrefix + hex.EncodeToString(digest[:12])
+}
+
+// dumpNginxConfigFiles 执行 nginx -T 并返回其加载的全部配置文件。
+func dumpNginxConfigFiles(parent context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(parent, nginxCommandTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, nginxCommand, "-T").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("执行 nginx -T 失败: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	files := splitNginxDump(string(output))
+	if len(files) == 0 {
+		return nil, errors.New("nginx -T 未输出任何配置文件")
+	}
+	return files, nil
+}
+
+// backupNginxConfigFile 把改写前的配置保存到 nginxPath/.anssl-backup，避免备份文件被 include 通配符加载。
+func backupNginxConfigFile(nginxPath, file string, content []byte) error {
+	backupDir := filepath.Join(nginxPath, nginxBackupDir)
+	if err := os.MkdirAll(backupDir, 0o700); err != nil {
+		return err
+	}
+	name := strings.ReplaceAll(strings.TrimPrefix(filepath.ToSlash(filepath.Clean(file)), "/"), "/", "_")
+	return os.WriteFile(filepath.Join(backupDir, name), content, 0o600)
+}
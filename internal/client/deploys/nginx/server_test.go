@@ -0,0 +1,233 @@
+package nginx
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+const testNginxSiteConfig = `# 站点配置
+server {
+    listen 80;
+    server_name example.com;
+    return 301 https://$host$request_uri;
+}
+
+server {
+    listen 443 ssl;
+    listen [::]:443 ssl;
+    http2 on;
+    server_name example.com www.example.com;
+    ssl_certificate /old/rsa.pem; # 旧 RSA 证书
+    ssl_certificate_key /old/rsa.key;
+    ssl_certificate "/old/ecc cert.pem";
+    ssl_certificate_key /old/ecc.key;
+    location / {
+        add_header X-Set "${scheme}{}";
+        proxy_pass http://127.0.0.1:8080;
+    }
+}
+`
+
+// TestDeployCertificateToNginxServerRewritesDirectivesInPlace 验证发现 HTTPS server 块、改写符号链接指向的配置、保留备份并执行校验和 reload；
+// 双证书 server 块只改写指向托管文件的一组，没有托管组时拒绝改写。
+func TestDeployCertificateToNginxServerRewritesDirectivesInPlace(t *testing.T) {
+	root := t.TempDir()
+	available := filepath.Join(root, "sites-available", "example")
+	enabled := filepath.Join(root, "sites-enabled", "example")
+	writeTestFile(t, available, testNginxSiteConfig)
+	if err := os.MkdirAll(filepath.Dir(enabled), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.Symlink(available, enabled); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	stateDir := installFakeNginx(t, enabled)
+	nginxPath := filepath.Join(root, "ssl")
+	ctx := nginxTestContext(nginxPath)
+
+	resources, err := DiscoverNginxServerResources(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverNginxServerResources: %v", err)
+	}
+	if len(resources) != 1 || resources[0].Label != "example.com" || strings.Join(resources[0].Domains, ",") != "example.com,www.example.com" || !strings.HasPrefix(resources[0].TargetRef, nginxServerTargetPrefix) {
+		t.Fatalf("发现的 server 块不匹配: %+v", resources)
+	}
+	if err := TestNginxServerConnection(ctx, resources[0].TargetRef); err != nil {
+		t.Fatalf("TestNginxServerConnection: %v", err)
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "example.com")
+	err = DeployCertificateToNginxServer(ctx, resources[0].TargetRef, "example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "2 组 ssl_certificate") {
+		t.Fatalf("没有托管组的双证书 server 块应拒绝改写: %v", err)
+	}
+	if readTestFile(t, available) != testNginxSiteConfig {
+		t.Fatal("拒绝改写时不应修改配置")
+	}
+
+	// 运维把 RSA 一组指向托管证书后，只改写这一组，ECDSA 一组原样保留。
+	certPath := filepath.Join(nginxPath, "example.com", "cert.pem")
+	keyPath := filepath.Join(nginxPath, "example.com", "privateKey.key")
+	managedConfig := strings.Replace(testNginxSiteConfig, "/old/rsa.pem", certPath, 1)
+	writeTestFile(t, available, managedConfig)
+	if err := DeployCertificateToNginxServer(ctx, resources[0].TargetRef, "example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToNginxServer: %v", err)
+	}
+	if info, err := os.Lstat(enabled); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatal("sites-enabled 中的符号链接不应被替换")
+	}
+	updated := readTestFile(t, available)
+	if !strings.Contains(updated, "    ssl_certificate "+certPath+"; # 旧 RSA 证书\n    ssl_certificate_key "+keyPath+";\n    ssl_certificate \"/old/ecc cert.pem\";\n    ssl_certificate_key /old/ecc.key;\n    location / {") || strings.Contains(updated, "/old/rsa") {
+		t.Fatalf("证书指令未按预期替换:\n%s", updated)
+	}
+	if readTestFile(t, certPath) != certificatePEM {
+		t.Fatal("证书文件未发布")
+	}
+	backup := filepath.Join(nginxPath, nginxBackupDir, strings.ReplaceAll(strings.TrimPrefix(filepath.ToSlash(available), "/"), "/", "_"))
+	if readTestFile(t, backup) != managedConfig {
+		t.Fatal("应保留改写前的配置备份")
+	}
+	if readTestFile(t, filepath.Join(stateDir, "reloads")) != "reload\n" {
+		t.Fatal("应执行一次 nginx -s reload")
+	}
+
+	// 改写后 targetRef 保持不变，再次部署时配置已指向证书目录，只重新加载。
+	resources, err = DiscoverNginxServerResources(ctx)
+	if err != nil || len(resources) != 1 {
+		t.Fatalf("改写后重新发现失败: %+v, %v", resources, err)
+	}
+	if err := DeployCertificateToNginxServer(ctx, resources[0].TargetRef, "example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("重复部署失败: %v", err)
+	}
+	if readTestFile(t, available) != updated {
+		t.Fatal("重复部署不应再次改写配置")
+	}
+}
+
+// TestDeployCertificateToNginxServerRollsBackWhenConfigTestFails 验证缺少证书指令时在块首插入，nginx -t 失败时恢复配置和证书目录。
+func TestDeployCertificateToNginxServerRollsBackWhenConfigTestFails(t *testing.T) {
+	root := t.TempDir()
+	site := filepath.Join(root, "conf.d", "api.conf")
+	original := "server {\n\tlisten 443 ssl;\n\tserver_name api.example.com;\n}\n"
+	writeTestFile(t, site, original)
+	stateDir := installFakeNginx(t, site)
+	nginxPath := filepath.Join(root, "ssl")
+	ctx := nginxTestContext(nginxPath)
+
+	blocks, err := parseNginxServerBlocks(original)
+	if err != nil || len(blocks) != 1 {
+		t.Fatalf("parseNginxServerBlocks: %+v, %v", blocks, err)
+	}
+	inserted, err := replaceNginxCertificateDirectives(original, blocks[0], "/ssl/a b/cert.pem", "/ssl/key.pem")
+	if err != nil || inserted != "server {\n\tssl_certificate \"/ssl/a b/cert.pem\";\n\tssl_certificate_key /ssl/key.pem;\n\tlisten 443 ssl;\n\tserver_name api.example.com;\n}\n" {
+		t.Fatalf("缺少证书指令时应在块首插入:\n%s", inserted)
+	}
+
+	resources, err := DiscoverNginxServerResources(ctx)
+	if err != nil || len(resources) != 1 {
+		t.Fatalf("DiscoverNginxServerResources: %+v, %v", resources, err)
+	}
+	writeTestFile(t, filepath.Join(stateDir, "fail"), "")
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "api.example.com")
+	err = DeployCertificateToNginxServer(ctx, resources[0].TargetRef, "api.example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "nginx配置测试失败") {
+		t.Fatalf("nginx -t 失败时应返回错误: %v", err)
+	}
+	if readTestFile(t, site) != original {
+		t.Fatal("校验失败后应恢复原配置")
+	}
+	if _, err := os.Stat(filepath.Join(nginxPath, "api.example.com")); !os.IsNotExist(err) {
+		t.Fatalf("校验失败后不应保留新证书目录: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, "reloads")); !os.IsNotExist(err) {
+		t.Fatal("校验失败后不应 reload")
+	}
+}
+
+// installFakeNginx 安装模拟 nginx 命令：-T 输出指定配置文件，-t 在 fail 文件存在时失败，-s reload 记录调用。
+func installFakeNginx(t *testing.T, files ...string) string {
+	t.Helper()
+	stateDir := t.TempDir()
+	script := "#!/bin/sh\ncase \"$1\" in\n" +
+		"-T)\n"
+	for _, file := range files {
+		script += "\techo '" + nginxDumpFilePrefix + file + ":'\n\tcat '" + file + "'\n\techo\n"
+	}
+	script += "\t;;\n" +
+		"-t)\n\tif [ -f '" + stateDir + "/fail' ]; then echo 'nginx: [emerg] test failed'; exit 1; fi\n\t;;\n" +
+		"-s)\n\techo \"$2\" >> '" + stateDir + "/reloads'\n\t;;\n" +
+		"esac\n"
+	command := filepath.Join(stateDir, "nginx")
+	if err := os.WriteFile(command, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake nginx: %v", err)
+	}
+	original := nginxCommand
+	nginxCommand = command
+	t.Cleanup(func() { nginxCommand = original })
+	return stateDir
+}
+
+// nginxTestContext 返回只包含 Nginx 证书目录配置的操作 context。
+func nginxTestContext(nginxPath string) context.Context {
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{NginxPath: nginxPath}}})
+}
+
+// writeTestFile 创建父目录并写入测试文件。
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// readTestFile 读取测试文件内容。
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(content)
+}
+
+// generateTestCertificatePair 生成 Nginx 测试使用的自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -0,0 +1,234 @@
+package nginxproxymanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// DiscoverNPMProxyHostResources 动态读取全部 Nginx Proxy Manager 代理主机的脱敏目录。
+func DiscoverNPMProxyHostResources(ctx context.Context) ([]NPMProxyHostResource, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	discoveryContext, cancel := context.WithTimeout(ctx, npmDiscoveryTimeout)
+	defer cancel()
+
+	session, err := openNPMSession(discoveryContext)
+	if err != nil {
+		return nil, err
+	}
+	records, err := loadNPMProxyHostRecords(discoveryContext, session)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]NPMProxyHostResource, 0, len(records))
+	for _, record := range records {
+		resource := record.Resource
+		resource.Domains = append([]string(nil), record.Resource.Domains...)
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+// TestNPMProxyHostConnection 只读确认 targetRef 对应代理主机仍存在、已启用且证书接口可访问。
+func TestNPMProxyHostConnection(ctx context.Context, targetRef string) error {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return fmt.Errorf("Nginx Proxy Manager 代理主机 targetRef 不能为空")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	discoveryContext, cancel := context.WithTimeout(ctx, npmDiscoveryTimeout)
+	defer cancel()
+
+	session, err := openNPMSession(discoveryContext)
+	if err != nil {
+		return err
+	}
+	record, err := findNPMProxyHostByTargetRef(discoveryContext, session, targetRef)
+	if err != nil {
+		return err
+	}
+	if record.Resource.Status == npmProxyHostStatusDisabled {
+		return fmt.Errorf("Nginx Proxy Manager 代理主机已禁用")
+	}
+	if _, err := listNPMCertificates(discoveryContext, session); err != nil {
+		return err
+	}
+	return nil
+}
+
+// listNPMProxyHosts 读取全部代理主机。
+func listNPMProxyHosts(ctx context.Context, session *npmSession) ([]npmProxyHost, error) {
+	var hosts []npmProxyHost
+	if err := session.requestJSON(ctx, http.MethodGet, npmProxyHostsPath, nil, &hosts); err != nil {
+		return nil, fmt.Errorf("读取 Nginx Proxy Manager 代理主机列表失败: %w", err)
+	}
+	return hosts, nil
+}
+
+// listNPMCertificates 读取全部证书记录。
+func listNPMCertificates(ctx context.Context, session *npmSession) ([]npmCertificate, error) {
+	var certificates []npmCertificate
+	if err := session.requestJSON(ctx, http.MethodGet, npmCertificatesPath, nil, &certificates); err != nil {
+		return nil, fmt.Errorf("读取 Nginx Proxy Manager 证书列表失败: %w", err)
+	}
+	return certificates, nil
+}
+
+// loadNPMProxyHostRecords 读取全部代理主机并规范化域名，跳过没有可用域名的主机。
+func loadNPMProxyHostRecords(ctx context.Context, session *npmSession) ([]npmProxyHostRecord, error) {
+	hosts, err := listNPMProxyHosts(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]npmProxyHostRecord, 0, len(hosts))
+	for _, host := range hosts {
+		if host.ID == 0 || strings.TrimSpace(host.CreatedOn) == "" {
+			return nil, fmt.Errorf("Nginx Proxy Manager 代理主机缺少生成稳定引用所需的身份字段")
+		}
+		primaryDomain, domains := normalizeNPMDomainNames(host.DomainNames)
+		if len(domains) == 0 {
+			continue
+		}
+		status := npmProxyHostStatusDisabled
+		if host.Enabled {
+			status = npmProxyHostStatusEnabled
+		}
+		records = append(records, npmProxyHostRecord{
+			ID: host.ID,
+			Resource: NPMProxyHostResource{
+				TargetRef: buildNPMProxyHostTargetRef(session.apiURL, host),
+				Label:     primaryDomain,
+				Domain:    primaryDomain,
+				Domains:   domains,
+				Status:    status,
+			},
+		})
+	}
+	return records, nil
+}
+
+// normalizeNPMDomainNames 返回第一个有效域名以及去重排序后的全部域名。
+func normalizeNPMDomainNames(domainNames []string) (string, []string) {
+	primaryDomain := ""
+	domains := make([]string, 0, len(domainNames))
+	seen := make(map[string]struct{}, len(domainNames))
+	for _, raw := range domainNames {
+		domain := normalizeNPMDomain(raw)
+		if domain == "" {
+			continue
+		}
+		if primaryDomain == "" {
+			primaryDomain = domain
+		}
+		if _, exists := seen[domain]; exists {
+			continue
+		}
+		seen[domain] = struct{}{}
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return primaryDomain, domains
+}
+
+// normalizeNPMDomain 将代理主机域名规范化为小写 ASCII 主机名、通配符域名或 IP。
+func normalizeNPMDomain(raw string) string {
+	value := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(raw)), ".")
+	wildcard := strings.HasPrefix(value, "*.")
+	if wildcard {
+		value = strings.TrimPrefix(value, "*.")
+	}
+	if value == "" {
+		return ""
+	}
+	if ip := net.ParseIP(value); ip != nil {
+		if wildcard {
+			return ""
+		}
+		return ip.String()
+	}
+	if strings.ContainsAny(value, "/:*@ ") {
+		return ""
+	}
+	ascii, err := idna.Lookup.ToASCII(value)
+	if err != nil {
+		return ""
+	}
+	normalized := strings.ToLower(strings.TrimSuffix(ascii, "."))
+	if wildcard && normalized != "" {
+		return "*." + normalized
+	}
+	return normalized
+}
+
+// buildNPMProxyHostTargetRef 根据实例、代理主机 ID 和创建时间生成稳定的不透明引用。
+func buildNPMProxyHostTargetRef(apiURL string, host npmProxyHost) string {
+	identity := strings.Join([]string{
+		npmResourceProvider,
+		"EXECUTE_BUSINES_ANSSL_CLI_NPM_PROXY_HOST_CERT",
+		normalizeNPMOrigin(apiURL),
+		strconv.FormatUint(host.ID, 10),
+		strings.TrimSpace(host.CreatedOn),
+	}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return npmProxyHostTargetRefPrefix + hex.EncodeToString(digest[:12])
+}
+
+// normalizeNPMOrigin 规范化仅用于本地哈希的管理端来源，不返回或记录该值。
+func normalizeNPMOrigin(apiURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(apiURL))
+	if err != nil {
+		return strings.ToLower(strings.TrimRight(strings.TrimSpace(apiURL), "/"))
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	hostname := strings.ToLower(parsed.Hostname())
+	port := parsed.Port()
+	if (parsed.Scheme == "https" && port == "443") || (parsed.Scheme == "http" && port == "80") {
+		port = ""
+	}
+	if port != "" {
+		parsed.Host = net.JoinHostPort(hostname, port)
+	} else if strings.Contains(hostname, ":") {
+		parsed.Host = "[" + hostname + "]"
+	} else {
+		parsed.Host = hostname
+	}
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return strings.TrimRight(parsed.String(), "/")
+}
+
+// findNPMProxyHostByTargetRef 重新读取代理主机并要求 targetRef 唯一匹配。
+func findNPMProxyHostByTargetRef(ctx context.Context, session *npmSession, targetRef string) (*npmProxyHostRecord, error) {
+	records, err := loadNPMProxyHostRecords(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	var matched *npmProxyHostRecord
+	for index := range records {
+		if records[index].Resource.TargetRef != targetRef {
+			continue
+		}
+		if matched != nil {
+			return nil, fmt.Errorf("Nginx Proxy Manager 代理主机 targetRef 不唯一，请重新配置部署目标")
+		}
+		record := records[index]
+		matched = &record
+	}
+	if matched == nil {
+		return nil, fmt.Errorf("Nginx Proxy Manager 代理主机不存在或已重新创建，请重新配置部署目标")
+	}
+	return matched, nil
+}
@@ -0,0 +1,108 @@
+package nginxproxymanager
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// validateNPMProxyHostCertificate 校验证书、私钥、有效期，并要求至少覆盖代理主机的一个域名。
+func validateNPMProxyHostCertificate(certificatePEM, privateKeyPEM string, domains []string, now time.Time) ([sha256.Size]byte, error) {
+	var emptyFingerprint [sha256.Size]byte
+	keyPair, err := tls.X509KeyPair([]byte(certificatePEM), []byte(privateKeyPEM))
+	if err != nil {
+		return emptyFingerprint, fmt.Errorf("Nginx Proxy Manager 证书和私钥无效: %w", err)
+	}
+	if len(keyPair.Certificate) == 0 {
+		return emptyFingerprint, fmt.Errorf("Nginx Proxy Manager 证书不包含叶证书")
+	}
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return emptyFingerprint, fmt.Errorf("解析 Nginx Proxy Manager 证书失败: %w", err)
+	}
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return emptyFingerprint, fmt.Errorf("Nginx Proxy Manager 证书不在有效期内")
+	}
+	if len(domains) == 0 {
+		return emptyFingerprint, fmt.Errorf("Nginx Proxy Manager 代理主机没有可校验的域名")
+	}
+	for _, domain := range domains {
+		if err := verifyNPMCertificateDomain(leaf, domain); err == nil {
+			return sha256.Sum256(leaf.Raw), nil
+		}
+	}
+	return emptyFingerprint, fmt.Errorf("证书未覆盖 Nginx Proxy Manager 代理主机的任何域名")
+}
+
+// verifyNPMCertificateDomain 校验普通域名/IP，并允许代理主机通配符与证书 SAN 精确匹配。
+func verifyNPMCertificateDomain(certificate *x509.Certificate, domain string) error {
+	if certificate == nil {
+		return fmt.Errorf("证书为空")
+	}
+	normalized := normalizeNPMDomain(domain)
+	if normalized == "" {
+		return fmt.Errorf("代理主机域名无效")
+	}
+	if !strings.HasPrefix(normalized, "*.") {
+		return certificate.VerifyHostname(normalized)
+	}
+	for _, certificateDomain := range certificate.DNSNames {
+		if normalizeNPMDomain(certificateDomain) == normalized {
+			return nil
+		}
+	}
+	return fmt.Errorf("证书 SAN 不包含 %s", normalized)
+}
+
+// splitNPMCertificateChain 把完整证书链拆成叶证书和中间证书，对应 NPM 上传接口的两个文件字段。
+func splitNPMCertificateChain(certificatePEM string) (string, string, error) {
+	var leaf strings.Builder
+	var intermediates strings.Builder
+	data := []byte(certificatePEM)
+	for len(data) > 0 {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		data = rest
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if leaf.Len() == 0 {
+			leaf.Write(pem.EncodeToMemory(block))
+			continue
+		}
+		intermediates.Write(pem.EncodeToMemory(block))
+	}
+	if leaf.Len() == 0 {
+		return "", "", fmt.Errorf("未找到 PEM 叶证书")
+	}
+	return leaf.String(), intermediates.String(), nil
+}
+
+// npmCertificateFingerprint 解析回读证书并计算叶证书 SHA-256 指纹。
+func npmCertificateFingerprint(certificatePEM string) ([sha256.Size]byte, error) {
+	var emptyFingerprint [sha256.Size]byte
+	leafPEM, _, err := splitNPMCertificateChain(certificatePEM)
+	if err != nil {
+		return emptyFingerprint, err
+	}
+	block, _ := pem.Decode([]byte(leafPEM))
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return emptyFingerprint, err
+	}
+	return sha256.Sum256(certificate.Raw), nil
+}
+
+// npmCertificateName 返回代理主机主域名对应的 anSSL 自定义证书名称。
+func npmCertificateName(domain string) string {
+	return npmCertificateNamePrefix + domain
+}
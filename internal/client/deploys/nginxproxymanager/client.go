@@ -0,0 +1,197 @@
+package nginxproxymanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// npmSession 保存一次操作内复用的 NPM 地址、HTTP 客户端和登录令牌。
+type npmSession struct {
+	apiURL string       // apiURL 是去掉末尾斜杠的 NPM 管理端地址。
+	client *http.Client // client 是按实例 TLS 策略创建的 HTTP 客户端。
+	token  string       // token 是 /api/tokens 返回的 Bearer 令牌，只保存在内存中。
+}
+
+// IsNPMConfiguredWithContext 从 context 快照判断 Nginx Proxy Manager 是否已配置。
+func IsNPMConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && configuration.SSL.NginxProxyManager != nil &&
+		strings.TrimSpace(configuration.SSL.NginxProxyManager.URL) != "" &&
+		strings.TrimSpace(configuration.SSL.NginxProxyManager.Email) != "" &&
+		configuration.SSL.NginxProxyManager.Password != ""
+}
+
+// IsNPMErrorRetryable 判断 Nginx Proxy Manager 操作是否适合由后端稍后重试。
+func IsNPMErrorRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var requestError *npmRequestError
+	if errors.As(err, &requestError) {
+		return requestError.Retryable
+	}
+	var networkError net.Error
+	//lint:ignore SA1019 保留历史网络错误的重试分类语义。
+	return errors.As(err, &networkError) && (networkError.Timeout() || networkError.Temporary())
+}
+
+// getNPMConfig 读取并校验当前操作快照中的 Nginx Proxy Manager 配置。
+func getNPMConfig(ctx context.Context) (*config.NginxProxyManagerConfig, string, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || configuration.SSL.NginxProxyManager == nil {
+		return nil, "", fmt.Errorf("未配置 Nginx Proxy Manager (ssl.nginxProxyManager)")
+	}
+	npmConfig := configuration.SSL.NginxProxyManager
+	apiURL := strings.TrimRight(strings.TrimSpace(npmConfig.URL), "/")
+	if apiURL == "" {
+		return nil, "", fmt.Errorf("Nginx Proxy Manager 地址未配置 (ssl.nginxProxyManager.url)")
+	}
+	if strings.TrimSpace(npmConfig.Email) == "" || npmConfig.Password == "" {
+		return nil, "", fmt.Errorf("Nginx Proxy Manager 登录凭据未配置 (ssl.nginxProxyManager.email/password)")
+	}
+	parsedURL, err := url.Parse(apiURL)
+	if err != nil || parsedURL.Host == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return nil, "", fmt.Errorf("Nginx Proxy Manager 地址必须是合法的 HTTP 或 HTTPS 地址")
+	}
+	if parsedURL.User != nil || parsedURL.RawQuery != "" || parsedURL.Fragment != "" {
+		return nil, "", fmt.Errorf("Nginx Proxy Manager 地址不能包含用户凭据、查询参数或片段")
+	}
+	return npmConfig, apiURL, nil
+}
+
+// openNPMSession 使用配置的账号登录 /api/tokens，返回本次操作使用的会话。
+func openNPMSession(ctx context.Context) (*npmSession, error) {
+	npmConfig, apiURL, err := getNPMConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	session := &npmSession{apiURL: apiURL, client: newNPMHTTPClient(npmConfig.InsecureSkipVerify)}
+	requestBody := map[string]string{"identity": strings.TrimSpace(npmConfig.Email), "secret": npmConfig.Password}
+	var token npmTokenResponse
+	if err := session.requestJSON(ctx, http.MethodPost, npmTokenPath, requestBody, &token); err != nil {
+		return nil, fmt.Errorf("登录 Nginx Proxy Manager 失败: %w", err)
+	}
+	if strings.TrimSpace(token.Token) == "" {
+		return nil, fmt.Errorf("Nginx Proxy Manager 登录响应缺少令牌")
+	}
+	session.token = strings.TrimSpace(token.Token)
+	return session, nil
+}
+
+// requestJSON 以 JSON 请求体调用 NPM API。
+func (s *npmSession) requestJSON(ctx context.Context, method, endpoint string, requestBody, responseData any) error {
+	var body io.Reader
+	contentType := ""
+	if requestBody != nil {
+		jsonData, err := json.Marshal(requestBody)
+		if err != nil {
+			return fmt.Errorf("序列化 Nginx Proxy Manager 请求失败: %w", err)
+		}
+		body = bytes.NewReader(jsonData)
+		contentType = "application/json"
+	}
+	return s.request(ctx, method, endpoint, contentType, body, responseData)
+}
+
+// uploadCertificate 以 multipart 文件字段上传证书内容，空内容的字段不发送。
+func (s *npmSession) uploadCertificate(ctx context.Context, certificateID uint64, files map[string]string, responseData any) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if files[name] == "" {
+			continue
+		}
+		part, err := writer.CreateFormFile(name, name+".pem")
+		if err != nil {
+			return fmt.Errorf("创建 Nginx Proxy Manager 上传字段失败: %w", err)
+		}
+		if _, err := io.WriteString(part, files[name]); err != nil {
+			return fmt.Errorf("写入 Nginx Proxy Manager 上传字段失败: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("编码 Nginx Proxy Manager 上传请求失败: %w", err)
+	}
+	return s.request(ctx, http.MethodPost, fmt.Sprintf(npmCertificateUploadPath, certificateID), writer.FormDataContentType(), &body, responseData)
+}
+
+// request 携带 Bearer 令牌调用 NPM API，并限制响应大小和重定向行为。
+func (s *npmSession) request(ctx context.Context, method, endpoint, contentType string, body io.Reader, responseData any) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.apiURL+endpoint, body)
+	if err != nil {
+		return fmt.Errorf("创建 Nginx Proxy Manager 请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return &npmRequestError{Retryable: true, Cause: fmt.Errorf("请求 Nginx Proxy Manager API 失败: %w", err)}
+	}
+	defer resp.Body.Close()
+	responseBody, err := io.ReadAll(io.LimitReader(resp.Body, npmMaxResponseBodySize+1))
+	if err != nil {
+		return &npmRequestError{Retryable: true, Cause: fmt.Errorf("读取 Nginx Proxy Manager 响应失败: %w", err)}
+	}
+	if len(responseBody) > npmMaxResponseBodySize {
+		return &npmRequestError{Retryable: false, Cause: fmt.Errorf("Nginx Proxy Manager 响应体超过最大限制")}
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		var errorResponse npmErrorResponse
+		if json.Unmarshal(responseBody, &errorResponse) == nil && strings.TrimSpace(errorResponse.Error.Message) != "" {
+			return &npmRequestError{Retryable: retryable, Cause: fmt.Errorf("Nginx Proxy Manager API 返回 HTTP %d: %s", resp.StatusCode, strings.TrimSpace(errorResponse.Error.Message))}
+		}
+		return &npmRequestError{Retryable: retryable, Cause: fmt.Errorf("Nginx Proxy Manager API 返回 HTTP %d", resp.StatusCode)}
+	}
+	if responseData == nil || len(bytes.TrimSpace(responseBody)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(responseBody, responseData); err != nil {
+		return &npmRequestError{Retryable: false, Cause: fmt.Errorf("解析 Nginx Proxy Manager 响应失败: %w", err)}
+	}
+	return nil
+}
+
+// newNPMHTTPClient 按配置的 TLS 策略创建客户端，并禁止自动跟随重定向以免令牌发往非预期地址。
+func newNPMHTTPClient(insecureSkipVerify bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: insecureSkipVerify} //nolint:gosec // 仅在用户显式配置后允许自签名管理端证书。
+	return &http.Client{
+		Timeout:   npmRequestTimeout,
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package nginxproxymanager
+
+import "time"
+
+const (
+	npmRequestTimeout           = 30 * time.Second
+	npmDiscoveryTimeout         = 15 * time.Second
+	npmMaxResponseBodySize      = 4 * 1024 * 1024
+	npmTokenPath                = "/api/tokens"
+	npmProxyHostsPath           = "/api/nginx/proxy-hosts"
+	npmProxyHostPath            = "/api/nginx/proxy-hosts/%d"
+	npmCertificatesPath         = "/api/nginx/certificates"
+	npmCertificateUploadPath    = "/api/nginx/certificates/%d/upload"
+	npmProxyHostTargetRefPrefix = "npm-proxy-host-"
+	npmCertificateProviderOther = "other"
+	npmCertificateNamePrefix    = "anssl "
+	npmProxyHostStatusEnabled   = "Enabled"
+	npmProxyHostStatusDisabled  = "Disabled"
+	npmResourceProvider         = "ansslCli"
+)
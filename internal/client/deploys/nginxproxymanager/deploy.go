@@ -0,0 +1,105 @@
+package nginxproxymanager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DeployCertificateToNPMProxyHost 上传或替换 anSSL 自定义证书，把 targetRef 对应代理主机指向该证书并回读确认。
+func DeployCertificateToNPMProxyHost(ctx context.Context, targetRef, certificatePEM, privateKeyPEM string) error {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return fmt.Errorf("Nginx Proxy Manager 代理主机 targetRef 不能为空")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	session, err := openNPMSession(ctx)
+	if err != nil {
+		return err
+	}
+	record, err := findNPMProxyHostByTargetRef(ctx, session, targetRef)
+	if err != nil {
+		return err
+	}
+	if record.Resource.Status == npmProxyHostStatusDisabled {
+		return fmt.Errorf("Nginx Proxy Manager 代理主机已禁用")
+	}
+	expectedFingerprint, err := validateNPMProxyHostCertificate(certificatePEM, privateKeyPEM, record.Resource.Domains, time.Now())
+	if err != nil {
+		return err
+	}
+	leafPEM, intermediatePEM, err := splitNPMCertificateChain(certificatePEM)
+	if err != nil {
+		return err
+	}
+
+	certificateID, err := ensureNPMCustomCertificate(ctx, session, npmCertificateName(record.Resource.Domain))
+	if err != nil {
+		return err
+	}
+	files := map[string]string{
+		"certificate":              leafPEM,
+		"certificate_key":          privateKeyPEM,
+		"intermediate_certificate": intermediatePEM,
+	}
+	var uploaded npmCertificateUploadResult
+	if err := session.uploadCertificate(ctx, certificateID, files, &uploaded); err != nil {
+		return fmt.Errorf("上传 Nginx Proxy Manager 自定义证书失败: %w", err)
+	}
+	actualFingerprint, err := npmCertificateFingerprint(uploaded.Certificate)
+	if err != nil {
+		return fmt.Errorf("解析 Nginx Proxy Manager 回显证书失败: %w", err)
+	}
+	if actualFingerprint != expectedFingerprint {
+		return fmt.Errorf("Nginx Proxy Manager 自定义证书回显指纹不一致")
+	}
+
+	// 即使代理主机已指向同一证书 ID 也要更新一次，NPM 只在保存代理主机时重新生成配置并重载 Nginx。
+	endpoint := fmt.Sprintf(npmProxyHostPath, record.ID)
+	if err := session.requestJSON(ctx, http.MethodPut, endpoint, map[string]uint64{"certificate_id": certificateID}, nil); err != nil {
+		return fmt.Errorf("更新 Nginx Proxy Manager 代理主机证书失败: %w", err)
+	}
+	var updated npmProxyHost
+	if err := session.requestJSON(ctx, http.MethodGet, endpoint, nil, &updated); err != nil {
+		return fmt.Errorf("回读 Nginx Proxy Manager 代理主机失败: %w", err)
+	}
+	if updated.CertificateID != certificateID {
+		return fmt.Errorf("Nginx Proxy Manager 代理主机回读证书 ID 不一致")
+	}
+	return nil
+}
+
+// ensureNPMCustomCertificate 返回同名 anSSL 自定义证书 ID，不存在时新建一条空的自定义证书记录。
+func ensureNPMCustomCertificate(ctx context.Context, session *npmSession, niceName string) (uint64, error) {
+	certificates, err := listNPMCertificates(ctx, session)
+	if err != nil {
+		return 0, err
+	}
+	var matchedID uint64
+	for _, certificate := range certificates {
+		if certificate.Provider != npmCertificateProviderOther || strings.TrimSpace(certificate.NiceName) != niceName {
+			continue
+		}
+		if matchedID == 0 || certificate.ID < matchedID {
+			matchedID = certificate.ID
+		}
+	}
+	if matchedID != 0 {
+		return matchedID, nil
+	}
+
+	requestBody := map[string]string{"provider": npmCertificateProviderOther, "nice_name": niceName}
+	var created npmCertificate
+	if err := session.requestJSON(ctx, http.MethodPost, npmCertificatesPath, requestBody, &created); err != nil {
+		return 0, fmt.Errorf("创建 Nginx Proxy Manager 自定义证书失败: %w", err)
+	}
+	if created.ID == 0 {
+		return 0, fmt.Errorf("Nginx Proxy Manager 创建证书响应缺少 ID")
+	}
+	return created.ID, nil
+}
@@ -0,0 +1,225 @@
+package nginxproxymanager
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// fakeNPMCertificate 是模拟 NPM 中保存的一张证书。
+type fakeNPMCertificate struct {
+	ID           uint64
+	Provider     string
+	NiceName     string
+	Certificate  string
+	Intermediate string
+}
+
+// fakeNPM 模拟 NPM 登录、代理主机和自定义证书接口。
+type fakeNPM struct {
+	mu           sync.Mutex
+	hosts        []map[string]any
+	certificates []*fakeNPMCertificate
+	uploads      int
+	hostUpdates  []string
+}
+
+// ServeHTTP 按路径分发模拟 NPM API 请求。
+func (f *fakeNPM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Method == http.MethodPost && r.URL.Path == npmTokenPath {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["identity"] != "admin@example.com" || body["secret"] != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 401, "message": "Invalid email or password"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"token": "token-1", "expires": "2099-01-01T00:00:00.000Z"})
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer token-1" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == npmProxyHostsPath:
+		json.NewEncoder(w).Encode(f.hosts)
+	case r.Method == http.MethodGet && r.URL.Path == npmCertificatesPath:
+		certificates := make([]map[string]any, 0, len(f.certificates))
+		for _, certificate := range f.certificates {
+			certificates = append(certificates, map[string]any{"id": certificate.ID, "provider": certificate.Provider, "nice_name": certificate.NiceName})
+		}
+		json.NewEncoder(w).Encode(certificates)
+	case r.Method == http.MethodPost && r.URL.Path == npmCertificatesPath:
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		certificate := &fakeNPMCertificate{ID: uint64(len(f.certificates) + 1), Provider: body["provider"], NiceName: body["nice_name"]}
+		f.certificates = append(f.certificates, certificate)
+		json.NewEncoder(w).Encode(map[string]any{"id": certificate.ID, "provider": certificate.Provider, "nice_name": certificate.NiceName})
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/upload"):
+		id, _ := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, npmCertificatesPath+"/"), "/upload"), 10, 64)
+		certificate := f.findCertificate(id)
+		if certificate == nil || certificate.Provider != npmCertificateProviderOther || r.ParseMultipartForm(1<<20) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fields := map[string]string{}
+		for _, name := range []string{"certificate", "certificate_key", "intermediate_certificate"} {
+			if file, _, err := r.FormFile(name); err == nil {
+				content, _ := io.ReadAll(file)
+				fields[name] = string(content)
+			}
+		}
+		f.uploads++
+		certificate.Certificate, certificate.Intermediate = fields["certificate"], fields["intermediate_certificate"]
+		json.NewEncoder(w).Encode(fields)
+	case strings.HasPrefix(r.URL.Path, npmProxyHostsPath+"/"):
+		host := f.findHost(strings.TrimPrefix(r.URL.Path, npmProxyHostsPath+"/"))
+		if host == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPut {
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			host["certificate_id"] = body["certificate_id"]
+			f.hostUpdates = append(f.hostUpdates, strconv.FormatFloat(host["id"].(float64), 'f', -1, 64))
+		}
+		json.NewEncoder(w).Encode(host)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// findCertificate 根据 ID 查找模拟证书。
+func (f *fakeNPM) findCertificate(id uint64) *fakeNPMCertificate {
+	for _, certificate := range f.certificates {
+		if certificate.ID == id {
+			return certificate
+		}
+	}
+	return nil
+}
+
+// findHost 根据路径中的 ID 查找模拟代理主机。
+func (f *fakeNPM) findHost(id string) map[string]any {
+	for _, host := range f.hosts {
+		if strconv.FormatFloat(host["id"].(float64), 'f', -1, 64) == id {
+			return host
+		}
+	}
+	return nil
+}
+
+// TestDeployCertificateToNPMProxyHostUploadsAndRepoints 验证发现代理主机、新建自定义证书、再次部署时原位替换并重新指向代理主机。
+func TestDeployCertificateToNPMProxyHostUploadsAndRepoints(t *testing.T) {
+	npm := &fakeNPM{
+		hosts: []map[string]any{
+			{"id": float64(1), "created_on": "2024-01-01 00:00:00", "domain_names": []string{"Example.com", "www.example.com"}, "certificate_id": float64(0), "enabled": float64(1)},
+			{"id": float64(2), "created_on": "2024-01-02 00:00:00", "domain_names": []string{"off.example.com"}, "certificate_id": float64(0), "enabled": false},
+			{"id": float64(3), "created_on": "2024-01-03 00:00:00", "domain_names": []string{}, "certificate_id": float64(0), "enabled": true},
+		},
+		certificates: []*fakeNPMCertificate{{ID: 1, Provider: "letsencrypt", NiceName: "anssl example.com"}},
+	}
+	server := httptest.NewServer(npm)
+	defer server.Close()
+	ctx := npmTestContext(&config.NginxProxyManagerConfig{URL: server.URL, Email: "admin@example.com", Password: "secret"})
+
+	resources, err := DiscoverNPMProxyHostResources(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverNPMProxyHostResources: %v", err)
+	}
+	if len(resources) != 2 || resources[0].Domain != "example.com" || strings.Join(resources[0].Domains, ",") != "example.com,www.example.com" ||
+		resources[0].Status != npmProxyHostStatusEnabled || resources[1].Status != npmProxyHostStatusDisabled || !strings.HasPrefix(resources[0].TargetRef, npmProxyHostTargetRefPrefix) {
+		t.Fatalf("发现的代理主机不匹配: %+v", resources)
+	}
+	if err := TestNPMProxyHostConnection(ctx, resources[0].TargetRef); err != nil {
+		t.Fatalf("TestNPMProxyHostConnection: %v", err)
+	}
+	if err := TestNPMProxyHostConnection(ctx, resources[1].TargetRef); err == nil {
+		t.Fatal("已禁用代理主机应拒绝连接测试")
+	}
+
+	leafPEM, privateKeyPEM := generateTestCertificatePair(t, "www.example.com")
+	intermediatePEM, _ := generateTestCertificatePair(t, "Test Intermediate")
+	for attempt := 0; attempt < 2; attempt++ {
+		if err := DeployCertificateToNPMProxyHost(ctx, resources[0].TargetRef, leafPEM+intermediatePEM, privateKeyPEM); err != nil {
+			t.Fatalf("DeployCertificateToNPMProxyHost: %v", err)
+		}
+	}
+	if len(npm.certificates) != 2 || npm.certificates[1].Provider != "other" || npm.certificates[1].NiceName != "anssl example.com" {
+		t.Fatalf("应只新建一张 anSSL 自定义证书: %+v", npm.certificates)
+	}
+	if npm.uploads != 2 || npm.certificates[1].Certificate != leafPEM || npm.certificates[1].Intermediate != intermediatePEM {
+		t.Fatal("证书链应拆分为叶证书和中间证书并原位替换")
+	}
+	if strings.Join(npm.hostUpdates, ",") != "1,1" || npm.hosts[0]["certificate_id"] != float64(2) {
+		t.Fatalf("代理主机应每次都重新指向新证书: updates=%v host=%v", npm.hostUpdates, npm.hosts[0])
+	}
+
+	otherPEM, otherKeyPEM := generateTestCertificatePair(t, "other.example.org")
+	if err := DeployCertificateToNPMProxyHost(ctx, resources[0].TargetRef, otherPEM, otherKeyPEM); err == nil {
+		t.Fatal("证书未覆盖代理主机域名时应拒绝部署")
+	}
+}
+
+// TestDiscoverNPMProxyHostResourcesRejectsInvalidLogin 验证登录失败时返回不可重试错误且不暴露资源。
+func TestDiscoverNPMProxyHostResourcesRejectsInvalidLogin(t *testing.T) {
+	server := httptest.NewServer(&fakeNPM{})
+	defer server.Close()
+	ctx := npmTestContext(&config.NginxProxyManagerConfig{URL: server.URL, Email: "admin@example.com", Password: "wrong"})
+
+	resources, err := DiscoverNPMProxyHostResources(ctx)
+	if err == nil || IsNPMErrorRetryable(err) || !strings.Contains(err.Error(), "Invalid email or password") || resources != nil {
+		t.Fatalf("登录失败应返回不可重试错误: resources=%v err=%v", resources, err)
+	}
+}
+
+// npmTestContext 返回只包含 Nginx Proxy Manager 配置的操作 context。
+func npmTestContext(npmConfig *config.NginxProxyManagerConfig) context.Context {
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{NginxProxyManager: npmConfig}}})
+}
+
+// generateTestCertificatePair 生成 Nginx Proxy Manager 测试使用的自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -0,0 +1,93 @@
+package nginxproxymanager
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// NPMProxyHostResource 是可以安全上报到 anSSL 后端的脱敏代理主机资源。
+type NPMProxyHostResource struct {
+	TargetRef string   // TargetRef 是客户端生成的不透明稳定引用。
+	Label     string   // Label 是代理主机的第一个域名。
+	Domain    string   // Domain 是代理主机的第一个规范化域名。
+	Domains   []string // Domains 是代理主机 domain_names 中的全部规范化域名。
+	Status    string   // Status 是安全展示的启用状态，仅用于阻止已禁用代理主机部署。
+}
+
+// npmFlexibleBool 兼容 NPM 旧版返回 0/1、新版返回 true/false 的布尔字段。
+type npmFlexibleBool bool
+
+// UnmarshalJSON 解析布尔值或 0/1 数字。
+func (b *npmFlexibleBool) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true", "1":
+		*b = true
+	case "false", "0", "null":
+		*b = false
+	default:
+		return fmt.Errorf("无法解析 Nginx Proxy Manager 布尔值: %s", data)
+	}
+	return nil
+}
+
+// npmTokenResponse 描述 /api/tokens 登录响应。
+type npmTokenResponse struct {
+	Token string `json:"token"` // Token 是后续请求使用的 Bearer 令牌。
+}
+
+// npmErrorResponse 描述 NPM API 的错误响应。
+type npmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`    // Code 是 NPM 返回的 HTTP 状态码。
+		Message string `json:"message"` // Message 是仅供 deploy 本地日志使用的诊断信息。
+	} `json:"error"`
+}
+
+// npmProxyHost 描述代理主机列表中生成资源和部署所需的字段。
+type npmProxyHost struct {
+	ID            uint64          `json:"id"`             // ID 是仅保留在 deploy 本地的代理主机 ID。
+	CreatedOn     string          `json:"created_on"`     // CreatedOn 用于区分删除后重新创建的代理主机。
+	DomainNames   []string        `json:"domain_names"`   // DomainNames 是代理主机绑定的域名。
+	CertificateID uint64          `json:"certificate_id"` // CertificateID 是当前证书 ID，未启用 SSL 时为 0。
+	Enabled       npmFlexibleBool `json:"enabled"`        // Enabled 表示代理主机是否启用。
+}
+
+// npmCertificate 描述证书列表中用于查找 anSSL 自定义证书的字段。
+type npmCertificate struct {
+	ID       uint64 `json:"id"`        // ID 是 NPM 证书 ID。
+	Provider string `json:"provider"`  // Provider 是 letsencrypt 或 other。
+	NiceName string `json:"nice_name"` // NiceName 是证书展示名称。
+}
+
+// npmCertificateUploadResult 描述上传接口回显的证书文件内容。
+type npmCertificateUploadResult struct {
+	Certificate string `json:"certificate"` // Certificate 是 NPM 保存的叶证书，仅用于本地指纹回读。
+}
+
+// npmProxyHostRecord 在 deploy 内部关联脱敏资源和真实代理主机 ID。
+type npmProxyHostRecord struct {
+	ID       uint64               // ID 是真实代理主机 ID，只能在 deploy 本地使用。
+	Resource NPMProxyHostResource // Resource 是可以上报的脱敏资源。
+}
+
+// npmRequestError 保存仅供 deploy 本地判断重试属性的 API 错误。
+type npmRequestError struct {
+	Retryable bool  // Retryable 表示网络或服务端错误可以稍后重试。
+	Cause     error // Cause 不得写入 WebSocket 响应；在线日志必须先经过统一脱敏。
+}
+
+// Error 返回 Nginx Proxy Manager 本地诊断信息。
+func (e *npmRequestError) Error() string {
+	if e == nil || e.Cause == nil {
+		return "Nginx Proxy Manager 请求失败"
+	}
+	return e.Cause.Error()
+}
+
+// Unwrap 返回原始错误，供 errors.Is 和 errors.As 使用。
+func (e *npmRequestError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Cause
+}
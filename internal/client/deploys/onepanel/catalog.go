@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -13,28 +14,40 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
 	"golang.org/x/net/idna"
 )
 
-// DiscoverOnePanelWebsiteResources 动态读取全部 1Panel 网站的脱敏目录。
+// DiscoverOnePanelWebsiteResources 动态读取全部 1Panel 实例的网站脱敏目录；部分实例不可达时同时返回已发现资源和错误。
 func DiscoverOnePanelWebsiteResources(ctx context.Context) ([]OnePanelWebsiteResource, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	discoveryContext, cancel := context.WithTimeout(ctx, onePanelDiscoveryTimeout)
-	defer cancel()
-
-	records, err := loadOnePanelWebsiteRecords(discoveryContext)
+	panels, err := getOnePanelConfigs(ctx)
 	if err != nil {
 		return nil, err
 	}
-	resources := make([]OnePanelWebsiteResource, 0, len(records))
-	for _, record := range records {
-		resource := record.Resource
-		resource.Domains = append([]string(nil), record.Resource.Domains...)
-		resources = append(resources, resource)
+
+	resources := make([]OnePanelWebsiteResource, 0)
+	var failures []error
+	for _, panel := range panels {
+		discoveryContext, cancel := context.WithTimeout(ctx, onePanelDiscoveryTimeout)
+		records, err := loadOnePanelWebsiteRecords(discoveryContext, panel)
+		cancel()
+		if err != nil {
+			logger.Warn("读取 1Panel 网站目录失败", "instance", panel.Name, "error", err)
+			failures = append(failures, onePanelInstanceError(panel, err))
+			continue
+		}
+		for _, record := range records {
+			resource := record.Resource
+			resource.Domains = append([]string(nil), record.Resource.Domains...)
+			resources = append(resources, resource)
+		}
 	}
-	return resources, nil
+	return resources, errors.Join(failures...)
 }
 
 // TestOnePanelWebsiteConnection 只读确认 targetRef 对应网站仍存在、正在运行且 HTTPS 配置可访问。
@@ -49,25 +62,21 @@ func TestOnePanelWebsiteConnection(ctx context.Context, targetRef string) error
 	discoveryContext, cancel := context.WithTimeout(ctx, onePanelDiscoveryTimeout)
 	defer cancel()
 
-	apiURL, apiKey, err := getOnePanelConfig(ctx)
-	if err != nil {
-		return err
-	}
-	record, err := findOnePanelWebsiteByTargetRef(discoveryContext, targetRef)
+	panel, record, err := findOnePanelWebsiteByTargetRef(discoveryContext, targetRef)
 	if err != nil {
 		return err
 	}
 	if isOnePanelWebsiteStopped(record.Resource.Status) {
 		return fmt.Errorf("1Panel 网站未运行")
 	}
-	if _, err := getOnePanelWebsiteHTTPS(discoveryContext, apiURL, apiKey, record.ID); err != nil {
-		return fmt.Errorf("读取 1Panel 网站 HTTPS 配置失败: %w", err)
+	if _, err := getOnePanelWebsiteHTTPS(discoveryContext, panel, record.ID); err != nil {
+		return onePanelInstanceError(panel, fmt.Errorf("读取 1Panel 网站 HTTPS 配置失败: %w", err))
 	}
 	return nil
 }
 
 // listOnePanelWebsiteSummaries 分页读取全部网站，避免单次响应随网站数量无限增长。
-func listOnePanelWebsiteSummaries(ctx context.Context, apiURL, apiKey string) ([]onePanelWebsiteSummary, error) {
+func listOnePanelWebsiteSummaries(ctx context.Context, panel *config.OnePanelConfig) ([]onePanelWebsiteSummary, error) {
 	websites := make([]onePanelWebsiteSummary, 0)
 	for page := 1; page <= onePanelWebsiteMaxPages; page++ {
 		requestBody := map[string]any{
@@ -80,7 +89,7 @@ func listOnePanelWebsiteSummaries(ctx context.Context, apiURL, apiKey string) ([
 			"type":           "",
 		}
 		var pageData onePanelWebsitePage
-		if err := requestOnePanelAPI(ctx, apiURL, apiKey, http.MethodPost, onePanelWebsiteSearchPath, requestBody, &pageData); err != nil {
+		if err := requestOnePanelAPI(ctx, panel, http.MethodPost, onePanelWebsiteSearchPath, requestBody, &pageData); err != nil {
 			return nil, fmt.Errorf("读取 1Panel 网站列表失败: %w", err)
 		}
 		websites = append(websites, pageData.Items...)
@@ -91,13 +100,9 @@ func listOnePanelWebsiteSummaries(ctx context.Context, apiURL, apiKey string) ([
 	return nil, fmt.Errorf("1Panel 网站分页超过安全上限")
 }
 
-// loadOnePanelWebsiteRecords 读取全部网站及其域名，并用有限并发控制面板压力。
-func loadOnePanelWebsiteRecords(ctx context.Context) ([]onePanelWebsiteRecord, error) {
-	apiURL, apiKey, err := getOnePanelConfig(ctx)
-	if err != nil {
-		return nil, err
-	}
-	websites, err := listOnePanelWebsiteSummaries(ctx, apiURL, apiKey)
+// loadOnePanelWebsiteRecords 读取一个 1Panel 实例的全部网站及其域名，并用有限并发控制面板压力。
+func loadOnePanelWebsiteRecords(ctx context.Context, panel *config.OnePanelConfig) ([]onePanelWebsiteRecord, error) {
+	websites, err := listOnePanelWebsiteSummaries(ctx, panel)
 	if err != nil {
 		return nil, err
 	}
@@ -135,7 +140,7 @@ func loadOnePanelWebsiteRecords(ctx context.Context) ([]onePanelWebsiteRecord, e
 		go func() {
 			defer waitGroup.Done()
 			for job := range jobs {
-				domains, loadErr := loadOnePanelWebsiteDomains(workerContext, apiURL, apiKey, job.Website)
+				domains, loadErr := loadOnePanelWebsiteDomains(workerContext, panel, job.Website)
 				if loadErr != nil {
 					select {
 					case errorChannel <- loadErr:
@@ -158,7 +163,7 @@ func loadOnePanelWebsiteRecords(ctx context.Context) ([]onePanelWebsiteRecord, e
 				records[job.Index] = onePanelWebsiteRecord{
 					ID: job.Website.ID,
 					Resource: OnePanelWebsiteResource{
-						TargetRef: buildOnePanelWebsiteTargetRef(apiURL, job.Website),
+						TargetRef: buildOnePanelWebsiteTargetRef(panel, job.Website),
 						Label:     label,
 						Domain:    primaryDomain,
 						Domains:   domains,
@@ -196,10 +201,10 @@ sendJobs:
 }
 
 // loadOnePanelWebsiteDomains 读取一个网站的域名并进行规范化、去重和排序。
-func loadOnePanelWebsiteDomains(ctx context.Context, apiURL, apiKey string, website onePanelWebsiteSummary) ([]string, error) {
+func loadOnePanelWebsiteDomains(ctx context.Context, panel *config.OnePanelConfig, website onePanelWebsiteSummary) ([]string, error) {
 	var domainRecords []onePanelWebsiteDomain
 	endpoint := fmt.Sprintf(onePanelWebsiteDomainsPath, website.ID)
-	if err := requestOnePanelAPI(ctx, apiURL, apiKey, http.MethodGet, endpoint, nil, &domainRecords); err != nil {
+	if err := requestOnePanelAPI(ctx, panel, http.MethodGet, endpoint, nil, &domainRecords); err != nil {
 		return nil, fmt.Errorf("读取 1Panel 网站域名失败: %w", err)
 	}
 	domains := make([]string, 0, len(domainRecords)+1)
@@ -262,17 +267,20 @@ func normalizeOnePanelDomain(raw string) string {
 	return normalized
 }
 
-// buildOnePanelWebsiteTargetRef 根据实例、网站身份和创建时间生成稳定的不透明引用。
-func buildOnePanelWebsiteTargetRef(apiURL string, website onePanelWebsiteSummary) string {
-	identity := strings.Join([]string{
+// buildOnePanelWebsiteTargetRef 根据实例、网站身份和创建时间生成稳定的不透明引用；具名实例的名称同时进入前缀和摘要。
+func buildOnePanelWebsiteTargetRef(panel *config.OnePanelConfig, website onePanelWebsiteSummary) string {
+	parts := []string{
 		onePanelWebsiteResourceProvider,
 		"EXECUTE_BUSINES_ANSSL_CLI_1PANEL_WEBSITE_CERT",
-		normalizeOnePanelOrigin(apiURL),
+		normalizeOnePanelOrigin(panel.URL),
 		strconv.FormatUint(website.ID, 10),
 		strings.TrimSpace(website.CreatedAt),
-	}, "\x00")
-	digest := sha256.Sum256([]byte(identity))
-	return onePanelWebsiteTargetRefPrefix + hex.EncodeToString(digest[:12])
+	}
+	if panel.Name != "" {
+		parts = append(parts, panel.Name)
+	}
+	digest := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return shared.InstanceTargetRefPrefix(onePanelWebsiteTargetRefPrefix, panel.Name) + hex.EncodeToString(digest[:12])
 }
 
 // normalizeOnePanelOrigin 规范化仅用于本地哈希的面板来源，不返回或记录该值。
@@ -299,17 +307,31 @@ func normalizeOnePanelOrigin(apiURL string) string {
 	return strings.TrimRight(parsed.String(), "/")
 }
 
-// findOnePanelWebsiteByTargetRef 重新发现网站并要求 targetRef 唯一匹配。
-func findOnePanelWebsiteByTargetRef(ctx context.Context, targetRef string) (*onePanelWebsiteRecord, error) {
+// findOnePanelWebsiteByTargetRef 按 targetRef 前缀定位所属实例，重新发现该实例的网站并要求 targetRef 唯一匹配。
+func findOnePanelWebsiteByTargetRef(ctx context.Context, targetRef string) (*config.OnePanelConfig, *onePanelWebsiteRecord, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	panels, err := getOnePanelConfigs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var panel *config.OnePanelConfig
+	for _, candidate := range panels {
+		if shared.MatchInstanceTargetRef(targetRef, shared.InstanceTargetRefPrefix(onePanelWebsiteTargetRefPrefix, candidate.Name)) {
+			panel = candidate
+			break
+		}
+	}
+	if panel == nil {
+		return nil, nil, fmt.Errorf("1Panel 网站所属实例不存在或已重命名，请重新配置部署目标")
+	}
 	discoveryContext, cancel := context.WithTimeout(ctx, onePanelDiscoveryTimeout)
 	defer cancel()
 
-	records, err := loadOnePanelWebsiteRecords(discoveryContext)
+	records, err := loadOnePanelWebsiteRecords(discoveryContext, panel)
 	if err != nil {
-		return nil, err
+		return nil, nil, onePanelInstanceError(panel, err)
 	}
 	var matched *onePanelWebsiteRecord
 	for index := range records {
@@ -317,15 +339,15 @@ func findOnePanelWebsiteByTargetRef(ctx context.Context, targetRef string) (*one
 			continue
 		}
 		if matched != nil {
-			return nil, fmt.Errorf("1Panel 网站 targetRef 不唯一，请重新配置部署目标")
+			return nil, nil, fmt.Errorf("1Panel 网站 targetRef 不唯一，请重新配置部署目标")
 		}
 		record := records[index]
 		matched = &record
 	}
 	if matched == nil {
-		return nil, fmt.Errorf("1Panel 网站不存在或已重新创建，请重新配置部署目标")
+		return nil, nil, fmt.Errorf("1Panel 网站不存在或已重新创建，请重新配置部署目标")
 	}
-	return matched, nil
+	return panel, matched, nil
 }
 
 // normalizeOnePanelWebsiteProtocol 只保留可通过 HTTPS 接口部署证书的网站协议。
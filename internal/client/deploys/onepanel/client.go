@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/md5"
+	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -17,16 +18,9 @@ import (
 	"time"
 
 	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
 )
 
-// onePanelHTTPClient 禁止自动跟随重定向，避免把面板鉴权头发送到非预期地址。
-var onePanelHTTPClient = &http.Client{
-	Timeout: onePanelRequestTimeout,
-	CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
-		return http.ErrUseLastResponse
-	},
-}
-
 // IsOnePanelConfigured 判断兼容入口是否带有可用的 1Panel 配置。
 func IsOnePanelConfigured() bool {
 	return IsOnePanelConfiguredWithContext(context.Background())
@@ -35,8 +29,7 @@ func IsOnePanelConfigured() bool {
 // IsOnePanelConfiguredWithContext 从 context 快照判断 1Panel 是否已配置。
 func IsOnePanelConfiguredWithContext(ctx context.Context) bool {
 	configuration := shared.ConfigurationFromContext(ctx)
-	return configuration != nil && configuration.SSL != nil && configuration.SSL.OnePanel != nil &&
-		strings.TrimSpace(configuration.SSL.OnePanel.URL) != "" && strings.TrimSpace(configuration.SSL.OnePanel.APIKey) != ""
+	return configuration != nil && len(config.OnePanelInstances(configuration.SSL)) > 0
 }
 
 // IsOnePanelErrorRetryable 判断 1Panel 操作是否适合由后端稍后重试。
@@ -63,33 +56,71 @@ func md5Sum(data string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// getOnePanelConfig 读取并校验当前操作快照中的 1Panel API 配置。
-func getOnePanelConfig(ctx context.Context) (string, string, error) {
+// getOnePanelConfigs 读取并校验当前操作快照中的全部 1Panel 实例，兼容的单实例配置排在最前。
+func getOnePanelConfigs(ctx context.Context) ([]*config.OnePanelConfig, error) {
 	configuration := shared.ConfigurationFromContext(ctx)
-	if configuration == nil || configuration.SSL == nil || configuration.SSL.OnePanel == nil {
-		return "", "", fmt.Errorf("未配置 1Panel (ssl.onePanel)")
+	if configuration == nil {
+		return nil, fmt.Errorf("未配置 1Panel (ssl.onePanel 或 ssl.onePanels)")
+	}
+	panels := config.OnePanelInstances(configuration.SSL)
+	if len(panels) == 0 {
+		return nil, fmt.Errorf("未配置 1Panel (ssl.onePanel 或 ssl.onePanels)")
+	}
+	for _, panel := range panels {
+		if err := checkOnePanelConfig(panel); err != nil {
+			return nil, onePanelInstanceError(panel, err)
+		}
 	}
+	return panels, nil
+}
 
-	apiURL := strings.TrimRight(strings.TrimSpace(configuration.SSL.OnePanel.URL), "/")
-	apiKey := strings.TrimSpace(configuration.SSL.OnePanel.APIKey)
+// checkOnePanelConfig 校验单个 1Panel 实例的 API 地址和密钥。
+func checkOnePanelConfig(panel *config.OnePanelConfig) error {
+	apiURL := onePanelAPIURL(panel)
 	if apiURL == "" {
-		return "", "", fmt.Errorf("1Panel API 地址未配置 (ssl.onePanel.url)")
+		return fmt.Errorf("1Panel API 地址未配置")
 	}
-	if apiKey == "" {
-		return "", "", fmt.Errorf("1Panel API 密钥未配置 (ssl.onePanel.apiKey)")
+	if strings.TrimSpace(panel.APIKey) == "" {
+		return fmt.Errorf("1Panel API 密钥未配置")
 	}
 	parsedURL, err := url.Parse(apiURL)
 	if err != nil || parsedURL.Host == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-		return "", "", fmt.Errorf("1Panel API 地址必须是合法的 HTTP 或 HTTPS 地址")
+		return fmt.Errorf("1Panel API 地址必须是合法的 HTTP 或 HTTPS 地址")
 	}
 	if parsedURL.User != nil || parsedURL.RawQuery != "" || parsedURL.Fragment != "" {
-		return "", "", fmt.Errorf("1Panel API 地址不能包含用户凭据、查询参数或片段")
+		return fmt.Errorf("1Panel API 地址不能包含用户凭据、查询参数或片段")
+	}
+	return nil
+}
+
+// onePanelAPIURL 返回不带结尾斜杠的 1Panel API 地址。
+func onePanelAPIURL(panel *config.OnePanelConfig) string {
+	return strings.TrimRight(strings.TrimSpace(panel.URL), "/")
+}
+
+// onePanelInstanceError 为具名实例的错误加上实例名称，兼容的单实例保持原错误。
+func onePanelInstanceError(panel *config.OnePanelConfig, err error) error {
+	if err == nil || panel.Name == "" {
+		return err
+	}
+	return fmt.Errorf("1Panel 实例 %s: %w", panel.Name, err)
+}
+
+// newOnePanelHTTPClient 为每个实例配置独立 TLS 策略，并禁止自动跟随重定向，避免把面板鉴权头发送到非预期地址。
+func newOnePanelHTTPClient(insecureSkipVerify bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: insecureSkipVerify} //nolint:gosec // 仅在用户显式配置后允许自签名面板。
+	return &http.Client{
+		Timeout:   onePanelRequestTimeout,
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
 	}
-	return apiURL, apiKey, nil
 }
 
 // requestOnePanelAPI 使用统一鉴权方式调用 1Panel API，并限制响应大小和重定向行为。
-func requestOnePanelAPI(ctx context.Context, apiURL, apiKey, method, endpoint string, requestBody, responseData any) error {
+func requestOnePanelAPI(ctx context.Context, panel *config.OnePanelConfig, method, endpoint string, requestBody, responseData any) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -102,8 +133,8 @@ func requestOnePanelAPI(ctx context.Context, apiURL, apiKey, method, endpoint st
 		body = bytes.NewReader(jsonData)
 	}
 	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	token := md5Sum("1panel" + apiKey + timestamp)
-	req, err := http.NewRequestWithContext(ctx, method, apiURL+endpoint, body)
+	token := md5Sum("1panel" + strings.TrimSpace(panel.APIKey) + timestamp)
+	req, err := http.NewRequestWithContext(ctx, method, onePanelAPIURL(panel)+endpoint, body)
 	if err != nil {
 		return fmt.Errorf("创建 1Panel 请求失败: %w", err)
 	}
@@ -114,7 +145,7 @@ func requestOnePanelAPI(ctx context.Context, apiURL, apiKey, method, endpoint st
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := onePanelHTTPClient.Do(req)
+	resp, err := newOnePanelHTTPClient(panel.InsecureSkipVerify).Do(req)
 	if err != nil {
 		return &onePanelRequestError{Retryable: true, Cause: fmt.Errorf("请求 1Panel API 失败: %w", err)}
 	}
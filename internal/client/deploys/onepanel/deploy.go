@@ -8,6 +8,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/https-cert/deploy/internal/config"
 )
 
 // DeployCertificateTo1PanelWebsite 精确部署 targetRef 对应网站的证书，并回读指纹确认。
@@ -20,11 +22,7 @@ func DeployCertificateTo1PanelWebsite(ctx context.Context, targetRef, certificat
 		ctx = context.Background()
 	}
 
-	apiURL, apiKey, err := getOnePanelConfig(ctx)
-	if err != nil {
-		return err
-	}
-	record, err := findOnePanelWebsiteByTargetRef(ctx, targetRef)
+	panel, record, err := findOnePanelWebsiteByTargetRef(ctx, targetRef)
 	if err != nil {
 		return err
 	}
@@ -36,17 +34,17 @@ func DeployCertificateTo1PanelWebsite(ctx context.Context, targetRef, certificat
 		return err
 	}
 
-	current, err := getOnePanelWebsiteHTTPS(ctx, apiURL, apiKey, record.ID)
+	current, err := getOnePanelWebsiteHTTPS(ctx, panel, record.ID)
 	if err != nil {
 		return fmt.Errorf("读取 1Panel 网站 HTTPS 配置失败: %w", err)
 	}
 	requestBody := buildOnePanelWebsiteHTTPSUpdate(record.ID, current, certificatePEM, privateKeyPEM)
 	endpoint := fmt.Sprintf(onePanelWebsiteHTTPSPath, record.ID)
-	if err := requestOnePanelAPI(ctx, apiURL, apiKey, http.MethodPost, endpoint, requestBody, nil); err != nil {
+	if err := requestOnePanelAPI(ctx, panel, http.MethodPost, endpoint, requestBody, nil); err != nil {
 		return fmt.Errorf("更新 1Panel 网站证书失败: %w", err)
 	}
 
-	updated, err := getOnePanelWebsiteHTTPS(ctx, apiURL, apiKey, record.ID)
+	updated, err := getOnePanelWebsiteHTTPS(ctx, panel, record.ID)
 	if err != nil {
 		return fmt.Errorf("回读 1Panel 网站 HTTPS 配置失败: %w", err)
 	}
@@ -61,10 +59,10 @@ func DeployCertificateTo1PanelWebsite(ctx context.Context, targetRef, certificat
 }
 
 // getOnePanelWebsiteHTTPS 读取一个网站的当前 HTTPS 配置。
-func getOnePanelWebsiteHTTPS(ctx context.Context, apiURL, apiKey string, websiteID uint64) (*onePanelWebsiteHTTPS, error) {
+func getOnePanelWebsiteHTTPS(ctx context.Context, panel *config.OnePanelConfig, websiteID uint64) (*onePanelWebsiteHTTPS, error) {
 	var httpsConfig onePanelWebsiteHTTPS
 	endpoint := fmt.Sprintf(onePanelWebsiteHTTPSPath, websiteID)
-	if err := requestOnePanelAPI(ctx, apiURL, apiKey, http.MethodGet, endpoint, nil, &httpsConfig); err != nil {
+	if err := requestOnePanelAPI(ctx, panel, http.MethodGet, endpoint, nil, &httpsConfig); err != nil {
 		return nil, err
 	}
 	return &httpsConfig, nil
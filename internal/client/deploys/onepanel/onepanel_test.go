@@ -0,0 +1,169 @@
+package onepanel
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestDiscoverOnePanelWebsiteResourcesAcrossInstances 验证多实例汇总发现、不可达实例返回部分结果、单实例 targetRef 保持不变，以及 targetRef 直接定位所属实例。
+func TestDiscoverOnePanelWebsiteResourcesAcrossInstances(t *testing.T) {
+	legacy := &fakeOnePanel{apiKey: "legacy-key", websites: []fakeOnePanelWebsite{{ID: 1, CreatedAt: "2024-01-01T00:00:00Z", Domain: "a.example.com"}}}
+	named := &fakeOnePanel{apiKey: "named-key", websites: []fakeOnePanelWebsite{{ID: 1, CreatedAt: "2024-01-01T00:00:00Z", Domain: "b.example.com"}}}
+	legacyServer := httptest.NewServer(legacy)
+	t.Cleanup(legacyServer.Close)
+	namedServer := httptest.NewServer(named)
+	t.Cleanup(namedServer.Close)
+	downServer := httptest.NewServer(http.NotFoundHandler())
+	downServer.Close()
+	ctx := shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{
+		OnePanel: &config.OnePanelConfig{URL: legacyServer.URL, APIKey: "legacy-key"},
+		OnePanels: []*config.OnePanelConfig{
+			{Name: "panel-02", URL: namedServer.URL, APIKey: "named-key"},
+			{Name: "down", URL: downServer.URL, APIKey: "down-key"},
+		},
+	}}})
+
+	resources, err := DiscoverOnePanelWebsiteResources(ctx)
+	if err == nil || !strings.Contains(err.Error(), "1Panel 实例 down") || !IsOnePanelErrorRetryable(err) || len(resources) != 2 {
+		t.Fatalf("不可达实例应返回其他实例的资源和可重试错误: %+v err=%v", resources, err)
+	}
+	if resources[0].Domain != "a.example.com" || resources[1].Domain != "b.example.com" {
+		t.Fatalf("应按配置顺序汇总各实例的网站: %+v", resources)
+	}
+	legacyIdentity := strings.Join([]string{onePanelWebsiteResourceProvider, "EXECUTE_BUSINES_ANSSL_CLI_1PANEL_WEBSITE_CERT", normalizeOnePanelOrigin(legacyServer.URL), "1", "2024-01-01T00:00:00Z"}, "\x00")
+	legacyDigest := sha256.Sum256([]byte(legacyIdentity))
+	if resources[0].TargetRef != onePanelWebsiteTargetRefPrefix+hex.EncodeToString(legacyDigest[:12]) {
+		t.Fatalf("单实例配置的 targetRef 应与升级前完全一致: %s", resources[0].TargetRef)
+	}
+	if !strings.HasPrefix(resources[1].TargetRef, shared.InstanceTargetRefPrefix(onePanelWebsiteTargetRefPrefix, "panel-02")) {
+		t.Fatalf("具名实例的 targetRef 应包含实例标记: %s", resources[1].TargetRef)
+	}
+
+	if err := TestOnePanelWebsiteConnection(ctx, shared.InstanceTargetRefPrefix(onePanelWebsiteTargetRefPrefix, "gone")+"0123"); err == nil || !strings.Contains(err.Error(), "已重命名") {
+		t.Fatalf("实例已删除的 targetRef 应提示重新配置: %v", err)
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "b.example.com")
+	if err := DeployCertificateTo1PanelWebsite(ctx, resources[1].TargetRef, certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateTo1PanelWebsite: %v", err)
+	}
+	named.mu.Lock()
+	defer named.mu.Unlock()
+	legacy.mu.Lock()
+	defer legacy.mu.Unlock()
+	if named.websites[0].Certificate != certificatePEM || legacy.websites[0].Certificate != "" {
+		t.Fatalf("证书应只部署到具名实例: named=%+v legacy=%+v", named.websites, legacy.websites)
+	}
+}
+
+// fakeOnePanelWebsite 是模拟 1Panel 中的一个网站。
+type fakeOnePanelWebsite struct {
+	ID          uint64
+	CreatedAt   string
+	Domain      string
+	Certificate string
+}
+
+// fakeOnePanel 在内存中模拟 1Panel 网站列表、域名和 HTTPS 接口。
+type fakeOnePanel struct {
+	mu       sync.Mutex
+	apiKey   string
+	websites []fakeOnePanelWebsite
+}
+
+// ServeHTTP 校验实例 API 密钥签名，并按 1Panel v2 的 code/message/data 包络返回数据。
+func (f *fakeOnePanel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("1Panel-Token") != md5Sum("1panel"+f.apiKey+r.Header.Get("1Panel-Timestamp")) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var data any
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == onePanelWebsiteSearchPath:
+		items := make([]map[string]any, 0, len(f.websites))
+		for _, website := range f.websites {
+			items = append(items, map[string]any{"id": website.ID, "createdAt": website.CreatedAt, "protocol": "HTTPS", "status": "Running", "primaryDomain": website.Domain})
+		}
+		data = map[string]any{"total": len(items), "items": items}
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/v2/websites/domains/"):
+		website := f.find(strings.TrimPrefix(r.URL.Path, "/api/v2/websites/domains/"))
+		if website == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data = []map[string]any{{"domain": website.Domain}}
+	case strings.HasSuffix(r.URL.Path, "/https"):
+		website := f.find(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v2/websites/"), "/https"))
+		if website == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPost {
+			var update onePanelWebsiteHTTPSUpdate
+			json.NewDecoder(r.Body).Decode(&update)
+			website.Certificate = update.Certificate
+			break
+		}
+		data = map[string]any{"enable": website.Certificate != "", "SSL": map[string]any{"pem": website.Certificate}}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"code": http.StatusOK, "message": "", "data": data})
+}
+
+// find 按字符串形式的网站 ID 查找模拟网站。
+func (f *fakeOnePanel) find(id string) *fakeOnePanelWebsite {
+	for index := range f.websites {
+		if strconv.FormatUint(f.websites[index].ID, 10) == id {
+			return &f.websites[index]
+		}
+	}
+	return nil
+}
+
+// generateTestCertificatePair 生成测试用自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
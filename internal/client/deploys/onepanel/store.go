@@ -2,6 +2,7 @@ package onepanel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -15,12 +16,12 @@ func TestOnePanelConnection() error {
 	return TestOnePanelConnectionWithContext(context.Background())
 }
 
-// TestOnePanelConnectionWithContext 使用调用方 context 验证 1Panel 连接。
+// TestOnePanelConnectionWithContext 使用调用方 context 逐个验证全部 1Panel 实例的连接。
 func TestOnePanelConnectionWithContext(ctx context.Context) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	apiURL, apiKey, err := getOnePanelConfig(ctx)
+	panels, err := getOnePanelConfigs(ctx)
 	if err != nil {
 		return err
 	}
@@ -29,15 +30,18 @@ func TestOnePanelConnectionWithContext(ctx context.Context) error {
 		"page":     1,
 		"pageSize": 1,
 	}
-	if err := requestOnePanelAPI(ctx, apiURL, apiKey, http.MethodPost, onePanelSSLSearchPath, requestBody, nil); err != nil {
-		return fmt.Errorf("1Panel 连接测试失败: %w", err)
+	var failures []error
+	for _, panel := range panels {
+		if err := requestOnePanelAPI(ctx, panel, http.MethodPost, onePanelSSLSearchPath, requestBody, nil); err != nil {
+			failures = append(failures, onePanelInstanceError(panel, fmt.Errorf("1Panel 连接测试失败: %w", err)))
+		}
 	}
-	return nil
+	return errors.Join(failures...)
 }
 
-// DeployToStore 上传证书到 1Panel 证书库，保留原有非网站级行为。
+// DeployToStore 上传证书到全部 1Panel 实例的证书库，保留原有非网站级行为；单个实例失败不影响其他实例。
 func DeployToStore(ctx context.Context, sourceDir, domain string) error {
-	apiURL, apiKey, err := getOnePanelConfig(ctx)
+	panels, err := getOnePanelConfigs(ctx)
 	if err != nil {
 		return err
 	}
@@ -56,11 +60,15 @@ func DeployToStore(ctx context.Context, sourceDir, domain string) error {
 		"privateKey":  string(keyContent),
 		"description": onePanelWebsiteDescription,
 	}
-	if err := requestOnePanelAPI(ctx, apiURL, apiKey, http.MethodPost, onePanelSSLUploadPath, certData, nil); err != nil {
-		return err
+	var failures []error
+	for _, panel := range panels {
+		if err := requestOnePanelAPI(ctx, panel, http.MethodPost, onePanelSSLUploadPath, certData, nil); err != nil {
+			failures = append(failures, onePanelInstanceError(panel, err))
+			continue
+		}
+		logger.Info("证书已上传到 1Panel 证书库", "domain", domain, "instance", panel.Name)
 	}
-	logger.Info("证书已上传到 1Panel 证书库", "domain", domain)
-	return nil
+	return errors.Join(failures...)
 }
 
 // osReadFile 读取证书目录下的固定文件名，便于集中约束路径拼接。
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
 	"github.com/https-cert/deploy/pkg/logger"
 )
 
@@ -25,7 +27,7 @@ func Deploy(ctx context.Context, sourceDir string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	sacliPath, err := getOpenVPNASSacliPath()
+	sacliPath, err := getOpenVPNASSacliPath(configuredSacliPath(ctx))
 	if err != nil {
 		return err
 	}
@@ -48,25 +50,50 @@ func Deploy(ctx context.Context, sourceDir string) error {
 		logger.Warn("OpenVPN-AS 未找到 issuer.crt，回退使用 fullchain.pem 作为 CA Bundle", "path", caBundlePath)
 	}
 
-	commands := [][]string{
+	for _, args := range openVPNASCommands(certPath, keyPath, caBundlePath) {
+		if err := runOpenVPNASSacli(ctx, sacliPath, args...); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("OpenVPN-AS 证书部署完成", "sacli", sacliPath)
+	return nil
+}
+
+// openVPNASCommands 返回导入私钥、证书、CA Bundle 并重新启动服务的 sacli 参数序列，本机和 SSH 模式共用。
+func openVPNASCommands(certPath, keyPath, caBundlePath string) [][]string {
+	return [][]string{
 		{"--key", "cs.priv_key", "--value_file", keyPath, "ConfigPut"},
 		{"--key", "cs.cert", "--value_file", certPath, "ConfigPut"},
 		{"--key", "cs.ca_bundle", "--value_file", caBundlePath, "ConfigPut"},
 		{"start"},
 	}
+}
 
-	for _, args := range commands {
-		if err := runOpenVPNASSacli(ctx, sacliPath, args...); err != nil {
-			return err
-		}
+// configuredSacliPath 读取当前操作快照中 ssl.openVPNAS.sacliPath，未配置时返回空字符串。
+func configuredSacliPath(ctx context.Context) string {
+	openVPNAS := getOpenVPNASConfig(ctx)
+	if openVPNAS == nil {
+		return ""
 	}
+	return openVPNAS.SacliPath
+}
 
-	logger.Info("OpenVPN-AS 证书部署完成", "sacli", sacliPath)
-	return nil
+// getOpenVPNASConfig 读取当前操作快照中的 OpenVPN-AS 配置。
+func getOpenVPNASConfig(ctx context.Context) *config.OpenVPNASConfig {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil {
+		return nil
+	}
+	return configuration.SSL.OpenVPNAS
 }
 
-func getOpenVPNASSacliPath() (string, error) {
-	for _, candidate := range openVPNASSacliCandidates {
+func getOpenVPNASSacliPath(configured string) (string, error) {
+	candidates := openVPNASSacliCandidates
+	if configured != "" {
+		candidates = []string{configured}
+	}
+	for _, candidate := range candidates {
 		path, err := exec.LookPath(candidate)
 		if err == nil {
 			return path, nil
@@ -102,3 +102,17 @@ func TestDeployToOpenVPNASRunsExpectedCommands(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildRemoteSacliCommandsQuotesPathsInDeploySequence(t *testing.T) {
+	commands := buildRemoteSacliCommands("/opt/openvpn as/sacli", "/tmp/anssl-openvpnas.abc")
+
+	expected := []string{
+		"'/opt/openvpn as/sacli' '--key' 'cs.priv_key' '--value_file' '/tmp/anssl-openvpnas.abc/privateKey.key' 'ConfigPut'",
+		"'/opt/openvpn as/sacli' '--key' 'cs.cert' '--value_file' '/tmp/anssl-openvpnas.abc/cert.pem' 'ConfigPut'",
+		"'/opt/openvpn as/sacli' '--key' 'cs.ca_bundle' '--value_file' '/tmp/anssl-openvpnas.abc/ca_bundle.pem' 'ConfigPut'",
+		"'/opt/openvpn as/sacli' 'start'",
+	}
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected remote commands:\n%s", strings.Join(commands, "\n"))
+	}
+}
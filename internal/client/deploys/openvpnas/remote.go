@@ -0,0 +1,152 @@
+package openvpnas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/remote"
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+// openVPNASSacliTimeout 是单条远程 sacli 命令的超时，与本机模式保持一致。
+const openVPNASSacliTimeout = 30 * time.Second
+
+var remoteOpenVPNASDeploymentLock = make(chan struct{}, 1)
+
+// IsRemote 判断 OpenVPN-AS 配置是否启用了 SSH 远程部署。
+func IsRemote(openVPNAS *config.OpenVPNASConfig) bool {
+	return openVPNAS != nil && config.IsSSHConfigured(&openVPNAS.SSHConfig)
+}
+
+// TestOpenVPNASConnectionWithContext 根据配置验证本机 sacli 或 SSH 登录、sudo 权限和远程 sacli。
+func TestOpenVPNASConnectionWithContext(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	openVPNAS := getOpenVPNASConfig(ctx)
+	if !IsRemote(openVPNAS) {
+		_, err := getOpenVPNASSacliPath(configuredSacliPath(ctx))
+		return err
+	}
+	executor, err := remote.NewExecutorContext(ctx, &openVPNAS.SSHConfig, "OpenVPN-AS", "anssl-openvpnas")
+	if err != nil {
+		return err
+	}
+	defer executor.Close()
+
+	sacliPath := remoteSacliPath(openVPNAS)
+	if _, err := executor.RunContext(ctx, "test -x "+remote.QuotePOSIXShellArg(sacliPath), nil, true); err != nil {
+		return fmt.Errorf("OpenVPN-AS SSH 已登录，但未找到可执行的 sacli (%s) 或无法 sudo: %w", sacliPath, err)
+	}
+	return nil
+}
+
+// DeployRemote 通过 SSH 上传证书并在远程主机上执行与本机模式相同的 sacli 导入和重启序列。
+func DeployRemote(ctx context.Context, sourceDir string, openVPNAS *config.OpenVPNASConfig, knownHostsFile string) error {
+	if err := shared.OperationContextError(ctx); err != nil {
+		return err
+	}
+	if openVPNAS == nil {
+		return errors.New("OpenVPN-AS SSH 配置不能为空")
+	}
+	caBundlePath, err := getOpenVPNASCABundlePath(sourceDir)
+	if err != nil {
+		return err
+	}
+	if filepath.Base(caBundlePath) == "fullchain.pem" {
+		logger.Warn("OpenVPN-AS 未找到 issuer.crt，回退使用 fullchain.pem 作为 CA Bundle", "path", caBundlePath)
+	}
+	uploads := []struct {
+		name   string
+		source string
+	}{
+		{"cert.pem", filepath.Join(sourceDir, "cert.pem")},
+		{"privateKey.key", filepath.Join(sourceDir, "privateKey.key")},
+		{"ca_bundle.pem", caBundlePath},
+	}
+	contents := make([][]byte, len(uploads))
+	for i, upload := range uploads {
+		if err := ensureRegularFile(upload.source); err != nil {
+			return fmt.Errorf("OpenVPN-AS 证书文件不可用: %w", err)
+		}
+		if contents[i], err = os.ReadFile(upload.source); err != nil {
+			return fmt.Errorf("读取 OpenVPN-AS 证书文件失败: %w", err)
+		}
+	}
+
+	select {
+	case remoteOpenVPNASDeploymentLock <- struct{}{}:
+		defer func() { <-remoteOpenVPNASDeploymentLock }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	executor, err := remote.NewExecutorContext(ctx, &openVPNAS.SSHConfig, "OpenVPN-AS", "anssl-openvpnas", knownHostsFile)
+	if err != nil {
+		return err
+	}
+	defer executor.Close()
+
+	remoteTempDir, err := executor.CreateTempDirContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if cleanupErr := executor.RemoveTempDirContext(cleanupCtx, remoteTempDir); cleanupErr != nil {
+			logger.WarnLocal("清理 OpenVPN-AS SSH 临时目录失败", "error", cleanupErr, "host", openVPNAS.Host)
+		}
+	}()
+
+	for i, upload := range uploads {
+		if err := executor.UploadContext(ctx, path.Join(remoteTempDir, upload.name), contents[i]); err != nil {
+			return fmt.Errorf("上传 OpenVPN-AS 证书文件 %s 失败: %w", upload.name, err)
+		}
+	}
+
+	sacliPath := remoteSacliPath(openVPNAS)
+	for _, command := range buildRemoteSacliCommands(sacliPath, remoteTempDir) {
+		// sacli 需要 root 权限，非 root 用户通过 sudo 执行。
+		if output, err := executor.RunWithTimeoutContext(ctx, command, nil, true, openVPNASSacliTimeout); err != nil {
+			return fmt.Errorf("执行 OpenVPN-AS 远程命令失败: %w\n%s", err, string(output))
+		}
+	}
+
+	logger.InfoLocal("证书已通过 SSH 导入 OpenVPN-AS", "host", openVPNAS.Host, "sacli", sacliPath)
+	return nil
+}
+
+// buildRemoteSacliCommands 把 sacli 参数序列转换为引用远程临时目录文件的 shell 命令。
+func buildRemoteSacliCommands(sacliPath, remoteTempDir string) []string {
+	sequence := openVPNASCommands(
+		path.Join(remoteTempDir, "cert.pem"),
+		path.Join(remoteTempDir, "privateKey.key"),
+		path.Join(remoteTempDir, "ca_bundle.pem"),
+	)
+	commands := make([]string, 0, len(sequence))
+	for _, args := range sequence {
+		quoted := []string{remote.QuotePOSIXShellArg(sacliPath)}
+		for _, arg := range args {
+			quoted = append(quoted, remote.QuotePOSIXShellArg(arg))
+		}
+		commands = append(commands, strings.Join(quoted, " "))
+	}
+	return commands
+}
+
+// remoteSacliPath 返回远程 sacli 路径，未配置时使用 OpenVPN-AS 默认安装位置。
+func remoteSacliPath(openVPNAS *config.OpenVPNASConfig) string {
+	if openVPNAS.SacliPath != "" {
+		return openVPNAS.SacliPath
+	}
+	return openVPNASSacliPath
+}
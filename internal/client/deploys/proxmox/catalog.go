@@ -0,0 +1,173 @@
+package proxmox
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+// proxmoxNodeNamePattern 限制节点名称为 Proxmox VE 允许的主机名字符，避免拼接 API 路径时越界。
+var proxmoxNodeNamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?$`)
+
+// DiscoverProxmoxNodeResources 读取全部集群的节点目录；部分集群不可达时同时返回已发现资源和错误。
+func DiscoverProxmoxNodeResources(ctx context.Context) ([]ProxmoxNodeResource, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	clusters, err := getProxmoxConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]ProxmoxNodeResource, 0)
+	var failures []error
+	for _, cluster := range clusters {
+		records, err := loadProxmoxNodeRecords(ctx, cluster)
+		if err != nil {
+			logger.Warn("读取 Proxmox VE 节点列表失败", "name", cluster.Name, "error", err)
+			failures = append(failures, fmt.Errorf("Proxmox VE 集群 %s: %w", cluster.Name, err))
+			continue
+		}
+		for _, record := range records {
+			resources = append(resources, record.Resource)
+		}
+	}
+	return resources, errors.Join(failures...)
+}
+
+// TestProxmoxNodeConnection 只读确认 targetRef 对应节点仍存在，且 API Token 可以读取节点证书信息。
+func TestProxmoxNodeConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	record, err := findProxmoxNode(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	if record.Resource.Status != proxmoxStatusOnline {
+		return &proxmoxRequestError{Retryable: true, Cause: fmt.Errorf("Proxmox VE 节点 %s 当前不在线", record.Node)}
+	}
+	operationContext, cancel := context.WithTimeout(ctx, proxmoxDiscoveryTimeout)
+	defer cancel()
+	if _, err := getProxmoxProxyCertificate(operationContext, record); err != nil {
+		return fmt.Errorf("读取 Proxmox VE 节点证书信息失败: %w", err)
+	}
+	return nil
+}
+
+// loadProxmoxNodeRecords 在独立的发现超时内读取单个集群的节点列表，并按节点名称排序。
+func loadProxmoxNodeRecords(ctx context.Context, cluster *config.ProxmoxConfig) ([]proxmoxNodeRecord, error) {
+	discoveryContext, cancel := context.WithTimeout(ctx, proxmoxDiscoveryTimeout)
+	defer cancel()
+	var nodes []proxmoxNode
+	if err := requestProxmoxAPI(discoveryContext, cluster, http.MethodGet, "/nodes", nil, &nodes); err != nil {
+		return nil, err
+	}
+	records := make([]proxmoxNodeRecord, 0, len(nodes))
+	for _, node := range nodes {
+		name := strings.TrimSpace(node.Node)
+		if !proxmoxNodeNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("Proxmox VE 返回的节点名称无效: %q", node.Node)
+		}
+		records = append(records, proxmoxNodeRecord{
+			Cluster: cluster,
+			Node:    name,
+			Resource: ProxmoxNodeResource{
+				TargetRef: buildProxmoxNodeTargetRef(cluster.URL, name),
+				Label:     name,
+				Cluster:   cluster.Name,
+				Status:    strings.ToLower(strings.TrimSpace(node.Status)),
+			},
+		})
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Node < records[j].Node })
+	return records, nil
+}
+
+// findProxmoxNode 逐个集群重新读取节点列表定位 targetRef，节点被移出集群后引用失效。
+func findProxmoxNode(ctx context.Context, targetRef string) (*proxmoxNodeRecord, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("Proxmox VE 节点 targetRef 不能为空")
+	}
+	clusters, err := getProxmoxConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var failures []error
+	for _, cluster := range clusters {
+		records, err := loadProxmoxNodeRecords(ctx, cluster)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("Proxmox VE 集群 %s: %w", cluster.Name, err))
+			continue
+		}
+		for index := range records {
+			if records[index].Resource.TargetRef == targetRef {
+				record := records[index]
+				return &record, nil
+			}
+		}
+	}
+	// 无法访问的集群中可能存在该节点，此时返回连接错误而不是判定引用失效。
+	if len(failures) > 0 {
+		return nil, errors.Join(failures...)
+	}
+	return nil, errors.New("Proxmox VE 节点不存在或已移出集群，请重新配置部署目标")
+}
+
+// getProxmoxProxyCertificate 读取节点当前的自定义 Web 证书信息，未上传过自定义证书时返回 nil。
+func getProxmoxProxyCertificate(ctx context.Context, record *proxmoxNodeRecord) (*proxmoxCertificateInfo, error) {
+	var certificates []proxmoxCertificateInfo
+	if err := requestProxmoxAPI(ctx, record.Cluster, http.MethodGet, "/nodes/"+url.PathEscape(record.Node)+"/certificates/info", nil, &certificates); err != nil {
+		return nil, err
+	}
+	for index := range certificates {
+		if certificates[index].Filename == proxmoxProxyCertFile {
+			return &certificates[index], nil
+		}
+	}
+	return nil, nil
+}
+
+// buildProxmoxNodeTargetRef 根据集群 API 地址和节点名称生成稳定的不透明引用。
+func buildProxmoxNodeTargetRef(apiURL, node string) string {
+	identity := strings.Join([]string{
+		"ansslCli",
+		"DEPLOYMENT_TYPE_ANSSL_CLI_PROXMOX_CERT",
+		normalizeProxmoxOrigin(apiURL),
+		node,
+	}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return proxmoxTargetPrefix + hex.EncodeToString(digest[:12])
+}
+
+// normalizeProxmoxOrigin 规范化仅用于本地哈希的集群 API 来源，不返回或记录该值。
+func normalizeProxmoxOrigin(apiURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(apiURL))
+	if err != nil {
+		return strings.ToLower(strings.TrimRight(strings.TrimSpace(apiURL), "/"))
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	hostname := strings.ToLower(parsed.Hostname())
+	port := parsed.Port()
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+	host := hostname
+	if port != "" {
+		host = net.JoinHostPort(hostname, port)
+	} else if strings.Contains(hostname, ":") {
+		host = "[" + hostname + "]"
+	}
+	return scheme + "://" + host + strings.TrimRight(parsed.Path, "/")
+}
@@ -0,0 +1,152 @@
+package proxmox
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// proxmoxRequestError 保存仅供 deploy 本地判断重试属性的 Proxmox VE API 错误。
+type proxmoxRequestError struct {
+	Retryable bool  // Retryable 表示网络或服务端错误可以稍后重试。
+	Cause     error // Cause 不得写入 WebSocket 响应；在线日志必须先经过统一脱敏。
+}
+
+// Error 返回 Proxmox VE 本地诊断信息。
+func (e *proxmoxRequestError) Error() string {
+	if e == nil || e.Cause == nil {
+		return "Proxmox VE 请求失败"
+	}
+	return e.Cause.Error()
+}
+
+// Unwrap 返回原始错误，供 errors.Is 和 errors.As 使用。
+func (e *proxmoxRequestError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Cause
+}
+
+// IsProxmoxConfiguredWithContext 从 context 快照判断是否配置了 Proxmox VE 集群。
+func IsProxmoxConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && len(configuration.SSL.Proxmox) > 0
+}
+
+// IsProxmoxErrorRetryable 判断 Proxmox VE 操作是否适合由后端稍后重试。
+func IsProxmoxErrorRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var requestError *proxmoxRequestError
+	if errors.As(err, &requestError) {
+		return requestError.Retryable
+	}
+	var networkError net.Error
+	return errors.As(err, &networkError) && networkError.Timeout()
+}
+
+// getProxmoxConfigs 读取当前操作快照中的 Proxmox VE 集群配置。
+func getProxmoxConfigs(ctx context.Context) ([]*config.ProxmoxConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || len(configuration.SSL.Proxmox) == 0 {
+		return nil, errors.New("未配置 Proxmox VE 集群 (ssl.proxmox)")
+	}
+	return configuration.SSL.Proxmox, nil
+}
+
+// requestProxmoxAPI 使用 API Token 调用 Proxmox VE API，values 非空时以表单 POST 提交。
+func requestProxmoxAPI(ctx context.Context, cluster *config.ProxmoxConfig, method, endpoint string, values url.Values, responseData any) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var body io.Reader
+	if values != nil {
+		body = strings.NewReader(values.Encode())
+	}
+	apiURL := strings.TrimRight(strings.TrimSpace(cluster.URL), "/")
+	req, err := http.NewRequestWithContext(ctx, method, apiURL+proxmoxAPIPath+endpoint, body)
+	if err != nil {
+		return fmt.Errorf("创建 Proxmox VE 请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "PVEAPIToken="+cluster.TokenID+"="+cluster.TokenSecret)
+	if values != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := newProxmoxHTTPClient(cluster.InsecureSkipVerify).Do(req)
+	if err != nil {
+		return &proxmoxRequestError{Retryable: true, Cause: fmt.Errorf("请求 Proxmox VE API 失败: %w", err)}
+	}
+	defer resp.Body.Close()
+	responseBody, err := io.ReadAll(io.LimitReader(resp.Body, proxmoxMaxResponseBodySize+1))
+	if err != nil {
+		return &proxmoxRequestError{Retryable: true, Cause: fmt.Errorf("读取 Proxmox VE 响应失败: %w", err)}
+	}
+	if len(responseBody) > proxmoxMaxResponseBodySize {
+		return &proxmoxRequestError{Retryable: false, Cause: errors.New("Proxmox VE 响应体超过最大限制")}
+	}
+	var envelope proxmoxEnvelope
+	decodeErr := json.Unmarshal(responseBody, &envelope)
+	if resp.StatusCode != http.StatusOK {
+		// Proxmox VE 把错误原因写在 HTTP 状态行，参数错误在 errors 字段中。
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return &proxmoxRequestError{Retryable: retryable, Cause: fmt.Errorf("Proxmox VE API 返回 HTTP %s%s", resp.Status, formatProxmoxErrors(envelope.Errors))}
+	}
+	if decodeErr != nil {
+		return &proxmoxRequestError{Retryable: false, Cause: fmt.Errorf("解析 Proxmox VE 响应失败: %w", decodeErr)}
+	}
+	if responseData == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, responseData); err != nil {
+		return &proxmoxRequestError{Retryable: false, Cause: fmt.Errorf("解析 Proxmox VE 响应数据失败: %w", err)}
+	}
+	return nil
+}
+
+// formatProxmoxErrors 把字段错误按名称排序后拼接为本地诊断信息。
+func formatProxmoxErrors(fieldErrors map[string]string) string {
+	if len(fieldErrors) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(fieldErrors))
+	for name := range fieldErrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+": "+strings.TrimSpace(fieldErrors[name]))
+	}
+	return " (" + strings.Join(parts, "; ") + ")"
+}
+
+// newProxmoxHTTPClient 为每个集群配置独立 TLS 策略，避免影响其他 HTTP 客户端。
+func newProxmoxHTTPClient(insecureSkipVerify bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: insecureSkipVerify} //nolint:gosec // 仅在用户显式配置后允许自签名节点证书。
+	return &http.Client{
+		Timeout:   proxmoxRequestTimeout,
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,15 @@
+package proxmox
+
+import "time"
+
+const (
+	proxmoxRequestTimeout      = 30 * time.Second
+	proxmoxDiscoveryTimeout    = 20 * time.Second
+	proxmoxMaxResponseBodySize = 4 * 1024 * 1024
+	proxmoxTargetPrefix        = "proxmox-node-"
+	proxmoxAPIPath             = "/api2/json"
+	proxmoxProxyCertFile       = "pveproxy-ssl.pem"
+	proxmoxReadbackAttempts    = 10
+	proxmoxReadbackInterval    = 2 * time.Second
+	proxmoxStatusOnline        = "online"
+)
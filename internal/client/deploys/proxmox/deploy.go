@@ -0,0 +1,92 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+// DeployCertificateToProxmoxNode 上传节点自定义 Web 证书并重启 pveproxy，再回读节点 API 报告的指纹确认。
+func DeployCertificateToProxmoxNode(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	expectedFingerprint, err := providers.LeafCertificateSHA256(certificatePEM)
+	if err != nil {
+		return err
+	}
+	record, err := findProxmoxNode(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	if record.Resource.Status != proxmoxStatusOnline {
+		return &proxmoxRequestError{Retryable: true, Cause: fmt.Errorf("Proxmox VE 节点 %s 当前不在线", record.Node)}
+	}
+
+	// 节点已经使用同一张证书时不再上传，避免无意义地重启 pveproxy。
+	current, err := getProxmoxProxyCertificate(ctx, record)
+	if err != nil {
+		return fmt.Errorf("读取 Proxmox VE 节点证书信息失败: %w", err)
+	}
+	if current != nil && normalizeProxmoxFingerprint(current.Fingerprint) == expectedFingerprint {
+		logger.Info("Proxmox VE 节点已在使用该证书，跳过上传", "cluster", record.Cluster.Name, "node", record.Node)
+		return nil
+	}
+
+	if err := requestProxmoxAPI(ctx, record.Cluster, http.MethodPost, "/nodes/"+url.PathEscape(record.Node)+"/certificates/custom", url.Values{
+		"certificates": {certificatePEM},
+		"key":          {privateKeyPEM},
+		"force":        {"1"},
+		"restart":      {"1"},
+	}, nil); err != nil {
+		return fmt.Errorf("上传 Proxmox VE 节点证书失败: %w", err)
+	}
+	if err := verifyProxmoxProxyCertificate(ctx, record, expectedFingerprint); err != nil {
+		return err
+	}
+	logger.Info("Proxmox VE 节点证书已更新", "cluster", record.Cluster.Name, "node", record.Node)
+	return nil
+}
+
+// verifyProxmoxProxyCertificate 回读节点证书指纹；pveproxy 重启期间连接可能短暂失败，因此有限次重试。
+func verifyProxmoxProxyCertificate(ctx context.Context, record *proxmoxNodeRecord, expectedFingerprint string) error {
+	var lastErr error
+	for attempt := 0; attempt < proxmoxReadbackAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(proxmoxReadbackInterval):
+			}
+		}
+		current, err := getProxmoxProxyCertificate(ctx, record)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if current == nil {
+			return errors.New("Proxmox VE 节点回读结果中缺少自定义证书")
+		}
+		if normalizeProxmoxFingerprint(current.Fingerprint) != expectedFingerprint {
+			return errors.New("Proxmox VE 节点回读证书指纹与提交证书不一致")
+		}
+		return nil
+	}
+	return fmt.Errorf("回读 Proxmox VE 节点证书信息失败: %w", lastErr)
+}
+
+// normalizeProxmoxFingerprint 把冒号分隔的大写指纹转换为 LeafCertificateSHA256 使用的小写十六进制。
+func normalizeProxmoxFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+}
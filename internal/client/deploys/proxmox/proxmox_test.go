@@ -0,0 +1,155 @@
+package proxmox
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// fakePVE 模拟 Proxmox VE 节点列表、证书信息和自定义证书上传接口。
+type fakePVE struct {
+	mu           sync.Mutex
+	fingerprints map[string]string
+	uploads      []map[string]string
+	tamper       bool
+}
+
+// ServeHTTP 校验 API Token 后分发模拟请求。
+func (f *fakePVE) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "PVEAPIToken=anssl@pve!deploy=secret" {
+		http.Error(w, "authentication failure", http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api2/json/nodes":
+		writePVEData(w, []map[string]string{{"node": "pve2", "status": "offline"}, {"node": "pve1", "status": "online"}})
+	case r.Method == http.MethodGet && r.URL.Path == "/api2/json/nodes/pve1/certificates/info":
+		certificates := []map[string]string{{"filename": "pve-ssl.pem", "fingerprint": "00:11"}}
+		if fingerprint := f.fingerprints["pve1"]; fingerprint != "" {
+			certificates = append(certificates, map[string]string{"filename": proxmoxProxyCertFile, "fingerprint": fingerprint})
+		}
+		writePVEData(w, certificates)
+	case r.Method == http.MethodPost && r.URL.Path == "/api2/json/nodes/pve1/certificates/custom":
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.uploads = append(f.uploads, map[string]string{"force": r.PostForm.Get("force"), "restart": r.PostForm.Get("restart"), "key": r.PostForm.Get("key")})
+		block, _ := pem.Decode([]byte(r.PostForm.Get("certificates")))
+		digest := sha256.Sum256(block.Bytes)
+		if f.tamper {
+			digest[0] ^= 0xff
+		}
+		parts := make([]string, 0, len(digest))
+		for _, value := range digest {
+			parts = append(parts, fmt.Sprintf("%02X", value))
+		}
+		f.fingerprints["pve1"] = strings.Join(parts, ":")
+		writePVEData(w, map[string]string{"filename": proxmoxProxyCertFile})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// TestDeployCertificateToProxmoxNodeUploadsOnceAndVerifies 验证节点按名称排序发现、上传时强制替换并重启 pveproxy，相同证书不重复上传。
+func TestDeployCertificateToProxmoxNodeUploadsOnceAndVerifies(t *testing.T) {
+	pve := &fakePVE{fingerprints: map[string]string{}}
+	server := httptest.NewServer(pve)
+	defer server.Close()
+	ctx := proxmoxTestContext(server.URL)
+
+	resources, err := DiscoverProxmoxNodeResources(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverProxmoxNodeResources: %v", err)
+	}
+	if len(resources) != 2 || resources[0].Label != "pve1" || resources[0].Cluster != "lab" || resources[1].Status != "offline" || !strings.HasPrefix(resources[0].TargetRef, proxmoxTargetPrefix) {
+		t.Fatalf("发现的节点不匹配: %+v", resources)
+	}
+	if resources[0].TargetRef != buildProxmoxNodeTargetRef(server.URL+"/", "pve1") {
+		t.Fatal("节点 targetRef 应只由 API 地址和节点名称决定")
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "pve1.example.com")
+	for range 2 {
+		if err := DeployCertificateToProxmoxNode(ctx, resources[0].TargetRef, "pve1.example.com", certificatePEM, privateKeyPEM); err != nil {
+			t.Fatalf("DeployCertificateToProxmoxNode: %v", err)
+		}
+	}
+	if len(pve.uploads) != 1 || pve.uploads[0]["force"] != "1" || pve.uploads[0]["restart"] != "1" || pve.uploads[0]["key"] != privateKeyPEM {
+		t.Fatalf("上传参数不匹配或相同证书被重复上传: %+v", pve.uploads)
+	}
+
+	err = DeployCertificateToProxmoxNode(ctx, resources[1].TargetRef, "pve1.example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !IsProxmoxErrorRetryable(err) {
+		t.Fatalf("离线节点应返回可重试错误: %v", err)
+	}
+}
+
+// TestDeployCertificateToProxmoxNodeRejectsFingerprintMismatch 验证节点回读指纹不一致时部署失败。
+func TestDeployCertificateToProxmoxNodeRejectsFingerprintMismatch(t *testing.T) {
+	pve := &fakePVE{fingerprints: map[string]string{}, tamper: true}
+	server := httptest.NewServer(pve)
+	defer server.Close()
+	ctx := proxmoxTestContext(server.URL)
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "pve1.example.com")
+	err := DeployCertificateToProxmoxNode(ctx, buildProxmoxNodeTargetRef(server.URL, "pve1"), "pve1.example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "指纹") {
+		t.Fatalf("指纹不一致时应部署失败: %v", err)
+	}
+}
+
+// proxmoxTestContext 返回只包含一个 Proxmox VE 集群配置的操作 context。
+func proxmoxTestContext(apiURL string) context.Context {
+	cluster := &config.ProxmoxConfig{Name: "lab", URL: apiURL, TokenID: "anssl@pve!deploy", TokenSecret: "secret"}
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{Proxmox: []*config.ProxmoxConfig{cluster}}}})
+}
+
+// writePVEData 写入 Proxmox VE 成功响应包络。
+func writePVEData(w http.ResponseWriter, data any) {
+	json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+// generateTestCertificatePair 生成 Proxmox VE 测试使用的自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -0,0 +1,40 @@
+package proxmox
+
+import (
+	"encoding/json"
+
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// ProxmoxNodeResource 是可以安全上报到 anSSL 后端的 Proxmox VE 节点资源。
+type ProxmoxNodeResource struct {
+	TargetRef string // TargetRef 是客户端根据 API 地址和节点名称生成的不透明稳定引用。
+	Label     string // Label 是节点名称。
+	Cluster   string // Cluster 是配置中的集群名称。
+	Status    string // Status 是节点当前的 online 或 offline 状态。
+}
+
+// proxmoxEnvelope 描述 Proxmox VE API 的统一响应包络。
+type proxmoxEnvelope struct {
+	Data   json.RawMessage   `json:"data"`   // Data 是各接口自己的响应数据。
+	Errors map[string]string `json:"errors"` // Errors 是参数校验失败时的字段错误。
+}
+
+// proxmoxNode 描述 /nodes 列表中的单个节点。
+type proxmoxNode struct {
+	Node   string `json:"node"`   // Node 是节点名称。
+	Status string `json:"status"` // Status 是 online、offline 或 unknown。
+}
+
+// proxmoxCertificateInfo 描述节点证书信息接口中的单个证书文件。
+type proxmoxCertificateInfo struct {
+	Filename    string `json:"filename"`    // Filename 是证书文件名，pveproxy-ssl.pem 为自定义 Web 证书。
+	Fingerprint string `json:"fingerprint"` // Fingerprint 是冒号分隔的 SHA-256 指纹。
+}
+
+// proxmoxNodeRecord 在 deploy 内部关联脱敏资源和真实集群配置。
+type proxmoxNodeRecord struct {
+	Cluster  *config.ProxmoxConfig // Cluster 是节点所在集群的 API 配置。
+	Node     string                // Node 是 API 路径中使用的节点名称。
+	Resource ProxmoxNodeResource   // Resource 是可以上报的脱敏资源。
+}
@@ -0,0 +1,159 @@
+package proxyserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+	"go.yaml.in/yaml/v3"
+)
+
+// proxyCertificatePair 是服务配置文件中引用的一组证书和私钥路径。
+type proxyCertificatePair struct {
+	CertFile string // CertFile 是证书链文件绝对路径。
+	KeyFile  string // KeyFile 是私钥文件绝对路径。
+}
+
+// locateProxyCertificatePairs 读取服务配置文件，按类型找出全部证书和私钥路径并去重。
+func locateProxyCertificatePairs(server *config.ProxyServerConfig) ([]proxyCertificatePair, error) {
+	content, err := os.ReadFile(server.Config)
+	if err != nil {
+		return nil, fmt.Errorf("读取代理服务配置文件失败: %w", err)
+	}
+	var document any
+	// Xray 配置常用制表符缩进，YAML 解析器会拒绝，JSON 文件单独解析。
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(trimmed, &document)
+	} else {
+		err = yaml.Unmarshal(content, &document)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析代理服务配置文件 %s 失败: %w", server.Config, err)
+	}
+
+	var pairs []proxyCertificatePair
+	switch server.Kind {
+	case config.ProxyServerKindXray, config.ProxyServerKindV2Ray:
+		// 证书可能出现在任意入站的 tlsSettings、xtlsSettings 或 v5 格式的 securitySettings 中。
+		pairs = collectProxyCertificateFiles(document, pairs)
+	case config.ProxyServerKindTrojanGo:
+		pairs = appendProxyCertificatePair(pairs, proxyConfigSection(document, "ssl"), "cert", "key")
+	case config.ProxyServerKindHysteria:
+		pairs = appendProxyCertificatePair(pairs, proxyConfigSection(document, "tls"), "cert", "key")
+		// Hysteria 1 的 JSON 配置把 cert 和 key 放在顶层。
+		pairs = appendProxyCertificatePair(pairs, document, "cert", "key")
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("代理服务配置文件 %s 中未找到证书和私钥文件路径", server.Config)
+	}
+
+	seen := make(map[proxyCertificatePair]struct{}, len(pairs))
+	unique := make([]proxyCertificatePair, 0, len(pairs))
+	for _, pair := range pairs {
+		if !filepath.IsAbs(pair.CertFile) || !filepath.IsAbs(pair.KeyFile) {
+			return nil, fmt.Errorf("代理服务证书路径必须是绝对路径: %s, %s", pair.CertFile, pair.KeyFile)
+		}
+		pair.CertFile, pair.KeyFile = filepath.Clean(pair.CertFile), filepath.Clean(pair.KeyFile)
+		if pair.CertFile == pair.KeyFile {
+			return nil, fmt.Errorf("代理服务证书和私钥不能是同一个文件: %s", pair.CertFile)
+		}
+		if _, exists := seen[pair]; exists {
+			continue
+		}
+		seen[pair] = struct{}{}
+		unique = append(unique, pair)
+	}
+	sort.Slice(unique, func(i, j int) bool { return unique[i].CertFile < unique[j].CertFile })
+	return unique, nil
+}
+
+// collectProxyCertificateFiles 递归查找同时包含 certificateFile 和 keyFile 的对象。
+func collectProxyCertificateFiles(node any, pairs []proxyCertificatePair) []proxyCertificatePair {
+	switch value := node.(type) {
+	case map[string]any:
+		pairs = appendProxyCertificatePair(pairs, value, "certificateFile", "keyFile")
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			pairs = collectProxyCertificateFiles(value[key], pairs)
+		}
+	case []any:
+		for _, item := range value {
+			pairs = collectProxyCertificateFiles(item, pairs)
+		}
+	}
+	return pairs
+}
+
+// proxyConfigSection 返回顶层对象中的指定子对象，不存在时返回 nil。
+func proxyConfigSection(document any, name string) any {
+	object, ok := document.(map[string]any)
+	if !ok {
+		return nil
+	}
+	return object[name]
+}
+
+// appendProxyCertificatePair 在对象同时包含非空的证书和私钥字段时追加一组路径。
+func appendProxyCertificatePair(pairs []proxyCertificatePair, node any, certKey, keyKey string) []proxyCertificatePair {
+	object, ok := node.(map[string]any)
+	if !ok {
+		return pairs
+	}
+	certFile, _ := object[certKey].(string)
+	keyFile, _ := object[keyKey].(string)
+	if strings.TrimSpace(certFile) == "" || strings.TrimSpace(keyFile) == "" {
+		return pairs
+	}
+	return append(pairs, proxyCertificatePair{CertFile: strings.TrimSpace(certFile), KeyFile: strings.TrimSpace(keyFile)})
+}
+
+// selectProxyCertificatePairs 只有一组路径时直接使用；多组时只替换当前证书已覆盖部署域名的路径，且必须位于同一目录。
+func selectProxyCertificatePairs(pairs []proxyCertificatePair, domain string) ([]proxyCertificatePair, string, error) {
+	selected := pairs
+	if len(pairs) > 1 {
+		selected = nil
+		for _, pair := range pairs {
+			if proxyCertificateCoversDomain(pair.CertFile, domain) {
+				selected = append(selected, pair)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, "", fmt.Errorf("代理服务配置中有 %d 组证书路径，且没有一组的现有证书覆盖 %s，无法确定替换目标", len(pairs), domain)
+		}
+	}
+	directory := filepath.Dir(selected[0].CertFile)
+	for _, pair := range selected {
+		if filepath.Dir(pair.CertFile) != directory || filepath.Dir(pair.KeyFile) != directory {
+			return nil, "", fmt.Errorf("代理服务证书和私钥必须位于同一目录: %s, %s", pair.CertFile, pair.KeyFile)
+		}
+	}
+	if directory == string(filepath.Separator) {
+		return nil, "", fmt.Errorf("代理服务证书不能直接放在根目录")
+	}
+	return selected, directory, nil
+}
+
+// proxyCertificateCoversDomain 判断现有证书文件是否已覆盖部署域名，文件不存在或无法解析时返回 false。
+func proxyCertificateCoversDomain(certFile, domain string) bool {
+	leaf, err := shared.ParseLeafCertificate(certFile)
+	if err != nil {
+		return false
+	}
+	normalized := shared.NormalizeCertificateDomain(domain)
+	for _, name := range leaf.DNSNames {
+		if shared.NormalizeCertificateDomain(name) == normalized {
+			return true
+		}
+	}
+	return !strings.HasPrefix(normalized, "*.") && leaf.VerifyHostname(normalized) == nil
+}
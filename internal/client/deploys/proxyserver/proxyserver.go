@@ -0,0 +1,333 @@
+package proxyserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	proxyServerTargetPrefix = "proxy-server-"
+	proxyCommandTimeout     = 30 * time.Second
+	proxyRollbackTimeout    = 30 * time.Second
+	// ProxyServerStatusReady 表示实例已配置，部署时从服务配置文件中定位证书路径。
+	ProxyServerStatusReady = "Ready"
+)
+
+var (
+	// systemctlCommand 允许测试替换 systemctl 可执行文件，生产环境始终从 PATH 查找。
+	systemctlCommand = "systemctl"
+	// proxySettleDelay 是重启后确认单元仍处于 active 前的等待时间，测试可以缩短。
+	proxySettleDelay = 2 * time.Second
+)
+
+// ProxyServerResource 是可以安全上报到 anSSL 后端的代理服务实例资源。
+type ProxyServerResource struct {
+	TargetRef string // TargetRef 是客户端根据实例名称生成的不透明稳定引用。
+	Label     string // Label 是配置中的实例名称。
+	Kind      string // Kind 是 xray、v2ray、trojan-go 或 hysteria。
+	Status    string // Status 是实例状态。
+}
+
+// IsProxyServerConfiguredWithContext 从 context 快照判断是否配置了代理服务实例。
+func IsProxyServerConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && len(configuration.SSL.ProxyServers) > 0
+}
+
+// DiscoverProxyServerResources 把配置中的每个具名代理服务列为一个部署资源。
+func DiscoverProxyServerResources(ctx context.Context) ([]ProxyServerResource, error) {
+	servers, err := getProxyServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]ProxyServerResource, 0, len(servers))
+	for _, server := range servers {
+		resources = append(resources, ProxyServerResource{
+			TargetRef: buildProxyServerTargetRef(server.Name),
+			Label:     server.Name,
+			Kind:      server.Kind,
+			Status:    ProxyServerStatusReady,
+		})
+	}
+	return resources, nil
+}
+
+// TestProxyServerConnection 检查配置文件中的证书路径可定位、证书目录可写，并执行服务自带的配置校验，不重启服务。
+func TestProxyServerConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	server, err := findProxyServer(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	pairs, err := locateProxyCertificatePairs(server)
+	if err != nil {
+		return err
+	}
+	directories := make(map[string]struct{}, len(pairs))
+	for _, pair := range pairs {
+		directories[filepath.Dir(pair.CertFile)] = struct{}{}
+		directories[filepath.Dir(pair.KeyFile)] = struct{}{}
+	}
+	for directory := range directories {
+		// 发布时会在父目录中创建暂存目录并整体替换证书目录。
+		probe, err := os.CreateTemp(filepath.Dir(directory), ".anssl-probe-*")
+		if err != nil {
+			return fmt.Errorf("代理服务证书目录的上级目录不可写: %w", err)
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+	}
+	return testProxyServerConfig(ctx, server)
+}
+
+// DeployCertificateToProxyServer 替换服务配置引用的证书文件，在同一发布事务中执行配置校验并重启 systemd 单元，失败时恢复旧文件。
+func DeployCertificateToProxyServer(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	server, err := findProxyServer(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	pairs, err := locateProxyCertificatePairs(server)
+	if err != nil {
+		return err
+	}
+	selected, certDir, err := selectProxyCertificatePairs(pairs, domain)
+	if err != nil {
+		return err
+	}
+
+	// 暂存目录与证书目录同级，发布时同文件系统重命名可以保留属主。
+	if err := os.MkdirAll(filepath.Dir(certDir), 0755); err != nil {
+		return fmt.Errorf("创建代理服务证书上级目录失败: %w", err)
+	}
+	stagingDir, err := os.MkdirTemp(filepath.Dir(certDir), ".anssl-proxy-*")
+	if err != nil {
+		return fmt.Errorf("创建代理服务临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	if err := stageProxyCertificateDir(certDir, stagingDir); err != nil {
+		return err
+	}
+	for _, pair := range selected {
+		if err := writeStagedProxyFile(stagingDir, pair.CertFile, []byte(certificatePEM), 0o644); err != nil {
+			return fmt.Errorf("写入证书文件失败: %w", err)
+		}
+		if err := writeStagedProxyFile(stagingDir, pair.KeyFile, []byte(privateKeyPEM), 0o600); err != nil {
+			return fmt.Errorf("写入私钥文件失败: %w", err)
+		}
+	}
+
+	restarted := false
+	err = shared.PublishDirectoryWithValidationContext(ctx, stagingDir, certDir, func() error {
+		if err := testProxyServerConfig(ctx, server); err != nil {
+			return err
+		}
+		restarted = true
+		return restartProxyServer(ctx, server)
+	})
+	if err != nil {
+		if restarted {
+			// 发布事务已恢复旧证书文件，再次重启让服务回到旧证书。
+			rollbackContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), proxyRollbackTimeout)
+			defer cancel()
+			if restartErr := runProxyCommand(rollbackContext, systemctlCommand, "restart", server.Service); restartErr != nil {
+				logger.Warn("恢复旧证书后重启代理服务失败", "service", server.Service, "error", restartErr)
+			}
+		}
+		return fmt.Errorf("代理服务 %s 证书部署失败: %w", server.Name, err)
+	}
+	logger.Info("代理服务证书已更新", "name", server.Name, "path", certDir, "service", server.Service)
+	return nil
+}
+
+// stageProxyCertificateDir 把证书目录中的现有文件连同权限和属主复制到暂存目录，拒绝包含子目录的目录。
+func stageProxyCertificateDir(certDir, stagingDir string) error {
+	info, err := os.Stat(certDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.Chmod(stagingDir, 0o755)
+	}
+	if err != nil {
+		return fmt.Errorf("读取代理服务证书目录失败: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("代理服务证书路径 %s 不是目录", certDir)
+	}
+	if err := applyProxyFileIdentity(stagingDir, info); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(certDir)
+	if err != nil {
+		return fmt.Errorf("读取代理服务证书目录失败: %w", err)
+	}
+	for _, entry := range entries {
+		source := filepath.Join(certDir, entry.Name())
+		target := filepath.Join(stagingDir, entry.Name())
+		entryInfo, err := os.Lstat(source)
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %w", source, err)
+		}
+		switch {
+		case entryInfo.Mode().IsRegular():
+			content, err := os.ReadFile(source)
+			if err != nil {
+				return fmt.Errorf("读取 %s 失败: %w", source, err)
+			}
+			if err := os.WriteFile(target, content, entryInfo.Mode().Perm()); err != nil {
+				return fmt.Errorf("复制 %s 失败: %w", source, err)
+			}
+			if err := applyProxyFileIdentity(target, entryInfo); err != nil {
+				return err
+			}
+		case entryInfo.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(source)
+			if err != nil {
+				return fmt.Errorf("读取符号链接 %s 失败: %w", source, err)
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return fmt.Errorf("复制符号链接 %s 失败: %w", source, err)
+			}
+		default:
+			// 整个目录会被替换，只处理证书专用目录，避免误把 /etc 这类系统目录当作发布目标。
+			return fmt.Errorf("代理服务证书目录 %s 包含子目录或特殊文件 %s，请把证书和私钥放到独立目录", certDir, entry.Name())
+		}
+	}
+	return nil
+}
+
+// writeStagedProxyFile 在暂存目录中写入新文件，已有文件沿用原权限和属主，新文件使用默认权限。
+func writeStagedProxyFile(stagingDir, path string, content []byte, defaultMode os.FileMode) error {
+	target := filepath.Join(stagingDir, filepath.Base(path))
+	info, err := os.Lstat(target)
+	if err == nil && info.Mode()&fs.ModeSymlink != 0 {
+		// 证书文件本身是符号链接时替换为普通文件，不改写链接指向的其他位置。
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+		info, err = nil, fs.ErrNotExist
+	}
+	mode := defaultMode
+	if err == nil {
+		mode = info.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.WriteFile(target, content, mode); err != nil {
+		return err
+	}
+	return os.Chmod(target, mode)
+}
+
+// applyProxyFileIdentity 让暂存文件沿用原文件的权限和属主。
+func applyProxyFileIdentity(path string, info os.FileInfo) error {
+	if err := os.Chmod(path, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("设置 %s 权限失败: %w", path, err)
+	}
+	uid, gid, ok := shared.FileOwnership(info)
+	if !ok || (uid == os.Getuid() && gid == os.Getgid()) {
+		return nil
+	}
+	if err := os.Lchown(path, uid, gid); err != nil {
+		return fmt.Errorf("设置 %s 属主失败: %w", path, err)
+	}
+	return nil
+}
+
+// testProxyServerConfig 执行 Xray 和 V2Ray 自带的配置校验，Trojan-Go 和 Hysteria 没有校验命令，改由重启后的单元状态确认。
+func testProxyServerConfig(ctx context.Context, server *config.ProxyServerConfig) error {
+	var args []string
+	switch server.Kind {
+	case config.ProxyServerKindXray:
+		args = []string{"run", "-test", "-c", server.Config}
+	case config.ProxyServerKindV2Ray:
+		args = []string{"test", "-c", server.Config}
+	default:
+		return nil
+	}
+	if err := runProxyCommand(ctx, server.Binary, args...); err != nil {
+		return fmt.Errorf("%s 配置校验失败: %w", server.Kind, err)
+	}
+	return nil
+}
+
+// restartProxyServer 重启 systemd 单元，等待片刻后确认服务没有因新证书退出。
+func restartProxyServer(ctx context.Context, server *config.ProxyServerConfig) error {
+	if err := runProxyCommand(ctx, systemctlCommand, "restart", server.Service); err != nil {
+		return fmt.Errorf("重启 %s 失败: %w", server.Service, err)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(proxySettleDelay):
+	}
+	if err := runProxyCommand(ctx, systemctlCommand, "is-active", "--quiet", server.Service); err != nil {
+		return fmt.Errorf("%s 重启后未处于运行状态: %w", server.Service, err)
+	}
+	return nil
+}
+
+// runProxyCommand 在独立超时内执行命令，失败时附带命令输出。
+func runProxyCommand(parent context.Context, command string, args ...string) error {
+	ctx, cancel := context.WithTimeout(parent, proxyCommandTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, command, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// getProxyServers 读取当前操作快照中的代理服务实例配置。
+func getProxyServers(ctx context.Context) ([]*config.ProxyServerConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || len(configuration.SSL.ProxyServers) == 0 {
+		return nil, errors.New("未配置代理服务实例 (ssl.proxyServers)")
+	}
+	return configuration.SSL.ProxyServers, nil
+}
+
+// findProxyServer 根据 targetRef 重新定位配置中的代理服务实例，实例改名或删除后引用失效。
+func findProxyServer(ctx context.Context, targetRef string) (*config.ProxyServerConfig, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("代理服务实例 targetRef 不能为空")
+	}
+	servers, err := getProxyServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, server := range servers {
+		if buildProxyServerTargetRef(server.Name) == targetRef {
+			return server, nil
+		}
+	}
+	return nil, errors.New("代理服务实例不存在或已改名，请重新配置部署目标")
+}
+
+// buildProxyServerTargetRef 根据实例名称生成稳定的不透明引用。
+func buildProxyServerTargetRef(name string) string {
+	identity := strings.Join([]string{"ansslCli", "DEPLOYMENT_TYPE_ANSSL_CLI_PROXY_SERVER_CERT", name}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return proxyServerTargetPrefix + hex.EncodeToString(digest[:12])
+}
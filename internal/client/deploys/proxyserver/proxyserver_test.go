@@ -0,0 +1,164 @@
+package proxyserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestDeployCertificateToProxyServerReplacesMatchingXrayCertificate 验证在 Xray 多组证书中只替换覆盖部署域名的一组，并按顺序执行配置校验、重启和状态确认。
+func TestDeployCertificateToProxyServerReplacesMatchingXrayCertificate(t *testing.T) {
+	root := t.TempDir()
+	stateDir := installFakeProxyCommands(t)
+	certDir := filepath.Join(root, "xray", "certs")
+	oldCertificate, oldKey := generateTestCertificatePair(t, "example.com")
+	otherCertificate, otherKey := generateTestCertificatePair(t, "other.example.org")
+	writeTestFile(t, filepath.Join(certDir, "example.crt"), oldCertificate, 0o640)
+	writeTestFile(t, filepath.Join(certDir, "example.key"), oldKey, 0o640)
+	writeTestFile(t, filepath.Join(certDir, "other.crt"), otherCertificate, 0o644)
+	writeTestFile(t, filepath.Join(certDir, "other.key"), otherKey, 0o600)
+	configFile := filepath.Join(root, "xray", "config.json")
+	writeTestFile(t, configFile, "{\n\t\"inbounds\": [\n"+
+		"\t\t{\"streamSettings\": {\"tlsSettings\": {\"certificates\": [{\"certificateFile\": \""+filepath.Join(certDir, "other.crt")+"\", \"keyFile\": \""+filepath.Join(certDir, "other.key")+"\"}]}}},\n"+
+		"\t\t{\"streamSettings\": {\"tlsSettings\": {\"certificates\": [{\"certificateFile\": \""+filepath.Join(certDir, "example.crt")+"\", \"keyFile\": \""+filepath.Join(certDir, "example.key")+"\"}]}}}\n"+
+		"\t]\n}\n", 0o644)
+	server := &config.ProxyServerConfig{Name: "edge", Kind: config.ProxyServerKindXray, Config: configFile, Service: "xray", Binary: filepath.Join(stateDir, "xray")}
+	ctx := proxyTestContext(server)
+
+	resources, err := DiscoverProxyServerResources(ctx)
+	if err != nil || len(resources) != 1 || resources[0].Label != "edge" || resources[0].Kind != "xray" || !strings.HasPrefix(resources[0].TargetRef, proxyServerTargetPrefix) {
+		t.Fatalf("发现的代理服务资源不匹配: %+v err=%v", resources, err)
+	}
+	if err := TestProxyServerConnection(ctx, resources[0].TargetRef); err != nil {
+		t.Fatalf("TestProxyServerConnection: %v", err)
+	}
+	os.Remove(filepath.Join(stateDir, "calls"))
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "example.com")
+	if err := DeployCertificateToProxyServer(ctx, resources[0].TargetRef, "example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToProxyServer: %v", err)
+	}
+	if readTestFile(t, filepath.Join(certDir, "example.crt")) != certificatePEM || readTestFile(t, filepath.Join(certDir, "example.key")) != privateKeyPEM {
+		t.Fatal("覆盖部署域名的证书路径应被替换")
+	}
+	if readTestFile(t, filepath.Join(certDir, "other.crt")) != otherCertificate || readTestFile(t, filepath.Join(certDir, "other.key")) != otherKey {
+		t.Fatal("其他域名的证书文件应保持不变")
+	}
+	if info, err := os.Stat(filepath.Join(certDir, "example.key")); err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("替换后的私钥应沿用原权限: info=%v err=%v", info, err)
+	}
+	expectedCalls := "xray run -test -c " + configFile + "\nsystemctl restart xray\nsystemctl is-active --quiet xray\n"
+	if calls := readTestFile(t, filepath.Join(stateDir, "calls")); calls != expectedCalls {
+		t.Fatalf("命令调用顺序不匹配:\n%s", calls)
+	}
+}
+
+// TestDeployCertificateToProxyServerRollsBackHysteriaWhenUnitStops 验证 Hysteria 重启后单元未运行时恢复旧证书并再次重启。
+func TestDeployCertificateToProxyServerRollsBackHysteriaWhenUnitStops(t *testing.T) {
+	root := t.TempDir()
+	stateDir := installFakeProxyCommands(t)
+	certDir := filepath.Join(root, "hysteria")
+	writeTestFile(t, filepath.Join(certDir, "server.crt"), "old-certificate", 0o644)
+	writeTestFile(t, filepath.Join(certDir, "server.key"), "old-key", 0o600)
+	configFile := filepath.Join(certDir, "config.yaml")
+	writeTestFile(t, configFile, "listen: :443\ntls:\n  cert: "+filepath.Join(certDir, "server.crt")+"\n  key: "+filepath.Join(certDir, "server.key")+"\n", 0o644)
+	writeTestFile(t, filepath.Join(stateDir, "fail-active"), "", 0o644)
+	server := &config.ProxyServerConfig{Name: "hy2", Kind: config.ProxyServerKindHysteria, Config: configFile, Service: "hysteria-server", Binary: "hysteria"}
+	ctx := proxyTestContext(server)
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "example.com")
+	err := DeployCertificateToProxyServer(ctx, buildProxyServerTargetRef("hy2"), "example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "未处于运行状态") {
+		t.Fatalf("单元未运行时应返回错误: %v", err)
+	}
+	if readTestFile(t, filepath.Join(certDir, "server.crt")) != "old-certificate" || readTestFile(t, filepath.Join(certDir, "server.key")) != "old-key" {
+		t.Fatal("旧证书文件未恢复")
+	}
+	if !strings.HasPrefix(readTestFile(t, configFile), "listen: :443") {
+		t.Fatal("同目录中的配置文件应保持不变")
+	}
+	expectedCalls := "systemctl restart hysteria-server\nsystemctl is-active --quiet hysteria-server\nsystemctl restart hysteria-server\n"
+	if calls := readTestFile(t, filepath.Join(stateDir, "calls")); calls != expectedCalls {
+		t.Fatalf("恢复旧证书后应再次重启:\n%s", calls)
+	}
+}
+
+// installFakeProxyCommands 安装模拟 xray 和 systemctl：记录每次调用，fail-active 文件存在时 is-active 失败。
+func installFakeProxyCommands(t *testing.T) string {
+	t.Helper()
+	stateDir := t.TempDir()
+	for _, name := range []string{"xray", "systemctl"} {
+		script := "#!/bin/sh\n" +
+			"echo \"" + name + " $*\" >> '" + stateDir + "/calls'\n" +
+			"if [ \"$1\" = is-active ] && [ -f '" + stateDir + "/fail-active' ]; then exit 3; fi\n"
+		writeTestFile(t, filepath.Join(stateDir, name), script, 0o755)
+	}
+	originalSystemctl, originalDelay := systemctlCommand, proxySettleDelay
+	systemctlCommand, proxySettleDelay = filepath.Join(stateDir, "systemctl"), 0
+	t.Cleanup(func() { systemctlCommand, proxySettleDelay = originalSystemctl, originalDelay })
+	return stateDir
+}
+
+// proxyTestContext 返回只包含代理服务实例配置的操作 context。
+func proxyTestContext(servers ...*config.ProxyServerConfig) context.Context {
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{ProxyServers: servers}}})
+}
+
+// writeTestFile 创建父目录并按指定权限写入测试文件。
+func writeTestFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// readTestFile 读取测试文件内容。
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(content)
+}
+
+// generateTestCertificatePair 生成代理服务测试使用的自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -224,14 +224,40 @@ func (executor *Executor) Run(command string, input []byte, privileged bool) ([]
 
 // RunContext 执行 SSH 命令，并在取消或硬超时后关闭会话并等待其退出。
 func (executor *Executor) RunContext(ctx context.Context, command string, input []byte, privileged bool) ([]byte, error) {
+	return executor.RunWithTimeoutContext(ctx, command, input, privileged, sshCommandTimeout)
+}
+
+// RunWithTimeoutContext 使用调用方指定的硬超时执行 SSH 命令，适用于用户配置的校验和重载命令。
+func (executor *Executor) RunWithTimeoutContext(ctx context.Context, command string, input []byte, privileged bool, timeout time.Duration) ([]byte, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if timeout <= 0 {
+		timeout = sshCommandTimeout
+	}
 	if privileged && !executor.isRoot {
 		command = "sudo -S -p '' sh -c " + QuotePOSIXShellArg(command)
 		input = append([]byte(executor.sudoPassword+"\n"), input...)
 	}
-	return runSSHCommandContext(ctx, executor.client, command, input, sshCommandTimeout)
+	return runSSHCommandContext(ctx, executor.client, command, input, timeout)
+}
+
+// NeedsPrivilegeForPathContext 判断创建或替换目标路径是否需要 root/sudo 权限。
+func (executor *Executor) NeedsPrivilegeForPathContext(ctx context.Context, targetPath string) (bool, error) {
+	if executor.IsRoot() {
+		return false, nil
+	}
+	command := "target=" + QuotePOSIXShellArg(targetPath) + "; " +
+		"while [ ! -e \"$target\" ]; do parent=$(dirname -- \"$target\"); " +
+		"[ \"$parent\" != \"$target\" ] || exit 1; target=$parent; done; " +
+		"test -d \"$target\" && test -w \"$target\""
+	if _, err := executor.RunContext(ctx, command, nil, false); err == nil {
+		return false, nil
+	}
+	if _, err := executor.RunContext(ctx, "true", nil, true); err != nil {
+		return false, fmt.Errorf("当前 SSH 用户无目录写入权限且无法 sudo: %w", err)
+	}
+	return true, nil
 }
 
 // runSSHCommandContext 在调用方取消或硬超时后主动关闭会话，并等待执行 goroutine 收敛。
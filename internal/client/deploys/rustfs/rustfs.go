@@ -63,7 +63,7 @@ func testRemoteRustFSConnection(ctx context.Context, rustFS *config.RustFSConfig
 			return fmt.Errorf("RustFS SSH 环境缺少命令 %s: %w", command, err)
 		}
 	}
-	privileged, err := needsPrivilegeForPath(ctx, executor, rustFS.Path)
+	privileged, err := executor.NeedsPrivilegeForPathContext(ctx, rustFS.Path)
 	if err != nil {
 		return fmt.Errorf("检查 RustFS 远程目录权限失败: %w", err)
 	}
@@ -189,31 +189,13 @@ func DeployRemote(ctx context.Context, sourceDir, safeDomain string, rustFS *con
 	return nil
 }
 
-// needsPrivilegeForPath 判断创建或替换目标路径是否需要 root/sudo 权限。
-func needsPrivilegeForPath(ctx context.Context, executor *remote.Executor, targetPath string) (bool, error) {
-	if executor.IsRoot() {
-		return false, nil
-	}
-	command := "target=" + remote.QuotePOSIXShellArg(targetPath) + "; " +
-		"while [ ! -e \"$target\" ]; do parent=$(dirname -- \"$target\"); " +
-		"[ \"$parent\" != \"$target\" ] || exit 1; target=$parent; done; " +
-		"test -d \"$target\" && test -w \"$target\""
-	if _, err := executor.RunContext(ctx, command, nil, false); err == nil {
-		return false, nil
-	}
-	if _, err := executor.RunContext(ctx, "true", nil, true); err != nil {
-		return false, fmt.Errorf("当前 SSH 用户无目录写入权限且无法 sudo: %w", err)
-	}
-	return true, nil
-}
-
 // publishCertificate 在远端同级目录中暂存并带回滚地替换域名证书目录。
 func publishCertificate(ctx context.Context, executor *remote.Executor, remoteCertificateFile, remotePrivateKeyFile, basePath, safeDomain string) error {
 	targetDir := path.Join(basePath, safeDomain)
 	token := strconv.FormatInt(time.Now().UnixNano(), 10)
 	stagingDir := path.Join(basePath, "."+safeDomain+".anssl-stage."+token)
 	backupDir := path.Join(basePath, "."+safeDomain+".anssl-backup."+token)
-	privileged, err := needsPrivilegeForPath(ctx, executor, basePath)
+	privileged, err := executor.NeedsPrivilegeForPathContext(ctx, basePath)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,123 @@
+package s3server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+	"github.com/https-cert/deploy/pkg/sigv4"
+)
+
+const (
+	s3AdminRequestTimeout = 15 * time.Second
+)
+
+var (
+	// s3RestartTimeout 是管理 API 重启后等待服务提供新证书的最长时间，实际等待还受操作截止时间限制，测试中可以缩短。
+	s3RestartTimeout = 30 * time.Second
+	// s3VerifyInterval 是等待服务重启时两次探测之间的间隔。
+	s3VerifyInterval = 2 * time.Second
+)
+
+// restartMinIOService 调用 MinIO 管理 API 重启服务，等价于 mc admin service restart。
+func restartMinIOService(ctx context.Context, server *config.S3ServerConfig) error {
+	requestContext, cancel := context.WithTimeout(ctx, s3AdminRequestTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(requestContext, http.MethodPost, strings.TrimRight(server.AdminURL, "/")+"/minio/admin/v3/service?action=restart", nil)
+	if err != nil {
+		return err
+	}
+	sigv4.SignRequest(request, nil, server.AccessKey, server.SecretKey, server.Region, "s3", time.Now())
+	response, err := newS3AdminClient(server).Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	logger.Info("已通过管理 API 重启对象存储服务", "name", server.Name)
+	return nil
+}
+
+// waitS3ServerReady 等待服务重启完成；fingerprint 非空且管理地址为 https 时要求握手证书与新证书一致。
+// 部署时的等待为回滚预留操作剩余时间，回滚时 fingerprint 为空，可以用完回滚 context 的全部时间。
+func waitS3ServerReady(ctx context.Context, server *config.S3ServerConfig, domain, fingerprint string) error {
+	reserve := time.Duration(0)
+	if fingerprint != "" {
+		reserve = s3RollbackTimeout
+	}
+	deadline := time.Now().Add(providers.OperationWaitTimeout(ctx, s3RestartTimeout, reserve))
+	var err error
+	if fingerprint != "" && strings.HasPrefix(server.AdminURL, "https://") {
+		err = shared.WaitForServedCertificate(ctx, fingerprint, deadline, s3VerifyInterval, func(ctx context.Context) ([]byte, error) {
+			return fetchS3ServerLeafCertificate(ctx, server, domain)
+		})
+	} else {
+		err = shared.WaitUntil(ctx, deadline, s3VerifyInterval, func(ctx context.Context) error {
+			return checkS3ServerHealth(ctx, server)
+		})
+	}
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("等待服务重启超时: %w", err)
+	}
+	return err
+}
+
+// checkS3ServerHealth 请求 MinIO 存活检查端点，其他实现只要求返回 200。
+func checkS3ServerHealth(ctx context.Context, server *config.S3ServerConfig) error {
+	requestContext, cancel := context.WithTimeout(ctx, s3AdminRequestTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(requestContext, http.MethodGet, strings.TrimRight(server.AdminURL, "/")+"/minio/health/live", nil)
+	if err != nil {
+		return err
+	}
+	response, err := newS3AdminClient(server).Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 4096))
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("存活检查返回 HTTP %d", response.StatusCode)
+	}
+	return nil
+}
+
+// fetchS3ServerLeafCertificate 以部署域名作为 SNI 连接管理地址，返回服务端叶证书。
+func fetchS3ServerLeafCertificate(ctx context.Context, server *config.S3ServerConfig, domain string) ([]byte, error) {
+	endpoint, err := url.Parse(server.AdminURL)
+	if err != nil {
+		return nil, err
+	}
+	address := endpoint.Host
+	if endpoint.Port() == "" {
+		address = net.JoinHostPort(endpoint.Hostname(), "443")
+	}
+	dialContext, cancel := context.WithTimeout(ctx, s3AdminRequestTimeout)
+	defer cancel()
+	return shared.DialServedLeaf(dialContext, address, domain)
+}
+
+// newS3AdminClient 创建不跟随重定向的管理 API 客户端，避免签名请求被转发到其他地址。
+func newS3AdminClient(server *config.S3ServerConfig) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: server.InsecureSkipVerify, MinVersion: tls.VersionTLS12}
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,492 @@
+package s3server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/remote"
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	s3ServerTargetPrefix = "s3-server-"
+	s3ServerTempPrefix   = "anssl-s3server"
+	// s3RollbackTimeout 限制恢复旧证书并重启服务的时长；回滚 context 脱离调用方取消，保证部署被取消后旧证书仍能生效，
+	// 因此必须足够短，并在等待新证书时预留出来，使整个部署仍在单次操作超时内结束。
+	s3RollbackTimeout = 15 * time.Second
+	// S3ServerStatusReady 表示实例已配置，部署时才写入目录或建立 SSH 连接。
+	S3ServerStatusReady = "Ready"
+)
+
+// s3ServerLocks 按实例名称串行化部署，避免同一证书目录的备份和发布交错。
+var s3ServerLocks sync.Map
+
+// S3ServerResource 是可以安全上报到 anSSL 后端的对象存储服务实例资源。
+type S3ServerResource struct {
+	TargetRef string // TargetRef 是客户端根据实例名称和主机地址生成的不透明稳定引用。
+	Label     string // Label 是配置中的实例名称。
+	Profile   string // Profile 是 minio、rustfs 或 custom 文件命名约定。
+	Location  string // Location 是本机证书根目录或 host:port 形式的远程地址加目录。
+	Status    string // Status 是实例状态。
+}
+
+// commandRunner 在本机或 SSH 远程主机上执行一条重启命令。
+type commandRunner func(ctx context.Context, command config.LocalCommandConfig, env []string) error
+
+// publishLayout 描述一次 SSH 远程发布使用的目标、暂存和备份目录。
+type publishLayout struct {
+	basePath   string // basePath 是远程证书根目录。
+	targetDir  string // targetDir 是 path/<域名> 发布目录。
+	stagingDir string // stagingDir 是与目标目录同级的暂存目录。
+	backupDir  string // backupDir 是重启完成前保留的旧目录。
+}
+
+// IsS3ServerConfiguredWithContext 从 context 快照判断是否配置了对象存储服务实例。
+func IsS3ServerConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && len(configuration.SSL.S3Servers) > 0
+}
+
+// IsS3ServerErrorRetryable 判断部署错误是否属于 SSH 或管理 API 连接超时等可稍后重试的情况。
+func IsS3ServerErrorRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var networkError net.Error
+	return errors.As(err, &networkError) && networkError.Timeout()
+}
+
+// DiscoverS3ServerResources 把配置中的每个对象存储服务实例列为一个部署资源，发现阶段不访问目录或远程主机。
+func DiscoverS3ServerResources(ctx context.Context) ([]S3ServerResource, error) {
+	servers, err := getS3Servers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]S3ServerResource, 0, len(servers))
+	for _, server := range servers {
+		location := server.Path
+		if config.IsSSHConfigured(&server.SSHConfig) {
+			location = net.JoinHostPort(server.Host, strconv.Itoa(server.Port)) + ":" + server.Path
+		}
+		resources = append(resources, S3ServerResource{
+			TargetRef: buildS3ServerTargetRef(server),
+			Label:     server.Name,
+			Profile:   server.Profile,
+			Location:  location,
+			Status:    S3ServerStatusReady,
+		})
+	}
+	return resources, nil
+}
+
+// TestS3ServerConnection 检查证书根目录可写，配置管理 API 时确认服务在线，不发布证书也不重启服务。
+func TestS3ServerConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	server, err := findS3Server(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	if config.IsSSHConfigured(&server.SSHConfig) {
+		err = testRemoteS3ServerDir(ctx, server)
+	} else {
+		err = testLocalS3ServerDir(server)
+	}
+	if err != nil {
+		return err
+	}
+	if server.AdminURL != "" {
+		if err := checkS3ServerHealth(ctx, server); err != nil {
+			return fmt.Errorf("对象存储服务 %s 不在线: %w", server.Name, err)
+		}
+	}
+	return nil
+}
+
+// DeployCertificateToS3Server 按 profile 命名把证书发布到 path/<域名>/，随后通过管理 API 或配置命令重启服务，失败时恢复旧证书目录。
+func DeployCertificateToS3Server(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	server, err := findS3Server(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	canonicalDomain, safeDomain, err := shared.NormalizeDeploymentDomain(domain)
+	if err != nil {
+		return err
+	}
+	fingerprint, err := providers.LeafCertificateSHA256(certificatePEM)
+	if err != nil {
+		return err
+	}
+
+	lock, _ := s3ServerLocks.LoadOrStore(server.Name, make(chan struct{}, 1))
+	select {
+	case lock.(chan struct{}) <- struct{}{}:
+		defer func() { <-lock.(chan struct{}) }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if config.IsSSHConfigured(&server.SSHConfig) {
+		err = deployRemote(ctx, server, canonicalDomain, safeDomain, certificatePEM, privateKeyPEM, fingerprint)
+	} else {
+		err = deployLocal(ctx, server, canonicalDomain, safeDomain, certificatePEM, privateKeyPEM, fingerprint)
+	}
+	if err != nil {
+		return fmt.Errorf("对象存储服务 %s 证书部署失败: %w", server.Name, err)
+	}
+	return nil
+}
+
+// deployLocal 在临时目录中按 profile 写入文件，通过共享发布器替换本机证书目录并在同一事务中重启服务。
+func deployLocal(ctx context.Context, server *config.S3ServerConfig, domain, safeDomain, certificatePEM, privateKeyPEM, fingerprint string) error {
+	certMode, err := config.ParseFileMode(server.CertMode)
+	if err != nil {
+		return err
+	}
+	keyMode, err := config.ParseFileMode(server.KeyMode)
+	if err != nil {
+		return err
+	}
+	uid, gid, err := shared.ResolveOwnership(server.Owner, server.Group)
+	if err != nil {
+		return err
+	}
+
+	stagingDir, err := os.MkdirTemp("", "anssl-s3server-*")
+	if err != nil {
+		return fmt.Errorf("创建对象存储证书临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	if err := writeFileWithMode(filepath.Join(stagingDir, server.CertFile), []byte(certificatePEM), certMode); err != nil {
+		return fmt.Errorf("写入证书文件失败: %w", err)
+	}
+	if err := writeFileWithMode(filepath.Join(stagingDir, server.KeyFile), []byte(privateKeyPEM), keyMode); err != nil {
+		return fmt.Errorf("写入私钥文件失败: %w", err)
+	}
+	if err := os.MkdirAll(server.Path, 0755); err != nil {
+		return fmt.Errorf("创建对象存储证书目录失败: %w", err)
+	}
+	targetDir, err := shared.SafeJoinUnderBase(server.Path, safeDomain)
+	if err != nil {
+		return err
+	}
+
+	env := commandEnvironment(domain, targetDir, server)
+	restarted := false
+	err = shared.PublishDirectoryWithValidationContext(ctx, stagingDir, targetDir, func() error {
+		// 跨设备发布会复制文件，属主必须在目标目录中设置。
+		if uid >= 0 || gid >= 0 {
+			for _, name := range []string{"", server.CertFile, server.KeyFile} {
+				if err := os.Lchown(filepath.Join(targetDir, name), uid, gid); err != nil {
+					return fmt.Errorf("设置文件属主失败: %w", err)
+				}
+			}
+		}
+		restarted = true
+		return restartS3Server(ctx, server, runLocalCommand, env, domain, fingerprint)
+	})
+	if err != nil {
+		if restarted {
+			// 发布事务已恢复旧证书目录，再次重启让服务回到旧证书。
+			rollbackContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), s3RollbackTimeout)
+			defer cancel()
+			if restartErr := restartS3Server(rollbackContext, server, runLocalCommand, env, domain, ""); restartErr != nil {
+				logger.Warn("恢复旧证书后重启对象存储服务失败", "name", server.Name, "error", restartErr)
+			}
+		}
+		return err
+	}
+	logger.Info("对象存储服务证书已更新", "name", server.Name, "path", targetDir)
+	return nil
+}
+
+// deployRemote 通过 SSH 上传证书，在远程同级目录中暂存并切换证书目录，重启失败时恢复旧目录。
+func deployRemote(ctx context.Context, server *config.S3ServerConfig, domain, safeDomain, certificatePEM, privateKeyPEM, fingerprint string) error {
+	executor, err := remote.NewExecutorContext(ctx, &server.SSHConfig, s3ServerPurpose(server), s3ServerTempPrefix, knownHostsFile(ctx))
+	if err != nil {
+		return err
+	}
+	defer executor.Close()
+
+	remoteTempDir, err := executor.CreateTempDirContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if cleanupErr := executor.RemoveTempDirContext(cleanupCtx, remoteTempDir); cleanupErr != nil {
+			logger.WarnLocal("清理对象存储 SSH 临时目录失败", "error", cleanupErr, "name", server.Name)
+		}
+	}()
+	remoteCertificateFile := path.Join(remoteTempDir, "cert.pem")
+	remotePrivateKeyFile := path.Join(remoteTempDir, "privateKey.key")
+	if err := executor.UploadContext(ctx, remoteCertificateFile, []byte(certificatePEM)); err != nil {
+		return fmt.Errorf("上传证书失败: %w", err)
+	}
+	if err := executor.UploadContext(ctx, remotePrivateKeyFile, []byte(privateKeyPEM)); err != nil {
+		return fmt.Errorf("上传私钥失败: %w", err)
+	}
+
+	privileged, err := needsPrivilege(ctx, executor, server)
+	if err != nil {
+		return err
+	}
+	layout := newPublishLayout(server.Path, safeDomain, strconv.FormatInt(time.Now().UnixNano(), 10))
+	if _, err := executor.RunContext(ctx, buildPublishScript(layout, server.CertificateFilesConfig, remoteCertificateFile, remotePrivateKeyFile), nil, privileged); err != nil {
+		return fmt.Errorf("发布远程证书目录失败: %w", err)
+	}
+
+	runRemote := func(ctx context.Context, command config.LocalCommandConfig, env []string) error {
+		timeout := time.Duration(command.Timeout) * time.Second
+		_, err := executor.RunWithTimeoutContext(ctx, buildRemoteCommand(command.Command, env), nil, server.Sudo, timeout)
+		return err
+	}
+	env := commandEnvironment(domain, layout.targetDir, server)
+	if err := restartS3Server(ctx, server, runRemote, env, domain, fingerprint); err != nil {
+		// 原操作可能已被取消，回滚使用独立的短超时 context。
+		rollbackContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), s3RollbackTimeout)
+		defer cancel()
+		if _, restoreErr := executor.RunContext(rollbackContext, buildRestoreScript(layout), nil, privileged); restoreErr != nil {
+			return fmt.Errorf("%w，回滚失败: %v", err, restoreErr)
+		}
+		if restartErr := restartS3Server(rollbackContext, server, runRemote, env, domain, ""); restartErr != nil {
+			logger.WarnLocal("恢复旧证书后重启对象存储服务失败", "name", server.Name, "error", restartErr)
+		}
+		return err
+	}
+	if _, err := executor.RunContext(ctx, "rm -rf -- "+remote.QuotePOSIXShellArg(layout.backupDir), nil, privileged); err != nil {
+		logger.WarnLocal("删除对象存储旧证书备份失败", "error", err, "name", server.Name, "path", layout.backupDir)
+	}
+	logger.InfoLocal("证书已通过 SSH 部署到对象存储服务", "name", server.Name, "host", server.Host, "path", layout.targetDir)
+	return nil
+}
+
+// restartS3Server 通过管理 API 或配置命令重启服务；fingerprint 非空时等待服务端提供新证书，回滚时只等待服务恢复在线。
+func restartS3Server(ctx context.Context, server *config.S3ServerConfig, run commandRunner, env []string, domain, fingerprint string) error {
+	if server.AdminURL != "" {
+		if err := restartMinIOService(ctx, server); err != nil {
+			return fmt.Errorf("管理 API 重启服务失败: %w", err)
+		}
+		return waitS3ServerReady(ctx, server, domain, fingerprint)
+	}
+	for _, command := range server.ReloadCommands {
+		if err := run(ctx, command, env); err != nil {
+			return fmt.Errorf("重启命令执行失败: %w", err)
+		}
+		logger.Info("对象存储服务重启命令执行成功", "command", command.Command)
+	}
+	return nil
+}
+
+// runLocalCommand 在本机通过 /bin/sh 执行重启命令。
+func runLocalCommand(ctx context.Context, command config.LocalCommandConfig, env []string) error {
+	return shared.RunShellCommandWithContext(ctx, command.Command, time.Duration(command.Timeout)*time.Second, env)
+}
+
+// testLocalS3ServerDir 检查本机证书根目录可写且属主可以解析。
+func testLocalS3ServerDir(server *config.S3ServerConfig) error {
+	if _, _, err := shared.ResolveOwnership(server.Owner, server.Group); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(server.Path, 0755); err != nil {
+		return fmt.Errorf("创建对象存储证书目录失败: %w", err)
+	}
+	probe, err := os.CreateTemp(server.Path, ".anssl-probe-*")
+	if err != nil {
+		return fmt.Errorf("对象存储证书目录不可写: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// testRemoteS3ServerDir 验证 SSH 登录、远程基础命令和证书根目录写入权限。
+func testRemoteS3ServerDir(ctx context.Context, server *config.S3ServerConfig) error {
+	executor, err := remote.NewExecutorContext(ctx, &server.SSHConfig, s3ServerPurpose(server), s3ServerTempPrefix, knownHostsFile(ctx))
+	if err != nil {
+		return err
+	}
+	defer executor.Close()
+
+	for _, command := range []string{"install", "mktemp", "mv", "rm"} {
+		if _, err := executor.RunContext(ctx, "command -v "+remote.QuotePOSIXShellArg(command)+" >/dev/null 2>&1", nil, false); err != nil {
+			return fmt.Errorf("对象存储服务 %s 的 SSH 主机缺少命令 %s: %w", server.Name, command, err)
+		}
+	}
+	privileged, err := needsPrivilege(ctx, executor, server)
+	if err != nil {
+		return fmt.Errorf("检查对象存储服务 %s 远程目录权限失败: %w", server.Name, err)
+	}
+	probeFile := path.Join(server.Path, ".anssl-write-check-"+strconv.FormatInt(time.Now().UnixNano(), 10))
+	command := "install -d -m 0755 -- " + remote.QuotePOSIXShellArg(server.Path) + " && " +
+		"umask 077 && : > " + remote.QuotePOSIXShellArg(probeFile) + " && rm -f -- " + remote.QuotePOSIXShellArg(probeFile)
+	if _, err := executor.RunContext(ctx, command, nil, privileged); err != nil {
+		return fmt.Errorf("对象存储服务 %s 远程证书目录不可写: %w", server.Name, err)
+	}
+	if server.Sudo && !executor.IsRoot() {
+		if _, err := executor.RunContext(ctx, "true", nil, true); err != nil {
+			return fmt.Errorf("对象存储服务 %s 的 SSH 主机无法通过 sudo 执行命令: %w", server.Name, err)
+		}
+	}
+	return nil
+}
+
+// needsPrivilege 判断远程发布是否需要 sudo；设置属主时非 root 用户总是需要提权。
+func needsPrivilege(ctx context.Context, executor *remote.Executor, server *config.S3ServerConfig) (bool, error) {
+	if (server.Owner != "" || server.Group != "") && !executor.IsRoot() {
+		if _, err := executor.RunContext(ctx, "true", nil, true); err != nil {
+			return false, fmt.Errorf("设置文件属主需要 root 或 sudo 权限: %w", err)
+		}
+		return true, nil
+	}
+	return executor.NeedsPrivilegeForPathContext(ctx, server.Path)
+}
+
+// newPublishLayout 在证书根目录下生成本次发布的同级暂存和备份目录。
+func newPublishLayout(basePath, safeDomain, token string) publishLayout {
+	return publishLayout{
+		basePath:   basePath,
+		targetDir:  path.Join(basePath, safeDomain),
+		stagingDir: path.Join(basePath, "."+safeDomain+".anssl-stage."+token),
+		backupDir:  path.Join(basePath, "."+safeDomain+".anssl-backup."+token),
+	}
+}
+
+// buildPublishScript 生成暂存并切换证书目录的脚本，旧目录移动到备份目录直到重启成功。
+func buildPublishScript(layout publishLayout, files config.CertificateFilesConfig, remoteCertificateFile, remotePrivateKeyFile string) string {
+	ownership := ""
+	if files.Owner != "" {
+		ownership += " -o " + remote.QuotePOSIXShellArg(files.Owner)
+	}
+	if files.Group != "" {
+		ownership += " -g " + remote.QuotePOSIXShellArg(files.Group)
+	}
+	targetDir := remote.QuotePOSIXShellArg(layout.targetDir)
+	stagingDir := remote.QuotePOSIXShellArg(layout.stagingDir)
+	backupDir := remote.QuotePOSIXShellArg(layout.backupDir)
+	commands := []string{
+		"set -eu",
+		"install -d -m 0755 -- " + remote.QuotePOSIXShellArg(layout.basePath),
+		"rm -rf -- " + stagingDir + " " + backupDir,
+		"install -d -m 0755" + ownership + " -- " + stagingDir,
+		"install -m " + files.CertMode + ownership + " -- " + remote.QuotePOSIXShellArg(remoteCertificateFile) + " " + remote.QuotePOSIXShellArg(path.Join(layout.stagingDir, files.CertFile)),
+		"install -m " + files.KeyMode + ownership + " -- " + remote.QuotePOSIXShellArg(remotePrivateKeyFile) + " " + remote.QuotePOSIXShellArg(path.Join(layout.stagingDir, files.KeyFile)),
+		"if [ -e " + targetDir + " ]; then mv -- " + targetDir + " " + backupDir + "; fi",
+		"if ! mv -- " + stagingDir + " " + targetDir + "; then rm -rf -- " + stagingDir + "; " +
+			"if [ -e " + backupDir + " ]; then mv -- " + backupDir + " " + targetDir + "; fi; exit 1; fi",
+	}
+	return strings.Join(commands, "; ")
+}
+
+// buildRestoreScript 生成删除新目录并恢复备份目录的脚本，首次部署时只删除新目录。
+func buildRestoreScript(layout publishLayout) string {
+	targetDir := remote.QuotePOSIXShellArg(layout.targetDir)
+	backupDir := remote.QuotePOSIXShellArg(layout.backupDir)
+	return "set -eu; rm -rf -- " + targetDir + "; if [ -e " + backupDir + " ]; then mv -- " + backupDir + " " + targetDir + "; fi"
+}
+
+// commandEnvironment 返回传给重启命令的证书位置环境变量。
+func commandEnvironment(domain, certDir string, server *config.S3ServerConfig) []string {
+	return []string{
+		"ANSSL_DOMAIN=" + domain,
+		"ANSSL_CERT_DIR=" + certDir,
+		"ANSSL_CERT_FILE=" + path.Join(certDir, server.CertFile),
+		"ANSSL_KEY_FILE=" + path.Join(certDir, server.KeyFile),
+	}
+}
+
+// buildRemoteCommand 在用户命令前导出证书位置环境变量。
+func buildRemoteCommand(command string, env []string) string {
+	exports := make([]string, 0, len(env))
+	for _, variable := range env {
+		name, value, _ := strings.Cut(variable, "=")
+		exports = append(exports, name+"="+remote.QuotePOSIXShellArg(value))
+	}
+	return "export " + strings.Join(exports, " ") + "; " + command
+}
+
+// writeFileWithMode 写入文件并显式设置权限，避免受 umask 影响。
+func writeFileWithMode(path string, content []byte, mode os.FileMode) error {
+	if err := os.WriteFile(path, content, mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
+// getS3Servers 读取当前操作快照中的对象存储服务实例。
+func getS3Servers(ctx context.Context) ([]*config.S3ServerConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || len(configuration.SSL.S3Servers) == 0 {
+		return nil, errors.New("未配置对象存储服务实例 (ssl.s3Servers)")
+	}
+	return configuration.SSL.S3Servers, nil
+}
+
+// findS3Server 根据 targetRef 重新定位配置中的实例，实例改名或更换主机后引用失效。
+func findS3Server(ctx context.Context, targetRef string) (*config.S3ServerConfig, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("对象存储服务实例 targetRef 不能为空")
+	}
+	servers, err := getS3Servers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, server := range servers {
+		if buildS3ServerTargetRef(server) == targetRef {
+			return server, nil
+		}
+	}
+	return nil, errors.New("对象存储服务实例不存在或配置已变更，请重新配置部署目标")
+}
+
+// buildS3ServerTargetRef 根据实例名称和 SSH 主机地址生成稳定的不透明引用，本机实例的主机部分为空。
+func buildS3ServerTargetRef(server *config.S3ServerConfig) string {
+	identity := strings.Join([]string{
+		"ansslCli",
+		"DEPLOYMENT_TYPE_ANSSL_CLI_S3_SERVER_CERT",
+		server.Name,
+		strings.ToLower(server.Host),
+		strconv.Itoa(server.Port),
+	}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return s3ServerTargetPrefix + hex.EncodeToString(digest[:12])
+}
+
+// s3ServerPurpose 返回日志和错误中使用的实例名称。
+func s3ServerPurpose(server *config.S3ServerConfig) string {
+	return "对象存储服务 " + server.Name
+}
+
+// knownHostsFile 返回运行时配置的 known_hosts 路径，未设置时由 remote 使用默认值。
+func knownHostsFile(ctx context.Context) string {
+	if runtime := shared.RuntimeFromContext(ctx); runtime != nil {
+		return runtime.KnownHostsFile
+	}
+	return ""
+}
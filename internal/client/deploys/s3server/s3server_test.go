@@ -0,0 +1,151 @@
+package s3server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestDeployCertificateToS3ServerRestartsMinIOThroughAdminAPI 验证按 MinIO 命名写入证书、用 SigV4 调用重启接口，并通过 SNI 握手确认新证书。
+func TestDeployCertificateToS3ServerRestartsMinIOThroughAdminAPI(t *testing.T) {
+	root := t.TempDir()
+	targetDir := filepath.Join(root, "minio.example.com")
+	var mu sync.Mutex
+	var authorization string
+	admin := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/minio/admin/v3/service" && r.URL.Query().Get("action") == "restart" {
+			mu.Lock()
+			authorization = r.Header.Get("Authorization")
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	fallbackCertificate, fallbackKey := generateTestCertificatePair(t, "fallback.example.com")
+	fallback, err := tls.X509KeyPair([]byte(fallbackCertificate), []byte(fallbackKey))
+	if err != nil {
+		t.Fatalf("load fallback certificate: %v", err)
+	}
+	admin.TLS = &tls.Config{GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		// 模拟 MinIO 按 SNI 从 certs/<域名>/ 读取证书，目录不存在时使用默认证书。
+		certificate, err := tls.LoadX509KeyPair(filepath.Join(root, hello.ServerName, "public.crt"), filepath.Join(root, hello.ServerName, "private.key"))
+		if err != nil {
+			return &fallback, nil
+		}
+		return &certificate, nil
+	}}
+	admin.StartTLS()
+	t.Cleanup(admin.Close)
+
+	server := &config.S3ServerConfig{
+		Name: "minio", Profile: config.S3ServerProfileMinIO, Path: root,
+		AdminURL: admin.URL, AccessKey: "admin", SecretKey: "secret", Region: "us-east-1", InsecureSkipVerify: true,
+		CertificateFilesConfig: config.CertificateFilesConfig{CertFile: "public.crt", KeyFile: "private.key", CertMode: "0644", KeyMode: "0600"},
+	}
+	ctx := s3ServerTestContext(server)
+	resources, err := DiscoverS3ServerResources(ctx)
+	if err != nil || len(resources) != 1 || resources[0].Location != root || !strings.HasPrefix(resources[0].TargetRef, s3ServerTargetPrefix) {
+		t.Fatalf("发现的对象存储资源不匹配: %+v err=%v", resources, err)
+	}
+	if err := TestS3ServerConnection(ctx, resources[0].TargetRef); err != nil {
+		t.Fatalf("TestS3ServerConnection: %v", err)
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "minio.example.com")
+	if err := DeployCertificateToS3Server(ctx, resources[0].TargetRef, "minio.example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToS3Server: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(targetDir, "public.crt")); string(content) != certificatePEM {
+		t.Fatal("public.crt 未更新")
+	}
+	if info, err := os.Stat(filepath.Join(targetDir, "private.key")); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("private.key 权限应为 0600: info=%v err=%v", info, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=admin/") || !strings.Contains(authorization, "/us-east-1/s3/aws4_request") {
+		t.Fatalf("重启请求签名不匹配: %q", authorization)
+	}
+}
+
+// TestDeployCertificateToS3ServerRestoresDirectoryWhenReloadCommandFails 验证重启命令失败时恢复旧证书目录并再次执行命令。
+func TestDeployCertificateToS3ServerRestoresDirectoryWhenReloadCommandFails(t *testing.T) {
+	root := t.TempDir()
+	targetDir := filepath.Join(root, "s3.example.com")
+	oldCertificate, oldKey := generateTestCertificatePair(t, "s3.example.com")
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	os.WriteFile(filepath.Join(targetDir, "tls.crt"), []byte(oldCertificate), 0o644)
+	os.WriteFile(filepath.Join(targetDir, "tls.key"), []byte(oldKey), 0o600)
+	calls := filepath.Join(t.TempDir(), "calls")
+
+	server := &config.S3ServerConfig{
+		Name: "garage", Profile: config.S3ServerProfileCustom, Path: root,
+		ReloadCommands: []config.LocalCommandConfig{{
+			// 第一次执行返回失败触发回滚，回滚后的再次执行成功。
+			Command: "echo \"$ANSSL_CERT_FILE\" >> '" + calls + "'; [ \"$(wc -l < '" + calls + "')\" -gt 1 ]",
+			Timeout: 5,
+		}},
+		CertificateFilesConfig: config.CertificateFilesConfig{CertFile: "tls.crt", KeyFile: "tls.key", CertMode: "0644", KeyMode: "0600"},
+	}
+	ctx := s3ServerTestContext(server)
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "s3.example.com")
+	err := DeployCertificateToS3Server(ctx, buildS3ServerTargetRef(server), "s3.example.com", certificatePEM, privateKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "重启命令执行失败") {
+		t.Fatalf("重启命令失败时应返回错误: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(targetDir, "tls.crt")); string(content) != oldCertificate {
+		t.Fatal("旧证书目录未恢复")
+	}
+	content, _ := os.ReadFile(calls)
+	if lines := strings.Split(strings.TrimSpace(string(content)), "\n"); len(lines) != 2 || lines[0] != filepath.Join(targetDir, "tls.crt") {
+		t.Fatalf("恢复旧证书后应再次执行重启命令:\n%s", content)
+	}
+}
+
+// s3ServerTestContext 返回只包含对象存储服务配置的操作 context。
+func s3ServerTestContext(servers ...*config.S3ServerConfig) context.Context {
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{S3Servers: servers}}})
+}
+
+// generateTestCertificatePair 生成测试用自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,6 +18,7 @@ import (
 	"time"
 
 	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
 	"github.com/https-cert/deploy/pkg/logger"
 )
 
@@ -29,9 +31,11 @@ const (
 
 // safeLineClient 保存仅供本机请求雷池 OpenAPI 使用的连接信息。
 type safeLineClient struct {
-	baseURL    string       // baseURL 是规范化后的雷池管理端地址。
-	apiToken   string       // apiToken 是不会发送到 ANSSL 后端的雷池鉴权 Token。
-	httpClient *http.Client // httpClient 限制超时、TLS 版本和重定向行为。
+	name         string       // name 是具名实例名称，兼容的单实例为空。
+	baseURL      string       // baseURL 是规范化后的雷池管理端地址。
+	apiToken     string       // apiToken 是不会发送到 ANSSL 后端的雷池鉴权 Token。
+	removeUnused bool         // removeUnused 表示站点换绑后删除不再被引用的旧证书。
+	httpClient   *http.Client // httpClient 限制超时、TLS 版本和重定向行为。
 }
 
 // safeLineAPIResponse 是雷池 OpenAPI 的通用响应包络。
@@ -71,25 +75,29 @@ func TestSafeLineConnection() error {
 	return TestSafeLineConnectionWithContext(context.Background())
 }
 
-// TestSafeLineConnectionWithContext 使用调用方 context 验证雷池连接。
+// TestSafeLineConnectionWithContext 使用调用方 context 逐个验证全部雷池实例的连接。
 func TestSafeLineConnectionWithContext(ctx context.Context) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	client, err := newSafeLineClient(ctx)
+	clients, err := newSafeLineClients(ctx)
 	if err != nil {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, safeLineRequestTimeout)
-	defer cancel()
-	if _, err := client.listCertificates(ctx); err != nil {
-		return fmt.Errorf("雷池连接测试失败: %w", err)
+	var failures []error
+	for _, client := range clients {
+		testContext, cancel := context.WithTimeout(ctx, safeLineRequestTimeout)
+		_, err := client.listCertificates(testContext)
+		cancel()
+		if err != nil {
+			failures = append(failures, client.instanceError(fmt.Errorf("雷池连接测试失败: %w", err)))
+		}
 	}
-	return nil
+	return errors.Join(failures...)
 }
 
-// Deploy 按完整证书域名集合更新雷池已有证书，未匹配时新增证书。
+// Deploy 在全部雷池实例上按完整证书域名集合更新已有证书，未匹配时新增证书；单个实例失败不影响其他实例。
 func Deploy(ctx context.Context, sourceDir, domain string) error {
 	if err := shared.OperationContextError(ctx); err != nil {
 		return err
@@ -113,10 +121,21 @@ func Deploy(ctx context.Context, sourceDir, domain string) error {
 		return err
 	}
 
-	client, err := newSafeLineClient(ctx)
+	clients, err := newSafeLineClients(ctx)
 	if err != nil {
 		return err
 	}
+	var failures []error
+	for _, client := range clients {
+		if err := client.deployCertificate(ctx, domain, domains, string(certificatePEM), string(privateKeyPEM)); err != nil {
+			failures = append(failures, client.instanceError(err))
+		}
+	}
+	return errors.Join(failures...)
+}
+
+// deployCertificate 在单个雷池实例上更新域名集合一致的证书，未匹配时新增证书。
+func (client *safeLineClient) deployCertificate(ctx context.Context, domain string, domains []string, certificatePEM, privateKeyPEM string) error {
 	certificates, err := client.listCertificates(ctx)
 	if err != nil {
 		return fmt.Errorf("读取雷池证书列表失败: %w", err)
@@ -125,8 +144,8 @@ func Deploy(ctx context.Context, sourceDir, domain string) error {
 	request := safeLineCertificateRequest{
 		Type: safeLineManualType,
 		Manual: safeLineCertificateManual{
-			Certificate: string(certificatePEM),
-			PrivateKey:  string(privateKeyPEM),
+			Certificate: certificatePEM,
+			PrivateKey:  privateKeyPEM,
 		},
 	}
 	matchedIDs := matchingSafeLineCertificateIDs(certificates, domains)
@@ -134,7 +153,7 @@ func Deploy(ctx context.Context, sourceDir, domain string) error {
 		if err := client.upsertCertificate(ctx, request); err != nil {
 			return fmt.Errorf("新增雷池证书失败: %w", err)
 		}
-		logger.Info("雷池证书已新增", "domain", domain)
+		logger.Info("雷池证书已新增", "domain", domain, "instance", client.name)
 		return nil
 	}
 
@@ -144,29 +163,43 @@ func Deploy(ctx context.Context, sourceDir, domain string) error {
 			return fmt.Errorf("更新雷池证书失败: %w", err)
 		}
 	}
-	logger.Info("雷池证书已更新", "domain", domain, "count", len(matchedIDs))
+	logger.Info("雷池证书已更新", "domain", domain, "instance", client.name, "count", len(matchedIDs))
 	return nil
 }
 
-// newSafeLineClient 从本地配置创建隔离的雷池 HTTP 客户端。
-func newSafeLineClient(contexts ...context.Context) (*safeLineClient, error) {
-	var ctx context.Context
-	if len(contexts) > 0 {
-		ctx = contexts[0]
-	}
+// newSafeLineClients 为当前操作快照中的全部雷池实例创建客户端，兼容的单实例配置排在最前。
+func newSafeLineClients(ctx context.Context) ([]*safeLineClient, error) {
 	configuration := shared.ConfigurationFromContext(ctx)
-	if configuration == nil || configuration.SSL == nil || configuration.SSL.SafeLine == nil {
-		return nil, errors.New("未配置雷池 WAF (ssl.safeLine)")
+	if configuration == nil {
+		return nil, errors.New("未配置雷池 WAF (ssl.safeLine 或 ssl.safeLines)")
+	}
+	instances := config.SafeLineInstances(configuration.SSL)
+	if len(instances) == 0 {
+		return nil, errors.New("未配置雷池 WAF (ssl.safeLine 或 ssl.safeLines)")
+	}
+	clients := make([]*safeLineClient, 0, len(instances))
+	for _, safeLine := range instances {
+		client, err := newSafeLineClient(safeLine)
+		if err != nil {
+			if safeLine.Name != "" {
+				return nil, fmt.Errorf("雷池实例 %s: %w", safeLine.Name, err)
+			}
+			return nil, err
+		}
+		clients = append(clients, client)
 	}
+	return clients, nil
+}
 
-	safeLine := configuration.SSL.SafeLine
+// newSafeLineClient 从单个雷池实例配置创建隔离的 HTTP 客户端。
+func newSafeLineClient(safeLine *config.SafeLineConfig) (*safeLineClient, error) {
 	baseURL := strings.TrimRight(strings.TrimSpace(safeLine.URL), "/")
 	apiToken := strings.TrimSpace(safeLine.APIToken)
 	if baseURL == "" {
-		return nil, errors.New("雷池 API 地址未配置 (ssl.safeLine.url)")
+		return nil, errors.New("雷池 API 地址未配置")
 	}
 	if apiToken == "" {
-		return nil, errors.New("雷池 API Token 未配置 (ssl.safeLine.apiToken)")
+		return nil, errors.New("雷池 API Token 未配置")
 	}
 	parsedURL, err := url.Parse(baseURL)
 	if err != nil || parsedURL.Hostname() == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
@@ -193,12 +226,22 @@ func newSafeLineClient(contexts ...context.Context) (*safeLineClient, error) {
 		},
 	}
 	return &safeLineClient{
-		baseURL:    baseURL,
-		apiToken:   apiToken,
-		httpClient: httpClient,
+		name:         safeLine.Name,
+		baseURL:      baseURL,
+		apiToken:     apiToken,
+		removeUnused: safeLine.RemoveUnusedCertificates,
+		httpClient:   httpClient,
 	}, nil
 }
 
+// instanceError 为具名实例的错误加上实例名称，兼容的单实例保持原错误。
+func (client *safeLineClient) instanceError(err error) error {
+	if err == nil || client.name == "" {
+		return err
+	}
+	return fmt.Errorf("雷池实例 %s: %w", client.name, err)
+}
+
 // listCertificates 获取雷池证书列表并校验响应包络。
 func (client *safeLineClient) listCertificates(ctx context.Context) ([]safeLineCertificateItem, error) {
 	var certificateList safeLineCertificateList
@@ -213,6 +256,24 @@ func (client *safeLineClient) upsertCertificate(ctx context.Context, request saf
 	return client.request(ctx, http.MethodPost, safeLineCertificatePath, request, nil)
 }
 
+// createCertificate 新增雷池证书并返回响应中的证书 ID。
+func (client *safeLineClient) createCertificate(ctx context.Context, request safeLineCertificateRequest) (int64, error) {
+	request.ID = 0
+	var certificateID int64
+	if err := client.request(ctx, http.MethodPost, safeLineCertificatePath, request, &certificateID); err != nil {
+		return 0, err
+	}
+	if certificateID <= 0 {
+		return 0, errors.New("雷池新增证书响应缺少 ID")
+	}
+	return certificateID, nil
+}
+
+// deleteCertificate 按 ID 删除雷池证书。
+func (client *safeLineClient) deleteCertificate(ctx context.Context, certificateID int64) error {
+	return client.request(ctx, http.MethodDelete, fmt.Sprintf("%s/%d", safeLineCertificatePath, certificateID), nil, nil)
+}
+
 // request 使用统一鉴权方式调用雷池 OpenAPI，并限制响应体与错误暴露范围。
 func (client *safeLineClient) request(ctx context.Context, method, endpoint string, requestBody, responseData any) error {
 	var body io.Reader
@@ -279,6 +340,11 @@ func safeLineCertificateDomains(certificatePath string) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("解析雷池证书域名失败: %w", err)
 	}
+	return safeLineLeafDomains(certificate)
+}
+
+// safeLineLeafDomains 返回叶子证书稳定排序的完整 DNS 域名集合，没有 SAN 时回退到 CommonName。
+func safeLineLeafDomains(certificate *x509.Certificate) ([]string, error) {
 	domains := certificate.DNSNames
 	if len(domains) == 0 && strings.TrimSpace(certificate.Subject.CommonName) != "" {
 		domains = []string{certificate.Subject.CommonName}
@@ -0,0 +1,258 @@
+package safeline
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestDeployCertificateToSafeLineSiteBindsNewCertificateAndRemovesUnused 验证新增证书后换绑站点、保留站点其他字段，并删除不再被引用的旧证书。
+func TestDeployCertificateToSafeLineSiteBindsNewCertificateAndRemovesUnused(t *testing.T) {
+	fake := newFakeSafeLine()
+	fake.sites = []map[string]any{
+		{"id": 7, "title": "blog", "server_names": []string{"blog.example.com"}, "ports": []string{"443_ssl", "80"}, "cert_id": 3, "create_time": "2025-01-01", "upstreams": []string{"http://10.0.0.2:8080"}},
+		{"id": 8, "title": "shop", "server_names": []string{"shop.example.com"}, "ports": []string{"443_ssl"}, "cert_id": 4},
+	}
+	fake.certificates = []map[string]any{
+		{"id": 3, "domains": []string{"old.example.com"}},
+		{"id": 4, "domains": []string{"shop.example.com"}},
+	}
+	ctx := safeLineTestContext(t, fake, true)
+
+	resources, err := DiscoverSafeLineSiteResources(ctx)
+	if err != nil || len(resources) != 2 || resources[0].Label != "blog" || resources[0].Protocol != safeLineProtocolHTTPS {
+		t.Fatalf("发现的雷池站点不匹配: %+v err=%v", resources, err)
+	}
+	if err := TestSafeLineSiteConnection(ctx, resources[0].TargetRef); err != nil {
+		t.Fatalf("TestSafeLineSiteConnection: %v", err)
+	}
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "blog.example.com")
+	if err := DeployCertificateToSafeLineSite(ctx, resources[0].TargetRef, "blog.example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToSafeLineSite: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if jsonNumber(fake.sites[0]["cert_id"]) != 10 || fake.sites[0]["upstreams"] == nil {
+		t.Fatalf("站点未换绑到新证书或丢失原有字段: %+v", fake.sites[0])
+	}
+	if len(fake.certificates) != 2 || jsonNumber(fake.certificates[0]["id"]) != 4 {
+		t.Fatalf("未被引用的旧证书应被删除，仍被引用的证书应保留: %+v", fake.certificates)
+	}
+	if fake.token != "safeline-token" {
+		t.Fatalf("API Token 未通过 X-SLCE-API-TOKEN 传递: %q", fake.token)
+	}
+}
+
+// TestDeployCertificateToSafeLineSiteUpdatesMatchingCertificate 验证域名集合一致时更新站点当前证书，停用站点拒绝部署。
+func TestDeployCertificateToSafeLineSiteUpdatesMatchingCertificate(t *testing.T) {
+	fake := newFakeSafeLine()
+	fake.sites = []map[string]any{
+		{"id": 1, "server_names": []string{"api.example.com"}, "ports": []string{"443_ssl"}, "cert_id": 5},
+		{"id": 2, "title": "legacy", "server_names": []string{"legacy.example.com"}, "ports": []string{"80"}, "is_enabled": false},
+		{"id": 3, "server_names": []string{}, "ports": []string{"8080"}},
+	}
+	fake.certificates = []map[string]any{
+		{"id": 2, "domains": []string{"API.example.com."}},
+		{"id": 5, "domains": []string{"api.example.com"}},
+	}
+	ctx := safeLineTestContext(t, fake, false)
+
+	resources, err := DiscoverSafeLineSiteResources(ctx)
+	if err != nil || len(resources) != 2 {
+		t.Fatalf("没有域名的站点应被跳过: %+v err=%v", resources, err)
+	}
+	if resources[0].Label != "api.example.com" || resources[1].Status != SafeLineSiteStatusDisabled || resources[1].Protocol != safeLineProtocolHTTP {
+		t.Fatalf("站点名称、状态或协议不匹配: %+v", resources)
+	}
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "api.example.com")
+	if err := DeployCertificateToSafeLineSite(ctx, resources[0].TargetRef, "api.example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToSafeLineSite: %v", err)
+	}
+	if err := DeployCertificateToSafeLineSite(ctx, resources[1].TargetRef, "legacy.example.com", certificatePEM, privateKeyPEM); err == nil {
+		t.Fatal("停用站点应拒绝部署")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.updatedCertificateID != 5 || len(fake.certificates) != 2 {
+		t.Fatalf("应更新站点当前引用的证书而不是新增: updated=%d certificates=%+v", fake.updatedCertificateID, fake.certificates)
+	}
+}
+
+// TestDiscoverSafeLineSiteResourcesAcrossInstances 验证多实例汇总发现、不可达实例返回部分结果，以及 targetRef 直接定位所属实例。
+func TestDiscoverSafeLineSiteResourcesAcrossInstances(t *testing.T) {
+	legacy := newFakeSafeLine()
+	legacy.sites = []map[string]any{{"id": 1, "title": "a", "server_names": []string{"a.example.com"}, "ports": []string{"443_ssl"}}}
+	named := newFakeSafeLine()
+	named.sites = []map[string]any{{"id": 1, "title": "b", "server_names": []string{"b.example.com"}, "ports": []string{"443_ssl"}}}
+	legacyServer := httptest.NewServer(legacy)
+	t.Cleanup(legacyServer.Close)
+	namedServer := httptest.NewServer(named)
+	t.Cleanup(namedServer.Close)
+	downServer := httptest.NewServer(http.NotFoundHandler())
+	downServer.Close()
+	ctx := shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{
+		SafeLine: &config.SafeLineConfig{URL: legacyServer.URL, APIToken: "legacy-token"},
+		SafeLines: []*config.SafeLineConfig{
+			{Name: "waf-02", URL: namedServer.URL, APIToken: "named-token"},
+			{Name: "down", URL: downServer.URL, APIToken: "down-token"},
+		},
+	}}})
+
+	resources, err := DiscoverSafeLineSiteResources(ctx)
+	if err == nil || !strings.Contains(err.Error(), "雷池实例 down") || len(resources) != 2 {
+		t.Fatalf("不可达实例应返回其他实例的资源和错误: %+v err=%v", resources, err)
+	}
+	if !strings.HasPrefix(resources[0].TargetRef, safeLineSiteTargetRefPrefix) || len(resources[0].TargetRef) != len(safeLineSiteTargetRefPrefix)+24 {
+		t.Fatalf("单实例配置的 targetRef 格式应保持不变: %s", resources[0].TargetRef)
+	}
+	if !strings.HasPrefix(resources[1].TargetRef, shared.InstanceTargetRefPrefix(safeLineSiteTargetRefPrefix, "waf-02")) {
+		t.Fatalf("具名实例的 targetRef 应包含实例标记: %s", resources[1].TargetRef)
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "b.example.com")
+	if err := DeployCertificateToSafeLineSite(ctx, resources[1].TargetRef, "b.example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToSafeLineSite: %v", err)
+	}
+	named.mu.Lock()
+	defer named.mu.Unlock()
+	legacy.mu.Lock()
+	defer legacy.mu.Unlock()
+	if jsonNumber(named.sites[0]["cert_id"]) != 10 || len(legacy.certificates) != 0 || named.token != "named-token" {
+		t.Fatalf("证书应只部署到具名实例: named=%+v legacy=%+v", named.sites, legacy.certificates)
+	}
+}
+
+// fakeSafeLine 在内存中模拟雷池站点和证书 OpenAPI。
+type fakeSafeLine struct {
+	mu                   sync.Mutex
+	sites                []map[string]any
+	certificates         []map[string]any
+	nextID               int
+	updatedCertificateID int
+	token                string
+}
+
+// newFakeSafeLine 创建新增证书 ID 从 10 开始的雷池替身。
+func newFakeSafeLine() *fakeSafeLine {
+	return &fakeSafeLine{nextID: 10}
+}
+
+// ServeHTTP 按雷池 OpenAPI 的 data/err/msg 包络返回数据。
+func (f *fakeSafeLine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.token = r.Header.Get("X-SLCE-API-TOKEN")
+	var body map[string]any
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+	var data any
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == safeLineSitePath:
+		data = map[string]any{"data": f.sites, "total": len(f.sites)}
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, safeLineSitePath+"/"):
+		data = f.find(f.sites, strings.TrimPrefix(r.URL.Path, safeLineSitePath+"/"))
+	case r.Method == http.MethodPut && r.URL.Path == safeLineSitePath:
+		for index, site := range f.sites {
+			if jsonNumber(site["id"]) == jsonNumber(body["id"]) {
+				f.sites[index] = body
+			}
+		}
+	case r.Method == http.MethodGet && r.URL.Path == safeLineCertificatePath:
+		data = map[string]any{"nodes": f.certificates, "total": len(f.certificates)}
+	case r.Method == http.MethodPost && r.URL.Path == safeLineCertificatePath:
+		if id := jsonNumber(body["id"]); id != 0 {
+			f.updatedCertificateID = id
+			break
+		}
+		f.certificates = append(f.certificates, map[string]any{"id": float64(f.nextID), "domains": []string{"new"}})
+		data = f.nextID
+		f.nextID++
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, safeLineCertificatePath+"/"):
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, safeLineCertificatePath+"/"))
+		kept := f.certificates[:0]
+		for _, certificate := range f.certificates {
+			if jsonNumber(certificate["id"]) != id {
+				kept = append(kept, certificate)
+			}
+		}
+		f.certificates = kept
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"data": data, "err": nil, "msg": ""})
+}
+
+// find 按字符串形式的 ID 查找记录。
+func (f *fakeSafeLine) find(records []map[string]any, id string) map[string]any {
+	for _, record := range records {
+		if strconv.Itoa(jsonNumber(record["id"])) == id {
+			return record
+		}
+	}
+	return nil
+}
+
+// jsonNumber 统一测试数据中的 int 和 JSON 解码后的 float64。
+func jsonNumber(value any) int {
+	switch number := value.(type) {
+	case int:
+		return number
+	case float64:
+		return int(number)
+	}
+	return 0
+}
+
+// safeLineTestContext 启动雷池替身并返回只包含雷池配置的操作 context。
+func safeLineTestContext(t *testing.T, fake *fakeSafeLine, removeUnused bool) context.Context {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	safeLine := &config.SafeLineConfig{URL: server.URL, APIToken: "safeline-token", RemoveUnusedCertificates: removeUnused}
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{SafeLine: safeLine}}})
+}
+
+// generateTestCertificatePair 生成测试用自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
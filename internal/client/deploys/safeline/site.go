@@ -0,0 +1,384 @@
+package safeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	safeLineSitePath            = "/api/open/site"
+	safeLineSiteTargetRefPrefix = "safeline-site-"
+	safeLineSitePageSize        = 100
+	safeLineSiteMaxPages        = 100
+	safeLineProtocolHTTP        = "HTTP"
+	safeLineProtocolHTTPS       = "HTTPS"
+	// SafeLineSiteStatusEnabled 表示站点已启用防护，可以部署证书。
+	SafeLineSiteStatusEnabled = "Enabled"
+	// SafeLineSiteStatusDisabled 表示站点已停用，部署前需要在雷池中重新启用。
+	SafeLineSiteStatusDisabled = "Disabled"
+)
+
+// SafeLineSiteResource 是可以安全上报到 anSSL 后端的雷池防护站点资源。
+type SafeLineSiteResource struct {
+	TargetRef string   // TargetRef 是客户端根据管理端地址、站点 ID 和创建时间生成的不透明稳定引用。
+	Label     string   // Label 是站点名称，未命名时使用第一个域名。
+	Domain    string   // Domain 是站点的第一个规范化域名。
+	Domains   []string // Domains 是站点 server_names 中的全部规范化域名。
+	Ports     []string // Ports 是站点监听端口，带 _ssl 后缀的端口使用证书。
+	Protocol  string   // Protocol 是 HTTPS 或 HTTP。
+	Status    string   // Status 是站点启用状态。
+}
+
+// safeLineSite 描述站点列表和详情中生成资源和换绑证书所需的字段。
+type safeLineSite struct {
+	ID          int64    `json:"id"`           // ID 是仅保留在 deploy 本地的站点 ID。
+	Title       string   `json:"title"`        // Title 是站点名称。
+	Comment     string   `json:"comment"`      // Comment 是旧版雷池的站点备注。
+	ServerNames []string `json:"server_names"` // ServerNames 是站点绑定的域名。
+	Ports       []string `json:"ports"`        // Ports 是站点监听端口。
+	CertID      int64    `json:"cert_id"`      // CertID 是站点当前引用的证书 ID，未启用 HTTPS 时为 0。
+	IsEnabled   *bool    `json:"is_enabled"`   // IsEnabled 为空时按已启用处理。
+	CreateTime  string   `json:"create_time"`  // CreateTime 用于区分删除后重新创建的站点。
+}
+
+// safeLineSiteList 是雷池站点列表响应数据，兼容 data 和 nodes 两种字段名。
+type safeLineSiteList struct {
+	Total int            `json:"total"` // Total 是站点总数。
+	Data  []safeLineSite `json:"data"`  // Data 是当前页站点。
+	Nodes []safeLineSite `json:"nodes"` // Nodes 是部分版本使用的当前页站点字段。
+}
+
+// safeLineSiteRecord 在 deploy 内部关联脱敏资源和真实站点。
+type safeLineSiteRecord struct {
+	Site     safeLineSite         // Site 是真实站点字段，只能在 deploy 本地使用。
+	Resource SafeLineSiteResource // Resource 是可以上报的脱敏资源。
+}
+
+// IsSafeLineConfiguredWithContext 从 context 快照判断是否配置了至少一个雷池实例的地址和 API Token。
+func IsSafeLineConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && len(config.SafeLineInstances(configuration.SSL)) > 0
+}
+
+// DiscoverSafeLineSiteResources 通过 OpenAPI 读取全部雷池实例防护站点的脱敏目录；部分实例不可达时同时返回已发现资源和错误。
+func DiscoverSafeLineSiteResources(ctx context.Context) ([]SafeLineSiteResource, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	clients, err := newSafeLineClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]SafeLineSiteResource, 0)
+	var failures []error
+	for _, client := range clients {
+		discoveryContext, cancel := context.WithTimeout(ctx, safeLineRequestTimeout)
+		records, err := client.loadSiteRecords(discoveryContext)
+		cancel()
+		if err != nil {
+			logger.Warn("读取雷池站点目录失败", "instance", client.name, "error", err)
+			failures = append(failures, client.instanceError(err))
+			continue
+		}
+		for _, record := range records {
+			resources = append(resources, record.Resource)
+		}
+	}
+	return resources, errors.Join(failures...)
+}
+
+// TestSafeLineSiteConnection 只读确认 targetRef 对应站点仍存在、已启用且证书列表可读取。
+func TestSafeLineSiteConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	client, err := findSiteClient(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	testContext, cancel := context.WithTimeout(ctx, safeLineRequestTimeout)
+	defer cancel()
+	record, err := client.findSiteByTargetRef(testContext, targetRef)
+	if err != nil {
+		return err
+	}
+	if record.Resource.Status == SafeLineSiteStatusDisabled {
+		return errors.New("雷池站点已停用")
+	}
+	if _, err := client.listCertificates(testContext); err != nil {
+		return fmt.Errorf("读取雷池证书列表失败: %w", err)
+	}
+	return nil
+}
+
+// DeployCertificateToSafeLineSite 按完整域名集合新增或更新雷池证书，把证书 ID 绑定到 targetRef 对应站点并回读确认。
+func DeployCertificateToSafeLineSite(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	chain, _, err := shared.ParseCertificateChainAndKey([]byte(certificatePEM), []byte(privateKeyPEM))
+	if err != nil {
+		return err
+	}
+	domains, err := safeLineLeafDomains(chain[0])
+	if err != nil {
+		return err
+	}
+	client, err := findSiteClient(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	record, err := client.findSiteByTargetRef(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	if record.Resource.Status == SafeLineSiteStatusDisabled {
+		return errors.New("雷池站点已停用")
+	}
+
+	certificateID, err := client.upsertSiteCertificate(ctx, record.Site.CertID, domains, certificatePEM, privateKeyPEM)
+	if err != nil {
+		return err
+	}
+	if err := client.bindSiteCertificate(ctx, record.Site.ID, certificateID); err != nil {
+		return err
+	}
+	logger.Info("雷池站点证书已绑定", "site", record.Resource.Label, "domain", domain, "instance", client.name)
+
+	previousID := record.Site.CertID
+	if client.removeUnused && previousID > 0 && previousID != certificateID {
+		if err := client.removeUnreferencedCertificate(ctx, previousID); err != nil {
+			logger.Warn("删除未被引用的雷池旧证书失败", "error", err)
+		}
+	}
+	return nil
+}
+
+// upsertSiteCertificate 优先更新站点当前引用且域名集合一致的证书，其次更新其他一致的证书，都没有时新增证书。
+func (client *safeLineClient) upsertSiteCertificate(ctx context.Context, currentID int64, domains []string, certificatePEM, privateKeyPEM string) (int64, error) {
+	certificates, err := client.listCertificates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("读取雷池证书列表失败: %w", err)
+	}
+	request := safeLineCertificateRequest{
+		Type: safeLineManualType,
+		Manual: safeLineCertificateManual{
+			Certificate: certificatePEM,
+			PrivateKey:  privateKeyPEM,
+		},
+	}
+	matchedIDs := matchingSafeLineCertificateIDs(certificates, domains)
+	if len(matchedIDs) == 0 {
+		certificateID, err := client.createCertificate(ctx, request)
+		if err != nil {
+			return 0, fmt.Errorf("新增雷池证书失败: %w", err)
+		}
+		return certificateID, nil
+	}
+	request.ID = matchedIDs[0]
+	for _, matchedID := range matchedIDs {
+		if matchedID == currentID {
+			request.ID = currentID
+		}
+	}
+	if err := client.upsertCertificate(ctx, request); err != nil {
+		return 0, fmt.Errorf("更新雷池证书失败: %w", err)
+	}
+	return request.ID, nil
+}
+
+// bindSiteCertificate 读取站点完整详情后只替换 cert_id 提交，再回读确认站点引用了新证书。
+func (client *safeLineClient) bindSiteCertificate(ctx context.Context, siteID, certificateID int64) error {
+	endpoint := fmt.Sprintf("%s/%d", safeLineSitePath, siteID)
+	// 雷池站点更新接口需要提交完整站点配置，保留原始字段避免丢失未识别的设置。
+	var detail map[string]json.RawMessage
+	if err := client.request(ctx, http.MethodGet, endpoint, nil, &detail); err != nil {
+		return fmt.Errorf("读取雷池站点详情失败: %w", err)
+	}
+	detail["id"] = json.RawMessage(strconv.FormatInt(siteID, 10))
+	detail["cert_id"] = json.RawMessage(strconv.FormatInt(certificateID, 10))
+	if err := client.request(ctx, http.MethodPut, safeLineSitePath, detail, nil); err != nil {
+		return fmt.Errorf("更新雷池站点证书失败: %w", err)
+	}
+	var updated safeLineSite
+	if err := client.request(ctx, http.MethodGet, endpoint, nil, &updated); err != nil {
+		return fmt.Errorf("回读雷池站点失败: %w", err)
+	}
+	if updated.CertID != certificateID {
+		return errors.New("雷池站点回读证书 ID 不一致")
+	}
+	return nil
+}
+
+// removeUnreferencedCertificate 在没有任何站点继续引用旧证书时删除该证书。
+func (client *safeLineClient) removeUnreferencedCertificate(ctx context.Context, certificateID int64) error {
+	sites, err := client.listSites(ctx)
+	if err != nil {
+		return err
+	}
+	for _, site := range sites {
+		if site.CertID == certificateID {
+			return nil
+		}
+	}
+	if err := client.deleteCertificate(ctx, certificateID); err != nil {
+		return err
+	}
+	logger.Info("已删除未被引用的雷池旧证书")
+	return nil
+}
+
+// listSites 分页读取全部雷池站点。
+func (client *safeLineClient) listSites(ctx context.Context) ([]safeLineSite, error) {
+	sites := make([]safeLineSite, 0)
+	for page := 1; page <= safeLineSiteMaxPages; page++ {
+		var pageData safeLineSiteList
+		query := url.Values{"page": {strconv.Itoa(page)}, "page_size": {strconv.Itoa(safeLineSitePageSize)}}
+		if err := client.request(ctx, http.MethodGet, safeLineSitePath+"?"+query.Encode(), nil, &pageData); err != nil {
+			return nil, fmt.Errorf("读取雷池站点列表失败: %w", err)
+		}
+		pageSites := pageData.Data
+		if len(pageSites) == 0 {
+			pageSites = pageData.Nodes
+		}
+		sites = append(sites, pageSites...)
+		if len(pageSites) < safeLineSitePageSize || (pageData.Total > 0 && len(sites) >= pageData.Total) {
+			return sites, nil
+		}
+	}
+	return nil, errors.New("雷池站点分页超过安全上限")
+}
+
+// loadSiteRecords 读取全部站点并生成脱敏资源，跳过没有域名的站点。
+func (client *safeLineClient) loadSiteRecords(ctx context.Context) ([]safeLineSiteRecord, error) {
+	sites, err := client.listSites(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]safeLineSiteRecord, 0, len(sites))
+	for _, site := range sites {
+		if site.ID <= 0 {
+			return nil, errors.New("雷池站点缺少生成稳定引用所需的 ID")
+		}
+		domains := normalizeSafeLineDomains(site.ServerNames)
+		if len(domains) == 0 {
+			continue
+		}
+		status := SafeLineSiteStatusEnabled
+		if site.IsEnabled != nil && !*site.IsEnabled {
+			status = SafeLineSiteStatusDisabled
+		}
+		label := strings.TrimSpace(site.Title)
+		if label == "" {
+			label = strings.TrimSpace(site.Comment)
+		}
+		if label == "" {
+			label = domains[0]
+		}
+		records = append(records, safeLineSiteRecord{
+			Site: site,
+			Resource: SafeLineSiteResource{
+				TargetRef: client.buildSiteTargetRef(site),
+				Label:     label,
+				Domain:    domains[0],
+				Domains:   domains,
+				Ports:     append([]string(nil), site.Ports...),
+				Protocol:  safeLineSiteProtocol(site.Ports),
+				Status:    status,
+			},
+		})
+	}
+	sort.SliceStable(records, func(left, right int) bool {
+		return records[left].Resource.Label < records[right].Resource.Label
+	})
+	return records, nil
+}
+
+// findSiteClient 按 targetRef 前缀定位站点所属的雷池实例。
+func findSiteClient(ctx context.Context, targetRef string) (*safeLineClient, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("雷池站点 targetRef 不能为空")
+	}
+	clients, err := newSafeLineClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, client := range clients {
+		if shared.MatchInstanceTargetRef(targetRef, shared.InstanceTargetRefPrefix(safeLineSiteTargetRefPrefix, client.name)) {
+			return client, nil
+		}
+	}
+	return nil, errors.New("雷池站点所属实例不存在或已重命名，请重新配置部署目标")
+}
+
+// findSiteByTargetRef 重新读取站点并要求 targetRef 唯一匹配。
+func (client *safeLineClient) findSiteByTargetRef(ctx context.Context, targetRef string) (*safeLineSiteRecord, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("雷池站点 targetRef 不能为空")
+	}
+	records, err := client.loadSiteRecords(ctx)
+	if err != nil {
+		return nil, client.instanceError(err)
+	}
+	var matched *safeLineSiteRecord
+	for index := range records {
+		if records[index].Resource.TargetRef != targetRef {
+			continue
+		}
+		if matched != nil {
+			return nil, errors.New("雷池站点 targetRef 不唯一，请重新配置部署目标")
+		}
+		matched = &records[index]
+	}
+	if matched == nil {
+		return nil, errors.New("雷池站点不存在或已重新创建，请重新配置部署目标")
+	}
+	return matched, nil
+}
+
+// buildSiteTargetRef 根据管理端地址、站点 ID 和创建时间生成稳定的不透明引用；具名实例的名称同时进入前缀和摘要。
+func (client *safeLineClient) buildSiteTargetRef(site safeLineSite) string {
+	parts := []string{
+		"ansslCli",
+		"DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_SITE_CERT",
+		strings.ToLower(client.baseURL),
+		strconv.FormatInt(site.ID, 10),
+		strings.TrimSpace(site.CreateTime),
+	}
+	if client.name != "" {
+		parts = append(parts, client.name)
+	}
+	digest := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return shared.InstanceTargetRefPrefix(safeLineSiteTargetRefPrefix, client.name) + hex.EncodeToString(digest[:12])
+}
+
+// safeLineSiteProtocol 根据端口是否带 ssl 标记判断站点协议。
+func safeLineSiteProtocol(ports []string) string {
+	for _, port := range ports {
+		if strings.Contains(strings.ToLower(port), "ssl") {
+			return safeLineProtocolHTTPS
+		}
+	}
+	return safeLineProtocolHTTP
+}
@@ -52,6 +52,46 @@ func ValidateCertificateFiles(sourceDir, domain string) error {
 	return nil
 }
 
+// SecondaryCertificateDir 是证书归档中第二组证书和私钥所在的子目录，文件名与主证书相同，用于 RSA 与 ECDSA 双证书部署。
+const SecondaryCertificateDir = "secondary"
+
+// HasSecondaryCertificate 判断证书目录中是否包含第二组证书或私钥。
+func HasSecondaryCertificate(certDir string) bool {
+	for _, name := range []string{"cert.pem", "privateKey.key"} {
+		if _, err := os.Stat(filepath.Join(certDir, SecondaryCertificateDir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCertificateFileSet 校验主证书和可选的第二组证书，两组证书必须使用不同的公钥算法。
+func ValidateCertificateFileSet(sourceDir, domain string) error {
+	if err := ValidateCertificateFiles(sourceDir, domain); err != nil {
+		return err
+	}
+	if !HasSecondaryCertificate(sourceDir) {
+		return nil
+	}
+	secondaryDir := filepath.Join(sourceDir, SecondaryCertificateDir)
+	if err := ValidateCertificateFiles(secondaryDir, domain); err != nil {
+		return fmt.Errorf("第二组证书不可用: %w", err)
+	}
+	primary, err := ParseLeafCertificate(filepath.Join(sourceDir, "cert.pem"))
+	if err != nil {
+		return fmt.Errorf("证书文件不可用: %w", err)
+	}
+	secondary, err := ParseLeafCertificate(filepath.Join(secondaryDir, "cert.pem"))
+	if err != nil {
+		return fmt.Errorf("第二组证书不可用: %w", err)
+	}
+	// Nginx 和 Apache 按密钥类型选择证书，同类型的两组证书只会有一组生效。
+	if primary.PublicKeyAlgorithm == secondary.PublicKeyAlgorithm {
+		return fmt.Errorf("两组证书使用相同的公钥算法: %s", primary.PublicKeyAlgorithm)
+	}
+	return nil
+}
+
 // ParseLeafCertificate 读取 PEM 证书文件并解析第一个 CERTIFICATE 块。
 func ParseLeafCertificate(certPath string) (*x509.Certificate, error) {
 	certPEM, err := os.ReadFile(certPath)
@@ -84,7 +124,11 @@ func parsePrivateKey(keyPath string) (crypto.PrivateKey, error) {
 	if err != nil {
 		return nil, err
 	}
+	return parsePrivateKeyPEM(keyPEM)
+}
 
+// parsePrivateKeyPEM 解析内存中的 PEM 私钥，兼容 PKCS#1、PKCS#8 和 EC 私钥格式。
+func parsePrivateKeyPEM(keyPEM []byte) (crypto.PrivateKey, error) {
 	block, _ := pem.Decode(keyPEM)
 	if block == nil {
 		return nil, fmt.Errorf("未找到 PEM 私钥块")
@@ -0,0 +1,33 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// RunShellCommandWithContext 通过 /bin/sh -c 执行用户配置的命令，附加 env 环境变量并在超时后终止。
+func RunShellCommandWithContext(parent context.Context, command string, timeout time.Duration, env []string) error {
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.Env = append(os.Environ(), env...)
+	// 命令可能启动后台子进程继承输出管道，终止后最多再等待一小段时间收集输出。
+	cmd.WaitDelay = time.Second
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("命令执行超时 (%s): %s", timeout, command)
+	}
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileBackup 记录部署前的单个文件，用于失败时原样恢复。
+type FileBackup struct {
+	path    string
+	exists  bool
+	link    string // link 非空表示原路径是指向该位置的符号链接。
+	content []byte
+	info    os.FileInfo
+}
+
+// BackupFile 读取部署前的文件内容、权限和属主，符号链接只记录链接本身。
+func BackupFile(path string) (FileBackup, error) {
+	backup := FileBackup{path: path}
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return backup, nil
+	}
+	if err != nil {
+		return backup, fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	backup.exists, backup.info = true, info
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		if backup.link, err = os.Readlink(path); err != nil {
+			return backup, fmt.Errorf("读取符号链接 %s 失败: %w", path, err)
+		}
+	case info.Mode().IsRegular():
+		if backup.content, err = os.ReadFile(path); err != nil {
+			return backup, fmt.Errorf("读取 %s 失败: %w", path, err)
+		}
+	default:
+		return backup, fmt.Errorf("%s 不是普通文件", path)
+	}
+	return backup, nil
+}
+
+// RestoreFiles 把文件恢复为部署前的状态，部署前不存在的文件会被删除。
+func RestoreFiles(backups []FileBackup) error {
+	var failures []error
+	for _, backup := range backups {
+		var err error
+		switch {
+		case !backup.exists:
+			if err = os.Remove(backup.path); errors.Is(err, fs.ErrNotExist) {
+				err = nil
+			}
+		case backup.link != "":
+			if err = os.Remove(backup.path); err == nil || errors.Is(err, fs.ErrNotExist) {
+				err = os.Symlink(backup.link, backup.path)
+			}
+		default:
+			uid, gid, ok := FileOwnership(backup.info)
+			if !ok {
+				uid, gid = -1, -1
+			}
+			err = ReplaceFile(backup.path, backup.content, backup.info.Mode().Perm(), uid, gid)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", backup.path, err))
+		}
+	}
+	return errors.Join(failures...)
+}
+
+// ReplaceFile 在同一目录写入临时文件并设置权限和属主后重命名，服务进程不会读到写了一半的文件；uid 和 gid 为 -1 时保持不变。
+func ReplaceFile(path string, content []byte, mode os.FileMode, uid, gid int) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), ".anssl-replace-*")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	defer os.Remove(tempPath)
+	if _, err := temp.Write(content); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tempPath, mode); err != nil {
+		return err
+	}
+	if uid >= 0 || gid >= 0 {
+		if err := os.Chown(tempPath, uid, gid); err != nil {
+			return fmt.Errorf("设置属主失败: %w", err)
+		}
+	}
+	return os.Rename(tempPath, path)
+}
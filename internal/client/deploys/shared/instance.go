@@ -0,0 +1,29 @@
+package shared
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// instanceTargetRefDigestLength 是面板资源 targetRef 末尾摘要的十六进制长度。
+const instanceTargetRefDigestLength = 24
+
+// InstanceTargetRefPrefix 为具名面板实例在 targetRef 前缀后加入短实例标记；未命名的兼容实例保持原前缀，已保存的部署目标不受影响。
+func InstanceTargetRefPrefix(prefix, instanceName string) string {
+	if instanceName == "" {
+		return prefix
+	}
+	tag := sha256.Sum256([]byte("ansslCli\x00instance\x00" + instanceName))
+	return prefix + hex.EncodeToString(tag[:4]) + "-"
+}
+
+// MatchInstanceTargetRef 判断 targetRef 是否由该实例前缀和 12 字节十六进制摘要组成，用于部署时直接定位所属实例。
+func MatchInstanceTargetRef(targetRef, instancePrefix string) bool {
+	digest, ok := strings.CutPrefix(targetRef, instancePrefix)
+	if !ok || len(digest) != instanceTargetRefDigestLength {
+		return false
+	}
+	_, err := hex.DecodeString(digest)
+	return err == nil
+}
@@ -0,0 +1,35 @@
+package shared
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+)
+
+// ResolveOwnership 把用户名、组名或数字 ID 解析为 chown 参数，未配置时返回 -1。
+func ResolveOwnership(owner, group string) (int, int, error) {
+	uid, gid := -1, -1
+	if owner != "" {
+		if id, err := strconv.Atoi(owner); err == nil {
+			uid = id
+		} else {
+			account, err := user.Lookup(owner)
+			if err != nil {
+				return 0, 0, fmt.Errorf("解析文件属主 %s 失败: %w", owner, err)
+			}
+			uid, _ = strconv.Atoi(account.Uid)
+		}
+	}
+	if group != "" {
+		if id, err := strconv.Atoi(group); err == nil {
+			gid = id
+		} else {
+			accountGroup, err := user.LookupGroup(group)
+			if err != nil {
+				return 0, 0, fmt.Errorf("解析文件属组 %s 失败: %w", group, err)
+			}
+			gid, _ = strconv.Atoi(accountGroup.Gid)
+		}
+	}
+	return uid, gid, nil
+}
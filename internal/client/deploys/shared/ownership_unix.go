@@ -0,0 +1,17 @@
+//go:build !windows
+
+package shared
+
+import (
+	"os"
+	"syscall"
+)
+
+// FileOwnership 返回文件的属主和属组，供回滚或替换文件时沿用原文件身份。
+func FileOwnership(info os.FileInfo) (int, int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
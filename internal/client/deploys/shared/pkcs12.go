@@ -0,0 +1,361 @@
+package shared
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"unicode/utf16"
+)
+
+const (
+	// pkcs12Iterations 与 OpenSSL 3 默认值一致，兼顾 JVM 加载速度和口令强度。
+	pkcs12Iterations = 2048
+	pkcs12SaltLength = 16
+)
+
+var (
+	oidPKCS7Data            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidPKCS12CertBag        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidPKCS12ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidPKCS9FriendlyName    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidPKCS9LocalKeyID      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+	oidPKCS9X509Certificate = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidPBES2                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA256       = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES256CBC            = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidSHA256               = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+)
+
+type pkcs12PFX struct {
+	Version  int
+	AuthSafe pkcs12ContentInfo
+	MacData  pkcs12MacData
+}
+
+type pkcs12ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit"`
+}
+
+type pkcs12MacData struct {
+	Mac        pkcs12DigestInfo
+	MacSalt    []byte
+	Iterations int
+}
+
+type pkcs12DigestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type pkcs12SafeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type pkcs12CertBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type pkcs12EncryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pkcs12PBES2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pkcs12PBKDF2Params struct {
+	Salt       []byte
+	Iterations int
+	PRF        pkix.AlgorithmIdentifier
+}
+
+// LoadCertificateChainAndKey 读取证书目录中的完整证书链和私钥，证书链第一个证书为叶证书。
+func LoadCertificateChainAndKey(sourceDir string) ([]*x509.Certificate, crypto.PrivateKey, error) {
+	certPEM, err := os.ReadFile(filepath.Join(sourceDir, "cert.pem"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取证书文件失败: %w", err)
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(sourceDir, "privateKey.key"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("私钥文件不可用: %w", err)
+	}
+	return ParseCertificateChainAndKey(certPEM, keyPEM)
+}
+
+// ParseCertificateChainAndKey 解析内存中的 PEM 证书链和私钥，证书链第一个证书为叶证书。
+func ParseCertificateChainAndKey(certPEM, keyPEM []byte) ([]*x509.Certificate, crypto.PrivateKey, error) {
+	var chain []*x509.Certificate
+	for {
+		block, rest := pem.Decode(certPEM)
+		if block == nil {
+			break
+		}
+		certPEM = rest
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("解析证书链失败: %w", err)
+		}
+		chain = append(chain, certificate)
+	}
+	if len(chain) == 0 {
+		return nil, nil, fmt.Errorf("未找到 PEM 证书块")
+	}
+	privateKey, err := parsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("私钥不可用: %w", err)
+	}
+	return chain, privateKey, nil
+}
+
+// EncodePKCS12 生成带别名的 PKCS#12 密钥库，私钥使用 PBES2/AES-256-CBC 加密，完整性使用 HMAC-SHA256；password 为空时按空口令加密，与 openssl pkcs12 -passout pass: 一致。
+func EncodePKCS12(privateKey crypto.PrivateKey, chain []*x509.Certificate, alias, password string) ([]byte, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("证书链不能为空")
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("编码私钥失败: %w", err)
+	}
+
+	// Java 以 friendlyName 作为条目别名，并通过 localKeyId 关联叶证书和私钥。
+	leafDigest := sha1.Sum(chain[0].Raw)
+	localKeyID, err := newPKCS12Attribute(oidPKCS9LocalKeyID, asn1.RawValue{Tag: asn1.TagOctetString, Bytes: leafDigest[:]})
+	if err != nil {
+		return nil, err
+	}
+	attributes := []pkcs12Attribute{localKeyID}
+	if alias != "" {
+		friendlyName, err := newPKCS12Attribute(oidPKCS9FriendlyName, asn1.RawValue{Tag: asn1.TagBMPString, Bytes: encodeBMPString(alias)})
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, friendlyName)
+	}
+
+	certBags := make([]pkcs12SafeBag, 0, len(chain))
+	for index, certificate := range chain {
+		certBagDER, err := asn1.Marshal(pkcs12CertBag{ID: oidPKCS9X509Certificate, Data: certificate.Raw})
+		if err != nil {
+			return nil, err
+		}
+		bag := pkcs12SafeBag{ID: oidPKCS12CertBag, Value: explicitPKCS12Value(certBagDER)}
+		if index == 0 {
+			bag.Attributes = attributes
+		}
+		certBags = append(certBags, bag)
+	}
+	shroudedKey, err := encryptPKCS12PrivateKey(keyDER, password)
+	if err != nil {
+		return nil, err
+	}
+	keyBags := []pkcs12SafeBag{{ID: oidPKCS12ShroudedKeyBag, Value: explicitPKCS12Value(shroudedKey), Attributes: attributes}}
+
+	// 证书属于公开信息，以明文 SafeContents 保存，私钥单独加密。
+	authenticatedSafe := make([]pkcs12ContentInfo, 0, 2)
+	for _, bags := range [][]pkcs12SafeBag{certBags, keyBags} {
+		contentInfo, err := newPKCS12DataContentInfo(bags)
+		if err != nil {
+			return nil, err
+		}
+		authenticatedSafe = append(authenticatedSafe, contentInfo)
+	}
+	authenticatedSafeDER, err := asn1.Marshal(authenticatedSafe)
+	if err != nil {
+		return nil, err
+	}
+
+	macSalt, err := randomBytes(pkcs12SaltLength)
+	if err != nil {
+		return nil, err
+	}
+	macKey := derivePKCS12Key(sha256.New, 64, macSalt, append(encodeBMPString(password), 0, 0), pkcs12Iterations, 3, sha256.Size)
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(authenticatedSafeDER)
+
+	authSafeContent, err := asn1.Marshal(authenticatedSafeDER)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs12PFX{
+		Version:  3,
+		AuthSafe: pkcs12ContentInfo{ContentType: oidPKCS7Data, Content: explicitPKCS12Value(authSafeContent)},
+		MacData: pkcs12MacData{
+			Mac:        pkcs12DigestInfo{Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue}, Digest: mac.Sum(nil)},
+			MacSalt:    macSalt,
+			Iterations: pkcs12Iterations,
+		},
+	})
+}
+
+// encryptPKCS12PrivateKey 使用 PBES2 加密 PKCS#8 私钥，口令按 RFC 8018 使用 UTF-8 字节。
+func encryptPKCS12PrivateKey(keyDER []byte, password string) ([]byte, error) {
+	salt, err := randomBytes(pkcs12SaltLength)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := randomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, pkcs12Iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padding := aes.BlockSize - len(keyDER)%aes.BlockSize
+	encrypted := append(append([]byte(nil), keyDER...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+
+	kdfParams, err := asn1.Marshal(pkcs12PBKDF2Params{
+		Salt:       salt,
+		Iterations: pkcs12Iterations,
+		PRF:        pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivDER, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	pbes2Params, err := asn1.Marshal(pkcs12PBES2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivDER}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs12EncryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: pbes2Params}},
+		EncryptedData: encrypted,
+	})
+}
+
+// newPKCS12DataContentInfo 把 SafeBag 列表封装为明文 data ContentInfo。
+func newPKCS12DataContentInfo(bags []pkcs12SafeBag) (pkcs12ContentInfo, error) {
+	safeContents, err := asn1.Marshal(bags)
+	if err != nil {
+		return pkcs12ContentInfo{}, err
+	}
+	content, err := asn1.Marshal(safeContents)
+	if err != nil {
+		return pkcs12ContentInfo{}, err
+	}
+	return pkcs12ContentInfo{ContentType: oidPKCS7Data, Content: explicitPKCS12Value(content)}, nil
+}
+
+// newPKCS12Attribute 生成只有一个取值的 PKCS#9 属性。
+func newPKCS12Attribute(id asn1.ObjectIdentifier, value asn1.RawValue) (pkcs12Attribute, error) {
+	encoded, err := asn1.Marshal(value)
+	if err != nil {
+		return pkcs12Attribute{}, err
+	}
+	return pkcs12Attribute{ID: id, Value: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: encoded}}, nil
+}
+
+// explicitPKCS12Value 生成 [0] EXPLICIT 包装的取值。
+func explicitPKCS12Value(encoded []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: encoded}
+}
+
+// derivePKCS12Key 实现 RFC 7292 附录 B.2 的密钥派生，仅用于 MAC 密钥。
+func derivePKCS12Key(newHash func() hash.Hash, blockSize int, salt, password []byte, iterations int, id byte, size int) []byte {
+	diversifier := bytes.Repeat([]byte{id}, blockSize)
+	input := append(repeatToBlocks(salt, blockSize), repeatToBlocks(password, blockSize)...)
+	result := make([]byte, 0, size)
+	for len(result) < size {
+		digest := newHash()
+		digest.Write(diversifier)
+		digest.Write(input)
+		sum := digest.Sum(nil)
+		for round := 1; round < iterations; round++ {
+			digest.Reset()
+			digest.Write(sum)
+			sum = digest.Sum(nil)
+		}
+		result = append(result, sum...)
+		if len(result) >= size {
+			break
+		}
+		// 每一块 I_j = (I_j + B + 1) mod 2^(v*8)，B 为 sum 重复填满 v 字节。
+		adder := repeatToLength(sum, blockSize)
+		for offset := 0; offset < len(input); offset += blockSize {
+			carry := 1
+			for index := blockSize - 1; index >= 0; index-- {
+				value := int(input[offset+index]) + int(adder[index]) + carry
+				input[offset+index] = byte(value)
+				carry = value >> 8
+			}
+		}
+	}
+	return result[:size]
+}
+
+// repeatToBlocks 把数据重复填充到 blockSize 的整数倍，空数据保持为空。
+func repeatToBlocks(data []byte, blockSize int) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	return repeatToLength(data, blockSize*((len(data)+blockSize-1)/blockSize))
+}
+
+// repeatToLength 把数据循环重复到指定长度。
+func repeatToLength(data []byte, length int) []byte {
+	result := make([]byte, length)
+	for index := range result {
+		result[index] = data[index%len(data)]
+	}
+	return result
+}
+
+// encodeBMPString 把字符串编码为 UTF-16BE，不带结尾零字符。
+func encodeBMPString(value string) []byte {
+	encoded := utf16.Encode([]rune(value))
+	result := make([]byte, 0, len(encoded)*2)
+	for _, unit := range encoded {
+		result = append(result, byte(unit>>8), byte(unit))
+	}
+	return result
+}
+
+// randomBytes 读取指定长度的安全随机数。
+func randomBytes(length int) ([]byte, error) {
+	result := make([]byte, length)
+	if _, err := rand.Read(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
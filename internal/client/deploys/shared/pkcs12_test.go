@@ -0,0 +1,84 @@
+package shared
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+// TestEncodePKCS12RoundTrip 验证生成的密钥库可被独立实现解码，且叶证书和私钥带有相同别名。
+func TestEncodePKCS12RoundTrip(t *testing.T) {
+	caKey, caCertificate := newPKCS12TestCertificate(t, "Test CA", nil, nil)
+	leafKey, leafCertificate := newPKCS12TestCertificate(t, "www.example.com", caCertificate, caKey)
+
+	data, err := EncodePKCS12(leafKey, []*x509.Certificate{leafCertificate, caCertificate}, "tomcat", "pässwörd")
+	if err != nil {
+		t.Fatalf("EncodePKCS12: %v", err)
+	}
+	privateKey, certificate, caCertificates, err := pkcs12.DecodeChain(data, "pässwörd")
+	if err != nil {
+		t.Fatalf("DecodeChain: %v", err)
+	}
+	if !certificate.Equal(leafCertificate) || len(caCertificates) != 1 || !caCertificates[0].Equal(caCertificate) {
+		t.Fatalf("证书链不匹配: leaf=%s ca=%d", certificate.Subject, len(caCertificates))
+	}
+	if decodedKey, ok := privateKey.(*ecdsa.PrivateKey); !ok || !decodedKey.Equal(leafKey) {
+		t.Fatal("私钥不匹配")
+	}
+	if _, _, _, err := pkcs12.DecodeChain(data, "wrong"); err == nil {
+		t.Fatal("错误密码应无法解码")
+	}
+
+	blocks, err := pkcs12.ToPEM(data, "pässwörd")
+	if err != nil {
+		t.Fatalf("ToPEM: %v", err)
+	}
+	aliased := 0
+	for _, block := range blocks {
+		if block.Headers["friendlyName"] == "tomcat" {
+			aliased++
+		}
+	}
+	if aliased != 2 {
+		t.Fatalf("叶证书和私钥应带有别名，实际 %d 个", aliased)
+	}
+}
+
+// newPKCS12TestCertificate 生成 ECDSA 测试证书，issuer 为空时生成自签 CA。
+func newPKCS12TestCertificate(t *testing.T, commonName string, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if issuer == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		issuer, issuerKey = template, privateKey
+	} else {
+		template.DNSNames = []string{commonName}
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, issuer, &privateKey.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificate, err := x509.ParseCertificate(certificateDER)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return privateKey, certificate
+}
@@ -0,0 +1,92 @@
+package shared
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"net"
+	"time"
+)
+
+// ServedLeafFetcher 建立一次连接完成 TLS 握手，返回服务端提供的叶证书 DER。
+type ServedLeafFetcher func(ctx context.Context) ([]byte, error)
+
+// ServedLeafTLSConfig 返回在握手时把服务端叶证书记录到 leaf 的 TLS 配置。
+// 只比较叶证书指纹，不依赖本机信任链，自签或内网 CA 证书同样可以校验。
+func ServedLeafTLSConfig(serverName string, leaf *[]byte) *tls.Config {
+	return &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS12,
+		// 在回调中记录叶证书，服务端要求客户端证书而随后中断握手时同样可以比较。
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) > 0 {
+				*leaf = rawCerts[0]
+			}
+			return nil
+		},
+	}
+}
+
+// HandshakeServedLeaf 在已建立的连接上完成 TLS 握手并返回服务端叶证书，供需要先协商协议的端口使用；调用方负责关闭连接。
+func HandshakeServedLeaf(ctx context.Context, conn net.Conn, serverName string) ([]byte, error) {
+	var leaf []byte
+	err := tls.Client(conn, ServedLeafTLSConfig(serverName, &leaf)).HandshakeContext(ctx)
+	if leaf == nil {
+		if err == nil {
+			err = errors.New("服务端未返回证书")
+		}
+		return nil, err
+	}
+	return leaf, nil
+}
+
+// DialServedLeaf 连接直接使用 TLS 的地址并返回服务端叶证书。
+func DialServedLeaf(ctx context.Context, address, serverName string) ([]byte, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	return HandshakeServedLeaf(ctx, conn, serverName)
+}
+
+// WaitForServedCertificate 在 deadline 前轮询 fetch，直到服务端叶证书的 SHA-256 指纹等于 fingerprint；
+// fingerprint 使用 providers.LeafCertificateSHA256 返回的小写十六进制格式。
+func WaitForServedCertificate(ctx context.Context, fingerprint string, deadline time.Time, interval time.Duration, fetch ServedLeafFetcher) error {
+	return WaitUntil(ctx, deadline, interval, func(ctx context.Context) error {
+		leaf, err := fetch(ctx)
+		if err != nil {
+			return err
+		}
+		served := sha256.Sum256(leaf)
+		if hex.EncodeToString(served[:]) != fingerprint {
+			return errors.New("服务端返回的叶证书与新证书指纹不一致")
+		}
+		return nil
+	})
+}
+
+// WaitUntil 在 deadline 前按 interval 重试 check 直到成功，服务重启或异步重新加载期间的失败不立即返回，超时后返回最后一次的错误。
+func WaitUntil(ctx context.Context, deadline time.Time, interval time.Duration, check func(context.Context) error) error {
+	for {
+		err := check(ctx)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
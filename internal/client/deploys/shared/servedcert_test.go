@@ -0,0 +1,35 @@
+package shared
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWaitForServedCertificateComparesLeafFingerprint 验证自签证书可以通过叶证书指纹校验，指纹不一致时在超时后返回最后一次的错误。
+func TestWaitForServedCertificateComparesLeafFingerprint(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	served := sha256.Sum256(server.Certificate().Raw)
+	fetch := func(ctx context.Context) ([]byte, error) {
+		return DialServedLeaf(ctx, server.Listener.Addr().String(), "example.com")
+	}
+
+	if err := WaitForServedCertificate(context.Background(), hex.EncodeToString(served[:]), time.Now(), time.Millisecond, fetch); err != nil {
+		t.Fatalf("WaitForServedCertificate() error = %v", err)
+	}
+
+	attempts := 0
+	err := WaitForServedCertificate(context.Background(), strings.Repeat("0", 64), time.Now().Add(20*time.Millisecond), time.Millisecond, func(ctx context.Context) ([]byte, error) {
+		attempts++
+		return fetch(ctx)
+	})
+	if err == nil || !strings.Contains(err.Error(), "指纹不一致") || attempts < 2 {
+		t.Fatalf("指纹不一致时应重试到超时后返回错误: attempts=%d err=%v", attempts, err)
+	}
+}
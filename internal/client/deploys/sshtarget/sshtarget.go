@@ -0,0 +1,355 @@
+package sshtarget
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/remote"
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+const (
+	sshTargetPrefix     = "ssh-target-"
+	sshTargetTempPrefix = "anssl-sshtarget"
+	sshRollbackTimeout  = 2 * time.Minute
+	// SSHTargetStatusReady 表示实例已配置，部署时才建立 SSH 连接。
+	SSHTargetStatusReady = "Ready"
+)
+
+// sshTargetLocks 按实例名称串行化部署，避免同一远程目录的备份和发布交错。
+var sshTargetLocks sync.Map
+
+// SSHTargetResource 是可以安全上报到 anSSL 后端的 SSH 远程主机实例资源。
+type SSHTargetResource struct {
+	TargetRef string // TargetRef 是客户端根据实例名称和主机地址生成的不透明稳定引用。
+	Label     string // Label 是配置中的实例名称。
+	Host      string // Host 是 host:port 形式的远程地址。
+	Path      string // Path 是远程证书根目录。
+	Status    string // Status 是实例状态。
+}
+
+// publishLayout 描述一次远程发布使用的目标、暂存和备份目录。
+type publishLayout struct {
+	basePath   string // basePath 是远程证书根目录。
+	targetDir  string // targetDir 是 path/<域名> 发布目录。
+	stagingDir string // stagingDir 是与目标目录同级的暂存目录。
+	backupDir  string // backupDir 是命令执行完成前保留的旧目录。
+}
+
+// IsSSHTargetConfiguredWithContext 从 context 快照判断是否配置了 SSH 远程主机实例。
+func IsSSHTargetConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && len(configuration.SSL.SSHTargets) > 0
+}
+
+// IsSSHTargetErrorRetryable 判断 SSH 部署错误是否属于连接超时等可稍后重试的情况。
+func IsSSHTargetErrorRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var networkError net.Error
+	return errors.As(err, &networkError) && networkError.Timeout()
+}
+
+// DiscoverSSHTargetResources 把配置中的每台远程主机列为一个部署资源，发现阶段不建立 SSH 连接。
+func DiscoverSSHTargetResources(ctx context.Context) ([]SSHTargetResource, error) {
+	targets, err := getSSHTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]SSHTargetResource, 0, len(targets))
+	for _, target := range targets {
+		resources = append(resources, SSHTargetResource{
+			TargetRef: buildSSHTargetRef(target),
+			Label:     target.Name,
+			Host:      net.JoinHostPort(target.Host, strconv.Itoa(target.Port)),
+			Path:      target.Path,
+			Status:    SSHTargetStatusReady,
+		})
+	}
+	return resources, nil
+}
+
+// TestSSHTargetConnection 验证 SSH 登录、远程基础命令和目录写入权限，不执行校验或重载命令。
+func TestSSHTargetConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	target, err := findSSHTarget(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	executor, err := remote.NewExecutorContext(ctx, &target.SSHConfig, sshTargetPurpose(target), sshTargetTempPrefix, knownHostsFile(ctx))
+	if err != nil {
+		return err
+	}
+	defer executor.Close()
+
+	for _, command := range []string{"install", "mktemp", "mv", "rm"} {
+		if _, err := executor.RunContext(ctx, "command -v "+remote.QuotePOSIXShellArg(command)+" >/dev/null 2>&1", nil, false); err != nil {
+			return fmt.Errorf("SSH 主机 %s 缺少命令 %s: %w", target.Name, command, err)
+		}
+	}
+	privileged, err := needsPrivilege(ctx, executor, target)
+	if err != nil {
+		return fmt.Errorf("检查 SSH 主机 %s 目录权限失败: %w", target.Name, err)
+	}
+	probeFile := path.Join(target.Path, ".anssl-write-check-"+strconv.FormatInt(time.Now().UnixNano(), 10))
+	command := "install -d -m 0755 -- " + remote.QuotePOSIXShellArg(target.Path) + " && " +
+		"umask 077 && : > " + remote.QuotePOSIXShellArg(probeFile) + " && rm -f -- " + remote.QuotePOSIXShellArg(probeFile)
+	if _, err := executor.RunContext(ctx, command, nil, privileged); err != nil {
+		return fmt.Errorf("SSH 主机 %s 证书目录不可写: %w", target.Name, err)
+	}
+	if target.Sudo && !executor.IsRoot() {
+		if _, err := executor.RunContext(ctx, "true", nil, true); err != nil {
+			return fmt.Errorf("SSH 主机 %s 无法通过 sudo 执行命令: %w", target.Name, err)
+		}
+	}
+	return nil
+}
+
+// DeployCertificateToSSHTarget 上传证书到远程 path/<域名>/，执行校验和重载命令，失败时恢复旧证书目录。
+func DeployCertificateToSSHTarget(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	target, err := findSSHTarget(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	canonicalDomain, safeDomain, err := shared.NormalizeDeploymentDomain(domain)
+	if err != nil {
+		return err
+	}
+
+	lock, _ := sshTargetLocks.LoadOrStore(target.Name, make(chan struct{}, 1))
+	select {
+	case lock.(chan struct{}) <- struct{}{}:
+		defer func() { <-lock.(chan struct{}) }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	executor, err := remote.NewExecutorContext(ctx, &target.SSHConfig, sshTargetPurpose(target), sshTargetTempPrefix, knownHostsFile(ctx))
+	if err != nil {
+		return err
+	}
+	defer executor.Close()
+
+	remoteTempDir, err := executor.CreateTempDirContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if cleanupErr := executor.RemoveTempDirContext(cleanupCtx, remoteTempDir); cleanupErr != nil {
+			logger.WarnLocal("清理 SSH 主机临时目录失败", "error", cleanupErr, "name", target.Name)
+		}
+	}()
+	remoteCertificateFile := path.Join(remoteTempDir, "cert.pem")
+	remotePrivateKeyFile := path.Join(remoteTempDir, "privateKey.key")
+	if err := executor.UploadContext(ctx, remoteCertificateFile, []byte(certificatePEM)); err != nil {
+		return fmt.Errorf("上传证书到 SSH 主机 %s 失败: %w", target.Name, err)
+	}
+	if err := executor.UploadContext(ctx, remotePrivateKeyFile, []byte(privateKeyPEM)); err != nil {
+		return fmt.Errorf("上传私钥到 SSH 主机 %s 失败: %w", target.Name, err)
+	}
+
+	privileged, err := needsPrivilege(ctx, executor, target)
+	if err != nil {
+		return err
+	}
+	layout := newPublishLayout(target.Path, safeDomain, strconv.FormatInt(time.Now().UnixNano(), 10))
+	if _, err := executor.RunContext(ctx, buildPublishScript(layout, target.CertificateFilesConfig, remoteCertificateFile, remotePrivateKeyFile), nil, privileged); err != nil {
+		return fmt.Errorf("发布 SSH 主机 %s 证书目录失败: %w", target.Name, err)
+	}
+
+	env := commandEnvironment(canonicalDomain, layout.targetDir, target.CertificateFilesConfig)
+	if err := runRemoteCommands(ctx, executor, target.ValidateCommands, env, target.Sudo); err != nil {
+		return restorePreviousFiles(executor, target, layout, privileged, nil, fmt.Errorf("SSH 主机 %s 校验命令执行失败: %w", target.Name, err))
+	}
+	if err := runRemoteCommands(ctx, executor, target.ReloadCommands, env, target.Sudo); err != nil {
+		return restorePreviousFiles(executor, target, layout, privileged, env, fmt.Errorf("SSH 主机 %s 重载命令执行失败: %w", target.Name, err))
+	}
+
+	if _, err := executor.RunContext(ctx, "rm -rf -- "+remote.QuotePOSIXShellArg(layout.backupDir), nil, privileged); err != nil {
+		logger.WarnLocal("删除 SSH 主机旧证书备份失败", "error", err, "name", target.Name, "path", layout.backupDir)
+	}
+	logger.InfoLocal("证书已通过 SSH 部署到远程主机", "name", target.Name, "host", target.Host, "path", layout.targetDir)
+	return nil
+}
+
+// restorePreviousFiles 恢复旧证书目录；reloadEnv 非空时表示重载已开始，需要让服务重新加载旧证书。
+func restorePreviousFiles(executor *remote.Executor, target *config.SSHTargetConfig, layout publishLayout, privileged bool, reloadEnv []string, cause error) error {
+	// 原操作可能已被取消，回滚使用独立的短超时 context。
+	ctx, cancel := context.WithTimeout(context.Background(), sshRollbackTimeout)
+	defer cancel()
+	if _, err := executor.RunContext(ctx, buildRestoreScript(layout), nil, privileged); err != nil {
+		return fmt.Errorf("%w，回滚失败: %v", cause, err)
+	}
+	if reloadEnv != nil {
+		if err := runRemoteCommands(ctx, executor, target.ReloadCommands, reloadEnv, target.Sudo); err != nil {
+			logger.WarnLocal("恢复旧证书后重新执行重载命令失败", "error", err, "name", target.Name)
+		}
+	}
+	return cause
+}
+
+// getSSHTargets 读取当前操作快照中的 SSH 远程主机实例。
+func getSSHTargets(ctx context.Context) ([]*config.SSHTargetConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || len(configuration.SSL.SSHTargets) == 0 {
+		return nil, errors.New("未配置 SSH 远程主机 (ssl.sshTargets)")
+	}
+	return configuration.SSL.SSHTargets, nil
+}
+
+// findSSHTarget 根据 targetRef 重新定位配置中的实例，实例改名或更换主机后引用失效。
+func findSSHTarget(ctx context.Context, targetRef string) (*config.SSHTargetConfig, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, errors.New("SSH 远程主机 targetRef 不能为空")
+	}
+	targets, err := getSSHTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		if buildSSHTargetRef(target) == targetRef {
+			return target, nil
+		}
+	}
+	return nil, errors.New("SSH 远程主机不存在或配置已变更，请重新配置部署目标")
+}
+
+// buildSSHTargetRef 根据实例名称和主机地址生成稳定的不透明引用。
+func buildSSHTargetRef(target *config.SSHTargetConfig) string {
+	identity := strings.Join([]string{
+		"ansslCli",
+		"DEPLOYMENT_TYPE_ANSSL_CLI_SSH_CERT",
+		target.Name,
+		strings.ToLower(target.Host),
+		strconv.Itoa(target.Port),
+	}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return sshTargetPrefix + hex.EncodeToString(digest[:12])
+}
+
+// sshTargetPurpose 返回日志和错误中使用的实例名称。
+func sshTargetPurpose(target *config.SSHTargetConfig) string {
+	return "SSH 主机 " + target.Name
+}
+
+// knownHostsFile 返回运行时配置的 known_hosts 路径，未设置时由 remote 使用默认值。
+func knownHostsFile(ctx context.Context) string {
+	if runtime := shared.RuntimeFromContext(ctx); runtime != nil {
+		return runtime.KnownHostsFile
+	}
+	return ""
+}
+
+// needsPrivilege 判断发布文件是否需要 sudo；设置属主时非 root 用户总是需要提权。
+func needsPrivilege(ctx context.Context, executor *remote.Executor, target *config.SSHTargetConfig) (bool, error) {
+	if (target.Owner != "" || target.Group != "") && !executor.IsRoot() {
+		if _, err := executor.RunContext(ctx, "true", nil, true); err != nil {
+			return false, fmt.Errorf("设置文件属主需要 root 或 sudo 权限: %w", err)
+		}
+		return true, nil
+	}
+	return executor.NeedsPrivilegeForPathContext(ctx, target.Path)
+}
+
+// newPublishLayout 在证书根目录下生成本次发布的同级暂存和备份目录。
+func newPublishLayout(basePath, safeDomain, token string) publishLayout {
+	return publishLayout{
+		basePath:   basePath,
+		targetDir:  path.Join(basePath, safeDomain),
+		stagingDir: path.Join(basePath, "."+safeDomain+".anssl-stage."+token),
+		backupDir:  path.Join(basePath, "."+safeDomain+".anssl-backup."+token),
+	}
+}
+
+// buildPublishScript 生成暂存并切换证书目录的脚本，旧目录移动到备份目录直到命令全部成功。
+func buildPublishScript(layout publishLayout, files config.CertificateFilesConfig, remoteCertificateFile, remotePrivateKeyFile string) string {
+	ownership := ""
+	if files.Owner != "" {
+		ownership += " -o " + remote.QuotePOSIXShellArg(files.Owner)
+	}
+	if files.Group != "" {
+		ownership += " -g " + remote.QuotePOSIXShellArg(files.Group)
+	}
+	targetDir := remote.QuotePOSIXShellArg(layout.targetDir)
+	stagingDir := remote.QuotePOSIXShellArg(layout.stagingDir)
+	backupDir := remote.QuotePOSIXShellArg(layout.backupDir)
+	commands := []string{
+		"set -eu",
+		"install -d -m 0755 -- " + remote.QuotePOSIXShellArg(layout.basePath),
+		"rm -rf -- " + stagingDir + " " + backupDir,
+		"install -d -m 0755" + ownership + " -- " + stagingDir,
+		"install -m " + files.CertMode + ownership + " -- " + remote.QuotePOSIXShellArg(remoteCertificateFile) + " " + remote.QuotePOSIXShellArg(path.Join(layout.stagingDir, files.CertFile)),
+		"install -m " + files.KeyMode + ownership + " -- " + remote.QuotePOSIXShellArg(remotePrivateKeyFile) + " " + remote.QuotePOSIXShellArg(path.Join(layout.stagingDir, files.KeyFile)),
+		"if [ -e " + targetDir + " ]; then mv -- " + targetDir + " " + backupDir + "; fi",
+		"if ! mv -- " + stagingDir + " " + targetDir + "; then rm -rf -- " + stagingDir + "; " +
+			"if [ -e " + backupDir + " ]; then mv -- " + backupDir + " " + targetDir + "; fi; exit 1; fi",
+	}
+	return strings.Join(commands, "; ")
+}
+
+// buildRestoreScript 生成删除新目录并恢复备份目录的脚本，首次部署时只删除新目录。
+func buildRestoreScript(layout publishLayout) string {
+	targetDir := remote.QuotePOSIXShellArg(layout.targetDir)
+	backupDir := remote.QuotePOSIXShellArg(layout.backupDir)
+	return "set -eu; rm -rf -- " + targetDir + "; if [ -e " + backupDir + " ]; then mv -- " + backupDir + " " + targetDir + "; fi"
+}
+
+// commandEnvironment 返回传给远程命令的证书位置环境变量。
+func commandEnvironment(domain, certDir string, files config.CertificateFilesConfig) []string {
+	return []string{
+		"ANSSL_DOMAIN=" + domain,
+		"ANSSL_CERT_DIR=" + certDir,
+		"ANSSL_CERT_FILE=" + path.Join(certDir, files.CertFile),
+		"ANSSL_KEY_FILE=" + path.Join(certDir, files.KeyFile),
+	}
+}
+
+// buildRemoteCommand 在用户命令前导出证书位置环境变量。
+func buildRemoteCommand(command string, env []string) string {
+	exports := make([]string, 0, len(env))
+	for _, variable := range env {
+		name, value, _ := strings.Cut(variable, "=")
+		exports = append(exports, name+"="+remote.QuotePOSIXShellArg(value))
+	}
+	return "export " + strings.Join(exports, " ") + "; " + command
+}
+
+// runRemoteCommands 按顺序执行远程命令，遇到第一个失败立即返回。
+func runRemoteCommands(ctx context.Context, executor *remote.Executor, commands []config.LocalCommandConfig, env []string, privileged bool) error {
+	for _, command := range commands {
+		timeout := time.Duration(command.Timeout) * time.Second
+		if _, err := executor.RunWithTimeoutContext(ctx, buildRemoteCommand(command.Command, env), nil, privileged, timeout); err != nil {
+			return err
+		}
+		logger.InfoLocal("SSH 远程命令执行成功", "command", command.Command)
+	}
+	return nil
+}
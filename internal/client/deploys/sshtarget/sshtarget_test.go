@@ -0,0 +1,107 @@
+package sshtarget
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestPublishAndRestoreScripts 在本机 shell 中执行远程发布脚本，验证文件权限、备份和回滚行为。
+func TestPublishAndRestoreScripts(t *testing.T) {
+	uploadDir := t.TempDir()
+	basePath := filepath.Join(t.TempDir(), "it's certs")
+	targetDir := filepath.Join(basePath, "example.com")
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		t.Fatalf("mkdir target: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "fullchain.pem"), []byte("old"), 0o644); err != nil {
+		t.Fatalf("write old certificate: %v", err)
+	}
+	remoteCertificateFile := filepath.Join(uploadDir, "cert.pem")
+	remotePrivateKeyFile := filepath.Join(uploadDir, "privateKey.key")
+	if err := os.WriteFile(remoteCertificateFile, []byte("new-cert"), 0o600); err != nil {
+		t.Fatalf("write upload cert: %v", err)
+	}
+	if err := os.WriteFile(remotePrivateKeyFile, []byte("new-key"), 0o600); err != nil {
+		t.Fatalf("write upload key: %v", err)
+	}
+	files := config.CertificateFilesConfig{CertFile: "fullchain.pem", KeyFile: "key.pem", CertMode: "0644", KeyMode: "0640"}
+	layout := newPublishLayout(basePath, "example.com", "1")
+
+	runScript(t, buildPublishScript(layout, files, remoteCertificateFile, remotePrivateKeyFile))
+	for name, expected := range map[string]struct {
+		content string
+		mode    os.FileMode
+	}{"fullchain.pem": {"new-cert", 0o644}, "key.pem": {"new-key", 0o640}} {
+		content, err := os.ReadFile(filepath.Join(targetDir, name))
+		info, statErr := os.Stat(filepath.Join(targetDir, name))
+		if err != nil || statErr != nil || string(content) != expected.content || info.Mode().Perm() != expected.mode {
+			t.Fatalf("%s 发布结果不匹配: content=%q info=%v err=%v", name, content, info, err)
+		}
+	}
+	if content, err := os.ReadFile(filepath.Join(layout.backupDir, "fullchain.pem")); err != nil || string(content) != "old" {
+		t.Fatalf("命令执行前应保留旧目录备份: content=%q err=%v", content, err)
+	}
+
+	runScript(t, buildRestoreScript(layout))
+	content, err := os.ReadFile(filepath.Join(targetDir, "fullchain.pem"))
+	if err != nil || string(content) != "old" {
+		t.Fatalf("旧证书未恢复: content=%q err=%v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "key.pem")); !os.IsNotExist(err) {
+		t.Fatalf("回滚后不应残留新私钥: %v", err)
+	}
+	if _, err := os.Stat(layout.backupDir); !os.IsNotExist(err) {
+		t.Fatalf("回滚后备份目录应被移回: %v", err)
+	}
+}
+
+// TestBuildRemoteCommandExportsQuotedEnvironment 验证远程命令前导出的环境变量经过 shell 转义。
+func TestBuildRemoteCommandExportsQuotedEnvironment(t *testing.T) {
+	env := commandEnvironment("example.com", "/etc/ssl/it's", config.CertificateFilesConfig{CertFile: "cert.pem", KeyFile: "privateKey.key"})
+	output, err := exec.Command("/bin/sh", "-c", buildRemoteCommand(`printf '%s|%s' "$ANSSL_DOMAIN" "$ANSSL_KEY_FILE"`, env)).Output()
+	if err != nil {
+		t.Fatalf("run command: %v", err)
+	}
+	if string(output) != "example.com|/etc/ssl/it's/privateKey.key" {
+		t.Fatalf("环境变量不匹配: %q", output)
+	}
+}
+
+// TestDiscoverSSHTargetResourcesUsesStableRefs 验证每台主机对应独立且稳定的 targetRef，更换主机后引用失效。
+func TestDiscoverSSHTargetResourcesUsesStableRefs(t *testing.T) {
+	targets := []*config.SSHTargetConfig{
+		{Name: "web-1", Path: "/etc/ssl/anssl", SSHConfig: config.SSHConfig{Host: "10.0.0.1", Port: 22}},
+		{Name: "web-2", Path: "/etc/ssl/anssl", SSHConfig: config.SSHConfig{Host: "10.0.0.2", Port: 2222}},
+	}
+	ctx := shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{SSHTargets: targets}}})
+
+	resources, err := DiscoverSSHTargetResources(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverSSHTargetResources: %v", err)
+	}
+	if len(resources) != 2 || resources[1].Host != "10.0.0.2:2222" || !strings.HasPrefix(resources[0].TargetRef, sshTargetPrefix) || resources[0].TargetRef == resources[1].TargetRef {
+		t.Fatalf("发现的主机资源不匹配: %+v", resources)
+	}
+	if target, err := findSSHTarget(ctx, resources[1].TargetRef); err != nil || target.Name != "web-2" {
+		t.Fatalf("findSSHTarget: target=%v err=%v", target, err)
+	}
+	targets[1].Host = "10.0.0.3"
+	if _, err := findSSHTarget(ctx, resources[1].TargetRef); err == nil {
+		t.Fatal("更换主机后旧 targetRef 应失效")
+	}
+}
+
+// runScript 使用本机 /bin/sh 执行远程脚本。
+func runScript(t *testing.T, script string) {
+	t.Helper()
+	if output, err := exec.Command("/bin/sh", "-c", script).CombinedOutput(); err != nil {
+		t.Fatalf("script failed: %v\n%s", err, output)
+	}
+}
@@ -0,0 +1,284 @@
+package synology
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+// DiscoverSynologyCertificateResources 登录全部 NAS 读取证书目录；部分 NAS 不可达时同时返回已发现资源和错误。
+func DiscoverSynologyCertificateResources(ctx context.Context) ([]SynologyCertificateResource, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	configs, err := getSynologyConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]SynologyCertificateResource, 0)
+	var failures []error
+	for _, nas := range configs {
+		records, err := loadSynologyNASRecords(ctx, nas)
+		if err != nil {
+			logger.Warn("读取群晖 NAS 证书目录失败", "name", nas.Name, "error", err)
+			failures = append(failures, fmt.Errorf("群晖 NAS %s: %w", nas.Name, err))
+			continue
+		}
+		for _, record := range records {
+			resource := record.Resource
+			resource.Domains = append([]string(nil), record.Resource.Domains...)
+			resources = append(resources, resource)
+		}
+	}
+	return resources, errors.Join(failures...)
+}
+
+// TestSynologyCertificateConnection 只读确认 targetRef 对应 NAS 可以登录且证书仍可定位。
+func TestSynologyCertificateConnection(ctx context.Context, targetRef string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	session, _, err := findSynologyCertificate(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	session.close(ctx)
+	return nil
+}
+
+// loadSynologyNASRecords 在独立的发现超时内登录单台 NAS 并读取证书记录。
+func loadSynologyNASRecords(ctx context.Context, nas *config.SynologyConfig) ([]synologyCertificateRecord, error) {
+	discoveryContext, cancel := context.WithTimeout(ctx, synologyDiscoveryTimeout)
+	defer cancel()
+	session, err := openSynologySession(discoveryContext, nas)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close(ctx)
+	return loadSynologyCertificateRecords(discoveryContext, session, nas)
+}
+
+// loadSynologyCertificateRecords 把 DSM 证书转换为记录，并在配置的描述尚不存在时追加待导入记录。
+func loadSynologyCertificateRecords(ctx context.Context, session *synologySession, nas *config.SynologyConfig) ([]synologyCertificateRecord, error) {
+	certificates, err := session.listCertificates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]synologyCertificateRecord, 0, len(certificates)+1)
+	refs := make(map[string]int, len(certificates)+1)
+	describedExists := false
+	for _, certificate := range certificates {
+		if strings.TrimSpace(certificate.ID) == "" {
+			return nil, errors.New("DSM 证书缺少证书 ID")
+		}
+		domains := synologyCertificateDomains(certificate.Subject)
+		description := strings.TrimSpace(certificate.Description)
+		if description != "" && description == nas.Description {
+			describedExists = true
+		}
+		domain := normalizeSynologyDomain(certificate.Subject.CommonName)
+		if domain == "" && len(domains) > 0 {
+			domain = domains[0]
+		}
+		label := description
+		if label == "" {
+			label = domain
+		}
+		targetRef := buildSynologyTargetRef(nas.URL, synologyMatchKey(description, certificate.ID))
+		if description == "" {
+			// 证书在 DSM 中删除后重新导入会得到新 ID，引用中附带 SAN 摘要以便按域名集合找回。
+			targetRef += "-" + synologyRefDigest(nas.URL, "san\x00"+strings.Join(domains, ","))
+		}
+		if index, exists := refs[targetRef]; exists {
+			records[index].Ambiguous = true
+			continue
+		}
+		refs[targetRef] = len(records)
+		records = append(records, synologyCertificateRecord{
+			NAS:           nas,
+			CertificateID: certificate.ID,
+			Description:   description,
+			Resource: SynologyCertificateResource{
+				TargetRef: targetRef,
+				Label:     label,
+				NAS:       nas.Name,
+				Domain:    domain,
+				Domains:   domains,
+				IsDefault: certificate.IsDefault,
+				Status:    synologyStatusImported,
+			},
+		})
+	}
+	if nas.Description != "" && !describedExists {
+		records = append(records, synologyCertificateRecord{
+			NAS:         nas,
+			Description: nas.Description,
+			Resource: SynologyCertificateResource{
+				TargetRef: buildSynologyTargetRef(nas.URL, synologyMatchKey(nas.Description, "")),
+				Label:     nas.Description,
+				NAS:       nas.Name,
+				Status:    synologyStatusNotImported,
+			},
+		})
+	}
+	return records, nil
+}
+
+// findSynologyCertificate 逐台登录 NAS 重新定位 targetRef，返回仍处于登录状态的会话，调用方必须执行 close。
+func findSynologyCertificate(ctx context.Context, targetRef string) (*synologySession, *synologyCertificateRecord, error) {
+	targetRef = strings.TrimSpace(targetRef)
+	if targetRef == "" {
+		return nil, nil, errors.New("群晖证书 targetRef 不能为空")
+	}
+	configs, err := getSynologyConfigs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var failures []error
+	for _, nas := range configs {
+		discoveryContext, cancel := context.WithTimeout(ctx, synologyDiscoveryTimeout)
+		session, err := openSynologySession(discoveryContext, nas)
+		if err != nil {
+			cancel()
+			failures = append(failures, fmt.Errorf("群晖 NAS %s: %w", nas.Name, err))
+			continue
+		}
+		records, err := loadSynologyCertificateRecords(discoveryContext, session, nas)
+		cancel()
+		if err != nil {
+			session.close(ctx)
+			failures = append(failures, fmt.Errorf("群晖 NAS %s: %w", nas.Name, err))
+			continue
+		}
+		record, err := matchSynologyRecord(records, targetRef)
+		if err != nil {
+			session.close(ctx)
+			return nil, nil, err
+		}
+		if record != nil {
+			return session, record, nil
+		}
+		session.close(ctx)
+	}
+	// 无法登录的 NAS 上可能存在该证书，此时返回连接错误而不是判定引用失效。
+	if len(failures) > 0 {
+		return nil, nil, errors.Join(failures...)
+	}
+	return nil, nil, errors.New("群晖证书不存在或描述已修改，请重新配置部署目标")
+}
+
+// matchSynologyRecord 按引用定位证书记录，未找到时返回 nil；无描述证书的 ID 已不存在时，按 SAN 摘要找回唯一一张无描述证书。
+func matchSynologyRecord(records []synologyCertificateRecord, targetRef string) (*synologyCertificateRecord, error) {
+	primary, sanDigest := splitSynologyTargetRef(targetRef)
+	var fallback []int
+	for index := range records {
+		recordPrimary, recordSANDigest := splitSynologyTargetRef(records[index].Resource.TargetRef)
+		if recordPrimary == primary {
+			if records[index].Ambiguous {
+				return nil, errors.New("群晖 NAS 上有多张证书使用同一描述，请为证书设置唯一描述后重新配置部署目标")
+			}
+			record := records[index]
+			return &record, nil
+		}
+		if sanDigest != "" && recordSANDigest == sanDigest {
+			fallback = append(fallback, index)
+		}
+	}
+	switch len(fallback) {
+	case 0:
+		return nil, nil
+	case 1:
+		record := records[fallback[0]]
+		logger.Info("群晖证书 ID 已变化，按域名集合找回无描述证书", "nas", record.NAS.Name, "label", record.Resource.Label)
+		return &record, nil
+	default:
+		return nil, errors.New("群晖 NAS 上有多张无描述证书包含相同域名，请为证书设置唯一描述后重新配置部署目标")
+	}
+}
+
+// splitSynologyTargetRef 拆分无描述证书引用中的 ID 引用和 SAN 摘要，按描述匹配的引用没有 SAN 摘要。
+func splitSynologyTargetRef(targetRef string) (string, string) {
+	if index := strings.LastIndex(targetRef, "-"); index >= len(synologyTargetPrefix) {
+		return targetRef[:index], targetRef[index+1:]
+	}
+	return targetRef, ""
+}
+
+// synologyMatchKey 优先使用证书描述匹配，描述为空时使用 DSM 证书 ID；原位替换不改变 ID，换成不同 SAN 的证书后引用仍然有效。
+func synologyMatchKey(description, certificateID string) string {
+	if description != "" {
+		return "desc\x00" + description
+	}
+	return "id\x00" + certificateID
+}
+
+// buildSynologyTargetRef 根据 DSM 地址和证书匹配键生成稳定的不透明引用。
+func buildSynologyTargetRef(apiURL, matchKey string) string {
+	return synologyTargetPrefix + synologyRefDigest(apiURL, matchKey)
+}
+
+// synologyRefDigest 返回 DSM 地址和匹配键的截断摘要，不泄露地址或证书信息。
+func synologyRefDigest(apiURL, matchKey string) string {
+	identity := strings.Join([]string{
+		"ansslCli",
+		"DEPLOYMENT_TYPE_ANSSL_CLI_SYNOLOGY_CERT",
+		normalizeSynologyOrigin(apiURL),
+		matchKey,
+	}, "\x00")
+	digest := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(digest[:12])
+}
+
+// normalizeSynologyOrigin 规范化仅用于本地哈希的 DSM 来源，不返回或记录该值。
+func normalizeSynologyOrigin(apiURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(apiURL))
+	if err != nil {
+		return strings.ToLower(strings.TrimRight(strings.TrimSpace(apiURL), "/"))
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	hostname := strings.ToLower(parsed.Hostname())
+	port := parsed.Port()
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+	host := hostname
+	if port != "" {
+		host = net.JoinHostPort(hostname, port)
+	} else if strings.Contains(hostname, ":") {
+		host = "[" + hostname + "]"
+	}
+	return scheme + "://" + host + strings.TrimRight(parsed.Path, "/")
+}
+
+// synologyCertificateDomains 合并 CN 和 SAN，并规范化、去重和排序。
+func synologyCertificateDomains(subject synologySubject) []string {
+	seen := make(map[string]struct{}, len(subject.SubjectAltNames)+1)
+	domains := make([]string, 0, len(subject.SubjectAltNames)+1)
+	for _, raw := range append([]string{subject.CommonName}, subject.SubjectAltNames...) {
+		domain := normalizeSynologyDomain(raw)
+		if domain == "" {
+			continue
+		}
+		if _, exists := seen[domain]; exists {
+			continue
+		}
+		seen[domain] = struct{}{}
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// normalizeSynologyDomain 将 DSM 返回的域名规范化为小写且不带结尾点的形式。
+func normalizeSynologyDomain(raw string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(raw)), ".")
+}
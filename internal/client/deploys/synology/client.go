@@ -0,0 +1,377 @@
+package synology
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// synologyErrorMessages 是常见 DSM Web API 错误码的本地诊断说明。
+var synologyErrorMessages = map[int]string{
+	100: "未知错误",
+	101: "缺少必要参数",
+	102: "API 不存在",
+	103: "API 方法不存在",
+	104: "API 版本不受支持",
+	105: "账号权限不足，需要 administrators 组账号",
+	106: "会话已超时",
+	107: "会话被重复登录中断",
+	119: "会话 ID 无效",
+	400: "账号或密码错误",
+	401: "账号已停用",
+	402: "账号权限不足",
+	403: "账号需要双重验证码，请配置已信任设备的 deviceId",
+	404: "双重验证失败",
+	406: "账号被强制要求启用双重验证",
+	407: "客户端 IP 已被 DSM 自动封锁",
+	408: "账号密码已过期",
+	409: "账号密码已过期且不能修改",
+	410: "账号密码必须修改",
+}
+
+// synologyRequestError 保存仅供 deploy 本地判断重试属性的 DSM API 错误。
+type synologyRequestError struct {
+	Retryable bool  // Retryable 表示网络、会话或服务端错误可以稍后重试。
+	Cause     error // Cause 不得写入 WebSocket 响应；在线日志必须先经过统一脱敏。
+}
+
+// Error 返回群晖 DSM 本地诊断信息。
+func (e *synologyRequestError) Error() string {
+	if e == nil || e.Cause == nil {
+		return "群晖 DSM 请求失败"
+	}
+	return e.Cause.Error()
+}
+
+// Unwrap 返回原始错误，供 errors.Is 和 errors.As 使用。
+func (e *synologyRequestError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Cause
+}
+
+// IsSynologyConfiguredWithContext 从 context 快照判断是否配置了群晖 NAS。
+func IsSynologyConfiguredWithContext(ctx context.Context) bool {
+	configuration := shared.ConfigurationFromContext(ctx)
+	return configuration != nil && configuration.SSL != nil && len(configuration.SSL.Synology) > 0
+}
+
+// IsSynologyErrorRetryable 判断群晖 DSM 操作是否适合由后端稍后重试。
+func IsSynologyErrorRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var requestError *synologyRequestError
+	if errors.As(err, &requestError) {
+		return requestError.Retryable
+	}
+	var networkError net.Error
+	return errors.As(err, &networkError) && networkError.Timeout()
+}
+
+// getSynologyConfigs 读取当前操作快照中的群晖 NAS 配置。
+func getSynologyConfigs(ctx context.Context) ([]*config.SynologyConfig, error) {
+	configuration := shared.ConfigurationFromContext(ctx)
+	if configuration == nil || configuration.SSL == nil || len(configuration.SSL.Synology) == 0 {
+		return nil, errors.New("未配置群晖 NAS (ssl.synology)")
+	}
+	return configuration.SSL.Synology, nil
+}
+
+// synologySession 是一次部署操作内使用的 DSM 登录会话。
+type synologySession struct {
+	baseURL  string       // baseURL 是不带结尾斜杠的 DSM 管理端地址。
+	client   *http.Client // client 使用该 NAS 独立的 TLS 策略。
+	authPath string       // authPath 是 SYNO.API.Auth 所在的 CGI 路径。
+	sid      string       // sid 是登录会话 ID。
+	token    string       // token 是写操作需要的 SynoToken。
+}
+
+// openSynologySession 查询 SYNO.API.Auth 路径后登录 DSM，调用方必须在结束时执行 close。
+func openSynologySession(ctx context.Context, nas *config.SynologyConfig) (*synologySession, error) {
+	session := &synologySession{
+		baseURL: strings.TrimRight(strings.TrimSpace(nas.URL), "/"),
+		client:  newSynologyHTTPClient(nas.InsecureSkipVerify),
+	}
+	var info map[string]synologyAPIInfo
+	if err := session.call(ctx, synologyQueryPath, url.Values{
+		"api":     {synologyInfoAPI},
+		"version": {"1"},
+		"method":  {"query"},
+		"query":   {synologyAuthAPI},
+	}, &info); err != nil {
+		return nil, fmt.Errorf("查询 DSM API 信息失败: %w", err)
+	}
+	auth, ok := info[synologyAuthAPI]
+	if !ok || auth.MaxVersion < synologyAuthVersion {
+		return nil, &synologyRequestError{Retryable: false, Cause: errors.New("DSM 不支持 SYNO.API.Auth v6，请升级到 DSM 6.2 或更高版本")}
+	}
+	if auth.Path == "" || path.Base(auth.Path) != auth.Path {
+		return nil, &synologyRequestError{Retryable: false, Cause: errors.New("DSM 返回的 SYNO.API.Auth 路径无效")}
+	}
+	session.authPath = "/webapi/" + auth.Path
+
+	values := url.Values{
+		"api":               {synologyAuthAPI},
+		"version":           {strconv.Itoa(synologyAuthVersion)},
+		"method":            {"login"},
+		"account":           {nas.Username},
+		"passwd":            {nas.Password},
+		"session":           {synologySessionName},
+		"format":            {"sid"},
+		"enable_syno_token": {"yes"},
+	}
+	if nas.DeviceID != "" {
+		values.Set("device_id", nas.DeviceID)
+		values.Set("device_name", synologyDeviceName)
+	}
+	var login synologyLoginData
+	if err := session.call(ctx, session.authPath, values, &login); err != nil {
+		return nil, fmt.Errorf("登录 DSM 失败: %w", err)
+	}
+	if login.SID == "" {
+		return nil, &synologyRequestError{Retryable: false, Cause: errors.New("DSM 登录响应缺少会话 ID")}
+	}
+	session.sid = login.SID
+	session.token = login.SynoToken
+	return session, nil
+}
+
+// close 注销 DSM 会话；调用方 context 已取消时仍在短超时内尝试注销。
+func (s *synologySession) close(ctx context.Context) {
+	if s == nil || s.sid == "" {
+		return
+	}
+	logoutContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	_ = s.call(logoutContext, s.authPath, url.Values{
+		"api":     {synologyAuthAPI},
+		"version": {strconv.Itoa(synologyAuthVersion)},
+		"method":  {"logout"},
+		"session": {synologySessionName},
+	}, nil)
+	s.sid = ""
+}
+
+// listCertificates 读取 DSM 证书列表的脱敏元数据。
+func (s *synologySession) listCertificates(ctx context.Context) ([]synologyCertificate, error) {
+	var list synologyCertificateList
+	if err := s.call(ctx, synologyEntryPath, url.Values{
+		"api":     {synologyCertificateListAPI},
+		"version": {"1"},
+		"method":  {"list"},
+	}, &list); err != nil {
+		return nil, fmt.Errorf("读取 DSM 证书列表失败: %w", err)
+	}
+	return list.Certificates, nil
+}
+
+// importCertificate 以 multipart 表单导入证书，certificateID 为空时新建证书，否则原位替换。
+func (s *synologySession) importCertificate(ctx context.Context, certificateID, description string, asDefault bool, leafPEM, chainPEM, privateKeyPEM string) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, file := range []struct {
+		field   string
+		name    string
+		content string
+	}{
+		{"key", "privkey.pem", privateKeyPEM},
+		{"cert", "cert.pem", leafPEM},
+		{"inter_cert", "chain.pem", chainPEM},
+	} {
+		if file.content == "" {
+			continue
+		}
+		part, err := writer.CreateFormFile(file.field, file.name)
+		if err != nil {
+			return "", fmt.Errorf("创建 DSM 导入表单失败: %w", err)
+		}
+		if _, err := io.WriteString(part, file.content); err != nil {
+			return "", fmt.Errorf("创建 DSM 导入表单失败: %w", err)
+		}
+	}
+	defaultValue := ""
+	if asDefault {
+		defaultValue = "true"
+	}
+	for _, field := range [][2]string{{"id", certificateID}, {"desc", description}, {"as_default", defaultValue}} {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return "", fmt.Errorf("创建 DSM 导入表单失败: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("创建 DSM 导入表单失败: %w", err)
+	}
+
+	query := url.Values{
+		"api":     {synologyCertificateAPI},
+		"version": {"1"},
+		"method":  {"import"},
+		"_sid":    {s.sid},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+synologyEntryPath+"?"+query.Encode(), &body)
+	if err != nil {
+		return "", fmt.Errorf("创建 DSM 请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	responseBody, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	var data synologyImportData
+	if err := decodeSynologyResponse(responseBody, &data); err != nil {
+		return "", err
+	}
+	return data.ID, nil
+}
+
+// exportCertificatePEM 导出 DSM 证书压缩包并返回其中的 cert.pem，用于导入后的指纹回读。
+func (s *synologySession) exportCertificatePEM(ctx context.Context, certificateID string) (string, error) {
+	query := url.Values{
+		"api":     {synologyCertificateAPI},
+		"version": {"1"},
+		"method":  {"export"},
+		"id":      {certificateID},
+		"_sid":    {s.sid},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+synologyEntryPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("创建 DSM 请求失败: %w", err)
+	}
+	responseBody, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.HasPrefix(responseBody, []byte("PK")) {
+		// 导出失败时 DSM 返回 JSON 错误包络而不是压缩包。
+		if err := decodeSynologyResponse(responseBody, nil); err != nil {
+			return "", err
+		}
+		return "", &synologyRequestError{Retryable: false, Cause: errors.New("DSM 导出证书响应不是压缩包")}
+	}
+	archive, err := zip.NewReader(bytes.NewReader(responseBody), int64(len(responseBody)))
+	if err != nil {
+		return "", &synologyRequestError{Retryable: false, Cause: fmt.Errorf("解析 DSM 导出证书压缩包失败: %w", err)}
+	}
+	for _, file := range archive.File {
+		if path.Base(file.Name) != synologyExportCertFile {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return "", fmt.Errorf("读取 DSM 导出证书失败: %w", err)
+		}
+		content, err := io.ReadAll(io.LimitReader(reader, synologyMaxResponseBodySize))
+		reader.Close()
+		if err != nil {
+			return "", fmt.Errorf("读取 DSM 导出证书失败: %w", err)
+		}
+		return string(content), nil
+	}
+	return "", &synologyRequestError{Retryable: false, Cause: errors.New("DSM 导出证书压缩包缺少 cert.pem")}
+}
+
+// call 以表单 POST 调用 DSM Web API，登录后自动附带会话 ID 和 SynoToken。
+func (s *synologySession) call(ctx context.Context, endpoint string, values url.Values, responseData any) error {
+	form := make(url.Values, len(values)+1)
+	for key, items := range values {
+		form[key] = append([]string(nil), items...)
+	}
+	if s.sid != "" {
+		form.Set("_sid", s.sid)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("创建 DSM 请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	responseBody, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	return decodeSynologyResponse(responseBody, responseData)
+}
+
+// do 发送请求并读取有大小限制的响应体。
+func (s *synologySession) do(req *http.Request) ([]byte, error) {
+	if s.token != "" {
+		req.Header.Set("X-SYNO-TOKEN", s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, &synologyRequestError{Retryable: true, Cause: fmt.Errorf("请求 DSM API 失败: %w", err)}
+	}
+	defer resp.Body.Close()
+	responseBody, err := io.ReadAll(io.LimitReader(resp.Body, synologyMaxResponseBodySize+1))
+	if err != nil {
+		return nil, &synologyRequestError{Retryable: true, Cause: fmt.Errorf("读取 DSM 响应失败: %w", err)}
+	}
+	if len(responseBody) > synologyMaxResponseBodySize {
+		return nil, &synologyRequestError{Retryable: false, Cause: errors.New("DSM 响应体超过最大限制")}
+	}
+	if resp.StatusCode != http.StatusOK {
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return nil, &synologyRequestError{Retryable: retryable, Cause: fmt.Errorf("DSM API 返回 HTTP %d", resp.StatusCode)}
+	}
+	return responseBody, nil
+}
+
+// decodeSynologyResponse 解析 DSM 响应包络，并把错误码转换为本地诊断信息。
+func decodeSynologyResponse(responseBody []byte, responseData any) error {
+	var response synologyResponse
+	if err := json.Unmarshal(responseBody, &response); err != nil {
+		return &synologyRequestError{Retryable: false, Cause: fmt.Errorf("解析 DSM 响应失败: %w", err)}
+	}
+	if !response.Success {
+		code := 0
+		if response.Error != nil {
+			code = response.Error.Code
+		}
+		message, ok := synologyErrorMessages[code]
+		if !ok {
+			message = "请求被拒绝"
+		}
+		retryable := code == 106 || code == 107 || code == 119
+		return &synologyRequestError{Retryable: retryable, Cause: fmt.Errorf("DSM API 返回错误 %d: %s", code, message)}
+	}
+	if responseData == nil || len(response.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(response.Data, responseData); err != nil {
+		return &synologyRequestError{Retryable: false, Cause: fmt.Errorf("解析 DSM 响应数据失败: %w", err)}
+	}
+	return nil
+}
+
+// newSynologyHTTPClient 为每台 NAS 配置独立 TLS 策略，避免影响其他 HTTP 客户端。
+func newSynologyHTTPClient(insecureSkipVerify bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: insecureSkipVerify} //nolint:gosec // 仅在用户显式配置后允许自签名 DSM。
+	return &http.Client{
+		Timeout:   synologyRequestTimeout,
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,22 @@
+package synology
+
+import "time"
+
+const (
+	synologyRequestTimeout      = 30 * time.Second
+	synologyDiscoveryTimeout    = 20 * time.Second
+	synologyMaxResponseBodySize = 4 * 1024 * 1024
+	synologyTargetPrefix        = "synology-cert-"
+	synologyQueryPath           = "/webapi/query.cgi"
+	synologyEntryPath           = "/webapi/entry.cgi"
+	synologyInfoAPI             = "SYNO.API.Info"
+	synologyAuthAPI             = "SYNO.API.Auth"
+	synologyAuthVersion         = 6
+	synologyCertificateAPI      = "SYNO.Core.Certificate"
+	synologyCertificateListAPI  = "SYNO.Core.Certificate.CRT"
+	synologySessionName         = "Certificate"
+	synologyDeviceName          = "anssl-deploy"
+	synologyExportCertFile      = "cert.pem"
+	synologyStatusImported      = "Imported"
+	synologyStatusNotImported   = "NotImported"
+)
@@ -0,0 +1,82 @@
+package synology
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+// DeployCertificateToSynology 导入或原位替换 targetRef 对应的 DSM 证书，并导出回读叶证书指纹确认。
+func DeployCertificateToSynology(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	certificate := providers.CertificateMaterial{Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+	if err := providers.ValidateCertificateMaterial(certificate, domain, time.Now()); err != nil {
+		return err
+	}
+	leafPEM, chainPEM, err := splitSynologyCertificateChain(certificatePEM)
+	if err != nil {
+		return err
+	}
+
+	session, record, err := findSynologyCertificate(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+	defer session.close(ctx)
+
+	// 已是默认证书的记录必须继续声明默认，否则 DSM 会在替换时取消默认状态。
+	asDefault := record.Resource.IsDefault || (record.NAS.SetDefault && record.Description == record.NAS.Description)
+	certificateID, err := session.importCertificate(ctx, record.CertificateID, record.Description, asDefault, leafPEM, chainPEM, privateKeyPEM)
+	if err != nil {
+		return fmt.Errorf("导入 DSM 证书失败: %w", err)
+	}
+	if certificateID == "" {
+		certificateID = record.CertificateID
+	}
+	if certificateID == "" {
+		return errors.New("DSM 导入证书响应缺少证书 ID")
+	}
+
+	exported, err := session.exportCertificatePEM(ctx, certificateID)
+	if err != nil {
+		return fmt.Errorf("回读 DSM 证书失败: %w", err)
+	}
+	if err := providers.VerifyLeafCertificateSHA256(certificatePEM, exported); err != nil {
+		return fmt.Errorf("校验 DSM 回读证书失败: %w", err)
+	}
+	logger.Info("群晖 DSM 证书已更新", "name", record.NAS.Name, "label", record.Resource.Label, "default", asDefault)
+	return nil
+}
+
+// splitSynologyCertificateChain 将完整 PEM 拆分为 DSM 导入表单需要的叶证书和中间证书链。
+func splitSynologyCertificateChain(certificatePEM string) (string, string, error) {
+	remaining := []byte(certificatePEM)
+	certificates := make([][]byte, 0)
+	for len(remaining) > 0 {
+		block, rest := pem.Decode(remaining)
+		if block == nil {
+			break
+		}
+		remaining = rest
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificates = append(certificates, pem.EncodeToMemory(block))
+	}
+	if len(certificates) == 0 {
+		return "", "", errors.New("未找到 PEM 证书块")
+	}
+	var chain strings.Builder
+	for _, certificate := range certificates[1:] {
+		chain.Write(certificate)
+	}
+	return string(certificates[0]), chain.String(), nil
+}
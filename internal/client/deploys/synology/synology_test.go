@@ -0,0 +1,280 @@
+package synology
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// fakeDSMCertificate 是模拟 DSM 中保存的一张证书。
+type fakeDSMCertificate struct {
+	ID          string
+	Description string
+	IsDefault   bool
+	Domains     []string
+	Certificate string
+}
+
+// fakeDSM 模拟 DSM 登录、证书列表、导入和导出接口。
+type fakeDSM struct {
+	mu           sync.Mutex
+	certificates []*fakeDSMCertificate
+	imports      []map[string]string
+	nextID       int
+}
+
+// ServeHTTP 按 api 和 method 分发模拟 DSM 请求。
+func (f *fakeDSM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.URL.Path == synologyQueryPath {
+		writeDSMResponse(w, map[string]any{synologyAuthAPI: map[string]any{"path": "entry.cgi", "minVersion": 1, "maxVersion": 7}})
+		return
+	}
+	api, method := r.URL.Query().Get("api"), r.URL.Query().Get("method")
+	if api == "" {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		api, method = r.PostForm.Get("api"), r.PostForm.Get("method")
+	}
+	if api == synologyAuthAPI && method == "login" {
+		if r.PostForm.Get("account") != "admin" || r.PostForm.Get("passwd") != "secret" {
+			writeDSMError(w, 400)
+			return
+		}
+		writeDSMResponse(w, map[string]any{"sid": "sid-1", "synotoken": "token-1"})
+		return
+	}
+	if r.FormValue("_sid") != "sid-1" || r.Header.Get("X-SYNO-TOKEN") != "token-1" {
+		writeDSMError(w, 119)
+		return
+	}
+	switch api + "." + method {
+	case synologyAuthAPI + ".logout":
+		writeDSMResponse(w, nil)
+	case synologyCertificateListAPI + ".list":
+		certificates := make([]map[string]any, 0, len(f.certificates))
+		for _, certificate := range f.certificates {
+			certificates = append(certificates, map[string]any{
+				"id":         certificate.ID,
+				"desc":       certificate.Description,
+				"is_default": certificate.IsDefault,
+				"subject":    map[string]any{"common_name": certificate.Domains[0], "sub_alt_name": certificate.Domains},
+			})
+		}
+		writeDSMResponse(w, map[string]any{"certificates": certificates})
+	case synologyCertificateAPI + ".import":
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fields := map[string]string{"id": r.FormValue("id"), "desc": r.FormValue("desc"), "as_default": r.FormValue("as_default")}
+		for _, name := range []string{"key", "cert", "inter_cert"} {
+			if file, _, err := r.FormFile(name); err == nil {
+				content, _ := io.ReadAll(file)
+				fields[name] = string(content)
+			}
+		}
+		f.imports = append(f.imports, fields)
+		target := f.find(fields["id"])
+		if target == nil {
+			f.nextID++
+			target = &fakeDSMCertificate{ID: "new-" + string(rune('0'+f.nextID))}
+			f.certificates = append(f.certificates, target)
+		}
+		if fields["as_default"] == "true" {
+			for _, certificate := range f.certificates {
+				certificate.IsDefault = false
+			}
+		}
+		block, _ := pem.Decode([]byte(fields["cert"]))
+		leaf, _ := x509.ParseCertificate(block.Bytes)
+		target.Description = fields["desc"]
+		target.IsDefault = fields["as_default"] == "true"
+		target.Domains = leaf.DNSNames
+		target.Certificate = fields["cert"]
+		writeDSMResponse(w, map[string]any{"id": target.ID})
+	case synologyCertificateAPI + ".export":
+		target := f.find(r.URL.Query().Get("id"))
+		if target == nil {
+			writeDSMError(w, 100)
+			return
+		}
+		var archive bytes.Buffer
+		writer := zip.NewWriter(&archive)
+		file, _ := writer.Create("cert.pem")
+		io.WriteString(file, target.Certificate)
+		writer.Close()
+		w.Write(archive.Bytes())
+	default:
+		writeDSMError(w, 103)
+	}
+}
+
+// find 根据证书 ID 查找模拟证书。
+func (f *fakeDSM) find(id string) *fakeDSMCertificate {
+	for _, certificate := range f.certificates {
+		if id != "" && certificate.ID == id {
+			return certificate
+		}
+	}
+	return nil
+}
+
+// TestDeployCertificateToSynologyReplacesDescribedCertificate 验证按描述匹配的默认证书被原位替换、保持默认并通过导出回读。
+func TestDeployCertificateToSynologyReplacesDescribedCertificate(t *testing.T) {
+	oldCertificate, _ := generateTestCertificatePair(t, "nas.example.com")
+	dsm := &fakeDSM{certificates: []*fakeDSMCertificate{
+		{ID: "abc", Description: "anssl", IsDefault: true, Domains: []string{"nas.example.com"}, Certificate: oldCertificate},
+		{ID: "def", Description: "", Domains: []string{"other.example.com"}},
+	}}
+	server := httptest.NewServer(dsm)
+	defer server.Close()
+	ctx := synologyTestContext(&config.SynologyConfig{Name: "nas-1", URL: server.URL, Username: "admin", Password: "secret"})
+
+	resources, err := DiscoverSynologyCertificateResources(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverSynologyCertificateResources: %v", err)
+	}
+	if len(resources) != 2 || resources[0].Label != "anssl" || !resources[0].IsDefault || resources[1].Label != "other.example.com" || !strings.HasPrefix(resources[0].TargetRef, synologyTargetPrefix) {
+		t.Fatalf("发现的 DSM 证书不匹配: %+v", resources)
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "nas.example.com")
+	if err := DeployCertificateToSynology(ctx, resources[0].TargetRef, "nas.example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToSynology: %v", err)
+	}
+	if len(dsm.imports) != 1 || dsm.imports[0]["id"] != "abc" || dsm.imports[0]["desc"] != "anssl" || dsm.imports[0]["as_default"] != "true" || dsm.imports[0]["key"] != privateKeyPEM {
+		t.Fatalf("DSM 导入参数不匹配: %+v", dsm.imports)
+	}
+	if len(dsm.certificates) != 2 || dsm.certificates[0].Certificate != certificatePEM {
+		t.Fatal("默认证书应被原位替换")
+	}
+}
+
+// TestDiscoverSynologyCertificatesMatchesByIDAndCreatesDescribed 验证无描述证书按 DSM 证书 ID 生成引用且换成不同 SAN 后仍然有效、配置描述不存在时新建证书，且不可达 NAS 只产生部分结果。
+func TestDiscoverSynologyCertificatesMatchesByIDAndCreatesDescribed(t *testing.T) {
+	dsm := &fakeDSM{certificates: []*fakeDSMCertificate{{ID: "abc", Domains: []string{"b.example.com", "a.example.com"}}}}
+	server := httptest.NewServer(dsm)
+	defer server.Close()
+	offline := httptest.NewServer(http.NotFoundHandler())
+	offline.Close()
+	ctx := synologyTestContext(
+		&config.SynologyConfig{Name: "nas-1", URL: server.URL, Username: "admin", Password: "secret", Description: "anssl", SetDefault: true},
+		&config.SynologyConfig{Name: "nas-2", URL: offline.URL, Username: "admin", Password: "secret"},
+	)
+
+	resources, err := DiscoverSynologyCertificateResources(ctx)
+	if err == nil || !IsSynologyErrorRetryable(err) {
+		t.Fatalf("不可达 NAS 应返回可重试错误: %v", err)
+	}
+	if len(resources) != 2 || resources[0].Domain != "b.example.com" || resources[1].Status != synologyStatusNotImported || resources[1].Label != "anssl" {
+		t.Fatalf("发现的 DSM 证书不匹配: %+v", resources)
+	}
+	if primary, _ := splitSynologyTargetRef(resources[0].TargetRef); primary != buildSynologyTargetRef(server.URL+"/", synologyMatchKey("", "abc")) {
+		t.Fatal("无描述证书的 targetRef 应由 DSM 证书 ID 决定")
+	}
+
+	certificatePEM, privateKeyPEM := generateTestCertificatePair(t, "nas.example.com")
+	if err := DeployCertificateToSynology(ctx, resources[1].TargetRef, "nas.example.com", certificatePEM, privateKeyPEM); err != nil {
+		t.Fatalf("DeployCertificateToSynology: %v", err)
+	}
+	if len(dsm.imports) != 1 || dsm.imports[0]["id"] != "" || dsm.imports[0]["desc"] != "anssl" || dsm.imports[0]["as_default"] != "true" {
+		t.Fatalf("DSM 新建证书参数不匹配: %+v", dsm.imports)
+	}
+
+	resources, _ = DiscoverSynologyCertificateResources(ctx)
+	if len(resources) != 2 || resources[1].Status != synologyStatusImported || !resources[1].IsDefault {
+		t.Fatalf("新建证书后应按描述识别为已导入的默认证书: %+v", resources)
+	}
+	if err := TestSynologyCertificateConnection(ctx, resources[1].TargetRef); err != nil {
+		t.Fatalf("新建证书后原 targetRef 应保持有效: %v", err)
+	}
+
+	// 无描述证书原位替换为不同 SAN 的证书后，targetRef 仍能定位到同一张证书。
+	sanChangedPEM, sanChangedKeyPEM := generateTestCertificatePair(t, "c.example.com")
+	if err := DeployCertificateToSynology(ctx, resources[0].TargetRef, "c.example.com", sanChangedPEM, sanChangedKeyPEM); err != nil {
+		t.Fatalf("替换无描述证书失败: %v", err)
+	}
+	if dsm.imports[1]["id"] != "abc" || dsm.imports[1]["desc"] != "" {
+		t.Fatalf("无描述证书应按 ID 原位替换: %+v", dsm.imports[1])
+	}
+	if err := DeployCertificateToSynology(ctx, resources[0].TargetRef, "c.example.com", sanChangedPEM, sanChangedKeyPEM); err != nil {
+		t.Fatalf("SAN 变化后原 targetRef 应保持有效: %v", err)
+	}
+
+	// 无描述证书在 DSM 中删除后重新导入得到新 ID，按 SAN 集合找回该证书。
+	resources, _ = DiscoverSynologyCertificateResources(ctx)
+	dsm.certificates[0].ID = "reimported"
+	if err := DeployCertificateToSynology(ctx, resources[0].TargetRef, "c.example.com", sanChangedPEM, sanChangedKeyPEM); err != nil {
+		t.Fatalf("证书 ID 变化后应按 SAN 找回无描述证书: %v", err)
+	}
+	if last := dsm.imports[len(dsm.imports)-1]; last["id"] != "reimported" || last["desc"] != "" {
+		t.Fatalf("应原位替换按 SAN 找回的证书: %+v", last)
+	}
+	dsm.certificates = append(dsm.certificates, &fakeDSMCertificate{ID: "copy", Domains: []string{"c.example.com"}})
+	dsm.certificates[0].ID = "reimported-again"
+	if err := DeployCertificateToSynology(ctx, resources[0].TargetRef, "c.example.com", sanChangedPEM, sanChangedKeyPEM); err == nil || !strings.Contains(err.Error(), "相同域名") {
+		t.Fatalf("多张无描述证书包含相同域名时应拒绝部署: %v", err)
+	}
+}
+
+// synologyTestContext 返回只包含群晖配置的操作 context。
+func synologyTestContext(configs ...*config.SynologyConfig) context.Context {
+	return shared.WithRuntime(context.Background(), &config.Runtime{Config: &config.Configuration{SSL: &config.DeployConfig{Synology: configs}}})
+}
+
+// writeDSMResponse 写入 DSM 成功响应包络。
+func writeDSMResponse(w http.ResponseWriter, data any) {
+	json.NewEncoder(w).Encode(map[string]any{"success": true, "data": data})
+}
+
+// writeDSMError 写入 DSM 错误响应包络。
+func writeDSMError(w http.ResponseWriter, code int) {
+	json.NewEncoder(w).Encode(map[string]any{"success": false, "error": map[string]any{"code": code}})
+}
+
+// generateTestCertificatePair 生成群晖测试使用的自签证书和匹配私钥。
+func generateTestCertificatePair(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
@@ -0,0 +1,74 @@
+package synology
+
+import (
+	"encoding/json"
+
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// SynologyCertificateResource 是可以安全上报到 anSSL 后端的脱敏 DSM 证书资源。
+type SynologyCertificateResource struct {
+	TargetRef string   // TargetRef 是客户端根据 DSM 地址和证书描述或证书 ID 生成的不透明稳定引用，无描述证书附带 SAN 摘要。
+	Label     string   // Label 是证书描述或主域名。
+	NAS       string   // NAS 是配置中的 NAS 名称。
+	Domain    string   // Domain 是证书主域名，尚未导入时为空。
+	Domains   []string // Domains 是证书包含的全部规范化域名。
+	IsDefault bool     // IsDefault 表示该证书是否为 DSM 系统默认证书。
+	Status    string   // Status 是证书已导入或等待首次导入的状态。
+}
+
+// synologyResponse 描述 DSM Web API 的统一响应包络。
+type synologyResponse struct {
+	Success bool            `json:"success"` // Success 表示请求是否成功。
+	Data    json.RawMessage `json:"data"`    // Data 是各接口自己的响应数据。
+	Error   *struct {
+		Code int `json:"code"` // Code 是 DSM 错误码，仅用于本地诊断。
+	} `json:"error"`
+}
+
+// synologyAPIInfo 描述 SYNO.API.Info 返回的单个 API 路径和版本范围。
+type synologyAPIInfo struct {
+	Path       string `json:"path"`       // Path 是 /webapi/ 下的 CGI 文件名。
+	MinVersion int    `json:"minVersion"` // MinVersion 是 DSM 支持的最低版本。
+	MaxVersion int    `json:"maxVersion"` // MaxVersion 是 DSM 支持的最高版本。
+}
+
+// synologyLoginData 描述 SYNO.API.Auth 登录成功后的会话凭据。
+type synologyLoginData struct {
+	SID       string `json:"sid"`       // SID 是会话 ID，不得写入日志。
+	SynoToken string `json:"synotoken"` // SynoToken 是写操作需要的 CSRF 令牌。
+}
+
+// synologyCertificateList 描述 SYNO.Core.Certificate.CRT 列表响应。
+type synologyCertificateList struct {
+	Certificates []synologyCertificate `json:"certificates"` // Certificates 是 DSM 证书列表。
+}
+
+// synologyCertificate 描述 DSM 证书列表中的脱敏元数据。
+type synologyCertificate struct {
+	ID          string          `json:"id"`         // ID 是 DSM 证书 ID，仅供 deploy 本地使用。
+	Description string          `json:"desc"`       // Description 是用户设置的证书描述。
+	IsDefault   bool            `json:"is_default"` // IsDefault 表示是否为系统默认证书。
+	Subject     synologySubject `json:"subject"`    // Subject 是证书主体和 SAN。
+	ValidTill   string          `json:"valid_till"` // ValidTill 是 DSM 格式化后的到期时间。
+}
+
+// synologySubject 描述 DSM 证书主体中的域名字段。
+type synologySubject struct {
+	CommonName      string   `json:"common_name"`  // CommonName 是证书 CN。
+	SubjectAltNames []string `json:"sub_alt_name"` // SubjectAltNames 是证书 SAN 列表。
+}
+
+// synologyImportData 描述证书导入接口返回的证书 ID。
+type synologyImportData struct {
+	ID string `json:"id"` // ID 是新建或替换后的 DSM 证书 ID。
+}
+
+// synologyCertificateRecord 在 deploy 内部关联脱敏资源和真实 DSM 证书身份。
+type synologyCertificateRecord struct {
+	NAS           *config.SynologyConfig      // NAS 是证书所在的 DSM 配置。
+	CertificateID string                      // CertificateID 是 DSM 证书 ID，首次导入时为空。
+	Description   string                      // Description 是导入时保留或设置的证书描述。
+	Ambiguous     bool                        // Ambiguous 表示同一 DSM 上有多张证书使用相同描述。
+	Resource      SynologyCertificateResource // Resource 是可以上报的脱敏资源。
+}
@@ -9,13 +9,30 @@ import (
 
 	"github.com/https-cert/deploy/internal/client/deploys/apache"
 	"github.com/https-cert/deploy/internal/client/deploys/btpanel"
+	"github.com/https-cert/deploy/internal/client/deploys/caddy"
+	"github.com/https-cert/deploy/internal/client/deploys/database"
+	"github.com/https-cert/deploy/internal/client/deploys/docker"
 	"github.com/https-cert/deploy/internal/client/deploys/feiniu"
+	"github.com/https-cert/deploy/internal/client/deploys/firewall"
+	"github.com/https-cert/deploy/internal/client/deploys/haproxy"
+	"github.com/https-cert/deploy/internal/client/deploys/javakeystore"
+	"github.com/https-cert/deploy/internal/client/deploys/kubernetes"
+	"github.com/https-cert/deploy/internal/client/deploys/localtarget"
+	"github.com/https-cert/deploy/internal/client/deploys/mail"
+	"github.com/https-cert/deploy/internal/client/deploys/mediaserver"
 	"github.com/https-cert/deploy/internal/client/deploys/nginx"
+	"github.com/https-cert/deploy/internal/client/deploys/nginxproxymanager"
 	"github.com/https-cert/deploy/internal/client/deploys/onepanel"
 	"github.com/https-cert/deploy/internal/client/deploys/openvpnas"
+	"github.com/https-cert/deploy/internal/client/deploys/proxmox"
+	"github.com/https-cert/deploy/internal/client/deploys/proxyserver"
 	"github.com/https-cert/deploy/internal/client/deploys/rustfs"
+	"github.com/https-cert/deploy/internal/client/deploys/s3server"
 	"github.com/https-cert/deploy/internal/client/deploys/safeline"
 	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/client/deploys/sshtarget"
+	"github.com/https-cert/deploy/internal/client/deploys/synology"
+	"github.com/https-cert/deploy/internal/client/deploys/traefik"
 	"github.com/https-cert/deploy/internal/client/deploys/uploadonly"
 	"github.com/https-cert/deploy/internal/config"
 	"github.com/https-cert/deploy/pkg/logger"
@@ -30,6 +47,57 @@ type OnePanelWebsiteResource = onepanel.OnePanelWebsiteResource
 // BTPanelWebsiteResource 是宝塔网站资源的兼容别名。
 type BTPanelWebsiteResource = btpanel.BTPanelWebsiteResource
 
+// KubernetesSecretResource 是 Kubernetes TLS Secret 资源的兼容别名。
+type KubernetesSecretResource = kubernetes.KubernetesSecretResource
+
+// KubernetesSecretStatusUnsupported 表示同名 Secret 存在但不是 TLS 类型。
+const KubernetesSecretStatusUnsupported = kubernetes.KubernetesSecretStatusUnsupported
+
+// LocalTargetResource 是本地目录与自定义命令部署实例资源的兼容别名。
+type LocalTargetResource = localtarget.LocalTargetResource
+
+// SSHTargetResource 是通用 SSH 远程主机实例资源的兼容别名。
+type SSHTargetResource = sshtarget.SSHTargetResource
+
+// SynologyCertificateResource 是群晖 DSM 证书资源的兼容别名。
+type SynologyCertificateResource = synology.SynologyCertificateResource
+
+// ProxmoxNodeResource 是 Proxmox VE 节点资源的兼容别名。
+type ProxmoxNodeResource = proxmox.ProxmoxNodeResource
+
+// FirewallResource 是 OPNsense/pfSense 防火墙资源的兼容别名。
+type FirewallResource = firewall.FirewallResource
+
+// NginxServerResource 是 Nginx server 块资源的兼容别名。
+type NginxServerResource = nginx.NginxServerResource
+
+// ApacheVhostResource 是 Apache 虚拟主机资源的兼容别名。
+type ApacheVhostResource = apache.ApacheVhostResource
+
+// DockerContainerResource 是 Docker 容器资源的兼容别名。
+type DockerContainerResource = docker.DockerContainerResource
+
+// NPMProxyHostResource 是 Nginx Proxy Manager 代理主机资源的兼容别名。
+type NPMProxyHostResource = nginxproxymanager.NPMProxyHostResource
+
+// ProxyServerResource 是代理服务实例资源的兼容别名。
+type ProxyServerResource = proxyserver.ProxyServerResource
+
+// DatabaseResource 是数据库 TLS 实例资源的兼容别名。
+type DatabaseResource = database.DatabaseResource
+
+// S3ServerResource 是对象存储服务实例资源的兼容别名。
+type S3ServerResource = s3server.S3ServerResource
+
+// MediaServerResource 是媒体服务器实例资源的兼容别名。
+type MediaServerResource = mediaserver.MediaServerResource
+
+// SafeLineSiteResource 是雷池防护站点资源的兼容别名。
+type SafeLineSiteResource = safeline.SafeLineSiteResource
+
+// FeiNiuDeviceResource 是飞牛 OS 设备资源的兼容别名。
+type FeiNiuDeviceResource = feiniu.FeiNiuDeviceResource
+
 // NormalizeDeploymentDomain 校验部署域名并返回规范域名和安全目录名。
 func NormalizeDeploymentDomain(domain string) (string, string, error) {
 	return shared.NormalizeDeploymentDomain(domain)
@@ -184,6 +252,137 @@ func ReloadApacheWithContext(ctx context.Context) error {
 	return apache.ReloadApacheWithContext(ctx)
 }
 
+// DeployCertificateToCaddy 下载证书并部署到 Caddy 证书目录后通过管理 API 加载。
+func (cd *CertDeployer) DeployCertificateToCaddy(ctx context.Context, domain, downloadURL string) error {
+	sslConfig := cd.ssl()
+	if sslConfig == nil {
+		return fmt.Errorf("SSL 配置未初始化")
+	}
+	if sslConfig.Caddy == nil || sslConfig.Caddy.Path == "" {
+		return fmt.Errorf("未配置 Caddy 证书目录 (ssl.caddy.path)")
+	}
+	canonicalDomain, safeDomain, extractDir, cleanup, err := cd.prepareCertificateArchive(ctx, domain, downloadURL)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if err := caddy.DeployWithContext(ctx, extractDir, canonicalDomain, safeDomain, sslConfig.Caddy); err != nil {
+		return fmt.Errorf("部署到Caddy失败: %w", err)
+	}
+	logger.Info("Caddy证书部署完成", "domain", canonicalDomain)
+	return nil
+}
+
+// IsCaddyAvailable 返回本机是否可以找到 caddy 命令。
+func IsCaddyAvailable() bool { return caddy.IsCaddyAvailable() }
+
+// TestCaddyConnectionWithContext 使用调用方 context 测试 Caddy 管理 API。
+func TestCaddyConnectionWithContext(ctx context.Context) error {
+	return caddy.TestCaddyConnectionWithContext(ctx)
+}
+
+// DeployCertificateToHAProxy 下载证书并发布 HAProxy 合并 PEM 后热替换或重新加载。
+func (cd *CertDeployer) DeployCertificateToHAProxy(ctx context.Context, domain, downloadURL string) error {
+	sslConfig := cd.ssl()
+	if sslConfig == nil {
+		return fmt.Errorf("SSL 配置未初始化")
+	}
+	if sslConfig.HAProxy == nil || sslConfig.HAProxy.Path == "" {
+		return fmt.Errorf("未配置 HAProxy 证书目录 (ssl.haproxy.path)")
+	}
+	canonicalDomain, safeDomain, extractDir, cleanup, err := cd.prepareCertificateArchive(ctx, domain, downloadURL)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if err := haproxy.DeployWithContext(ctx, extractDir, canonicalDomain, safeDomain, sslConfig.HAProxy); err != nil {
+		return fmt.Errorf("部署到HAProxy失败: %w", err)
+	}
+	logger.Info("HAProxy证书部署完成", "domain", canonicalDomain)
+	return nil
+}
+
+// IsHAProxyAvailable 返回本机是否可以找到 haproxy 命令。
+func IsHAProxyAvailable() bool { return haproxy.IsHAProxyAvailable() }
+
+// TestHAProxyConnectionWithContext 使用调用方 context 测试 HAProxy Runtime API 或配置文件。
+func TestHAProxyConnectionWithContext(ctx context.Context) error {
+	return haproxy.TestHAProxyConnectionWithContext(ctx)
+}
+
+// DeployCertificateToMail 下载证书并发布到 Postfix 和 Dovecot 引用的目录后重新加载并校验握手。
+func (cd *CertDeployer) DeployCertificateToMail(ctx context.Context, domain, downloadURL string) error {
+	sslConfig := cd.ssl()
+	if sslConfig == nil {
+		return fmt.Errorf("SSL 配置未初始化")
+	}
+	if sslConfig.Mail == nil || sslConfig.Mail.Path == "" {
+		return fmt.Errorf("未配置邮件服务证书目录 (ssl.mail.path)")
+	}
+	canonicalDomain, safeDomain, extractDir, cleanup, err := cd.prepareCertificateArchive(ctx, domain, downloadURL)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if err := mail.DeployWithContext(ctx, extractDir, canonicalDomain, safeDomain, sslConfig.Mail); err != nil {
+		return fmt.Errorf("部署到邮件服务失败: %w", err)
+	}
+	logger.Info("邮件服务证书部署完成", "domain", canonicalDomain)
+	return nil
+}
+
+// TestMailConnectionWithContext 使用调用方 context 检查邮件服务证书目录和 Postfix、Dovecot 配置。
+func TestMailConnectionWithContext(ctx context.Context) error {
+	return mail.TestMailConnectionWithContext(ctx)
+}
+
+// DeployCertificateToTraefik 下载证书并发布到 Traefik 文件 provider 目录。
+func (cd *CertDeployer) DeployCertificateToTraefik(ctx context.Context, domain, downloadURL string) error {
+	sslConfig := cd.ssl()
+	if sslConfig == nil {
+		return fmt.Errorf("SSL 配置未初始化")
+	}
+	if sslConfig.Traefik == nil || sslConfig.Traefik.Path == "" {
+		return fmt.Errorf("未配置 Traefik 证书目录 (ssl.traefik.path)")
+	}
+	canonicalDomain, safeDomain, extractDir, cleanup, err := cd.prepareCertificateArchive(ctx, domain, downloadURL)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if err := traefik.DeployWithContext(ctx, extractDir, canonicalDomain, safeDomain, sslConfig.Traefik); err != nil {
+		return fmt.Errorf("部署到Traefik失败: %w", err)
+	}
+	logger.Info("Traefik证书部署完成", "domain", canonicalDomain)
+	return nil
+}
+
+// DeployCertificateToJavaKeystore 下载证书并转换为 PKCS#12（可选 JKS）密钥库后发布。
+func (cd *CertDeployer) DeployCertificateToJavaKeystore(ctx context.Context, domain, downloadURL string) error {
+	sslConfig := cd.ssl()
+	if sslConfig == nil {
+		return fmt.Errorf("SSL 配置未初始化")
+	}
+	if sslConfig.JavaKeystore == nil || sslConfig.JavaKeystore.Path == "" {
+		return fmt.Errorf("未配置 Java 密钥库目录 (ssl.javaKeystore.path)")
+	}
+	canonicalDomain, safeDomain, extractDir, cleanup, err := cd.prepareCertificateArchive(ctx, domain, downloadURL)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if err := javakeystore.DeployWithContext(ctx, extractDir, canonicalDomain, safeDomain, sslConfig.JavaKeystore); err != nil {
+		return fmt.Errorf("部署到Java密钥库失败: %w", err)
+	}
+	logger.Info("Java密钥库证书部署完成", "domain", canonicalDomain)
+	return nil
+}
+
+// TestJavaKeystoreConnectionWithContext 检查 Java 密钥库密码来源是否可读。
+func TestJavaKeystoreConnectionWithContext(ctx context.Context) error {
+	return javakeystore.TestJavaKeystoreConnectionWithContext(ctx)
+}
+
 // IsOnePanelConfigured 返回 operation context 是否包含可用 1Panel 配置。
 func IsOnePanelConfigured() bool { return onepanel.IsOnePanelConfigured() }
 
@@ -423,6 +622,11 @@ func (cd *CertDeployer) DeployCertificateToSafeLine(ctx context.Context, domain,
 	return nil
 }
 
+// TestOpenVPNASConnectionWithContext 使用调用方 context 测试本机或 SSH 远程 OpenVPN-AS。
+func TestOpenVPNASConnectionWithContext(ctx context.Context) error {
+	return openvpnas.TestOpenVPNASConnectionWithContext(ctx)
+}
+
 // DeployToOpenVPNAS 将证书导入 OpenVPN-AS。
 func (cd *CertDeployer) DeployToOpenVPNAS(ctx context.Context, sourceDir string) error {
 	return openvpnas.Deploy(ctx, sourceDir)
@@ -435,6 +639,13 @@ func (cd *CertDeployer) DeployCertificateToOpenVPNAS(ctx context.Context, domain
 		return err
 	}
 	defer cleanup()
+	if sslConfig := cd.ssl(); sslConfig != nil && openvpnas.IsRemote(sslConfig.OpenVPNAS) {
+		if err := openvpnas.DeployRemote(ctx, extractDir, sslConfig.OpenVPNAS, cd.knownHosts); err != nil {
+			return err
+		}
+		logger.InfoLocal("OpenVPN-AS 远程证书上传完成", "domain", canonicalDomain, "host", sslConfig.OpenVPNAS.Host)
+		return nil
+	}
 	if err := openvpnas.Deploy(ctx, extractDir); err != nil {
 		return err
 	}
@@ -466,3 +677,344 @@ func (cd *CertDeployer) DeployCertificateToUploadOnly(ctx context.Context, domai
 	logger.Info("UploadOnly 证书保存完成", "domain", canonicalDomain, "path", uploadonly.UploadOnlyTargetDir(canonicalDomain))
 	return nil
 }
+
+// IsKubernetesConfiguredWithContext 返回 operation context 是否包含可用 Kubernetes 配置。
+func IsKubernetesConfiguredWithContext(ctx context.Context) bool {
+	return kubernetes.IsKubernetesConfiguredWithContext(ctx)
+}
+
+// IsKubernetesErrorRetryable 判断 Kubernetes API 错误是否适合稍后重试。
+func IsKubernetesErrorRetryable(err error) bool { return kubernetes.IsKubernetesErrorRetryable(err) }
+
+// DiscoverKubernetesSecretResources 发现当前 Kubernetes TLS Secret 资源。
+func DiscoverKubernetesSecretResources(ctx context.Context) ([]KubernetesSecretResource, error) {
+	return kubernetes.DiscoverKubernetesSecretResources(ctx)
+}
+
+// TestKubernetesSecretConnection 只读测试精确 Kubernetes TLS Secret 资源。
+func TestKubernetesSecretConnection(ctx context.Context, targetRef string) error {
+	return kubernetes.TestKubernetesSecretConnection(ctx, targetRef)
+}
+
+// DeployCertificateToKubernetesSecret 部署证书到精确 Kubernetes TLS Secret 资源。
+func DeployCertificateToKubernetesSecret(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return kubernetes.DeployCertificateToKubernetesSecret(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsLocalTargetConfiguredWithContext 返回 operation context 是否包含本地部署实例配置。
+func IsLocalTargetConfiguredWithContext(ctx context.Context) bool {
+	return localtarget.IsLocalTargetConfiguredWithContext(ctx)
+}
+
+// DiscoverLocalTargetResources 列出配置中的本地部署实例。
+func DiscoverLocalTargetResources(ctx context.Context) ([]LocalTargetResource, error) {
+	return localtarget.DiscoverLocalTargetResources(ctx)
+}
+
+// TestLocalTargetConnection 检查本地部署实例目录和属主配置，不执行命令。
+func TestLocalTargetConnection(ctx context.Context, targetRef string) error {
+	return localtarget.TestLocalTargetConnection(ctx, targetRef)
+}
+
+// DeployCertificateToLocalTarget 部署证书到精确本地部署实例。
+func DeployCertificateToLocalTarget(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return localtarget.DeployCertificateToLocalTarget(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsSSHTargetConfiguredWithContext 返回 operation context 是否包含 SSH 远程主机配置。
+func IsSSHTargetConfiguredWithContext(ctx context.Context) bool {
+	return sshtarget.IsSSHTargetConfiguredWithContext(ctx)
+}
+
+// IsSSHTargetErrorRetryable 判断 SSH 远程主机部署错误是否适合稍后重试。
+func IsSSHTargetErrorRetryable(err error) bool { return sshtarget.IsSSHTargetErrorRetryable(err) }
+
+// DiscoverSSHTargetResources 列出配置中的 SSH 远程主机。
+func DiscoverSSHTargetResources(ctx context.Context) ([]SSHTargetResource, error) {
+	return sshtarget.DiscoverSSHTargetResources(ctx)
+}
+
+// TestSSHTargetConnection 测试精确 SSH 远程主机的登录和目录写入权限。
+func TestSSHTargetConnection(ctx context.Context, targetRef string) error {
+	return sshtarget.TestSSHTargetConnection(ctx, targetRef)
+}
+
+// DeployCertificateToSSHTarget 部署证书到精确 SSH 远程主机。
+func DeployCertificateToSSHTarget(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return sshtarget.DeployCertificateToSSHTarget(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsSynologyConfiguredWithContext 返回 operation context 是否包含群晖 NAS 配置。
+func IsSynologyConfiguredWithContext(ctx context.Context) bool {
+	return synology.IsSynologyConfiguredWithContext(ctx)
+}
+
+// IsSynologyErrorRetryable 判断群晖 DSM 部署错误是否适合稍后重试。
+func IsSynologyErrorRetryable(err error) bool { return synology.IsSynologyErrorRetryable(err) }
+
+// DiscoverSynologyCertificateResources 读取全部群晖 NAS 的证书目录。
+func DiscoverSynologyCertificateResources(ctx context.Context) ([]SynologyCertificateResource, error) {
+	return synology.DiscoverSynologyCertificateResources(ctx)
+}
+
+// TestSynologyCertificateConnection 测试精确群晖证书所在 NAS 的登录和证书读取权限。
+func TestSynologyCertificateConnection(ctx context.Context, targetRef string) error {
+	return synology.TestSynologyCertificateConnection(ctx, targetRef)
+}
+
+// DeployCertificateToSynology 导入或替换精确群晖 DSM 证书。
+func DeployCertificateToSynology(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return synology.DeployCertificateToSynology(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsProxmoxConfiguredWithContext 返回 operation context 是否包含 Proxmox VE 集群配置。
+func IsProxmoxConfiguredWithContext(ctx context.Context) bool {
+	return proxmox.IsProxmoxConfiguredWithContext(ctx)
+}
+
+// IsProxmoxErrorRetryable 判断 Proxmox VE 部署错误是否适合稍后重试。
+func IsProxmoxErrorRetryable(err error) bool { return proxmox.IsProxmoxErrorRetryable(err) }
+
+// DiscoverProxmoxNodeResources 读取全部 Proxmox VE 集群的节点目录。
+func DiscoverProxmoxNodeResources(ctx context.Context) ([]ProxmoxNodeResource, error) {
+	return proxmox.DiscoverProxmoxNodeResources(ctx)
+}
+
+// TestProxmoxNodeConnection 测试精确 Proxmox VE 节点的证书信息读取权限。
+func TestProxmoxNodeConnection(ctx context.Context, targetRef string) error {
+	return proxmox.TestProxmoxNodeConnection(ctx, targetRef)
+}
+
+// DeployCertificateToProxmoxNode 上传证书到精确 Proxmox VE 节点。
+func DeployCertificateToProxmoxNode(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return proxmox.DeployCertificateToProxmoxNode(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsFirewallConfiguredWithContext 返回 operation context 是否包含防火墙配置。
+func IsFirewallConfiguredWithContext(ctx context.Context) bool {
+	return firewall.IsFirewallConfiguredWithContext(ctx)
+}
+
+// IsFirewallErrorRetryable 判断防火墙部署错误是否适合稍后重试。
+func IsFirewallErrorRetryable(err error) bool { return firewall.IsFirewallErrorRetryable(err) }
+
+// DiscoverFirewallResources 列出配置的全部防火墙。
+func DiscoverFirewallResources(ctx context.Context) ([]FirewallResource, error) {
+	return firewall.DiscoverFirewallResources(ctx)
+}
+
+// TestFirewallConnection 测试精确防火墙的 REST API 读取权限。
+func TestFirewallConnection(ctx context.Context, targetRef string) error {
+	return firewall.TestFirewallConnection(ctx, targetRef)
+}
+
+// DeployCertificateToFirewall 导入证书到精确防火墙并切换引用。
+func DeployCertificateToFirewall(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return firewall.DeployCertificateToFirewall(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsNginxServerConfiguredWithContext 返回 operation context 是否配置了 Nginx 证书目录。
+func IsNginxServerConfiguredWithContext(ctx context.Context) bool {
+	return nginx.IsNginxServerConfiguredWithContext(ctx)
+}
+
+// DiscoverNginxServerResources 通过 nginx -T 列出全部 HTTPS server 块。
+func DiscoverNginxServerResources(ctx context.Context) ([]NginxServerResource, error) {
+	return nginx.DiscoverNginxServerResources(ctx)
+}
+
+// TestNginxServerConnection 测试精确 server 块的配置文件和证书目录写入权限。
+func TestNginxServerConnection(ctx context.Context, targetRef string) error {
+	return nginx.TestNginxServerConnection(ctx, targetRef)
+}
+
+// DeployCertificateToNginxServer 发布证书并原位改写精确 server 块的证书指令。
+func DeployCertificateToNginxServer(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return nginx.DeployCertificateToNginxServer(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsApacheVhostConfiguredWithContext 返回 operation context 是否配置了 Apache 证书目录。
+func IsApacheVhostConfiguredWithContext(ctx context.Context) bool {
+	return apache.IsApacheVhostConfiguredWithContext(ctx)
+}
+
+// DiscoverApacheVhostResources 通过 apachectl -S 列出全部 443 端口虚拟主机。
+func DiscoverApacheVhostResources(ctx context.Context) ([]ApacheVhostResource, error) {
+	return apache.DiscoverApacheVhostResources(ctx)
+}
+
+// TestApacheVhostConnection 测试精确虚拟主机的配置目录和证书目录写入权限。
+func TestApacheVhostConnection(ctx context.Context, targetRef string) error {
+	return apache.TestApacheVhostConnection(ctx, targetRef)
+}
+
+// DeployCertificateToApacheVhost 发布证书并原子改写精确虚拟主机的证书指令。
+func DeployCertificateToApacheVhost(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return apache.DeployCertificateToApacheVhost(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsDockerConfiguredWithContext 返回 operation context 是否配置了 Docker 容器部署。
+func IsDockerConfiguredWithContext(ctx context.Context) bool {
+	return docker.IsDockerConfiguredWithContext(ctx)
+}
+
+// DiscoverDockerContainerResources 通过 Docker Engine API 列出带有配置标签的运行中容器。
+func DiscoverDockerContainerResources(ctx context.Context) ([]DockerContainerResource, error) {
+	return docker.DiscoverDockerContainerResources(ctx)
+}
+
+// TestDockerContainerConnection 测试精确容器仍在运行且证书目录可写。
+func TestDockerContainerConnection(ctx context.Context, targetRef string) error {
+	return docker.TestDockerContainerConnection(ctx, targetRef)
+}
+
+// DeployCertificateToDockerContainer 发布证书到 bind mount 目录并通知同一域名的容器。
+func DeployCertificateToDockerContainer(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return docker.DeployCertificateToDockerContainer(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsNPMConfiguredWithContext 返回 operation context 是否配置了 Nginx Proxy Manager。
+func IsNPMConfiguredWithContext(ctx context.Context) bool {
+	return nginxproxymanager.IsNPMConfiguredWithContext(ctx)
+}
+
+// IsNPMErrorRetryable 判断 Nginx Proxy Manager 错误是否适合稍后重试。
+func IsNPMErrorRetryable(err error) bool { return nginxproxymanager.IsNPMErrorRetryable(err) }
+
+// DiscoverNPMProxyHostResources 发现当前 Nginx Proxy Manager 代理主机资源。
+func DiscoverNPMProxyHostResources(ctx context.Context) ([]NPMProxyHostResource, error) {
+	return nginxproxymanager.DiscoverNPMProxyHostResources(ctx)
+}
+
+// TestNPMProxyHostConnection 测试精确 Nginx Proxy Manager 代理主机资源。
+func TestNPMProxyHostConnection(ctx context.Context, targetRef string) error {
+	return nginxproxymanager.TestNPMProxyHostConnection(ctx, targetRef)
+}
+
+// DeployCertificateToNPMProxyHost 上传自定义证书并把精确代理主机指向该证书。
+func DeployCertificateToNPMProxyHost(ctx context.Context, targetRef, certificatePEM, privateKeyPEM string) error {
+	return nginxproxymanager.DeployCertificateToNPMProxyHost(ctx, targetRef, certificatePEM, privateKeyPEM)
+}
+
+// IsProxyServerConfiguredWithContext 返回 operation context 是否配置了代理服务实例。
+func IsProxyServerConfiguredWithContext(ctx context.Context) bool {
+	return proxyserver.IsProxyServerConfiguredWithContext(ctx)
+}
+
+// DiscoverProxyServerResources 列出配置中的代理服务实例。
+func DiscoverProxyServerResources(ctx context.Context) ([]ProxyServerResource, error) {
+	return proxyserver.DiscoverProxyServerResources(ctx)
+}
+
+// TestProxyServerConnection 测试精确代理服务实例的证书路径和配置校验。
+func TestProxyServerConnection(ctx context.Context, targetRef string) error {
+	return proxyserver.TestProxyServerConnection(ctx, targetRef)
+}
+
+// DeployCertificateToProxyServer 替换代理服务配置引用的证书文件并重启服务。
+func DeployCertificateToProxyServer(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return proxyserver.DeployCertificateToProxyServer(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsDatabaseConfiguredWithContext 返回 operation context 是否配置了数据库实例。
+func IsDatabaseConfiguredWithContext(ctx context.Context) bool {
+	return database.IsDatabaseConfiguredWithContext(ctx)
+}
+
+// DiscoverDatabaseResources 列出配置中的数据库实例。
+func DiscoverDatabaseResources(ctx context.Context) ([]DatabaseResource, error) {
+	return database.DiscoverDatabaseResources(ctx)
+}
+
+// TestDatabaseConnection 测试精确数据库实例的管理连接和 TLS 握手。
+func TestDatabaseConnection(ctx context.Context, targetRef string) error {
+	return database.TestDatabaseConnection(ctx, targetRef)
+}
+
+// DeployCertificateToDatabase 写入数据库证书文件并在线重新加载 TLS。
+func DeployCertificateToDatabase(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return database.DeployCertificateToDatabase(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsS3ServerConfiguredWithContext 返回 operation context 是否配置了对象存储服务实例。
+func IsS3ServerConfiguredWithContext(ctx context.Context) bool {
+	return s3server.IsS3ServerConfiguredWithContext(ctx)
+}
+
+// DiscoverS3ServerResources 列出配置中的对象存储服务实例。
+func DiscoverS3ServerResources(ctx context.Context) ([]S3ServerResource, error) {
+	return s3server.DiscoverS3ServerResources(ctx)
+}
+
+// TestS3ServerConnection 测试精确对象存储服务实例的证书目录和管理 API。
+func TestS3ServerConnection(ctx context.Context, targetRef string) error {
+	return s3server.TestS3ServerConnection(ctx, targetRef)
+}
+
+// DeployCertificateToS3Server 发布对象存储服务证书目录并重启服务。
+func DeployCertificateToS3Server(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return s3server.DeployCertificateToS3Server(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsS3ServerErrorRetryable 判断对象存储服务部署错误是否适合稍后重试。
+func IsS3ServerErrorRetryable(err error) bool { return s3server.IsS3ServerErrorRetryable(err) }
+
+// IsMediaServerConfiguredWithContext 返回 operation context 是否配置了媒体服务器实例。
+func IsMediaServerConfiguredWithContext(ctx context.Context) bool {
+	return mediaserver.IsMediaServerConfiguredWithContext(ctx)
+}
+
+// DiscoverMediaServerResources 列出配置中的媒体服务器实例。
+func DiscoverMediaServerResources(ctx context.Context) ([]MediaServerResource, error) {
+	return mediaserver.DiscoverMediaServerResources(ctx)
+}
+
+// TestMediaServerConnection 测试精确媒体服务器实例的 PFX 目录和 API 密钥。
+func TestMediaServerConnection(ctx context.Context, targetRef string) error {
+	return mediaserver.TestMediaServerConnection(ctx, targetRef)
+}
+
+// DeployCertificateToMediaServer 写入媒体服务器 PFX 并通过 HTTP API 加载新证书。
+func DeployCertificateToMediaServer(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return mediaserver.DeployCertificateToMediaServer(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsSafeLineConfiguredWithContext 返回 operation context 是否配置了雷池地址和 API Token。
+func IsSafeLineConfiguredWithContext(ctx context.Context) bool {
+	return safeline.IsSafeLineConfiguredWithContext(ctx)
+}
+
+// DiscoverSafeLineSiteResources 列出雷池防护站点。
+func DiscoverSafeLineSiteResources(ctx context.Context) ([]SafeLineSiteResource, error) {
+	return safeline.DiscoverSafeLineSiteResources(ctx)
+}
+
+// TestSafeLineSiteConnection 测试精确雷池站点资源。
+func TestSafeLineSiteConnection(ctx context.Context, targetRef string) error {
+	return safeline.TestSafeLineSiteConnection(ctx, targetRef)
+}
+
+// DeployCertificateToSafeLineSite 新增或更新雷池证书并绑定到精确站点。
+func DeployCertificateToSafeLineSite(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return safeline.DeployCertificateToSafeLineSite(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
+
+// IsFeiNiuDevicesConfiguredWithContext 返回 operation context 是否配置了具名飞牛设备。
+func IsFeiNiuDevicesConfiguredWithContext(ctx context.Context) bool {
+	return feiniu.IsFeiNiuDevicesConfiguredWithContext(ctx)
+}
+
+// DiscoverFeiNiuDeviceResources 列出飞牛设备及其主机名和当前证书到期时间。
+func DiscoverFeiNiuDeviceResources(ctx context.Context) ([]FeiNiuDeviceResource, error) {
+	return feiniu.DiscoverFeiNiuDeviceResources(ctx)
+}
+
+// TestFeiNiuDeviceConnection 测试精确飞牛设备资源。
+func TestFeiNiuDeviceConnection(ctx context.Context, targetRef string) error {
+	return feiniu.TestFeiNiuDeviceConnection(ctx, targetRef)
+}
+
+// DeployCertificateToFeiNiuDevice 通过 SSH 部署证书到精确飞牛设备。
+func DeployCertificateToFeiNiuDevice(ctx context.Context, targetRef, domain, certificatePEM, privateKeyPEM string) error {
+	return feiniu.DeployCertificateToFeiNiuDevice(ctx, targetRef, domain, certificatePEM, privateKeyPEM)
+}
@@ -0,0 +1,154 @@
+package traefik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/https-cert/deploy/internal/client/deploys/shared"
+	"github.com/https-cert/deploy/internal/config"
+	"github.com/https-cert/deploy/pkg/logger"
+)
+
+// DynamicConfigFileName 是生成在证书根目录下的 Traefik 动态配置文件名。
+const DynamicConfigFileName = "anssl-tls.yml"
+
+// dynamicConfigMu 串行化动态配置重写，避免并发部署不同域名时互相覆盖条目。
+var dynamicConfigMu sync.Mutex
+
+// DeployWithContext 发布证书目录，并在同一发布事务中原子重写 Traefik 动态配置。
+func DeployWithContext(ctx context.Context, sourceDir, domain, safeDomain string, traefikConfig *config.TraefikConfig) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if traefikConfig == nil || traefikConfig.Path == "" {
+		return errors.New("未配置 Traefik 证书目录 (ssl.traefik.path)")
+	}
+	if err := shared.ValidateCertificateFiles(sourceDir, domain); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(traefikConfig.Path, 0755); err != nil {
+		return fmt.Errorf("创建 Traefik 证书目录失败: %w", err)
+	}
+	targetDir, err := shared.SafeJoinUnderBase(traefikConfig.Path, safeDomain)
+	if err != nil {
+		return err
+	}
+
+	// Traefik 通过自身文件监听加载变更，无需 reload；配置写入失败时回滚证书目录。
+	return shared.PublishDirectoryWithValidationContext(ctx, sourceDir, targetDir, func() error {
+		if err := GenerateDynamicConfig(traefikConfig.Path); err != nil {
+			return fmt.Errorf("生成Traefik动态配置失败: %w", err)
+		}
+		logger.Info("证书文件夹已更新", "path", targetDir)
+		return nil
+	})
+}
+
+// GenerateDynamicConfig 扫描证书根目录，为每个域名目录生成 tls.certificates 条目并原子替换配置文件。
+func GenerateDynamicConfig(basePath string) error {
+	dynamicConfigMu.Lock()
+	defer dynamicConfigMu.Unlock()
+
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		return fmt.Errorf("读取证书目录失败: %w", err)
+	}
+	dirs := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs[entry.Name()] = true
+		}
+	}
+	var domains []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || isPublisherSiblingDir(name, dirs) {
+			continue
+		}
+		if shared.ValidateSafeDomainName(name) != nil {
+			continue
+		}
+		if !regularFileExists(filepath.Join(basePath, name, "cert.pem")) || !regularFileExists(filepath.Join(basePath, name, "privateKey.key")) {
+			continue
+		}
+		domains = append(domains, name)
+	}
+	sort.Strings(domains)
+
+	var content strings.Builder
+	content.WriteString("# 由 anssl deploy 自动生成，请勿手动修改。\n")
+	content.WriteString("# 在 Traefik 静态配置中通过 providers.file.filename 或 providers.file.directory 引入此文件。\n")
+	content.WriteString("tls:\n")
+	if len(domains) == 0 {
+		content.WriteString("  certificates: []\n")
+	} else {
+		content.WriteString("  certificates:\n")
+		for _, domain := range domains {
+			certDir := filepath.Join(basePath, domain)
+			fmt.Fprintf(&content, "    - certFile: %s\n", strconv.Quote(filepath.Join(certDir, "cert.pem")))
+			fmt.Fprintf(&content, "      keyFile: %s\n", strconv.Quote(filepath.Join(certDir, "privateKey.key")))
+		}
+	}
+
+	configFile := filepath.Join(basePath, DynamicConfigFileName)
+	if err := writeFileAtomically(configFile, []byte(content.String()), 0644); err != nil {
+		return err
+	}
+	logger.Info("Traefik动态配置已生成", "path", configFile, "certificates", len(domains))
+	return nil
+}
+
+// isPublisherSiblingDir 判断目录是否为发布器给同级域名目录创建的临时目录或备份目录，这类目录不能进入配置，
+// 否则回滚后 Traefik 会引用不存在的文件；docs.new 这类没有同级 docs 目录的真实域名照常保留。
+func isPublisherSiblingDir(name string, dirs map[string]bool) bool {
+	for _, suffix := range []string{".new", ".bak"} {
+		if base, ok := strings.CutSuffix(name, suffix); ok && base != "" && dirs[base] {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFileAtomically 先写入同目录临时文件再重命名，避免 Traefik 文件监听读到半写配置。
+func writeFileAtomically(path string, content []byte, mode os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("创建临时配置文件失败: %w", err)
+	}
+	tempName := tempFile.Name()
+	defer os.Remove(tempName)
+
+	if _, err := tempFile.Write(content); err != nil {
+		_ = tempFile.Close()
+		return fmt.Errorf("写入临时配置文件失败: %w", err)
+	}
+	if err := tempFile.Chmod(mode); err != nil {
+		_ = tempFile.Close()
+		return fmt.Errorf("设置配置文件权限失败: %w", err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		_ = tempFile.Close()
+		return fmt.Errorf("同步临时配置文件失败: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("关闭临时配置文件失败: %w", err)
+	}
+	if err := os.Rename(tempName, path); err != nil {
+		return fmt.Errorf("替换配置文件失败: %w", err)
+	}
+	return nil
+}
+
+// regularFileExists 判断路径是否为普通文件。
+func regularFileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,104 @@
+package traefik
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/config"
+)
+
+// TestDeployWithContextWritesEntryPerDomain 验证每个已发布域名都会生成一条 tls.certificates 配置。
+func TestDeployWithContextWritesEntryPerDomain(t *testing.T) {
+	basePath := t.TempDir()
+	traefikConfig := &config.TraefikConfig{Path: basePath}
+	for _, domain := range []string{"b.example.com", "a.example.com"} {
+		sourceDir := t.TempDir()
+		writeTestCertificatePair(t, sourceDir, domain)
+		if err := DeployWithContext(context.Background(), sourceDir, domain, domain, traefikConfig); err != nil {
+			t.Fatalf("DeployWithContext(%s): %v", domain, err)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(basePath, DynamicConfigFileName))
+	if err != nil {
+		t.Fatalf("动态配置未生成: %v", err)
+	}
+	text := string(content)
+	first := strings.Index(text, filepath.Join(basePath, "a.example.com", "cert.pem"))
+	second := strings.Index(text, filepath.Join(basePath, "b.example.com", "cert.pem"))
+	if first < 0 || second < 0 || first > second || strings.Count(text, "keyFile:") != 2 {
+		t.Fatalf("动态配置条目不匹配:\n%s", text)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(basePath, ".*.tmp-*"))
+	if len(leftovers) != 0 {
+		t.Fatalf("临时配置文件未清理: %v", leftovers)
+	}
+}
+
+// TestGenerateDynamicConfigSkipsIncompleteDirectories 验证缺少私钥或发布器备份目录不会进入配置，以 .new/.bak 结尾的真实域名目录照常保留。
+func TestGenerateDynamicConfigSkipsIncompleteDirectories(t *testing.T) {
+	basePath := t.TempDir()
+	for _, dir := range []string{"example.com", "example.com.bak", "docs.new", "x.bak", "missing-key.example.com"} {
+		if err := os.MkdirAll(filepath.Join(basePath, dir), 0755); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(basePath, dir, "cert.pem"), []byte("cert"), 0o644); err != nil {
+			t.Fatalf("write cert: %v", err)
+		}
+	}
+	for _, dir := range []string{"example.com", "example.com.bak", "docs.new", "x.bak"} {
+		if err := os.WriteFile(filepath.Join(basePath, dir, "privateKey.key"), []byte("key"), 0o600); err != nil {
+			t.Fatalf("write key: %v", err)
+		}
+	}
+
+	if err := GenerateDynamicConfig(basePath); err != nil {
+		t.Fatalf("GenerateDynamicConfig: %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(basePath, DynamicConfigFileName))
+	if strings.Count(string(content), "certFile:") != 3 || strings.Contains(string(content), "example.com.bak") || !strings.Contains(string(content), "docs.new") || !strings.Contains(string(content), "x.bak") || strings.Contains(string(content), "missing-key") {
+		t.Fatalf("动态配置包含无效目录:\n%s", content)
+	}
+}
+
+// writeTestCertificatePair 写入测试用自签证书和匹配私钥。
+func writeTestCertificatePair(t *testing.T, dir, domain string) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0o644); err != nil {
+		t.Fatalf("write cert.pem: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	if err := os.WriteFile(filepath.Join(dir, "privateKey.key"), keyPEM, 0o600); err != nil {
+		t.Fatalf("write privateKey.key: %v", err)
+	}
+}
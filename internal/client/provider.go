@@ -16,6 +16,9 @@ var testFeiNiuConnection = deploys.TestFeiNiuConnectionWithContext
 // testRustFSConnection 允许连接测试使用替身而不触碰真实 RustFS 环境。
 var testRustFSConnection = deploys.TestRustFSConnectionWithContext
 
+// testOpenVPNASConnection 允许连接测试使用替身而不执行真实 sacli 或建立 SSH 连接。
+var testOpenVPNASConnection = deploys.TestOpenVPNASConnectionWithContext
+
 // testOnePanelConnection 允许连接测试使用替身而不请求真实 1Panel API。
 var testOnePanelConnection = deploys.TestOnePanelConnectionWithContext
 
@@ -31,6 +34,66 @@ var testBTPanelCertificateConnection = deploys.TestBTPanelCertificateConnectionW
 // testSafeLineConnection 允许连接测试使用替身而不请求真实雷池 OpenAPI。
 var testSafeLineConnection = deploys.TestSafeLineConnectionWithContext
 
+// testCaddyConnection 允许连接测试使用替身而不请求真实 Caddy 管理 API。
+var testCaddyConnection = deploys.TestCaddyConnectionWithContext
+
+// testHAProxyConnection 允许连接测试使用替身而不连接真实 HAProxy Runtime API。
+var testHAProxyConnection = deploys.TestHAProxyConnectionWithContext
+
+// testMailConnection 允许连接测试使用替身而不执行真实 Postfix 和 Dovecot 命令。
+var testMailConnection = deploys.TestMailConnectionWithContext
+
+// testKubernetesSecretConnection 允许连接测试使用替身而不请求真实 Kubernetes API Server。
+var testKubernetesSecretConnection = deploys.TestKubernetesSecretConnection
+
+// testJavaKeystoreConnection 允许连接测试使用替身而不读取真实密码来源。
+var testJavaKeystoreConnection = deploys.TestJavaKeystoreConnectionWithContext
+
+// testLocalTargetConnection 允许连接测试使用替身而不访问真实本地部署目录。
+var testLocalTargetConnection = deploys.TestLocalTargetConnection
+
+// testSSHTargetConnection 允许连接测试使用替身而不建立真实 SSH 连接。
+var testSSHTargetConnection = deploys.TestSSHTargetConnection
+
+// testSynologyConnection 允许连接测试使用替身而不登录真实 DSM。
+var testSynologyConnection = deploys.TestSynologyCertificateConnection
+
+// testProxmoxConnection 允许连接测试使用替身而不请求真实 Proxmox VE API。
+var testProxmoxConnection = deploys.TestProxmoxNodeConnection
+
+// testFirewallConnection 允许连接测试使用替身而不请求真实防火墙 API。
+var testFirewallConnection = deploys.TestFirewallConnection
+
+// testNginxServerConnection 允许连接测试使用替身而不执行真实 nginx 命令。
+var testNginxServerConnection = deploys.TestNginxServerConnection
+
+// testApacheVhostConnection 允许连接测试使用替身而不执行真实 apachectl 命令。
+var testApacheVhostConnection = deploys.TestApacheVhostConnection
+
+// testDockerContainerConnection 允许连接测试使用替身而不请求真实 Docker Engine API。
+var testDockerContainerConnection = deploys.TestDockerContainerConnection
+
+// testNPMProxyHostConnection 允许连接测试使用替身而不请求真实 Nginx Proxy Manager API。
+var testNPMProxyHostConnection = deploys.TestNPMProxyHostConnection
+
+// testProxyServerConnection 允许连接测试使用替身而不执行真实代理服务配置校验。
+var testProxyServerConnection = deploys.TestProxyServerConnection
+
+// testDatabaseConnection 允许连接测试使用替身而不连接真实数据库。
+var testDatabaseConnection = deploys.TestDatabaseConnection
+
+// testS3ServerConnection 允许连接测试使用替身而不访问真实对象存储服务。
+var testS3ServerConnection = deploys.TestS3ServerConnection
+
+// testMediaServerConnection 允许连接测试使用替身而不访问真实媒体服务器。
+var testMediaServerConnection = deploys.TestMediaServerConnection
+
+// testSafeLineSiteConnection 允许连接测试使用替身而不请求真实雷池站点接口。
+var testSafeLineSiteConnection = deploys.TestSafeLineSiteConnection
+
+// testFeiNiuDeviceConnection 允许连接测试使用替身而不建立真实飞牛设备 SSH 连接。
+var testFeiNiuDeviceConnection = deploys.TestFeiNiuDeviceConnection
+
 // TestProviderConnection 测试 config.yaml 中的云服务 provider，供 CLI doctor 复用。
 func TestProviderConnection(ctx context.Context, runtime *config.Runtime, providerName string) (bool, error) {
 	provider, ok := config.DeploymentProviderFromName(providerName)
@@ -60,6 +123,11 @@ func testDeploymentConnection(ctx context.Context, provider deployPB.Provider, d
 				return false, err
 			}
 		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_OPENVPN_AS_CERT {
+			if err := testOpenVPNASConnection(ctx); err != nil {
+				return false, err
+			}
+		}
 		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_CERT {
 			if err := testOnePanelConnection(ctx); err != nil {
 				return false, err
@@ -85,6 +153,106 @@ func testDeploymentConnection(ctx context.Context, provider deployPB.Provider, d
 				return false, err
 			}
 		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_CADDY_CERT {
+			if err := testCaddyConnection(ctx); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_HAPROXY_CERT {
+			if err := testHAProxyConnection(ctx); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MAIL_CERT {
+			if err := testMailConnection(ctx); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_KUBERNETES_SECRET_CERT {
+			if err := testKubernetesSecretConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_JAVA_KEYSTORE_CERT {
+			if err := testJavaKeystoreConnection(ctx); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_LOCAL_TARGET_CERT {
+			if err := testLocalTargetConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SSH_CERT {
+			if err := testSSHTargetConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SYNOLOGY_CERT {
+			if err := testSynologyConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXMOX_CERT {
+			if err := testProxmoxConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FIREWALL_CERT {
+			if err := testFirewallConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_SERVER_CERT {
+			if err := testNginxServerConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_VHOST_CERT {
+			if err := testApacheVhostConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DOCKER_CONTAINER_CERT {
+			if err := testDockerContainerConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NPM_PROXY_HOST_CERT {
+			if err := testNPMProxyHostConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXY_SERVER_CERT {
+			if err := testProxyServerConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DATABASE_TLS_CERT {
+			if err := testDatabaseConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_S3_SERVER_CERT {
+			if err := testS3ServerConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MEDIA_SERVER_PFX {
+			if err := testMediaServerConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_SITE_CERT {
+			if err := testSafeLineSiteConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
+		if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_DEVICE_CERT {
+			if err := testFeiNiuDeviceConnection(ctx, targetRef); err != nil {
+				return false, err
+			}
+		}
 		return true, nil
 
 	default:
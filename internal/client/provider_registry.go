@@ -5,6 +5,7 @@ import (
 
 	"github.com/https-cert/deploy/internal/client/providers"
 	"github.com/https-cert/deploy/internal/client/providers/aliyun"
+	"github.com/https-cert/deploy/internal/client/providers/aws"
 	"github.com/https-cert/deploy/internal/client/providers/baidu"
 	cloud_tencent "github.com/https-cert/deploy/internal/client/providers/cloud_tencent"
 	"github.com/https-cert/deploy/internal/client/providers/dogecloud"
@@ -42,6 +43,7 @@ var providerDefinitions = []providerDefinition{
 	{Provider: deployPB.Provider_PROVIDER_VOLCENGINE, ConfigName: config.ProviderVolcengine, UploadOnly: true, ResourceTypes: []deployPB.DeploymentType{deployPB.DeploymentType_DEPLOYMENT_TYPE_CDN, deployPB.DeploymentType_DEPLOYMENT_TYPE_DCDN, deployPB.DeploymentType_DEPLOYMENT_TYPE_TOS_CUSTOM_DOMAIN, deployPB.DeploymentType_DEPLOYMENT_TYPE_CLB, deployPB.DeploymentType_DEPLOYMENT_TYPE_ALB, deployPB.DeploymentType_DEPLOYMENT_TYPE_NLB}, New: newVolcengineHandler},
 	{Provider: deployPB.Provider_PROVIDER_HUAWEI_CLOUD, ConfigName: config.ProviderHuaweiCloud, UploadOnly: true, ResourceTypes: []deployPB.DeploymentType{deployPB.DeploymentType_DEPLOYMENT_TYPE_CDN, deployPB.DeploymentType_DEPLOYMENT_TYPE_DCDN, deployPB.DeploymentType_DEPLOYMENT_TYPE_OBS_CUSTOM_DOMAIN, deployPB.DeploymentType_DEPLOYMENT_TYPE_ELB}, New: newHuaweiHandler},
 	{Provider: deployPB.Provider_PROVIDER_LECDN, ConfigName: config.ProviderLeCDN, UploadOnly: false, ResourceTypes: []deployPB.DeploymentType{deployPB.DeploymentType_DEPLOYMENT_TYPE_CDN}, New: newLeCDNHandler},
+	{Provider: deployPB.Provider_PROVIDER_AWS, ConfigName: config.ProviderAWS, UploadOnly: true, ResourceTypes: []deployPB.DeploymentType{deployPB.DeploymentType_DEPLOYMENT_TYPE_CDN, deployPB.DeploymentType_DEPLOYMENT_TYPE_ALB, deployPB.DeploymentType_DEPLOYMENT_TYPE_NLB}, New: newAWSHandler},
 }
 
 // findProviderDefinition 按协议枚举查找唯一云厂商定义。
@@ -222,3 +224,11 @@ func newLeCDNHandler(configuration *config.Provider) (any, error) {
 	}
 	return lecdn.New(configuration.GetAPIBaseURL(), configuration.GetAPIToken()), nil
 }
+
+// newAWSHandler 创建亚马逊云科技 provider。
+func newAWSHandler(configuration *config.Provider) (any, error) {
+	if err := providerAuthRequired(configuration, "accessKeyId", "accessKeySecret"); err != nil {
+		return nil, fmt.Errorf("AWS%s", err)
+	}
+	return aws.New(configuration.GetAccessKeyId(), configuration.GetAccessKeySecret(), configuration.Region, configuration.CertificateRegion, configuration.Regions)
+}
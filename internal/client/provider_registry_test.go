@@ -29,8 +29,8 @@ func TestProviderRegistryIsUniqueAndComplete(t *testing.T) {
 			}
 		}
 	}
-	if len(seenProviders) != 9 {
-		t.Fatalf("registered cloud provider count = %d, want 9", len(seenProviders))
+	if len(seenProviders) != 10 {
+		t.Fatalf("registered cloud provider count = %d, want 10", len(seenProviders))
 	}
 }
 
@@ -0,0 +1,290 @@
+package aws
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/client/providers"
+)
+
+const (
+	acmTypeImported = "IMPORTED"
+	acmStatusIssued = "ISSUED"
+)
+
+// acmKeyTypes 覆盖 ACM 支持导入的全部密钥类型；ListCertificates 默认只返回 RSA_2048 证书。
+var acmKeyTypes = []string{"RSA_1024", "RSA_2048", "RSA_3072", "RSA_4096", "EC_prime256v1", "EC_secp384r1", "EC_secp521r1"}
+
+// acmListCertificatesInput 是 ACM ListCertificates 请求。
+type acmListCertificatesInput struct {
+	Includes  *acmCertificateFilters `json:"Includes,omitempty"`  // Includes 指定需要列出的密钥类型。
+	MaxItems  int                    `json:"MaxItems,omitempty"`  // MaxItems 是单页数量。
+	NextToken string                 `json:"NextToken,omitempty"` // NextToken 是下一页游标。
+}
+
+// acmCertificateFilters 是 ListCertificates 的附加过滤条件。
+type acmCertificateFilters struct {
+	KeyTypes []string `json:"keyTypes,omitempty"` // KeyTypes 是需要列出的证书密钥类型。
+}
+
+// acmListCertificatesOutput 是 ACM ListCertificates 响应。
+type acmListCertificatesOutput struct {
+	CertificateSummaryList []acmCertificateSummary `json:"CertificateSummaryList"` // CertificateSummaryList 是当前页证书摘要。
+	NextToken              string                  `json:"NextToken"`              // NextToken 为空表示最后一页。
+}
+
+// acmCertificateSummary 保存证书复用判断所需的 ACM 摘要字段。
+type acmCertificateSummary struct {
+	CertificateArn                       string   `json:"CertificateArn"`                       // CertificateArn 是证书 ARN。
+	DomainName                           string   `json:"DomainName"`                           // DomainName 是证书主域名。
+	SubjectAlternativeNameSummaries      []string `json:"SubjectAlternativeNameSummaries"`      // SubjectAlternativeNameSummaries 是截断后的 SAN 列表。
+	HasAdditionalSubjectAlternativeNames bool     `json:"HasAdditionalSubjectAlternativeNames"` // HasAdditionalSubjectAlternativeNames 表示 SAN 列表被截断。
+	Type                                 string   `json:"Type"`                                 // Type 是 IMPORTED 或 AMAZON_ISSUED 等来源。
+	Status                               string   `json:"Status"`                               // Status 是证书状态。
+}
+
+// acmCertificateDetail 保存 DescribeCertificate 返回的证书身份。
+type acmCertificateDetail struct {
+	CertificateArn          string   `json:"CertificateArn"`          // CertificateArn 是证书 ARN。
+	DomainName              string   `json:"DomainName"`              // DomainName 是证书主域名。
+	SubjectAlternativeNames []string `json:"SubjectAlternativeNames"` // SubjectAlternativeNames 是完整 SAN 列表。
+	Type                    string   `json:"Type"`                    // Type 是证书来源。
+	Status                  string   `json:"Status"`                  // Status 是证书状态。
+}
+
+// acmImportCertificateInput 是 ACM ImportCertificate 请求，证书字段按 JSON Blob 规则 Base64 编码。
+type acmImportCertificateInput struct {
+	CertificateArn   string `json:"CertificateArn,omitempty"`   // CertificateArn 非空时原地重新导入。
+	Certificate      []byte `json:"Certificate"`                // Certificate 是叶证书 PEM。
+	PrivateKey       []byte `json:"PrivateKey"`                 // PrivateKey 是私钥 PEM。
+	CertificateChain []byte `json:"CertificateChain,omitempty"` // CertificateChain 是中间证书 PEM。
+}
+
+// ensureACMCertificate 返回指定地域内承载该证书的 ACM ARN：指纹相同直接复用，同域名导入证书原地重新导入，否则新建导入。
+func (p *Provider) ensureACMCertificate(ctx context.Context, region string, certificate providers.CertificateMaterial, currentARN string) (string, string, error) {
+	leafPEM, chainPEM, domains, err := splitCertificateChain(certificate.CertificatePEM)
+	if err != nil {
+		return "", "", providers.NewDeploymentError("AWS 证书内容解析失败", false, "", err)
+	}
+	candidates, requestID, err := p.findImportedACMCertificates(ctx, region, domains, currentARN)
+	if err != nil {
+		return "", requestID, err
+	}
+	for _, candidate := range candidates {
+		matched, readbackRequestID, err := p.acmCertificateMatches(ctx, region, candidate, certificate.CertificatePEM)
+		requestID = firstNonEmpty(readbackRequestID, requestID)
+		if err != nil {
+			return "", requestID, err
+		}
+		if matched {
+			verifyRequestID, err := p.verifyACMCertificate(ctx, region, candidate, certificate.CertificatePEM)
+			return candidate, firstNonEmpty(verifyRequestID, requestID), err
+		}
+	}
+
+	input := acmImportCertificateInput{
+		Certificate:      []byte(leafPEM),
+		PrivateKey:       []byte(certificate.PrivateKeyPEM),
+		CertificateChain: []byte(chainPEM),
+	}
+	if len(candidates) > 0 {
+		input.CertificateArn = candidates[0]
+	}
+	if chainPEM == "" {
+		input.CertificateChain = nil
+	}
+	var output struct {
+		CertificateArn string `json:"CertificateArn"`
+	}
+	importRequestID, err := p.callACM(ctx, region, "导入 ACM 证书", "ImportCertificate", input, &output)
+	requestID = firstNonEmpty(importRequestID, requestID)
+	if err != nil {
+		return "", requestID, err
+	}
+	certificateARN := strings.TrimSpace(output.CertificateArn)
+	if certificateARN == "" || (input.CertificateArn != "" && certificateARN != input.CertificateArn) {
+		return "", requestID, providers.NewDeploymentError("AWS ACM 导入响应证书 ARN 无效", false, requestID, nil)
+	}
+	readbackRequestID, err := p.verifyACMCertificate(ctx, region, certificateARN, certificate.CertificatePEM)
+	return certificateARN, firstNonEmpty(readbackRequestID, requestID), err
+}
+
+// findImportedACMCertificates 返回域名集合与新证书完全一致的导入证书 ARN，当前槽位证书优先。
+func (p *Provider) findImportedACMCertificates(ctx context.Context, region string, domains []string, currentARN string) ([]string, string, error) {
+	if isACMCertificateARN(currentARN) {
+		detail, requestID, err := p.describeACMCertificate(ctx, region, currentARN)
+		if err != nil {
+			return nil, requestID, err
+		}
+		if detail.Type == acmTypeImported && sameDomains(acmCertificateDomains(detail.DomainName, detail.SubjectAlternativeNames), domains) {
+			return []string{currentARN}, requestID, nil
+		}
+	}
+	summaries, requestID, err := p.listACMCertificates(ctx, region)
+	if err != nil {
+		return nil, requestID, err
+	}
+	candidates := make([]string, 0)
+	for _, summary := range summaries {
+		if summary.Type != acmTypeImported || strings.TrimSpace(summary.CertificateArn) == "" {
+			continue
+		}
+		summaryDomains := acmCertificateDomains(summary.DomainName, summary.SubjectAlternativeNameSummaries)
+		if summary.HasAdditionalSubjectAlternativeNames {
+			detail, detailRequestID, err := p.describeACMCertificate(ctx, region, summary.CertificateArn)
+			requestID = firstNonEmpty(detailRequestID, requestID)
+			if err != nil {
+				return nil, requestID, err
+			}
+			summaryDomains = acmCertificateDomains(detail.DomainName, detail.SubjectAlternativeNames)
+		}
+		if sameDomains(summaryDomains, domains) {
+			candidates = append(candidates, summary.CertificateArn)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates, requestID, nil
+}
+
+// listACMCertificates 分页读取指定地域的全部 ACM 证书摘要。
+func (p *Provider) listACMCertificates(ctx context.Context, region string) ([]acmCertificateSummary, string, error) {
+	summaries := make([]acmCertificateSummary, 0)
+	requestID := ""
+	nextToken := ""
+	for page := 0; page < maxPages; page++ {
+		var output acmListCertificatesOutput
+		pageRequestID, err := p.callACM(ctx, region, "读取 ACM 证书列表", "ListCertificates", acmListCertificatesInput{
+			Includes:  &acmCertificateFilters{KeyTypes: acmKeyTypes},
+			MaxItems:  pageSize,
+			NextToken: nextToken,
+		}, &output)
+		requestID = firstNonEmpty(pageRequestID, requestID)
+		if err != nil {
+			return nil, requestID, err
+		}
+		summaries = append(summaries, output.CertificateSummaryList...)
+		nextToken = strings.TrimSpace(output.NextToken)
+		if nextToken == "" {
+			return summaries, requestID, nil
+		}
+	}
+	return nil, requestID, providers.NewDeploymentError("AWS ACM 证书列表超过安全分页上限", false, requestID, nil)
+}
+
+// describeACMCertificate 读取一个 ACM 证书的来源、状态和完整域名集合。
+func (p *Provider) describeACMCertificate(ctx context.Context, region, certificateARN string) (acmCertificateDetail, string, error) {
+	var output struct {
+		Certificate *acmCertificateDetail `json:"Certificate"`
+	}
+	requestID, err := p.callACM(ctx, region, "读取 ACM 证书详情", "DescribeCertificate", map[string]string{"CertificateArn": certificateARN}, &output)
+	if err != nil {
+		return acmCertificateDetail{}, requestID, err
+	}
+	if output.Certificate == nil || output.Certificate.CertificateArn != certificateARN {
+		return acmCertificateDetail{}, requestID, providers.NewDeploymentError("AWS ACM 证书详情回读结果不一致", true, requestID, nil)
+	}
+	return *output.Certificate, requestID, nil
+}
+
+// verifyACMCertificate 回读 ACM 证书状态和叶证书内容，确认与提交证书的 SHA-256 指纹一致。
+func (p *Provider) verifyACMCertificate(ctx context.Context, region, certificateARN, certificatePEM string) (string, error) {
+	detail, requestID, err := p.describeACMCertificate(ctx, region, certificateARN)
+	if err != nil {
+		return requestID, err
+	}
+	if detail.Type != acmTypeImported || detail.Status != acmStatusIssued {
+		return requestID, providers.NewDeploymentError("AWS ACM 证书状态不可用于部署", false, requestID, nil)
+	}
+	matched, getRequestID, err := p.acmCertificateMatches(ctx, region, certificateARN, certificatePEM)
+	requestID = firstNonEmpty(getRequestID, requestID)
+	if err != nil {
+		return requestID, err
+	}
+	if !matched {
+		return requestID, providers.NewDeploymentError("AWS ACM 证书指纹回读校验失败", true, requestID, nil)
+	}
+	return requestID, nil
+}
+
+// acmCertificateMatches 读取 ACM 证书内容并比较叶证书 SHA-256 指纹。
+func (p *Provider) acmCertificateMatches(ctx context.Context, region, certificateARN, certificatePEM string) (bool, string, error) {
+	var output struct {
+		Certificate string `json:"Certificate"`
+	}
+	requestID, err := p.callACM(ctx, region, "回读 ACM 证书", "GetCertificate", map[string]string{"CertificateArn": certificateARN}, &output)
+	if err != nil {
+		return false, requestID, err
+	}
+	if strings.TrimSpace(output.Certificate) == "" {
+		return false, requestID, providers.NewDeploymentError("AWS ACM 证书回读内容为空", true, requestID, nil)
+	}
+	return providers.VerifyLeafCertificateSHA256(certificatePEM, output.Certificate) == nil, requestID, nil
+}
+
+// splitCertificateChain 拆分叶证书和中间证书链，并返回叶证书覆盖的规范化域名集合。
+func splitCertificateChain(certificatePEM string) (string, string, []string, error) {
+	rest := []byte(certificatePEM)
+	var leafPEM string
+	var chain strings.Builder
+	var domains []string
+	for len(rest) > 0 {
+		block, remaining := pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		rest = remaining
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		encoded := string(pem.EncodeToMemory(block))
+		if leafPEM != "" {
+			chain.WriteString(encoded)
+			continue
+		}
+		leaf, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("解析叶证书失败: %w", err)
+		}
+		leafPEM = encoded
+		domains = leaf.DNSNames
+		if len(domains) == 0 && leaf.Subject.CommonName != "" {
+			domains = []string{leaf.Subject.CommonName}
+		}
+	}
+	if leafPEM == "" {
+		return "", "", nil, fmt.Errorf("证书 PEM 中没有证书块")
+	}
+	normalized := providers.NormalizeDomains(domains...)
+	if len(normalized) == 0 {
+		return "", "", nil, fmt.Errorf("叶证书缺少可识别域名")
+	}
+	return leafPEM, chain.String(), normalized, nil
+}
+
+// acmCertificateDomains 合并 ACM 主域名和 SAN 并规范化。
+func acmCertificateDomains(domainName string, subjectAlternativeNames []string) []string {
+	return providers.NormalizeDomains(append([]string{domainName}, subjectAlternativeNames...)...)
+}
+
+// sameDomains 判断两个已规范化排序的域名集合是否完全相同。
+func sameDomains(left, right []string) bool {
+	if len(left) == 0 || len(left) != len(right) {
+		return false
+	}
+	for index := range left {
+		if left[index] != right[index] {
+			return false
+		}
+	}
+	return true
+}
+
+// isACMCertificateARN 判断证书 ARN 是否由 ACM 管理；IAM 服务器证书不能原地重新导入。
+func isACMCertificateARN(certificateARN string) bool {
+	parts := strings.SplitN(strings.TrimSpace(certificateARN), ":", 6)
+	return len(parts) == 6 && parts[0] == "arn" && parts[2] == "acm" && strings.HasPrefix(parts[5], "certificate/")
+}
@@ -0,0 +1,158 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/pkg/sigv4"
+)
+
+const (
+	serviceACM        = "acm"
+	serviceCloudFront = "cloudfront"
+	serviceELB        = "elasticloadbalancing"
+	elbAPIVersion     = "2015-12-01"
+)
+
+// apiRequest 描述一次待签名的 AWS 控制面请求。
+type apiRequest struct {
+	Operation string      // Operation 是用于诊断的控制面操作名称。
+	Service   string      // Service 是 SigV4 签名服务名，同时决定官方控制面主机。
+	Region    string      // Region 是 SigV4 签名地域。
+	Method    string      // Method 是 HTTP 方法。
+	Path      string      // Path 是不含查询参数的请求路径。
+	Query     url.Values  // Query 是需要参与签名的查询参数。
+	Header    http.Header // Header 是需要附加并参与签名的请求头。
+	Body      []byte      // Body 是原始请求体。
+}
+
+// apiResponse 保存成功响应的原始内容和请求编号。
+type apiResponse struct {
+	Body      []byte      // Body 是原始响应体。
+	Header    http.Header // Header 是响应头，CloudFront 从中读取 ETag。
+	RequestID string      // RequestID 是 AWS 请求编号。
+}
+
+// call 创建、签名并发送一次 AWS 请求，非 2xx 响应统一转换为 apiError。
+func (p *Provider) call(ctx context.Context, input apiRequest) (apiResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	requestURL := p.serviceEndpoint(input.Service, input.Region) + input.Path
+	if len(input.Query) > 0 {
+		requestURL += "?" + input.Query.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, input.Method, requestURL, bytes.NewReader(input.Body))
+	if err != nil {
+		return apiResponse{}, &apiError{Operation: input.Operation, Retryable: false, Cause: err}
+	}
+	for name, values := range input.Header {
+		for _, value := range values {
+			request.Header.Add(name, value)
+		}
+	}
+	// SigV4 把 Host 纳入规范请求，签名前固定为实际发送的主机。
+	request.Host = request.URL.Host
+	sigv4.SignRequest(request, input.Body, p.accessKeyID, p.secretAccessKey, input.Region, input.Service, time.Now())
+
+	response, err := p.httpClient.Do(request)
+	if err != nil {
+		return apiResponse{}, &apiError{Operation: input.Operation, Retryable: true, Cause: err}
+	}
+	defer response.Body.Close()
+	requestID := responseRequestID(response.Header)
+	responseBody, readErr := io.ReadAll(io.LimitReader(response.Body, maxResponseBytes+1))
+	if readErr != nil {
+		return apiResponse{}, &apiError{Operation: input.Operation, Status: response.StatusCode, RequestID: requestID, Retryable: true, Cause: readErr}
+	}
+	if len(responseBody) > maxResponseBytes {
+		return apiResponse{}, &apiError{Operation: input.Operation, Status: response.StatusCode, RequestID: requestID, Retryable: false}
+	}
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		code, bodyRequestID := parseErrorResponse(response.Header, responseBody)
+		requestID = firstNonEmpty(requestID, bodyRequestID)
+		return apiResponse{}, &apiError{
+			Operation: input.Operation,
+			Status:    response.StatusCode,
+			Code:      code,
+			RequestID: requestID,
+			Retryable: isRetryable(response.StatusCode, code),
+		}
+	}
+	return apiResponse{Body: responseBody, Header: response.Header, RequestID: requestID}, nil
+}
+
+// callACM 调用 ACM JSON 1.1 协议接口并解码响应。
+func (p *Provider) callACM(ctx context.Context, region, operation, action string, input, output any) (string, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return "", &apiError{Operation: operation, Retryable: false, Cause: err}
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-amz-json-1.1")
+	header.Set("X-Amz-Target", "CertificateManager."+action)
+	response, err := p.call(ctx, apiRequest{Operation: operation, Service: serviceACM, Region: region, Method: http.MethodPost, Path: "/", Header: header, Body: body})
+	if err != nil {
+		return "", err
+	}
+	if output != nil {
+		if err := json.Unmarshal(response.Body, output); err != nil {
+			return response.RequestID, &apiError{Operation: operation, RequestID: response.RequestID, Retryable: true, Cause: err}
+		}
+	}
+	return response.RequestID, nil
+}
+
+// callELB 调用 ELBv2 Query 协议接口并解码 XML 响应。
+func (p *Provider) callELB(ctx context.Context, region, operation, action string, parameters url.Values, output any) (string, error) {
+	form := url.Values{}
+	for key, values := range parameters {
+		form[key] = append([]string(nil), values...)
+	}
+	form.Set("Action", action)
+	form.Set("Version", elbAPIVersion)
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	response, err := p.call(ctx, apiRequest{Operation: operation, Service: serviceELB, Region: region, Method: http.MethodPost, Path: "/", Header: header, Body: []byte(form.Encode())})
+	if err != nil {
+		return "", err
+	}
+	var metadata struct {
+		RequestID string `xml:"ResponseMetadata>RequestId"`
+	}
+	_ = xml.Unmarshal(response.Body, &metadata)
+	requestID := firstNonEmpty(response.RequestID, metadata.RequestID)
+	if output != nil {
+		if err := xml.Unmarshal(response.Body, output); err != nil {
+			return requestID, &apiError{Operation: operation, RequestID: requestID, Retryable: true, Cause: err}
+		}
+	}
+	return requestID, nil
+}
+
+// callCloudFront 调用 CloudFront REST-XML 接口，CloudFront 是全局服务，固定使用 us-east-1 签名。
+func (p *Provider) callCloudFront(ctx context.Context, operation, method, path string, query url.Values, header http.Header, body []byte) (apiResponse, error) {
+	return p.call(ctx, apiRequest{Operation: operation, Service: serviceCloudFront, Region: cloudFrontRegion, Method: method, Path: path, Query: query, Header: header, Body: body})
+}
+
+// serviceEndpoint 返回服务控制面地址；配置了测试替身时所有服务共用同一地址。
+func (p *Provider) serviceEndpoint(service, region string) string {
+	if p.endpoint != "" {
+		return p.endpoint
+	}
+	if service == serviceCloudFront {
+		return "https://cloudfront.amazonaws.com"
+	}
+	suffix := "amazonaws.com"
+	if strings.HasPrefix(region, "cn-") {
+		suffix = "amazonaws.com.cn"
+	}
+	return "https://" + service + "." + region + "." + suffix
+}
@@ -0,0 +1,271 @@
+// Package aws implements AWS ACM certificate import and CloudFront/ELBv2 deployment.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/pb/deployPB"
+)
+
+const (
+	defaultRegion       = "us-east-1"
+	pageSize            = 100
+	maxPages            = 100
+	maxResources        = 10000
+	defaultHTTPTimeout  = 30 * time.Second
+	defaultSyncTimeout  = 2 * time.Minute
+	defaultPollInterval = 2 * time.Second
+	// syncDeadlineReserve 是回读等待从操作剩余时间中预留的时间，用于回读 ACM 证书并返回带请求 ID 的结构化错误。
+	syncDeadlineReserve = 5 * time.Second
+	maxResponseBytes    = 8 << 20
+)
+
+var (
+	_ providers.ProviderHandler            = (*Provider)(nil)
+	_ providers.DeploymentResourceProvider = (*Provider)(nil)
+)
+
+// regionPattern 匹配 AWS 标准地域名称，例如 us-east-1、ap-southeast-1 和 cn-north-1。
+var regionPattern = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+
+// HTTPClient 是 AWS provider 使用的最小 HTTP 客户端接口。
+type HTTPClient interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// Options 提供测试可替换的 HTTP 客户端、控制面地址和回读轮询参数。
+type Options struct {
+	HTTPClient   HTTPClient    // HTTPClient 执行 AWS 控制面请求。
+	Endpoint     string        // Endpoint 覆盖全部服务的控制面地址，仅用于测试替身。
+	PollInterval time.Duration // PollInterval 是监听器证书回读轮询间隔。
+	SyncTimeout  time.Duration // SyncTimeout 是单次回读等待上限。
+}
+
+// Provider 保存 AWS 凭据、地域和控制面访问参数。
+type Provider struct {
+	accessKeyID       string        // accessKeyID 是 IAM Access Key ID。
+	secretAccessKey   string        // secretAccessKey 是 IAM Secret Access Key。
+	region            string        // region 是默认资源地域。
+	certificateRegion string        // certificateRegion 是上传证书时导入 ACM 的地域。
+	regions           []string      // regions 是参与 ALB/NLB 资源发现的地域集合。
+	endpoint          string        // endpoint 是测试替身地址，为空时按服务和地域拼接官方地址。
+	httpClient        HTTPClient    // httpClient 执行带上下文的 HTTP 请求。
+	pollInterval      time.Duration // pollInterval 控制监听器证书回读频率。
+	syncTimeout       time.Duration // syncTimeout 限制单次回读等待时间。
+}
+
+// New 使用配置的默认地域、证书地域和资源地域创建 AWS provider。
+func New(accessKeyID, secretAccessKey, region, certificateRegion string, regions []string) (*Provider, error) {
+	return NewWithOptions(accessKeyID, secretAccessKey, region, certificateRegion, regions, nil)
+}
+
+// NewWithOptions 创建支持注入 HTTP 客户端和控制面地址的 AWS provider。
+func NewWithOptions(accessKeyID, secretAccessKey, region, certificateRegion string, regions []string, options *Options) (*Provider, error) {
+	resolved := Options{}
+	if options != nil {
+		resolved = *options
+	}
+	if resolved.HTTPClient == nil {
+		resolved.HTTPClient = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	if resolved.PollInterval <= 0 {
+		resolved.PollInterval = defaultPollInterval
+	}
+	if resolved.SyncTimeout <= 0 {
+		resolved.SyncTimeout = defaultSyncTimeout
+	}
+	region = strings.ToLower(strings.TrimSpace(region))
+	if region == "" {
+		region = defaultRegion
+	}
+	certificateRegion = strings.ToLower(strings.TrimSpace(certificateRegion))
+	if certificateRegion == "" {
+		certificateRegion = region
+	}
+	resolvedRegions, err := normalizeRegions(region, regions)
+	if err != nil {
+		return nil, err
+	}
+	if !regionPattern.MatchString(certificateRegion) {
+		return nil, fmt.Errorf("AWS 证书地域格式无效: %s", certificateRegion)
+	}
+	return &Provider{
+		accessKeyID:       strings.TrimSpace(accessKeyID),
+		secretAccessKey:   strings.TrimSpace(secretAccessKey),
+		region:            region,
+		certificateRegion: certificateRegion,
+		regions:           resolvedRegions,
+		endpoint:          strings.TrimRight(strings.TrimSpace(resolved.Endpoint), "/"),
+		httpClient:        resolved.HTTPClient,
+		pollInterval:      resolved.PollInterval,
+		syncTimeout:       resolved.SyncTimeout,
+	}, nil
+}
+
+// normalizeRegions 校验并去重资源发现地域，未配置时只使用默认地域。
+func normalizeRegions(region string, regions []string) ([]string, error) {
+	if !regionPattern.MatchString(region) {
+		return nil, fmt.Errorf("AWS 地域格式无效: %s", region)
+	}
+	if len(regions) == 0 {
+		return []string{region}, nil
+	}
+	seen := make(map[string]struct{}, len(regions))
+	result := make([]string, 0, len(regions))
+	for _, item := range regions {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if !regionPattern.MatchString(item) {
+			return nil, fmt.Errorf("AWS 地域格式无效: %s", item)
+		}
+		if _, exists := seen[item]; exists {
+			continue
+		}
+		seen[item] = struct{}{}
+		result = append(result, item)
+	}
+	if len(result) == 0 {
+		return []string{region}, nil
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// TestConnection 验证凭据可以读取证书地域的 ACM 证书目录。
+func (p *Provider) TestConnection(ctx context.Context) (bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := p.validateCredentials(); err != nil {
+		return false, err
+	}
+	var output acmListCertificatesOutput
+	_, err := p.callACM(ctx, p.certificateRegion, "测试连接", "ListCertificates", acmListCertificatesInput{MaxItems: 1}, &output)
+	return err == nil, toDeploymentError("测试连接", err)
+}
+
+// UploadCertificate 将证书导入证书地域的 ACM，已有同域名导入证书时原地重新导入。
+func (p *Provider) UploadCertificate(ctx context.Context, certificate providers.CertificateMaterial) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := p.validateCredentials(); err != nil {
+		return err
+	}
+	if err := providers.ValidateCertificateMaterial(certificate, certificate.Domain, time.Now()); err != nil {
+		return providers.NewDeploymentError("AWS 上传证书校验失败", false, "", err)
+	}
+	_, _, err := p.ensureACMCertificate(ctx, p.certificateRegion, certificate, "")
+	return toDeploymentError("上传证书", err)
+}
+
+// DiscoverResources 实时发现 CloudFront 分发或 ALB/NLB 监听器证书槽位。
+func (p *Provider) DiscoverResources(ctx context.Context, deploymentType deployPB.DeploymentType) providers.ResourceCatalogResult {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if deploymentType != deployPB.DeploymentType_DEPLOYMENT_TYPE_CDN &&
+		deploymentType != deployPB.DeploymentType_DEPLOYMENT_TYPE_ALB &&
+		deploymentType != deployPB.DeploymentType_DEPLOYMENT_TYPE_NLB {
+		return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE}
+	}
+	if err := p.validateCredentials(); err != nil {
+		return providers.ResourceCatalogResult{Status: deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED, Error: err}
+	}
+	var resources []providers.DeploymentResource
+	var partial bool
+	var err error
+	if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_CDN {
+		resources, partial, err = p.discoverCloudFrontResources(ctx, deploymentType)
+	} else {
+		resources, partial, err = p.discoverLoadBalancerResources(ctx, deploymentType)
+	}
+	if err != nil {
+		status := deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE
+		if isPermissionDenied(err) {
+			status = deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_PERMISSION_DENIED
+		}
+		if len(resources) > 0 {
+			status = deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_PARTIAL
+		}
+		return providers.ResourceCatalogResult{Resources: resources, Status: status, Error: err}
+	}
+	status := deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_READY
+	if partial {
+		status = deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_PARTIAL
+	} else if len(resources) == 0 {
+		status = deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_EMPTY
+	}
+	return providers.ResourceCatalogResult{Resources: resources, Status: status}
+}
+
+// ResolveResource 重新读取 AWS 资源目录并按 targetRef 唯一解析资源。
+func (p *Provider) ResolveResource(ctx context.Context, deploymentType deployPB.DeploymentType, targetRef string) (providers.DeploymentResource, error) {
+	catalog := p.DiscoverResources(ctx, deploymentType)
+	if catalog.Status == deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_UNAVAILABLE ||
+		catalog.Status == deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_NOT_CONFIGURED ||
+		catalog.Status == deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_PERMISSION_DENIED {
+		return providers.DeploymentResource{}, providers.NewDeploymentError("AWS 资源目录不可用", false, providers.RequestID(catalog.Error), catalog.Error)
+	}
+	return providers.FindResourceByTargetRef(catalog.Resources, targetRef)
+}
+
+// TestResource 确认 AWS 资源仍可部署。
+func (p *Provider) TestResource(ctx context.Context, deploymentType deployPB.DeploymentType, targetRef string) error {
+	resource, err := p.ResolveResource(ctx, deploymentType, targetRef)
+	if err != nil {
+		return err
+	}
+	if err := providers.EnsureResourceReady(resource); err != nil {
+		return providers.NewDeploymentError("AWS 资源当前不可部署", false, "", err)
+	}
+	return nil
+}
+
+// DeployCertificate 将证书导入 ACM 后绑定到一个精确的 AWS 资源并回读。
+func (p *Provider) DeployCertificate(ctx context.Context, certificate providers.CertificateMaterial, deploymentType deployPB.DeploymentType, resource providers.DeploymentResource) (providers.DeploymentResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := p.validateCredentials(); err != nil {
+		return providers.DeploymentResult{}, err
+	}
+	if strings.TrimSpace(resource.TargetRef) == "" || strings.TrimSpace(resource.Domain) == "" || strings.TrimSpace(resource.ResourceID) == "" {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 目标缺少 targetRef、域名或资源标识", false, "", nil)
+	}
+	targetDomains := resource.Domains
+	if len(targetDomains) == 0 {
+		targetDomains = []string{resource.Domain}
+	}
+	if err := providers.ValidateCertificateForDomains(certificate, targetDomains, time.Now()); err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 证书校验失败", false, "", err)
+	}
+	switch deploymentType {
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_CDN:
+		return p.deployCloudFront(ctx, certificate, resource)
+	case deployPB.DeploymentType_DEPLOYMENT_TYPE_ALB, deployPB.DeploymentType_DEPLOYMENT_TYPE_NLB:
+		return p.deployLoadBalancer(ctx, certificate, deploymentType, resource)
+	default:
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 不支持该部署业务", false, "", nil)
+	}
+}
+
+// validateCredentials 在发出任何请求前拒绝不完整的本地凭据。
+func (p *Provider) validateCredentials() error {
+	if p == nil || p.accessKeyID == "" || p.secretAccessKey == "" {
+		return providers.NewDeploymentError("AWS accessKeyId 或 accessKeySecret 未配置", false, "", nil)
+	}
+	if strings.ContainsAny(p.accessKeyID, "\r\n\x00/ ") {
+		return providers.NewDeploymentError("AWS accessKeyId 格式无效", false, "", nil)
+	}
+	return nil
+}
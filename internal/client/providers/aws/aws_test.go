@@ -0,0 +1,403 @@
+package aws
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/pb/deployPB"
+)
+
+const (
+	testLoadBalancerARN = "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/web/50dc6c495c0c9188"
+	testListenerARN     = "arn:aws:elasticloadbalancing:us-east-1:123456789012:listener/app/web/50dc6c495c0c9188/f2f7dc8efc522ab2"
+)
+
+// fakeACMCertificate 是 fake ACM 中的一张导入证书。
+type fakeACMCertificate struct {
+	domains        []string // domains 是证书主域名和 SAN。
+	certificatePEM string   // certificatePEM 是当前导入的叶证书。
+	amazonIssued   bool     // amazonIssued 表示由 ACM 签发，不能重新导入。
+}
+
+// fakeListenerCertificate 是 fake 监听器证书列表中的一项。
+type fakeListenerCertificate struct {
+	arn       string // arn 是证书 ARN。
+	isDefault bool   // isDefault 表示默认证书。
+}
+
+// fakeAWS 实现 ACM、ELBv2 和 CloudFront 的最小离线控制面。
+type fakeAWS struct {
+	mu                   sync.Mutex
+	certificates         map[string]*fakeACMCertificate // certificates 按 ARN 保存 ACM 证书。
+	listenerCertificates []fakeListenerCertificate      // listenerCertificates 是唯一监听器的证书列表。
+	distributionConfig   string                         // distributionConfig 是 CloudFront 分发配置 XML。
+	etag                 string                         // etag 是分发配置版本。
+	imports              []string                       // imports 记录 ImportCertificate 使用的 ARN，新建时为空字符串。
+	actions              []string                       // actions 记录 ELB 写操作。
+	unsigned             int                            // unsigned 记录缺少 SigV4 头的请求数。
+}
+
+// ServeHTTP 按 X-Amz-Target、Action 表单参数或 CloudFront 路径分派请求。
+func (f *fakeAWS) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasPrefix(request.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || request.Header.Get("X-Amz-Date") == "" {
+		f.unsigned++
+	}
+	writer.Header().Set("X-Amzn-Requestid", "aws-request")
+	body, _ := io.ReadAll(request.Body)
+	if target := request.Header.Get("X-Amz-Target"); target != "" {
+		f.serveACM(writer, strings.TrimPrefix(target, "CertificateManager."), body)
+		return
+	}
+	if strings.HasPrefix(request.URL.Path, "/2020-05-31/") {
+		f.serveCloudFront(writer, request, body)
+		return
+	}
+	form, _ := url.ParseQuery(string(body))
+	f.serveELB(writer, form)
+}
+
+// serveACM 处理 ACM JSON 1.1 请求。
+func (f *fakeAWS) serveACM(writer http.ResponseWriter, action string, body []byte) {
+	var input struct {
+		CertificateArn string
+		Certificate    []byte
+	}
+	_ = json.Unmarshal(body, &input)
+	switch action {
+	case "ListCertificates":
+		summaries := make([]map[string]any, 0, len(f.certificates))
+		for arn, certificate := range f.certificates {
+			summaries = append(summaries, map[string]any{"CertificateArn": arn, "DomainName": certificate.domains[0], "SubjectAlternativeNameSummaries": certificate.domains, "Type": certificate.certificateType(), "Status": "ISSUED"})
+		}
+		_ = json.NewEncoder(writer).Encode(map[string]any{"CertificateSummaryList": summaries})
+	case "DescribeCertificate":
+		certificate, exists := f.certificates[input.CertificateArn]
+		if !exists {
+			writer.WriteHeader(http.StatusBadRequest)
+			_, _ = writer.Write([]byte(`{"__type":"ResourceNotFoundException"}`))
+			return
+		}
+		_ = json.NewEncoder(writer).Encode(map[string]any{"Certificate": map[string]any{"CertificateArn": input.CertificateArn, "DomainName": certificate.domains[0], "SubjectAlternativeNames": certificate.domains, "Type": certificate.certificateType(), "Status": "ISSUED"}})
+	case "GetCertificate":
+		_ = json.NewEncoder(writer).Encode(map[string]string{"Certificate": f.certificates[input.CertificateArn].certificatePEM})
+	case "ImportCertificate":
+		f.imports = append(f.imports, input.CertificateArn)
+		arn := input.CertificateArn
+		if arn == "" {
+			arn = fmt.Sprintf("arn:aws:acm:us-east-1:123456789012:certificate/new-%d", len(f.imports))
+		}
+		block, _ := pem.Decode(input.Certificate)
+		leaf, _ := x509.ParseCertificate(block.Bytes)
+		f.certificates[arn] = &fakeACMCertificate{domains: leaf.DNSNames, certificatePEM: string(input.Certificate)}
+		_ = json.NewEncoder(writer).Encode(map[string]string{"CertificateArn": arn})
+	default:
+		writer.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// certificateType 返回 ACM 证书来源。
+func (c *fakeACMCertificate) certificateType() string {
+	if c.amazonIssued {
+		return "AMAZON_ISSUED"
+	}
+	return "IMPORTED"
+}
+
+// serveELB 处理 ELBv2 Query 协议请求。
+func (f *fakeAWS) serveELB(writer http.ResponseWriter, form url.Values) {
+	action := form.Get("Action")
+	switch action {
+	case "DescribeLoadBalancers":
+		writeELB(writer, action, `<LoadBalancers><member><LoadBalancerArn>`+testLoadBalancerARN+`</LoadBalancerArn><LoadBalancerName>web</LoadBalancerName><Type>application</Type><State><Code>active</Code></State><CreatedTime>2026-01-02T03:04:05.000Z</CreatedTime></member></LoadBalancers>`)
+	case "DescribeListeners":
+		listeners := `<member><ListenerArn>` + testListenerARN + `</ListenerArn><LoadBalancerArn>` + testLoadBalancerARN + `</LoadBalancerArn><Port>443</Port><Protocol>HTTPS</Protocol></member>`
+		if form.Get("ListenerArns.member.1") == "" {
+			listeners += `<member><ListenerArn>http</ListenerArn><LoadBalancerArn>` + testLoadBalancerARN + `</LoadBalancerArn><Port>80</Port><Protocol>HTTP</Protocol></member>`
+		}
+		writeELB(writer, action, `<Listeners>`+listeners+`</Listeners>`)
+	case "DescribeListenerCertificates":
+		var members strings.Builder
+		for _, certificate := range f.listenerCertificates {
+			fmt.Fprintf(&members, `<member><CertificateArn>%s</CertificateArn><IsDefault>%t</IsDefault></member>`, certificate.arn, certificate.isDefault)
+		}
+		writeELB(writer, action, `<Certificates>`+members.String()+`</Certificates>`)
+	case "ModifyListener":
+		f.actions = append(f.actions, action)
+		for index := range f.listenerCertificates {
+			if f.listenerCertificates[index].isDefault {
+				f.listenerCertificates[index].arn = form.Get("Certificates.member.1.CertificateArn")
+			}
+		}
+		writeELB(writer, action, "")
+	case "AddListenerCertificates":
+		f.actions = append(f.actions, action)
+		f.listenerCertificates = append(f.listenerCertificates, fakeListenerCertificate{arn: form.Get("Certificates.member.1.CertificateArn")})
+		writeELB(writer, action, "")
+	case "RemoveListenerCertificates":
+		f.actions = append(f.actions, action)
+		kept := f.listenerCertificates[:0]
+		for _, certificate := range f.listenerCertificates {
+			if certificate.arn != form.Get("Certificates.member.1.CertificateArn") {
+				kept = append(kept, certificate)
+			}
+		}
+		f.listenerCertificates = kept
+		writeELB(writer, action, "")
+	default:
+		writer.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// serveCloudFront 处理分发列表和分发配置读写。
+func (f *fakeAWS) serveCloudFront(writer http.ResponseWriter, request *http.Request, body []byte) {
+	switch {
+	case request.Method == http.MethodGet && request.URL.Path == "/2020-05-31/distribution":
+		_, _ = writer.Write([]byte(`<DistributionList><IsTruncated>false</IsTruncated><Items><DistributionSummary><Id>E1EXAMPLE</Id><DomainName>d111111abcdef8.cloudfront.net</DomainName><Status>Deployed</Status><Enabled>true</Enabled><Aliases><Quantity>1</Quantity><Items><CNAME>cdn.example.com</CNAME></Items></Aliases></DistributionSummary><DistributionSummary><Id>E2NOALIAS</Id><Status>Deployed</Status><Aliases><Quantity>0</Quantity></Aliases></DistributionSummary></Items></DistributionList>`))
+	case request.Method == http.MethodGet && request.URL.Path == "/2020-05-31/distribution/E1EXAMPLE/config":
+		writer.Header().Set("ETag", f.etag)
+		_, _ = writer.Write([]byte(f.distributionConfig))
+	case request.Method == http.MethodPut && request.URL.Path == "/2020-05-31/distribution/E1EXAMPLE/config":
+		if request.Header.Get("If-Match") != f.etag {
+			writer.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = writer.Write([]byte(`<ErrorResponse><Error><Code>PreconditionFailed</Code></Error><RequestId>cf-conflict</RequestId></ErrorResponse>`))
+			return
+		}
+		f.distributionConfig = string(body)
+		f.etag += "-next"
+		_, _ = writer.Write(body)
+	default:
+		http.NotFound(writer, request)
+	}
+}
+
+// TestUploadCertificateReimportsExistingARN 验证同域名导入证书原地重新导入，相同证书不重复导入。
+func TestUploadCertificateReimportsExistingARN(t *testing.T) {
+	oldCertificatePEM, _ := generateAWSCertificate(t, "api.example.com")
+	certificate := generateAWSMaterial(t, "api.example.com")
+	existingARN := "arn:aws:acm:us-east-1:123456789012:certificate/existing"
+	fake := &fakeAWS{certificates: map[string]*fakeACMCertificate{
+		existingARN: {domains: []string{"api.example.com"}, certificatePEM: oldCertificatePEM},
+	}}
+	provider := newAWSTestProvider(t, fake)
+
+	if err := provider.UploadCertificate(context.Background(), certificate); err != nil {
+		t.Fatalf("UploadCertificate() error = %v", err)
+	}
+	if err := provider.UploadCertificate(context.Background(), certificate); err != nil {
+		t.Fatalf("second UploadCertificate() error = %v", err)
+	}
+	if len(fake.imports) != 1 || fake.imports[0] != existingARN || len(fake.certificates) != 1 || fake.unsigned != 0 {
+		t.Fatalf("imports=%v certificates=%d unsigned=%d", fake.imports, len(fake.certificates), fake.unsigned)
+	}
+}
+
+// TestDiscoverAndDeployALBSNICertificate 验证 ALB 证书槽位发现、SNI 证书替换和回读。
+func TestDiscoverAndDeployALBSNICertificate(t *testing.T) {
+	defaultPEM, _ := generateAWSCertificate(t, "www.example.com")
+	sniPEM, _ := generateAWSCertificate(t, "api.example.com")
+	defaultARN := "arn:aws:acm:us-east-1:123456789012:certificate/default"
+	sniARN := "arn:aws:acm:us-east-1:123456789012:certificate/sni"
+	fake := &fakeAWS{
+		certificates: map[string]*fakeACMCertificate{
+			defaultARN: {domains: []string{"www.example.com"}, certificatePEM: defaultPEM},
+			sniARN:     {domains: []string{"api.example.com"}, certificatePEM: sniPEM, amazonIssued: true},
+		},
+		listenerCertificates: []fakeListenerCertificate{
+			{arn: defaultARN, isDefault: true},
+			{arn: sniARN},
+			{arn: "arn:aws:iam::123456789012:server-certificate/legacy"},
+		},
+	}
+	provider := newAWSTestProvider(t, fake)
+
+	catalog := provider.DiscoverResources(context.Background(), deployPB.DeploymentType_DEPLOYMENT_TYPE_ALB)
+	if catalog.Status != deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_PARTIAL || catalog.Error != nil || len(catalog.Resources) != 2 {
+		t.Fatalf("catalog = %#v", catalog)
+	}
+	var target providers.DeploymentResource
+	for _, resource := range catalog.Resources {
+		if resource.ResourceID == elbSlotSNI {
+			target = resource
+		}
+	}
+	if target.Domain != "api.example.com" || target.ListenerPort != 443 || target.ListenerID != testListenerARN || target.Group != "web" {
+		t.Fatalf("sni resource = %#v", target)
+	}
+	resolved, err := provider.ResolveResource(context.Background(), deployPB.DeploymentType_DEPLOYMENT_TYPE_ALB, target.TargetRef)
+	if err != nil || resolved.TargetRef != target.TargetRef {
+		t.Fatalf("ResolveResource() = %#v, %v", resolved, err)
+	}
+
+	// SNI 证书域名已变化时槽位无法唯一确认，部署必须拒绝。
+	certificate := generateAWSMaterial(t, "api.example.com")
+	fake.certificates[sniARN].domains = []string{"other.example.com"}
+	if result, err := provider.DeployCertificate(context.Background(), certificate, deployPB.DeploymentType_DEPLOYMENT_TYPE_ALB, target); err == nil {
+		t.Fatalf("DeployCertificate() with stale slot = %#v", result)
+	}
+	fake.certificates[sniARN].domains = []string{"api.example.com"}
+
+	// ACM 签发的旧证书不能重新导入，新建导入后先添加新 SNI 证书再移除旧证书。
+	result, err := provider.DeployCertificate(context.Background(), certificate, deployPB.DeploymentType_DEPLOYMENT_TYPE_ALB, target)
+	if err != nil {
+		t.Fatalf("DeployCertificate() error = %v", err)
+	}
+	if result.RequestID != "aws-request" || len(fake.imports) != 1 || fake.imports[0] != "" ||
+		strings.Join(fake.actions, ",") != "AddListenerCertificates,RemoveListenerCertificates" {
+		t.Fatalf("result=%#v imports=%v actions=%v", result, fake.imports, fake.actions)
+	}
+	for _, listenerCertificate := range fake.listenerCertificates {
+		if listenerCertificate.arn == sniARN {
+			t.Fatalf("old SNI certificate still bound: %#v", fake.listenerCertificates)
+		}
+	}
+	fake.actions = nil
+
+	// 默认证书由 IAM 迁移到 ACM 时新建导入并通过 ModifyListener 替换。
+	fake.listenerCertificates[0].arn = "arn:aws:iam::123456789012:server-certificate/www"
+	defaultTarget := target
+	defaultTarget.ResourceID = elbSlotDefault
+	defaultTarget.Domain = "www.example.com"
+	defaultTarget.Domains = []string{"www.example.com"}
+	delete(fake.certificates, defaultARN)
+	result, err = provider.DeployCertificate(context.Background(), generateAWSMaterial(t, "www.example.com"), deployPB.DeploymentType_DEPLOYMENT_TYPE_ALB, defaultTarget)
+	if err != nil {
+		t.Fatalf("default DeployCertificate() error = %v", err)
+	}
+	if len(fake.actions) != 1 || fake.actions[0] != "ModifyListener" || !strings.HasSuffix(fake.listenerCertificates[0].arn, "/new-2") || !strings.Contains(result.Message, "默认证书") {
+		t.Fatalf("result=%#v actions=%v listener=%#v", result, fake.actions, fake.listenerCertificates)
+	}
+	if fake.unsigned != 0 {
+		t.Fatalf("unsigned requests = %d", fake.unsigned)
+	}
+}
+
+// TestWaitListenerCertificateSlotRespectsOperationDeadline 验证监听器回读等待受操作截止时间约束，并为返回结构化错误预留时间。
+func TestWaitListenerCertificateSlotRespectsOperationDeadline(t *testing.T) {
+	fake := &fakeAWS{listenerCertificates: []fakeListenerCertificate{{arn: "arn:aws:acm:us-east-1:123456789012:certificate/old", isDefault: true}}}
+	provider := newAWSTestProvider(t, fake)
+	provider.syncTimeout = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncDeadlineReserve+200*time.Millisecond)
+	defer cancel()
+	startedAt := time.Now()
+	_, err := provider.waitListenerCertificateSlot(ctx, "us-east-1", testListenerARN, "arn:aws:acm:us-east-1:123456789012:certificate/new", true, "")
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(startedAt) >= syncDeadlineReserve {
+		t.Fatalf("回读等待应在预留时间之前结束: elapsed=%s err=%v", time.Since(startedAt), err)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("操作 context 不应在回读等待结束时已超时")
+	}
+}
+
+// TestDiscoverAndDeployCloudFrontViewerCertificate 验证分发发现、ETag 条件更新和查看器证书回读。
+func TestDiscoverAndDeployCloudFrontViewerCertificate(t *testing.T) {
+	fake := &fakeAWS{
+		certificates: map[string]*fakeACMCertificate{},
+		etag:         "E2QWRUHEXAMPLE",
+		distributionConfig: `<?xml version="1.0" encoding="UTF-8"?>
+<DistributionConfig xmlns="http://cloudfront.amazonaws.com/doc/2020-05-31/"><CallerReference>ref</CallerReference><Aliases><Quantity>1</Quantity><Items><CNAME>cdn.example.com</CNAME></Items></Aliases><Comment>keep</Comment><ViewerCertificate><CloudFrontDefaultCertificate>true</CloudFrontDefaultCertificate><MinimumProtocolVersion>TLSv1</MinimumProtocolVersion><CertificateSource>cloudfront</CertificateSource></ViewerCertificate><HttpVersion>http2</HttpVersion></DistributionConfig>`,
+	}
+	provider := newAWSTestProvider(t, fake)
+
+	catalog := provider.DiscoverResources(context.Background(), deployPB.DeploymentType_DEPLOYMENT_TYPE_CDN)
+	if catalog.Status != deployPB.DeploymentResourceStatus_DEPLOYMENT_RESOURCE_STATUS_READY || len(catalog.Resources) != 1 {
+		t.Fatalf("catalog = %#v", catalog)
+	}
+	target := catalog.Resources[0]
+	if target.ResourceID != "E1EXAMPLE" || target.Domain != "cdn.example.com" || target.Group != "d111111abcdef8.cloudfront.net" {
+		t.Fatalf("resource = %#v", target)
+	}
+
+	certificate := generateAWSMaterial(t, "cdn.example.com")
+	result, err := provider.DeployCertificate(context.Background(), certificate, deployPB.DeploymentType_DEPLOYMENT_TYPE_CDN, target)
+	if err != nil {
+		t.Fatalf("DeployCertificate() error = %v", err)
+	}
+	config := fake.distributionConfig
+	if !strings.Contains(config, "<ACMCertificateArn>arn:aws:acm:us-east-1:123456789012:certificate/new-1</ACMCertificateArn>") ||
+		!strings.Contains(config, "<SSLSupportMethod>sni-only</SSLSupportMethod>") ||
+		!strings.Contains(config, "<MinimumProtocolVersion>TLSv1.2_2021</MinimumProtocolVersion>") ||
+		!strings.Contains(config, "<Comment>keep</Comment>") || !strings.Contains(config, "<HttpVersion>http2</HttpVersion>") {
+		t.Fatalf("updated config = %s", config)
+	}
+	if !strings.Contains(result.Message, "部署成功") {
+		t.Fatalf("result = %#v", result)
+	}
+
+	result, err = provider.DeployCertificate(context.Background(), certificate, deployPB.DeploymentType_DEPLOYMENT_TYPE_CDN, target)
+	if err != nil || !strings.Contains(result.Message, "已配置当前证书") || len(fake.imports) != 1 {
+		t.Fatalf("repeat DeployCertificate() = %#v, %v imports=%v", result, err, fake.imports)
+	}
+}
+
+// newAWSTestProvider 创建连接 httptest 替身的 AWS provider。
+func newAWSTestProvider(t *testing.T, fake *fakeAWS) *Provider {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	provider, err := NewWithOptions("AKIDEXAMPLE", "secret", "us-east-1", "", nil, &Options{HTTPClient: server.Client(), Endpoint: server.URL, PollInterval: time.Millisecond, SyncTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return provider
+}
+
+// writeELB 写入 ELBv2 Query 协议成功响应。
+func writeELB(writer http.ResponseWriter, action, result string) {
+	fmt.Fprintf(writer, `<%sResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/"><%sResult>%s</%sResult><ResponseMetadata><RequestId>elb-request</RequestId></ResponseMetadata></%sResponse>`, action, action, result, action, action)
+}
+
+// generateAWSMaterial 生成部署测试使用的证书材料。
+func generateAWSMaterial(t *testing.T, domain string) providers.CertificateMaterial {
+	t.Helper()
+	certificatePEM, privateKeyPEM := generateAWSCertificate(t, domain)
+	return providers.CertificateMaterial{Name: "certificate", Domain: domain, CertificatePEM: certificatePEM, PrivateKeyPEM: privateKeyPEM}
+}
+
+// generateAWSCertificate 生成离线测试使用的自签名证书和匹配私钥。
+func generateAWSCertificate(t *testing.T, domain string) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate private key: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("generate serial: %v", err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return string(certificatePEM), string(privateKeyPEM)
+}
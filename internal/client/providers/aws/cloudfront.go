@@ -0,0 +1,245 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/pb/deployPB"
+)
+
+const (
+	cloudFrontAPIVersion      = "2020-05-31"
+	cloudFrontRegion          = "us-east-1"
+	cloudFrontResourceRegion  = "global"
+	cloudFrontStatusDeployed  = "Deployed"
+	cloudFrontDefaultSSL      = "sni-only"
+	cloudFrontDefaultProtocol = "TLSv1.2_2021"
+)
+
+// cloudFrontDistributionList 是 ListDistributions 响应中用于资源发现的字段。
+type cloudFrontDistributionList struct {
+	IsTruncated bool                            `xml:"IsTruncated"`               // IsTruncated 表示仍有下一页。
+	NextMarker  string                          `xml:"NextMarker"`                // NextMarker 是下一页游标。
+	Items       []cloudFrontDistributionSummary `xml:"Items>DistributionSummary"` // Items 是当前页分发摘要。
+}
+
+// cloudFrontDistributionSummary 保存分发身份、别名和当前查看器证书。
+type cloudFrontDistributionSummary struct {
+	ID                string                      `xml:"Id"`                  // ID 是分发 ID。
+	DomainName        string                      `xml:"DomainName"`          // DomainName 是 cloudfront.net 默认域名。
+	Status            string                      `xml:"Status"`              // Status 是 Deployed 或 InProgress。
+	Enabled           bool                        `xml:"Enabled"`             // Enabled 表示分发是否启用。
+	Aliases           []string                    `xml:"Aliases>Items>CNAME"` // Aliases 是分发绑定的备用域名。
+	ViewerCertificate cloudFrontViewerCertificate `xml:"ViewerCertificate"`   // ViewerCertificate 是当前查看器证书配置。
+}
+
+// cloudFrontDistributionConfig 是 GetDistributionConfig 响应中部署需要校验的字段。
+type cloudFrontDistributionConfig struct {
+	Aliases           []string                    `xml:"Aliases>Items>CNAME"` // Aliases 是分发绑定的备用域名。
+	ViewerCertificate cloudFrontViewerCertificate `xml:"ViewerCertificate"`   // ViewerCertificate 是当前查看器证书配置。
+}
+
+// cloudFrontViewerCertificate 是 CloudFront 查看器证书配置，字段顺序与官方 XML Schema 一致。
+type cloudFrontViewerCertificate struct {
+	XMLName                      xml.Name `xml:"ViewerCertificate"`
+	CloudFrontDefaultCertificate bool     `xml:"CloudFrontDefaultCertificate"`     // CloudFrontDefaultCertificate 表示使用 *.cloudfront.net 默认证书。
+	IAMCertificateID             string   `xml:"IAMCertificateId,omitempty"`       // IAMCertificateID 是 IAM 服务器证书 ID。
+	ACMCertificateArn            string   `xml:"ACMCertificateArn,omitempty"`      // ACMCertificateArn 是 us-east-1 ACM 证书 ARN。
+	SSLSupportMethod             string   `xml:"SSLSupportMethod,omitempty"`       // SSLSupportMethod 是 sni-only、vip 或 static-ip。
+	MinimumProtocolVersion       string   `xml:"MinimumProtocolVersion,omitempty"` // MinimumProtocolVersion 是最低 TLS 协议策略。
+}
+
+// discoverCloudFrontResources 分页读取全部绑定了备用域名的 CloudFront 分发。
+func (p *Provider) discoverCloudFrontResources(ctx context.Context, deploymentType deployPB.DeploymentType) ([]providers.DeploymentResource, bool, error) {
+	resources := make([]providers.DeploymentResource, 0)
+	partial := false
+	marker := ""
+	for page := 0; page < maxPages; page++ {
+		query := url.Values{"MaxItems": {strconv.Itoa(pageSize)}}
+		if marker != "" {
+			query.Set("Marker", marker)
+		}
+		response, err := p.callCloudFront(ctx, "读取 CloudFront 分发列表", http.MethodGet, "/"+cloudFrontAPIVersion+"/distribution", query, nil, nil)
+		if err != nil {
+			if len(resources) > 0 {
+				return resources, true, nil
+			}
+			return nil, false, err
+		}
+		var list cloudFrontDistributionList
+		if err := xml.Unmarshal(response.Body, &list); err != nil {
+			return resources, true, &apiError{Operation: "解析 CloudFront 分发列表", RequestID: response.RequestID, Retryable: true, Cause: err}
+		}
+		for _, summary := range list.Items {
+			resource, ok := buildCloudFrontResource(deploymentType, summary)
+			if !ok {
+				continue
+			}
+			resources = append(resources, resource)
+		}
+		if len(resources) > maxResources {
+			return resources, true, providers.NewDeploymentError("AWS CloudFront 资源数量超过安全上限", false, response.RequestID, nil)
+		}
+		marker = strings.TrimSpace(list.NextMarker)
+		if !list.IsTruncated || marker == "" {
+			sort.Slice(resources, func(left, right int) bool {
+				return resources[left].Label < resources[right].Label
+			})
+			return resources, partial, nil
+		}
+	}
+	return resources, true, providers.NewDeploymentError("AWS CloudFront 分发目录超过安全分页上限", false, "", nil)
+}
+
+// buildCloudFrontResource 将带备用域名的分发转换为稳定资源引用；没有备用域名的分发无法使用自定义证书。
+func buildCloudFrontResource(deploymentType deployPB.DeploymentType, summary cloudFrontDistributionSummary) (providers.DeploymentResource, bool) {
+	distributionID := strings.TrimSpace(summary.ID)
+	domains := providers.NormalizeDomains(summary.Aliases...)
+	if distributionID == "" || len(domains) == 0 {
+		return providers.DeploymentResource{}, false
+	}
+	availability := deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_STOPPED
+	if strings.EqualFold(summary.Status, cloudFrontStatusDeployed) {
+		availability = deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY
+	}
+	return providers.DeploymentResource{
+		TargetRef:    providers.BuildTargetRef("aws", deploymentType, cloudFrontResourceRegion, distributionID),
+		Label:        fmt.Sprintf("%s (%s)", distributionID, strings.Join(domains, ", ")),
+		Domain:       domains[0],
+		Domains:      domains,
+		Group:        firstNonEmpty(summary.DomainName, distributionID),
+		Region:       cloudFrontResourceRegion,
+		Protocol:     "HTTPS",
+		Status:       summary.Status,
+		Availability: availability,
+		ResourceID:   distributionID,
+	}, true
+}
+
+// deployCloudFront 把 us-east-1 ACM 证书绑定到分发查看器证书，并回读分发配置和 ACM 指纹。
+func (p *Provider) deployCloudFront(ctx context.Context, certificate providers.CertificateMaterial, resource providers.DeploymentResource) (providers.DeploymentResult, error) {
+	distributionID := resource.ResourceID
+	body, etag, requestID, err := p.getCloudFrontDistributionConfig(ctx, distributionID)
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("读取 CloudFront 分发配置", err)
+	}
+	config, err := parseCloudFrontDistributionConfig(body)
+	if err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS CloudFront 分发配置解析失败", true, requestID, err)
+	}
+	if !sameDomains(providers.NormalizeDomains(config.Aliases...), providers.NormalizeDomains(resource.Domains...)) {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS CloudFront 分发备用域名已变化，请重新关联资源", false, requestID, nil)
+	}
+
+	currentARN := ""
+	if !config.ViewerCertificate.CloudFrontDefaultCertificate {
+		currentARN = config.ViewerCertificate.ACMCertificateArn
+	}
+	certificateARN, importRequestID, err := p.ensureACMCertificate(ctx, cloudFrontRegion, certificate, currentARN)
+	requestID = firstNonEmpty(importRequestID, requestID)
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("准备 CloudFront 证书", err)
+	}
+	if currentARN != "" && certificateARN == currentARN {
+		return providers.DeploymentResult{RequestID: requestID, Message: "AWS CloudFront 分发已配置当前证书"}, nil
+	}
+
+	updated, err := replaceCloudFrontViewerCertificate(body, config.ViewerCertificate, certificateARN)
+	if err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS CloudFront 分发配置无法安全更新", false, requestID, err)
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	header.Set("If-Match", etag)
+	written, err := p.callCloudFront(ctx, "更新 CloudFront 分发证书", http.MethodPut, cloudFrontConfigPath(distributionID), nil, header, updated)
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("更新 CloudFront 分发证书", err)
+	}
+	requestID = firstNonEmpty(written.RequestID, requestID)
+
+	readback, _, readbackRequestID, err := p.getCloudFrontDistributionConfig(ctx, distributionID)
+	requestID = firstNonEmpty(readbackRequestID, requestID)
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("回读 CloudFront 分发配置", err)
+	}
+	readbackConfig, err := parseCloudFrontDistributionConfig(readback)
+	if err != nil || readbackConfig.ViewerCertificate.CloudFrontDefaultCertificate || readbackConfig.ViewerCertificate.ACMCertificateArn != certificateARN {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS CloudFront 分发证书尚未生效", true, requestID, err)
+	}
+	fingerprintRequestID, err := p.verifyACMCertificate(ctx, cloudFrontRegion, certificateARN, certificate.CertificatePEM)
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("回读 ACM 证书", err)
+	}
+	return providers.DeploymentResult{RequestID: firstNonEmpty(fingerprintRequestID, requestID), Message: "AWS CloudFront 分发证书部署成功"}, nil
+}
+
+// getCloudFrontDistributionConfig 读取分发原始配置 XML 和用于乐观锁的 ETag。
+func (p *Provider) getCloudFrontDistributionConfig(ctx context.Context, distributionID string) ([]byte, string, string, error) {
+	response, err := p.callCloudFront(ctx, "读取 CloudFront 分发配置", http.MethodGet, cloudFrontConfigPath(distributionID), nil, nil, nil)
+	if err != nil {
+		return nil, "", "", err
+	}
+	etag := strings.TrimSpace(response.Header.Get("ETag"))
+	if etag == "" {
+		return nil, "", response.RequestID, providers.NewDeploymentError("AWS CloudFront 分发配置响应缺少 ETag", true, response.RequestID, nil)
+	}
+	return response.Body, etag, response.RequestID, nil
+}
+
+// parseCloudFrontDistributionConfig 解析分发配置中的备用域名和查看器证书。
+func parseCloudFrontDistributionConfig(body []byte) (cloudFrontDistributionConfig, error) {
+	var config cloudFrontDistributionConfig
+	if err := xml.Unmarshal(body, &config); err != nil {
+		return cloudFrontDistributionConfig{}, err
+	}
+	return config, nil
+}
+
+// replaceCloudFrontViewerCertificate 只替换原始配置中的 ViewerCertificate 元素，保留其余未知字段原样提交。
+func replaceCloudFrontViewerCertificate(body []byte, current cloudFrontViewerCertificate, certificateARN string) ([]byte, error) {
+	const openTag, closeTag = "<ViewerCertificate>", "</ViewerCertificate>"
+	if bytes.Count(body, []byte(openTag)) != 1 || bytes.Count(body, []byte(closeTag)) != 1 {
+		return nil, fmt.Errorf("分发配置中的 ViewerCertificate 元素不唯一")
+	}
+	start := bytes.Index(body, []byte(openTag))
+	end := bytes.Index(body, []byte(closeTag)) + len(closeTag)
+	if end <= start {
+		return nil, fmt.Errorf("分发配置中的 ViewerCertificate 元素无效")
+	}
+	sslSupportMethod := current.SSLSupportMethod
+	if sslSupportMethod != "sni-only" && sslSupportMethod != "vip" && sslSupportMethod != "static-ip" {
+		sslSupportMethod = cloudFrontDefaultSSL
+	}
+	minimumProtocolVersion := current.MinimumProtocolVersion
+	// 默认证书固定使用 TLSv1 策略，切换到自定义证书时改用当前推荐策略。
+	if current.CloudFrontDefaultCertificate || minimumProtocolVersion == "" {
+		minimumProtocolVersion = cloudFrontDefaultProtocol
+	}
+	element, err := xml.Marshal(cloudFrontViewerCertificate{
+		CloudFrontDefaultCertificate: false,
+		ACMCertificateArn:            certificateARN,
+		SSLSupportMethod:             sslSupportMethod,
+		MinimumProtocolVersion:       minimumProtocolVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	updated := make([]byte, 0, len(body)+len(element))
+	updated = append(updated, body[:start]...)
+	updated = append(updated, element...)
+	updated = append(updated, body[end:]...)
+	return updated, nil
+}
+
+// cloudFrontConfigPath 返回分发配置接口路径。
+func cloudFrontConfigPath(distributionID string) string {
+	return "/" + cloudFrontAPIVersion + "/distribution/" + url.PathEscape(distributionID) + "/config"
+}
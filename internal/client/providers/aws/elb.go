@@ -0,0 +1,456 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/https-cert/deploy/internal/client/providers"
+	"github.com/https-cert/deploy/pb/deployPB"
+)
+
+const (
+	elbSlotDefault        = "default"
+	elbSlotSNI            = "sni"
+	elbStateActive        = "active"
+	elbStateProvisioning  = "provisioning"
+	elbTypeApplication    = "application"
+	elbTypeNetwork        = "network"
+	elbCertificatePageMax = 400
+)
+
+// elbLoadBalancer 保存负载均衡实例身份和状态。
+type elbLoadBalancer struct {
+	LoadBalancerArn  string `xml:"LoadBalancerArn"`  // LoadBalancerArn 是实例 ARN。
+	LoadBalancerName string `xml:"LoadBalancerName"` // LoadBalancerName 是实例名称。
+	Type             string `xml:"Type"`             // Type 是 application、network 或 gateway。
+	State            string `xml:"State>Code"`       // State 是 active、provisioning 等实例状态。
+	CreatedTime      string `xml:"CreatedTime"`      // CreatedTime 是 ISO 8601 创建时间。
+}
+
+// elbDescribeLoadBalancersOutput 是 DescribeLoadBalancers 响应。
+type elbDescribeLoadBalancersOutput struct {
+	LoadBalancers []elbLoadBalancer `xml:"DescribeLoadBalancersResult>LoadBalancers>member"` // LoadBalancers 是当前页实例。
+	NextMarker    string            `xml:"DescribeLoadBalancersResult>NextMarker"`           // NextMarker 为空表示最后一页。
+}
+
+// elbListener 保存监听器身份、端口和协议。
+type elbListener struct {
+	ListenerArn     string `xml:"ListenerArn"`     // ListenerArn 是监听器 ARN。
+	LoadBalancerArn string `xml:"LoadBalancerArn"` // LoadBalancerArn 是所属实例 ARN。
+	Port            int    `xml:"Port"`            // Port 是监听端口。
+	Protocol        string `xml:"Protocol"`        // Protocol 是 HTTPS、TLS 等监听协议。
+}
+
+// elbDescribeListenersOutput 是 DescribeListeners 响应。
+type elbDescribeListenersOutput struct {
+	Listeners  []elbListener `xml:"DescribeListenersResult>Listeners>member"` // Listeners 是当前页监听器。
+	NextMarker string        `xml:"DescribeListenersResult>NextMarker"`       // NextMarker 为空表示最后一页。
+}
+
+// elbListenerCertificate 保存监听器证书列表中的一个证书。
+type elbListenerCertificate struct {
+	CertificateArn string `xml:"CertificateArn"` // CertificateArn 是 ACM 或 IAM 证书 ARN。
+	IsDefault      bool   `xml:"IsDefault"`      // IsDefault 表示默认证书。
+}
+
+// elbDescribeListenerCertificatesOutput 是 DescribeListenerCertificates 响应。
+type elbDescribeListenerCertificatesOutput struct {
+	Certificates []elbListenerCertificate `xml:"DescribeListenerCertificatesResult>Certificates>member"` // Certificates 是当前页证书。
+	NextMarker   string                   `xml:"DescribeListenerCertificatesResult>NextMarker"`          // NextMarker 为空表示最后一页。
+}
+
+// elbCertificateSlot 是待部署的监听器证书槽位。
+type elbCertificateSlot struct {
+	IsDefault             bool   // IsDefault 表示默认证书槽位。
+	CurrentCertificateARN string // CurrentCertificateARN 是槽位当前证书 ARN。
+}
+
+// elbListenerKind 返回部署业务对应的负载均衡类型和 TLS 监听协议。
+func elbListenerKind(deploymentType deployPB.DeploymentType) (string, string) {
+	if deploymentType == deployPB.DeploymentType_DEPLOYMENT_TYPE_NLB {
+		return elbTypeNetwork, "TLS"
+	}
+	return elbTypeApplication, "HTTPS"
+}
+
+// discoverLoadBalancerResources 遍历配置地域的 ALB/NLB TLS 监听器，每个默认或 SNI 证书槽位生成一个资源。
+func (p *Provider) discoverLoadBalancerResources(ctx context.Context, deploymentType deployPB.DeploymentType) ([]providers.DeploymentResource, bool, error) {
+	resources := make([]providers.DeploymentResource, 0)
+	partial := false
+	var firstErr error
+	for _, region := range p.regions {
+		regionResources, regionPartial, err := p.discoverRegionLoadBalancerResources(ctx, deploymentType, region)
+		resources = append(resources, regionResources...)
+		partial = partial || regionPartial
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			partial = true
+		}
+		if len(resources) > maxResources {
+			return resources, true, providers.NewDeploymentError("AWS 负载均衡资源数量超过安全上限", false, "", nil)
+		}
+	}
+	sort.Slice(resources, func(left, right int) bool {
+		if resources[left].Region != resources[right].Region {
+			return resources[left].Region < resources[right].Region
+		}
+		return resources[left].Label < resources[right].Label
+	})
+	if firstErr != nil && len(resources) == 0 {
+		return nil, false, firstErr
+	}
+	return resources, partial, nil
+}
+
+// discoverRegionLoadBalancerResources 发现单个地域的监听器证书槽位；无法确认域名的证书会使目录标记为部分可用。
+func (p *Provider) discoverRegionLoadBalancerResources(ctx context.Context, deploymentType deployPB.DeploymentType, region string) ([]providers.DeploymentResource, bool, error) {
+	loadBalancerType, listenerProtocol := elbListenerKind(deploymentType)
+	loadBalancers, err := p.listLoadBalancers(ctx, region, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	resources := make([]providers.DeploymentResource, 0)
+	partial := false
+	certificateDomains := make(map[string][]string)
+	for _, loadBalancer := range loadBalancers {
+		if loadBalancer.Type != loadBalancerType {
+			continue
+		}
+		listeners, _, err := p.listListeners(ctx, region, url.Values{"LoadBalancerArn": {loadBalancer.LoadBalancerArn}})
+		if err != nil {
+			return resources, true, err
+		}
+		for _, listener := range listeners {
+			if !strings.EqualFold(listener.Protocol, listenerProtocol) {
+				continue
+			}
+			certificates, _, err := p.listListenerCertificates(ctx, region, listener.ListenerArn)
+			if err != nil {
+				return resources, true, err
+			}
+			for _, certificate := range certificates {
+				domains, ok := p.cachedCertificateDomains(ctx, region, certificate.CertificateArn, certificateDomains)
+				if !ok {
+					partial = true
+					continue
+				}
+				resources = append(resources, buildLoadBalancerResource(deploymentType, region, loadBalancer, listener, certificate.IsDefault, domains))
+			}
+		}
+	}
+	return resources, partial, nil
+}
+
+// cachedCertificateDomains 读取并缓存 ACM 证书域名；IAM 证书和读取失败的证书无法确认身份，跳过即可。
+func (p *Provider) cachedCertificateDomains(ctx context.Context, region, certificateARN string, cache map[string][]string) ([]string, bool) {
+	if domains, exists := cache[certificateARN]; exists {
+		return domains, len(domains) > 0
+	}
+	if !isACMCertificateARN(certificateARN) {
+		cache[certificateARN] = nil
+		return nil, false
+	}
+	detail, _, err := p.describeACMCertificate(ctx, region, certificateARN)
+	if err != nil {
+		cache[certificateARN] = nil
+		return nil, false
+	}
+	domains := acmCertificateDomains(detail.DomainName, detail.SubjectAlternativeNames)
+	cache[certificateARN] = domains
+	return domains, len(domains) > 0
+}
+
+// buildLoadBalancerResource 构造监听器证书槽位资源，SNI 槽位以证书域名集合区分。
+func buildLoadBalancerResource(deploymentType deployPB.DeploymentType, region string, loadBalancer elbLoadBalancer, listener elbListener, isDefault bool, domains []string) providers.DeploymentResource {
+	slot, slotLabel := elbSlotSNI, "SNI"
+	identity := []string{region, loadBalancer.LoadBalancerArn, listener.ListenerArn, elbSlotSNI, strings.Join(domains, ",")}
+	if isDefault {
+		slot, slotLabel = elbSlotDefault, "默认"
+		identity = []string{region, loadBalancer.LoadBalancerArn, listener.ListenerArn, elbSlotDefault}
+	}
+	availability := deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_STOPPED
+	if loadBalancer.State == elbStateActive {
+		availability = deployPB.DeploymentResourceAvailability_DEPLOYMENT_RESOURCE_AVAILABILITY_READY
+	}
+	return providers.DeploymentResource{
+		TargetRef:      providers.BuildTargetRef("aws", deploymentType, identity...),
+		Label:          fmt.Sprintf("%s:%d %s (%s)", loadBalancer.LoadBalancerName, listener.Port, slotLabel, strings.Join(domains, ", ")),
+		Domain:         domains[0],
+		Domains:        domains,
+		Group:          loadBalancer.LoadBalancerName,
+		Region:         region,
+		Protocol:       strings.ToUpper(listener.Protocol),
+		Status:         loadBalancer.State,
+		Availability:   availability,
+		LoadBalancerID: loadBalancer.LoadBalancerArn,
+		ListenerPort:   listener.Port,
+		ListenerID:     listener.ListenerArn,
+		ResourceID:     slot,
+		CreatedAt:      strings.TrimSpace(loadBalancer.CreatedTime),
+	}
+}
+
+// deployLoadBalancer 将 ACM 证书写入 ALB/NLB 监听器的默认或 SNI 槽位，并轮询回读证书列表。
+func (p *Provider) deployLoadBalancer(ctx context.Context, certificate providers.CertificateMaterial, deploymentType deployPB.DeploymentType, resource providers.DeploymentResource) (providers.DeploymentResult, error) {
+	region := strings.TrimSpace(resource.Region)
+	if !regionPattern.MatchString(region) || strings.TrimSpace(resource.LoadBalancerID) == "" || strings.TrimSpace(resource.ListenerID) == "" {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 负载均衡目标缺少地域、实例或监听器", false, "", nil)
+	}
+	if resource.ResourceID != elbSlotDefault && resource.ResourceID != elbSlotSNI {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 负载均衡证书槽位无效", false, "", nil)
+	}
+	loadBalancerType, listenerProtocol := elbListenerKind(deploymentType)
+
+	loadBalancers, err := p.listLoadBalancers(ctx, region, url.Values{"LoadBalancerArns.member.1": {resource.LoadBalancerID}})
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("读取负载均衡实例", err)
+	}
+	if len(loadBalancers) != 1 || loadBalancers[0].LoadBalancerArn != resource.LoadBalancerID || loadBalancers[0].Type != loadBalancerType {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 负载均衡实例已变化，请重新关联资源", false, "", nil)
+	}
+	switch loadBalancers[0].State {
+	case elbStateActive:
+	case elbStateProvisioning:
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 负载均衡实例仍在创建中", true, "", nil)
+	default:
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 负载均衡实例状态不支持部署", false, "", nil)
+	}
+	listeners, listenerRequestID, err := p.listListeners(ctx, region, url.Values{"ListenerArns.member.1": {resource.ListenerID}})
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("读取负载均衡监听器", err)
+	}
+	if len(listeners) != 1 || listeners[0].ListenerArn != resource.ListenerID || listeners[0].LoadBalancerArn != resource.LoadBalancerID ||
+		!strings.EqualFold(listeners[0].Protocol, listenerProtocol) || (resource.ListenerPort > 0 && listeners[0].Port != resource.ListenerPort) {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 负载均衡监听器已变化，请重新关联资源", false, listenerRequestID, nil)
+	}
+
+	certificates, certificatesRequestID, err := p.listListenerCertificates(ctx, region, resource.ListenerID)
+	requestID := firstNonEmpty(certificatesRequestID, listenerRequestID)
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("读取监听器证书", err)
+	}
+	slot, err := p.selectListenerCertificateSlot(ctx, region, resource, certificates)
+	if err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 监听器证书槽位校验失败", false, requestID, err)
+	}
+
+	certificateARN, importRequestID, err := p.ensureACMCertificate(ctx, region, certificate, slot.CurrentCertificateARN)
+	requestID = firstNonEmpty(importRequestID, requestID)
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("准备负载均衡证书", err)
+	}
+	if certificateARN == slot.CurrentCertificateARN {
+		return providers.DeploymentResult{RequestID: requestID, Message: "AWS 负载均衡监听器已配置当前证书"}, nil
+	}
+
+	if slot.IsDefault {
+		writeRequestID, err := p.callELB(ctx, region, "更新监听器默认证书", "ModifyListener", url.Values{
+			"ListenerArn":                          {resource.ListenerID},
+			"Certificates.member.1.CertificateArn": {certificateARN},
+		}, nil)
+		if err != nil {
+			return providers.DeploymentResult{}, toDeploymentError("更新监听器默认证书", err)
+		}
+		readbackRequestID, err := p.waitListenerCertificateSlot(ctx, region, resource.ListenerID, certificateARN, true, "")
+		if err != nil {
+			return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 监听器默认证书回读超时", true, firstNonEmpty(readbackRequestID, writeRequestID), err)
+		}
+		fingerprintRequestID, err := p.verifyACMCertificate(ctx, region, certificateARN, certificate.CertificatePEM)
+		if err != nil {
+			return providers.DeploymentResult{}, toDeploymentError("回读 ACM 证书", err)
+		}
+		return providers.DeploymentResult{RequestID: firstNonEmpty(fingerprintRequestID, writeRequestID, readbackRequestID), Message: "AWS 负载均衡默认证书部署成功"}, nil
+	}
+
+	addRequestID, err := p.callELB(ctx, region, "添加监听器 SNI 证书", "AddListenerCertificates", url.Values{
+		"ListenerArn":                          {resource.ListenerID},
+		"Certificates.member.1.CertificateArn": {certificateARN},
+	}, nil)
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("添加监听器 SNI 证书", err)
+	}
+	readbackRequestID, err := p.waitListenerCertificateSlot(ctx, region, resource.ListenerID, certificateARN, false, "")
+	if err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 监听器新 SNI 证书回读超时", true, firstNonEmpty(readbackRequestID, addRequestID), err)
+	}
+	removeRequestID, err := p.callELB(ctx, region, "移除监听器旧 SNI 证书", "RemoveListenerCertificates", url.Values{
+		"ListenerArn":                          {resource.ListenerID},
+		"Certificates.member.1.CertificateArn": {slot.CurrentCertificateARN},
+	}, nil)
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("移除监听器旧 SNI 证书", err)
+	}
+	removedRequestID, err := p.waitListenerCertificateSlot(ctx, region, resource.ListenerID, certificateARN, false, slot.CurrentCertificateARN)
+	if err != nil {
+		return providers.DeploymentResult{}, providers.NewDeploymentError("AWS 监听器旧 SNI 证书移除超时", true, firstNonEmpty(removedRequestID, removeRequestID), err)
+	}
+	fingerprintRequestID, err := p.verifyACMCertificate(ctx, region, certificateARN, certificate.CertificatePEM)
+	if err != nil {
+		return providers.DeploymentResult{}, toDeploymentError("回读 ACM 证书", err)
+	}
+	return providers.DeploymentResult{RequestID: firstNonEmpty(fingerprintRequestID, removeRequestID, removedRequestID, addRequestID), Message: "AWS 负载均衡 SNI 证书部署成功"}, nil
+}
+
+// selectListenerCertificateSlot 找到资源对应的默认证书或唯一一个域名集合相同的 SNI 证书。
+func (p *Provider) selectListenerCertificateSlot(ctx context.Context, region string, resource providers.DeploymentResource, certificates []elbListenerCertificate) (elbCertificateSlot, error) {
+	if resource.ResourceID == elbSlotDefault {
+		for _, certificate := range certificates {
+			if certificate.IsDefault {
+				return elbCertificateSlot{IsDefault: true, CurrentCertificateARN: certificate.CertificateArn}, nil
+			}
+		}
+		return elbCertificateSlot{}, errors.New("监听器缺少默认证书")
+	}
+	targetDomains := providers.NormalizeDomains(resource.Domains...)
+	cache := make(map[string][]string)
+	matches := make([]string, 0, 1)
+	for _, certificate := range certificates {
+		if certificate.IsDefault {
+			continue
+		}
+		domains, ok := p.cachedCertificateDomains(ctx, region, certificate.CertificateArn, cache)
+		if ok && sameDomains(domains, targetDomains) {
+			matches = append(matches, certificate.CertificateArn)
+		}
+	}
+	if len(matches) != 1 {
+		return elbCertificateSlot{}, fmt.Errorf("匹配到 %d 个 SNI 证书，请重新关联资源", len(matches))
+	}
+	return elbCertificateSlot{CurrentCertificateARN: matches[0]}, nil
+}
+
+// waitListenerCertificateSlot 轮询监听器证书列表，确认新证书位于期望槽位并按需确认旧证书已移除；等待时间不超过操作剩余时间。
+func (p *Provider) waitListenerCertificateSlot(ctx context.Context, region, listenerARN, expectedCertificateARN string, wantDefault bool, removedCertificateARN string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, providers.OperationWaitTimeout(ctx, p.syncTimeout, syncDeadlineReserve))
+	defer cancel()
+	for {
+		certificates, requestID, err := p.listListenerCertificates(ctx, region, listenerARN)
+		if err != nil {
+			return requestID, err
+		}
+		if listenerCertificateSlotMatches(certificates, expectedCertificateARN, wantDefault) &&
+			(removedCertificateARN == "" || !listenerCertificateExists(certificates, removedCertificateARN)) {
+			return requestID, nil
+		}
+		select {
+		case <-ctx.Done():
+			return requestID, ctx.Err()
+		case <-time.After(p.pollInterval):
+		}
+	}
+}
+
+// listenerCertificateSlotMatches 判断证书是否已位于期望的默认或 SNI 槽位。
+func listenerCertificateSlotMatches(certificates []elbListenerCertificate, expectedCertificateARN string, wantDefault bool) bool {
+	for _, certificate := range certificates {
+		if certificate.CertificateArn == expectedCertificateARN && certificate.IsDefault == wantDefault {
+			return true
+		}
+	}
+	return false
+}
+
+// listenerCertificateExists 判断证书 ARN 是否仍在监听器证书列表中。
+func listenerCertificateExists(certificates []elbListenerCertificate, certificateARN string) bool {
+	for _, certificate := range certificates {
+		if certificate.CertificateArn == certificateARN {
+			return true
+		}
+	}
+	return false
+}
+
+// listLoadBalancers 分页读取地域内的负载均衡实例。
+func (p *Provider) listLoadBalancers(ctx context.Context, region string, filter url.Values) ([]elbLoadBalancer, error) {
+	loadBalancers := make([]elbLoadBalancer, 0)
+	marker := ""
+	for page := 0; page < maxPages; page++ {
+		parameters := cloneValues(filter)
+		if len(filter) == 0 {
+			parameters.Set("PageSize", strconv.Itoa(pageSize))
+		}
+		if marker != "" {
+			parameters.Set("Marker", marker)
+		}
+		var output elbDescribeLoadBalancersOutput
+		if _, err := p.callELB(ctx, region, "读取负载均衡实例列表", "DescribeLoadBalancers", parameters, &output); err != nil {
+			return loadBalancers, err
+		}
+		loadBalancers = append(loadBalancers, output.LoadBalancers...)
+		marker = strings.TrimSpace(output.NextMarker)
+		if marker == "" {
+			return loadBalancers, nil
+		}
+	}
+	return loadBalancers, providers.NewDeploymentError("AWS 负载均衡实例列表超过安全分页上限", false, "", nil)
+}
+
+// listListeners 分页读取实例或指定 ARN 的监听器。
+func (p *Provider) listListeners(ctx context.Context, region string, filter url.Values) ([]elbListener, string, error) {
+	listeners := make([]elbListener, 0)
+	requestID := ""
+	marker := ""
+	for page := 0; page < maxPages; page++ {
+		parameters := cloneValues(filter)
+		if marker != "" {
+			parameters.Set("Marker", marker)
+		}
+		var output elbDescribeListenersOutput
+		pageRequestID, err := p.callELB(ctx, region, "读取负载均衡监听器列表", "DescribeListeners", parameters, &output)
+		requestID = firstNonEmpty(pageRequestID, requestID)
+		if err != nil {
+			return listeners, requestID, err
+		}
+		listeners = append(listeners, output.Listeners...)
+		marker = strings.TrimSpace(output.NextMarker)
+		if marker == "" {
+			return listeners, requestID, nil
+		}
+	}
+	return listeners, requestID, providers.NewDeploymentError("AWS 监听器列表超过安全分页上限", false, requestID, nil)
+}
+
+// listListenerCertificates 分页读取监听器的默认证书和 SNI 证书。
+func (p *Provider) listListenerCertificates(ctx context.Context, region, listenerARN string) ([]elbListenerCertificate, string, error) {
+	certificates := make([]elbListenerCertificate, 0)
+	requestID := ""
+	marker := ""
+	for page := 0; page < maxPages; page++ {
+		parameters := url.Values{
+			"ListenerArn": {listenerARN},
+			"PageSize":    {strconv.Itoa(elbCertificatePageMax)},
+		}
+		if marker != "" {
+			parameters.Set("Marker", marker)
+		}
+		var output elbDescribeListenerCertificatesOutput
+		pageRequestID, err := p.callELB(ctx, region, "读取监听器证书列表", "DescribeListenerCertificates", parameters, &output)
+		requestID = firstNonEmpty(pageRequestID, requestID)
+		if err != nil {
+			return certificates, requestID, err
+		}
+		certificates = append(certificates, output.Certificates...)
+		marker = strings.TrimSpace(output.NextMarker)
+		if marker == "" {
+			return certificates, requestID, nil
+		}
+	}
+	return certificates, requestID, providers.NewDeploymentError("AWS 监听器证书列表超过安全分页上限", false, requestID, nil)
+}
+
+// cloneValues 复制查询参数，避免分页游标写回调用方的过滤条件。
+func cloneValues(values url.Values) url.Values {
+	cloned := url.Values{}
+	for key, items := range values {
+		cloned[key] = append([]string(nil), items...)
+	}
+	return cloned
+}
@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/https-cert/deploy/internal/client/providers"
+)
+
+// apiError 保存 AWS 请求的错误码、重试分类和脱敏诊断信息。
+type apiError struct {
+	Operation string // Operation 是失败的控制面操作。
+	Status    int    // Status 是 HTTP 状态码，传输失败时为零。
+	Code      string // Code 是 AWS 错误码，例如 AccessDeniedException。
+	RequestID string // RequestID 是 AWS 请求编号。
+	Retryable bool   // Retryable 表示重试是否可能恢复。
+	Cause     error  // Cause 保存底层网络或解析错误。
+}
+
+// Error 返回不包含凭据、证书或完整响应体的本地诊断。
+func (e *apiError) Error() string {
+	if e == nil {
+		return ""
+	}
+	if e.Status > 0 {
+		return fmt.Sprintf("AWS %s 失败: HTTP %d, code=%s", e.Operation, e.Status, e.Code)
+	}
+	return fmt.Sprintf("AWS %s 失败", e.Operation)
+}
+
+// Unwrap 暴露底层错误供 errors.Is 和 errors.As 使用。
+func (e *apiError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Cause
+}
+
+// GetCode 返回 AWS 错误码，供 providers.IsPermissionDenied 识别权限不足。
+func (e *apiError) GetCode() string {
+	if e == nil {
+		return ""
+	}
+	return e.Code
+}
+
+// toDeploymentError 将 AWS API 错误转换为统一重试和 request ID 语义。
+func toDeploymentError(operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var deploymentError *providers.DeploymentError
+	if errors.As(err, &deploymentError) {
+		return err
+	}
+	var requestError *apiError
+	if errors.As(err, &requestError) {
+		return providers.NewDeploymentError("AWS "+operation+"失败", requestError.Retryable, requestError.RequestID, err)
+	}
+	return providers.NewDeploymentError("AWS "+operation+"失败", false, "", err)
+}
+
+// isPermissionDenied 判断错误是否由凭据无效或 IAM 权限不足引起。
+func isPermissionDenied(err error) bool {
+	var requestError *apiError
+	if errors.As(err, &requestError) && (requestError.Status == http.StatusUnauthorized || requestError.Status == http.StatusForbidden) {
+		return true
+	}
+	return providers.IsPermissionDenied(err)
+}
+
+// isRetryable 按 HTTP 状态和 AWS 限流、并发冲突错误码判断是否可重试。
+func isRetryable(status int, code string) bool {
+	if status == http.StatusTooManyRequests || status >= http.StatusInternalServerError {
+		return true
+	}
+	switch code {
+	case "Throttling", "ThrottlingException", "RequestLimitExceeded", "TooManyRequestsException",
+		"PreconditionFailed", "PriorRequestNotComplete", "ResourceInUse", "ResourceInUseException":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseErrorResponse 从 JSON、XML 响应体或 x-amzn-ErrorType 头中提取错误码和请求编号。
+func parseErrorResponse(header http.Header, body []byte) (string, string) {
+	var jsonError struct {
+		Type string `json:"__type"`
+		Code string `json:"code"`
+	}
+	if json.Unmarshal(body, &jsonError) == nil && firstNonEmpty(jsonError.Type, jsonError.Code) != "" {
+		return normalizeErrorCode(firstNonEmpty(jsonError.Type, jsonError.Code)), ""
+	}
+	var xmlError struct {
+		Code      string `xml:"Error>Code"`
+		RequestID string `xml:"RequestId"`
+	}
+	if xml.Unmarshal(body, &xmlError) == nil && xmlError.Code != "" {
+		return normalizeErrorCode(xmlError.Code), strings.TrimSpace(xmlError.RequestID)
+	}
+	return normalizeErrorCode(header.Get("X-Amzn-Errortype")), ""
+}
+
+// normalizeErrorCode 去掉 AWS 错误类型中的命名空间前缀和文档地址后缀。
+func normalizeErrorCode(code string) string {
+	code = strings.TrimSpace(code)
+	if index := strings.LastIndex(code, "#"); index >= 0 {
+		code = code[index+1:]
+	}
+	if index := strings.Index(code, ":"); index >= 0 {
+		code = code[:index]
+	}
+	return code
+}
+
+// responseRequestID 从 AWS 常见响应头提取请求编号。
+func responseRequestID(header http.Header) string {
+	for _, key := range []string{"X-Amzn-Requestid", "X-Amz-Request-Id"} {
+		if value := strings.TrimSpace(header.Get(key)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// firstNonEmpty 返回第一个非空字符串。
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
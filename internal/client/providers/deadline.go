@@ -0,0 +1,18 @@
+package providers
+
+import (
+	"context"
+	"time"
+)
+
+// OperationWaitTimeout 返回不超过 ctx 剩余时间的等待时长；ctx 有截止时间时先扣除 reserve，为回滚和返回结构化错误留出时间，结果不超过 timeout 且不小于 0。
+func OperationWaitTimeout(ctx context.Context, timeout, reserve time.Duration) time.Duration {
+	if ctx == nil {
+		return timeout
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return timeout
+	}
+	return max(0, min(timeout, time.Until(deadline)-reserve))
+}
@@ -0,0 +1,27 @@
+package providers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestOperationWaitTimeoutRespectsDeadline 验证等待时长受 ctx 截止时间约束并扣除预留时间。
+func TestOperationWaitTimeoutRespectsDeadline(t *testing.T) {
+	if got := OperationWaitTimeout(context.Background(), time.Minute, 10*time.Second); got != time.Minute {
+		t.Fatalf("没有截止时间时应使用默认时长: %s", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Second)
+	defer cancel()
+	got := OperationWaitTimeout(ctx, 2*time.Minute, 10*time.Second)
+	if got > 45*time.Second || got < 44*time.Second {
+		t.Fatalf("等待时长应为剩余时间减去预留时间: %s", got)
+	}
+	if got := OperationWaitTimeout(ctx, 5*time.Second, 10*time.Second); got != 5*time.Second {
+		t.Fatalf("剩余时间充足时应使用默认时长: %s", got)
+	}
+	if got := OperationWaitTimeout(ctx, time.Minute, time.Minute); got != 0 {
+		t.Fatalf("剩余时间不足预留时间时应返回 0: %s", got)
+	}
+}
@@ -353,6 +353,11 @@ func TestDeploymentExecutorRoutes(t *testing.T) {
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_CERT,
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_CERT,
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_CADDY_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_HAPROXY_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_TRAEFIK_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_JAVA_KEYSTORE_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MAIL_CERT,
 	} {
 		if err := executor.executeNonResourceDeployment(context.Background(), deployPB.Provider_PROVIDER_ANSSL_CLI, deploymentType, "", "", "", "", ""); err == nil {
 			t.Fatalf("空域名本地部署应被拒绝: %s", deploymentType)
@@ -370,44 +375,128 @@ func TestDeploymentExecutorRoutes(t *testing.T) {
 func TestProviderConnectionRoutes(t *testing.T) {
 	originalFeiNiu := testFeiNiuConnection
 	originalRustFS := testRustFSConnection
+	originalOpenVPNAS := testOpenVPNASConnection
 	originalOnePanel := testOnePanelConnection
 	originalOnePanelWebsite := testOnePanelWebsiteConnection
 	originalBTPanelWebsite := testBTPanelWebsiteConnection
 	originalBTPanelCertificate := testBTPanelCertificateConnection
 	originalSafeLine := testSafeLineConnection
+	originalCaddy := testCaddyConnection
+	originalHAProxy := testHAProxyConnection
+	originalMail := testMailConnection
+	originalKubernetesSecret := testKubernetesSecretConnection
+	originalJavaKeystore := testJavaKeystoreConnection
+	originalLocalTarget := testLocalTargetConnection
+	originalSSHTarget := testSSHTargetConnection
+	originalSynology := testSynologyConnection
+	originalProxmox := testProxmoxConnection
+	originalFirewall := testFirewallConnection
+	originalNginxServer := testNginxServerConnection
+	originalApacheVhost := testApacheVhostConnection
+	originalDockerContainer := testDockerContainerConnection
+	originalNPMProxyHost := testNPMProxyHostConnection
+	originalProxyServer := testProxyServerConnection
+	originalDatabase := testDatabaseConnection
+	originalS3Server := testS3ServerConnection
+	originalMediaServer := testMediaServerConnection
+	originalSafeLineSite := testSafeLineSiteConnection
+	originalFeiNiuDevice := testFeiNiuDeviceConnection
 	t.Cleanup(func() {
 		testFeiNiuConnection = originalFeiNiu
 		testRustFSConnection = originalRustFS
+		testOpenVPNASConnection = originalOpenVPNAS
 		testOnePanelConnection = originalOnePanel
 		testOnePanelWebsiteConnection = originalOnePanelWebsite
 		testBTPanelWebsiteConnection = originalBTPanelWebsite
 		testBTPanelCertificateConnection = originalBTPanelCertificate
 		testSafeLineConnection = originalSafeLine
+		testCaddyConnection = originalCaddy
+		testHAProxyConnection = originalHAProxy
+		testMailConnection = originalMail
+		testKubernetesSecretConnection = originalKubernetesSecret
+		testJavaKeystoreConnection = originalJavaKeystore
+		testLocalTargetConnection = originalLocalTarget
+		testSSHTargetConnection = originalSSHTarget
+		testSynologyConnection = originalSynology
+		testProxmoxConnection = originalProxmox
+		testFirewallConnection = originalFirewall
+		testNginxServerConnection = originalNginxServer
+		testApacheVhostConnection = originalApacheVhost
+		testDockerContainerConnection = originalDockerContainer
+		testNPMProxyHostConnection = originalNPMProxyHost
+		testProxyServerConnection = originalProxyServer
+		testDatabaseConnection = originalDatabase
+		testS3ServerConnection = originalS3Server
+		testMediaServerConnection = originalMediaServer
+		testSafeLineSiteConnection = originalSafeLineSite
+		testFeiNiuDeviceConnection = originalFeiNiuDevice
 	})
 	called := 0
 	success := func(context.Context) error { called++; return nil }
 	testFeiNiuConnection = success
 	testRustFSConnection = success
+	testOpenVPNASConnection = success
 	testOnePanelConnection = success
 	testBTPanelCertificateConnection = success
 	testSafeLineConnection = success
+	testCaddyConnection = success
+	testHAProxyConnection = success
+	testMailConnection = success
+	testJavaKeystoreConnection = success
 	testOnePanelWebsiteConnection = func(context.Context, string) error { called++; return nil }
 	testBTPanelWebsiteConnection = func(context.Context, string) error { called++; return nil }
+	testKubernetesSecretConnection = func(context.Context, string) error { called++; return nil }
+	testLocalTargetConnection = func(context.Context, string) error { called++; return nil }
+	testSSHTargetConnection = func(context.Context, string) error { called++; return nil }
+	testSynologyConnection = func(context.Context, string) error { called++; return nil }
+	testProxmoxConnection = func(context.Context, string) error { called++; return nil }
+	testFirewallConnection = func(context.Context, string) error { called++; return nil }
+	testNginxServerConnection = func(context.Context, string) error { called++; return nil }
+	testApacheVhostConnection = func(context.Context, string) error { called++; return nil }
+	testDockerContainerConnection = func(context.Context, string) error { called++; return nil }
+	testNPMProxyHostConnection = func(context.Context, string) error { called++; return nil }
+	testProxyServerConnection = func(context.Context, string) error { called++; return nil }
+	testDatabaseConnection = func(context.Context, string) error { called++; return nil }
+	testS3ServerConnection = func(context.Context, string) error { called++; return nil }
+	testMediaServerConnection = func(context.Context, string) error { called++; return nil }
+	testSafeLineSiteConnection = func(context.Context, string) error { called++; return nil }
+	testFeiNiuDeviceConnection = func(context.Context, string) error { called++; return nil }
 	for _, deploymentType := range []deployPB.DeploymentType{
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_CERT,
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_RUSTFS_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_OPENVPN_AS_CERT,
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_CERT,
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_WEBSITE_CERT,
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_WEBSITE_CERT,
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_CERT,
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_CADDY_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_HAPROXY_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MAIL_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_KUBERNETES_SECRET_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_JAVA_KEYSTORE_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_LOCAL_TARGET_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SSH_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SYNOLOGY_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXMOX_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FIREWALL_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_SERVER_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_VHOST_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DOCKER_CONTAINER_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NPM_PROXY_HOST_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXY_SERVER_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DATABASE_TLS_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_S3_SERVER_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MEDIA_SERVER_PFX,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_SITE_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_DEVICE_CERT,
 	} {
 		ok, err := testDeploymentConnection(context.Background(), deployPB.Provider_PROVIDER_ANSSL_CLI, deploymentType, "target", nil)
 		if !ok || err != nil {
 			t.Fatalf("本地连接测试失败: type=%s ok=%v err=%v", deploymentType, ok, err)
 		}
 	}
-	if called != 7 {
+	if called != 28 {
 		t.Fatalf("本地连接测试调用次数不匹配: %d", called)
 	}
 	if _, err := TestProviderConnection(context.Background(), nil, "unknown"); err == nil {
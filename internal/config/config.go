@@ -9,6 +9,7 @@ import (
 	"path"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -26,6 +27,65 @@ const (
 	maxPort                  = 65535
 	envLocal                 = "local"
 	defaultUpdateMirror      = "ghproxy"
+	defaultCaddyAdminURL     = "http://localhost:2019"
+	defaultHAProxyConfigFile = "/etc/haproxy/haproxy.cfg"
+	defaultJavaKeystoreAlias = "anssl"
+	defaultLocalCertFile     = "cert.pem"
+	defaultLocalKeyFile      = "privateKey.key"
+	defaultLocalCertMode     = "0644"
+	defaultLocalKeyMode      = "0600"
+	defaultLocalTimeout      = 60
+	defaultMailVerifyHost    = "localhost"
+	defaultDockerSocket      = "/var/run/docker.sock"
+	defaultDockerLabel       = "anssl.domain"
+	defaultDockerSignal      = "SIGHUP"
+	defaultDockerTimeout     = 10
+	maxLocalTimeout          = 3600
+
+	// MailServicePostfix 表示通过 smtpd_tls_chain_files 引用证书的 Postfix。
+	MailServicePostfix = "postfix"
+	// MailServiceDovecot 表示通过 ssl_cert 和 ssl_key 引用证书的 Dovecot。
+	MailServiceDovecot = "dovecot"
+
+	// DockerActionSignal 表示证书发布后向容器发送信号。
+	DockerActionSignal = "signal"
+	// DockerActionRestart 表示证书发布后重启容器。
+	DockerActionRestart = "restart"
+
+	// FirewallKindOPNsense 表示使用 OPNsense 内置 REST API 的防火墙。
+	FirewallKindOPNsense = "opnsense"
+	// FirewallKindPfSense 表示安装了 pfSense-pkg-RESTAPI v2 的 pfSense 防火墙。
+	FirewallKindPfSense = "pfsense"
+
+	// ProxyServerKindXray 表示 Xray-core，部署前执行 xray run -test。
+	ProxyServerKindXray = "xray"
+	// ProxyServerKindV2Ray 表示 V2Ray v5，部署前执行 v2ray test。
+	ProxyServerKindV2Ray = "v2ray"
+	// ProxyServerKindTrojanGo 表示 Trojan-Go，证书字段位于 ssl.cert 和 ssl.key。
+	ProxyServerKindTrojanGo = "trojan-go"
+	// ProxyServerKindHysteria 表示 Hysteria，证书字段位于 tls.cert 和 tls.key。
+	ProxyServerKindHysteria = "hysteria"
+
+	// DatabaseKindPostgreSQL 表示通过 SELECT pg_reload_conf() 重新加载证书的 PostgreSQL。
+	DatabaseKindPostgreSQL = "postgresql"
+	// DatabaseKindMySQL 表示通过 ALTER INSTANCE RELOAD TLS 重新加载证书的 MySQL 8。
+	DatabaseKindMySQL = "mysql"
+	// DatabaseKindRedis 表示通过 CONFIG SET tls-cert-file 重新加载证书的 Redis 6 及以上版本。
+	DatabaseKindRedis = "redis"
+
+	// S3ServerProfileMinIO 表示 MinIO 的 public.crt 和 private.key 命名，Garage、SeaweedFS 等按同一约定部署时同样适用。
+	S3ServerProfileMinIO = "minio"
+	// S3ServerProfileRustFS 表示 RustFS 的 rustfs_cert.pem 和 rustfs_key.pem 命名。
+	S3ServerProfileRustFS = "rustfs"
+	// S3ServerProfileCustom 表示由 certFile 和 keyFile 指定文件名。
+	S3ServerProfileCustom = "custom"
+
+	// MediaServerKindJellyfin 表示通过 POST /System/Restart 重启加载证书的 Jellyfin。
+	MediaServerKindJellyfin = "jellyfin"
+	// MediaServerKindEmby 表示通过 POST /emby/System/Restart 重启加载证书的 Emby。
+	MediaServerKindEmby = "emby"
+	// MediaServerKindPlex 表示通过 PUT /:/prefs 重新加载网络配置的 Plex Media Server。
+	MediaServerKindPlex = "plex"
 )
 
 // Configuration 应用配置结构
@@ -59,14 +119,36 @@ type (
 
 	// DeployConfig 本地证书部署目标配置
 	DeployConfig struct {
-		NginxPath  string          `yaml:"nginxPath"`  // NginxPath 是 Nginx SSL 证书目录
-		ApachePath string          `yaml:"apachePath"` // ApachePath 是 Apache SSL 证书目录
-		RustFSPath string          `yaml:"rustFSPath"` // RustFSPath 兼容旧版 RustFS 本机目录配置
-		RustFS     *RustFSConfig   `yaml:"rustFS"`     // RustFS 是本机或 SSH 远程部署配置
-		FeiNiu     *SSHConfig      `yaml:"feiNiu"`     // FeiNiu 是可选的 SSH 远程配置，空值表示本机部署
-		OnePanel   *OnePanelConfig `yaml:"onePanel"`   // OnePanel 是 1Panel API 配置
-		BTPanel    *BTPanelConfig  `yaml:"btPanel"`    // BTPanel 是宝塔面板 API 配置
-		SafeLine   *SafeLineConfig `yaml:"safeLine"`   // SafeLine 是雷池 WAF OpenAPI 配置
+		NginxPath         string                   `yaml:"nginxPath"`         // NginxPath 是 Nginx SSL 证书目录
+		ApachePath        string                   `yaml:"apachePath"`        // ApachePath 是 Apache SSL 证书目录
+		RustFSPath        string                   `yaml:"rustFSPath"`        // RustFSPath 兼容旧版 RustFS 本机目录配置
+		RustFS            *RustFSConfig            `yaml:"rustFS"`            // RustFS 是本机或 SSH 远程部署配置
+		FeiNiu            *SSHConfig               `yaml:"feiNiu"`            // FeiNiu 是可选的 SSH 远程配置，空值表示本机部署
+		FeiNiuDevices     []*FeiNiuDeviceConfig    `yaml:"feiNiuDevices"`     // FeiNiuDevices 是多台具名飞牛 OS 设备的 SSH 远程配置
+		OpenVPNAS         *OpenVPNASConfig         `yaml:"openVPNAS"`         // OpenVPNAS 是可选的 sacli 路径和 SSH 远程配置，空值表示本机部署
+		OnePanel          *OnePanelConfig          `yaml:"onePanel"`          // OnePanel 是兼容的单实例 1Panel API 配置
+		OnePanels         []*OnePanelConfig        `yaml:"onePanels"`         // OnePanels 是多个具名 1Panel 实例的 API 配置
+		BTPanel           *BTPanelConfig           `yaml:"btPanel"`           // BTPanel 是兼容的单实例宝塔面板 API 配置
+		BTPanels          []*BTPanelConfig         `yaml:"btPanels"`          // BTPanels 是多个具名宝塔面板实例的 API 配置
+		SafeLine          *SafeLineConfig          `yaml:"safeLine"`          // SafeLine 是兼容的单实例雷池 WAF OpenAPI 配置
+		SafeLines         []*SafeLineConfig        `yaml:"safeLines"`         // SafeLines 是多个具名雷池 WAF 实例的 OpenAPI 配置
+		NginxProxyManager *NginxProxyManagerConfig `yaml:"nginxProxyManager"` // NginxProxyManager 是 Nginx Proxy Manager 管理端登录配置
+		Caddy             *CaddyConfig             `yaml:"caddy"`             // Caddy 是 Caddy 证书目录和管理 API 配置
+		HAProxy           *HAProxyConfig           `yaml:"haproxy"`           // HAProxy 是 HAProxy 合并证书目录和 Runtime API 配置
+		Traefik           *TraefikConfig           `yaml:"traefik"`           // Traefik 是 Traefik 文件 provider 证书目录配置
+		Kubernetes        *KubernetesConfig        `yaml:"kubernetes"`        // Kubernetes 是 TLS Secret 部署的集群凭据和命名空间配置
+		JavaKeystore      *JavaKeystoreConfig      `yaml:"javaKeystore"`      // JavaKeystore 是 PKCS#12/JKS 密钥库输出配置
+		LocalTargets      []*LocalTargetConfig     `yaml:"localTargets"`      // LocalTargets 是多个具名的本地目录与自定义命令部署实例
+		SSHTargets        []*SSHTargetConfig       `yaml:"sshTargets"`        // SSHTargets 是多个具名的通用 SSH 远程主机部署实例
+		Synology          []*SynologyConfig        `yaml:"synology"`          // Synology 是多台具名群晖 DSM 的 Web API 配置
+		Proxmox           []*ProxmoxConfig         `yaml:"proxmox"`           // Proxmox 是多个具名 Proxmox VE 集群的 API Token 配置
+		Firewalls         []*FirewallConfig        `yaml:"firewalls"`         // Firewalls 是多台具名 OPNsense 或 pfSense 防火墙的 REST API 配置
+		ProxyServers      []*ProxyServerConfig     `yaml:"proxyServers"`      // ProxyServers 是多个具名 Xray、V2Ray、Trojan-Go 或 Hysteria 代理服务实例
+		Databases         []*DatabaseConfig        `yaml:"databases"`         // Databases 是多个具名 PostgreSQL、MySQL 或 Redis 服务端 TLS 证书实例
+		S3Servers         []*S3ServerConfig        `yaml:"s3Servers"`         // S3Servers 是多个具名 MinIO、RustFS 等 S3 兼容对象存储服务的证书目录实例
+		MediaServers      []*MediaServerConfig     `yaml:"mediaServers"`      // MediaServers 是多个具名 Jellyfin、Emby 或 Plex 媒体服务器的 PFX 证书实例
+		Mail              *MailConfig              `yaml:"mail"`              // Mail 是 Postfix 与 Dovecot 邮件服务证书配置
+		Docker            *DockerConfig            `yaml:"docker"`            // Docker 是按标签发现容器并写入 bind mount 目录的 Docker Engine API 配置
 	}
 
 	// SSHConfig 保存仅供 deploy 客户端本地使用的 SSH 认证配置。
@@ -82,32 +164,237 @@ type (
 	// FeiNiuSSHConfig 兼容已有飞牛 SSH 配置类型名称。
 	FeiNiuSSHConfig = SSHConfig
 
+	// FeiNiuDeviceConfig 具名飞牛 OS 设备的 SSH 远程部署配置。
+	FeiNiuDeviceConfig struct {
+		Name      string                                  `yaml:"name"` // Name 是设备名称，只能包含字母、数字、下划线和连字符
+		SSHConfig `yaml:",inline" mapstructure:",squash"` // SSHConfig 是设备 SSH 连接和认证配置
+	}
+
 	// RustFSConfig 保存 RustFS 证书目录及可选的 SSH 远程配置。
 	RustFSConfig struct {
 		Path      string                                  `yaml:"path"` // Path 是 RustFS TLS 证书根目录
 		SSHConfig `yaml:",inline" mapstructure:",squash"` // SSHConfig 是可选的 SSH 远程连接配置
 	}
 
+	// OpenVPNASConfig 保存 OpenVPN-AS sacli 路径及可选的 SSH 远程配置。
+	OpenVPNASConfig struct {
+		SacliPath string                                  `yaml:"sacliPath"` // SacliPath 是 sacli 绝对路径，留空时使用 OpenVPN-AS 默认安装位置
+		SSHConfig `yaml:",inline" mapstructure:",squash"` // SSHConfig 是可选的 SSH 远程连接配置
+	}
+
 	// OnePanelConfig 1Panel 配置
 	OnePanelConfig struct {
-		URL    string `yaml:"url"`    // 1Panel API 地址
-		APIKey string `yaml:"apiKey"` // 1Panel API 密钥
+		Name               string `yaml:"name"`               // Name 是 onePanels 中的实例名称，只能包含字母、数字、下划线和连字符
+		URL                string `yaml:"url"`                // 1Panel API 地址
+		APIKey             string `yaml:"apiKey"`             // 1Panel API 密钥
+		InsecureSkipVerify bool   `yaml:"insecureSkipVerify"` // InsecureSkipVerify 仅用于显式信任自签名 HTTPS 证书
 	}
 
 	// BTPanelConfig 宝塔面板 API 配置。
 	BTPanelConfig struct {
+		Name               string `yaml:"name"`               // Name 是 btPanels 中的实例名称，只能包含字母、数字、下划线和连字符
 		URL                string `yaml:"url"`                // URL 是宝塔面板地址
 		APIKey             string `yaml:"apiKey"`             // APIKey 是宝塔面板接口密钥
 		InsecureSkipVerify bool   `yaml:"insecureSkipVerify"` // InsecureSkipVerify 仅用于显式信任自签名 HTTPS 证书
 	}
 
+	// SynologyConfig 群晖 DSM Web API 配置。
+	SynologyConfig struct {
+		Name               string `yaml:"name"`               // Name 是 NAS 名称，只能包含字母、数字、下划线和连字符
+		URL                string `yaml:"url"`                // URL 是 DSM 管理端地址，例如 https://nas.lan:5001
+		Username           string `yaml:"username"`           // Username 是 administrators 组内的 DSM 账号
+		Password           string `yaml:"password"`           // Password 是 DSM 账号密码
+		DeviceID           string `yaml:"deviceId"`           // DeviceID 是开启双重验证时已信任设备的 did，可选
+		Description        string `yaml:"description"`        // Description 是新导入证书的描述，DSM 中不存在时按该描述新建
+		SetDefault         bool   `yaml:"setDefault"`         // SetDefault 为 true 时把 description 对应的证书设为系统默认证书
+		InsecureSkipVerify bool   `yaml:"insecureSkipVerify"` // InsecureSkipVerify 仅用于显式信任自签名 HTTPS 证书
+	}
+
+	// ProxmoxConfig Proxmox VE 集群 API 配置。
+	ProxmoxConfig struct {
+		Name               string `yaml:"name"`               // Name 是集群名称，只能包含字母、数字、下划线和连字符
+		URL                string `yaml:"url"`                // URL 是任一集群节点的 API 地址，例如 https://pve1.lan:8006
+		TokenID            string `yaml:"tokenId"`            // TokenID 是 API Token 完整 ID，格式为 用户@域!令牌名
+		TokenSecret        string `yaml:"tokenSecret"`        // TokenSecret 是 API Token 密钥
+		InsecureSkipVerify bool   `yaml:"insecureSkipVerify"` // InsecureSkipVerify 仅用于显式信任自签名 HTTPS 证书
+	}
+
+	// ProxyServerConfig Xray、V2Ray、Trojan-Go 或 Hysteria 代理服务实例配置。
+	ProxyServerConfig struct {
+		Name    string `yaml:"name"`    // Name 是实例名称，只能包含字母、数字、下划线和连字符
+		Kind    string `yaml:"kind"`    // Kind 是服务类型，支持 xray、v2ray、trojan-go 和 hysteria
+		Config  string `yaml:"config"`  // Config 是服务的 JSON 或 YAML 配置文件绝对路径，证书路径从中读取
+		Service string `yaml:"service"` // Service 是部署后重启的 systemd 单元，默认 xray、v2ray、trojan-go 或 hysteria-server
+		Binary  string `yaml:"binary"`  // Binary 是执行配置校验的可执行文件，默认与 kind 同名并从 PATH 查找
+	}
+
+	// DatabaseConfig PostgreSQL、MySQL 或 Redis 服务端 TLS 证书文件和在线重新加载连接配置。
+	DatabaseConfig struct {
+		Name     string `yaml:"name"`     // Name 是实例名称，只能包含字母、数字、下划线和连字符
+		Kind     string `yaml:"kind"`     // Kind 是数据库类型，支持 postgresql、mysql 和 redis
+		Host     string `yaml:"host"`     // Host 是管理连接和握手校验的主机，默认 127.0.0.1
+		Port     int    `yaml:"port"`     // Port 是管理连接端口，默认 5432、3306 或 6379
+		TLSPort  int    `yaml:"tlsPort"`  // TLSPort 是 Redis tls-port，默认与 port 相同；PostgreSQL 和 MySQL 在同一端口协商 TLS
+		Username string `yaml:"username"` // Username 是执行重新加载的账号，默认 postgres、root，Redis 留空时使用 default 用户
+		Password string `yaml:"password"` // Password 是管理连接密码
+		CertFile string `yaml:"certFile"` // CertFile 是服务端配置引用的证书文件绝对路径
+		KeyFile  string `yaml:"keyFile"`  // KeyFile 是服务端配置引用的私钥文件绝对路径
+		Owner    string `yaml:"owner"`    // Owner 是证书和私钥所属用户名或 UID，默认 postgres、mysql 或 redis
+		Group    string `yaml:"group"`    // Group 是证书和私钥所属组名或 GID，留空时不修改
+		Binary   string `yaml:"binary"`   // Binary 是 PostgreSQL 的 psql 或 MySQL 的 mysql 客户端，默认从 PATH 查找；Redis 直接使用 RESP 协议
+	}
+
+	// S3ServerConfig S3 兼容对象存储服务的证书目录、文件命名和重启配置，填写 host 时通过 SSH 部署到远程主机。
+	S3ServerConfig struct {
+		Name                   string                                  `yaml:"name"`               // Name 是实例名称，只能包含字母、数字、下划线和连字符
+		Profile                string                                  `yaml:"profile"`            // Profile 是文件命名约定，支持 minio、rustfs 和 custom，默认 minio
+		Path                   string                                  `yaml:"path"`               // Path 是服务的证书根目录，文件发布到 path/<域名>/
+		AdminURL               string                                  `yaml:"adminURL"`           // AdminURL 是 MinIO 服务地址，配置后通过管理 API 重启服务
+		AccessKey              string                                  `yaml:"accessKey"`          // AccessKey 是有 admin:ServiceRestart 权限的访问密钥
+		SecretKey              string                                  `yaml:"secretKey"`          // SecretKey 是访问密钥对应的私密密钥
+		Region                 string                                  `yaml:"region"`             // Region 是签名使用的区域，默认 us-east-1
+		InsecureSkipVerify     bool                                    `yaml:"insecureSkipVerify"` // InsecureSkipVerify 为 true 时跳过管理 API 的 HTTPS 证书校验
+		Sudo                   bool                                    `yaml:"sudo"`               // Sudo 为 true 时 SSH 非 root 用户通过 sudo 执行重启命令
+		ReloadCommands         []LocalCommandConfig                    `yaml:"reloadCommands"`     // ReloadCommands 在发布后执行，失败时恢复旧证书目录并重新执行
+		SSHConfig              `yaml:",inline" mapstructure:",squash"` // SSHConfig 是可选的 SSH 远程连接配置
+		CertificateFilesConfig `yaml:",inline" mapstructure:",squash"` // CertificateFilesConfig 是文件名、权限和属主配置，文件名默认取自 profile
+	}
+
+	// MediaServerConfig Jellyfin、Emby 或 Plex 的 PFX 证书文件和 HTTP API 配置。
+	MediaServerConfig struct {
+		Name               string `yaml:"name"`               // Name 是实例名称，只能包含字母、数字、下划线和连字符
+		Kind               string `yaml:"kind"`               // Kind 是媒体服务器类型，支持 jellyfin、emby 和 plex
+		URL                string `yaml:"url"`                // URL 是 HTTP API 地址，默认 http://127.0.0.1:8096，Plex 默认 http://127.0.0.1:32400
+		APIKey             string `yaml:"apiKey"`             // APIKey 是 Jellyfin 或 Emby 的 API 密钥，Plex 填写 X-Plex-Token
+		PFXFile            string `yaml:"pfxFile"`            // PFXFile 是服务器配置引用的 PKCS#12 文件绝对路径
+		Password           string `yaml:"password"`           // Password 是 PFX 密码，需与服务器中配置的一致，可以为空
+		Owner              string `yaml:"owner"`              // Owner 是 PFX 文件所属用户名或 UID，默认 jellyfin、emby 或 plex
+		Group              string `yaml:"group"`              // Group 是 PFX 文件所属组名或 GID，留空时不修改
+		HTTPSPort          int    `yaml:"httpsPort"`          // HTTPSPort 是校验新证书的 HTTPS 端口，默认 8920，Plex 默认 32400
+		InsecureSkipVerify bool   `yaml:"insecureSkipVerify"` // InsecureSkipVerify 为 true 时跳过 HTTPS API 地址的证书校验
+	}
+
+	// FirewallConfig OPNsense 或 pfSense 防火墙 REST API 配置。
+	FirewallConfig struct {
+		Name               string `yaml:"name"`               // Name 是防火墙名称，只能包含字母、数字、下划线和连字符
+		Kind               string `yaml:"kind"`               // Kind 是防火墙类型，支持 opnsense 和 pfsense
+		URL                string `yaml:"url"`                // URL 是防火墙管理端地址
+		APIKey             string `yaml:"apiKey"`             // APIKey 是 OPNsense API key 或 pfSense REST API 包的密钥
+		APISecret          string `yaml:"apiSecret"`          // APISecret 是 OPNsense API secret，pfSense 不使用
+		WebGUI             bool   `yaml:"webGui"`             // WebGUI 为 true 时把 Web 管理界面切换到新证书，仅支持 pfSense
+		HAProxy            bool   `yaml:"haproxy"`            // HAProxy 为 true 时把引用旧证书的 HAProxy 插件前端切换到新证书
+		InsecureSkipVerify bool   `yaml:"insecureSkipVerify"` // InsecureSkipVerify 仅用于显式信任自签名 HTTPS 证书
+	}
+
 	// SafeLineConfig 雷池 WAF OpenAPI 配置。
 	SafeLineConfig struct {
-		URL                string `yaml:"url"`                // URL 是雷池管理端地址
-		APIToken           string `yaml:"apiToken"`           // APIToken 是通用设置中生成的 API Token
+		Name                     string `yaml:"name"`                     // Name 是 safeLines 中的实例名称，只能包含字母、数字、下划线和连字符
+		URL                      string `yaml:"url"`                      // URL 是雷池管理端地址
+		APIToken                 string `yaml:"apiToken"`                 // APIToken 是通用设置中生成的 API Token
+		InsecureSkipVerify       bool   `yaml:"insecureSkipVerify"`       // InsecureSkipVerify 仅用于显式信任自签名 HTTPS 证书
+		RemoveUnusedCertificates bool   `yaml:"removeUnusedCertificates"` // RemoveUnusedCertificates 表示站点部署换绑后删除不再被任何站点引用的旧证书
+	}
+
+	// NginxProxyManagerConfig Nginx Proxy Manager 管理端 API 配置。
+	NginxProxyManagerConfig struct {
+		URL                string `yaml:"url"`                // URL 是管理端地址，例如 http://npm.lan:81
+		Email              string `yaml:"email"`              // Email 是管理员登录邮箱
+		Password           string `yaml:"password"`           // Password 是管理员登录密码
 		InsecureSkipVerify bool   `yaml:"insecureSkipVerify"` // InsecureSkipVerify 仅用于显式信任自签名 HTTPS 证书
 	}
 
+	// CaddyConfig Caddy 证书目录和管理 API 配置。
+	CaddyConfig struct {
+		Path       string `yaml:"path"`       // Path 是 Caddy 读取证书文件的根目录
+		AdminURL   string `yaml:"adminUrl"`   // AdminURL 是 Caddy 管理 API 地址，默认 http://localhost:2019
+		ConfigFile string `yaml:"configFile"` // ConfigFile 是可选的 caddy validate 配置文件，留空时校验管理 API 当前配置
+	}
+
+	// HAProxyConfig HAProxy 合并证书目录和 Runtime API 配置。
+	HAProxyConfig struct {
+		Path          string `yaml:"path"`          // Path 是 HAProxy 读取合并 PEM 证书的根目录
+		ConfigFile    string `yaml:"configFile"`    // ConfigFile 是 haproxy -c 校验的配置文件，默认 /etc/haproxy/haproxy.cfg
+		RuntimeSocket string `yaml:"runtimeSocket"` // RuntimeSocket 是可选的 stats socket 路径，配置后通过 Runtime API 热替换证书
+	}
+
+	// MailConfig Postfix 与 Dovecot 邮件服务证书目录和握手校验配置。
+	MailConfig struct {
+		Path        string   `yaml:"path"`        // Path 是证书根目录，文件发布到 path/<域名>/
+		Services    []string `yaml:"services"`    // Services 是需要校验和重新加载的服务，支持 postfix 和 dovecot，默认两者都启用
+		VerifyHost  string   `yaml:"verifyHost"`  // VerifyHost 是重新加载后握手校验连接的主机，默认 localhost
+		VerifyPorts []int    `yaml:"verifyPorts"` // VerifyPorts 是握手校验的端口，支持 25、465、587、993、995，默认按服务取 25、587 和 993
+	}
+
+	// DockerConfig Docker Engine API 容器证书部署配置。
+	DockerConfig struct {
+		Socket  string `yaml:"socket"`  // Socket 是 Docker Engine API 的 Unix socket 路径，默认 /var/run/docker.sock
+		Path    string `yaml:"path"`    // Path 是容器 bind mount 的宿主机证书根目录，文件发布到 path/<域名>/
+		Label   string `yaml:"label"`   // Label 是标记容器证书域名的标签键，默认 anssl.domain
+		Action  string `yaml:"action"`  // Action 是证书发布后对容器的操作，支持 signal 和 restart，默认 signal
+		Signal  string `yaml:"signal"`  // Signal 是 action 为 signal 时发送的信号，默认 SIGHUP
+		Timeout int    `yaml:"timeout"` // Timeout 是 action 为 restart 时等待容器停止的秒数，默认 10
+	}
+
+	// TraefikConfig Traefik 文件 provider 证书目录配置。
+	TraefikConfig struct {
+		Path string `yaml:"path"` // Path 是证书目录和 anssl-tls.yml 动态配置所在目录
+	}
+
+	// KubernetesConfig Kubernetes TLS Secret 部署配置。
+	KubernetesConfig struct {
+		Kubeconfig string   `yaml:"kubeconfig"` // Kubeconfig 是 kubeconfig 绝对路径，留空时使用 Pod 内 ServiceAccount 凭据
+		Context    string   `yaml:"context"`    // Context 是 kubeconfig 中使用的上下文，留空时使用 current-context
+		Namespaces []string `yaml:"namespaces"` // Namespaces 是发现 kubernetes.io/tls Secret 的命名空间，留空时使用凭据默认命名空间
+		Secrets    []string `yaml:"secrets"`    // Secrets 是 namespace/name 形式的目标 Secret，不存在时部署会创建
+	}
+
+	// JavaKeystoreConfig Java 密钥库输出目录、别名和密码来源配置。
+	JavaKeystoreConfig struct {
+		Path           string `yaml:"path"`           // Path 是密钥库根目录，文件发布到 path/<域名>/keystore.p12
+		Alias          string `yaml:"alias"`          // Alias 是密钥条目别名，默认 anssl
+		Password       string `yaml:"password"`       // Password 是直接配置的密钥库密码
+		PasswordFile   string `yaml:"passwordFile"`   // PasswordFile 是保存密钥库密码的文件绝对路径
+		PasswordEnv    string `yaml:"passwordEnv"`    // PasswordEnv 是保存密钥库密码的环境变量名
+		JKS            bool   `yaml:"jks"`            // JKS 为 true 时额外生成旧版 keystore.jks
+		RestartCommand string `yaml:"restartCommand"` // RestartCommand 是发布后执行的可选重启或重载命令，最长执行 45 秒，失败时恢复旧密钥库
+	}
+
+	// LocalTargetConfig 本地目录与自定义命令部署实例配置。
+	LocalTargetConfig struct {
+		Name                   string                                  `yaml:"name"`         // Name 是实例名称，只能包含字母、数字、下划线和连字符
+		Path                   string                                  `yaml:"path"`         // Path 是证书根目录，文件发布到 path/<域名>/
+		PreCommands            []LocalCommandConfig                    `yaml:"preCommands"`  // PreCommands 在发布前针对临时目录执行，失败时不改动目标目录
+		PostCommands           []LocalCommandConfig                    `yaml:"postCommands"` // PostCommands 在发布后执行，任一失败时恢复旧证书目录
+		CertificateFilesConfig `yaml:",inline" mapstructure:",squash"` // CertificateFilesConfig 是文件名、权限和属主配置
+	}
+
+	// SSHTargetConfig 通用 SSH 远程主机部署实例配置。
+	SSHTargetConfig struct {
+		Name                   string                                  `yaml:"name"`             // Name 是实例名称，只能包含字母、数字、下划线和连字符
+		Path                   string                                  `yaml:"path"`             // Path 是远程证书根目录，文件发布到 path/<域名>/
+		Sudo                   bool                                    `yaml:"sudo"`             // Sudo 为 true 时非 root 用户通过 sudo 执行校验和重载命令
+		ValidateCommands       []LocalCommandConfig                    `yaml:"validateCommands"` // ValidateCommands 在发布后、重载前执行，失败时恢复旧证书目录
+		ReloadCommands         []LocalCommandConfig                    `yaml:"reloadCommands"`   // ReloadCommands 在校验通过后执行，失败时恢复旧证书目录并重新执行
+		SSHConfig              `yaml:",inline" mapstructure:",squash"` // SSHConfig 是远程主机连接和认证配置
+		CertificateFilesConfig `yaml:",inline" mapstructure:",squash"` // CertificateFilesConfig 是文件名、权限和属主配置
+	}
+
+	// CertificateFilesConfig 证书和私钥文件的名称、权限和属主配置。
+	CertificateFilesConfig struct {
+		CertFile string `yaml:"certFile"` // CertFile 是完整证书链文件名，默认 cert.pem
+		KeyFile  string `yaml:"keyFile"`  // KeyFile 是私钥文件名，默认 privateKey.key
+		CertMode string `yaml:"certMode"` // CertMode 是证书文件的八进制权限，默认 0644
+		KeyMode  string `yaml:"keyMode"`  // KeyMode 是私钥文件的八进制权限，默认 0600
+		Owner    string `yaml:"owner"`    // Owner 是发布后文件所属用户名或 UID，留空时不修改
+		Group    string `yaml:"group"`    // Group 是发布后文件所属组名或 GID，留空时不修改
+	}
+
+	// LocalCommandConfig 部署实例执行的单条校验或重载命令。
+	LocalCommandConfig struct {
+		Command string `yaml:"command"` // Command 通过 /bin/sh -c 执行
+		Timeout int    `yaml:"timeout"` // Timeout 是命令超时秒数，默认 60，最大 3600
+	}
+
 	// UpdateConfig 自更新下载源和代理配置
 	UpdateConfig struct {
 		// 镜像源类型: github, ghproxy, custom
@@ -219,12 +506,72 @@ func validateConfig(configuration *Configuration) error {
 	if err := validateFeiNiuConfig(configuration.SSL); err != nil {
 		return err
 	}
+	if err := validateFeiNiuDevicesConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateOpenVPNASConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateOnePanelConfig(configuration.SSL); err != nil {
+		return err
+	}
 	if err := validateBTPanelConfig(configuration.SSL); err != nil {
 		return err
 	}
 	if err := validateSafeLineConfig(configuration.SSL); err != nil {
 		return err
 	}
+	if err := validateNginxProxyManagerConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateCaddyConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateHAProxyConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateMailConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateDockerConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateTraefikConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateKubernetesConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateJavaKeystoreConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateLocalTargetsConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateSSHTargetsConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateSynologyConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateProxmoxConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateFirewallsConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateProxyServersConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateDatabasesConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateS3ServersConfig(configuration.SSL); err != nil {
+		return err
+	}
+	if err := validateMediaServersConfig(configuration.SSL); err != nil {
+		return err
+	}
 
 	if configuration.Server.Env != "" && configuration.Server.Env != envLocal {
 		return fmt.Errorf("不支持的服务环境: %s (支持: 空值, local)", configuration.Server.Env)
@@ -278,205 +625,1400 @@ func validateFeiNiuConfig(sslConfig *DeployConfig) error {
 	return validateSSHConfig("ssl.feiNiu", sslConfig.FeiNiu)
 }
 
-// validateBTPanelConfig 验证可选的宝塔面板地址和 API 密钥，并规范化管理端地址。
-func validateBTPanelConfig(sslConfig *DeployConfig) error {
-	if sslConfig.BTPanel == nil {
+// validateFeiNiuDevicesConfig 验证飞牛 OS 设备名称唯一和 SSH 连接配置完整。
+func validateFeiNiuDevicesConfig(sslConfig *DeployConfig) error {
+	names := make(map[string]struct{}, len(sslConfig.FeiNiuDevices))
+	for index, device := range sslConfig.FeiNiuDevices {
+		if device == nil {
+			return fmt.Errorf("ssl.feiNiuDevices[%d] 不能为空", index)
+		}
+		device.Name = strings.TrimSpace(device.Name)
+		if !isLocalTargetName(device.Name) {
+			return fmt.Errorf("ssl.feiNiuDevices[%d].name 只能包含字母、数字、下划线和连字符，且长度不能超过 64: %q", index, device.Name)
+		}
+		if _, exists := names[device.Name]; exists {
+			return fmt.Errorf("ssl.feiNiuDevices.name 不能重复: %s", device.Name)
+		}
+		names[device.Name] = struct{}{}
+		if err := validateSSHConfig("ssl.feiNiuDevices["+device.Name+"]", &device.SSHConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateOpenVPNASConfig 验证 OpenVPN-AS sacli 路径和可选的 SSH 远程部署配置。
+func validateOpenVPNASConfig(sslConfig *DeployConfig) error {
+	openVPNAS := sslConfig.OpenVPNAS
+	if openVPNAS == nil {
+		return nil
+	}
+	openVPNAS.SacliPath = strings.TrimSpace(openVPNAS.SacliPath)
+	if openVPNAS.SacliPath != "" && (!path.IsAbs(openVPNAS.SacliPath) || path.Clean(openVPNAS.SacliPath) != openVPNAS.SacliPath) {
+		return errors.New("ssl.openVPNAS.sacliPath 必须是规范的绝对路径")
+	}
+	if !IsSSHConfigured(&openVPNAS.SSHConfig) {
 		return nil
 	}
+	return validateSSHConfig("ssl.openVPNAS", &openVPNAS.SSHConfig)
+}
+
+// validateOnePanelConfig 验证兼容的单实例 1Panel 和具名 1Panel 实例列表。
+func validateOnePanelConfig(sslConfig *DeployConfig) error {
+	var legacy *panelInstanceFields
+	if panel := sslConfig.OnePanel; panel != nil {
+		legacy = &panelInstanceFields{name: &panel.Name, url: &panel.URL, credential: &panel.APIKey, credentialField: "apiKey", insecureSkipVerify: panel.InsecureSkipVerify}
+	}
+	instances := make([]*panelInstanceFields, len(sslConfig.OnePanels))
+	for index, panel := range sslConfig.OnePanels {
+		if panel != nil {
+			instances[index] = &panelInstanceFields{name: &panel.Name, url: &panel.URL, credential: &panel.APIKey, credentialField: "apiKey", insecureSkipVerify: panel.InsecureSkipVerify}
+		}
+	}
+	return validatePanelInstances("onePanel", "1Panel 地址和 API 密钥", legacy, instances)
+}
+
+// validateBTPanelConfig 验证兼容的单实例宝塔面板和具名宝塔面板实例列表。
+func validateBTPanelConfig(sslConfig *DeployConfig) error {
+	var legacy *panelInstanceFields
+	if panel := sslConfig.BTPanel; panel != nil {
+		legacy = &panelInstanceFields{name: &panel.Name, url: &panel.URL, credential: &panel.APIKey, credentialField: "apiKey", insecureSkipVerify: panel.InsecureSkipVerify}
+	}
+	instances := make([]*panelInstanceFields, len(sslConfig.BTPanels))
+	for index, panel := range sslConfig.BTPanels {
+		if panel != nil {
+			instances[index] = &panelInstanceFields{name: &panel.Name, url: &panel.URL, credential: &panel.APIKey, credentialField: "apiKey", insecureSkipVerify: panel.InsecureSkipVerify}
+		}
+	}
+	return validatePanelInstances("btPanel", "宝塔面板地址和 API 密钥", legacy, instances)
+}
+
+// validateSafeLineConfig 验证兼容的单实例雷池和具名雷池实例列表。
+func validateSafeLineConfig(sslConfig *DeployConfig) error {
+	var legacy *panelInstanceFields
+	if safeLine := sslConfig.SafeLine; safeLine != nil {
+		legacy = &panelInstanceFields{name: &safeLine.Name, url: &safeLine.URL, credential: &safeLine.APIToken, credentialField: "apiToken", insecureSkipVerify: safeLine.InsecureSkipVerify}
+	}
+	instances := make([]*panelInstanceFields, len(sslConfig.SafeLines))
+	for index, safeLine := range sslConfig.SafeLines {
+		if safeLine != nil {
+			instances[index] = &panelInstanceFields{name: &safeLine.Name, url: &safeLine.URL, credential: &safeLine.APIToken, credentialField: "apiToken", insecureSkipVerify: safeLine.InsecureSkipVerify}
+		}
+	}
+	return validatePanelInstances("safeLine", "雷池地址和 API Token", legacy, instances)
+}
+
+// panelInstanceFields 引用面板实例中需要统一校验和规范化的字段。
+type panelInstanceFields struct {
+	name               *string // name 是实例名称，单实例节点必须留空
+	url                *string // url 是面板管理端地址
+	credential         *string // credential 是 API 密钥或 Token
+	credentialField    string  // credentialField 是凭据在 YAML 中的字段名
+	insecureSkipVerify bool    // insecureSkipVerify 表示显式信任自签名 HTTPS 证书
+}
+
+// validatePanelInstances 验证单实例节点 ssl.<section> 和具名列表 ssl.<section>s，列表中的实例名称必须唯一。
+func validatePanelInstances(section, credentialLabel string, legacy *panelInstanceFields, instances []*panelInstanceFields) error {
+	if legacy != nil {
+		field := "ssl." + section
+		if strings.TrimSpace(*legacy.name) != "" {
+			return fmt.Errorf("%s.name 仅适用于 ssl.%ss 列表", field, section)
+		}
+		*legacy.name = ""
+		if err := validatePanelInstance(field, credentialLabel, legacy, false); err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]struct{}, len(instances))
+	for index, instance := range instances {
+		if instance == nil {
+			return fmt.Errorf("ssl.%ss[%d] 不能为空", section, index)
+		}
+		*instance.name = strings.TrimSpace(*instance.name)
+		if !isLocalTargetName(*instance.name) {
+			return fmt.Errorf("ssl.%ss[%d].name 只能包含字母、数字、下划线和连字符，且长度不能超过 64: %q", section, index, *instance.name)
+		}
+		if _, exists := names[*instance.name]; exists {
+			return fmt.Errorf("ssl.%ss.name 不能重复: %s", section, *instance.name)
+		}
+		names[*instance.name] = struct{}{}
+		if err := validatePanelInstance("ssl."+section+"s["+*instance.name+"]", credentialLabel, instance, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	btPanel := sslConfig.BTPanel
-	btPanel.URL = strings.TrimRight(strings.TrimSpace(btPanel.URL), "/")
-	btPanel.APIKey = strings.TrimSpace(btPanel.APIKey)
-	if btPanel.URL == "" && btPanel.APIKey == "" {
-		if btPanel.InsecureSkipVerify {
-			return errors.New("ssl.btPanel.insecureSkipVerify 只能在配置宝塔面板地址和 API 密钥后启用")
+// validatePanelInstance 验证单个面板实例的地址和 API 凭据，并规范化管理端地址；required 为 false 时允许两者同时留空。
+func validatePanelInstance(field, credentialLabel string, instance *panelInstanceFields, required bool) error {
+	*instance.url = strings.TrimRight(strings.TrimSpace(*instance.url), "/")
+	*instance.credential = strings.TrimSpace(*instance.credential)
+	if !required && *instance.url == "" && *instance.credential == "" {
+		if instance.insecureSkipVerify {
+			return fmt.Errorf("%s.insecureSkipVerify 只能在配置%s后启用", field, credentialLabel)
 		}
 		return nil
 	}
-	if btPanel.URL == "" {
-		return errors.New("ssl.btPanel.url 不能为空")
+	if *instance.url == "" {
+		return fmt.Errorf("%s.url 不能为空", field)
 	}
-	if btPanel.APIKey == "" {
-		return errors.New("ssl.btPanel.apiKey 不能为空")
+	if *instance.credential == "" {
+		return fmt.Errorf("%s.%s 不能为空", field, instance.credentialField)
 	}
-	if strings.ContainsAny(btPanel.APIKey, "\r\n\x00") {
-		return errors.New("ssl.btPanel.apiKey 不能包含换行或 NUL 字符")
+	if strings.ContainsAny(*instance.credential, "\r\n\x00") {
+		return fmt.Errorf("%s.%s 不能包含换行或 NUL 字符", field, instance.credentialField)
 	}
 
-	parsedURL, err := url.Parse(btPanel.URL)
+	parsedURL, err := url.Parse(*instance.url)
 	if err != nil || parsedURL.Hostname() == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-		return errors.New("ssl.btPanel.url 必须是合法的 HTTP 或 HTTPS 地址")
+		return fmt.Errorf("%s.url 必须是合法的 HTTP 或 HTTPS 地址", field)
 	}
 	if parsedURL.User != nil || parsedURL.RawQuery != "" || parsedURL.Fragment != "" {
-		return errors.New("ssl.btPanel.url 不能包含用户凭据、查询参数或片段")
+		return fmt.Errorf("%s.url 不能包含用户凭据、查询参数或片段", field)
 	}
-	if btPanel.InsecureSkipVerify && parsedURL.Scheme != "https" {
-		return errors.New("ssl.btPanel.insecureSkipVerify 仅适用于 HTTPS 地址")
+	if instance.insecureSkipVerify && parsedURL.Scheme != "https" {
+		return fmt.Errorf("%s.insecureSkipVerify 仅适用于 HTTPS 地址", field)
 	}
 	return nil
 }
 
-// validateSafeLineConfig 验证可选的雷池地址和 API Token，并规范化管理端地址。
-func validateSafeLineConfig(sslConfig *DeployConfig) error {
-	if sslConfig.SafeLine == nil {
+// OnePanelInstances 返回已配置地址和密钥的单实例 1Panel 与全部具名 1Panel 实例，单实例排在最前。
+func OnePanelInstances(sslConfig *DeployConfig) []*OnePanelConfig {
+	if sslConfig == nil {
+		return nil
+	}
+	instances := make([]*OnePanelConfig, 0, len(sslConfig.OnePanels)+1)
+	if panel := sslConfig.OnePanel; panel != nil && strings.TrimSpace(panel.URL) != "" && strings.TrimSpace(panel.APIKey) != "" {
+		instances = append(instances, panel)
+	}
+	for _, panel := range sslConfig.OnePanels {
+		if panel != nil {
+			instances = append(instances, panel)
+		}
+	}
+	return instances
+}
+
+// BTPanelInstances 返回已配置地址和密钥的单实例宝塔面板与全部具名宝塔面板实例，单实例排在最前。
+func BTPanelInstances(sslConfig *DeployConfig) []*BTPanelConfig {
+	if sslConfig == nil {
+		return nil
+	}
+	instances := make([]*BTPanelConfig, 0, len(sslConfig.BTPanels)+1)
+	if panel := sslConfig.BTPanel; panel != nil && strings.TrimSpace(panel.URL) != "" && strings.TrimSpace(panel.APIKey) != "" {
+		instances = append(instances, panel)
+	}
+	for _, panel := range sslConfig.BTPanels {
+		if panel != nil {
+			instances = append(instances, panel)
+		}
+	}
+	return instances
+}
+
+// SafeLineInstances 返回已配置地址和 Token 的单实例雷池与全部具名雷池实例，单实例排在最前。
+func SafeLineInstances(sslConfig *DeployConfig) []*SafeLineConfig {
+	if sslConfig == nil {
+		return nil
+	}
+	instances := make([]*SafeLineConfig, 0, len(sslConfig.SafeLines)+1)
+	if safeLine := sslConfig.SafeLine; safeLine != nil && strings.TrimSpace(safeLine.URL) != "" && strings.TrimSpace(safeLine.APIToken) != "" {
+		instances = append(instances, safeLine)
+	}
+	for _, safeLine := range sslConfig.SafeLines {
+		if safeLine != nil {
+			instances = append(instances, safeLine)
+		}
+	}
+	return instances
+}
+
+// validateNginxProxyManagerConfig 验证可选的 Nginx Proxy Manager 地址和登录凭据，并规范化管理端地址。
+func validateNginxProxyManagerConfig(sslConfig *DeployConfig) error {
+	if sslConfig.NginxProxyManager == nil {
 		return nil
 	}
 
-	safeLine := sslConfig.SafeLine
-	safeLine.URL = strings.TrimRight(strings.TrimSpace(safeLine.URL), "/")
-	safeLine.APIToken = strings.TrimSpace(safeLine.APIToken)
-	if safeLine.URL == "" && safeLine.APIToken == "" {
-		if safeLine.InsecureSkipVerify {
-			return errors.New("ssl.safeLine.insecureSkipVerify 只能在配置雷池地址和 API Token 后启用")
+	npm := sslConfig.NginxProxyManager
+	npm.URL = strings.TrimRight(strings.TrimSpace(npm.URL), "/")
+	npm.Email = strings.TrimSpace(npm.Email)
+	if npm.URL == "" && npm.Email == "" && npm.Password == "" {
+		if npm.InsecureSkipVerify {
+			return errors.New("ssl.nginxProxyManager.insecureSkipVerify 只能在配置管理端地址和登录凭据后启用")
 		}
 		return nil
 	}
-	if safeLine.URL == "" {
-		return errors.New("ssl.safeLine.url 不能为空")
+	if npm.URL == "" {
+		return errors.New("ssl.nginxProxyManager.url 不能为空")
 	}
-	if safeLine.APIToken == "" {
-		return errors.New("ssl.safeLine.apiToken 不能为空")
+	if npm.Email == "" {
+		return errors.New("ssl.nginxProxyManager.email 不能为空")
 	}
-	if strings.ContainsAny(safeLine.APIToken, "\r\n\x00") {
-		return errors.New("ssl.safeLine.apiToken 不能包含换行或 NUL 字符")
+	if npm.Password == "" {
+		return errors.New("ssl.nginxProxyManager.password 不能为空")
+	}
+	if strings.ContainsAny(npm.Email+npm.Password, "\r\n\x00") {
+		return errors.New("ssl.nginxProxyManager 登录凭据不能包含换行或 NUL 字符")
 	}
 
-	parsedURL, err := url.Parse(safeLine.URL)
+	parsedURL, err := url.Parse(npm.URL)
 	if err != nil || parsedURL.Hostname() == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-		return errors.New("ssl.safeLine.url 必须是合法的 HTTP 或 HTTPS 地址")
+		return errors.New("ssl.nginxProxyManager.url 必须是合法的 HTTP 或 HTTPS 地址")
 	}
 	if parsedURL.User != nil || parsedURL.RawQuery != "" || parsedURL.Fragment != "" {
-		return errors.New("ssl.safeLine.url 不能包含用户凭据、查询参数或片段")
+		return errors.New("ssl.nginxProxyManager.url 不能包含用户凭据、查询参数或片段")
 	}
-	if safeLine.InsecureSkipVerify && parsedURL.Scheme != "https" {
-		return errors.New("ssl.safeLine.insecureSkipVerify 仅适用于 HTTPS 地址")
+	if npm.InsecureSkipVerify && parsedURL.Scheme != "https" {
+		return errors.New("ssl.nginxProxyManager.insecureSkipVerify 仅适用于 HTTPS 地址")
 	}
 	return nil
 }
 
-// validateRustFSConfig 归一化 RustFS 新旧配置并验证本机或 SSH 远程模式。
-func validateRustFSConfig(sslConfig *DeployConfig) error {
-	legacyPath := strings.TrimSpace(sslConfig.RustFSPath)
-	if sslConfig.RustFS == nil {
-		if legacyPath == "" {
-			return nil
-		}
-		sslConfig.RustFS = &RustFSConfig{Path: legacyPath}
-		sslConfig.RustFSPath = ""
-		return validateRustFSPath(sslConfig.RustFS.Path)
+// validateCaddyConfig 验证可选的 Caddy 证书目录和本机管理 API 地址。
+func validateCaddyConfig(sslConfig *DeployConfig) error {
+	if sslConfig.Caddy == nil {
+		return nil
 	}
 
-	rustFS := sslConfig.RustFS
-	rustFS.Path = strings.TrimSpace(rustFS.Path)
-	if legacyPath != "" && rustFS.Path != "" && legacyPath != rustFS.Path {
-		return errors.New("ssl.rustFS.path 与旧版 ssl.rustFSPath 不能同时配置为不同目录")
+	caddy := sslConfig.Caddy
+	caddy.Path = strings.TrimSpace(caddy.Path)
+	caddy.AdminURL = strings.TrimRight(strings.TrimSpace(caddy.AdminURL), "/")
+	caddy.ConfigFile = strings.TrimSpace(caddy.ConfigFile)
+	if caddy.Path == "" {
+		return errors.New("ssl.caddy.path 不能为空")
 	}
-	if rustFS.Path == "" {
-		rustFS.Path = legacyPath
+	if !filepath.IsAbs(caddy.Path) || filepath.Clean(caddy.Path) != caddy.Path || caddy.Path == "/" {
+		return errors.New("ssl.caddy.path 必须是非根目录的规范绝对路径")
 	}
-	sslConfig.RustFSPath = ""
-	if rustFS.Path == "" {
-		return errors.New("ssl.rustFS.path 不能为空")
+	if caddy.AdminURL == "" {
+		caddy.AdminURL = defaultCaddyAdminURL
 	}
-	if err := validateRustFSPath(rustFS.Path); err != nil {
-		return err
+	parsedURL, err := url.Parse(caddy.AdminURL)
+	if err != nil || parsedURL.Hostname() == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return errors.New("ssl.caddy.adminUrl 必须是合法的 HTTP 或 HTTPS 地址")
 	}
-	if !IsSSHConfigured(&rustFS.SSHConfig) {
-		return nil
+	if parsedURL.User != nil || parsedURL.RawQuery != "" || parsedURL.Fragment != "" || (parsedURL.Path != "" && parsedURL.Path != "/") {
+		return errors.New("ssl.caddy.adminUrl 不能包含用户凭据、路径、查询参数或片段")
 	}
-	return validateSSHConfig("ssl.rustFS", &rustFS.SSHConfig)
+	if caddy.ConfigFile != "" && (!filepath.IsAbs(caddy.ConfigFile) || strings.ContainsAny(caddy.ConfigFile, "\r\n\x00")) {
+		return errors.New("ssl.caddy.configFile 必须是不含换行或 NUL 字符的绝对路径")
+	}
+	return nil
 }
 
-// validateRustFSPath 验证 RustFS 证书根目录是安全的 POSIX 绝对路径。
-func validateRustFSPath(rustFSPath string) error {
-	if !path.IsAbs(rustFSPath) || path.Clean(rustFSPath) != rustFSPath || rustFSPath == "/" {
-		return errors.New("ssl.rustFS.path 必须是非根目录的规范 POSIX 绝对路径")
+// validateHAProxyConfig 验证可选的 HAProxy 证书目录、配置文件和 Runtime API 套接字路径。
+func validateHAProxyConfig(sslConfig *DeployConfig) error {
+	if sslConfig.HAProxy == nil {
+		return nil
 	}
-	if strings.ContainsAny(rustFSPath, "\r\n\x00") {
-		return errors.New("ssl.rustFS.path 不能包含换行或 NUL 字符")
+
+	haproxy := sslConfig.HAProxy
+	haproxy.Path = strings.TrimSpace(haproxy.Path)
+	haproxy.ConfigFile = strings.TrimSpace(haproxy.ConfigFile)
+	haproxy.RuntimeSocket = strings.TrimSpace(haproxy.RuntimeSocket)
+	if haproxy.Path == "" {
+		return errors.New("ssl.haproxy.path 不能为空")
+	}
+	if !filepath.IsAbs(haproxy.Path) || filepath.Clean(haproxy.Path) != haproxy.Path || haproxy.Path == "/" {
+		return errors.New("ssl.haproxy.path 必须是非根目录的规范绝对路径")
+	}
+	// Runtime API 命令按行解析，证书路径中的空白会破坏命令边界。
+	if strings.ContainsAny(haproxy.Path, " \t\r\n\x00") {
+		return errors.New("ssl.haproxy.path 不能包含空白或 NUL 字符")
+	}
+	if haproxy.ConfigFile == "" {
+		haproxy.ConfigFile = defaultHAProxyConfigFile
+	}
+	if !filepath.IsAbs(haproxy.ConfigFile) || strings.ContainsAny(haproxy.ConfigFile, "\r\n\x00") {
+		return errors.New("ssl.haproxy.configFile 必须是不含换行或 NUL 字符的绝对路径")
+	}
+	if haproxy.RuntimeSocket != "" && (!filepath.IsAbs(haproxy.RuntimeSocket) || strings.ContainsAny(haproxy.RuntimeSocket, "\r\n\x00")) {
+		return errors.New("ssl.haproxy.runtimeSocket 必须是不含换行或 NUL 字符的绝对路径")
 	}
 	return nil
 }
 
-// IsSSHConfigured 判断除默认端口外是否已经填写任一 SSH 字段。
-func IsSSHConfigured(sshConfig *SSHConfig) bool {
-	if sshConfig == nil {
-		return false
-	}
-	return strings.TrimSpace(sshConfig.Host) != "" ||
-		strings.TrimSpace(sshConfig.Username) != "" ||
-		sshConfig.Password != "" ||
-		strings.TrimSpace(sshConfig.PrivateKeyPath) != "" ||
-		sshConfig.PrivateKeyPassphrase != "" ||
-		(sshConfig.Port != 0 && sshConfig.Port != 22)
+// mailVerifyPortServices 记录每个握手校验端口所属的邮件服务。
+var mailVerifyPortServices = map[int]string{
+	25:  MailServicePostfix,
+	465: MailServicePostfix,
+	587: MailServicePostfix,
+	993: MailServiceDovecot,
+	995: MailServiceDovecot,
 }
 
-// validateSSHConfig 验证密码或私钥认证共用的 SSH 连接字段。
-func validateSSHConfig(section string, sshConfig *SSHConfig) error {
-	sshConfig.Host = strings.TrimSpace(sshConfig.Host)
-	if strings.HasPrefix(sshConfig.Host, "[") && strings.HasSuffix(sshConfig.Host, "]") {
-		sshConfig.Host = strings.TrimSuffix(strings.TrimPrefix(sshConfig.Host, "["), "]")
-	}
-	sshConfig.Username = strings.TrimSpace(sshConfig.Username)
-	sshConfig.PrivateKeyPath = strings.TrimSpace(sshConfig.PrivateKeyPath)
-	if sshConfig.Port == 0 {
-		sshConfig.Port = 22
+// validateMailConfig 验证可选的邮件服务证书目录、服务列表和握手校验端口，并补齐默认值。
+func validateMailConfig(sslConfig *DeployConfig) error {
+	if sslConfig.Mail == nil {
+		return nil
 	}
-	if sshConfig.Host == "" {
-		return fmt.Errorf("%s.host 不能为空", section)
+
+	mail := sslConfig.Mail
+	mail.Path = strings.TrimSpace(mail.Path)
+	if mail.Path == "" {
+		return errors.New("ssl.mail.path 不能为空")
 	}
-	if strings.Contains(sshConfig.Host, "://") || strings.ContainsAny(sshConfig.Host, "/?#@") || containsSpaceOrControl(sshConfig.Host) {
-		return fmt.Errorf("%s.host 必须是主机名或 IP 地址，不能包含协议、路径或空白字符", section)
+	if !filepath.IsAbs(mail.Path) || filepath.Clean(mail.Path) != mail.Path || mail.Path == "/" {
+		return errors.New("ssl.mail.path 必须是非根目录的规范绝对路径")
 	}
-	if strings.Contains(sshConfig.Host, ":") && net.ParseIP(sshConfig.Host) == nil {
-		return fmt.Errorf("%s.host 不能包含端口，端口请单独填写到 %s.port", section, section)
+	if len(mail.Services) == 0 {
+		mail.Services = []string{MailServicePostfix, MailServiceDovecot}
 	}
-	if sshConfig.Port < minPort || sshConfig.Port > maxPort {
-		return fmt.Errorf("%s.port 必须在 %d-%d 之间", section, minPort, maxPort)
+	services := make(map[string]struct{}, len(mail.Services))
+	for index, service := range mail.Services {
+		service = strings.ToLower(strings.TrimSpace(service))
+		if service != MailServicePostfix && service != MailServiceDovecot {
+			return fmt.Errorf("ssl.mail.services 只支持 %s 和 %s: %q", MailServicePostfix, MailServiceDovecot, service)
+		}
+		if _, exists := services[service]; exists {
+			return fmt.Errorf("ssl.mail.services 不能重复: %s", service)
+		}
+		services[service] = struct{}{}
+		mail.Services[index] = service
 	}
-	if sshConfig.Username == "" || containsSpaceOrControl(sshConfig.Username) {
-		return fmt.Errorf("%s.username 不能为空且不能包含空白或控制字符", section)
+
+	mail.VerifyHost = strings.TrimSpace(mail.VerifyHost)
+	if mail.VerifyHost == "" {
+		mail.VerifyHost = defaultMailVerifyHost
 	}
-	if sshConfig.Password == "" && sshConfig.PrivateKeyPath == "" {
-		return fmt.Errorf("%s.password 或 %s.privateKeyPath 必须填写一项", section, section)
+	if strings.ContainsAny(mail.VerifyHost, " /\\@[]\t\r\n\x00") || strings.Count(mail.VerifyHost, ":") == 1 {
+		return errors.New("ssl.mail.verifyHost 必须是不含端口的主机名或 IP 地址")
 	}
-	if strings.ContainsAny(sshConfig.Password, "\r\n\x00") {
-		return fmt.Errorf("%s.password 不能包含换行或 NUL 字符", section)
+	if len(mail.VerifyPorts) == 0 {
+		for _, port := range []int{25, 587, 993} {
+			if _, enabled := services[mailVerifyPortServices[port]]; enabled {
+				mail.VerifyPorts = append(mail.VerifyPorts, port)
+			}
+		}
 	}
-	if sshConfig.PrivateKeyPath != "" {
-		if !filepath.IsAbs(sshConfig.PrivateKeyPath) || filepath.Clean(sshConfig.PrivateKeyPath) != sshConfig.PrivateKeyPath {
-			return fmt.Errorf("%s.privateKeyPath 必须是 deploy 客户端本地的规范绝对路径", section)
+	ports := make(map[int]struct{}, len(mail.VerifyPorts))
+	for _, port := range mail.VerifyPorts {
+		service, supported := mailVerifyPortServices[port]
+		if !supported {
+			return fmt.Errorf("ssl.mail.verifyPorts 只支持 25、465、587、993 和 995: %d", port)
 		}
-		if strings.ContainsAny(sshConfig.PrivateKeyPath, "\r\n\x00") {
-			return fmt.Errorf("%s.privateKeyPath 不能包含换行或 NUL 字符", section)
+		if _, enabled := services[service]; !enabled {
+			return fmt.Errorf("ssl.mail.verifyPorts 中的 %d 端口需要在 ssl.mail.services 中启用 %s", port, service)
 		}
-	} else if sshConfig.PrivateKeyPassphrase != "" {
-		return fmt.Errorf("%s.privateKeyPassphrase 只能与 privateKeyPath 一起配置", section)
-	}
-	if strings.ContainsAny(sshConfig.PrivateKeyPassphrase, "\r\n\x00") {
-		return fmt.Errorf("%s.privateKeyPassphrase 不能包含换行或 NUL 字符", section)
+		if _, exists := ports[port]; exists {
+			return fmt.Errorf("ssl.mail.verifyPorts 不能重复: %d", port)
+		}
+		ports[port] = struct{}{}
 	}
 	return nil
 }
 
-// containsSpaceOrControl 判断 SSH 标识字段是否含不可接受的空白或控制字符。
-func containsSpaceOrControl(value string) bool {
-	return strings.IndexFunc(value, func(character rune) bool {
-		return unicode.IsSpace(character) || unicode.IsControl(character)
-	}) >= 0
-}
+// validateDockerConfig 验证 Docker socket、bind mount 目录、标签键和发布后操作，并填充默认值。
+func validateDockerConfig(sslConfig *DeployConfig) error {
+	if sslConfig.Docker == nil {
+		return nil
+	}
 
-// validateUpdateURL validates an update mirror URL and permits local HTTP only in local mode.
-func validateUpdateURL(name, rawURL string, allowPath bool, environment string) error {
-	parsed, err := url.Parse(strings.TrimSpace(rawURL))
-	if err != nil || parsed.Hostname() == "" || parsed.User != nil {
-		return fmt.Errorf("%s 必须是包含主机名且不含用户凭据的合法 URL", name)
+	docker := sslConfig.Docker
+	docker.Socket = strings.TrimSpace(docker.Socket)
+	docker.Path = strings.TrimSpace(docker.Path)
+	docker.Label = strings.TrimSpace(docker.Label)
+	docker.Action = strings.ToLower(strings.TrimSpace(docker.Action))
+	docker.Signal = strings.ToUpper(strings.TrimSpace(docker.Signal))
+	if docker.Socket == "" {
+		docker.Socket = defaultDockerSocket
 	}
-	if !allowPath && parsed.RawQuery != "" {
-		return fmt.Errorf("%s 不能包含查询参数", name)
+	if !filepath.IsAbs(docker.Socket) || strings.ContainsAny(docker.Socket, "\r\n\x00") {
+		return errors.New("ssl.docker.socket 必须是不含换行或 NUL 字符的绝对路径")
 	}
-	if parsed.Scheme == "https" {
-		return nil
+	if docker.Path == "" {
+		return errors.New("ssl.docker.path 不能为空")
+	}
+	if !filepath.IsAbs(docker.Path) || filepath.Clean(docker.Path) != docker.Path || docker.Path == "/" {
+		return errors.New("ssl.docker.path 必须是非根目录的规范绝对路径")
+	}
+	if docker.Label == "" {
+		docker.Label = defaultDockerLabel
+	}
+	if !isDockerLabelKey(docker.Label) {
+		return fmt.Errorf("ssl.docker.label 只能包含小写字母、数字、点、下划线和连字符: %q", docker.Label)
+	}
+	if docker.Action == "" {
+		docker.Action = DockerActionSignal
+	}
+	switch docker.Action {
+	case DockerActionSignal:
+		if docker.Signal == "" {
+			docker.Signal = defaultDockerSignal
+		}
+		if !isDockerSignal(docker.Signal) {
+			return fmt.Errorf("ssl.docker.signal 必须是 SIG 开头的信号名称或信号编号: %q", docker.Signal)
+		}
+	case DockerActionRestart:
+		if docker.Signal != "" {
+			return errors.New("ssl.docker.signal 只能与 action: signal 一起使用")
+		}
+	default:
+		return fmt.Errorf("ssl.docker.action 只支持 %s 和 %s: %q", DockerActionSignal, DockerActionRestart, docker.Action)
+	}
+	if docker.Timeout < 0 || docker.Timeout > maxLocalTimeout {
+		return fmt.Errorf("ssl.docker.timeout 必须在 0 到 %d 秒之间", maxLocalTimeout)
+	}
+	if docker.Timeout == 0 {
+		docker.Timeout = defaultDockerTimeout
+	}
+	return nil
+}
+
+// isDockerLabelKey 判断标签键是否符合 Docker 推荐的小写反向域名格式。
+func isDockerLabelKey(value string) bool {
+	if value == "" || len(value) > 128 || strings.ContainsRune(".-_", rune(value[0])) || strings.ContainsRune(".-_", rune(value[len(value)-1])) {
+		return false
+	}
+	for _, r := range value {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '.' && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// isDockerSignal 判断信号是否为 SIG 开头的名称或 1 到 64 的信号编号。
+func isDockerSignal(value string) bool {
+	if number, err := strconv.Atoi(value); err == nil {
+		return number >= 1 && number <= 64
+	}
+	name, ok := strings.CutPrefix(value, "SIG")
+	if !ok || name == "" || len(name) > 16 {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '+' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateTraefikConfig 验证可选的 Traefik 证书目录。
+func validateTraefikConfig(sslConfig *DeployConfig) error {
+	if sslConfig.Traefik == nil {
+		return nil
+	}
+
+	traefik := sslConfig.Traefik
+	traefik.Path = strings.TrimSpace(traefik.Path)
+	if traefik.Path == "" {
+		return errors.New("ssl.traefik.path 不能为空")
+	}
+	if !filepath.IsAbs(traefik.Path) || filepath.Clean(traefik.Path) != traefik.Path || traefik.Path == "/" {
+		return errors.New("ssl.traefik.path 必须是非根目录的规范绝对路径")
+	}
+	return nil
+}
+
+// validateKubernetesConfig 验证可选的 kubeconfig 路径、命名空间和目标 Secret 名称。
+func validateKubernetesConfig(sslConfig *DeployConfig) error {
+	if sslConfig.Kubernetes == nil {
+		return nil
+	}
+
+	kubernetes := sslConfig.Kubernetes
+	kubernetes.Kubeconfig = strings.TrimSpace(kubernetes.Kubeconfig)
+	kubernetes.Context = strings.TrimSpace(kubernetes.Context)
+	if kubernetes.Kubeconfig != "" && (!filepath.IsAbs(kubernetes.Kubeconfig) || strings.ContainsAny(kubernetes.Kubeconfig, "\r\n\x00")) {
+		return errors.New("ssl.kubernetes.kubeconfig 必须是不含换行或 NUL 字符的绝对路径")
+	}
+	if kubernetes.Kubeconfig == "" && kubernetes.Context != "" {
+		return errors.New("ssl.kubernetes.context 只能与 kubeconfig 一起使用")
+	}
+	namespaces := make([]string, 0, len(kubernetes.Namespaces))
+	seenNamespaces := make(map[string]struct{}, len(kubernetes.Namespaces))
+	for _, namespace := range kubernetes.Namespaces {
+		namespace = strings.TrimSpace(namespace)
+		if !isKubernetesDNSLabel(namespace) {
+			return fmt.Errorf("ssl.kubernetes.namespaces 包含无效命名空间: %q", namespace)
+		}
+		if _, exists := seenNamespaces[namespace]; exists {
+			continue
+		}
+		seenNamespaces[namespace] = struct{}{}
+		namespaces = append(namespaces, namespace)
+	}
+	kubernetes.Namespaces = namespaces
+	secrets := make([]string, 0, len(kubernetes.Secrets))
+	seenSecrets := make(map[string]struct{}, len(kubernetes.Secrets))
+	for _, secret := range kubernetes.Secrets {
+		secret = strings.TrimSpace(secret)
+		namespace, name, ok := strings.Cut(secret, "/")
+		if !ok || !isKubernetesDNSLabel(namespace) || !isKubernetesDNSSubdomain(name) {
+			return fmt.Errorf("ssl.kubernetes.secrets 必须使用 namespace/name 格式: %q", secret)
+		}
+		if _, exists := seenSecrets[secret]; exists {
+			continue
+		}
+		seenSecrets[secret] = struct{}{}
+		secrets = append(secrets, secret)
+	}
+	kubernetes.Secrets = secrets
+	return nil
+}
+
+// isKubernetesDNSLabel 判断名称是否符合 Kubernetes 命名空间使用的 RFC 1123 标签规则。
+func isKubernetesDNSLabel(value string) bool {
+	if value == "" || len(value) > 63 || value[0] == '-' || value[len(value)-1] == '-' {
+		return false
+	}
+	for _, r := range value {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// isKubernetesDNSSubdomain 判断名称是否符合 Kubernetes Secret 使用的 RFC 1123 子域名规则。
+func isKubernetesDNSSubdomain(value string) bool {
+	if value == "" || len(value) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(value, ".") {
+		if !isKubernetesDNSLabel(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateJavaKeystoreConfig 验证 Java 密钥库目录、别名和唯一的密码来源。
+func validateJavaKeystoreConfig(sslConfig *DeployConfig) error {
+	if sslConfig.JavaKeystore == nil {
+		return nil
+	}
+
+	keystore := sslConfig.JavaKeystore
+	keystore.Path = strings.TrimSpace(keystore.Path)
+	if keystore.Path == "" {
+		return errors.New("ssl.javaKeystore.path 不能为空")
+	}
+	if !filepath.IsAbs(keystore.Path) || filepath.Clean(keystore.Path) != keystore.Path || keystore.Path == "/" {
+		return errors.New("ssl.javaKeystore.path 必须是非根目录的规范绝对路径")
+	}
+	keystore.Alias = strings.TrimSpace(keystore.Alias)
+	if keystore.Alias == "" {
+		keystore.Alias = defaultJavaKeystoreAlias
+	}
+	if len(keystore.Alias) > 64 || strings.IndexFunc(keystore.Alias, func(r rune) bool { return r < 0x20 || r == 0x7f }) >= 0 {
+		return errors.New("ssl.javaKeystore.alias 不能包含控制字符且长度不能超过 64")
+	}
+
+	keystore.PasswordFile = strings.TrimSpace(keystore.PasswordFile)
+	keystore.PasswordEnv = strings.TrimSpace(keystore.PasswordEnv)
+	sources := 0
+	for _, source := range []string{keystore.Password, keystore.PasswordFile, keystore.PasswordEnv} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return errors.New("ssl.javaKeystore 必须且只能配置 password、passwordFile、passwordEnv 其中之一")
+	}
+	if keystore.PasswordFile != "" && !filepath.IsAbs(keystore.PasswordFile) {
+		return errors.New("ssl.javaKeystore.passwordFile 必须是绝对路径")
+	}
+	if keystore.PasswordEnv != "" && !isEnvironmentVariableName(keystore.PasswordEnv) {
+		return fmt.Errorf("ssl.javaKeystore.passwordEnv 不是有效的环境变量名: %q", keystore.PasswordEnv)
+	}
+	keystore.RestartCommand = strings.TrimSpace(keystore.RestartCommand)
+	return nil
+}
+
+// validateLocalTargetsConfig 验证本地部署实例名称唯一，并归一化文件名、权限和命令超时。
+func validateLocalTargetsConfig(sslConfig *DeployConfig) error {
+	names := make(map[string]struct{}, len(sslConfig.LocalTargets))
+	for index, target := range sslConfig.LocalTargets {
+		if target == nil {
+			return fmt.Errorf("ssl.localTargets[%d] 不能为空", index)
+		}
+		target.Name = strings.TrimSpace(target.Name)
+		if !isLocalTargetName(target.Name) {
+			return fmt.Errorf("ssl.localTargets[%d].name 只能包含字母、数字、下划线和连字符，且长度不能超过 64: %q", index, target.Name)
+		}
+		if _, exists := names[target.Name]; exists {
+			return fmt.Errorf("ssl.localTargets.name 不能重复: %s", target.Name)
+		}
+		names[target.Name] = struct{}{}
+		field := "ssl.localTargets[" + target.Name + "]"
+
+		target.Path = strings.TrimSpace(target.Path)
+		if target.Path == "" {
+			return fmt.Errorf("%s.path 不能为空", field)
+		}
+		if !filepath.IsAbs(target.Path) || filepath.Clean(target.Path) != target.Path || target.Path == "/" {
+			return fmt.Errorf("%s.path 必须是非根目录的规范绝对路径", field)
+		}
+		if err := validateCertificateFilesConfig(field, &target.CertificateFilesConfig); err != nil {
+			return err
+		}
+		if err := normalizeLocalCommands(field+".preCommands", target.PreCommands); err != nil {
+			return err
+		}
+		if err := normalizeLocalCommands(field+".postCommands", target.PostCommands); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSSHTargetsConfig 验证 SSH 远程主机实例名称唯一、连接配置完整和远程目录安全。
+func validateSSHTargetsConfig(sslConfig *DeployConfig) error {
+	names := make(map[string]struct{}, len(sslConfig.SSHTargets))
+	for index, target := range sslConfig.SSHTargets {
+		if target == nil {
+			return fmt.Errorf("ssl.sshTargets[%d] 不能为空", index)
+		}
+		target.Name = strings.TrimSpace(target.Name)
+		if !isLocalTargetName(target.Name) {
+			return fmt.Errorf("ssl.sshTargets[%d].name 只能包含字母、数字、下划线和连字符，且长度不能超过 64: %q", index, target.Name)
+		}
+		if _, exists := names[target.Name]; exists {
+			return fmt.Errorf("ssl.sshTargets.name 不能重复: %s", target.Name)
+		}
+		names[target.Name] = struct{}{}
+		field := "ssl.sshTargets[" + target.Name + "]"
+
+		if err := validateSSHConfig(field, &target.SSHConfig); err != nil {
+			return err
+		}
+		target.Path = strings.TrimSpace(target.Path)
+		if target.Path == "" {
+			return fmt.Errorf("%s.path 不能为空", field)
+		}
+		if !path.IsAbs(target.Path) || path.Clean(target.Path) != target.Path || target.Path == "/" || strings.ContainsAny(target.Path, "\r\n\x00") {
+			return fmt.Errorf("%s.path 必须是非根目录的规范 POSIX 绝对路径", field)
+		}
+		if err := validateCertificateFilesConfig(field, &target.CertificateFilesConfig); err != nil {
+			return err
+		}
+		if err := normalizeLocalCommands(field+".validateCommands", target.ValidateCommands); err != nil {
+			return err
+		}
+		if err := normalizeLocalCommands(field+".reloadCommands", target.ReloadCommands); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSynologyConfig 验证群晖 NAS 名称唯一、DSM 地址和登录凭据，并规范化管理端地址。
+func validateSynologyConfig(sslConfig *DeployConfig) error {
+	names := make(map[string]struct{}, len(sslConfig.Synology))
+	for index, nas := range sslConfig.Synology {
+		if nas == nil {
+			return fmt.Errorf("ssl.synology[%d] 不能为空", index)
+		}
+		nas.Name = strings.TrimSpace(nas.Name)
+		if !isLocalTargetName(nas.Name) {
+			return fmt.Errorf("ssl.synology[%d].name 只能包含字母、数字、下划线和连字符，且长度不能超过 64: %q", index, nas.Name)
+		}
+		if _, exists := names[nas.Name]; exists {
+			return fmt.Errorf("ssl.synology.name 不能重复: %s", nas.Name)
+		}
+		names[nas.Name] = struct{}{}
+		field := "ssl.synology[" + nas.Name + "]"
+
+		nas.URL = strings.TrimRight(strings.TrimSpace(nas.URL), "/")
+		if nas.URL == "" {
+			return fmt.Errorf("%s.url 不能为空", field)
+		}
+		parsedURL, err := url.Parse(nas.URL)
+		if err != nil || parsedURL.Hostname() == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			return fmt.Errorf("%s.url 必须是合法的 HTTP 或 HTTPS 地址", field)
+		}
+		if parsedURL.User != nil || parsedURL.RawQuery != "" || parsedURL.Fragment != "" {
+			return fmt.Errorf("%s.url 不能包含用户凭据、查询参数或片段", field)
+		}
+		if nas.InsecureSkipVerify && parsedURL.Scheme != "https" {
+			return fmt.Errorf("%s.insecureSkipVerify 仅适用于 HTTPS 地址", field)
+		}
+
+		nas.Username = strings.TrimSpace(nas.Username)
+		nas.DeviceID = strings.TrimSpace(nas.DeviceID)
+		nas.Description = strings.TrimSpace(nas.Description)
+		if nas.Username == "" {
+			return fmt.Errorf("%s.username 不能为空", field)
+		}
+		if nas.Password == "" {
+			return fmt.Errorf("%s.password 不能为空", field)
+		}
+		for _, item := range []struct{ name, value string }{
+			{"username", nas.Username}, {"password", nas.Password}, {"deviceId", nas.DeviceID}, {"description", nas.Description},
+		} {
+			if strings.ContainsAny(item.value, "\r\n\x00") {
+				return fmt.Errorf("%s.%s 不能包含换行或 NUL 字符", field, item.name)
+			}
+		}
+		if nas.SetDefault && nas.Description == "" {
+			return fmt.Errorf("%s.setDefault 需要同时配置 description", field)
+		}
+	}
+	return nil
+}
+
+// validateProxmoxConfig 验证 Proxmox VE 集群名称唯一、API 地址和 Token 格式，并规范化 API 地址。
+func validateProxmoxConfig(sslConfig *DeployConfig) error {
+	names := make(map[string]struct{}, len(sslConfig.Proxmox))
+	for index, cluster := range sslConfig.Proxmox {
+		if cluster == nil {
+			return fmt.Errorf("ssl.proxmox[%d] 不能为空", index)
+		}
+		cluster.Name = strings.TrimSpace(cluster.Name)
+		if !isLocalTargetName(cluster.Name) {
+			return fmt.Errorf("ssl.proxmox[%d].name 只能包含字母、数字、下划线和连字符，且长度不能超过 64: %q", index, cluster.Name)
+		}
+		if _, exists := names[cluster.Name]; exists {
+			return fmt.Errorf("ssl.proxmox.name 不能重复: %s", cluster.Name)
+		}
+		names[cluster.Name] = struct{}{}
+		field := "ssl.proxmox[" + cluster.Name + "]"
+
+		cluster.URL = strings.TrimRight(strings.TrimSpace(cluster.URL), "/")
+		if cluster.URL == "" {
+			return fmt.Errorf("%s.url 不能为空", field)
+		}
+		parsedURL, err := url.Parse(cluster.URL)
+		if err != nil || parsedURL.Hostname() == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			return fmt.Errorf("%s.url 必须是合法的 HTTP 或 HTTPS 地址", field)
+		}
+		if parsedURL.User != nil || parsedURL.RawQuery != "" || parsedURL.Fragment != "" {
+			return fmt.Errorf("%s.url 不能包含用户凭据、查询参数或片段", field)
+		}
+		if cluster.InsecureSkipVerify && parsedURL.Scheme != "https" {
+			return fmt.Errorf("%s.insecureSkipVerify 仅适用于 HTTPS 地址", field)
+		}
+
+		cluster.TokenID = strings.TrimSpace(cluster.TokenID)
+		cluster.TokenSecret = strings.TrimSpace(cluster.TokenSecret)
+		user, tokenName, found := strings.Cut(cluster.TokenID, "!")
+		if !found || !strings.Contains(user, "@") || tokenName == "" || strings.ContainsAny(cluster.TokenID, " =\r\n\x00") {
+			return fmt.Errorf("%s.tokenId 必须是 用户@域!令牌名 格式", field)
+		}
+		if cluster.TokenSecret == "" {
+			return fmt.Errorf("%s.tokenSecret 不能为空", field)
+		}
+		if strings.ContainsAny(cluster.TokenSecret, " \r\n\x00") {
+			return fmt.Errorf("%s.tokenSecret 不能包含空白或 NUL 字符", field)
+		}
+	}
+	return nil
+}
+
+// validateFirewallsConfig 验证防火墙名称唯一、类型、管理端地址和 API 凭据，并规范化管理端地址。
+func validateFirewallsConfig(sslConfig *DeployConfig) error {
+	names := make(map[string]struct{}, len(sslConfig.Firewalls))
+	for index, firewall := range sslConfig.Firewalls {
+		if firewall == nil {
+			return fmt.Errorf("ssl.firewalls[%d] 不能为空", index)
+		}
+		firewall.Name = strings.TrimSpace(firewall.Name)
+		if !isLocalTargetName(firewall.Name) {
+			return fmt.Errorf("ssl.firewalls[%d].name 只能包含字母、数字、下划线和连字符，且长度不能超过 64: %q", index, firewall.Name)
+		}
+		if _, exists := names[firewall.Name]; exists {
+			return fmt.Errorf("ssl.firewalls.name 不能重复: %s", firewall.Name)
+		}
+		names[firewall.Name] = struct{}{}
+		field := "ssl.firewalls[" + firewall.Name + "]"
+
+		firewall.Kind = strings.ToLower(strings.TrimSpace(firewall.Kind))
+		if firewall.Kind != FirewallKindOPNsense && firewall.Kind != FirewallKindPfSense {
+			return fmt.Errorf("%s.kind 只支持 %s 或 %s", field, FirewallKindOPNsense, FirewallKindPfSense)
+		}
+		firewall.URL = strings.TrimRight(strings.TrimSpace(firewall.URL), "/")
+		if firewall.URL == "" {
+			return fmt.Errorf("%s.url 不能为空", field)
+		}
+		parsedURL, err := url.Parse(firewall.URL)
+		if err != nil || parsedURL.Hostname() == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			return fmt.Errorf("%s.url 必须是合法的 HTTP 或 HTTPS 地址", field)
+		}
+		if parsedURL.User != nil || parsedURL.RawQuery != "" || parsedURL.Fragment != "" {
+			return fmt.Errorf("%s.url 不能包含用户凭据、查询参数或片段", field)
+		}
+		if firewall.InsecureSkipVerify && parsedURL.Scheme != "https" {
+			return fmt.Errorf("%s.insecureSkipVerify 仅适用于 HTTPS 地址", field)
+		}
+
+		firewall.APIKey = strings.TrimSpace(firewall.APIKey)
+		firewall.APISecret = strings.TrimSpace(firewall.APISecret)
+		if firewall.APIKey == "" {
+			return fmt.Errorf("%s.apiKey 不能为空", field)
+		}
+		if firewall.Kind == FirewallKindOPNsense && firewall.APISecret == "" {
+			return fmt.Errorf("%s.apiSecret 不能为空", field)
+		}
+		if strings.ContainsAny(firewall.APIKey+firewall.APISecret, "\r\n\x00") {
+			return fmt.Errorf("%s API 凭据不能包含换行或 NUL 字符", field)
+		}
+		// OPNsense 的 Web 管理界面证书只能在页面中修改，REST API 不提供对应接口。
+		if firewall.WebGUI && firewall.Kind == FirewallKindOPNsense {
+			return fmt.Errorf("%s.webGui 仅支持 pfSense，OPNsense 请在 System > Settings > Administration 中手动选择证书", field)
+		}
+	}
+	return nil
+}
+
+// validateProxyServersConfig 验证代理服务实例名称唯一、类型和配置文件路径，并按类型补齐 systemd 单元和可执行文件。
+func validateProxyServersConfig(sslConfig *DeployConfig) error {
+	names := make(map[string]struct{}, len(sslConfig.ProxyServers))
+	for index, server := range sslConfig.ProxyServers {
+		if server == nil {
+			return fmt.Errorf("ssl.proxyServers[%d] 不能为空", index)
+		}
+		server.Name = strings.TrimSpace(server.Name)
+		if !isLocalTargetName(server.Name) {
+			return fmt.Errorf("ssl.proxyServers[%d].name 只能包含字母、数字、下划线和连字符，且长度不能超过 64: %q", index, server.Name)
+		}
+		if _, exists := names[server.Name]; exists {
+			return fmt.Errorf("ssl.proxyServers.name 不能重复: %s", server.Name)
+		}
+		names[server.Name] = struct{}{}
+		field := "ssl.proxyServers[" + server.Name + "]"
+
+		server.Kind = strings.ToLower(strings.TrimSpace(server.Kind))
+		defaultService := ""
+		switch server.Kind {
+		case ProxyServerKindXray, ProxyServerKindV2Ray, ProxyServerKindTrojanGo:
+			defaultService = server.Kind
+		case ProxyServerKindHysteria:
+			defaultService = "hysteria-server"
+		default:
+			return fmt.Errorf("%s.kind 只支持 %s、%s、%s 或 %s", field, ProxyServerKindXray, ProxyServerKindV2Ray, ProxyServerKindTrojanGo, ProxyServerKindHysteria)
+		}
+		server.Config = strings.TrimSpace(server.Config)
+		if server.Config == "" {
+			return fmt.Errorf("%s.config 不能为空", field)
+		}
+		if !filepath.IsAbs(server.Config) || filepath.Clean(server.Config) != server.Config {
+			return fmt.Errorf("%s.config 必须是规范的绝对路径", field)
+		}
+		server.Service = strings.TrimSpace(server.Service)
+		if server.Service == "" {
+			server.Service = defaultService
+		}
+		if !isSystemdUnitName(server.Service) {
+			return fmt.Errorf("%s.service 不是合法的 systemd 单元名称: %q", field, server.Service)
+		}
+		server.Binary = strings.TrimSpace(server.Binary)
+		if server.Binary == "" {
+			server.Binary = server.Kind
+		}
+		if strings.ContainsRune(server.Binary, filepath.Separator) && (!filepath.IsAbs(server.Binary) || filepath.Clean(server.Binary) != server.Binary) {
+			return fmt.Errorf("%s.binary 必须是命令名称或规范的绝对路径", field)
+		}
+	}
+	return nil
+}
+
+// databaseDefaults 记录每种数据库的默认端口、管理账号、文件属主和客户端命令。
+var databaseDefaults = map[string]struct {
+	port     int
+	username string
+	owner    string
+	binary   string
+}{
+	DatabaseKindPostgreSQL: {5432, "postgres", "postgres", "psql"},
+	DatabaseKindMySQL:      {3306, "root", "mysql", "mysql"},
+	DatabaseKindRedis:      {6379, "", "redis", ""},
+}
+
+// validateDatabasesConfig 验证数据库实例名称唯一、连接地址和证书文件路径，并按类型补齐端口、账号、属主和客户端命令。
+func validateDatabasesConfig(sslConfig *DeployConfig) error {
+	names := make(map[string]struct{}, len(sslConfig.Databases))
+	for index, database := range sslConfig.Databases {
+		if database == nil {
+			return fmt.Errorf("ssl.databases[%d] 不能为空", index)
+		}
+		database.Name = strings.TrimSpace(database.Name)
+		if !isLocalTargetName(database.Name) {
+			return fmt.Errorf("ssl.databases[%d].name 只能包含字母、数字、下划线和连字符，且长度不能超过 64: %q", index, database.Name)
+		}
+		if _, exists := names[database.Name]; exists {
+			return fmt.Errorf("ssl.databases.name 不能重复: %s", database.Name)
+		}
+		names[database.Name] = struct{}{}
+		field := "ssl.databases[" + database.Name + "]"
+
+		database.Kind = strings.ToLower(strings.TrimSpace(database.Kind))
+		defaults, supported := databaseDefaults[database.Kind]
+		if !supported {
+			return fmt.Errorf("%s.kind 只支持 %s、%s 或 %s", field, DatabaseKindPostgreSQL, DatabaseKindMySQL, DatabaseKindRedis)
+		}
+		database.Host = strings.TrimSpace(database.Host)
+		if database.Host == "" {
+			database.Host = "127.0.0.1"
+		}
+		if strings.ContainsAny(database.Host, " /\\@[]\t\r\n\x00") || strings.Count(database.Host, ":") == 1 {
+			return fmt.Errorf("%s.host 必须是不含端口的主机名或 IP 地址", field)
+		}
+		if database.Port == 0 {
+			database.Port = defaults.port
+		}
+		if database.TLSPort == 0 {
+			database.TLSPort = database.Port
+		}
+		if database.Port < 1 || database.Port > 65535 || database.TLSPort < 1 || database.TLSPort > 65535 {
+			return fmt.Errorf("%s.port 和 tlsPort 必须在 1-65535 之间", field)
+		}
+		if database.Kind != DatabaseKindRedis && database.TLSPort != database.Port {
+			return fmt.Errorf("%s.tlsPort 只适用于 redis，%s 在管理端口上协商 TLS", field, database.Kind)
+		}
+		database.Username = strings.TrimSpace(database.Username)
+		if database.Username == "" {
+			database.Username = defaults.username
+		}
+
+		database.CertFile = strings.TrimSpace(database.CertFile)
+		database.KeyFile = strings.TrimSpace(database.KeyFile)
+		for _, path := range []string{database.CertFile, database.KeyFile} {
+			if path == "" || !filepath.IsAbs(path) || filepath.Clean(path) != path {
+				return fmt.Errorf("%s.certFile 和 keyFile 必须是规范的绝对路径", field)
+			}
+		}
+		if database.CertFile == database.KeyFile {
+			return fmt.Errorf("%s.certFile 和 keyFile 不能相同", field)
+		}
+		database.Owner = strings.TrimSpace(database.Owner)
+		if database.Owner == "" {
+			database.Owner = defaults.owner
+		}
+		database.Group = strings.TrimSpace(database.Group)
+		for _, account := range []string{database.Owner, database.Group} {
+			if strings.HasPrefix(account, "-") || strings.IndexFunc(account, func(r rune) bool { return r <= ' ' || r == 0x7f || r == ':' }) >= 0 {
+				return fmt.Errorf("%s.owner/group 不能以连字符开头或包含空白、冒号、控制字符: %q", field, account)
+			}
+		}
+
+		database.Binary = strings.TrimSpace(database.Binary)
+		if database.Kind == DatabaseKindRedis {
+			if database.Binary != "" {
+				return fmt.Errorf("%s.binary 不适用于 redis", field)
+			}
+			continue
+		}
+		if database.Binary == "" {
+			database.Binary = defaults.binary
+		}
+		if strings.ContainsRune(database.Binary, filepath.Separator) && (!filepath.IsAbs(database.Binary) || filepath.Clean(database.Binary) != database.Binary) {
+			return fmt.Errorf("%s.binary 必须是命令名称或规范的绝对路径", field)
+		}
+	}
+	return nil
+}
+
+// s3ServerProfileFiles 记录每种命名约定的证书和私钥文件名。
+var s3ServerProfileFiles = map[string][2]string{
+	S3ServerProfileMinIO:  {"public.crt", "private.key"},
+	S3ServerProfileRustFS: {"rustfs_cert.pem", "rustfs_key.pem"},
+	S3ServerProfileCustom: {"", ""},
+}
+
+// validateS3ServersConfig 验证对象存储实例名称唯一、证书目录、SSH 连接和重启方式，并按 profile 补齐文件名。
+func validateS3ServersConfig(sslConfig *DeployConfig) error {
+	names := make(map[string]struct{}, len(sslConfig.S3Servers))
+	for index, server := range sslConfig.S3Servers {
+		if server == nil {
+			return fmt.Errorf("ssl.s3Servers[%d] 不能为空", index)
+		}
+		server.Name = strings.TrimSpace(server.Name)
+		if !isLocalTargetName(server.Name) {
+			return fmt.Errorf("ssl.s3Servers[%d].name 只能包含字母、数字、下划线和连字符，且长度不能超过 64: %q", index, server.Name)
+		}
+		if _, exists := names[server.Name]; exists {
+			return fmt.Errorf("ssl.s3Servers.name 不能重复: %s", server.Name)
+		}
+		names[server.Name] = struct{}{}
+		field := "ssl.s3Servers[" + server.Name + "]"
+
+		server.Profile = strings.ToLower(strings.TrimSpace(server.Profile))
+		if server.Profile == "" {
+			server.Profile = S3ServerProfileMinIO
+		}
+		files, supported := s3ServerProfileFiles[server.Profile]
+		if !supported {
+			return fmt.Errorf("%s.profile 只支持 %s、%s 或 %s", field, S3ServerProfileMinIO, S3ServerProfileRustFS, S3ServerProfileCustom)
+		}
+		server.CertFile = strings.TrimSpace(server.CertFile)
+		server.KeyFile = strings.TrimSpace(server.KeyFile)
+		if server.Profile == S3ServerProfileCustom && (server.CertFile == "" || server.KeyFile == "") {
+			return fmt.Errorf("%s.profile 为 custom 时必须填写 certFile 和 keyFile", field)
+		}
+		if server.CertFile == "" {
+			server.CertFile = files[0]
+		}
+		if server.KeyFile == "" {
+			server.KeyFile = files[1]
+		}
+		if err := validateCertificateFilesConfig(field, &server.CertificateFilesConfig); err != nil {
+			return err
+		}
+
+		server.Path = strings.TrimSpace(server.Path)
+		if server.Path == "" {
+			return fmt.Errorf("%s.path 不能为空", field)
+		}
+		if !path.IsAbs(server.Path) || path.Clean(server.Path) != server.Path || server.Path == "/" || strings.ContainsAny(server.Path, "\r\n\x00") {
+			return fmt.Errorf("%s.path 必须是非根目录的规范 POSIX 绝对路径", field)
+		}
+		if IsSSHConfigured(&server.SSHConfig) {
+			if err := validateSSHConfig(field, &server.SSHConfig); err != nil {
+				return err
+			}
+		} else if server.Sudo {
+			return fmt.Errorf("%s.sudo 只适用于 SSH 远程部署", field)
+		}
+
+		server.AdminURL = strings.TrimRight(strings.TrimSpace(server.AdminURL), "/")
+		server.AccessKey = strings.TrimSpace(server.AccessKey)
+		server.Region = strings.TrimSpace(server.Region)
+		if server.AdminURL == "" {
+			if server.AccessKey != "" || server.SecretKey != "" || server.InsecureSkipVerify {
+				return fmt.Errorf("%s.accessKey、secretKey 和 insecureSkipVerify 只能在配置 adminURL 后使用", field)
+			}
+		} else {
+			parsedURL, err := url.Parse(server.AdminURL)
+			if err != nil || parsedURL.Hostname() == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+				return fmt.Errorf("%s.adminURL 必须是合法的 HTTP 或 HTTPS 地址", field)
+			}
+			if parsedURL.User != nil || (parsedURL.Path != "" && parsedURL.Path != "/") || parsedURL.RawQuery != "" || parsedURL.Fragment != "" {
+				return fmt.Errorf("%s.adminURL 只能包含协议、主机和端口", field)
+			}
+			if server.InsecureSkipVerify && parsedURL.Scheme != "https" {
+				return fmt.Errorf("%s.insecureSkipVerify 仅适用于 HTTPS 地址", field)
+			}
+			if server.AccessKey == "" || server.SecretKey == "" {
+				return fmt.Errorf("%s.accessKey 和 secretKey 不能为空", field)
+			}
+			if len(server.ReloadCommands) > 0 {
+				return fmt.Errorf("%s.adminURL 和 reloadCommands 只能配置一个", field)
+			}
+			if server.Region == "" {
+				server.Region = "us-east-1"
+			}
+		}
+		if err := normalizeLocalCommands(field+".reloadCommands", server.ReloadCommands); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mediaServerDefaults 记录每种媒体服务器的默认 API 地址、HTTPS 端口和文件属主。
+var mediaServerDefaults = map[string]struct {
+	url       string
+	httpsPort int
+	owner     string
+}{
+	MediaServerKindJellyfin: {"http://127.0.0.1:8096", 8920, "jellyfin"},
+	MediaServerKindEmby:     {"http://127.0.0.1:8096", 8920, "emby"},
+	MediaServerKindPlex:     {"http://127.0.0.1:32400", 32400, "plex"},
+}
+
+// validateMediaServersConfig 验证媒体服务器名称唯一、API 地址、密钥和 PFX 路径，并按类型补齐地址、端口和属主。
+func validateMediaServersConfig(sslConfig *DeployConfig) error {
+	names := make(map[string]struct{}, len(sslConfig.MediaServers))
+	for index, server := range sslConfig.MediaServers {
+		if server == nil {
+			return fmt.Errorf("ssl.mediaServers[%d] 不能为空", index)
+		}
+		server.Name = strings.TrimSpace(server.Name)
+		if !isLocalTargetName(server.Name) {
+			return fmt.Errorf("ssl.mediaServers[%d].name 只能包含字母、数字、下划线和连字符，且长度不能超过 64: %q", index, server.Name)
+		}
+		if _, exists := names[server.Name]; exists {
+			return fmt.Errorf("ssl.mediaServers.name 不能重复: %s", server.Name)
+		}
+		names[server.Name] = struct{}{}
+		field := "ssl.mediaServers[" + server.Name + "]"
+
+		server.Kind = strings.ToLower(strings.TrimSpace(server.Kind))
+		defaults, supported := mediaServerDefaults[server.Kind]
+		if !supported {
+			return fmt.Errorf("%s.kind 只支持 %s、%s 或 %s", field, MediaServerKindJellyfin, MediaServerKindEmby, MediaServerKindPlex)
+		}
+		server.URL = strings.TrimRight(strings.TrimSpace(server.URL), "/")
+		if server.URL == "" {
+			server.URL = defaults.url
+		}
+		parsedURL, err := url.Parse(server.URL)
+		if err != nil || parsedURL.Hostname() == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			return fmt.Errorf("%s.url 必须是合法的 HTTP 或 HTTPS 地址", field)
+		}
+		if parsedURL.User != nil || parsedURL.RawQuery != "" || parsedURL.Fragment != "" {
+			return fmt.Errorf("%s.url 不能包含用户凭据、查询参数或片段", field)
+		}
+		if server.InsecureSkipVerify && parsedURL.Scheme != "https" {
+			return fmt.Errorf("%s.insecureSkipVerify 仅适用于 HTTPS 地址", field)
+		}
+		server.APIKey = strings.TrimSpace(server.APIKey)
+		if server.APIKey == "" {
+			return fmt.Errorf("%s.apiKey 不能为空", field)
+		}
+		if strings.ContainsAny(server.APIKey, " \"\r\n\x00") {
+			return fmt.Errorf("%s.apiKey 不能包含空白、引号或 NUL 字符", field)
+		}
+		if server.HTTPSPort == 0 {
+			server.HTTPSPort = defaults.httpsPort
+		}
+		if server.HTTPSPort < 1 || server.HTTPSPort > 65535 {
+			return fmt.Errorf("%s.httpsPort 必须在 1-65535 之间", field)
+		}
+
+		server.PFXFile = strings.TrimSpace(server.PFXFile)
+		if server.PFXFile == "" || !filepath.IsAbs(server.PFXFile) || filepath.Clean(server.PFXFile) != server.PFXFile {
+			return fmt.Errorf("%s.pfxFile 必须是规范的绝对路径", field)
+		}
+		server.Owner = strings.TrimSpace(server.Owner)
+		if server.Owner == "" {
+			server.Owner = defaults.owner
+		}
+		server.Group = strings.TrimSpace(server.Group)
+		for _, account := range []string{server.Owner, server.Group} {
+			if strings.HasPrefix(account, "-") || strings.IndexFunc(account, func(r rune) bool { return r <= ' ' || r == 0x7f || r == ':' }) >= 0 {
+				return fmt.Errorf("%s.owner/group 不能以连字符开头或包含空白、冒号、控制字符: %q", field, account)
+			}
+		}
+	}
+	return nil
+}
+
+// isSystemdUnitName 判断名称是否只包含 systemd 单元名允许的字符，且不以连字符开头。
+func isSystemdUnitName(value string) bool {
+	if value == "" || len(value) > 255 || value[0] == '-' {
+		return false
+	}
+	for _, r := range value {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && !strings.ContainsRune(":-_.@\\", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateCertificateFilesConfig 补齐默认文件名和权限，并拒绝路径分隔符和非法属主。
+func validateCertificateFilesConfig(field string, files *CertificateFilesConfig) error {
+	files.CertFile = strings.TrimSpace(files.CertFile)
+	if files.CertFile == "" {
+		files.CertFile = defaultLocalCertFile
+	}
+	files.KeyFile = strings.TrimSpace(files.KeyFile)
+	if files.KeyFile == "" {
+		files.KeyFile = defaultLocalKeyFile
+	}
+	for _, fileName := range []string{files.CertFile, files.KeyFile} {
+		if fileName == "." || fileName == ".." || strings.ContainsAny(fileName, "/\\\x00\r\n") {
+			return fmt.Errorf("%s 文件名必须是不含路径分隔符的普通文件名: %q", field, fileName)
+		}
+	}
+	if files.CertFile == files.KeyFile {
+		return fmt.Errorf("%s.certFile 和 keyFile 不能相同", field)
+	}
+
+	files.CertMode = strings.TrimSpace(files.CertMode)
+	if files.CertMode == "" {
+		files.CertMode = defaultLocalCertMode
+	}
+	files.KeyMode = strings.TrimSpace(files.KeyMode)
+	if files.KeyMode == "" {
+		files.KeyMode = defaultLocalKeyMode
+	}
+	for _, mode := range []string{files.CertMode, files.KeyMode} {
+		if _, err := ParseFileMode(mode); err != nil {
+			return fmt.Errorf("%s 文件权限无效: %w", field, err)
+		}
+	}
+
+	files.Owner = strings.TrimSpace(files.Owner)
+	files.Group = strings.TrimSpace(files.Group)
+	for _, account := range []string{files.Owner, files.Group} {
+		if strings.HasPrefix(account, "-") || strings.IndexFunc(account, func(r rune) bool { return r <= ' ' || r == 0x7f || r == ':' }) >= 0 {
+			return fmt.Errorf("%s.owner/group 不能以连字符开头或包含空白、冒号、控制字符: %q", field, account)
+		}
+	}
+	return nil
+}
+
+// normalizeLocalCommands 拒绝空命令并补齐默认超时。
+func normalizeLocalCommands(field string, commands []LocalCommandConfig) error {
+	for index := range commands {
+		commands[index].Command = strings.TrimSpace(commands[index].Command)
+		if commands[index].Command == "" {
+			return fmt.Errorf("%s[%d].command 不能为空", field, index)
+		}
+		if commands[index].Timeout == 0 {
+			commands[index].Timeout = defaultLocalTimeout
+		}
+		if commands[index].Timeout < 0 || commands[index].Timeout > maxLocalTimeout {
+			return fmt.Errorf("%s[%d].timeout 必须在 1-%d 秒之间", field, index, maxLocalTimeout)
+		}
+	}
+	return nil
+}
+
+// isLocalTargetName 判断本地部署实例名称是否只包含安全字符。
+func isLocalTargetName(value string) bool {
+	if value == "" || len(value) > 64 {
+		return false
+	}
+	for _, r := range value {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseFileMode 解析 0640 形式的八进制文件权限，只允许普通权限位。
+func ParseFileMode(value string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("必须是 0000-0777 之间的八进制权限: %q", value)
+	}
+	return os.FileMode(mode), nil
+}
+
+// isEnvironmentVariableName 判断名称是否为 POSIX shell 可引用的环境变量名。
+func isEnvironmentVariableName(value string) bool {
+	if value == "" || (value[0] >= '0' && value[0] <= '9') {
+		return false
+	}
+	for _, r := range value {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateRustFSConfig 归一化 RustFS 新旧配置并验证本机或 SSH 远程模式。
+func validateRustFSConfig(sslConfig *DeployConfig) error {
+	legacyPath := strings.TrimSpace(sslConfig.RustFSPath)
+	if sslConfig.RustFS == nil {
+		if legacyPath == "" {
+			return nil
+		}
+		sslConfig.RustFS = &RustFSConfig{Path: legacyPath}
+		sslConfig.RustFSPath = ""
+		return validateRustFSPath(sslConfig.RustFS.Path)
+	}
+
+	rustFS := sslConfig.RustFS
+	rustFS.Path = strings.TrimSpace(rustFS.Path)
+	if legacyPath != "" && rustFS.Path != "" && legacyPath != rustFS.Path {
+		return errors.New("ssl.rustFS.path 与旧版 ssl.rustFSPath 不能同时配置为不同目录")
+	}
+	if rustFS.Path == "" {
+		rustFS.Path = legacyPath
+	}
+	sslConfig.RustFSPath = ""
+	if rustFS.Path == "" {
+		return errors.New("ssl.rustFS.path 不能为空")
+	}
+	if err := validateRustFSPath(rustFS.Path); err != nil {
+		return err
+	}
+	if !IsSSHConfigured(&rustFS.SSHConfig) {
+		return nil
+	}
+	return validateSSHConfig("ssl.rustFS", &rustFS.SSHConfig)
+}
+
+// validateRustFSPath 验证 RustFS 证书根目录是安全的 POSIX 绝对路径。
+func validateRustFSPath(rustFSPath string) error {
+	if !path.IsAbs(rustFSPath) || path.Clean(rustFSPath) != rustFSPath || rustFSPath == "/" {
+		return errors.New("ssl.rustFS.path 必须是非根目录的规范 POSIX 绝对路径")
+	}
+	if strings.ContainsAny(rustFSPath, "\r\n\x00") {
+		return errors.New("ssl.rustFS.path 不能包含换行或 NUL 字符")
+	}
+	return nil
+}
+
+// IsSSHConfigured 判断除默认端口外是否已经填写任一 SSH 字段。
+func IsSSHConfigured(sshConfig *SSHConfig) bool {
+	if sshConfig == nil {
+		return false
+	}
+	return strings.TrimSpace(sshConfig.Host) != "" ||
+		strings.TrimSpace(sshConfig.Username) != "" ||
+		sshConfig.Password != "" ||
+		strings.TrimSpace(sshConfig.PrivateKeyPath) != "" ||
+		sshConfig.PrivateKeyPassphrase != "" ||
+		(sshConfig.Port != 0 && sshConfig.Port != 22)
+}
+
+// validateSSHConfig 验证密码或私钥认证共用的 SSH 连接字段。
+func validateSSHConfig(section string, sshConfig *SSHConfig) error {
+	sshConfig.Host = strings.TrimSpace(sshConfig.Host)
+	if strings.HasPrefix(sshConfig.Host, "[") && strings.HasSuffix(sshConfig.Host, "]") {
+		sshConfig.Host = strings.TrimSuffix(strings.TrimPrefix(sshConfig.Host, "["), "]")
+	}
+	sshConfig.Username = strings.TrimSpace(sshConfig.Username)
+	sshConfig.PrivateKeyPath = strings.TrimSpace(sshConfig.PrivateKeyPath)
+	if sshConfig.Port == 0 {
+		sshConfig.Port = 22
+	}
+	if sshConfig.Host == "" {
+		return fmt.Errorf("%s.host 不能为空", section)
+	}
+	if strings.Contains(sshConfig.Host, "://") || strings.ContainsAny(sshConfig.Host, "/?#@") || containsSpaceOrControl(sshConfig.Host) {
+		return fmt.Errorf("%s.host 必须是主机名或 IP 地址，不能包含协议、路径或空白字符", section)
+	}
+	if strings.Contains(sshConfig.Host, ":") && net.ParseIP(sshConfig.Host) == nil {
+		return fmt.Errorf("%s.host 不能包含端口，端口请单独填写到 %s.port", section, section)
+	}
+	if sshConfig.Port < minPort || sshConfig.Port > maxPort {
+		return fmt.Errorf("%s.port 必须在 %d-%d 之间", section, minPort, maxPort)
+	}
+	if sshConfig.Username == "" || containsSpaceOrControl(sshConfig.Username) {
+		return fmt.Errorf("%s.username 不能为空且不能包含空白或控制字符", section)
+	}
+	if sshConfig.Password == "" && sshConfig.PrivateKeyPath == "" {
+		return fmt.Errorf("%s.password 或 %s.privateKeyPath 必须填写一项", section, section)
+	}
+	if strings.ContainsAny(sshConfig.Password, "\r\n\x00") {
+		return fmt.Errorf("%s.password 不能包含换行或 NUL 字符", section)
+	}
+	if sshConfig.PrivateKeyPath != "" {
+		if !filepath.IsAbs(sshConfig.PrivateKeyPath) || filepath.Clean(sshConfig.PrivateKeyPath) != sshConfig.PrivateKeyPath {
+			return fmt.Errorf("%s.privateKeyPath 必须是 deploy 客户端本地的规范绝对路径", section)
+		}
+		if strings.ContainsAny(sshConfig.PrivateKeyPath, "\r\n\x00") {
+			return fmt.Errorf("%s.privateKeyPath 不能包含换行或 NUL 字符", section)
+		}
+	} else if sshConfig.PrivateKeyPassphrase != "" {
+		return fmt.Errorf("%s.privateKeyPassphrase 只能与 privateKeyPath 一起配置", section)
+	}
+	if strings.ContainsAny(sshConfig.PrivateKeyPassphrase, "\r\n\x00") {
+		return fmt.Errorf("%s.privateKeyPassphrase 不能包含换行或 NUL 字符", section)
+	}
+	return nil
+}
+
+// containsSpaceOrControl 判断 SSH 标识字段是否含不可接受的空白或控制字符。
+func containsSpaceOrControl(value string) bool {
+	return strings.IndexFunc(value, func(character rune) bool {
+		return unicode.IsSpace(character) || unicode.IsControl(character)
+	}) >= 0
+}
+
+// validateUpdateURL validates an update mirror URL and permits local HTTP only in local mode.
+func validateUpdateURL(name, rawURL string, allowPath bool, environment string) error {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Hostname() == "" || parsed.User != nil {
+		return fmt.Errorf("%s 必须是包含主机名且不含用户凭据的合法 URL", name)
+	}
+	if !allowPath && parsed.RawQuery != "" {
+		return fmt.Errorf("%s 不能包含查询参数", name)
+	}
+	if parsed.Scheme == "https" {
+		return nil
 	}
 	if parsed.Scheme == "http" && environment == envLocal && isLoopbackHostname(parsed.Hostname()) {
 		return nil
@@ -552,6 +2094,41 @@ func PrepareRuntimeDirsForRuntime(runtime *Runtime) error {
 	if err := prepareDir("Apache", runtime.Config.SSL.ApachePath); err != nil {
 		return err
 	}
+	if runtime.Config.SSL.Caddy != nil {
+		if err := prepareDir("Caddy", runtime.Config.SSL.Caddy.Path); err != nil {
+			return err
+		}
+	}
+	if runtime.Config.SSL.HAProxy != nil {
+		if err := prepareDir("HAProxy", runtime.Config.SSL.HAProxy.Path); err != nil {
+			return err
+		}
+	}
+	if runtime.Config.SSL.Mail != nil {
+		if err := prepareDir("邮件服务", runtime.Config.SSL.Mail.Path); err != nil {
+			return err
+		}
+	}
+	if runtime.Config.SSL.Docker != nil {
+		if err := prepareDir("Docker 容器", runtime.Config.SSL.Docker.Path); err != nil {
+			return err
+		}
+	}
+	if runtime.Config.SSL.Traefik != nil {
+		if err := prepareDir("Traefik", runtime.Config.SSL.Traefik.Path); err != nil {
+			return err
+		}
+	}
+	if runtime.Config.SSL.JavaKeystore != nil {
+		if err := prepareDir("Java 密钥库", runtime.Config.SSL.JavaKeystore.Path); err != nil {
+			return err
+		}
+	}
+	for _, target := range runtime.Config.SSL.LocalTargets {
+		if err := prepareDir("本地部署实例 "+target.Name, target.Path); err != nil {
+			return err
+		}
+	}
 	if runtime.Config.SSL.RustFS != nil && !IsSSHConfigured(&runtime.Config.SSL.RustFS.SSHConfig) {
 		if err := prepareDir("RustFS", runtime.Config.SSL.RustFS.Path); err != nil {
 			return err
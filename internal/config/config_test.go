@@ -0,0 +1,74 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestValidatePanelInstancesRejectsDuplicateNames 验证 1Panel、宝塔和雷池实例列表的名称去除空白后必须唯一，单实例节点不能设置名称。
+func TestValidatePanelInstancesRejectsDuplicateNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate func(*DeployConfig) error
+		config   *DeployConfig
+		want     string
+	}{
+		{
+			name:     "1Panel 重名实例",
+			validate: validateOnePanelConfig,
+			config: &DeployConfig{OnePanels: []*OnePanelConfig{
+				{Name: "panel", URL: "https://a.example.com", APIKey: "key-a"},
+				{Name: " panel ", URL: "https://b.example.com", APIKey: "key-b"},
+			}},
+			want: "ssl.onePanels.name 不能重复: panel",
+		},
+		{
+			name:     "宝塔重名实例",
+			validate: validateBTPanelConfig,
+			config: &DeployConfig{BTPanels: []*BTPanelConfig{
+				{Name: "bt", URL: "https://a.example.com", APIKey: "key-a"},
+				{Name: "bt", URL: "https://b.example.com", APIKey: "key-b"},
+			}},
+			want: "ssl.btPanels.name 不能重复: bt",
+		},
+		{
+			name:     "雷池重名实例",
+			validate: validateSafeLineConfig,
+			config: &DeployConfig{SafeLines: []*SafeLineConfig{
+				{Name: "waf", URL: "https://a.example.com", APIToken: "token-a"},
+				{Name: "waf", URL: "https://b.example.com", APIToken: "token-b"},
+			}},
+			want: "ssl.safeLines.name 不能重复: waf",
+		},
+		{
+			name:     "单实例节点设置名称",
+			validate: validateOnePanelConfig,
+			config:   &DeployConfig{OnePanel: &OnePanelConfig{Name: "panel", URL: "https://a.example.com", APIKey: "key-a"}},
+			want:     "ssl.onePanel.name 仅适用于 ssl.onePanels 列表",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.validate(test.config); err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("应拒绝配置并提示 %q，实际错误: %v", test.want, err)
+			}
+		})
+	}
+}
+
+// TestValidatePanelInstancesAcceptsLegacyAndNamedInstances 验证单实例节点可以与具名实例列表共存，并规范化名称和地址。
+func TestValidatePanelInstancesAcceptsLegacyAndNamedInstances(t *testing.T) {
+	sslConfig := &DeployConfig{
+		OnePanel:  &OnePanelConfig{URL: "https://a.example.com/", APIKey: "key-a"},
+		OnePanels: []*OnePanelConfig{{Name: " panel-02 ", URL: "https://b.example.com/", APIKey: "key-b"}},
+	}
+	if err := validateOnePanelConfig(sslConfig); err != nil {
+		t.Fatalf("validateOnePanelConfig() error = %v", err)
+	}
+	if sslConfig.OnePanels[0].Name != "panel-02" || sslConfig.OnePanels[0].URL != "https://b.example.com" || sslConfig.OnePanel.URL != "https://a.example.com" {
+		t.Fatalf("实例名称和地址未规范化: legacy=%+v named=%+v", sslConfig.OnePanel, sslConfig.OnePanels[0])
+	}
+	if instances := OnePanelInstances(sslConfig); len(instances) != 2 || instances[0] != sslConfig.OnePanel {
+		t.Fatalf("单实例应排在具名实例之前: %+v", instances)
+	}
+}
@@ -29,6 +29,8 @@ const (
 	ProviderDogeCloud = "dogecloud"
 	// ProviderLeCDN 是 LeCDN provider 的配置名称。
 	ProviderLeCDN = "lecdn"
+	// ProviderAWS 是亚马逊云科技 provider 的配置名称。
+	ProviderAWS = "aws"
 )
 
 // DeploymentProviderName 返回 v2 provider 对应的兼容配置键。
@@ -54,6 +56,8 @@ func DeploymentProviderName(provider deployPB.Provider) (string, bool) {
 		return ProviderDogeCloud, true
 	case deployPB.Provider_PROVIDER_LECDN:
 		return ProviderLeCDN, true
+	case deployPB.Provider_PROVIDER_AWS:
+		return ProviderAWS, true
 	default:
 		return "", false
 	}
@@ -82,6 +86,8 @@ func DeploymentProviderFromName(name string) (deployPB.Provider, bool) {
 		return deployPB.Provider_PROVIDER_DOGE_CLOUD, true
 	case ProviderLeCDN:
 		return deployPB.Provider_PROVIDER_LECDN, true
+	case ProviderAWS:
+		return deployPB.Provider_PROVIDER_AWS, true
 	default:
 		return deployPB.Provider_PROVIDER_UNSPECIFIED, false
 	}
@@ -115,7 +121,23 @@ func IsDeploymentResourceType(deploymentType deployPB.DeploymentType) bool {
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_NLB,
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ELB,
 		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_WEBSITE_CERT,
-		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_WEBSITE_CERT:
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_WEBSITE_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_KUBERNETES_SECRET_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_LOCAL_TARGET_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SSH_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SYNOLOGY_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXMOX_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FIREWALL_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_SERVER_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_VHOST_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DOCKER_CONTAINER_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NPM_PROXY_HOST_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXY_SERVER_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DATABASE_TLS_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_S3_SERVER_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MEDIA_SERVER_PFX,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_SITE_CERT,
+		deployPB.DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_DEVICE_CERT:
 		return true
 	default:
 		return false
@@ -153,7 +175,8 @@ func validateRemovedDeploymentResourceFields(rawProviders any) error {
 func validateProviderCredentials(provider *Provider, environment string) error {
 	if provider.Name != ProviderAliyun && provider.Name != ProviderTencentCloud && provider.Name != ProviderQiniu &&
 		provider.Name != ProviderHuaweiCloud && provider.Name != ProviderVolcengine && provider.Name != ProviderJDCloud &&
-		provider.Name != ProviderBaiduCloud && provider.Name != ProviderDogeCloud && provider.Name != ProviderLeCDN {
+		provider.Name != ProviderBaiduCloud && provider.Name != ProviderDogeCloud && provider.Name != ProviderLeCDN &&
+		provider.Name != ProviderAWS {
 		return nil
 	}
 	if provider.Auth == nil {
@@ -183,7 +206,7 @@ func validateProviderCredentials(provider *Provider, environment string) error {
 		if strings.TrimSpace(provider.Auth.AccessSecret) == "" {
 			missingFields = append(missingFields, "accessSecret")
 		}
-	case ProviderHuaweiCloud, ProviderVolcengine, ProviderJDCloud, ProviderBaiduCloud:
+	case ProviderHuaweiCloud, ProviderVolcengine, ProviderJDCloud, ProviderBaiduCloud, ProviderAWS:
 		if strings.TrimSpace(provider.Auth.AccessKeyId) == "" {
 			missingFields = append(missingFields, "accessKeyId")
 		}
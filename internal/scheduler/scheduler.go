@@ -82,12 +82,67 @@ func configuredSensitiveValues(configuration *config.Configuration) []string {
 		if configuration.SSL.SafeLine != nil {
 			values = append(values, configuration.SSL.SafeLine.APIToken)
 		}
+		for _, panel := range configuration.SSL.OnePanels {
+			if panel != nil {
+				values = append(values, panel.APIKey)
+			}
+		}
+		for _, panel := range configuration.SSL.BTPanels {
+			if panel != nil {
+				values = append(values, sensitiveHTTPConfigValues(panel.URL)...)
+				values = append(values, panel.APIKey)
+			}
+		}
+		for _, safeLine := range configuration.SSL.SafeLines {
+			if safeLine != nil {
+				values = append(values, safeLine.APIToken)
+			}
+		}
+		if configuration.SSL.NginxProxyManager != nil {
+			values = append(values, sensitiveHTTPConfigValues(configuration.SSL.NginxProxyManager.URL)...)
+			values = append(values, configuration.SSL.NginxProxyManager.Password)
+		}
 		if configuration.SSL.FeiNiu != nil {
 			values = append(values, configuration.SSL.FeiNiu.Password, configuration.SSL.FeiNiu.PrivateKeyPassphrase)
 		}
+		for _, device := range configuration.SSL.FeiNiuDevices {
+			values = append(values, device.Password, device.PrivateKeyPassphrase)
+		}
 		if configuration.SSL.RustFS != nil {
 			values = append(values, configuration.SSL.RustFS.Password, configuration.SSL.RustFS.PrivateKeyPassphrase)
 		}
+		if configuration.SSL.OpenVPNAS != nil {
+			values = append(values, configuration.SSL.OpenVPNAS.Password, configuration.SSL.OpenVPNAS.PrivateKeyPassphrase)
+		}
+		if configuration.SSL.JavaKeystore != nil {
+			values = append(values, configuration.SSL.JavaKeystore.Password)
+		}
+		for _, target := range configuration.SSL.SSHTargets {
+			values = append(values, target.Password, target.PrivateKeyPassphrase)
+		}
+		for _, nas := range configuration.SSL.Synology {
+			values = append(values, sensitiveHTTPConfigValues(nas.URL)...)
+			values = append(values, nas.Password, nas.DeviceID)
+		}
+		for _, cluster := range configuration.SSL.Proxmox {
+			values = append(values, sensitiveHTTPConfigValues(cluster.URL)...)
+			values = append(values, cluster.TokenSecret)
+		}
+		for _, firewall := range configuration.SSL.Firewalls {
+			values = append(values, sensitiveHTTPConfigValues(firewall.URL)...)
+			values = append(values, firewall.APIKey, firewall.APISecret)
+		}
+		for _, database := range configuration.SSL.Databases {
+			values = append(values, database.Password)
+		}
+		for _, server := range configuration.SSL.S3Servers {
+			values = append(values, sensitiveHTTPConfigValues(server.AdminURL)...)
+			values = append(values, server.SecretKey, server.Password, server.PrivateKeyPassphrase)
+		}
+		for _, server := range configuration.SSL.MediaServers {
+			values = append(values, sensitiveHTTPConfigValues(server.URL)...)
+			values = append(values, server.APIKey, server.Password)
+		}
 	}
 	for _, provider := range configuration.Provider {
 		if provider == nil || provider.Auth == nil {
@@ -36,6 +36,7 @@ const (
 	Provider_PROVIDER_BAIDU_CLOUD   Provider = 8  // 百度云
 	Provider_PROVIDER_DOGE_CLOUD    Provider = 9  // 多吉云
 	Provider_PROVIDER_LECDN         Provider = 10 // LeCDN
+	Provider_PROVIDER_AWS           Provider = 11 // 亚马逊云科技
 )
 
 // Enum value maps for Provider.
@@ -52,6 +53,7 @@ var (
 		8:  "PROVIDER_BAIDU_CLOUD",
 		9:  "PROVIDER_DOGE_CLOUD",
 		10: "PROVIDER_LECDN",
+		11: "PROVIDER_AWS",
 	}
 	Provider_value = map[string]int32{
 		"PROVIDER_UNSPECIFIED":   0,
@@ -65,6 +67,7 @@ var (
 		"PROVIDER_BAIDU_CLOUD":   8,
 		"PROVIDER_DOGE_CLOUD":    9,
 		"PROVIDER_LECDN":         10,
+		"PROVIDER_AWS":           11,
 	}
 )
 
@@ -99,31 +102,52 @@ func (Provider) EnumDescriptor() ([]byte, []int) {
 type DeploymentType int32
 
 const (
-	DeploymentType_DEPLOYMENT_TYPE_UNSPECIFIED                     DeploymentType = 0  // 未指定部署业务
-	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_CERT            DeploymentType = 1  // Nginx 证书部署
-	DeploymentType_DEPLOYMENT_TYPE_UPLOAD_CERT                     DeploymentType = 2  // 上传证书
-	DeploymentType_DEPLOYMENT_TYPE_CDN                             DeploymentType = 3  // CDN
-	DeploymentType_DEPLOYMENT_TYPE_DCDN                            DeploymentType = 4  // DCDN
-	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_CERT           DeploymentType = 6  // Apache 证书部署
-	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_RUSTFS_CERT           DeploymentType = 7  // RustFS 证书部署
-	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_CERT           DeploymentType = 8  // 飞牛 OS 证书部署
-	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_CERT           DeploymentType = 9  // 1Panel 证书部署
-	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_OPENVPN_AS_CERT       DeploymentType = 10 // OpenVPN-AS 证书部署
-	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_UPLOAD_ONLY_CERT      DeploymentType = 11 // 仅上传证书
-	DeploymentType_DEPLOYMENT_TYPE_ESA                             DeploymentType = 12 // 阿里云 ESA
-	DeploymentType_DEPLOYMENT_TYPE_EDGEONE                         DeploymentType = 13 // 腾讯云 EdgeOne
-	DeploymentType_DEPLOYMENT_TYPE_COS                             DeploymentType = 14 // 腾讯云 COS 自定义域名
-	DeploymentType_DEPLOYMENT_TYPE_OSS_CUSTOM_DOMAIN               DeploymentType = 15 // 阿里云 OSS 自定义域名
-	DeploymentType_DEPLOYMENT_TYPE_CLB                             DeploymentType = 16 // 负载均衡 CLB
-	DeploymentType_DEPLOYMENT_TYPE_ALB                             DeploymentType = 17 // 阿里云 ALB
-	DeploymentType_DEPLOYMENT_TYPE_NLB                             DeploymentType = 18 // 阿里云 NLB
-	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_CERT         DeploymentType = 19 // 雷池 WAF 证书部署
-	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_WEBSITE_CERT   DeploymentType = 20 // 1Panel 网站证书部署
-	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_WEBSITE_CERT DeploymentType = 21 // 宝塔面板网站证书部署
-	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_CERT         DeploymentType = 22 // 宝塔面板证书库上传
-	DeploymentType_DEPLOYMENT_TYPE_OBS_CUSTOM_DOMAIN               DeploymentType = 23 // 华为云 OBS 自定义域名
-	DeploymentType_DEPLOYMENT_TYPE_TOS_CUSTOM_DOMAIN               DeploymentType = 24 // 火山引擎 TOS 自定义域名
-	DeploymentType_DEPLOYMENT_TYPE_ELB                             DeploymentType = 25 // 华为云 ELB
+	DeploymentType_DEPLOYMENT_TYPE_UNSPECIFIED                      DeploymentType = 0  // 未指定部署业务
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_CERT             DeploymentType = 1  // Nginx 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_UPLOAD_CERT                      DeploymentType = 2  // 上传证书
+	DeploymentType_DEPLOYMENT_TYPE_CDN                              DeploymentType = 3  // CDN
+	DeploymentType_DEPLOYMENT_TYPE_DCDN                             DeploymentType = 4  // DCDN
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_CERT            DeploymentType = 6  // Apache 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_RUSTFS_CERT            DeploymentType = 7  // RustFS 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_CERT            DeploymentType = 8  // 飞牛 OS 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_CERT            DeploymentType = 9  // 1Panel 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_OPENVPN_AS_CERT        DeploymentType = 10 // OpenVPN-AS 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_UPLOAD_ONLY_CERT       DeploymentType = 11 // 仅上传证书
+	DeploymentType_DEPLOYMENT_TYPE_ESA                              DeploymentType = 12 // 阿里云 ESA
+	DeploymentType_DEPLOYMENT_TYPE_EDGEONE                          DeploymentType = 13 // 腾讯云 EdgeOne
+	DeploymentType_DEPLOYMENT_TYPE_COS                              DeploymentType = 14 // 腾讯云 COS 自定义域名
+	DeploymentType_DEPLOYMENT_TYPE_OSS_CUSTOM_DOMAIN                DeploymentType = 15 // 阿里云 OSS 自定义域名
+	DeploymentType_DEPLOYMENT_TYPE_CLB                              DeploymentType = 16 // 负载均衡 CLB
+	DeploymentType_DEPLOYMENT_TYPE_ALB                              DeploymentType = 17 // 阿里云 ALB
+	DeploymentType_DEPLOYMENT_TYPE_NLB                              DeploymentType = 18 // 阿里云 NLB
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_CERT          DeploymentType = 19 // 雷池 WAF 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_WEBSITE_CERT    DeploymentType = 20 // 1Panel 网站证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_WEBSITE_CERT  DeploymentType = 21 // 宝塔面板网站证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_CERT          DeploymentType = 22 // 宝塔面板证书库上传
+	DeploymentType_DEPLOYMENT_TYPE_OBS_CUSTOM_DOMAIN                DeploymentType = 23 // 华为云 OBS 自定义域名
+	DeploymentType_DEPLOYMENT_TYPE_TOS_CUSTOM_DOMAIN                DeploymentType = 24 // 火山引擎 TOS 自定义域名
+	DeploymentType_DEPLOYMENT_TYPE_ELB                              DeploymentType = 25 // 华为云 ELB
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_CADDY_CERT             DeploymentType = 26 // Caddy 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_HAPROXY_CERT           DeploymentType = 27 // HAProxy 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_TRAEFIK_CERT           DeploymentType = 28 // Traefik 文件 provider 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_KUBERNETES_SECRET_CERT DeploymentType = 29 // Kubernetes TLS Secret 部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_JAVA_KEYSTORE_CERT     DeploymentType = 30 // Java 密钥库部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_LOCAL_TARGET_CERT      DeploymentType = 31 // 本地目录与自定义命令部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SSH_CERT               DeploymentType = 32 // 通用 SSH 远程主机部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SYNOLOGY_CERT          DeploymentType = 33 // 群晖 DSM 证书导入
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXMOX_CERT           DeploymentType = 34 // Proxmox VE 节点证书
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FIREWALL_CERT          DeploymentType = 35 // OPNsense/pfSense 防火墙证书
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_SERVER_CERT      DeploymentType = 36 // Nginx server 块证书原位替换
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_VHOST_CERT      DeploymentType = 37 // Apache 虚拟主机证书原位替换
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MAIL_CERT              DeploymentType = 38 // 邮件服务证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DOCKER_CONTAINER_CERT  DeploymentType = 39 // Docker 容器证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_NPM_PROXY_HOST_CERT    DeploymentType = 40 // Nginx Proxy Manager 代理主机证书
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_PROXY_SERVER_CERT      DeploymentType = 41 // 代理服务证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_DATABASE_TLS_CERT      DeploymentType = 42 // 数据库 TLS 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_S3_SERVER_CERT         DeploymentType = 43 // S3 兼容对象存储证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_MEDIA_SERVER_PFX       DeploymentType = 44 // 媒体服务器 PFX 证书部署
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_SITE_CERT     DeploymentType = 45 // 部署到本地 CLI 雷池 WAF 防护站点并绑定证书
+	DeploymentType_DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_DEVICE_CERT     DeploymentType = 46 // 飞牛 OS 设备证书，通过 SSH 部署到具名设备
 )
 
 // Enum value maps for DeploymentType.
@@ -154,33 +178,75 @@ var (
 		23: "DEPLOYMENT_TYPE_OBS_CUSTOM_DOMAIN",
 		24: "DEPLOYMENT_TYPE_TOS_CUSTOM_DOMAIN",
 		25: "DEPLOYMENT_TYPE_ELB",
+		26: "DEPLOYMENT_TYPE_ANSSL_CLI_CADDY_CERT",
+		27: "DEPLOYMENT_TYPE_ANSSL_CLI_HAPROXY_CERT",
+		28: "DEPLOYMENT_TYPE_ANSSL_CLI_TRAEFIK_CERT",
+		29: "DEPLOYMENT_TYPE_ANSSL_CLI_KUBERNETES_SECRET_CERT",
+		30: "DEPLOYMENT_TYPE_ANSSL_CLI_JAVA_KEYSTORE_CERT",
+		31: "DEPLOYMENT_TYPE_ANSSL_CLI_LOCAL_TARGET_CERT",
+		32: "DEPLOYMENT_TYPE_ANSSL_CLI_SSH_CERT",
+		33: "DEPLOYMENT_TYPE_ANSSL_CLI_SYNOLOGY_CERT",
+		34: "DEPLOYMENT_TYPE_ANSSL_CLI_PROXMOX_CERT",
+		35: "DEPLOYMENT_TYPE_ANSSL_CLI_FIREWALL_CERT",
+		36: "DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_SERVER_CERT",
+		37: "DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_VHOST_CERT",
+		38: "DEPLOYMENT_TYPE_ANSSL_CLI_MAIL_CERT",
+		39: "DEPLOYMENT_TYPE_ANSSL_CLI_DOCKER_CONTAINER_CERT",
+		40: "DEPLOYMENT_TYPE_ANSSL_CLI_NPM_PROXY_HOST_CERT",
+		41: "DEPLOYMENT_TYPE_ANSSL_CLI_PROXY_SERVER_CERT",
+		42: "DEPLOYMENT_TYPE_ANSSL_CLI_DATABASE_TLS_CERT",
+		43: "DEPLOYMENT_TYPE_ANSSL_CLI_S3_SERVER_CERT",
+		44: "DEPLOYMENT_TYPE_ANSSL_CLI_MEDIA_SERVER_PFX",
+		45: "DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_SITE_CERT",
+		46: "DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_DEVICE_CERT",
 	}
 	DeploymentType_value = map[string]int32{
-		"DEPLOYMENT_TYPE_UNSPECIFIED":                     0,
-		"DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_CERT":            1,
-		"DEPLOYMENT_TYPE_UPLOAD_CERT":                     2,
-		"DEPLOYMENT_TYPE_CDN":                             3,
-		"DEPLOYMENT_TYPE_DCDN":                            4,
-		"DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_CERT":           6,
-		"DEPLOYMENT_TYPE_ANSSL_CLI_RUSTFS_CERT":           7,
-		"DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_CERT":           8,
-		"DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_CERT":           9,
-		"DEPLOYMENT_TYPE_ANSSL_CLI_OPENVPN_AS_CERT":       10,
-		"DEPLOYMENT_TYPE_ANSSL_CLI_UPLOAD_ONLY_CERT":      11,
-		"DEPLOYMENT_TYPE_ESA":                             12,
-		"DEPLOYMENT_TYPE_EDGEONE":                         13,
-		"DEPLOYMENT_TYPE_COS":                             14,
-		"DEPLOYMENT_TYPE_OSS_CUSTOM_DOMAIN":               15,
-		"DEPLOYMENT_TYPE_CLB":                             16,
-		"DEPLOYMENT_TYPE_ALB":                             17,
-		"DEPLOYMENT_TYPE_NLB":                             18,
-		"DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_CERT":         19,
-		"DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_WEBSITE_CERT":   20,
-		"DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_WEBSITE_CERT": 21,
-		"DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_CERT":         22,
-		"DEPLOYMENT_TYPE_OBS_CUSTOM_DOMAIN":               23,
-		"DEPLOYMENT_TYPE_TOS_CUSTOM_DOMAIN":               24,
-		"DEPLOYMENT_TYPE_ELB":                             25,
+		"DEPLOYMENT_TYPE_UNSPECIFIED":                      0,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_CERT":             1,
+		"DEPLOYMENT_TYPE_UPLOAD_CERT":                      2,
+		"DEPLOYMENT_TYPE_CDN":                              3,
+		"DEPLOYMENT_TYPE_DCDN":                             4,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_CERT":            6,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_RUSTFS_CERT":            7,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_CERT":            8,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_CERT":            9,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_OPENVPN_AS_CERT":        10,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_UPLOAD_ONLY_CERT":       11,
+		"DEPLOYMENT_TYPE_ESA":                              12,
+		"DEPLOYMENT_TYPE_EDGEONE":                          13,
+		"DEPLOYMENT_TYPE_COS":                              14,
+		"DEPLOYMENT_TYPE_OSS_CUSTOM_DOMAIN":                15,
+		"DEPLOYMENT_TYPE_CLB":                              16,
+		"DEPLOYMENT_TYPE_ALB":                              17,
+		"DEPLOYMENT_TYPE_NLB":                              18,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_CERT":          19,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_1PANEL_WEBSITE_CERT":    20,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_WEBSITE_CERT":  21,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_CERT":          22,
+		"DEPLOYMENT_TYPE_OBS_CUSTOM_DOMAIN":                23,
+		"DEPLOYMENT_TYPE_TOS_CUSTOM_DOMAIN":                24,
+		"DEPLOYMENT_TYPE_ELB":                              25,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_CADDY_CERT":             26,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_HAPROXY_CERT":           27,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_TRAEFIK_CERT":           28,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_KUBERNETES_SECRET_CERT": 29,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_JAVA_KEYSTORE_CERT":     30,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_LOCAL_TARGET_CERT":      31,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_SSH_CERT":               32,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_SYNOLOGY_CERT":          33,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_PROXMOX_CERT":           34,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_FIREWALL_CERT":          35,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_SERVER_CERT":      36,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_VHOST_CERT":      37,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_MAIL_CERT":              38,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_DOCKER_CONTAINER_CERT":  39,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_NPM_PROXY_HOST_CERT":    40,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_PROXY_SERVER_CERT":      41,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_DATABASE_TLS_CERT":      42,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_S3_SERVER_CERT":         43,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_MEDIA_SERVER_PFX":       44,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_SITE_CERT":     45,
+		"DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_DEVICE_CERT":     46,
 	}
 )
 
@@ -4067,7 +4133,7 @@ const file_deployPB_deploy_proto_rawDesc = "" +
 	"\x0echallengeToken\x18\a \x01(\tR\x0echallengeToken\x12,\n" +
 	"\x11challengeResponse\x18\b \x01(\tR\x11challengeResponse\x12\x1d\n" +
 	"\n" +
-	"target_ref\x18\t \x01(\tR\ttargetRef*\x93\x02\n" +
+	"target_ref\x18\t \x01(\tR\ttargetRef*\xa5\x02\n" +
 	"\bProvider\x12\x18\n" +
 	"\x14PROVIDER_UNSPECIFIED\x10\x00\x12\x16\n" +
 	"\x12PROVIDER_ANSSL_CLI\x10\x01\x12\x13\n" +
@@ -4080,7 +4146,8 @@ const file_deployPB_deploy_proto_rawDesc = "" +
 	"\x14PROVIDER_BAIDU_CLOUD\x10\b\x12\x17\n" +
 	"\x13PROVIDER_DOGE_CLOUD\x10\t\x12\x12\n" +
 	"\x0ePROVIDER_LECDN\x10\n" +
-	"*\xaa\a\n" +
+	"\x12\x10\n" +
+	"\fPROVIDER_AWS\x10\v*\x8a\x0f\n" +
 	"\x0eDeploymentType\x12\x1f\n" +
 	"\x1bDEPLOYMENT_TYPE_UNSPECIFIED\x10\x00\x12(\n" +
 	"$DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_CERT\x10\x01\x12\x1f\n" +
@@ -4107,7 +4174,28 @@ const file_deployPB_deploy_proto_rawDesc = "" +
 	"'DEPLOYMENT_TYPE_ANSSL_CLI_BT_PANEL_CERT\x10\x16\x12%\n" +
 	"!DEPLOYMENT_TYPE_OBS_CUSTOM_DOMAIN\x10\x17\x12%\n" +
 	"!DEPLOYMENT_TYPE_TOS_CUSTOM_DOMAIN\x10\x18\x12\x17\n" +
-	"\x13DEPLOYMENT_TYPE_ELB\x10\x19\"\x04\b\x05\x10\x05*\x84\x01\n" +
+	"\x13DEPLOYMENT_TYPE_ELB\x10\x19\x12(\n" +
+	"$DEPLOYMENT_TYPE_ANSSL_CLI_CADDY_CERT\x10\x1a\x12*\n" +
+	"&DEPLOYMENT_TYPE_ANSSL_CLI_HAPROXY_CERT\x10\x1b\x12*\n" +
+	"&DEPLOYMENT_TYPE_ANSSL_CLI_TRAEFIK_CERT\x10\x1c\x124\n" +
+	"0DEPLOYMENT_TYPE_ANSSL_CLI_KUBERNETES_SECRET_CERT\x10\x1d\x120\n" +
+	",DEPLOYMENT_TYPE_ANSSL_CLI_JAVA_KEYSTORE_CERT\x10\x1e\x12/\n" +
+	"+DEPLOYMENT_TYPE_ANSSL_CLI_LOCAL_TARGET_CERT\x10\x1f\x12&\n" +
+	"\"DEPLOYMENT_TYPE_ANSSL_CLI_SSH_CERT\x10 \x12+\n" +
+	"'DEPLOYMENT_TYPE_ANSSL_CLI_SYNOLOGY_CERT\x10!\x12*\n" +
+	"&DEPLOYMENT_TYPE_ANSSL_CLI_PROXMOX_CERT\x10\"\x12+\n" +
+	"'DEPLOYMENT_TYPE_ANSSL_CLI_FIREWALL_CERT\x10#\x12/\n" +
+	"+DEPLOYMENT_TYPE_ANSSL_CLI_NGINX_SERVER_CERT\x10$\x12/\n" +
+	"+DEPLOYMENT_TYPE_ANSSL_CLI_APACHE_VHOST_CERT\x10%\x12'\n" +
+	"#DEPLOYMENT_TYPE_ANSSL_CLI_MAIL_CERT\x10&\x123\n" +
+	"/DEPLOYMENT_TYPE_ANSSL_CLI_DOCKER_CONTAINER_CERT\x10'\x121\n" +
+	"-DEPLOYMENT_TYPE_ANSSL_CLI_NPM_PROXY_HOST_CERT\x10(\x12/\n" +
+	"+DEPLOYMENT_TYPE_ANSSL_CLI_PROXY_SERVER_CERT\x10)\x12/\n" +
+	"+DEPLOYMENT_TYPE_ANSSL_CLI_DATABASE_TLS_CERT\x10*\x12,\n" +
+	"(DEPLOYMENT_TYPE_ANSSL_CLI_S3_SERVER_CERT\x10+\x12.\n" +
+	"*DEPLOYMENT_TYPE_ANSSL_CLI_MEDIA_SERVER_PFX\x10,\x120\n" +
+	",DEPLOYMENT_TYPE_ANSSL_CLI_SAFELINE_SITE_CERT\x10-\x120\n" +
+	",DEPLOYMENT_TYPE_ANSSL_CLI_FEINIU_DEVICE_CERT\x10.\"\x04\b\x05\x10\x05*\x84\x01\n" +
 	"\x14DeploymentTargetMode\x12&\n" +
 	"\"DEPLOYMENT_TARGET_MODE_UNSPECIFIED\x10\x00\x12\x1f\n" +
 	"\x1bDEPLOYMENT_TARGET_MODE_NONE\x10\x01\x12#\n" +